@@ -1,6 +1,7 @@
 package beaconclient
 
 import (
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +17,9 @@ type MockBeaconInstance struct {
 	MockProposerDuties     *ProposerDutiesResponse
 	MockProposerDutiesErr  error
 	MockFetchValidatorsErr error
+	MockRandao             *GetRandaoResponse
+	MockWithdrawals        *GetWithdrawalsResponse
+	MockPublishBlock       func() (code int, err error) // publishing succeeds if nil
 
 	ResponseDelay time.Duration
 }
@@ -77,6 +81,30 @@ func (c *MockBeaconInstance) GetStateValidators(stateID string) (*GetStateValida
 	return validatorResp, c.MockFetchValidatorsErr
 }
 
+func (c *MockBeaconInstance) GetPendingStateValidators(stateID string) (*GetStateValidatorsResponse, error) {
+	c.addDelay()
+	validatorResp := &GetStateValidatorsResponse{ //nolint:exhaustruct
+		Data: make([]ValidatorResponseEntry, 0),
+	}
+	for _, entry := range c.validatorSet {
+		if strings.HasPrefix(entry.Status, "pending") {
+			validatorResp.Data = append(validatorResp.Data, entry)
+		}
+	}
+	return validatorResp, c.MockFetchValidatorsErr
+}
+
+func (c *MockBeaconInstance) GetStateValidator(stateID, pubkey string) (*GetStateValidatorResponse, error) {
+	c.addDelay()
+	c.mu.RLock()
+	entry, found := c.validatorSet[common.NewPubkeyHex(pubkey)]
+	c.mu.RUnlock()
+	if !found {
+		return nil, ErrValidatorNotFound
+	}
+	return &GetStateValidatorResponse{Data: entry}, c.MockFetchValidatorsErr //nolint:exhaustruct
+}
+
 func (c *MockBeaconInstance) SyncStatus() (*SyncStatusPayloadData, error) {
 	c.addDelay()
 	return c.MockSyncStatus, c.MockSyncStatusErr
@@ -91,6 +119,8 @@ func (c *MockBeaconInstance) SubscribeToHeadEvents(slotC chan HeadEventData) {}
 
 func (c *MockBeaconInstance) SubscribeToPayloadAttributesEvents(slotC chan PayloadAttributesEvent) {}
 
+func (c *MockBeaconInstance) SubscribeToChainReorgEvents(reorgC chan ChainReorgEvent) {}
+
 func (c *MockBeaconInstance) GetProposerDuties(epoch uint64) (*ProposerDutiesResponse, error) {
 	c.addDelay()
 	return c.MockProposerDuties, c.MockProposerDutiesErr
@@ -111,6 +141,9 @@ func (c *MockBeaconInstance) addDelay() {
 }
 
 func (c *MockBeaconInstance) PublishBlock(block *common.VersionedSignedProposal, broadcaseMode BroadcastMode) (code int, err error) {
+	if c.MockPublishBlock != nil {
+		return c.MockPublishBlock()
+	}
 	return 0, nil
 }
 
@@ -127,9 +160,9 @@ func (c *MockBeaconInstance) GetForkSchedule() (spec *GetForkScheduleResponse, e
 }
 
 func (c *MockBeaconInstance) GetRandao(slot uint64) (spec *GetRandaoResponse, err error) {
-	return nil, nil
+	return c.MockRandao, nil
 }
 
 func (c *MockBeaconInstance) GetWithdrawals(slot uint64) (spec *GetWithdrawalsResponse, err error) {
-	return nil, nil
+	return c.MockWithdrawals, nil
 }
@@ -20,16 +20,26 @@ func (*MockMultiBeaconClient) SubscribeToHeadEvents(slotC chan HeadEventData) {}
 func (*MockMultiBeaconClient) SubscribeToPayloadAttributesEvents(payloadAttrC chan PayloadAttributesEvent) {
 }
 
+func (*MockMultiBeaconClient) SubscribeToChainReorgEvents(reorgC chan ChainReorgEvent) {}
+
 func (*MockMultiBeaconClient) GetStateValidators(stateID string) (*GetStateValidatorsResponse, error) {
 	return nil, nil
 }
 
+func (*MockMultiBeaconClient) GetPendingStateValidators(stateID string) (*GetStateValidatorsResponse, error) {
+	return nil, nil
+}
+
+func (*MockMultiBeaconClient) GetStateValidator(stateID, pubkey string) (*GetStateValidatorResponse, error) {
+	return nil, ErrValidatorNotFound
+}
+
 func (*MockMultiBeaconClient) GetProposerDuties(epoch uint64) (*ProposerDutiesResponse, error) {
 	return nil, nil
 }
 
-func (*MockMultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal) (code int, err error) {
-	return 0, nil
+func (*MockMultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal) (code int, results *PublishResults, err error) {
+	return 0, nil, nil
 }
 
 func (*MockMultiBeaconClient) GetGenesis() (*GetGenesisResponse, error) {
@@ -4,10 +4,13 @@ package beaconclient
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/sirupsen/logrus"
 	uberatomic "go.uber.org/atomic"
@@ -34,11 +37,17 @@ type IMultiBeaconClient interface {
 	SubscribeToHeadEvents(slotC chan HeadEventData)
 	// SubscribeToPayloadAttributesEvents subscribes to payload attributes events to validate fields such as prevrandao and withdrawals
 	SubscribeToPayloadAttributesEvents(payloadAttrC chan PayloadAttributesEvent)
+	// SubscribeToChainReorgEvents subscribes to chain reorg events, to invalidate bids built on a reorged-away head
+	SubscribeToChainReorgEvents(reorgC chan ChainReorgEvent)
 
 	// GetStateValidators returns all active and pending validators from the beacon node
 	GetStateValidators(stateID string) (*GetStateValidatorsResponse, error)
+	// GetPendingStateValidators returns only the pending validators, for incremental updates of the known validators
+	GetPendingStateValidators(stateID string) (*GetStateValidatorsResponse, error)
+	// GetStateValidator returns a single validator by pubkey
+	GetStateValidator(stateID, pubkey string) (*GetStateValidatorResponse, error)
 	GetProposerDuties(epoch uint64) (*ProposerDutiesResponse, error)
-	PublishBlock(block *common.VersionedSignedProposal) (code int, err error)
+	PublishBlock(block *common.VersionedSignedProposal) (code int, results *PublishResults, err error)
 	GetGenesis() (*GetGenesisResponse, error)
 	GetSpec() (spec *GetSpecResponse, err error)
 	GetForkSchedule() (spec *GetForkScheduleResponse, err error)
@@ -52,7 +61,10 @@ type IBeaconInstance interface {
 	CurrentSlot() (uint64, error)
 	SubscribeToHeadEvents(slotC chan HeadEventData)
 	SubscribeToPayloadAttributesEvents(slotC chan PayloadAttributesEvent)
+	SubscribeToChainReorgEvents(reorgC chan ChainReorgEvent)
 	GetStateValidators(stateID string) (*GetStateValidatorsResponse, error)
+	GetPendingStateValidators(stateID string) (*GetStateValidatorsResponse, error)
+	GetStateValidator(stateID, pubkey string) (*GetStateValidatorResponse, error)
 	GetProposerDuties(epoch uint64) (*ProposerDutiesResponse, error)
 	GetURI() string
 	GetPublishURI() string
@@ -73,6 +85,10 @@ type MultiBeaconClient struct {
 	ffAllowSyncingBeaconNode bool
 
 	broadcastMode BroadcastMode
+
+	// publish attempts per beacon node, and the backoff before the first retry (doubled on every further retry)
+	publishMaxAttempts  int
+	publishRetryBackoff time.Duration
 }
 
 func NewMultiBeaconClient(log *logrus.Entry, beaconInstances []IBeaconInstance) *MultiBeaconClient {
@@ -82,6 +98,8 @@ func NewMultiBeaconClient(log *logrus.Entry, beaconInstances []IBeaconInstance)
 		bestBeaconIndex:          *uberatomic.NewInt64(0),
 		ffAllowSyncingBeaconNode: false,
 		broadcastMode:            ConsensusAndEquivocation,
+		publishMaxAttempts:       cli.GetEnvInt("PUBLISH_BLOCK_MAX_ATTEMPTS", 3),
+		publishRetryBackoff:      time.Duration(cli.GetEnvInt("PUBLISH_BLOCK_RETRY_BACKOFF_MS", 50)) * time.Millisecond,
 	}
 
 	// feature flags
@@ -171,6 +189,14 @@ func (c *MultiBeaconClient) SubscribeToPayloadAttributesEvents(slotC chan Payloa
 	}
 }
 
+// SubscribeToChainReorgEvents subscribes to chain reorg events from all beacon nodes. Like head events, a single reorg
+// will likely be received once for every beacon node.
+func (c *MultiBeaconClient) SubscribeToChainReorgEvents(reorgC chan ChainReorgEvent) {
+	for _, instance := range c.beaconInstances {
+		go instance.SubscribeToChainReorgEvents(reorgC)
+	}
+}
+
 // GetStateValidators returns all known validators, and queries the beacon nodes in reverse order (because it is a heavy request for the CL client)
 func (c *MultiBeaconClient) GetStateValidators(stateID string) (*GetStateValidatorsResponse, error) {
 	for i, client := range c.beaconInstancesByLeastUsed() {
@@ -192,6 +218,44 @@ func (c *MultiBeaconClient) GetStateValidators(stateID string) (*GetStateValidat
 	return nil, ErrBeaconNodesUnavailable
 }
 
+// GetPendingStateValidators returns the pending validators, and queries the beacon nodes in reverse order
+func (c *MultiBeaconClient) GetPendingStateValidators(stateID string) (*GetStateValidatorsResponse, error) {
+	for i, client := range c.beaconInstancesByLeastUsed() {
+		log := c.log.WithField("uri", client.GetURI())
+		log.Debug("fetching pending validators")
+
+		validators, err := client.GetPendingStateValidators(stateID)
+		if err != nil {
+			log.WithError(err).Error("failed to fetch pending validators")
+			continue
+		}
+
+		c.bestBeaconIndex.Store(int64(i))
+		return validators, nil
+	}
+
+	return nil, ErrBeaconNodesUnavailable
+}
+
+// GetStateValidator returns a single validator by pubkey, from the first beacon node which knows it
+func (c *MultiBeaconClient) GetStateValidator(stateID, pubkey string) (*GetStateValidatorResponse, error) {
+	for i, client := range c.beaconInstancesByLeastUsed() {
+		log := c.log.WithFields(logrus.Fields{"uri": client.GetURI(), "pubkey": pubkey})
+		log.Debug("fetching validator")
+
+		validator, err := client.GetStateValidator(stateID, pubkey)
+		if err != nil {
+			log.WithError(err).Debug("failed to fetch validator")
+			continue
+		}
+
+		c.bestBeaconIndex.Store(int64(i))
+		return validator, nil
+	}
+
+	return nil, ErrBeaconNodesUnavailable
+}
+
 func (c *MultiBeaconClient) GetProposerDuties(epoch uint64) (*ProposerDutiesResponse, error) {
 	// return the first successful beacon node response
 	clients := c.beaconInstancesByLastResponse()
@@ -247,19 +311,23 @@ type publishResp struct {
 	index int
 	code  int
 	err   error
+
+	alreadyKnown bool
 }
 
 // PublishBlock publishes the signed beacon block via https://ethereum.github.io/beacon-APIs/#/ValidatorRequiredApi/publishBlock
-func (c *MultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal) (code int, err error) {
+// on all beacon nodes, retrying on each of them with exponential backoff. It returns on the first successful publish,
+// while the results keep collecting the outcomes of the other beacon nodes.
+func (c *MultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal) (code int, results *PublishResults, err error) {
 	slot, err := block.Slot()
 	if err != nil {
 		c.log.WithError(err).Warn("failed to publish block as block slot is missing")
-		return 0, err
+		return 0, nil, err
 	}
 	blockHash, err := block.ExecutionBlockHash()
 	if err != nil {
 		c.log.WithError(err).Warn("failed to publish block as block hash is missing")
-		return 0, err
+		return 0, nil, err
 	}
 	log := c.log.WithFields(logrus.Fields{
 		"slot":      slot,
@@ -267,6 +335,7 @@ func (c *MultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal)
 	})
 
 	clients := c.beaconInstancesByLastResponse()
+	results = new(PublishResults)
 
 	// The chan will be cleaner up automatically once the function exists even if it was still being written to
 	resChans := make(chan publishResp, len(clients))
@@ -275,11 +344,13 @@ func (c *MultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal)
 		log := log.WithField("uri", client.GetPublishURI())
 		log.Debug("publishing block")
 		go func(index int, client IBeaconInstance) {
-			code, err := client.PublishBlock(block, c.broadcastMode)
+			outcome, err := c.publishBlockWithRetry(log, client, block)
+			results.add(outcome)
 			resChans <- publishResp{
-				index: index,
-				code:  code,
-				err:   err,
+				index:        index,
+				code:         outcome.Code,
+				err:          err,
+				alreadyKnown: outcome.AlreadyKnown,
 			}
 		}(i, client)
 	}
@@ -288,7 +359,11 @@ func (c *MultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal)
 	for i := 0; i < len(clients); i++ {
 		res := <-resChans
 		log = log.WithField("beacon", clients[res.index].GetPublishURI())
-		if res.err != nil {
+		if res.alreadyKnown {
+			// The block is already on the network, i.e. through gossip or another relay instance
+			log.WithField("statusCode", res.code).WithError(res.err).Info("block already known by beacon node")
+			return http.StatusOK, results, nil
+		} else if res.err != nil {
 			log.WithField("statusCode", res.code).WithError(res.err).Warn("failed to publish block")
 			lastErrPublishResp = res
 			continue
@@ -303,14 +378,14 @@ func (c *MultiBeaconClient) PublishBlock(block *common.VersionedSignedProposal)
 		c.bestBeaconIndex.Store(int64(res.index))
 
 		log.WithField("statusCode", res.code).Info("published block")
-		return res.code, nil
+		return res.code, results, nil
 	}
 
 	if lastErrPublishResp.err == nil {
-		return lastErrPublishResp.code, nil
+		return lastErrPublishResp.code, results, nil
 	}
 	log.Error("failed to publish block on any CL node")
-	return lastErrPublishResp.code, fmt.Errorf("last error: %w", lastErrPublishResp.err)
+	return lastErrPublishResp.code, results, fmt.Errorf("last error: %w", lastErrPublishResp.err)
 }
 
 // GetGenesis returns the genesis info - https://ethereum.github.io/beacon-APIs/#/Beacon/getGenesis
@@ -74,6 +74,16 @@ type PayloadAttributesEventData struct {
 	PayloadAttributes PayloadAttributes `json:"payload_attributes"`
 }
 
+// ChainReorgEvent represents the data of a chain_reorg event
+// {"slot":"200","depth":"50","old_head_block":"0x9a2fefd2fdb57f74993c7780ea5b9030d2897b615b89f808011ca5aebed54eaf","new_head_block":"0x76262e91970d375a19bfe8a867288d7b9cde43c8635f598d93d39d041706fc76","old_head_state":"0x9a2fefd2fdb57f74993c7780ea5b9030d2897b615b89f808011ca5aebed54eaf","new_head_state":"0x600e852a08c1200654ddf11025f1ceacb3c2e74bdd5c630cde0838b2591b69f9","epoch":"2","execution_optimistic":false}
+type ChainReorgEvent struct {
+	Slot         uint64 `json:"slot,string"`
+	Depth        uint64 `json:"depth,string"`
+	OldHeadBlock string `json:"old_head_block"`
+	NewHeadBlock string `json:"new_head_block"`
+	Epoch        uint64 `json:"epoch,string"`
+}
+
 type PayloadAttributes struct {
 	Timestamp             uint64                `json:"timestamp,string"`
 	PrevRandao            string                `json:"prev_randao"`
@@ -134,6 +144,32 @@ func (c *ProdBeaconInstance) SubscribeToPayloadAttributesEvents(payloadAttribute
 	}
 }
 
+func (c *ProdBeaconInstance) SubscribeToChainReorgEvents(reorgC chan ChainReorgEvent) {
+	eventsURL := fmt.Sprintf("%s/eth/v1/events?topics=chain_reorg", c.beaconURI)
+	log := c.log.WithField("url", eventsURL)
+	log.Info("subscribing to chain_reorg events")
+
+	client := sse.NewClient(eventsURL)
+
+	for {
+		err := client.SubscribeRaw(func(msg *sse.Event) {
+			var data ChainReorgEvent
+			err := json.Unmarshal(msg.Data, &data)
+			if err != nil {
+				log.WithError(err).Error("could not unmarshal chain_reorg event")
+			} else {
+				reorgC <- data
+			}
+		})
+		if err != nil {
+			log.WithError(err).Error("failed to subscribe to chain_reorg events")
+			time.Sleep(1 * time.Second)
+		}
+		c.log.Warn("beaconclient SubscribeRaw/SubscribeToChainReorgEvents ended, reconnecting")
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
 type GetStateValidatorsResponse struct {
 	ExecutionOptimistic bool `json:"execution_optimistic"`
 	Finalized           bool `json:"finalized"`
@@ -167,6 +203,31 @@ func (c *ProdBeaconInstance) GetStateValidators(stateID string) (*GetStateValida
 	return vd, err
 }
 
+// GetPendingStateValidators loads only the pending validators
+// https://ethereum.github.io/beacon-APIs/#/Beacon/getStateValidators
+func (c *ProdBeaconInstance) GetPendingStateValidators(stateID string) (*GetStateValidatorsResponse, error) {
+	uri := fmt.Sprintf("%s/eth/v1/beacon/states/%s/validators?status=pending", c.beaconURI, stateID)
+	vd := new(GetStateValidatorsResponse)
+	_, err := fetchBeacon(http.MethodGet, uri, nil, vd, nil, http.Header{}, false)
+	return vd, err
+}
+
+type GetStateValidatorResponse struct {
+	ExecutionOptimistic bool `json:"execution_optimistic"`
+	Finalized           bool `json:"finalized"`
+	Data                ValidatorResponseEntry
+}
+
+// GetStateValidator loads a single validator by pubkey, i.e. to look up a validator which was added to the state
+// after the last refresh of the known validators
+// https://ethereum.github.io/beacon-APIs/#/Beacon/getStateValidator
+func (c *ProdBeaconInstance) GetStateValidator(stateID, pubkey string) (*GetStateValidatorResponse, error) {
+	uri := fmt.Sprintf("%s/eth/v1/beacon/states/%s/validators/%s", c.beaconURI, stateID, pubkey)
+	vd := new(GetStateValidatorResponse)
+	_, err := fetchBeacon(http.MethodGet, uri, nil, vd, nil, http.Header{}, false)
+	return vd, err
+}
+
 // SyncStatusPayload is the response payload for /eth/v1/node/syncing
 // {"data":{"head_slot":"251114","sync_distance":"0","is_syncing":false,"is_optimistic":false}}
 type SyncStatusPayload struct {
@@ -318,12 +379,25 @@ func (c *ProdBeaconInstance) GetGenesis() (*GetGenesisResponse, error) {
 }
 
 type GetSpecResponse struct {
+	Data GetSpecResponseData `json:"data"`
+}
+
+type GetSpecResponseData struct {
 	SecondsPerSlot                  uint64 `json:"SECONDS_PER_SLOT,string"`            //nolint:tagliatelle
+	SlotsPerEpoch                   uint64 `json:"SLOTS_PER_EPOCH,string"`             //nolint:tagliatelle
 	DepositContractAddress          string `json:"DEPOSIT_CONTRACT_ADDRESS"`           //nolint:tagliatelle
 	DepositNetworkID                string `json:"DEPOSIT_NETWORK_ID"`                 //nolint:tagliatelle
 	DomainAggregateAndProof         string `json:"DOMAIN_AGGREGATE_AND_PROOF"`         //nolint:tagliatelle
 	InactivityPenaltyQuotient       string `json:"INACTIVITY_PENALTY_QUOTIENT"`        //nolint:tagliatelle
 	InactivityPenaltyQuotientAltair string `json:"INACTIVITY_PENALTY_QUOTIENT_ALTAIR"` //nolint:tagliatelle
+	GenesisForkVersion              string `json:"GENESIS_FORK_VERSION"`               //nolint:tagliatelle
+	BellatrixForkVersion            string `json:"BELLATRIX_FORK_VERSION"`             //nolint:tagliatelle
+	CapellaForkVersion              string `json:"CAPELLA_FORK_VERSION"`               //nolint:tagliatelle
+	CapellaForkEpoch                uint64 `json:"CAPELLA_FORK_EPOCH,string"`          //nolint:tagliatelle
+	DenebForkVersion                string `json:"DENEB_FORK_VERSION"`                 //nolint:tagliatelle
+	DenebForkEpoch                  uint64 `json:"DENEB_FORK_EPOCH,string"`            //nolint:tagliatelle
+	ElectraForkVersion              string `json:"ELECTRA_FORK_VERSION"`               //nolint:tagliatelle
+	ElectraForkEpoch                uint64 `json:"ELECTRA_FORK_EPOCH,string"`          //nolint:tagliatelle
 }
 
 // GetSpec - https://ethereum.github.io/beacon-APIs/#/Config/getSpec
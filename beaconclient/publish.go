@@ -0,0 +1,122 @@
+package beaconclient
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+// error messages of beacon nodes which already received the block through gossip or another relay instance
+var blockAlreadyKnownMessages = []string{"already known", "already_known", "already imported", "duplicate"}
+
+// error messages of beacon nodes which rejected a block because of its execution payload, compared in lower case and
+// without spaces, underscores and dashes (e.g. "ExecutionPayloadError", "FAILED_EXECUTION_PAYLOAD_EXECUTION")
+var invalidPayloadMessages = []string{"executionpayload", "executionengine", "newpayload"}
+
+// isInvalidPayloadError returns whether the publish error means the beacon node rejected the execution payload of the
+// block, rather than the part of the block which was built by the proposer (signature, attestations, slashings, ...)
+func isInvalidPayloadError(msg string) bool {
+	msg = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(msg))
+	for _, invalid := range invalidPayloadMessages {
+		if strings.Contains(msg, invalid) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlockAlreadyKnown returns whether the publish error means the beacon node already has the block, which is not a
+// failure: the block is on the network
+func isBlockAlreadyKnown(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, known := range blockAlreadyKnownMessages {
+		if strings.Contains(msg, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryablePublishError returns whether publishing may succeed on a retry, i.e. the beacon node was unreachable or
+// had an internal error. Blocks which the beacon node rejected as invalid (400) or broadcast despite failing
+// validation (202) are not retried.
+func isRetryablePublishError(code int, err error) bool {
+	return err != nil && (code == 0 || code >= http.StatusInternalServerError)
+}
+
+// PublishResults collects the publish outcome of every beacon node. PublishBlock returns on the first successful
+// publish, so beacon nodes which are still retrying add their outcome later.
+type PublishResults struct {
+	mu       sync.Mutex
+	outcomes []common.BeaconPublishOutcome
+}
+
+func (r *PublishResults) add(outcome common.BeaconPublishOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes = append(r.outcomes, outcome)
+}
+
+// Outcomes returns the publish outcomes which are known so far (nil-safe)
+func (r *PublishResults) Outcomes() []common.BeaconPublishOutcome {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]common.BeaconPublishOutcome(nil), r.outcomes...)
+}
+
+// PayloadRejection returns the error of a beacon node which rejected the block because of its execution payload, if no
+// beacon node accepted the block (nil-safe). Blocks which were broadcast despite failing validation (202) don't say why
+// they failed, so they are not attributed to the payload.
+func (r *PublishResults) PayloadRejection() (reason string, ok bool) {
+	for _, outcome := range r.Outcomes() {
+		if outcome.Code == http.StatusOK || outcome.AlreadyKnown {
+			return "", false
+		}
+		if outcome.Code == http.StatusBadRequest && isInvalidPayloadError(outcome.Error) {
+			reason, ok = outcome.Error, true
+		}
+	}
+	return reason, ok
+}
+
+// publishBlockWithRetry publishes the block through a single beacon node, and retries with exponential backoff while
+// the beacon node fails for reasons a retry may fix. Every attempt sends the full block contents, so the blob
+// sidecars of deneb blocks are broadcast again as well.
+func (c *MultiBeaconClient) publishBlockWithRetry(log *logrus.Entry, client IBeaconInstance, block *common.VersionedSignedProposal) (outcome common.BeaconPublishOutcome, err error) {
+	outcome.URI = client.GetPublishURI()
+	startTime := time.Now()
+	backoff := c.publishRetryBackoff
+
+	for outcome.Attempts < max(c.publishMaxAttempts, 1) {
+		outcome.Attempts++
+		outcome.Code, err = client.PublishBlock(block, c.broadcastMode)
+		if !isRetryablePublishError(outcome.Code, err) || isBlockAlreadyKnown(err) || outcome.Attempts >= c.publishMaxAttempts {
+			break
+		}
+		log.WithFields(logrus.Fields{
+			"statusCode": outcome.Code,
+			"attempt":    outcome.Attempts,
+			"backoffMs":  backoff.Milliseconds(),
+		}).WithError(err).Warn("failed to publish block, retrying")
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	outcome.DurationMs = time.Since(startTime).Milliseconds()
+	if isBlockAlreadyKnown(err) {
+		outcome.AlreadyKnown = true
+	} else if err != nil {
+		outcome.Error = err.Error()
+	}
+	return outcome, err
+}
@@ -0,0 +1,164 @@
+package beaconclient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	eth2Api "github.com/attestantio/go-eth2-client/api"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+	uberatomic "go.uber.org/atomic"
+)
+
+func testSignedProposal() *common.VersionedSignedProposal {
+	return &common.VersionedSignedProposal{
+		VersionedSignedProposal: eth2Api.VersionedSignedProposal{
+			Version: spec.DataVersionCapella,
+			Capella: &capella.SignedBeaconBlock{
+				Message: &capella.BeaconBlock{
+					Slot: 1,
+					Body: &capella.BeaconBlockBody{ExecutionPayload: &capella.ExecutionPayload{}},
+				},
+			},
+		},
+	}
+}
+
+func TestPublishBlockRetry(t *testing.T) {
+	t.Run("retries server errors", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		client := backend.beaconClient.(*MultiBeaconClient)
+		client.publishRetryBackoff = 0
+
+		calls := uberatomic.NewInt64(0)
+		backend.beaconInstances[0].MockPublishBlock = func() (int, error) {
+			if calls.Inc() < 3 {
+				return http.StatusServiceUnavailable, fmt.Errorf("%w: syncing", ErrHTTPErrorResponse)
+			}
+			return http.StatusOK, nil
+		}
+
+		code, results, err := client.PublishBlock(testSignedProposal())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, int64(3), calls.Load())
+		require.Equal(t, []common.BeaconPublishOutcome{{Code: http.StatusOK, Attempts: 3}}, results.Outcomes())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		client := backend.beaconClient.(*MultiBeaconClient)
+		client.publishRetryBackoff = 0
+
+		backend.beaconInstances[0].MockPublishBlock = func() (int, error) {
+			return 0, errTest
+		}
+
+		_, results, err := client.PublishBlock(testSignedProposal())
+		require.ErrorIs(t, err, errTest)
+		outcomes := results.Outcomes()
+		require.Len(t, outcomes, 1)
+		require.Equal(t, client.publishMaxAttempts, outcomes[0].Attempts)
+		require.Equal(t, errTest.Error(), outcomes[0].Error)
+	})
+
+	t.Run("invalid blocks are not retried", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		client := backend.beaconClient.(*MultiBeaconClient)
+
+		backend.beaconInstances[0].MockPublishBlock = func() (int, error) {
+			return http.StatusBadRequest, fmt.Errorf("%w: invalid block", ErrHTTPErrorResponse)
+		}
+
+		code, results, err := client.PublishBlock(testSignedProposal())
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Equal(t, 1, results.Outcomes()[0].Attempts)
+	})
+
+	t.Run("already known block is published", func(t *testing.T) {
+		backend := newTestBackend(t, 2)
+		client := backend.beaconClient.(*MultiBeaconClient)
+
+		backend.beaconInstances[0].MockPublishBlock = func() (int, error) {
+			return http.StatusBadRequest, fmt.Errorf("%w: block already known", ErrHTTPErrorResponse)
+		}
+		backend.beaconInstances[1].MockPublishBlock = backend.beaconInstances[0].MockPublishBlock
+
+		code, results, err := client.PublishBlock(testSignedProposal())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		require.True(t, results.Outcomes()[0].AlreadyKnown)
+		require.Empty(t, results.Outcomes()[0].Error)
+	})
+}
+
+func TestPayloadRejection(t *testing.T) {
+	invalidPayload := "HTTP error response: Invalid block: ExecutionPayloadError(RejectedByExecutionEngine)"
+	tests := []struct {
+		name     string
+		outcomes []common.BeaconPublishOutcome
+		rejected bool
+	}{
+		{
+			name:     "invalid payload",
+			outcomes: []common.BeaconPublishOutcome{{Code: http.StatusBadRequest, Error: invalidPayload}},
+			rejected: true,
+		},
+		{
+			name:     "invalid payload in teku's format",
+			outcomes: []common.BeaconPublishOutcome{{Code: http.StatusBadRequest, Error: "HTTP error response: FAILED_EXECUTION_PAYLOAD_EXECUTION"}},
+			rejected: true,
+		},
+		{
+			name:     "invalid proposer signature",
+			outcomes: []common.BeaconPublishOutcome{{Code: http.StatusBadRequest, Error: "HTTP error response: Invalid block: ProposalSignatureInvalid"}},
+		},
+		{
+			name: "accepted by another beacon node",
+			outcomes: []common.BeaconPublishOutcome{
+				{Code: http.StatusBadRequest, Error: invalidPayload},
+				{Code: http.StatusOK},
+			},
+		},
+		{
+			name: "already known by another beacon node",
+			outcomes: []common.BeaconPublishOutcome{
+				{Code: http.StatusBadRequest, Error: invalidPayload},
+				{Code: http.StatusBadRequest, AlreadyKnown: true},
+			},
+		},
+		{
+			name: "broadcast but failed integration",
+			outcomes: []common.BeaconPublishOutcome{
+				{Code: http.StatusAccepted},
+				{Code: http.StatusBadRequest, Error: invalidPayload},
+			},
+			rejected: true,
+		},
+		{
+			name:     "only broadcast but failed integration",
+			outcomes: []common.BeaconPublishOutcome{{Code: http.StatusAccepted}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := new(PublishResults)
+			for _, outcome := range tt.outcomes {
+				results.add(outcome)
+			}
+			reason, rejected := results.PayloadRejection()
+			require.Equal(t, tt.rejected, rejected)
+			if rejected {
+				require.NotEmpty(t, reason)
+			}
+		})
+	}
+
+	// Not published by this instance
+	_, rejected := (*PublishResults)(nil).PayloadRejection()
+	require.False(t, rejected)
+}
@@ -8,11 +8,16 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/flashbots/mev-boost-relay/common"
 )
 
 var (
 	ErrHTTPErrorResponse     = errors.New("got an HTTP error response")
 	ErrInvalidRequestPayload = errors.New("invalid request payload")
+	ErrMissingNetworkInfo    = errors.New("missing genesis or spec info")
+	ErrNetworkMismatch       = errors.New("network config does not match beacon node")
+	ErrValidatorNotFound     = errors.New("validator not found")
 
 	StateIDHead      = "head"
 	StateIDGenesis   = "genesis"
@@ -90,3 +95,60 @@ func fetchBeacon(method, url string, payload []byte, dst any, httpClient *http.C
 
 	return resp.StatusCode, nil
 }
+
+// ValidateNetworkDetails checks that the network details of the relay match the genesis and spec of the beacon node.
+// This is used to catch misconfigured custom networks early. Forks which are unscheduled in the relay config are not
+// checked, and empty values on the beacon node side (i.e. forks unknown to the beacon node) are ignored.
+func ValidateNetworkDetails(networkDetails *common.EthNetworkDetails, genesis *GetGenesisResponse, spec *GetSpecResponse) error {
+	if genesis == nil || spec == nil {
+		return ErrMissingNetworkInfo
+	}
+
+	type check struct {
+		name     string
+		expected string
+		actual   string
+	}
+	checks := []check{
+		{"genesis_validators_root", networkDetails.GenesisValidatorsRootHex, genesis.Data.GenesisValidatorsRoot},
+		{"genesis_fork_version", networkDetails.GenesisForkVersionHex, genesis.Data.GenesisForkVersion},
+		{"genesis_fork_version", networkDetails.GenesisForkVersionHex, spec.Data.GenesisForkVersion},
+		{"bellatrix_fork_version", networkDetails.BellatrixForkVersionHex, spec.Data.BellatrixForkVersion},
+	}
+	if networkDetails.ForkSchedule.CapellaEpoch >= 0 {
+		checks = append(checks,
+			check{"capella_fork_version", networkDetails.CapellaForkVersionHex, spec.Data.CapellaForkVersion},
+			check{"capella_fork_epoch", fmt.Sprint(networkDetails.ForkSchedule.CapellaEpoch), specEpochString(spec.Data.CapellaForkVersion, spec.Data.CapellaForkEpoch)},
+		)
+	}
+	if networkDetails.ForkSchedule.DenebEpoch >= 0 {
+		checks = append(checks,
+			check{"deneb_fork_version", networkDetails.DenebForkVersionHex, spec.Data.DenebForkVersion},
+			check{"deneb_fork_epoch", fmt.Sprint(networkDetails.ForkSchedule.DenebEpoch), specEpochString(spec.Data.DenebForkVersion, spec.Data.DenebForkEpoch)},
+		)
+	}
+	if networkDetails.ForkSchedule.ElectraEpoch >= 0 {
+		checks = append(checks,
+			check{"electra_fork_version", networkDetails.ElectraForkVersionHex, spec.Data.ElectraForkVersion},
+			check{"electra_fork_epoch", fmt.Sprint(networkDetails.ForkSchedule.ElectraEpoch), specEpochString(spec.Data.ElectraForkVersion, spec.Data.ElectraForkEpoch)},
+		)
+	}
+
+	for _, c := range checks {
+		if c.actual == "" {
+			continue
+		}
+		if !strings.EqualFold(c.expected, c.actual) {
+			return fmt.Errorf("%w: %s is %s, but beacon node has %s", ErrNetworkMismatch, c.name, c.expected, c.actual)
+		}
+	}
+	return nil
+}
+
+// specEpochString returns the fork epoch of the spec as string, or an empty string if the fork is unknown to the beacon node
+func specEpochString(forkVersion string, epoch uint64) string {
+	if forkVersion == "" {
+		return ""
+	}
+	return fmt.Sprint(epoch)
+}
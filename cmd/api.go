@@ -1,17 +1,13 @@
 package cmd
 
 import (
-	"net/url"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/flashbots/go-boost-utils/bls"
-	"github.com/flashbots/mev-boost-relay/beaconclient"
 	"github.com/flashbots/mev-boost-relay/common"
-	"github.com/flashbots/mev-boost-relay/database"
 	"github.com/flashbots/mev-boost-relay/datastore"
 	"github.com/flashbots/mev-boost-relay/services/api"
 	"github.com/sirupsen/logrus"
@@ -25,6 +21,7 @@ var (
 	apiDefaultLogTag     = os.Getenv("LOG_TAG")
 
 	apiDefaultPprofEnabled       = os.Getenv("PPROF") == "1"
+	apiDefaultDiagnosticsAddr    = os.Getenv("DIAGNOSTICS_LISTEN_ADDR")
 	apiDefaultInternalAPIEnabled = os.Getenv("ENABLE_INTERNAL_API") == "1"
 
 	// Default Builder, Data, and Proposer API as true.
@@ -32,42 +29,103 @@ var (
 	apiDefaultDataAPIEnabled     = os.Getenv("DISABLE_DATA_API") != "1"
 	apiDefaultProposerAPIEnabled = os.Getenv("DISABLE_PROPOSER_API") != "1"
 
-	apiListenAddr   string
-	apiPprofEnabled bool
-	apiSecretKey    string
-	apiBlockSimURL  string
-	apiDebug        bool
-	apiBuilderAPI   bool
-	apiDataAPI      bool
-	apiInternalAPI  bool
-	apiProposerAPI  bool
-	apiLogTag       string
+	// Optional listener for trusted builder submissions
+	apiDefaultTrustedListenAddr   = os.Getenv("TRUSTED_LISTEN_ADDR")
+	apiDefaultTrustedTLSCert      = os.Getenv("TRUSTED_TLS_CERT")
+	apiDefaultTrustedTLSKey       = os.Getenv("TRUSTED_TLS_KEY")
+	apiDefaultTrustedClientCA     = os.Getenv("TRUSTED_CLIENT_CA")
+	apiDefaultTrustedBuildersFile = os.Getenv("TRUSTED_BUILDERS_FILE")
+
+	// Optional HTTP/3 listener for block submissions
+	apiDefaultHTTP3ListenAddr = os.Getenv("HTTP3_LISTEN_ADDR")
+	apiDefaultHTTP3TLSCert    = os.Getenv("HTTP3_TLS_CERT")
+	apiDefaultHTTP3TLSKey     = os.Getenv("HTTP3_TLS_KEY")
+
+	// Optional gRPC listener for block submissions
+	apiDefaultGRPCListenAddr = os.Getenv("GRPC_LISTEN_ADDR")
+	apiDefaultGRPCTLSCert    = os.Getenv("GRPC_TLS_CERT")
+	apiDefaultGRPCTLSKey     = os.Getenv("GRPC_TLS_KEY")
+
+	// Previous secret keys during a key rotation
+	apiDefaultOldSecretKeys = common.GetSliceEnv("OLD_SECRET_KEYS", nil)
+
+	// Relay mode and address blocklist (used in filtered mode)
+	apiDefaultRelayMode = common.GetEnv("RELAY_MODE", common.RelayModeMaxProfit)
+	apiDefaultBlocklist = os.Getenv("BLOCKLIST")
+
+	// Upstream relays for aggregation mode
+	apiDefaultUpstreamRelays = common.GetSliceEnv("UPSTREAM_RELAYS", nil)
+
+	apiListenAddr    string
+	apiPprofEnabled  bool
+	apiDiagnostics   string
+	apiSecretKey     string
+	apiOldSecretKeys []string
+	apiBlockSimURL   string
+	apiDebug         bool
+	apiBuilderAPI    bool
+	apiDataAPI       bool
+	apiInternalAPI   bool
+	apiProposerAPI   bool
+	apiLogTag        string
+
+	apiTrustedListenAddr   string
+	apiTrustedTLSCert      string
+	apiTrustedTLSKey       string
+	apiTrustedClientCA     string
+	apiTrustedBuildersFile string
+	apiHTTP3ListenAddr     string
+	apiHTTP3TLSCert        string
+	apiHTTP3TLSKey         string
+	apiGRPCListenAddr      string
+	apiGRPCTLSCert         string
+	apiGRPCTLSKey          string
+
+	apiRelayMode      string
+	apiBlocklist      string
+	apiUpstreamRelays []string
 )
 
 func init() {
 	rootCmd.AddCommand(apiCmd)
-	apiCmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
-	apiCmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+	addLogFlags(apiCmd)
 	apiCmd.Flags().StringVar(&apiLogTag, "log-tag", apiDefaultLogTag, "if set, a 'tag' field will be added to all log entries")
 	apiCmd.Flags().BoolVar(&apiDebug, "debug", false, "debug logging")
 
 	apiCmd.Flags().StringVar(&apiListenAddr, "listen-addr", apiDefaultListenAddr, "listen address for webserver")
-	apiCmd.Flags().StringSliceVar(&beaconNodeURIs, "beacon-uris", defaultBeaconURIs, "beacon endpoints")
-	apiCmd.Flags().StringSliceVar(&beaconNodePublishURIs, "beacon-publish-uris", defaultBeaconPublishURIs, "beacon publish endpoints")
-	apiCmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
-	apiCmd.Flags().StringVar(&redisReadonlyURI, "redis-readonly-uri", defaultRedisReadonlyURI, "redis readonly uri")
-	apiCmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
-	apiCmd.Flags().StringSliceVar(&memcachedURIs, "memcached-uris", defaultMemcachedURIs,
-		"Enable memcached, typically used as secondary backup to Redis for redundancy")
+	addNetworkFlag(apiCmd)
+	addBeaconFlags(apiCmd, true)
+	addRedisFlags(apiCmd, true)
+	addPostgresFlag(apiCmd)
+	addPostgresReplicaFlag(apiCmd)
+	addMemcachedFlag(apiCmd)
+	addRedisSecondaryFlag(apiCmd)
 	apiCmd.Flags().StringVar(&apiSecretKey, "secret-key", apiDefaultSecretKey, "secret key for signing bids")
+	apiCmd.Flags().StringSliceVar(&apiOldSecretKeys, "old-secret-keys", apiDefaultOldSecretKeys, "previous secret keys during a key rotation (not used for signing)")
 	apiCmd.Flags().StringVar(&apiBlockSimURL, "blocksim", apiDefaultBlockSim, "URL for block simulator")
-	apiCmd.Flags().StringVar(&network, "network", defaultNetwork, "Which network to use")
 
 	apiCmd.Flags().BoolVar(&apiPprofEnabled, "pprof", apiDefaultPprofEnabled, "enable pprof API")
+	apiCmd.Flags().StringVar(&apiDiagnostics, "diagnostics-listen-addr", apiDefaultDiagnosticsAddr, "private listen address for pprof, expvar and runtime diagnostics (disabled if empty)")
 	apiCmd.Flags().BoolVar(&apiBuilderAPI, "builder-api", apiDefaultBuilderAPIEnabled, "enable builder API (/builder/...)")
 	apiCmd.Flags().BoolVar(&apiDataAPI, "data-api", apiDefaultDataAPIEnabled, "enable data API (/data/...)")
 	apiCmd.Flags().BoolVar(&apiInternalAPI, "internal-api", apiDefaultInternalAPIEnabled, "enable internal API (/internal/...)")
 	apiCmd.Flags().BoolVar(&apiProposerAPI, "proposer-api", apiDefaultProposerAPIEnabled, "enable proposer API (/proposer/...)")
+
+	apiCmd.Flags().StringVar(&apiTrustedListenAddr, "trusted-listen-addr", apiDefaultTrustedListenAddr, "listen address for trusted builder submissions (disabled if empty)")
+	apiCmd.Flags().StringVar(&apiTrustedTLSCert, "trusted-tls-cert", apiDefaultTrustedTLSCert, "TLS certificate file for the trusted builder listener")
+	apiCmd.Flags().StringVar(&apiTrustedTLSKey, "trusted-tls-key", apiDefaultTrustedTLSKey, "TLS key file for the trusted builder listener")
+	apiCmd.Flags().StringVar(&apiTrustedClientCA, "trusted-client-ca", apiDefaultTrustedClientCA, "CA file to verify client certificates on the trusted builder listener")
+	apiCmd.Flags().StringVar(&apiHTTP3ListenAddr, "http3-listen-addr", apiDefaultHTTP3ListenAddr, "UDP listen address for block submissions over HTTP/3 (disabled if empty)")
+	apiCmd.Flags().StringVar(&apiHTTP3TLSCert, "http3-tls-cert", apiDefaultHTTP3TLSCert, "TLS certificate file for the HTTP/3 listener")
+	apiCmd.Flags().StringVar(&apiHTTP3TLSKey, "http3-tls-key", apiDefaultHTTP3TLSKey, "TLS key file for the HTTP/3 listener")
+	apiCmd.Flags().StringVar(&apiGRPCListenAddr, "grpc-listen-addr", apiDefaultGRPCListenAddr, "listen address for block submissions over gRPC (disabled if empty)")
+	apiCmd.Flags().StringVar(&apiGRPCTLSCert, "grpc-tls-cert", apiDefaultGRPCTLSCert, "TLS certificate file for the gRPC listener (plaintext if empty)")
+	apiCmd.Flags().StringVar(&apiGRPCTLSKey, "grpc-tls-key", apiDefaultGRPCTLSKey, "TLS key file for the gRPC listener")
+	apiCmd.Flags().StringVar(&apiTrustedBuildersFile, "trusted-builders-file", apiDefaultTrustedBuildersFile, "JSON file with the trusted builder identities (certificate common name / IPs -> builder pubkeys)")
+
+	apiCmd.Flags().StringVar(&apiRelayMode, "relay-mode", apiDefaultRelayMode, "relay mode: max_profit (accept all valid blocks) or filtered (reject blocks with blocklisted addresses)")
+	apiCmd.Flags().StringVar(&apiBlocklist, "blocklist", apiDefaultBlocklist, "file or URL of the address blocklist (required in filtered mode)")
+	apiCmd.Flags().StringSliceVar(&apiUpstreamRelays, "upstream-relays", apiDefaultUpstreamRelays, "upstream relays (https://0x<relay pubkey>@<host>) whose bids are merged with the local bids (aggregation mode)")
 }
 
 var apiCmd = &cobra.Command{
@@ -89,63 +147,15 @@ var apiCmd = &cobra.Command{
 		}
 		log.Infof("boost-relay %s", Version)
 
-		networkInfo, err := common.NewEthNetworkDetails(network)
-		if err != nil {
-			log.WithError(err).Fatalf("error getting network details")
-		}
-		log.Infof("Using network: %s", networkInfo.Name)
-		log.Debug(networkInfo.String())
-
-		// Connect to beacon clients and ensure it's synced
-		if len(beaconNodeURIs) == 0 {
-			log.Fatalf("no beacon endpoints specified")
-		}
-		log.Infof("Using beacon endpoints: %s", strings.Join(beaconNodeURIs, ", "))
-		if len(beaconNodePublishURIs) == 0 {
-			// default to same endpoint as the beacon endpoints
-			beaconNodePublishURIs = beaconNodeURIs
-		} else if len(beaconNodePublishURIs) != len(beaconNodeURIs) {
-			log.Fatalf("beacon publish endpoints do not match the number of beacon endpoints")
-		} else {
-			log.Infof("Using beacon publish endpoints: %s", strings.Join(beaconNodePublishURIs, ", "))
-		}
-
-		var beaconInstances []beaconclient.IBeaconInstance
-		for i, uri := range beaconNodeURIs {
-			beaconInstances = append(beaconInstances, beaconclient.NewProdBeaconInstance(log, uri, beaconNodePublishURIs[i]))
-		}
-		beaconClient := beaconclient.NewMultiBeaconClient(log, beaconInstances)
-
-		// Connect to Redis
-		if redisReadonlyURI == "" {
-			log.Infof("Connecting to Redis at %s ...", redisURI)
-		} else {
-			log.Infof("Connecting to Redis at %s / readonly: %s ...", redisURI, redisReadonlyURI)
-		}
-		redis, err := datastore.NewRedisCache(networkInfo.Name, redisURI, redisReadonlyURI)
-		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
-		}
-
-		// Connect to Memcached if it exists
-		var mem *datastore.Memcached
-		if len(memcachedURIs) > 0 {
-			log.Infof("Connecting to Memcached at %s ...", strings.Join(memcachedURIs, ", "))
-			mem, err = datastore.NewMemcached(networkInfo.Name, memcachedURIs...)
-			if err != nil {
-				log.WithError(err).Fatalf("Failed to connect to Memcached")
-			}
-		}
+		networkInfo := setupNetwork(log)
+		beaconClient := setupBeaconClient(log)
+		redis := setupRedis(log, networkInfo)
+		mem := setupMemcached(log, networkInfo)
+		db := setupPostgres(log)
 
-		// Connect to Postgres
-		dbURL, err := url.Parse(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("couldn't read db URL")
-		}
-		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
-		db, err := database.NewDatabaseService(postgresDSN)
+		tunables, err := loadTunables(cmd, config)
 		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+			log.WithError(err).Fatal("invalid tunables in config file")
 		}
 
 		log.Info("Setting up datastore...")
@@ -153,6 +163,9 @@ var apiCmd = &cobra.Command{
 		if err != nil {
 			log.WithError(err).Fatalf("Failed setting up prod datastore")
 		}
+		for _, backend := range setupRedisSecondaries(log, networkInfo) {
+			ds.AddCacheBackend(backend)
+		}
 
 		opts := api.RelayAPIOpts{
 			Log:           log,
@@ -161,15 +174,58 @@ var apiCmd = &cobra.Command{
 			Datastore:     ds,
 			Redis:         redis,
 			Memcached:     mem,
-			DB:            db,
+			DB:            withReadReplica(log, db),
 			EthNetDetails: *networkInfo,
 			BlockSimURL:   apiBlockSimURL,
+			Version:       Version,
 
 			BlockBuilderAPI: apiBuilderAPI,
 			DataAPI:         apiDataAPI,
 			InternalAPI:     apiInternalAPI,
 			ProposerAPI:     apiProposerAPI,
 			PprofAPI:        apiPprofEnabled,
+
+			DiagnosticsListenAddr: apiDiagnostics,
+
+			RelayMode: apiRelayMode,
+			Blocklist: tunables.Blocklist,
+
+			Tunables:       &tunables,
+			FeatureFlags:   config.FeatureFlags,
+			UpstreamRelays: apiUpstreamRelays,
+		}
+
+		if apiTrustedListenAddr != "" {
+			identities, err := api.LoadTrustedBuilderIdentities(apiTrustedBuildersFile)
+			if err != nil {
+				log.WithError(err).Fatal("failed to load trusted builders file")
+			}
+			opts.TrustedBuilderListener = &api.TrustedBuilderListenerOpts{
+				ListenAddr:   apiTrustedListenAddr,
+				TLSCertFile:  apiTrustedTLSCert,
+				TLSKeyFile:   apiTrustedTLSKey,
+				ClientCAFile: apiTrustedClientCA,
+				Identities:   identities,
+			}
+		}
+
+		if apiHTTP3ListenAddr != "" {
+			if apiHTTP3TLSCert == "" || apiHTTP3TLSKey == "" {
+				log.Fatal("HTTP/3 listener requires --http3-tls-cert and --http3-tls-key")
+			}
+			opts.HTTP3Listener = &api.HTTP3ListenerOpts{
+				ListenAddr:  apiHTTP3ListenAddr,
+				TLSCertFile: apiHTTP3TLSCert,
+				TLSKeyFile:  apiHTTP3TLSKey,
+			}
+		}
+
+		if apiGRPCListenAddr != "" {
+			opts.GRPCListener = &api.GRPCListenerOpts{
+				ListenAddr:  apiGRPCListenAddr,
+				TLSCertFile: apiGRPCTLSCert,
+				TLSKeyFile:  apiGRPCTLSKey,
+			}
 		}
 
 		// Decode the private key
@@ -185,6 +241,20 @@ var apiCmd = &cobra.Command{
 			if err != nil {
 				log.WithError(err).Fatal("incorrect builder API secret key provided")
 			}
+			for _, oldSecretKey := range apiOldSecretKeys {
+				if oldSecretKey == "" {
+					continue
+				}
+				oldSkBytes, err := hexutil.Decode(oldSecretKey)
+				if err != nil {
+					log.WithError(err).Fatal("incorrect old secret key provided")
+				}
+				oldSk, err := bls.SecretKeyFromBytes(oldSkBytes)
+				if err != nil {
+					log.WithError(err).Fatal("incorrect old secret key provided")
+				}
+				opts.OldSecretKeys = append(opts.OldSecretKeys, oldSk)
+			}
 		}
 
 		// Create the relay service
@@ -206,6 +276,26 @@ var apiCmd = &cobra.Command{
 			}
 		}()
 
+		// Reload the tunables from the config file on SIGHUP
+		hups := make(chan os.Signal, 1)
+		signal.Notify(hups, syscall.SIGHUP)
+		go func() {
+			for range hups {
+				if configFile == "" {
+					log.Warn("SIGHUP received, but no config file is used")
+					continue
+				}
+				log.Infof("SIGHUP received, reloading tunables from %s", configFile)
+				tunables, err := reloadTunables(cmd)
+				if err == nil {
+					err = srv.UpdateTunables(tunables)
+				}
+				if err != nil {
+					log.WithError(err).Error("failed to reload tunables, keeping the previous ones")
+				}
+			}
+		}()
+
 		// Start the server
 		log.Infof("Webserver starting on %s ...", apiListenAddr)
 		err = srv.StartServer()
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/services/api"
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrUnknownConfigFlag = errors.New("unknown flag in config file")
+
+	defaultConfigFile = common.GetEnv("CONFIG_FILE", "")
+
+	configFile string
+	config     = &relayConfig{}
+
+	// environment variables of the flags, which take precedence over the config file
+	flagEnvVars = map[string]string{
+		"json":                    "LOG_JSON",
+		"loglevel":                "LOG_LEVEL",
+		"log-tag":                 "LOG_TAG",
+		"network":                 "NETWORK",
+		"listen-addr":             "LISTEN_ADDR",
+		"beacon-uris":             "BEACON_URIS",
+		"beacon-publish-uris":     "BEACON_PUBLISH_URIS",
+		"redis-uri":               "REDIS_URI",
+		"redis-readonly-uri":      "REDIS_READONLY_URI",
+		"redis-mode":              "REDIS_MODE",
+		"redis-sentinel-master":   "REDIS_SENTINEL_MASTER",
+		"redis-username":          "REDIS_USERNAME",
+		"redis-password":          "REDIS_PASSWORD",
+		"redis-tls":               "REDIS_TLS",
+		"redis-pool-size":         "REDIS_CONNECTION_POOL_SIZE",
+		"redis-min-idle-conns":    "REDIS_MIN_IDLE_CONNECTIONS",
+		"redis-read-timeout-sec":  "REDIS_READ_TIMEOUT_SEC",
+		"redis-write-timeout-sec": "REDIS_WRITE_TIMEOUT_SEC",
+		"redis-pool-timeout-sec":  "REDIS_POOL_TIMEOUT_SEC",
+		"db":                      "POSTGRES_DSN",
+		"db-replica":              "POSTGRES_REPLICA_DSN",
+		"memcached-uris":          "MEMCACHED_URIS",
+		"redis-secondary-uris":    "REDIS_SECONDARY_URIS",
+		"secret-key":              "SECRET_KEY",
+		"old-secret-keys":         "OLD_SECRET_KEYS",
+		"blocksim":                "BLOCKSIM_URI",
+		"pprof":                   "PPROF",
+		"pprof-listen-addr":       "PPROF_LISTEN_ADDR",
+		"diagnostics-listen-addr": "DIAGNOSTICS_LISTEN_ADDR",
+		"builder-api":             "DISABLE_BUILDER_API",
+		"data-api":                "DISABLE_DATA_API",
+		"proposer-api":            "DISABLE_PROPOSER_API",
+		"internal-api":            "ENABLE_INTERNAL_API",
+		"trusted-listen-addr":     "TRUSTED_LISTEN_ADDR",
+		"trusted-tls-cert":        "TRUSTED_TLS_CERT",
+		"trusted-tls-key":         "TRUSTED_TLS_KEY",
+		"trusted-client-ca":       "TRUSTED_CLIENT_CA",
+		"trusted-builders-file":   "TRUSTED_BUILDERS_FILE",
+		"http3-listen-addr":       "HTTP3_LISTEN_ADDR",
+		"http3-tls-cert":          "HTTP3_TLS_CERT",
+		"http3-tls-key":           "HTTP3_TLS_KEY",
+		"grpc-listen-addr":        "GRPC_LISTEN_ADDR",
+		"grpc-tls-cert":           "GRPC_TLS_CERT",
+		"grpc-tls-key":            "GRPC_TLS_KEY",
+		"relay-mode":              "RELAY_MODE",
+		"blocklist":               "BLOCKLIST",
+		"execution-uri":           "EXECUTION_URI",
+		"pubkey-override":         "PUBKEY_OVERRIDE",
+		"show-config-details":     "SHOW_CONFIG_DETAILS",
+		"link-beaconchain":        "LINK_BEACONCHAIN",
+		"link-etherscan":          "LINK_ETHERSCAN",
+		"link-data-api":           "LINK_DATA_API",
+		"relay-url":               "RELAY_URL",
+	}
+)
+
+// relayConfig is the YAML config file. Values set on the command line or in the environment take precedence.
+type relayConfig struct {
+	// Flag values by flag name, i.e. listen addresses and datastore URIs (see --help of the subcommands)
+	Flags map[string]any `yaml:"flags"`
+
+	// Tunables of the API by name (see api.Tunables), which are reloaded on SIGHUP
+	Tunables map[string]any `yaml:"tunables"`
+
+	// Feature flags of the API by environment variable name, i.e. ENABLE_BUILDER_CANCELLATIONS
+	FeatureFlags map[string]bool `yaml:"feature_flags"`
+}
+
+func loadConfigFile(fn string) (*relayConfig, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &relayConfig{}
+	err = yaml.UnmarshalWithOptions(data, cfg, yaml.Strict())
+	return cfg, err
+}
+
+// applyConfigFlags sets the flags of the command from the config file, unless they are set on the command line or in
+// the environment
+func applyConfigFlags(cmd *cobra.Command, cfg *relayConfig) error {
+	for name, value := range cfg.Flags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			// flags of other subcommands are fine, as the config file is shared
+			if !isKnownFlag(cmd.Root(), name) {
+				return fmt.Errorf("%w: %s", ErrUnknownConfigFlag, name)
+			}
+			continue
+		}
+		if flag.Changed || isEnvSet(flagEnvVars[name]) {
+			continue
+		}
+
+		strValue := fmt.Sprint(value)
+		if values, ok := value.([]any); ok {
+			strValues := make([]string, len(values))
+			for i, v := range values {
+				strValues[i] = fmt.Sprint(v)
+			}
+			strValue = strings.Join(strValues, ",")
+		}
+		if err := cmd.Flags().Set(name, strValue); err != nil {
+			return fmt.Errorf("invalid value for %s in config file: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func isKnownFlag(root *cobra.Command, name string) bool {
+	for _, cmd := range root.Commands() {
+		if cmd.Flags().Lookup(name) != nil {
+			return true
+		}
+		for _, subCmd := range cmd.Commands() {
+			if subCmd.Flags().Lookup(name) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isEnvSet(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, ok := os.LookupEnv(name)
+	return ok
+}
+
+// loadTunables returns the API tunables: the defaults from the environment and the --blocklist flag, with the values
+// of the config file for those which are not set in the environment or on the command line
+func loadTunables(cmd *cobra.Command, cfg *relayConfig) (api.Tunables, error) {
+	tunables := api.DefaultTunables()
+	tunables.Blocklist = apiBlocklist
+
+	overrides := make(map[string]any)
+	for name, value := range cfg.Tunables {
+		if isEnvSet(strings.ToUpper(name)) {
+			continue
+		}
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			continue
+		}
+		overrides[name] = value
+	}
+	if len(overrides) == 0 {
+		return tunables, nil
+	}
+
+	data, err := yaml.Marshal(overrides)
+	if err != nil {
+		return tunables, err
+	}
+	err = yaml.UnmarshalWithOptions(data, &tunables, yaml.Strict())
+	return tunables, err
+}
+
+// reloadTunables reads the config file again, and returns the tunables from it
+func reloadTunables(cmd *cobra.Command) (api.Tunables, error) {
+	cfg, err := loadConfigFile(configFile)
+	if err != nil {
+		return api.Tunables{}, err
+	}
+	return loadTunables(cmd, cfg)
+}
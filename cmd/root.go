@@ -16,6 +16,20 @@ var rootCmd = &cobra.Command{
 		fmt.Printf("mev-boost-relay %s\n", Version)
 		_ = cmd.Help()
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		if configFile == "" {
+			return nil
+		}
+		config, err = loadConfigFile(configFile)
+		if err != nil {
+			return fmt.Errorf("failed to load config file %s: %w", configFile, err)
+		}
+		return applyConfigFlags(cmd, config)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", defaultConfigFile, "YAML config file (values on the command line and in the environment take precedence)")
 }
 
 func Execute() {
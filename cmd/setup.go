@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// The setup helpers below are shared by the subcommands, and exit if the respective flags are invalid or the
+// connection fails.
+
+func setupNetwork(log *logrus.Entry) *common.EthNetworkDetails {
+	networkInfo, err := common.NewEthNetworkDetails(network)
+	if err != nil {
+		log.WithError(err).Fatalf("error getting network details")
+	}
+	log.Infof("Using network: %s", networkInfo.Name)
+	log.Debug(networkInfo.String())
+	return networkInfo
+}
+
+// setupBeaconClient connects to the beacon nodes, publishing blocks through the --beacon-publish-uris if set
+func setupBeaconClient(log *logrus.Entry) *beaconclient.MultiBeaconClient {
+	if len(beaconNodeURIs) == 0 {
+		log.Fatalf("no beacon endpoints specified")
+	}
+	log.Infof("Using beacon endpoints: %s", strings.Join(beaconNodeURIs, ", "))
+	if len(beaconNodePublishURIs) == 0 {
+		// default to same endpoint as the beacon endpoints
+		beaconNodePublishURIs = beaconNodeURIs
+	} else if len(beaconNodePublishURIs) != len(beaconNodeURIs) {
+		log.Fatalf("beacon publish endpoints do not match the number of beacon endpoints")
+	} else {
+		log.Infof("Using beacon publish endpoints: %s", strings.Join(beaconNodePublishURIs, ", "))
+	}
+
+	var beaconInstances []beaconclient.IBeaconInstance
+	for i, uri := range beaconNodeURIs {
+		beaconInstances = append(beaconInstances, beaconclient.NewProdBeaconInstance(log, uri, beaconNodePublishURIs[i]))
+	}
+	return beaconclient.NewMultiBeaconClient(log, beaconInstances)
+}
+
+func setupRedis(log *logrus.Entry, networkInfo *common.EthNetworkDetails) *datastore.RedisCache {
+	if redisReadonlyURI == "" {
+		log.Infof("Connecting to Redis at %s ...", redisURI)
+	} else {
+		log.Infof("Connecting to Redis at %s / readonly: %s ...", redisURI, redisReadonlyURI)
+	}
+	redis, err := datastore.NewRedisCacheWithOpts(networkInfo.Name, getRedisOpts())
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
+	}
+	return redis
+}
+
+// setupRedisSecondaries connects to the --redis-secondary-uris, which are standalone Redis instances with the
+// credentials in the URI, and returns them as cache backends
+func setupRedisSecondaries(log *logrus.Entry, networkInfo *common.EthNetworkDetails) []datastore.CacheBackend {
+	backends := make([]datastore.CacheBackend, 0, len(redisSecondaryURIs))
+	for i, uri := range redisSecondaryURIs {
+		name := fmt.Sprintf("redis-secondary-%d", i+1)
+		log.Infof("Connecting to secondary Redis %s ...", name)
+		redis, err := datastore.NewRedisCacheWithOpts(networkInfo.Name, datastore.RedisOpts{
+			URI:          uri,
+			PoolSize:     redisPoolSize,
+			MinIdleConns: redisMinIdleConns,
+			ReadTimeout:  time.Duration(redisReadTimeoutSec) * time.Second,
+			WriteTimeout: time.Duration(redisWriteTimeoutSec) * time.Second,
+			PoolTimeout:  time.Duration(redisPoolTimeoutSec) * time.Second,
+		})
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to secondary Redis %s", name)
+		}
+		backends = append(backends, datastore.NewRedisSecondaryBackend(name, redis))
+	}
+	return backends
+}
+
+// setupMemcached connects to memcached, or returns nil if no --memcached-uris are set
+func setupMemcached(log *logrus.Entry, networkInfo *common.EthNetworkDetails) *datastore.Memcached {
+	if len(memcachedURIs) == 0 {
+		return nil
+	}
+	log.Infof("Connecting to Memcached at %s ...", strings.Join(memcachedURIs, ", "))
+	mem, err := datastore.NewMemcached(networkInfo.Name, memcachedURIs...)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to connect to Memcached")
+	}
+	return mem
+}
+
+func setupPostgres(log *logrus.Entry) *database.DatabaseService {
+	dbURL, err := url.Parse(postgresDSN)
+	if err != nil {
+		log.WithError(err).Fatalf("couldn't read db URL")
+	}
+	log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
+	db, err := database.NewDatabaseService(postgresDSN)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+	}
+	return db
+}
+
+// withReadReplica routes the read-only queries of the data API and the website to the --db-replica, if it is set
+func withReadReplica(log *logrus.Entry, primary *database.DatabaseService) database.IDatabaseService {
+	if postgresReplicaDSN == "" {
+		return primary
+	}
+	dbURL, err := url.Parse(postgresReplicaDSN)
+	if err != nil {
+		log.WithError(err).Fatalf("couldn't read db replica URL")
+	}
+	log.Infof("Using Postgres read replica at %s%s", dbURL.Host, dbURL.Path)
+	replica, err := database.NewReadReplicaDatabaseService(postgresReplicaDSN)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to open Postgres read replica at %s%s", dbURL.Host, dbURL.Path)
+	}
+	return database.NewReplicaDatabaseService(log, primary, replica)
+}
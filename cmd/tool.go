@@ -10,8 +10,15 @@ import (
 func init() {
 	toolCmd.AddCommand(tool.DataAPIExportPayloads)
 	toolCmd.AddCommand(tool.DataAPIExportBids)
+	toolCmd.AddCommand(tool.DataAPIExport)
 	toolCmd.AddCommand(tool.ArchiveExecutionPayloads)
+	toolCmd.AddCommand(tool.ArchivePayloads)
+	toolCmd.AddCommand(tool.PruneExecutionPayloads)
 	toolCmd.AddCommand(tool.Migrate)
+	toolCmd.AddCommand(tool.MigrateCrossRelay)
+	toolCmd.AddCommand(tool.ReplaySubmissions)
+	toolCmd.AddCommand(tool.Backfill)
+	toolCmd.AddCommand(tool.Registrations)
 	rootCmd.AddCommand(toolCmd)
 }
 
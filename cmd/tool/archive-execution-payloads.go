@@ -3,7 +3,6 @@ package tool
 import (
 	"encoding/csv"
 	"encoding/json"
-	"net/url"
 	"os"
 	"strings"
 
@@ -15,7 +14,7 @@ import (
 var doDelete bool
 
 func init() {
-	ArchiveExecutionPayloads.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	addPostgresFlag(ArchiveExecutionPayloads)
 	ArchiveExecutionPayloads.Flags().Uint64Var(&idFirst, "id-from", 0, "start id (inclusive")
 	ArchiveExecutionPayloads.Flags().Uint64Var(&idLast, "id-to", 0, "end id (inclusive)")
 	ArchiveExecutionPayloads.Flags().StringVar(&dateStart, "date-start", "", "start date (inclusive)")
@@ -38,22 +37,13 @@ var ArchiveExecutionPayloads = &cobra.Command{
 			log.Fatal("must specify --id-to or --date-end")
 		}
 
-		// Connect to Postgres
-		dbURL, err := url.Parse(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("couldn't read db URL")
-		}
-		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
-		db, err := database.NewDatabaseService(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
-		}
+		db := setupPostgres()
 
 		// if date, then find corresponding id
 		if dateStart != "" {
 			// find first enrty at or after dateStart
 			query := `SELECT id FROM ` + vars.TableExecutionPayload + ` WHERE inserted_at::date >= date '` + dateStart + `' ORDER BY id ASC LIMIT 1;`
-			err = db.DB.QueryRow(query).Scan(&idFirst)
+			err := db.DB.QueryRow(query).Scan(&idFirst)
 			if err != nil {
 				log.WithError(err).Fatalf("failed to find start id for date %s", dateStart)
 			}
@@ -61,7 +51,7 @@ var ArchiveExecutionPayloads = &cobra.Command{
 		if dateEnd != "" {
 			// find last enry before dateEnd
 			query := `SELECT id FROM ` + vars.TableExecutionPayload + ` WHERE inserted_at::date < date '` + dateEnd + `' ORDER BY id DESC LIMIT 1;`
-			err = db.DB.QueryRow(query).Scan(&idLast)
+			err := db.DB.QueryRow(query).Scan(&idLast)
 			if err != nil {
 				log.WithError(err).Fatalf("failed to find end id for date %s", dateEnd)
 			}
@@ -112,6 +102,17 @@ var ArchiveExecutionPayloads = &cobra.Command{
 			writeToFile(outFile)
 		}
 
+		// record the archive progress, which the compaction of the housekeeper waits for if required
+		archivedID, err := db.GetBackfillProgress(database.ProgressArchiveExecutionPayloads)
+		if err != nil {
+			log.WithError(err).Fatal("error getting archive progress")
+		}
+		if int64(idLast) > archivedID {
+			if err := db.SetBackfillProgress(database.ProgressArchiveExecutionPayloads, int64(idLast)); err != nil {
+				log.WithError(err).Fatal("error storing archive progress")
+			}
+		}
+
 		if doDelete {
 			log.Infof("deleting archived payloads from DB")
 			err = db.DeleteExecutionPayloads(idFirst, idLast)
@@ -0,0 +1,227 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/spf13/cobra"
+)
+
+// GCS is written to through its S3 compatible XML API, with HMAC keys as AWS credentials
+var gcsEndpoint = "https://storage.googleapis.com"
+
+var (
+	archiveFormat   string
+	archiveOutDir   string
+	archiveUploadTo string
+	archiveDelete   bool
+)
+
+func init() {
+	addPostgresFlag(ArchivePayloads)
+	ArchivePayloads.Flags().Uint64Var(&slotFrom, "slot-from", 0, "start slot (inclusive")
+	ArchivePayloads.Flags().Uint64Var(&slotTo, "slot-to", 0, "end slot (inclusive)")
+	ArchivePayloads.Flags().StringVar(&archiveFormat, "format", "csv", "output format (csv, json or parquet)")
+	ArchivePayloads.Flags().StringVar(&archiveOutDir, "out-dir", ".", "output directory")
+	ArchivePayloads.Flags().StringVar(&archiveUploadTo, "upload-to", "", "optional object storage destination (s3://bucket/prefix or gs://bucket/prefix), with the credentials of the AWS environment (HMAC keys for GCS)")
+	ArchivePayloads.Flags().BoolVar(&archiveDelete, "delete", false, "whether to delete the exported rows from the DB after a verified upload (requires --upload-to)")
+}
+
+var ArchivePayloads = &cobra.Command{
+	Use:   "archive-payloads",
+	Short: "export delivered payloads and builder submissions for a slot range to CSV, JSON or Parquet files, optionally upload them to S3/GCS and delete the rows from the DB",
+	Run: func(cmd *cobra.Command, args []string) {
+		if slotFrom == 0 || slotTo == 0 || slotTo < slotFrom {
+			log.Fatal("must specify --slot-from and --slot-to")
+		}
+		if archiveFormat != "csv" && archiveFormat != "json" && archiveFormat != "parquet" {
+			log.Fatalf("unsupported format '%s', must be csv, json or parquet", archiveFormat)
+		}
+		if archiveUploadTo != "" && !strings.HasPrefix(archiveUploadTo, "s3://") && !strings.HasPrefix(archiveUploadTo, "gs://") {
+			log.Fatalf("unsupported upload destination '%s', must start with s3:// or gs://", archiveUploadTo)
+		}
+		if archiveDelete && archiveUploadTo == "" {
+			log.Fatal("--delete requires --upload-to, rows are only deleted after the files are uploaded")
+		}
+
+		db := setupPostgres()
+
+		log.Infof("archiving slots %d to %d (%d slots in total)...", slotFrom, slotTo, slotTo-slotFrom+1)
+
+		payloadsFn, numPayloads, bidsFn, numBids := exportSlotRange(db, archiveOutDir, archiveFormat)
+
+		if archiveUploadTo != "" {
+			uploadArchiveFile(payloadsFn)
+			uploadArchiveFile(bidsFn)
+		}
+
+		if archiveDelete {
+			// Only delete if the rows are still the exported ones, i.e. no late writes for the slot range
+			deliveredPayloads, err := db.GetDeliveredPayloadsBySlots(slotFrom, slotTo)
+			if err != nil {
+				log.WithError(err).Fatal("error getting delivered payloads")
+			}
+			bids, err := db.GetBuilderSubmissionsBySlots(slotFrom, slotTo)
+			if err != nil {
+				log.WithError(err).Fatal("error getting builder submissions")
+			}
+			if len(deliveredPayloads) != numPayloads || len(bids) != numBids {
+				log.Fatalf("rows changed since the export (delivered payloads: %d exported, %d now; builder submissions: %d exported, %d now), not deleting", numPayloads, len(deliveredPayloads), numBids, len(bids))
+			}
+
+			log.Info("deleting archived rows from DB")
+			numDeleted, err := db.DeleteDeliveredPayloadsBySlots(slotFrom, slotTo)
+			if err != nil {
+				log.WithError(err).Fatal("error deleting delivered payloads")
+			}
+			log.Infof("deleted %d delivered payloads", numDeleted)
+
+			numDeleted, err = db.DeleteBuilderSubmissionsBySlots(slotFrom, slotTo)
+			if err != nil {
+				log.WithError(err).Fatal("error deleting builder submissions")
+			}
+			log.Infof("deleted %d builder submissions", numDeleted)
+		}
+
+		log.Infof("all done")
+	},
+}
+
+// exportSlotRange writes the delivered payloads and builder submissions of the slot range to files (csv, json or
+// parquet) in the output directory, and returns the filenames and number of rows
+func exportSlotRange(db *database.DatabaseService, outDir, format string) (payloadsFn string, numPayloads int, bidsFn string, numBids int) {
+	// Delivered payloads
+	deliveredPayloads, err := db.GetDeliveredPayloadsBySlots(slotFrom, slotTo)
+	if err != nil {
+		log.WithError(err).Fatal("error getting delivered payloads")
+	}
+	log.Infof("got %d delivered payloads", len(deliveredPayloads))
+	payloadEntries := make([]common.BidTraceV2JSON, len(deliveredPayloads))
+	payloadRecords := make([][]string, len(deliveredPayloads))
+	for i, payload := range deliveredPayloads {
+		payloadEntries[i] = database.DeliveredPayloadEntryToBidTraceV2JSON(payload)
+		payloadRecords[i] = payloadEntries[i].ToCSVRecord()
+	}
+	payloadsFn = fmt.Sprintf("delivered-payloads_slot-%d-to-%d.%s", slotFrom, slotTo, format)
+	writeArchiveFile(outDir, format, payloadsFn, new(common.BidTraceV2JSON).CSVHeader(), payloadRecords, payloadEntries)
+
+	// Builder submissions
+	bids, err := db.GetBuilderSubmissionsBySlots(slotFrom, slotTo)
+	if err != nil {
+		log.WithError(err).Fatal("error getting builder submissions")
+	}
+	log.Infof("got %d builder submissions", len(bids))
+	bidEntries := make([]common.BidTraceV2WithTimestampJSON, len(bids))
+	bidRecords := make([][]string, len(bids))
+	for i, bid := range bids {
+		bidEntries[i] = database.BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(bid)
+		bidRecords[i] = bidEntries[i].ToCSVRecord()
+	}
+	bidsFn = fmt.Sprintf("builder-submissions_slot-%d-to-%d.%s", slotFrom, slotTo, format)
+	writeArchiveFile(outDir, format, bidsFn, new(common.BidTraceV2WithTimestampJSON).CSVHeader(), bidRecords, bidEntries)
+	return payloadsFn, len(deliveredPayloads), bidsFn, len(bids)
+}
+
+func writeArchiveFile(outDir, format, fn string, csvHeader []string, csvRecords [][]string, jsonEntries any) {
+	outFile := filepath.Join(outDir, fn)
+	f, err := os.Create(outFile)
+	if err != nil {
+		log.WithError(err).Fatal("failed to open file")
+	}
+	defer f.Close()
+
+	switch format {
+	case "csv":
+		w := csv.NewWriter(f)
+		if err := w.Write(csvHeader); err != nil {
+			log.WithError(err).Fatal("error writing record to file")
+		}
+		if err := w.WriteAll(csvRecords); err != nil {
+			log.WithError(err).Fatal("error writing records to file")
+		}
+	case "parquet":
+		if err := writeParquetFile(f, csvHeader, csvRecords); err != nil {
+			log.WithError(err).Fatal("error writing parquet file")
+		}
+	default:
+		encoder := json.NewEncoder(f)
+		if err := encoder.Encode(jsonEntries); err != nil {
+			log.WithError(err).Fatal("failed to write json to file")
+		}
+	}
+	log.Infof("Wrote %d entries to %s", len(csvRecords), outFile)
+}
+
+// uploadArchiveFile uploads the file to the --upload-to destination, and verifies the size of the uploaded object
+func uploadArchiveFile(fn string) {
+	src := filepath.Join(archiveOutDir, fn)
+	dst := strings.TrimSuffix(archiveUploadTo, "/") + "/" + fn
+	log := log.WithField("file", src).WithField("destination", dst)
+
+	dstURL, err := url.Parse(dst)
+	if err != nil {
+		log.WithError(err).Fatal("invalid upload destination")
+	}
+	bucket, key := dstURL.Host, strings.TrimPrefix(dstURL.Path, "/")
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		log.WithError(err).Fatal("failed to read file")
+	}
+	contentMD5 := md5.Sum(data) //nolint:gosec
+
+	ctx := context.Background()
+	client, err := newArchiveS3Client(ctx, dstURL.Scheme)
+	if err != nil {
+		log.WithError(err).Fatal("failed to create object storage client")
+	}
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		ContentMD5:    aws.String(base64.StdEncoding.EncodeToString(contentMD5[:])), // rejected by the server if corrupted
+	})
+	if err != nil {
+		log.WithError(err).Fatal("failed to upload file")
+	}
+
+	// Read back the object metadata, to not rely on the upload response alone before deleting rows
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		log.WithError(err).Fatal("failed to read back uploaded file")
+	}
+	if size := aws.ToInt64(head.ContentLength); size != int64(len(data)) {
+		log.Fatalf("uploaded file has %d bytes instead of %d", size, len(data))
+	}
+	log.Infof("Uploaded %s to %s", src, dst)
+}
+
+// newArchiveS3Client returns an S3 client for s3:// destinations, or for gs:// destinations through the S3
+// compatible API of GCS
+func newArchiveS3Client(ctx context.Context, scheme string) (*s3.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if scheme == "gs" {
+			o.BaseEndpoint = aws.String(gcsEndpoint)
+			o.Region = "auto"
+		}
+	}), nil
+}
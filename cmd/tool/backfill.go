@@ -0,0 +1,99 @@
+package tool
+
+import (
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// name of the backfill in the progress table
+var backfillBuilderSubmissions = "builder_submissions"
+
+var (
+	backfillBatchSize   uint64
+	backfillBatchDelay  uint64
+	backfillRestart     bool
+	backfillDryRun      bool
+	backfillMaxBatches  uint64
+	backfillDefaultSize = uint64(500)
+)
+
+func init() {
+	addPostgresFlag(Backfill)
+	Backfill.Flags().Uint64Var(&backfillBatchSize, "batch-size", backfillDefaultSize, "number of block submissions per batch")
+	Backfill.Flags().Uint64Var(&backfillBatchDelay, "batch-delay-ms", 100, "pause between batches in milliseconds")
+	Backfill.Flags().Uint64Var(&backfillMaxBatches, "max-batches", 0, "stop after this many batches (0 for no limit), the next run resumes from there")
+	Backfill.Flags().BoolVar(&backfillRestart, "restart", false, "start from the first block submission instead of resuming")
+	Backfill.Flags().BoolVar(&backfillDryRun, "dry-run", false, "only report the changes and mismatches, don't update the rows or the progress")
+}
+
+var Backfill = &cobra.Command{
+	Use:   "backfill",
+	Short: "recompute the derived columns of block submissions (num_tx, num_blobs) from their stored execution payloads, and report mismatches between bid traces and payloads",
+	Run: func(cmd *cobra.Command, args []string) {
+		if backfillBatchSize == 0 {
+			log.Fatal("--batch-size must be greater than 0")
+		}
+
+		db := setupPostgres()
+
+		var lastID int64
+		if !backfillRestart {
+			var err error
+			lastID, err = db.GetBackfillProgress(backfillBuilderSubmissions)
+			if err != nil {
+				log.WithError(err).Fatal("failed to get backfill progress")
+			}
+		}
+		log.Infof("backfilling block submissions after id %d ...", lastID)
+
+		var numProcessed, numUpdated, numMismatches, numErrors, numBatches uint64
+		for backfillMaxBatches == 0 || numBatches < backfillMaxBatches {
+			entries, err := db.GetBuilderSubmissionsWithPayloadAfterID(lastID, backfillBatchSize)
+			if err != nil {
+				log.WithError(err).Fatal("failed to get block submissions")
+			}
+			if len(entries) == 0 {
+				break
+			}
+
+			updates := []*database.BuilderSubmissionBackfill{}
+			for _, entry := range entries {
+				backfill, changed, mismatches, err := database.ComputeBuilderSubmissionBackfill(entry)
+				entryLog := log.WithFields(logrus.Fields{"id": entry.ID, "slot": entry.Slot, "blockHash": entry.BlockHash})
+				if err != nil {
+					entryLog.WithError(err).Warn("failed to decode execution payload")
+					numErrors++
+					continue
+				}
+				if len(mismatches) > 0 {
+					entryLog.WithField("mismatches", mismatches).Warn("bid trace doesn't match the execution payload")
+					numMismatches++
+				}
+				if changed {
+					updates = append(updates, backfill)
+				}
+			}
+
+			lastID = entries[len(entries)-1].ID
+			if !backfillDryRun {
+				if err := db.UpdateBuilderSubmissionsBackfill(updates); err != nil {
+					log.WithError(err).Fatal("failed to update block submissions")
+				}
+				if err := db.SetBackfillProgress(backfillBuilderSubmissions, lastID); err != nil {
+					log.WithError(err).Fatal("failed to save backfill progress")
+				}
+			}
+
+			numBatches++
+			numProcessed += uint64(len(entries))
+			numUpdated += uint64(len(updates))
+			log.Infof("processed %d block submissions up to id %d (updated: %d, mismatches: %d, errors: %d)", numProcessed, lastID, numUpdated, numMismatches, numErrors)
+			time.Sleep(time.Duration(backfillBatchDelay) * time.Millisecond)
+		}
+
+		log.Infof("all done, processed %d block submissions (updated: %d, mismatches: %d, errors: %d)", numProcessed, numUpdated, numMismatches, numErrors)
+	},
+}
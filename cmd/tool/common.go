@@ -1,7 +1,13 @@
 // Package tool exports tool subcommands
 package tool
 
-import "github.com/flashbots/mev-boost-relay/common"
+import (
+	"net/url"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/spf13/cobra"
+)
 
 var (
 	log                = common.LogSetup(false, "info")
@@ -15,3 +21,22 @@ var (
 	dateStart string
 	dateEnd   string
 )
+
+// addPostgresFlag adds the flag for the Postgres DSN
+func addPostgresFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+}
+
+// setupPostgres connects to the database, and exits if that fails
+func setupPostgres() *database.DatabaseService {
+	dbURL, err := url.Parse(postgresDSN)
+	if err != nil {
+		log.WithError(err).Fatalf("couldn't read db URL")
+	}
+	log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
+	db, err := database.NewDatabaseService(postgresDSN)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+	}
+	return db
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
 	"runtime"
 	"strings"
@@ -20,7 +19,7 @@ var (
 )
 
 func init() {
-	DataAPIExportBids.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	addPostgresFlag(DataAPIExportBids)
 	DataAPIExportBids.Flags().Uint64Var(&slotFrom, "slot-from", 0, "start slot (inclusive")
 	DataAPIExportBids.Flags().Uint64Var(&slotTo, "slot-to", 0, "end slot (inclusive)")
 	DataAPIExportBids.Flags().StringSliceVar(&outFiles, "out", []string{}, "output filename")
@@ -40,16 +39,7 @@ var DataAPIExportBids = &cobra.Command{
 			log.Fatal("must specify --slot-from and --slot-to")
 		}
 
-		// Connect to Postgres
-		dbURL, err := url.Parse(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("couldn't read db URL")
-		}
-		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
-		db, err := database.NewDatabaseService(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
-		}
+		db := setupPostgres()
 
 		log.Info("Connected to Postgres database, starting queries")
 		log.Infof("exporting slots %d to %d (%d slots in total)...", slotFrom, slotTo, slotTo-slotFrom+1)
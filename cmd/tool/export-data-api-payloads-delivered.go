@@ -3,7 +3,6 @@ package tool
 import (
 	"encoding/csv"
 	"encoding/json"
-	"net/url"
 	"os"
 	"strings"
 
@@ -14,7 +13,7 @@ import (
 )
 
 func init() {
-	DataAPIExportPayloads.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	addPostgresFlag(DataAPIExportPayloads)
 	DataAPIExportPayloads.Flags().Uint64Var(&idFirst, "id-from", 0, "start id (inclusive")
 	DataAPIExportPayloads.Flags().Uint64Var(&idLast, "id-to", 0, "end id (inclusive)")
 	DataAPIExportPayloads.Flags().StringVar(&dateStart, "date-start", "", "start date (inclusive)")
@@ -36,22 +35,13 @@ var DataAPIExportPayloads = &cobra.Command{
 			log.Fatal("must specify --id-to or --date-end")
 		}
 
-		// Connect to Postgres
-		dbURL, err := url.Parse(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("couldn't read db URL")
-		}
-		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
-		db, err := database.NewDatabaseService(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
-		}
+		db := setupPostgres()
 
 		// if date, then find corresponding id
 		if dateStart != "" {
 			// find first enrty at or after dateStart
 			query := `SELECT id FROM ` + vars.TableDeliveredPayload + ` WHERE inserted_at::date >= date '` + dateStart + `' ORDER BY id ASC LIMIT 1;`
-			err = db.DB.QueryRow(query).Scan(&idFirst)
+			err := db.DB.QueryRow(query).Scan(&idFirst)
 			if err != nil {
 				log.WithError(err).Fatalf("failed to find start id for date %s", dateStart)
 			}
@@ -59,7 +49,7 @@ var DataAPIExportPayloads = &cobra.Command{
 		if dateEnd != "" {
 			// find last entry before dateEnd
 			query := `SELECT id FROM ` + vars.TableDeliveredPayload + ` WHERE inserted_at::date < date '` + dateEnd + `' ORDER BY id DESC LIMIT 1;`
-			err = db.DB.QueryRow(query).Scan(&idLast)
+			err := db.DB.QueryRow(query).Scan(&idLast)
 			if err != nil {
 				log.WithError(err).Fatalf("failed to find end id for date %s", dateEnd)
 			}
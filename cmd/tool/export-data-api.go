@@ -0,0 +1,37 @@
+package tool
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOutDir string
+)
+
+func init() {
+	addPostgresFlag(DataAPIExport)
+	DataAPIExport.Flags().Uint64Var(&slotFrom, "slot-from", 0, "start slot (inclusive")
+	DataAPIExport.Flags().Uint64Var(&slotTo, "slot-to", 0, "end slot (inclusive)")
+	DataAPIExport.Flags().StringVar(&exportFormat, "format", "json", "output format (csv or json)")
+	DataAPIExport.Flags().StringVar(&exportOutDir, "out-dir", ".", "output directory")
+}
+
+var DataAPIExport = &cobra.Command{
+	Use:   "data-api-export",
+	Short: "export the delivered payloads and builder submissions (bid traces as served by the data API) for a slot range to CSV or JSON files",
+	Run: func(cmd *cobra.Command, args []string) {
+		if slotFrom == 0 || slotTo == 0 || slotTo < slotFrom {
+			log.Fatal("must specify --slot-from and --slot-to")
+		}
+		if exportFormat != "csv" && exportFormat != "json" {
+			log.Fatalf("unsupported format '%s', must be csv or json", exportFormat)
+		}
+
+		db := setupPostgres()
+
+		log.Infof("exporting slots %d to %d (%d slots in total)...", slotFrom, slotTo, slotTo-slotFrom+1)
+		exportSlotRange(db, exportOutDir, exportFormat)
+		log.Infof("all done")
+	},
+}
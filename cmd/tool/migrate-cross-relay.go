@@ -0,0 +1,110 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importRelayURL string
+	importSource   string
+	importPageSize uint64
+)
+
+func init() {
+	addPostgresFlag(MigrateCrossRelay)
+	MigrateCrossRelay.Flags().StringVar(&importRelayURL, "relay-url", "", "URL of the relay to import the delivered payloads from")
+	MigrateCrossRelay.Flags().Uint64Var(&slotFrom, "slot-from", 0, "start slot (inclusive")
+	MigrateCrossRelay.Flags().Uint64Var(&slotTo, "slot-to", 0, "end slot (inclusive)")
+	MigrateCrossRelay.Flags().StringVar(&importSource, "source", "", "source to mark the imported payloads with (default: host of the relay URL)")
+	MigrateCrossRelay.Flags().Uint64Var(&importPageSize, "page-size", 200, "number of payloads per request (the data API allows at most 200)")
+	_ = MigrateCrossRelay.MarkFlagRequired("relay-url")
+}
+
+var MigrateCrossRelay = &cobra.Command{
+	Use:   "migrate-cross-relay",
+	Short: "import the delivered payloads of a slot range from the data API of another relay",
+	Run: func(cmd *cobra.Command, args []string) {
+		if slotFrom == 0 || slotTo == 0 || slotTo < slotFrom {
+			log.Fatal("must specify --slot-from and --slot-to")
+		}
+		if importPageSize == 0 {
+			log.Fatal("--page-size must be greater than 0")
+		}
+
+		relayURL, err := url.Parse(strings.TrimSuffix(importRelayURL, "/"))
+		if err != nil || relayURL.Host == "" {
+			log.Fatalf("invalid relay URL: %s", importRelayURL)
+		}
+		if importSource == "" {
+			importSource = relayURL.Host
+		}
+
+		db := setupPostgres()
+		client := &http.Client{Timeout: 30 * time.Second}
+
+		log.Infof("importing delivered payloads of slots %d to %d from %s (source: %s)...", slotFrom, slotTo, relayURL.Host, importSource)
+		numInserted, numSkipped := 0, 0
+
+		// the data API returns the payloads at or before the cursor, in descending order of the slot
+		cursor := slotTo
+		for {
+			payloads, err := fetchDeliveredPayloads(client, relayURL.String(), cursor, importPageSize)
+			if err != nil {
+				log.WithError(err).Fatalf("failed to fetch delivered payloads at cursor %d", cursor)
+			}
+			if len(payloads) == 0 {
+				break
+			}
+
+			for _, payload := range payloads {
+				if payload.Slot < slotFrom {
+					continue
+				}
+				inserted, err := db.InsertImportedDeliveredPayload(database.BidTraceV2JSONToDeliveredPayloadEntry(payload, importSource))
+				if err != nil {
+					log.WithError(err).Fatalf("failed to insert delivered payload for slot %d", payload.Slot)
+				}
+				if inserted {
+					numInserted++
+				} else {
+					numSkipped++
+				}
+			}
+
+			lastSlot := payloads[len(payloads)-1].Slot
+			log.Infof("imported payloads down to slot %d (inserted: %d, already known: %d)", lastSlot, numInserted, numSkipped)
+			if lastSlot <= slotFrom {
+				break
+			}
+			cursor = lastSlot - 1
+		}
+
+		log.Infof("all done. inserted: %d, already known: %d", numInserted, numSkipped)
+	},
+}
+
+func fetchDeliveredPayloads(client *http.Client, relayURL string, cursor, limit uint64) ([]common.BidTraceV2JSON, error) {
+	url := fmt.Sprintf("%s/relay/v1/data/bidtraces/proposer_payload_delivered?cursor=%d&limit=%d", relayURL, cursor, limit)
+	resp, err := client.Get(url) //nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url) //nolint:goerr113
+	}
+
+	payloads := []common.BidTraceV2JSON{}
+	err = json.NewDecoder(resp.Body).Decode(&payloads)
+	return payloads, err
+}
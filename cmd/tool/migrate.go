@@ -10,8 +10,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var migrateDryRun bool
+
 func init() {
-	Migrate.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	addPostgresFlag(Migrate)
+	Migrate.Flags().BoolVar(&migrateDryRun, "dry-run", false, "only list applied and pending migrations, don't apply them")
 }
 
 var Migrate = &cobra.Command{
@@ -29,8 +32,28 @@ var Migrate = &cobra.Command{
 			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
 		}
 
-		log.Infof("Migrating database ...")
 		migrate.SetTable(vars.TableMigrations)
+		if migrateDryRun {
+			records, err := migrate.GetMigrationRecords(db.DB, "postgres")
+			if err != nil {
+				log.WithError(err).Fatalf("Failed to get applied migrations")
+			}
+			for _, record := range records {
+				log.WithField("applied_at", record.AppliedAt).Infof("applied: %s", record.Id)
+			}
+
+			plannedMigrations, _, err := migrate.PlanMigration(db.DB, "postgres", migrations.Migrations, migrate.Up, 0)
+			if err != nil {
+				log.WithError(err).Fatalf("Failed to plan migrations")
+			}
+			for _, plannedMigration := range plannedMigrations {
+				log.Infof("pending: %s", plannedMigration.Id)
+			}
+			log.WithField("num_pending_migrations", len(plannedMigrations)).Info("Dry run finished, no migrations applied")
+			return
+		}
+
+		log.Infof("Migrating database ...")
 		numAppliedMigrations, err := migrate.Exec(db.DB, "postgres", migrations.Migrations, migrate.Up)
 		if err != nil {
 			log.WithError(err).Fatalf("Failed to migrate database")
@@ -0,0 +1,213 @@
+package tool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// Minimal Parquet writer for the archive exports: a single row group of required UTF8 columns, PLAIN encoded and
+// uncompressed. See https://github.com/apache/parquet-format for the file layout and parquet.thrift for the
+// metadata structs, which are written with the Thrift compact protocol.
+
+var parquetMagic = []byte("PAR1")
+
+// values of the enums of parquet.thrift which are used here
+const (
+	parquetTypeByteArray      = 6
+	parquetRepetitionRequired = 0
+	parquetConvertedTypeUTF8  = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageTypeData       = 0
+
+	// number of values per data page
+	parquetPageSize = 10_000
+)
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes Thrift compact protocol structs
+type thriftWriter struct {
+	buf         bytes.Buffer
+	lastFieldID []int16 // stack of the last field id of the structs being written
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	w.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) structBegin() {
+	w.lastFieldID = append(w.lastFieldID, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf.WriteByte(0) // stop field
+	w.lastFieldID = w.lastFieldID[:len(w.lastFieldID)-1]
+}
+
+func (w *thriftWriter) fieldBegin(id int16, fieldType byte) {
+	last := &w.lastFieldID[len(w.lastFieldID)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) listBegin(elemType byte, size int) {
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.varint(uint64(size))
+	}
+}
+
+func (w *thriftWriter) binary(b []byte) {
+	w.varint(uint64(len(b)))
+	w.buf.Write(b)
+}
+
+func (w *thriftWriter) i32Field(id int16, v int32) {
+	w.fieldBegin(id, thriftI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64Field(id int16, v int64) {
+	w.fieldBegin(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) stringField(id int16, s string) {
+	w.fieldBegin(id, thriftBinary)
+	w.binary([]byte(s))
+}
+
+type parquetColumnChunk struct {
+	offset           int64 // of the first data page
+	size             int64 // including the page headers
+	numValues        int64
+	pathInSchemaName string
+}
+
+// writeParquetFile writes the rows as a Parquet file with a required UTF8 column per header field
+func writeParquetFile(out io.Writer, header []string, rows [][]string) error {
+	var file bytes.Buffer
+	file.Write(parquetMagic)
+
+	// Column chunks, with all values of a column in consecutive data pages
+	chunks := make([]parquetColumnChunk, len(header))
+	for col, name := range header {
+		chunks[col] = parquetColumnChunk{offset: int64(file.Len()), numValues: int64(len(rows)), pathInSchemaName: name}
+		for first := 0; first < len(rows); first += parquetPageSize {
+			last := min(first+parquetPageSize, len(rows))
+
+			var page bytes.Buffer
+			for _, row := range rows[first:last] {
+				_ = binary.Write(&page, binary.LittleEndian, uint32(len(row[col])))
+				page.WriteString(row[col])
+			}
+
+			pageHeader := thriftWriter{}
+			pageHeader.structBegin()
+			pageHeader.i32Field(1, parquetPageTypeData)
+			pageHeader.i32Field(2, int32(page.Len())) // uncompressed size
+			pageHeader.i32Field(3, int32(page.Len())) // compressed size
+			pageHeader.fieldBegin(5, thriftStruct)    // data page header
+			pageHeader.structBegin()
+			pageHeader.i32Field(1, int32(last-first))
+			pageHeader.i32Field(2, parquetEncodingPlain)
+			pageHeader.i32Field(3, parquetEncodingRLE) // no definition levels for required columns
+			pageHeader.i32Field(4, parquetEncodingRLE) // no repetition levels for flat schemas
+			pageHeader.structEnd()
+			pageHeader.structEnd()
+
+			file.Write(pageHeader.buf.Bytes())
+			file.Write(page.Bytes())
+		}
+		chunks[col].size = int64(file.Len()) - chunks[col].offset
+	}
+
+	// File metadata
+	meta := thriftWriter{}
+	meta.structBegin()
+	meta.i32Field(1, 1) // version
+
+	meta.fieldBegin(2, thriftList) // schema: the root followed by the columns
+	meta.listBegin(thriftStruct, len(header)+1)
+	meta.structBegin()
+	meta.stringField(4, "schema")
+	meta.i32Field(5, int32(len(header)))
+	meta.structEnd()
+	for _, name := range header {
+		meta.structBegin()
+		meta.i32Field(1, parquetTypeByteArray)
+		meta.i32Field(3, parquetRepetitionRequired)
+		meta.stringField(4, name)
+		meta.i32Field(6, parquetConvertedTypeUTF8)
+		meta.structEnd()
+	}
+
+	meta.i64Field(3, int64(len(rows)))
+
+	meta.fieldBegin(4, thriftList) // row groups
+	if len(rows) == 0 {
+		meta.listBegin(thriftStruct, 0)
+	} else {
+		meta.listBegin(thriftStruct, 1)
+		meta.structBegin()
+		meta.fieldBegin(1, thriftList) // column chunks
+		meta.listBegin(thriftStruct, len(chunks))
+		var totalSize int64
+		for _, chunk := range chunks {
+			totalSize += chunk.size
+			meta.structBegin()
+			meta.i64Field(2, chunk.offset) // file offset
+			meta.fieldBegin(3, thriftStruct)
+			meta.structBegin()
+			meta.i32Field(1, parquetTypeByteArray)
+			meta.fieldBegin(2, thriftList) // encodings
+			meta.listBegin(thriftI32, 2)
+			meta.zigzag(parquetEncodingPlain)
+			meta.zigzag(parquetEncodingRLE)
+			meta.fieldBegin(3, thriftList) // path in schema
+			meta.listBegin(thriftBinary, 1)
+			meta.binary([]byte(chunk.pathInSchemaName))
+			meta.i32Field(4, parquetCodecUncompressed)
+			meta.i64Field(5, chunk.numValues)
+			meta.i64Field(6, chunk.size) // uncompressed size
+			meta.i64Field(7, chunk.size) // compressed size
+			meta.i64Field(9, chunk.offset)
+			meta.structEnd()
+			meta.structEnd()
+		}
+		meta.i64Field(2, totalSize)
+		meta.i64Field(3, int64(len(rows)))
+		meta.structEnd()
+	}
+
+	meta.stringField(6, "mev-boost-relay")
+	meta.structEnd()
+
+	file.Write(meta.buf.Bytes())
+	_ = binary.Write(&file, binary.LittleEndian, uint32(meta.buf.Len()))
+	file.Write(parquetMagic)
+
+	_, err := out.Write(file.Bytes())
+	return err
+}
@@ -0,0 +1,131 @@
+package tool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// thriftReader decodes Thrift compact protocol structs into maps of field id to value
+type thriftReader struct {
+	t *testing.T
+	r *bytes.Reader
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, err := binary.ReadUvarint(r.r)
+	require.NoError(r.t, err)
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(fieldType byte) any {
+	switch fieldType {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		b := make([]byte, r.varint())
+		_, err := r.r.Read(b)
+		require.NoError(r.t, err)
+		return string(b)
+	case thriftList:
+		header, err := r.r.ReadByte()
+		require.NoError(r.t, err)
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	require.Fail(r.t, "unexpected thrift type", fieldType)
+	return nil
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var lastID int16
+	for {
+		header, err := r.r.ReadByte()
+		require.NoError(r.t, err)
+		if header == 0 {
+			return fields
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+		lastID = id
+	}
+}
+
+func TestWriteParquetFile(t *testing.T) {
+	header := []string{"slot", "block_hash"}
+	rows := make([][]string, parquetPageSize+1) // two data pages per column
+	for i := range rows {
+		rows[i] = []string{"123", "0xab"}
+	}
+	rows[parquetPageSize] = []string{"124", ""}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeParquetFile(&buf, header, rows))
+	file := buf.Bytes()
+	require.Equal(t, parquetMagic, file[:4])
+	require.Equal(t, parquetMagic, file[len(file)-4:])
+
+	metaLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	metaReader := &thriftReader{t: t, r: bytes.NewReader(file[len(file)-8-metaLen : len(file)-8])}
+	meta := metaReader.readStruct()
+	require.Equal(t, 0, metaReader.r.Len())
+	require.Equal(t, int64(len(rows)), meta[3])
+
+	schema := meta[2].([]any)
+	require.Len(t, schema, 3)
+	require.Equal(t, int64(2), schema[0].(map[int16]any)[5])
+	require.Equal(t, "block_hash", schema[2].(map[int16]any)[4])
+
+	// Read back the values of the second column from its data pages
+	rowGroup := meta[4].([]any)[0].(map[int16]any)
+	require.Equal(t, int64(len(rows)), rowGroup[3])
+	chunkMeta := rowGroup[1].([]any)[1].(map[int16]any)[3].(map[int16]any)
+	require.Equal(t, []any{"block_hash"}, chunkMeta[3])
+
+	offset, size := chunkMeta[9].(int64), chunkMeta[6].(int64)
+	chunkReader := &thriftReader{t: t, r: bytes.NewReader(file[offset : offset+size])}
+	values := []string{}
+	for chunkReader.r.Len() > 0 {
+		pageHeader := chunkReader.readStruct()
+		numValues := pageHeader[5].(map[int16]any)[1].(int64)
+		for i := int64(0); i < numValues; i++ {
+			var n uint32
+			require.NoError(t, binary.Read(chunkReader.r, binary.LittleEndian, &n))
+			value := make([]byte, n)
+			_, _ = chunkReader.r.Read(value)
+			values = append(values, string(value))
+		}
+	}
+	require.Len(t, values, len(rows))
+	require.Equal(t, "0xab", values[0])
+	require.Equal(t, "", values[parquetPageSize])
+
+	// Without rows there are no row groups
+	buf.Reset()
+	require.NoError(t, writeParquetFile(&buf, header, nil))
+	file = buf.Bytes()
+	metaLen = int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	meta = (&thriftReader{t: t, r: bytes.NewReader(file[len(file)-8-metaLen : len(file)-8])}).readStruct()
+	require.Equal(t, int64(0), meta[3])
+	require.Empty(t, meta[4])
+}
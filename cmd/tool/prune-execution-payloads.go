@@ -0,0 +1,57 @@
+package tool
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOlderThanDays  uint64
+	pruneBatchSize      uint64
+	pruneBatchDelayMs   uint64
+	pruneDefaultDays    = uint64(30)
+	pruneDefaultBatch   = uint64(1000)
+	pruneDefaultDelayMs = uint64(500)
+)
+
+func init() {
+	addPostgresFlag(PruneExecutionPayloads)
+	PruneExecutionPayloads.Flags().Uint64Var(&pruneOlderThanDays, "older-than-days", pruneDefaultDays, "delete execution payloads inserted more than this many days ago")
+	PruneExecutionPayloads.Flags().Uint64Var(&pruneBatchSize, "batch-size", pruneDefaultBatch, "number of rows to delete per batch")
+	PruneExecutionPayloads.Flags().Uint64Var(&pruneBatchDelayMs, "batch-delay-ms", pruneDefaultDelayMs, "pause between batches in milliseconds")
+}
+
+var PruneExecutionPayloads = &cobra.Command{
+	Use:   "prune-execution-payloads",
+	Short: "delete old execution payloads from the DB in batches (block submissions and delivered payloads are kept)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if pruneOlderThanDays == 0 {
+			log.Fatal("--older-than-days must be greater than 0")
+		}
+		if pruneBatchSize == 0 {
+			log.Fatal("--batch-size must be greater than 0")
+		}
+
+		db := setupPostgres()
+
+		olderThan := time.Now().UTC().Add(-time.Duration(pruneOlderThanDays) * 24 * time.Hour)
+		log.Infof("pruning execution payloads inserted before %s", olderThan.String())
+
+		var numPrunedTotal int64
+		for {
+			numPruned, err := db.PruneExecutionPayloads(olderThan, pruneBatchSize)
+			if err != nil {
+				log.WithError(err).Fatal("error pruning execution payloads")
+			}
+			numPrunedTotal += numPruned
+			log.Infof("pruned %d execution payloads (total: %d)", numPruned, numPrunedTotal)
+			if numPruned < int64(pruneBatchSize) {
+				break
+			}
+			time.Sleep(time.Duration(pruneBatchDelayMs) * time.Millisecond)
+		}
+
+		log.Infof("all done, pruned %d execution payloads", numPrunedTotal)
+	},
+}
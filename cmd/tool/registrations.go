@@ -0,0 +1,137 @@
+package tool
+
+import (
+	"encoding/json"
+	"os"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/spf13/cobra"
+)
+
+var (
+	registrationsFile    string
+	registrationsNetwork string
+	registrationsRedis   string
+	skipSignatureCheck   bool
+)
+
+func init() {
+	addPostgresFlag(RegistrationsExport)
+	RegistrationsExport.Flags().StringVar(&registrationsFile, "out", "", "output JSON filename")
+	_ = RegistrationsExport.MarkFlagRequired("out")
+
+	addPostgresFlag(RegistrationsImport)
+	RegistrationsImport.Flags().StringVar(&registrationsFile, "in", "", "input JSON filename (as written by export)")
+	RegistrationsImport.Flags().StringVar(&registrationsNetwork, "network", common.GetEnv("NETWORK", ""), "network of the relay, for the signature check and the Redis key prefix")
+	RegistrationsImport.Flags().StringVar(&registrationsRedis, "redis-uri", common.GetEnv("REDIS_URI", "localhost:6379"), "Redis URI")
+	RegistrationsImport.Flags().BoolVar(&skipSignatureCheck, "skip-signature-check", false, "import registrations without verifying their signatures")
+	_ = RegistrationsImport.MarkFlagRequired("in")
+
+	Registrations.AddCommand(RegistrationsExport)
+	Registrations.AddCommand(RegistrationsImport)
+}
+
+var Registrations = &cobra.Command{
+	Use:   "registrations",
+	Short: "export or import the current validator registrations, e.g. to migrate them to another relay deployment",
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Error("please use a valid subcommand: export or import")
+		_ = cmd.Help()
+	},
+}
+
+var RegistrationsExport = &cobra.Command{
+	Use:   "export",
+	Short: "write the latest registration of every validator from the DB to a JSON file",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := setupPostgres()
+		entries, err := db.GetLatestValidatorRegistrations(false)
+		if err != nil {
+			log.WithError(err).Fatal("failed to get validator registrations")
+		}
+		log.Infof("got %d validator registrations", len(entries))
+
+		registrations := make([]*builderApiV1.SignedValidatorRegistration, 0, len(entries))
+		for _, entry := range entries {
+			registration, err := entry.ToSignedValidatorRegistration()
+			if err != nil {
+				log.WithError(err).Fatalf("invalid registration of %s", entry.Pubkey)
+			}
+			registrations = append(registrations, registration)
+		}
+
+		f, err := os.Create(registrationsFile)
+		if err != nil {
+			log.WithError(err).Fatal("failed to open file")
+		}
+		defer f.Close()
+		if err := json.NewEncoder(f).Encode(registrations); err != nil {
+			log.WithError(err).Fatal("failed to write registrations")
+		}
+		log.Infof("wrote %d registrations to %s", len(registrations), registrationsFile)
+	},
+}
+
+var RegistrationsImport = &cobra.Command{
+	Use:   "import",
+	Short: "load validator registrations from a JSON file into the DB and Redis, keeping newer registrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		networkInfo, err := common.NewEthNetworkDetails(registrationsNetwork)
+		if err != nil {
+			log.WithError(err).Fatalf("error getting network details")
+		}
+
+		f, err := os.Open(registrationsFile)
+		if err != nil {
+			log.WithError(err).Fatal("failed to open file")
+		}
+		defer f.Close()
+		registrations := []*builderApiV1.SignedValidatorRegistration{}
+		if err := json.NewDecoder(f).Decode(&registrations); err != nil {
+			log.WithError(err).Fatal("failed to read registrations")
+		}
+		log.Infof("read %d registrations from %s", len(registrations), registrationsFile)
+
+		db := setupPostgres()
+		redis, err := datastore.NewRedisCache(networkInfo.Name, registrationsRedis, "")
+		if err != nil {
+			log.WithError(err).Fatalf("failed to connect to Redis at %s", registrationsRedis)
+		}
+
+		numImported, numInvalid := 0, 0
+		for _, registration := range registrations {
+			if registration == nil || registration.Message == nil {
+				numInvalid++
+				continue
+			}
+			pubkey := registration.Message.Pubkey
+			if !skipSignatureCheck {
+				ok, err := ssz.VerifySignature(registration.Message, networkInfo.DomainBuilder, pubkey[:], registration.Signature[:])
+				if err != nil || !ok {
+					log.WithError(err).Warnf("skipping registration of %s with an invalid signature", pubkey.String())
+					numInvalid++
+					continue
+				}
+			}
+
+			// the database and Redis only take a registration if it's newer than the existing one
+			if err := db.SaveValidatorRegistration(database.SignedValidatorRegistrationToEntry(*registration)); err != nil {
+				log.WithError(err).Fatalf("failed to save registration of %s", pubkey.String())
+			}
+			err = redis.SetValidatorRegistrationTimestampIfNewer(common.NewPubkeyHex(pubkey.String()), uint64(registration.Message.Timestamp.Unix()))
+			if err != nil {
+				log.WithError(err).Fatalf("failed to save registration timestamp of %s", pubkey.String())
+			}
+
+			numImported++
+			if numImported%10_000 == 0 {
+				log.Infof("imported %d registrations", numImported)
+			}
+		}
+		log.Infof("all done. imported: %d, invalid: %d", numImported, numInvalid)
+	},
+}
@@ -0,0 +1,208 @@
+package tool
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayTarget      string
+	replayInFile      string
+	replayConcurrency int
+	replayRate        int
+	replayRepeat      int
+)
+
+func init() {
+	addPostgresFlag(ReplaySubmissions)
+	ReplaySubmissions.Flags().Uint64Var(&slotFrom, "slot-from", 0, "start slot (inclusive), to load the submissions from the database")
+	ReplaySubmissions.Flags().Uint64Var(&slotTo, "slot-to", 0, "end slot (inclusive), to load the submissions from the database")
+	ReplaySubmissions.Flags().StringVar(&replayInFile, "in", "", "JSON file with a list of submissions, instead of loading them from the database")
+	ReplaySubmissions.Flags().StringVar(&replayTarget, "target", "", "URL of the relay to submit to")
+	ReplaySubmissions.Flags().IntVar(&replayConcurrency, "concurrency", 10, "number of concurrent requests")
+	ReplaySubmissions.Flags().IntVar(&replayRate, "rate", 0, "maximum number of requests per second (0 for no limit)")
+	ReplaySubmissions.Flags().IntVar(&replayRepeat, "repeat", 1, "number of times to submit each submission")
+	_ = ReplaySubmissions.MarkFlagRequired("target")
+}
+
+var ReplaySubmissions = &cobra.Command{
+	Use:   "replay-submissions",
+	Short: "re-submit stored builder submissions to a relay, to load test it, and report the latencies",
+	Run: func(cmd *cobra.Command, args []string) {
+		if replayConcurrency < 1 || replayRepeat < 1 || replayRate < 0 {
+			log.Fatal("--concurrency and --repeat must be at least 1, and --rate must not be negative")
+		}
+
+		var submissions [][]byte
+		if replayInFile != "" {
+			submissions = loadSubmissionsFromFile(replayInFile)
+		} else {
+			if slotFrom == 0 || slotTo == 0 || slotTo < slotFrom {
+				log.Fatal("must specify --in, or --slot-from and --slot-to")
+			}
+			submissions = loadSubmissionsFromDB()
+		}
+		if len(submissions) == 0 {
+			log.Fatal("no submissions to replay")
+		}
+
+		url := strings.TrimSuffix(replayTarget, "/") + "/relay/v1/builder/blocks"
+		log.Infof("replaying %d submissions %d times to %s (concurrency: %d, rate: %d/s)...", len(submissions), replayRepeat, url, replayConcurrency, replayRate)
+		results := replaySubmissions(url, submissions)
+		reportReplayResults(results)
+	},
+}
+
+// loadSubmissionsFromFile reads a JSON list of submissions as sent by the builders
+func loadSubmissionsFromFile(fn string) (submissions [][]byte) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		log.WithError(err).Fatalf("failed to read %s", fn)
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.WithError(err).Fatalf("failed to decode %s", fn)
+	}
+	for i, entry := range entries {
+		// make sure it is a valid submission, to not measure the latency of rejecting garbage
+		request := new(common.VersionedSubmitBlockRequest)
+		if err := json.Unmarshal(entry, request); err != nil {
+			log.WithError(err).Fatalf("invalid submission at index %d", i)
+		}
+		submissions = append(submissions, entry)
+	}
+	log.Infof("loaded %d submissions from %s", len(submissions), fn)
+	return submissions
+}
+
+// loadSubmissionsFromDB reconstructs the submissions of the slot range whose execution payloads are stored
+func loadSubmissionsFromDB() (submissions [][]byte) {
+	db := setupPostgres()
+	entries, err := db.GetBuilderSubmissionsWithPayloadBySlots(slotFrom, slotTo)
+	if err != nil {
+		log.WithError(err).Fatal("failed to get builder submissions")
+	}
+	log.Infof("got %d submissions with execution payloads for slots %d to %d", len(entries), slotFrom, slotTo)
+
+	for _, entry := range entries {
+		payloadEntry, err := db.GetExecutionPayloadEntryByID(entry.ExecutionPayloadID.Int64)
+		if err != nil {
+			log.WithError(err).Warnf("failed to get execution payload %d, skipping", entry.ExecutionPayloadID.Int64)
+			continue
+		}
+		request, err := database.BuilderSubmissionEntryToSubmitBlockRequest(entry, payloadEntry)
+		if err != nil {
+			log.WithError(err).Warnf("failed to reconstruct submission %d, skipping", entry.ID)
+			continue
+		}
+		body, err := json.Marshal(request)
+		if err != nil {
+			log.WithError(err).Fatalf("failed to encode submission %d", entry.ID)
+		}
+		submissions = append(submissions, body)
+	}
+	return submissions
+}
+
+type replayResult struct {
+	statusCode int // 0 if the request failed
+	latency    time.Duration
+}
+
+func replaySubmissions(url string, submissions [][]byte) []replayResult {
+	client := &http.Client{Timeout: 30 * time.Second}
+	bodies := make(chan []byte)
+	resultC := make(chan replayResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < replayConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for body := range bodies {
+				resultC <- submitReplay(client, url, body)
+			}
+		}()
+	}
+
+	go func() {
+		var ticker *time.Ticker
+		if replayRate > 0 {
+			ticker = time.NewTicker(time.Second / time.Duration(replayRate))
+			defer ticker.Stop()
+		}
+		for i := 0; i < replayRepeat; i++ {
+			for _, body := range submissions {
+				if ticker != nil {
+					<-ticker.C
+				}
+				bodies <- body
+			}
+		}
+		close(bodies)
+		wg.Wait()
+		close(resultC)
+	}()
+
+	results := make([]replayResult, 0, len(submissions)*replayRepeat)
+	for result := range resultC {
+		results = append(results, result)
+		if len(results)%1000 == 0 {
+			log.Infof("%d requests done", len(results))
+		}
+	}
+	return results
+}
+
+func submitReplay(client *http.Client, url string, body []byte) replayResult {
+	start := time.Now()
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body)) //nolint:noctx
+	if err != nil {
+		log.WithError(err).Debug("request failed")
+		return replayResult{latency: time.Since(start)}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return replayResult{statusCode: resp.StatusCode, latency: time.Since(start)}
+}
+
+func reportReplayResults(results []replayResult) {
+	statusCodes := make(map[int]int)
+	latencies := make([]time.Duration, len(results))
+	for i, result := range results {
+		statusCodes[result.statusCode]++
+		latencies[i] = result.latency
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+	log.Infof("requests: %d, latency p50: %s, p90: %s, p99: %s, max: %s",
+		len(results), percentile(50), percentile(90), percentile(99), latencies[len(latencies)-1])
+
+	codes := make([]int, 0, len(statusCodes))
+	for code := range statusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		status := strconv.Itoa(code)
+		if code == 0 {
+			status = "failed"
+		}
+		log.Infof("status %s: %d requests", status, statusCodes[code])
+	}
+}
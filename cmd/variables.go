@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"os"
+	"time"
 
+	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/spf13/cobra"
 )
 
 var (
@@ -12,8 +16,20 @@ var (
 	defaultBeaconPublishURIs = common.GetSliceEnv("BEACON_PUBLISH_URIS", []string{})
 	defaultRedisURI          = common.GetEnv("REDIS_URI", "localhost:6379")
 	defaultRedisReadonlyURI  = common.GetEnv("REDIS_READONLY_URI", "")
+	defaultRedisMode         = common.GetEnv("REDIS_MODE", datastore.RedisModeStandalone)
+	defaultRedisMaster       = os.Getenv("REDIS_SENTINEL_MASTER")
+	defaultRedisUsername     = os.Getenv("REDIS_USERNAME")
+	defaultRedisPassword     = os.Getenv("REDIS_PASSWORD")
+	defaultRedisTLS          = os.Getenv("REDIS_TLS") == "1"
+	defaultRedisPoolSize     = cli.GetEnvInt("REDIS_CONNECTION_POOL_SIZE", 0)
+	defaultRedisMinIdleConns = cli.GetEnvInt("REDIS_MIN_IDLE_CONNECTIONS", 0)
+	defaultRedisReadTimeout  = cli.GetEnvInt("REDIS_READ_TIMEOUT_SEC", 0)
+	defaultRedisWriteTimeout = cli.GetEnvInt("REDIS_WRITE_TIMEOUT_SEC", 0)
+	defaultRedisPoolTimeout  = cli.GetEnvInt("REDIS_POOL_TIMEOUT_SEC", 0)
 	defaultPostgresDSN       = common.GetEnv("POSTGRES_DSN", "")
+	defaultPostgresReplica   = common.GetEnv("POSTGRES_REPLICA_DSN", "")
 	defaultMemcachedURIs     = common.GetSliceEnv("MEMCACHED_URIS", nil)
+	defaultRedisSecondaries  = common.GetSliceEnv("REDIS_SECONDARY_URIS", nil)
 	defaultLogJSON           = os.Getenv("LOG_JSON") != ""
 	defaultLogLevel          = common.GetEnv("LOG_LEVEL", "info")
 
@@ -21,11 +37,100 @@ var (
 	beaconNodePublishURIs []string
 	redisURI              string
 	redisReadonlyURI      string
+	redisMode             string
+	redisMaster           string
+	redisUsername         string
+	redisPassword         string
+	redisTLS              bool
+	redisPoolSize         int
+	redisMinIdleConns     int
+	redisReadTimeoutSec   int
+	redisWriteTimeoutSec  int
+	redisPoolTimeoutSec   int
 	postgresDSN           string
+	postgresReplicaDSN    string
 	memcachedURIs         []string
+	redisSecondaryURIs    []string
 
 	logJSON  bool
 	logLevel string
 
 	network string
 )
+
+// addLogFlags adds the flags for the log format and level
+func addLogFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
+	cmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+}
+
+// addNetworkFlag adds the flag for the network to use
+func addNetworkFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&network, "network", defaultNetwork, "Which network to use")
+}
+
+// addBeaconFlags adds the flags for the beacon node endpoints, and optionally for separate endpoints to publish blocks
+func addBeaconFlags(cmd *cobra.Command, withPublishURIs bool) {
+	cmd.Flags().StringSliceVar(&beaconNodeURIs, "beacon-uris", defaultBeaconURIs, "beacon endpoints")
+	if withPublishURIs {
+		cmd.Flags().StringSliceVar(&beaconNodePublishURIs, "beacon-publish-uris", defaultBeaconPublishURIs, "beacon publish endpoints")
+	}
+}
+
+// addRedisFlags adds the flags for the Redis URI (and optionally the readonly URI), topology, authentication and
+// connection pool
+func addRedisFlags(cmd *cobra.Command, withReadonlyURI bool) {
+	cmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
+	if withReadonlyURI {
+		cmd.Flags().StringVar(&redisReadonlyURI, "redis-readonly-uri", defaultRedisReadonlyURI, "redis readonly uri")
+	}
+	cmd.Flags().StringVar(&redisMode, "redis-mode", defaultRedisMode, "redis topology: standalone, cluster or sentinel (redis uris are then comma separated node addresses)")
+	cmd.Flags().StringVar(&redisMaster, "redis-sentinel-master", defaultRedisMaster, "redis sentinel master name")
+	cmd.Flags().StringVar(&redisUsername, "redis-username", defaultRedisUsername, "redis username")
+	cmd.Flags().StringVar(&redisPassword, "redis-password", defaultRedisPassword, "redis password")
+	cmd.Flags().BoolVar(&redisTLS, "redis-tls", defaultRedisTLS, "connect to redis over TLS")
+	cmd.Flags().IntVar(&redisPoolSize, "redis-pool-size", defaultRedisPoolSize, "redis connection pool size (0 for the default of 10 per CPU)")
+	cmd.Flags().IntVar(&redisMinIdleConns, "redis-min-idle-conns", defaultRedisMinIdleConns, "redis minimum idle connections")
+	cmd.Flags().IntVar(&redisReadTimeoutSec, "redis-read-timeout-sec", defaultRedisReadTimeout, "redis read timeout (0 for the default of 3 sec)")
+	cmd.Flags().IntVar(&redisWriteTimeoutSec, "redis-write-timeout-sec", defaultRedisWriteTimeout, "redis write timeout (0 for the default of 3 sec)")
+	cmd.Flags().IntVar(&redisPoolTimeoutSec, "redis-pool-timeout-sec", defaultRedisPoolTimeout, "redis pool timeout (0 for the default of read timeout + 1 sec)")
+}
+
+// addPostgresFlag adds the flag for the Postgres DSN
+func addPostgresFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+}
+
+// addPostgresReplicaFlag adds the flag for the DSN of the optional read replica for the data API and the website
+func addPostgresReplicaFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&postgresReplicaDSN, "db-replica", defaultPostgresReplica, "PostgreSQL DSN of a read replica for the data API and the website (optional)")
+}
+
+// addMemcachedFlag adds the flag for the optional memcached endpoints
+func addMemcachedFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&memcachedURIs, "memcached-uris", defaultMemcachedURIs,
+		"Enable memcached, typically used as secondary backup to Redis for redundancy")
+}
+
+// addRedisSecondaryFlag adds the flag for the optional secondary Redis endpoints for the execution payloads
+func addRedisSecondaryFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&redisSecondaryURIs, "redis-secondary-uris", defaultRedisSecondaries,
+		"secondary (i.e. cross-region) standalone redis uris, which execution payloads are copied to and read from in getPayload")
+}
+
+func getRedisOpts() datastore.RedisOpts {
+	return datastore.RedisOpts{
+		Mode:           redisMode,
+		URI:            redisURI,
+		ReadonlyURI:    redisReadonlyURI,
+		SentinelMaster: redisMaster,
+		Username:       redisUsername,
+		Password:       redisPassword,
+		TLS:            redisTLS,
+		PoolSize:       redisPoolSize,
+		MinIdleConns:   redisMinIdleConns,
+		ReadTimeout:    time.Duration(redisReadTimeoutSec) * time.Second,
+		WriteTimeout:   time.Duration(redisWriteTimeoutSec) * time.Second,
+		PoolTimeout:    time.Duration(redisPoolTimeoutSec) * time.Second,
+	}
+}
@@ -1,11 +1,9 @@
 package cmd
 
 import (
-	"net/url"
 	"os"
 
 	"github.com/flashbots/mev-boost-relay/common"
-	"github.com/flashbots/mev-boost-relay/database"
 	"github.com/flashbots/mev-boost-relay/datastore"
 	"github.com/flashbots/mev-boost-relay/services/website"
 	"github.com/sirupsen/logrus"
@@ -32,16 +30,14 @@ var (
 
 func init() {
 	rootCmd.AddCommand(websiteCmd)
-	websiteCmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
-	websiteCmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+	addLogFlags(websiteCmd)
+	addNetworkFlag(websiteCmd)
+	addRedisFlags(websiteCmd, true)
+	addPostgresFlag(websiteCmd)
+	addPostgresReplicaFlag(websiteCmd)
 
 	websiteCmd.Flags().StringVar(&websiteListenAddr, "listen-addr", websiteDefaultListenAddr, "listen address for webserver")
-	websiteCmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
-	websiteCmd.Flags().StringVar(&redisReadonlyURI, "redis-readonly-uri", defaultRedisReadonlyURI, "redis readonly uri")
-	websiteCmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
 	websiteCmd.Flags().StringVar(&websitePubkeyOverride, "pubkey-override", os.Getenv("PUBKEY_OVERRIDE"), "override for public key")
-
-	websiteCmd.Flags().StringVar(&network, "network", defaultNetwork, "Which network to use")
 	websiteCmd.Flags().BoolVar(&websiteShowConfigDetails, "show-config-details", websiteDefaultShowConfigDetails, "show config details")
 	websiteCmd.Flags().StringVar(&websiteLinkBeaconchain, "link-beaconchain", websiteDefaultLinkBeaconchain, "url for beaconcha.in")
 	websiteCmd.Flags().StringVar(&websiteLinkEtherscan, "link-etherscan", websiteDefaultLinkEtherscan, "url for etherscan")
@@ -61,24 +57,8 @@ var websiteCmd = &cobra.Command{
 		})
 		log.Infof("boost-relay %s", Version)
 
-		networkInfo, err := common.NewEthNetworkDetails(network)
-		if err != nil {
-			log.WithError(err).Fatalf("error getting network details")
-		}
-
-		log.Infof("Using network: %s", networkInfo.Name)
-		log.Debug(networkInfo.String())
-
-		// Connect to Redis
-		if redisReadonlyURI == "" {
-			log.Infof("Connecting to Redis at %s ...", redisURI)
-		} else {
-			log.Infof("Connecting to Redis at %s / readonly: %s ...", redisURI, redisReadonlyURI)
-		}
-		redis, err := datastore.NewRedisCache(networkInfo.Name, redisURI, redisReadonlyURI)
-		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
-		}
+		networkInfo := setupNetwork(log)
+		redis := setupRedis(log, networkInfo)
 
 		relayPubkey := ""
 		if websitePubkeyOverride != "" {
@@ -90,17 +70,7 @@ var websiteCmd = &cobra.Command{
 			}
 		}
 
-		// Connect to Postgres
-		log.Infof("Connecting to Postgres database...")
-		dbURL, err := url.Parse(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("couldn't read db URL")
-		}
-		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
-		db, err := database.NewDatabaseService(postgresDSN)
-		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
-		}
+		db := setupPostgres(log)
 
 		// Create the website service
 		opts := &website.WebserverOpts{
@@ -108,7 +78,7 @@ var websiteCmd = &cobra.Command{
 			RelayPubkeyHex:    relayPubkey,
 			NetworkDetails:    networkInfo,
 			Redis:             redis,
-			DB:                db,
+			DB:                withReadReplica(log, db),
 			Log:               log,
 			ShowConfigDetails: websiteShowConfigDetails,
 			LinkBeaconchain:   websiteLinkBeaconchain,
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// BidFeedbackRequest is a request of a builder for the feedback on its bids for a slot
+type BidFeedbackRequest struct {
+	BuilderPubkey phase0.BLSPubKey `json:"builder_pubkey" ssz-size:"48"`
+	Slot          uint64           `json:"slot,string"`
+	Timestamp     uint64           `json:"timestamp,string"`
+}
+
+// SignedBidFeedbackRequest is a bid feedback request, signed by the builder key with the builder domain
+type SignedBidFeedbackRequest struct {
+	Message   *BidFeedbackRequest `json:"message"`
+	Signature phase0.BLSSignature `json:"signature"`
+}
+
+// HashTreeRoot ssz hashes the BidFeedbackRequest object
+func (r *BidFeedbackRequest) HashTreeRoot() ([32]byte, error) {
+	return ssz.HashWithDefaultHasher(r)
+}
+
+// HashTreeRootWith ssz hashes the BidFeedbackRequest object with a hasher
+func (r *BidFeedbackRequest) HashTreeRootWith(hh ssz.HashWalker) (err error) {
+	indx := hh.Index()
+
+	// Field (0) 'BuilderPubkey'
+	hh.PutBytes(r.BuilderPubkey[:])
+
+	// Field (1) 'Slot'
+	hh.PutUint64(r.Slot)
+
+	// Field (2) 'Timestamp'
+	hh.PutUint64(r.Timestamp)
+
+	hh.Merkleize(indx)
+	return nil
+}
+
+// GetTree ssz hashes the BidFeedbackRequest object
+func (r *BidFeedbackRequest) GetTree() (*ssz.Node, error) {
+	return ssz.ProofTree(r)
+}
+
+// BidFeedbackJSON is the feedback on the best bid of a builder for a slot: the highest eligible bid, or the highest
+// rejected bid if none was eligible. The winning bid is the delivered one, or else the highest eligible bid.
+type BidFeedbackJSON struct {
+	Slot            uint64 `json:"slot,string"`
+	BuilderPubkey   string `json:"builder_pubkey"`
+	BlockHash       string `json:"block_hash"`
+	Value           string `json:"value"`
+	Position        uint64 `json:"position,string"` // 1 for the highest bid of all builders, 0 if rejected
+	NumBuilders     uint64 `json:"num_builders,string"`
+	NumSubmissions  uint64 `json:"num_submissions,string"`
+	RejectionReason string `json:"rejection_reason,omitempty"`
+	ReceivedAt      int64  `json:"received_at_ms,string"`
+	EligibleAt      int64  `json:"eligible_at_ms,string,omitempty"`
+
+	WinningBlockHash string `json:"winning_block_hash,omitempty"`
+	WinningValue     string `json:"winning_value,omitempty"`
+	WinnerDelivered  bool   `json:"winner_delivered"`
+	DeltaToWinner    string `json:"delta_to_winner,omitempty"` // winning value minus the value of the bid
+}
@@ -38,6 +38,23 @@ type BuilderStatus struct {
 	IsOptimistic  bool
 }
 
+// BuilderLabels attach a builder pubkey to the organization operating it, to analyze the auction per organization
+type BuilderLabels struct {
+	Operator string `json:"operator"`
+	Region   string `json:"region"`
+	Tier     string `json:"tier"`
+}
+
+// BeaconPublishOutcome is the result of publishing a block through one beacon node
+type BeaconPublishOutcome struct {
+	URI          string `json:"uri"`
+	Code         int    `json:"code"`
+	Attempts     int    `json:"attempts"`
+	AlreadyKnown bool   `json:"already_known,omitempty"` // the beacon node already had the block
+	Error        string `json:"error,omitempty"`
+	DurationMs   int64  `json:"duration_ms"`
+}
+
 // Profile captures performance metrics for the block submission handler. Each
 // field corresponds to the number of microseconds in each stage. The `Total`
 // field is the number of microseconds taken for entire flow.
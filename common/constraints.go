@@ -0,0 +1,120 @@
+package common
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// MaxConstraintsTransactions is the maximum number of transaction hashes and of raw transactions in inclusion constraints
+const MaxConstraintsTransactions = 16
+
+// maxConstraintsTransactionSize is the ssz list limit of a single raw transaction (as in the execution payload)
+const maxConstraintsTransactionSize = 1073741824
+
+// InclusionConstraints are the transactions a proposer commits to include in the block of its slot. Bids for the slot
+// are only accepted if the block contains all of them, given either by hash or as raw transaction.
+type InclusionConstraints struct {
+	Pubkey       phase0.BLSPubKey `json:"pubkey" ssz-size:"48"`
+	Slot         uint64           `json:"slot,string"`
+	TxHashes     []phase0.Hash32  `json:"tx_hashes" ssz-max:"16" ssz-size:"?,32"`
+	Transactions []hexutil.Bytes  `json:"transactions" ssz-max:"16,1073741824" ssz-size:"?,?"`
+}
+
+// SignedInclusionConstraints are the inclusion constraints, signed by the proposer's validator key with the builder domain
+type SignedInclusionConstraints struct {
+	Message   *InclusionConstraints `json:"message"`
+	Signature phase0.BLSSignature   `json:"signature"`
+}
+
+// RequiredTxHashes returns the hashes of all transactions which must be included, without duplicates
+func (c *InclusionConstraints) RequiredTxHashes() []phase0.Hash32 {
+	hashes := make([]phase0.Hash32, 0, len(c.TxHashes)+len(c.Transactions))
+	seen := make(map[phase0.Hash32]bool, cap(hashes))
+	add := func(hash phase0.Hash32) {
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	for _, hash := range c.TxHashes {
+		add(hash)
+	}
+	for _, tx := range c.Transactions {
+		add(phase0.Hash32(crypto.Keccak256Hash(tx)))
+	}
+	return hashes
+}
+
+// MissingTxHashes returns the required transaction hashes which are not in the given transactions of a block
+func (c *InclusionConstraints) MissingTxHashes(txs []bellatrix.Transaction) []phase0.Hash32 {
+	included := make(map[phase0.Hash32]bool, len(txs))
+	for _, tx := range txs {
+		included[phase0.Hash32(crypto.Keccak256Hash(tx))] = true
+	}
+	var missing []phase0.Hash32
+	for _, hash := range c.RequiredTxHashes() {
+		if !included[hash] {
+			missing = append(missing, hash)
+		}
+	}
+	return missing
+}
+
+// HashTreeRoot ssz hashes the InclusionConstraints object
+func (c *InclusionConstraints) HashTreeRoot() ([32]byte, error) {
+	return ssz.HashWithDefaultHasher(c)
+}
+
+// HashTreeRootWith ssz hashes the InclusionConstraints object with a hasher
+func (c *InclusionConstraints) HashTreeRootWith(hh ssz.HashWalker) (err error) {
+	indx := hh.Index()
+
+	// Field (0) 'Pubkey'
+	hh.PutBytes(c.Pubkey[:])
+
+	// Field (1) 'Slot'
+	hh.PutUint64(c.Slot)
+
+	// Field (2) 'TxHashes'
+	{
+		num := uint64(len(c.TxHashes))
+		if num > MaxConstraintsTransactions {
+			return ssz.ErrIncorrectListSize
+		}
+		subIndx := hh.Index()
+		for _, hash := range c.TxHashes {
+			hh.PutBytes(hash[:])
+		}
+		hh.MerkleizeWithMixin(subIndx, num, MaxConstraintsTransactions)
+	}
+
+	// Field (3) 'Transactions'
+	{
+		num := uint64(len(c.Transactions))
+		if num > MaxConstraintsTransactions {
+			return ssz.ErrIncorrectListSize
+		}
+		subIndx := hh.Index()
+		for _, tx := range c.Transactions {
+			txIndx := hh.Index()
+			byteLen := uint64(len(tx))
+			if byteLen > maxConstraintsTransactionSize {
+				return ssz.ErrIncorrectListSize
+			}
+			hh.AppendBytes32(tx)
+			hh.MerkleizeWithMixin(txIndx, byteLen, (maxConstraintsTransactionSize+31)/32)
+		}
+		hh.MerkleizeWithMixin(subIndx, num, MaxConstraintsTransactions)
+	}
+
+	hh.Merkleize(indx)
+	return nil
+}
+
+// GetTree ssz hashes the InclusionConstraints object
+func (c *InclusionConstraints) GetTree() (*ssz.Node, error) {
+	return ssz.ProofTree(c)
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInclusionConstraints(t *testing.T) {
+	tx1 := bellatrix.Transaction{0x02, 0x01}
+	tx2 := bellatrix.Transaction{0x02, 0x02}
+	hash1 := phase0.Hash32(crypto.Keccak256Hash(tx1))
+	hash2 := phase0.Hash32(crypto.Keccak256Hash(tx2))
+
+	constraints := &InclusionConstraints{
+		Slot:         1,
+		TxHashes:     []phase0.Hash32{hash1},
+		Transactions: []hexutil.Bytes{hexutil.Bytes(tx1), hexutil.Bytes(tx2)},
+	}
+	require.Equal(t, []phase0.Hash32{hash1, hash2}, constraints.RequiredTxHashes())
+
+	require.Empty(t, constraints.MissingTxHashes([]bellatrix.Transaction{tx2, {0x03}, tx1}))
+	require.Equal(t, []phase0.Hash32{hash2}, constraints.MissingTxHashes([]bellatrix.Transaction{tx1}))
+
+	root, err := constraints.HashTreeRoot()
+	require.NoError(t, err)
+	constraints.Transactions = constraints.Transactions[:1]
+	root2, err := constraints.HashTreeRoot()
+	require.NoError(t, err)
+	require.NotEqual(t, root, root2)
+
+	constraints.TxHashes = make([]phase0.Hash32, MaxConstraintsTransactions+1)
+	_, err = constraints.HashTreeRoot()
+	require.Error(t, err)
+}
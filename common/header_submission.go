@@ -0,0 +1,97 @@
+package common
+
+import (
+	"errors"
+
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+)
+
+var (
+	ErrMissingBidTrace               = errors.New("missing bid trace")
+	ErrMissingExecutionPayloadHeader = errors.New("missing execution payload header")
+)
+
+// SubmitHeaderRequest is a header-only block submission (deneb): the signed bid trace and the execution payload header
+// of a block, whose payload has to be submitted to the regular block submission endpoint before a deadline
+type SubmitHeaderRequest struct {
+	Message                *builderApiV1.BidTrace        `json:"message"`
+	ExecutionPayloadHeader *deneb.ExecutionPayloadHeader `json:"execution_payload_header"`
+	BlobKZGCommitments     []deneb.KZGCommitment         `json:"blob_kzg_commitments"`
+	Signature              phase0.BLSSignature           `json:"signature"`
+}
+
+// BlockSubmissionInfo returns the submission info of the header, for the checks shared with full block submissions.
+// The transactions and withdrawals of the block are unknown, instead the withdrawals root of the header is set.
+func (r *SubmitHeaderRequest) BlockSubmissionInfo() (*BlockSubmissionInfo, error) {
+	if r.Message == nil {
+		return nil, ErrMissingBidTrace
+	}
+	header := r.ExecutionPayloadHeader
+	if header == nil {
+		return nil, ErrMissingExecutionPayloadHeader
+	}
+	withdrawalsRoot := header.WithdrawalsRoot
+	return &BlockSubmissionInfo{
+		BidTrace:                   r.Message,
+		ExecutionPayloadBlockHash:  header.BlockHash,
+		ExecutionPayloadParentHash: header.ParentHash,
+		FeeRecipient:               header.FeeRecipient,
+		GasUsed:                    header.GasUsed,
+		GasLimit:                   header.GasLimit,
+		Timestamp:                  header.Timestamp,
+		BlockNumber:                header.BlockNumber,
+		PrevRandao:                 header.PrevRandao,
+		Signature:                  r.Signature,
+		WithdrawalsRoot:            &withdrawalsRoot,
+		BlobGasUsed:                header.BlobGasUsed,
+		ExcessBlobGas:              header.ExcessBlobGas,
+	}, nil
+}
+
+// BuildHeaderGetHeaderResponse returns the relay-signed getHeader response of a header-only submission
+func BuildHeaderGetHeaderResponse(req *SubmitHeaderRequest, sk *bls.SecretKey, pubkey *phase0.BLSPubKey, domain phase0.Domain) (*builderSpec.VersionedSignedBuilderBid, error) {
+	if req == nil || req.Message == nil || req.ExecutionPayloadHeader == nil {
+		return nil, ErrMissingRequest
+	}
+	if sk == nil {
+		return nil, ErrMissingSecretKey
+	}
+
+	builderBid := builderApiDeneb.BuilderBid{
+		Header:             req.ExecutionPayloadHeader,
+		BlobKZGCommitments: req.BlobKZGCommitments,
+		Value:              req.Message.Value,
+		Pubkey:             *pubkey,
+	}
+	sig, err := ssz.SignMessage(&builderBid, domain, sk)
+	if err != nil {
+		return nil, err
+	}
+	return &builderSpec.VersionedSignedBuilderBid{
+		Version: spec.DataVersionDeneb,
+		Deneb: &builderApiDeneb.SignedBuilderBid{
+			Message:   &builderBid,
+			Signature: sig,
+		},
+	}, nil
+}
+
+// PendingPayload is a header-only bid whose payload was not received yet. The bid is cancelled if the payload doesn't
+// arrive before the deadline.
+type PendingPayload struct {
+	Slot           uint64 `json:"slot,string"`
+	ParentHash     string `json:"parent_hash"`
+	ProposerPubkey string `json:"proposer_pubkey"`
+	BuilderPubkey  string `json:"builder_pubkey"`
+	BlockHash      string `json:"block_hash"`
+	Value          string `json:"value"`
+	ReceivedAtMs   int64  `json:"received_at_ms,string"`
+	DeadlineMs     int64  `json:"deadline_ms,string"`
+}
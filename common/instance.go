@@ -0,0 +1,21 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+)
+
+// InstanceID identifies this relay process in the Redis and database entries it writes, when several instances share
+// them. Defaults to the hostname, which is unique per pod or machine.
+var InstanceID = GetEnv("INSTANCE_ID", defaultInstanceID())
+
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err == nil && hostname != "" {
+		return hostname
+	}
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"os"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/goccy/go-yaml"
+)
+
+// CustomNetworkConfig contains the details of a custom network (i.e. a devnet). It is loaded from the YAML or JSON file
+// in NETWORK_CONFIG_FILE, or else from the individual environment variables.
+type CustomNetworkConfig struct {
+	GenesisForkVersion    string `json:"genesis_fork_version"    yaml:"genesis_fork_version"`
+	GenesisValidatorsRoot string `json:"genesis_validators_root" yaml:"genesis_validators_root"`
+	BellatrixForkVersion  string `json:"bellatrix_fork_version"  yaml:"bellatrix_fork_version"`
+	CapellaForkVersion    string `json:"capella_fork_version"    yaml:"capella_fork_version"`
+	CapellaForkEpoch      int64  `json:"capella_fork_epoch"      yaml:"capella_fork_epoch"`
+	DenebForkVersion      string `json:"deneb_fork_version"      yaml:"deneb_fork_version"`
+	DenebForkEpoch        int64  `json:"deneb_fork_epoch"        yaml:"deneb_fork_epoch"`
+	ElectraForkVersion    string `json:"electra_fork_version"    yaml:"electra_fork_version"`
+	ElectraForkEpoch      int64  `json:"electra_fork_epoch"      yaml:"electra_fork_epoch"`
+
+	// Optional, computed from the genesis fork version if empty
+	BuilderDomain string `json:"builder_domain" yaml:"builder_domain"`
+}
+
+// LoadCustomNetworkConfig loads the custom network config from the given file (YAML or JSON)
+func LoadCustomNetworkConfig(fn string) (*CustomNetworkConfig, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forks which are not in the file are not scheduled
+	cfg := &CustomNetworkConfig{
+		CapellaForkEpoch: -1,
+		DenebForkEpoch:   -1,
+		ElectraForkEpoch: -1,
+	}
+	err = yaml.Unmarshal(data, cfg)
+	return cfg, err
+}
+
+// CustomNetworkConfigFromEnv returns the custom network config from the environment variables
+func CustomNetworkConfigFromEnv() *CustomNetworkConfig {
+	return &CustomNetworkConfig{
+		GenesisForkVersion:    os.Getenv("GENESIS_FORK_VERSION"),
+		GenesisValidatorsRoot: os.Getenv("GENESIS_VALIDATORS_ROOT"),
+		BellatrixForkVersion:  os.Getenv("BELLATRIX_FORK_VERSION"),
+		CapellaForkVersion:    os.Getenv("CAPELLA_FORK_VERSION"),
+		CapellaForkEpoch:      int64(cli.GetEnvInt("CAPELLA_FORK_EPOCH", -1)),
+		DenebForkVersion:      os.Getenv("DENEB_FORK_VERSION"),
+		DenebForkEpoch:        int64(cli.GetEnvInt("DENEB_FORK_EPOCH", -1)),
+		ElectraForkVersion:    os.Getenv("ELECTRA_FORK_VERSION"),
+		ElectraForkEpoch:      int64(cli.GetEnvInt("ELECTRA_FORK_EPOCH", -1)),
+		BuilderDomain:         os.Getenv("BUILDER_DOMAIN"),
+	}
+}
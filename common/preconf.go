@@ -0,0 +1,77 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MaxPreconfTransactions is the maximum number of preconfirmed transactions of a bid
+const MaxPreconfTransactions = 256
+
+var (
+	ErrInvalidPreconfTxIndex      = errors.New("invalid preconfirmed transaction index")
+	ErrPreconfCommitmentMismatch  = errors.New("preconfirmation commitment does not match the payload")
+	ErrTooManyPreconfTransactions = fmt.Errorf("too many preconfirmed transactions (maximum is %d)", MaxPreconfTransactions)
+)
+
+// PreconfCommitment is a validated commitment of a bid to preconfirmed transactions
+type PreconfCommitment struct {
+	Slot          uint64          `json:"slot,string"`
+	BlockHash     string          `json:"block_hash"`
+	BuilderPubkey string          `json:"builder_pubkey"`
+	Value         string          `json:"value"`
+	Commitment    phase0.Hash32   `json:"commitment"`
+	TxHashes      []phase0.Hash32 `json:"tx_hashes"`
+	ReceivedAtMs  int64           `json:"received_at_ms,string"`
+}
+
+// ParsePreconfTxIndices parses a comma separated list of transaction indices, i.e. "0,1,5"
+func ParsePreconfTxIndices(s string) ([]int, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) > MaxPreconfTransactions {
+		return nil, ErrTooManyPreconfTransactions
+	}
+	indices := make([]int, len(parts))
+	for i, part := range parts {
+		index, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPreconfTxIndex, part)
+		}
+		indices[i] = index
+	}
+	return indices, nil
+}
+
+// ComputePreconfCommitment returns the commitment to the transactions at the given indices of a payload, which is the
+// keccak256 hash of the concatenated transaction hashes in the given order, together with the transaction hashes
+func ComputePreconfCommitment(txs []bellatrix.Transaction, indices []int) (commitment phase0.Hash32, txHashes []phase0.Hash32, err error) {
+	txHashes = make([]phase0.Hash32, len(indices))
+	data := make([]byte, 0, len(indices)*32)
+	for i, index := range indices {
+		if index >= len(txs) {
+			return commitment, nil, fmt.Errorf("%w: %d, payload has %d transactions", ErrInvalidPreconfTxIndex, index, len(txs))
+		}
+		txHashes[i] = phase0.Hash32(crypto.Keccak256Hash(txs[index]))
+		data = append(data, txHashes[i][:]...)
+	}
+	return phase0.Hash32(crypto.Keccak256Hash(data)), txHashes, nil
+}
+
+// VerifyPreconfCommitment checks that the commitment matches the transactions at the given indices of a payload, and
+// returns their hashes
+func VerifyPreconfCommitment(txs []bellatrix.Transaction, indices []int, commitment phase0.Hash32) ([]phase0.Hash32, error) {
+	computed, txHashes, err := ComputePreconfCommitment(txs, indices)
+	if err != nil {
+		return nil, err
+	}
+	if computed != commitment {
+		return nil, fmt.Errorf("%w: got %s, computed %s", ErrPreconfCommitmentMismatch, commitment.String(), computed.String())
+	}
+	return txHashes, nil
+}
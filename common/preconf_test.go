@@ -0,0 +1,36 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreconfCommitment(t *testing.T) {
+	txs := []bellatrix.Transaction{{0x02, 0x01}, {0x02, 0x02}, {0x02, 0x03}}
+	hash0 := crypto.Keccak256Hash(txs[0])
+	hash2 := crypto.Keccak256Hash(txs[2])
+	expected := phase0.Hash32(crypto.Keccak256Hash(hash2[:], hash0[:]))
+
+	indices, err := ParsePreconfTxIndices("2, 0")
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 0}, indices)
+
+	txHashes, err := VerifyPreconfCommitment(txs, indices, expected)
+	require.NoError(t, err)
+	require.Equal(t, []phase0.Hash32{phase0.Hash32(hash2), phase0.Hash32(hash0)}, txHashes)
+
+	_, err = VerifyPreconfCommitment(txs, []int{0, 2}, expected)
+	require.ErrorIs(t, err, ErrPreconfCommitmentMismatch)
+
+	_, err = VerifyPreconfCommitment(txs, []int{3}, expected)
+	require.ErrorIs(t, err, ErrInvalidPreconfTxIndex)
+
+	_, err = ParsePreconfTxIndices("1,-1")
+	require.ErrorIs(t, err, ErrInvalidPreconfTxIndex)
+	_, err = ParsePreconfTxIndices("1,,2")
+	require.ErrorIs(t, err, ErrInvalidPreconfTxIndex)
+}
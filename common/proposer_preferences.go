@@ -0,0 +1,84 @@
+package common
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// MaxBuilderPreferencesPubkeys is the maximum number of builder pubkeys in each list of the builder preferences
+const MaxBuilderPreferencesPubkeys = 64
+
+// BuilderPreferences are the builders a proposer accepts bids from. If AllowedBuilders is not empty, only bids of
+// these builders are returned in getHeader. Bids of DeniedBuilders are never returned.
+type BuilderPreferences struct {
+	Pubkey          phase0.BLSPubKey   `json:"pubkey" ssz-size:"48"`
+	Timestamp       uint64             `json:"timestamp,string"`
+	AllowedBuilders []phase0.BLSPubKey `json:"allowed_builders" ssz-max:"64" ssz-size:"?,48"`
+	DeniedBuilders  []phase0.BLSPubKey `json:"denied_builders" ssz-max:"64" ssz-size:"?,48"`
+}
+
+// SignedBuilderPreferences are the builder preferences, signed by the proposer's validator key with the builder domain
+type SignedBuilderPreferences struct {
+	Message   *BuilderPreferences `json:"message"`
+	Signature phase0.BLSSignature `json:"signature"`
+}
+
+// IsEmpty returns true if the preferences don't restrict any builders
+func (p *BuilderPreferences) IsEmpty() bool {
+	return len(p.AllowedBuilders) == 0 && len(p.DeniedBuilders) == 0
+}
+
+// AllowsBuilder returns true if the proposer accepts bids from the builder
+func (p *BuilderPreferences) AllowsBuilder(builderPubkey string) bool {
+	for _, pubkey := range p.DeniedBuilders {
+		if pubkey.String() == builderPubkey {
+			return false
+		}
+	}
+	if len(p.AllowedBuilders) == 0 {
+		return true
+	}
+	for _, pubkey := range p.AllowedBuilders {
+		if pubkey.String() == builderPubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// HashTreeRoot ssz hashes the BuilderPreferences object
+func (p *BuilderPreferences) HashTreeRoot() ([32]byte, error) {
+	return ssz.HashWithDefaultHasher(p)
+}
+
+// HashTreeRootWith ssz hashes the BuilderPreferences object with a hasher
+func (p *BuilderPreferences) HashTreeRootWith(hh ssz.HashWalker) (err error) {
+	indx := hh.Index()
+
+	// Field (0) 'Pubkey'
+	hh.PutBytes(p.Pubkey[:])
+
+	// Field (1) 'Timestamp'
+	hh.PutUint64(p.Timestamp)
+
+	// Field (2) 'AllowedBuilders' and Field (3) 'DeniedBuilders'
+	for _, pubkeys := range [][]phase0.BLSPubKey{p.AllowedBuilders, p.DeniedBuilders} {
+		num := uint64(len(pubkeys))
+		if num > MaxBuilderPreferencesPubkeys {
+			return ssz.ErrIncorrectListSize
+		}
+		subIndx := hh.Index()
+		for _, pubkey := range pubkeys {
+			hh.PutBytes(pubkey[:])
+		}
+		hh.MerkleizeWithMixin(subIndx, num, MaxBuilderPreferencesPubkeys)
+	}
+
+	hh.Merkleize(indx)
+	return nil
+}
+
+// GetTree ssz hashes the BuilderPreferences object
+func (p *BuilderPreferences) GetTree() (*ssz.Node, error) {
+	return ssz.ProofTree(p)
+}
@@ -0,0 +1,173 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+// rateLimitMaxIPs is the maximum number of client IPs whose request rate is tracked. The least recently seen IPs are
+// forgotten first, which resets their limit.
+const rateLimitMaxIPs = 100_000
+
+// tokenBucket allows a burst of requests and then refills at rate tokens per second
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	burst := math.Max(1, rate)
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// take removes a token if available, and otherwise returns how long until the next token is available
+func (b *tokenBucket) take(now time.Time) (ok bool, retryAfter time.Duration) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// RateLimiterOpts configures the RateLimiter. A rate of 0 disables that limit.
+type RateLimiterOpts struct {
+	PerIPRate  float64 // requests per second per client IP
+	GlobalRate float64 // requests per second for all clients together
+
+	// IPs or CIDR ranges of reverse proxies, whose X-Forwarded-For header is trusted to determine the client IP
+	TrustedProxies []string
+}
+
+// RateLimiter is an HTTP middleware which limits the requests per second per client IP and globally, and responds
+// with 429 Too Many Requests and a Retry-After header when a limit is exceeded
+type RateLimiter struct {
+	perIPRate      float64
+	trustedProxies []*net.IPNet
+
+	mu     sync.Mutex
+	global *tokenBucket
+	perIP  lru.BasicLRU[string, *tokenBucket]
+}
+
+func NewRateLimiter(opts RateLimiterOpts) (*RateLimiter, error) {
+	l := &RateLimiter{
+		perIPRate: opts.PerIPRate,
+		perIP:     lru.NewBasicLRU[string, *tokenBucket](rateLimitMaxIPs),
+	}
+	if opts.GlobalRate > 0 {
+		l.global = newTokenBucket(opts.GlobalRate, time.Now())
+	}
+
+	for _, proxy := range opts.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			if strings.Contains(proxy, ":") {
+				proxy += "/128"
+			} else {
+				proxy += "/32"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s: %w", proxy, err)
+		}
+		l.trustedProxies = append(l.trustedProxies, ipNet)
+	}
+	return l, nil
+}
+
+func (l *RateLimiter) isTrustedProxy(ip net.IP) bool {
+	for _, ipNet := range l.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client. The X-Forwarded-For header is only used if the request comes from a trusted
+// proxy, and then the client is the rightmost address which isn't a trusted proxy.
+func (l *RateLimiter) ClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !l.isTrustedProxy(ip) {
+		return host
+	}
+
+	forwarded := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		forwardedIP := net.ParseIP(addr)
+		if forwardedIP == nil {
+			break
+		}
+		host = addr
+		if !l.isTrustedProxy(forwardedIP) {
+			break
+		}
+	}
+	return host
+}
+
+// allow checks the per-IP limit first, so that a single client exceeding its limit doesn't use up the global limit
+func (l *RateLimiter) allow(clientIP string) (ok bool, limit float64, retryAfter time.Duration) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perIPRate > 0 {
+		bucket, found := l.perIP.Get(clientIP)
+		if !found {
+			bucket = newTokenBucket(l.perIPRate, now)
+			l.perIP.Add(clientIP, bucket)
+		}
+		if ok, retryAfter := bucket.take(now); !ok {
+			return false, l.perIPRate, retryAfter
+		}
+	}
+
+	if l.global != nil {
+		if ok, retryAfter := l.global.take(now); !ok {
+			return false, l.global.rate, retryAfter
+		}
+	}
+	return true, 0, 0
+}
+
+// Wrap returns the handler with the rate limits applied
+func (l *RateLimiter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ok, limit, retryAfter := l.allow(l.ClientIP(req))
+		if !ok {
+			retryAfterSec := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+			w.Header().Set("Retry-After", retryAfterSec)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(int(math.Ceil(limit))))
+			w.Header().Set("RateLimit-Remaining", "0")
+			w.Header().Set("RateLimit-Reset", retryAfterSec)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(HTTPErrorResp{http.StatusTooManyRequests, "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(2, now)
+
+	ok, _ := bucket.take(now)
+	require.True(t, ok)
+	ok, _ = bucket.take(now)
+	require.True(t, ok)
+	ok, retryAfter := bucket.take(now)
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, retryAfter)
+
+	ok, _ = bucket.take(now.Add(500 * time.Millisecond))
+	require.True(t, ok)
+}
+
+func TestRateLimiterClientIP(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimiterOpts{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "1.2.3.4:1234"
+	req.Header.Set("X-Forwarded-For", "5.6.7.8")
+	require.Equal(t, "1.2.3.4", limiter.ClientIP(req), "untrusted remote")
+
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "9.9.9.9, 5.6.7.8, 192.168.1.1")
+	require.Equal(t, "5.6.7.8", limiter.ClientIP(req), "rightmost untrusted address")
+
+	req.Header.Del("X-Forwarded-For")
+	require.Equal(t, "10.0.0.1", limiter.ClientIP(req), "trusted remote without header")
+
+	_, err = NewRateLimiter(RateLimiterOpts{TrustedProxies: []string{"invalid"}})
+	require.Error(t, err)
+}
+
+func TestRateLimiter(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	get := func(h http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("per IP", func(t *testing.T) {
+		limiter, err := NewRateLimiter(RateLimiterOpts{PerIPRate: 1})
+		require.NoError(t, err)
+		h := limiter.Wrap(handler)
+
+		require.Equal(t, http.StatusOK, get(h, "1.1.1.1:1").Code)
+		rr := get(h, "1.1.1.1:2")
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		require.Equal(t, "1", rr.Header().Get("Retry-After"))
+		require.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+		require.Equal(t, http.StatusOK, get(h, "2.2.2.2:1").Code)
+	})
+
+	t.Run("global", func(t *testing.T) {
+		limiter, err := NewRateLimiter(RateLimiterOpts{GlobalRate: 2})
+		require.NoError(t, err)
+		h := limiter.Wrap(handler)
+
+		require.Equal(t, http.StatusOK, get(h, "1.1.1.1:1").Code)
+		require.Equal(t, http.StatusOK, get(h, "2.2.2.2:1").Code)
+		require.Equal(t, http.StatusTooManyRequests, get(h, "3.3.3.3:1").Code)
+	})
+}
@@ -13,12 +13,16 @@ import (
 	builderApi "github.com/attestantio/go-builder-client/api"
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	builderSpec "github.com/attestantio/go-builder-client/spec"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/ssz"
@@ -81,6 +85,36 @@ var ValidPayloadRegisterValidator = builderApiV1.SignedValidatorRegistration{
 func TestBuilderSubmitBlockRequest(sk *bls.SecretKey, bid *BidTraceV2WithBlobFields, version spec.DataVersion) *VersionedSubmitBlockRequest {
 	signature, err := ssz.SignMessage(bid, ssz.DomainBuilder, sk)
 	check(err, " SignMessage: ", bid, sk)
+	if version == spec.DataVersionElectra {
+		return &VersionedSubmitBlockRequest{
+			VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{ //nolint:exhaustruct
+				Version: spec.DataVersionElectra,
+				Electra: &builderApiElectra.SubmitBlockRequest{
+					Message:   &bid.BidTrace,
+					Signature: signature,
+					ExecutionPayload: &deneb.ExecutionPayload{ //nolint:exhaustruct
+						Transactions:  []bellatrix.Transaction{[]byte{0x03}},
+						Timestamp:     bid.Slot * 12, // 12 seconds per slot.
+						PrevRandao:    _HexToHash("0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2"),
+						Withdrawals:   []*capella.Withdrawal{},
+						BaseFeePerGas: uint256.NewInt(0),
+						BlobGasUsed:   321,
+						ExcessBlobGas: 123,
+					},
+					BlobsBundle: &builderApiDeneb.BlobsBundle{
+						Commitments: []deneb.KZGCommitment{},
+						Proofs:      []deneb.KZGProof{},
+						Blobs:       []deneb.Blob{},
+					},
+					ExecutionRequests: &electra.ExecutionRequests{
+						Deposits:       []*electra.DepositRequest{},
+						Withdrawals:    []*electra.WithdrawalRequest{},
+						Consolidations: []*electra.ConsolidationRequest{},
+					},
+				},
+			},
+		}
+	}
 	if version == spec.DataVersionDeneb {
 		return &VersionedSubmitBlockRequest{
 			VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{ //nolint:exhaustruct
@@ -123,6 +157,74 @@ func TestBuilderSubmitBlockRequest(sk *bls.SecretKey, bid *BidTraceV2WithBlobFie
 	}
 }
 
+// TestExecutionRequests returns execution requests with one request of each type
+func TestExecutionRequests() *electra.ExecutionRequests {
+	pubkey := _HexToPubkey("0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c")
+	address := _HexToAddress("0xdb65fEd33dc262Fe09D9a2Ba8F80b329BA25f941")
+	return &electra.ExecutionRequests{
+		Deposits: []*electra.DepositRequest{{
+			Pubkey:                pubkey,
+			WithdrawalCredentials: make([]byte, 32),
+			Amount:                32000000000,
+			Signature:             phase0.BLSSignature{},
+			Index:                 1,
+		}},
+		Withdrawals: []*electra.WithdrawalRequest{{
+			SourceAddress:   address,
+			ValidatorPubkey: pubkey,
+			Amount:          1000000000,
+		}},
+		Consolidations: []*electra.ConsolidationRequest{{
+			SourceAddress: address,
+			SourcePubkey:  pubkey,
+			TargetPubkey:  pubkey,
+		}},
+	}
+}
+
+// ElectraSubmitBlockRequestFromDeneb turns a deneb block submission into an electra one with the given execution
+// requests. The execution payload and blobs bundle are the same for both forks.
+func ElectraSubmitBlockRequestFromDeneb(payload *VersionedSubmitBlockRequest, executionRequests *electra.ExecutionRequests) *VersionedSubmitBlockRequest {
+	return &VersionedSubmitBlockRequest{
+		VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{ //nolint:exhaustruct
+			Version: spec.DataVersionElectra,
+			Electra: &builderApiElectra.SubmitBlockRequest{
+				Message:           payload.Deneb.Message,
+				ExecutionPayload:  payload.Deneb.ExecutionPayload,
+				BlobsBundle:       payload.Deneb.BlobsBundle,
+				ExecutionRequests: executionRequests,
+				Signature:         payload.Deneb.Signature,
+			},
+		},
+	}
+}
+
+// ElectraBlindedBeaconBlockFromDeneb turns a deneb blinded block into an electra one with the given execution requests.
+// The attestations and attester slashings changed in electra, and are dropped.
+func ElectraBlindedBeaconBlockFromDeneb(block *eth2ApiV1Deneb.BlindedBeaconBlock, executionRequests *electra.ExecutionRequests) *eth2ApiV1Electra.BlindedBeaconBlock {
+	return &eth2ApiV1Electra.BlindedBeaconBlock{
+		Slot:          block.Slot,
+		ProposerIndex: block.ProposerIndex,
+		ParentRoot:    block.ParentRoot,
+		StateRoot:     block.StateRoot,
+		Body: &eth2ApiV1Electra.BlindedBeaconBlockBody{
+			RANDAOReveal:           block.Body.RANDAOReveal,
+			ETH1Data:               block.Body.ETH1Data,
+			Graffiti:               block.Body.Graffiti,
+			ProposerSlashings:      block.Body.ProposerSlashings,
+			AttesterSlashings:      []*electra.AttesterSlashing{},
+			Attestations:           []*electra.Attestation{},
+			Deposits:               block.Body.Deposits,
+			VoluntaryExits:         block.Body.VoluntaryExits,
+			SyncAggregate:          block.Body.SyncAggregate,
+			ExecutionPayloadHeader: block.Body.ExecutionPayloadHeader,
+			BLSToExecutionChanges:  block.Body.BLSToExecutionChanges,
+			BlobKZGCommitments:     block.Body.BlobKZGCommitments,
+			ExecutionRequests:      executionRequests,
+		},
+	}
+}
+
 type CreateTestBlockSubmissionOpts struct {
 	relaySk bls.SecretKey
 	relayPk phase0.BLSPubKey
@@ -134,7 +236,7 @@ type CreateTestBlockSubmissionOpts struct {
 	ProposerPubkey string
 }
 
-func CreateTestBlockSubmission(t *testing.T, builderPubkey string, value *uint256.Int, opts *CreateTestBlockSubmissionOpts) (payload *VersionedSubmitBlockRequest, getPayloadResponse *builderApi.VersionedSubmitBlindedBlockResponse, getHeaderResponse *builderSpec.VersionedSignedBuilderBid) {
+func CreateTestBlockSubmission(t testing.TB, builderPubkey string, value *uint256.Int, opts *CreateTestBlockSubmissionOpts) (payload *VersionedSubmitBlockRequest, getPayloadResponse *builderApi.VersionedSubmitBlindedBlockResponse, getHeaderResponse *builderSpec.VersionedSignedBuilderBid) {
 	t.Helper()
 	var err error
 
@@ -178,7 +280,25 @@ func CreateTestBlockSubmission(t *testing.T, builderPubkey string, value *uint25
 		ProposerPubkey: proposerPk,
 	}
 
-	if version == spec.DataVersionDeneb {
+	switch version { //nolint:exhaustive
+	case spec.DataVersionElectra:
+		payload = &VersionedSubmitBlockRequest{
+			VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{ //nolint:exhaustruct
+				Version: version,
+				Electra: &builderApiElectra.SubmitBlockRequest{
+					Message: bidTrace,
+					ExecutionPayload: &deneb.ExecutionPayload{ //nolint:exhaustruct
+						BaseFeePerGas: uint256.NewInt(0),
+					},
+					BlobsBundle: &builderApiDeneb.BlobsBundle{ //nolint:exhaustruct
+						Commitments: make([]deneb.KZGCommitment, 0),
+					},
+					ExecutionRequests: &electra.ExecutionRequests{}, //nolint:exhaustruct
+					Signature:         phase0.BLSSignature{},
+				},
+			},
+		}
+	case spec.DataVersionDeneb:
 		payload = &VersionedSubmitBlockRequest{
 			VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{ //nolint:exhaustruct
 				Version: version,
@@ -194,7 +314,7 @@ func CreateTestBlockSubmission(t *testing.T, builderPubkey string, value *uint25
 				},
 			},
 		}
-	} else {
+	default:
 		payload = &VersionedSubmitBlockRequest{
 			VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{ //nolint:exhaustruct
 				Version: version,
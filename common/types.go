@@ -9,9 +9,11 @@ import (
 	"strings"
 
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	ssz "github.com/ferranbt/fastssz"
 	boostSsz "github.com/flashbots/go-boost-utils/ssz"
@@ -52,9 +54,34 @@ var (
 	DenebForkVersionGoerli  = "0x04001020"
 	DenebForkVersionMainnet = "0x04000000"
 
+	ElectraForkVersionHolesky = "0x06017000"
+	ElectraForkVersionSepolia = "0x90000074"
+	ElectraForkVersionGoerli  = "" // goerli is deprecated and has no electra fork
+	ElectraForkVersionMainnet = "0x05000000"
+
+	CapellaForkEpochHolesky = int64(256)
+	CapellaForkEpochSepolia = int64(56832)
+	CapellaForkEpochGoerli  = int64(162304)
+	CapellaForkEpochMainnet = int64(194048)
+
+	DenebForkEpochHolesky = int64(29696)
+	DenebForkEpochSepolia = int64(132608)
+	DenebForkEpochGoerli  = int64(231680)
+	DenebForkEpochMainnet = int64(269568)
+
+	ElectraForkEpochHolesky = int64(115968)
+	ElectraForkEpochSepolia = int64(222464)
+	ElectraForkEpochGoerli  = int64(-1)
+	ElectraForkEpochMainnet = int64(364032)
+
 	ForkVersionStringBellatrix = "bellatrix"
 	ForkVersionStringCapella   = "capella"
 	ForkVersionStringDeneb     = "deneb"
+	ForkVersionStringElectra   = "electra"
+
+	// RelayModeMaxProfit accepts all valid blocks, RelayModeFiltered rejects blocks with blocklisted addresses
+	RelayModeMaxProfit = "max_profit"
+	RelayModeFiltered  = "filtered"
 )
 
 type EthNetworkDetails struct {
@@ -64,11 +91,38 @@ type EthNetworkDetails struct {
 	BellatrixForkVersionHex  string
 	CapellaForkVersionHex    string
 	DenebForkVersionHex      string
+	ElectraForkVersionHex    string
+
+	ForkSchedule ForkVersionSchedule
 
 	DomainBuilder                 phase0.Domain
 	DomainBeaconProposerBellatrix phase0.Domain
 	DomainBeaconProposerCapella   phase0.Domain
 	DomainBeaconProposerDeneb     phase0.Domain
+	DomainBeaconProposerElectra   phase0.Domain
+}
+
+// ForkVersionSchedule contains the fork epochs of a network, to derive the fork of a slot. An epoch of -1 means that
+// the fork is not scheduled.
+type ForkVersionSchedule struct {
+	CapellaEpoch int64
+	DenebEpoch   int64
+	ElectraEpoch int64
+}
+
+// ForkAtSlot returns the fork (data version) that is active at the given slot
+func (s ForkVersionSchedule) ForkAtSlot(slot uint64) spec.DataVersion {
+	epoch := SlotToEpoch(slot)
+	switch {
+	case s.ElectraEpoch >= 0 && epoch >= uint64(s.ElectraEpoch):
+		return spec.DataVersionElectra
+	case s.DenebEpoch >= 0 && epoch >= uint64(s.DenebEpoch):
+		return spec.DataVersionDeneb
+	case s.CapellaEpoch >= 0 && epoch >= uint64(s.CapellaEpoch):
+		return spec.DataVersionCapella
+	default:
+		return spec.DataVersionBellatrix
+	}
 }
 
 func NewEthNetworkDetails(networkName string) (ret *EthNetworkDetails, err error) {
@@ -77,10 +131,14 @@ func NewEthNetworkDetails(networkName string) (ret *EthNetworkDetails, err error
 	var bellatrixForkVersion string
 	var capellaForkVersion string
 	var denebForkVersion string
+	var electraForkVersion string
 	var domainBuilder phase0.Domain
 	var domainBeaconProposerBellatrix phase0.Domain
 	var domainBeaconProposerCapella phase0.Domain
 	var domainBeaconProposerDeneb phase0.Domain
+	var domainBeaconProposerElectra phase0.Domain
+	var forkSchedule ForkVersionSchedule
+	var builderDomain string // only set for custom networks
 
 	switch networkName {
 	case EthNetworkHolesky:
@@ -89,35 +147,61 @@ func NewEthNetworkDetails(networkName string) (ret *EthNetworkDetails, err error
 		bellatrixForkVersion = BellatrixForkVersionHolesky
 		capellaForkVersion = CapellaForkVersionHolesky
 		denebForkVersion = DenebForkVersionHolesky
+		electraForkVersion = ElectraForkVersionHolesky
+		forkSchedule = ForkVersionSchedule{CapellaEpoch: CapellaForkEpochHolesky, DenebEpoch: DenebForkEpochHolesky, ElectraEpoch: ElectraForkEpochHolesky}
 	case EthNetworkSepolia:
 		genesisForkVersion = GenesisForkVersionSepolia
 		genesisValidatorsRoot = GenesisValidatorsRootSepolia
 		bellatrixForkVersion = BellatrixForkVersionSepolia
 		capellaForkVersion = CapellaForkVersionSepolia
 		denebForkVersion = DenebForkVersionSepolia
+		electraForkVersion = ElectraForkVersionSepolia
+		forkSchedule = ForkVersionSchedule{CapellaEpoch: CapellaForkEpochSepolia, DenebEpoch: DenebForkEpochSepolia, ElectraEpoch: ElectraForkEpochSepolia}
 	case EthNetworkGoerli:
 		genesisForkVersion = GenesisForkVersionGoerli
 		genesisValidatorsRoot = GenesisValidatorsRootGoerli
 		bellatrixForkVersion = BellatrixForkVersionGoerli
 		capellaForkVersion = CapellaForkVersionGoerli
 		denebForkVersion = DenebForkVersionGoerli
+		electraForkVersion = ElectraForkVersionGoerli
+		forkSchedule = ForkVersionSchedule{CapellaEpoch: CapellaForkEpochGoerli, DenebEpoch: DenebForkEpochGoerli, ElectraEpoch: ElectraForkEpochGoerli}
 	case EthNetworkMainnet:
 		genesisForkVersion = GenesisForkVersionMainnet
 		genesisValidatorsRoot = GenesisValidatorsRootMainnet
 		bellatrixForkVersion = BellatrixForkVersionMainnet
 		capellaForkVersion = CapellaForkVersionMainnet
 		denebForkVersion = DenebForkVersionMainnet
+		electraForkVersion = ElectraForkVersionMainnet
+		forkSchedule = ForkVersionSchedule{CapellaEpoch: CapellaForkEpochMainnet, DenebEpoch: DenebForkEpochMainnet, ElectraEpoch: ElectraForkEpochMainnet}
 	case EthNetworkCustom:
-		genesisForkVersion = os.Getenv("GENESIS_FORK_VERSION")
-		genesisValidatorsRoot = os.Getenv("GENESIS_VALIDATORS_ROOT")
-		bellatrixForkVersion = os.Getenv("BELLATRIX_FORK_VERSION")
-		capellaForkVersion = os.Getenv("CAPELLA_FORK_VERSION")
-		denebForkVersion = os.Getenv("DENEB_FORK_VERSION")
+		cfg := CustomNetworkConfigFromEnv()
+		if fn := os.Getenv("NETWORK_CONFIG_FILE"); fn != "" {
+			cfg, err = LoadCustomNetworkConfig(fn)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load network config file %s: %w", fn, err)
+			}
+		}
+		genesisForkVersion = cfg.GenesisForkVersion
+		genesisValidatorsRoot = cfg.GenesisValidatorsRoot
+		bellatrixForkVersion = cfg.BellatrixForkVersion
+		capellaForkVersion = cfg.CapellaForkVersion
+		denebForkVersion = cfg.DenebForkVersion
+		electraForkVersion = cfg.ElectraForkVersion
+		builderDomain = cfg.BuilderDomain
+		forkSchedule = ForkVersionSchedule{
+			CapellaEpoch: cfg.CapellaForkEpoch,
+			DenebEpoch:   cfg.DenebForkEpoch,
+			ElectraEpoch: cfg.ElectraForkEpoch,
+		}
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrUnknownNetwork, networkName)
 	}
 
-	domainBuilder, err = ComputeDomain(boostSsz.DomainTypeAppBuilder, genesisForkVersion, phase0.Root{}.String())
+	if builderDomain != "" {
+		domainBuilder, err = HexToDomain(builderDomain)
+	} else {
+		domainBuilder, err = ComputeDomain(boostSsz.DomainTypeAppBuilder, genesisForkVersion, phase0.Root{}.String())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +221,13 @@ func NewEthNetworkDetails(networkName string) (ret *EthNetworkDetails, err error
 		return nil, err
 	}
 
+	if electraForkVersion != "" {
+		domainBeaconProposerElectra, err = ComputeDomain(boostSsz.DomainTypeBeaconProposer, electraForkVersion, genesisValidatorsRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &EthNetworkDetails{
 		Name:                          networkName,
 		GenesisForkVersionHex:         genesisForkVersion,
@@ -144,13 +235,35 @@ func NewEthNetworkDetails(networkName string) (ret *EthNetworkDetails, err error
 		BellatrixForkVersionHex:       bellatrixForkVersion,
 		CapellaForkVersionHex:         capellaForkVersion,
 		DenebForkVersionHex:           denebForkVersion,
+		ElectraForkVersionHex:         electraForkVersion,
+		ForkSchedule:                  forkSchedule,
 		DomainBuilder:                 domainBuilder,
 		DomainBeaconProposerBellatrix: domainBeaconProposerBellatrix,
 		DomainBeaconProposerCapella:   domainBeaconProposerCapella,
 		DomainBeaconProposerDeneb:     domainBeaconProposerDeneb,
+		DomainBeaconProposerElectra:   domainBeaconProposerElectra,
 	}, nil
 }
 
+// DomainBeaconProposer returns the proposer signing domain for the given fork
+func (e *EthNetworkDetails) DomainBeaconProposer(version spec.DataVersion) (phase0.Domain, error) {
+	switch version { //nolint:exhaustive
+	case spec.DataVersionBellatrix:
+		return e.DomainBeaconProposerBellatrix, nil
+	case spec.DataVersionCapella:
+		return e.DomainBeaconProposerCapella, nil
+	case spec.DataVersionDeneb:
+		return e.DomainBeaconProposerDeneb, nil
+	case spec.DataVersionElectra:
+		if e.ElectraForkVersionHex == "" {
+			return phase0.Domain{}, ErrInvalidForkVersion
+		}
+		return e.DomainBeaconProposerElectra, nil
+	default:
+		return phase0.Domain{}, ErrInvalidForkVersion
+	}
+}
+
 func (e *EthNetworkDetails) String() string {
 	return fmt.Sprintf(
 		`EthNetworkDetails{
@@ -160,10 +273,12 @@ func (e *EthNetworkDetails) String() string {
 	BellatrixForkVersionHex: %s, 
 	CapellaForkVersionHex: %s, 
 	DenebForkVersionHex: %s,
+	ElectraForkVersionHex: %s,
 	DomainBuilder: %x, 
 	DomainBeaconProposerBellatrix: %x, 
 	DomainBeaconProposerCapella: %x, 
-	DomainBeaconProposerDeneb: %x
+	DomainBeaconProposerDeneb: %x,
+	DomainBeaconProposerElectra: %x
 }`,
 		e.Name,
 		e.GenesisForkVersionHex,
@@ -171,10 +286,12 @@ func (e *EthNetworkDetails) String() string {
 		e.BellatrixForkVersionHex,
 		e.CapellaForkVersionHex,
 		e.DenebForkVersionHex,
+		e.ElectraForkVersionHex,
 		e.DomainBuilder,
 		e.DomainBeaconProposerBellatrix,
 		e.DomainBeaconProposerCapella,
-		e.DomainBeaconProposerDeneb)
+		e.DomainBeaconProposerDeneb,
+		e.DomainBeaconProposerElectra)
 }
 
 type PubkeyHex string
@@ -211,6 +328,16 @@ type BidTraceV2JSON struct {
 	Value                string `json:"value"`
 	NumTx                uint64 `json:"num_tx,string"`
 	BlockNumber          uint64 `json:"block_number,string"`
+
+	// Only for delivered payloads: the relay fee of the builder agreement, and the value net of the relay fee
+	RelayFee      string `json:"relay_fee,omitempty"`
+	AdjustedValue string `json:"adjusted_value,omitempty"`
+
+	// Only for delivered payloads: the relay mode (max_profit or filtered) which was active when it was delivered
+	RelayMode string `json:"relay_mode,omitempty"`
+
+	// Only for delivered payloads: the relay key which signed the bid (empty for payloads delivered before it was recorded)
+	RelayPubkey string `json:"relay_pubkey,omitempty"`
 }
 
 func (b BidTraceV2) MarshalJSON() ([]byte, error) {
@@ -282,11 +409,85 @@ func (b *BidTraceV2JSON) ToCSVRecord() []string {
 	}
 }
 
+// ArchivedBidJSON is a received bid of the bid archive, including rejected bids with the rejection reason
+type ArchivedBidJSON struct {
+	BidTraceV2JSON
+	TimestampMs     int64  `json:"timestamp_ms,string"`
+	Accepted        bool   `json:"accepted"`
+	StatusCode      int    `json:"status_code"`
+	ErrorCode       string `json:"error_code,omitempty"`
+	RejectionReason string `json:"rejection_reason,omitempty"`
+}
+
+// BuilderDemotionJSON is a demotion of an optimistic builder, with the evidence for a collateral refund. A refund is
+// justified if the proposer signed the block (signed_beacon_block is present), which then failed simulation or was
+// rejected by the beacon node.
+type BuilderDemotionJSON struct {
+	Slot           uint64 `json:"slot,string"`
+	Epoch          uint64 `json:"epoch,string"`
+	BuilderPubkey  string `json:"builder_pubkey"`
+	ProposerPubkey string `json:"proposer_pubkey"`
+	Value          string `json:"value"`
+	FeeRecipient   string `json:"fee_recipient"`
+	BlockHash      string `json:"block_hash"`
+	Error          string `json:"error"`
+	DemotedAt      int64  `json:"demoted_at_ms,string"`
+	RefundEligible bool   `json:"refund_eligible"`
+
+	SubmitBlockRequest          json.RawMessage `json:"submit_block_request,omitempty"`
+	SignedBeaconBlock           json.RawMessage `json:"signed_beacon_block,omitempty"`
+	SignedValidatorRegistration json.RawMessage `json:"signed_validator_registration,omitempty"`
+}
+
+// PaymentVerificationJSON is the result of verifying the proposer payment of a delivered payload on the execution layer
+type PaymentVerificationJSON struct {
+	Slot                 uint64 `json:"slot,string"`
+	BlockNumber          uint64 `json:"block_number,string"`
+	BlockHash            string `json:"block_hash"`
+	BuilderPubkey        string `json:"builder_pubkey"`
+	ProposerPubkey       string `json:"proposer_pubkey"`
+	ProposerFeeRecipient string `json:"proposer_fee_recipient"`
+	ExpectedValue        string `json:"expected_value"`
+	ReceivedValue        string `json:"received_value"`
+	Method               string `json:"method"`
+	Status               string `json:"status"`
+	VerifiedAt           int64  `json:"verified_at_ms,string"`
+}
+
+// SlotSummaryJSON is the summary of the auction of a slot
+type SlotSummaryJSON struct {
+	Slot             uint64 `json:"slot,string"`
+	NumBids          uint64 `json:"num_bids,string"`
+	NumBuilders      uint64 `json:"num_builders,string"`
+	NumSimFailures   uint64 `json:"num_sim_failures,string"`
+	HighestValue     string `json:"highest_value"`
+	WinningBlockHash string `json:"winning_block_hash,omitempty"`
+	WinningValue     string `json:"winning_value"`
+	NumGetHeader     uint64 `json:"num_getheader,string"`
+	NumGetPayload    uint64 `json:"num_getpayload,string"`
+	UpdatedAt        int64  `json:"updated_at_ms,string"`
+}
+
+// EpochSummaryJSON is the rollup of an epoch
+type EpochSummaryJSON struct {
+	Epoch                uint64 `json:"epoch,string"`
+	NumRegistrations     uint64 `json:"num_registrations,string"`
+	NumHeadersServed     uint64 `json:"num_headers_served,string"`
+	NumPayloadsDelivered uint64 `json:"num_payloads_delivered,string"`
+	DeliveredValue       string `json:"delivered_value"`
+	UpdatedAt            int64  `json:"updated_at_ms,string"`
+}
+
 type BidTraceV2WithTimestampJSON struct {
 	BidTraceV2JSON
 	Timestamp            int64 `json:"timestamp,string,omitempty"`
 	TimestampMs          int64 `json:"timestamp_ms,string,omitempty"`
 	OptimisticSubmission bool  `json:"optimistic_submission"`
+
+	// Unix nanoseconds of the receive, decode-complete and eligibility times (omitted if unknown or never eligible)
+	TimestampNs  int64 `json:"timestamp_ns,string,omitempty"`
+	DecodedAtNs  int64 `json:"decoded_at_ns,string,omitempty"`
+	EligibleAtNs int64 `json:"eligible_at_ns,string,omitempty"`
 }
 
 func (b *BidTraceV2WithTimestampJSON) CSVHeader() []string {
@@ -305,6 +506,9 @@ func (b *BidTraceV2WithTimestampJSON) CSVHeader() []string {
 		"timestamp",
 		"timestamp_ms",
 		"optimistic_submission",
+		"timestamp_ns",
+		"decoded_at_ns",
+		"eligible_at_ns",
 	}
 }
 
@@ -324,9 +528,35 @@ func (b *BidTraceV2WithTimestampJSON) ToCSVRecord() []string {
 		strconv.FormatInt(b.Timestamp, 10),
 		strconv.FormatInt(b.TimestampMs, 10),
 		strconv.FormatBool(b.OptimisticSubmission),
+		strconv.FormatInt(b.TimestampNs, 10),
+		strconv.FormatInt(b.DecodedAtNs, 10),
+		strconv.FormatInt(b.EligibleAtNs, 10),
 	}
 }
 
+// DeliveredPayloadV2JSON is a delivered payload in the v2 schema of the data API, which adds the ms timestamps and blob
+// fields, and always has the adjusted value
+type DeliveredPayloadV2JSON struct {
+	BidTraceV2JSON
+	TimestampMs   int64  `json:"timestamp_ms,string"` // when the payload was requested, or else saved
+	MsIntoSlot    int64  `json:"ms_into_slot,string"`
+	PublishMs     uint64 `json:"publish_ms,string"`
+	NumBlobs      uint64 `json:"num_blobs,string"`
+	BlobGasUsed   uint64 `json:"blob_gas_used,string"`
+	ExcessBlobGas uint64 `json:"excess_blob_gas,string"`
+}
+
+// BuilderBidV2JSON is a block submission in the v2 schema of the data API, which has all timestamps in ms and adds
+// the blob count
+type BuilderBidV2JSON struct {
+	BidTraceV2JSON
+	TimestampMs  int64  `json:"timestamp_ms,string"`
+	DecodedAtMs  int64  `json:"decoded_at_ms,string,omitempty"`
+	EligibleAtMs int64  `json:"eligible_at_ms,string,omitempty"` // omitted if the bid never became eligible
+	Optimistic   bool   `json:"optimistic"`
+	NumBlobs     uint64 `json:"num_blobs,string"`
+}
+
 type BidTraceV2WithBlobFields struct {
 	builderApiV1.BidTrace
 	BlockNumber   uint64 `db:"block_number"    json:"block_number,string"`
@@ -334,6 +564,16 @@ type BidTraceV2WithBlobFields struct {
 	NumBlobs      uint64 `db:"num_blobs"       json:"num_blobs,string"`
 	BlobGasUsed   uint64 `db:"blob_gas_used"   json:"blob_gas_used,string"`
 	ExcessBlobGas uint64 `db:"excess_blob_gas" json:"excess_blob_gas,string"`
+
+	// RelayPubkey is the relay key which signed the getHeader response of the bid
+	RelayPubkey string `db:"relay_pubkey" json:"relay_pubkey,omitempty"`
+
+	// InstanceID is the relay instance which received the submission of the bid
+	InstanceID string `db:"instance_id" json:"instance_id,omitempty"`
+
+	// ExecutionRequestsRoot is the hash tree root of the execution requests of an electra payload, which the blinded
+	// block of the proposer must include
+	ExecutionRequestsRoot *phase0.Root `db:"-" json:"execution_requests_root,omitempty"`
 }
 
 type BidTraceV2WithBlobFieldsJSON struct {
@@ -351,6 +591,10 @@ type BidTraceV2WithBlobFieldsJSON struct {
 	NumBlobs             uint64 `json:"num_blobs,string"`
 	BlobGasUsed          uint64 `json:"blob_gas_used,string"`
 	ExcessBlobGas        uint64 `json:"excess_blob_gas,string"`
+	RelayPubkey          string `json:"relay_pubkey,omitempty"`
+	InstanceID           string `json:"instance_id,omitempty"`
+
+	ExecutionRequestsRoot *phase0.Root `json:"execution_requests_root,omitempty"`
 }
 
 func (b BidTraceV2WithBlobFields) MarshalJSON() ([]byte, error) {
@@ -369,6 +613,10 @@ func (b BidTraceV2WithBlobFields) MarshalJSON() ([]byte, error) {
 		NumBlobs:             b.NumBlobs,
 		BlobGasUsed:          b.BlobGasUsed,
 		ExcessBlobGas:        b.ExcessBlobGas,
+		RelayPubkey:          b.RelayPubkey,
+		InstanceID:           b.InstanceID,
+
+		ExecutionRequestsRoot: b.ExecutionRequestsRoot,
 	})
 }
 
@@ -379,6 +627,10 @@ func (b *BidTraceV2WithBlobFields) UnmarshalJSON(data []byte) error {
 		NumBlobs      uint64 `json:"num_blobs,string"`
 		BlobGasUsed   uint64 `json:"blob_gas_used,string"`
 		ExcessBlobGas uint64 `json:"excess_blob_gas,string"`
+		RelayPubkey   string `json:"relay_pubkey"`
+		InstanceID    string `json:"instance_id"`
+
+		ExecutionRequestsRoot *phase0.Root `json:"execution_requests_root"`
 	}{}
 	err := json.Unmarshal(data, params)
 	if err != nil {
@@ -389,6 +641,9 @@ func (b *BidTraceV2WithBlobFields) UnmarshalJSON(data []byte) error {
 	b.NumBlobs = params.NumBlobs
 	b.BlobGasUsed = params.BlobGasUsed
 	b.ExcessBlobGas = params.ExcessBlobGas
+	b.RelayPubkey = params.RelayPubkey
+	b.InstanceID = params.InstanceID
+	b.ExecutionRequestsRoot = params.ExecutionRequestsRoot
 
 	bidTrace := new(builderApiV1.BidTrace)
 	err = json.Unmarshal(data, bidTrace)
@@ -403,6 +658,7 @@ type BlockSubmissionInfo struct {
 	BidTrace                   *builderApiV1.BidTrace
 	ExecutionPayloadBlockHash  phase0.Hash32
 	ExecutionPayloadParentHash phase0.Hash32
+	FeeRecipient               bellatrix.ExecutionAddress
 	GasUsed                    uint64
 	GasLimit                   uint64
 	Timestamp                  uint64
@@ -411,9 +667,11 @@ type BlockSubmissionInfo struct {
 	Signature                  phase0.BLSSignature
 	Transactions               []bellatrix.Transaction
 	Withdrawals                []*capella.Withdrawal
+	WithdrawalsRoot            *phase0.Root // only set for header-only submissions, which don't include the withdrawals
 	Blobs                      []deneb.Blob
 	BlobGasUsed                uint64
 	ExcessBlobGas              uint64
+	ExecutionRequests          *electra.ExecutionRequests // from electra
 }
 
 /*
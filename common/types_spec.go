@@ -7,14 +7,17 @@ import (
 	builderApi "github.com/attestantio/go-builder-client/api"
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	builderSpec "github.com/attestantio/go-builder-client/spec"
 	eth2Api "github.com/attestantio/go-eth2-client/api"
 	eth2ApiV1Capella "github.com/attestantio/go-eth2-client/api/v1/capella"
 	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/ssz"
@@ -74,6 +77,20 @@ func BuildGetHeaderResponse(payload *VersionedSubmitBlockRequest, sk *bls.Secret
 			Version: spec.DataVersionDeneb,
 			Deneb:   signedBuilderBid.Deneb,
 		}, nil
+	case spec.DataVersionElectra:
+		versionedPayload.Electra = payload.Electra.ExecutionPayload
+		header, err := utils.PayloadToPayloadHeader(versionedPayload)
+		if err != nil {
+			return nil, err
+		}
+		signedBuilderBid, err := BuilderBlockRequestToSignedBuilderBid(payload, header, sk, pubkey, domain)
+		if err != nil {
+			return nil, err
+		}
+		return &builderSpec.VersionedSignedBuilderBid{
+			Version: spec.DataVersionElectra,
+			Electra: signedBuilderBid.Electra,
+		}, nil
 	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
 		return nil, ErrInvalidVersion
 	default:
@@ -96,6 +113,14 @@ func BuildGetPayloadResponse(payload *VersionedSubmitBlockRequest) (*builderApi.
 				BlobsBundle:      payload.Deneb.BlobsBundle,
 			},
 		}, nil
+	case spec.DataVersionElectra:
+		return &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionElectra,
+			Electra: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+				ExecutionPayload: payload.Electra.ExecutionPayload,
+				BlobsBundle:      payload.Electra.BlobsBundle,
+			},
+		}, nil
 	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
 		return nil, ErrInvalidVersion
 	}
@@ -148,6 +173,27 @@ func BuilderBlockRequestToSignedBuilderBid(payload *VersionedSubmitBlockRequest,
 				Signature: sig,
 			},
 		}, nil
+	case spec.DataVersionElectra:
+		builderBid := builderApiElectra.BuilderBid{
+			Header:             header.Electra,
+			BlobKZGCommitments: payload.Electra.BlobsBundle.Commitments,
+			ExecutionRequests:  payload.Electra.ExecutionRequests,
+			Value:              value,
+			Pubkey:             *pubkey,
+		}
+
+		sig, err := ssz.SignMessage(&builderBid, domain, sk)
+		if err != nil {
+			return nil, err
+		}
+
+		return &builderSpec.VersionedSignedBuilderBid{
+			Version: spec.DataVersionElectra,
+			Electra: &builderApiElectra.SignedBuilderBid{
+				Message:   &builderBid,
+				Signature: sig,
+			},
+		}, nil
 	default:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", payload.Version))
 	}
@@ -170,6 +216,13 @@ func SignedBlindedBeaconBlockToBeaconBlock(signedBlindedBeaconBlock *VersionedSi
 		}
 
 		signedBeaconBlock.Deneb = DenebUnblindSignedBlock(denebBlindedBlock, blockPayload.Deneb)
+	case spec.DataVersionElectra:
+		electraBlindedBlock := signedBlindedBeaconBlock.Electra
+		if len(electraBlindedBlock.Message.Body.BlobKZGCommitments) != len(blockPayload.Electra.BlobsBundle.Blobs) {
+			return nil, errors.New("number of blinded blobs does not match blobs bundle length")
+		}
+
+		signedBeaconBlock.Electra = ElectraUnblindSignedBlock(electraBlindedBlock, blockPayload.Electra)
 	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", signedBlindedBeaconBlock.Version))
 	}
@@ -231,6 +284,37 @@ func DenebUnblindSignedBlock(blindedBlock *eth2ApiV1Deneb.SignedBlindedBeaconBlo
 	}
 }
 
+func ElectraUnblindSignedBlock(blindedBlock *eth2ApiV1Electra.SignedBlindedBeaconBlock, blockPayload *builderApiDeneb.ExecutionPayloadAndBlobsBundle) *eth2ApiV1Electra.SignedBlockContents {
+	return &eth2ApiV1Electra.SignedBlockContents{
+		SignedBlock: &electra.SignedBeaconBlock{
+			Message: &electra.BeaconBlock{
+				Slot:          blindedBlock.Message.Slot,
+				ProposerIndex: blindedBlock.Message.ProposerIndex,
+				ParentRoot:    blindedBlock.Message.ParentRoot,
+				StateRoot:     blindedBlock.Message.StateRoot,
+				Body: &electra.BeaconBlockBody{
+					RANDAOReveal:          blindedBlock.Message.Body.RANDAOReveal,
+					ETH1Data:              blindedBlock.Message.Body.ETH1Data,
+					Graffiti:              blindedBlock.Message.Body.Graffiti,
+					ProposerSlashings:     blindedBlock.Message.Body.ProposerSlashings,
+					AttesterSlashings:     blindedBlock.Message.Body.AttesterSlashings,
+					Attestations:          blindedBlock.Message.Body.Attestations,
+					Deposits:              blindedBlock.Message.Body.Deposits,
+					VoluntaryExits:        blindedBlock.Message.Body.VoluntaryExits,
+					SyncAggregate:         blindedBlock.Message.Body.SyncAggregate,
+					ExecutionPayload:      blockPayload.ExecutionPayload,
+					BLSToExecutionChanges: blindedBlock.Message.Body.BLSToExecutionChanges,
+					BlobKZGCommitments:    blindedBlock.Message.Body.BlobKZGCommitments,
+					ExecutionRequests:     blindedBlock.Message.Body.ExecutionRequests,
+				},
+			},
+			Signature: blindedBlock.Signature,
+		},
+		KZGProofs: blockPayload.BlobsBundle.Proofs,
+		Blobs:     blockPayload.BlobsBundle.Blobs,
+	}
+}
+
 type BuilderBlockValidationRequest struct {
 	*VersionedSubmitBlockRequest
 	RegisteredGasLimit    uint64
@@ -253,6 +337,16 @@ type denebBuilderBlockValidationRequestJSON struct {
 	ParentBeaconBlockRoot string                       `json:"parent_beacon_block_root"`
 }
 
+type electraBuilderBlockValidationRequestJSON struct {
+	Message               *builderApiV1.BidTrace       `json:"message"`
+	ExecutionPayload      *deneb.ExecutionPayload      `json:"execution_payload"`
+	BlobsBundle           *builderApiDeneb.BlobsBundle `json:"blobs_bundle"`
+	ExecutionRequests     *electra.ExecutionRequests   `json:"execution_requests"`
+	Signature             string                       `json:"signature"`
+	RegisteredGasLimit    uint64                       `json:"registered_gas_limit,string"`
+	ParentBeaconBlockRoot string                       `json:"parent_beacon_block_root"`
+}
+
 func (r *BuilderBlockValidationRequest) MarshalJSON() ([]byte, error) {
 	switch r.Version { //nolint:exhaustive
 	case spec.DataVersionCapella:
@@ -271,6 +365,16 @@ func (r *BuilderBlockValidationRequest) MarshalJSON() ([]byte, error) {
 			RegisteredGasLimit:    r.RegisteredGasLimit,
 			ParentBeaconBlockRoot: r.ParentBeaconBlockRoot.String(),
 		})
+	case spec.DataVersionElectra:
+		return json.Marshal(&electraBuilderBlockValidationRequestJSON{
+			Message:               r.Electra.Message,
+			ExecutionPayload:      r.Electra.ExecutionPayload,
+			BlobsBundle:           r.Electra.BlobsBundle,
+			ExecutionRequests:     r.Electra.ExecutionRequests,
+			Signature:             r.Electra.Signature.String(),
+			RegisteredGasLimit:    r.RegisteredGasLimit,
+			ParentBeaconBlockRoot: r.ParentBeaconBlockRoot.String(),
+		})
 	default:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", r.Version))
 	}
@@ -286,6 +390,8 @@ func (r *VersionedSubmitBlockRequest) MarshalSSZ() ([]byte, error) {
 		return r.Capella.MarshalSSZ()
 	case spec.DataVersionDeneb:
 		return r.Deneb.MarshalSSZ()
+	case spec.DataVersionElectra:
+		return r.Electra.MarshalSSZ()
 	default:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", r.Version))
 	}
@@ -294,6 +400,13 @@ func (r *VersionedSubmitBlockRequest) MarshalSSZ() ([]byte, error) {
 func (r *VersionedSubmitBlockRequest) UnmarshalSSZ(input []byte) error {
 	var err error
 
+	electraRequest := new(builderApiElectra.SubmitBlockRequest)
+	if err = electraRequest.UnmarshalSSZ(input); err == nil {
+		r.Version = spec.DataVersionElectra
+		r.Electra = electraRequest
+		return nil
+	}
+
 	denebRequest := new(builderApiDeneb.SubmitBlockRequest)
 	if err = denebRequest.UnmarshalSSZ(input); err == nil {
 		r.Version = spec.DataVersionDeneb
@@ -316,6 +429,8 @@ func (r *VersionedSubmitBlockRequest) HashTreeRoot() (phase0.Root, error) {
 		return r.Capella.HashTreeRoot()
 	case spec.DataVersionDeneb:
 		return r.Deneb.HashTreeRoot()
+	case spec.DataVersionElectra:
+		return r.Electra.HashTreeRoot()
 	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
 		fallthrough
 	default:
@@ -329,6 +444,8 @@ func (r *VersionedSubmitBlockRequest) MarshalJSON() ([]byte, error) {
 		return json.Marshal(r.Capella)
 	case spec.DataVersionDeneb:
 		return json.Marshal(r.Deneb)
+	case spec.DataVersionElectra:
+		return json.Marshal(r.Electra)
 	default:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", r.Version))
 	}
@@ -336,6 +453,13 @@ func (r *VersionedSubmitBlockRequest) MarshalJSON() ([]byte, error) {
 
 func (r *VersionedSubmitBlockRequest) UnmarshalJSON(input []byte) error {
 	var err error
+	electraRequest := new(builderApiElectra.SubmitBlockRequest)
+	if err = json.Unmarshal(input, electraRequest); err == nil {
+		r.Version = spec.DataVersionElectra
+		r.Electra = electraRequest
+		return nil
+	}
+
 	denebRequest := new(builderApiDeneb.SubmitBlockRequest)
 	if err = json.Unmarshal(input, denebRequest); err == nil {
 		r.Version = spec.DataVersionDeneb
@@ -362,6 +486,8 @@ func (r *VersionedSignedProposal) MarshalSSZ() ([]byte, error) {
 		return r.Capella.MarshalSSZ()
 	case spec.DataVersionDeneb:
 		return r.Deneb.MarshalSSZ()
+	case spec.DataVersionElectra:
+		return r.Electra.MarshalSSZ()
 	default:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", r.Version))
 	}
@@ -369,6 +495,13 @@ func (r *VersionedSignedProposal) MarshalSSZ() ([]byte, error) {
 
 func (r *VersionedSignedProposal) UnmarshalSSZ(input []byte) error {
 	var err error
+	electraRequest := new(eth2ApiV1Electra.SignedBlockContents)
+	if err = electraRequest.UnmarshalSSZ(input); err == nil {
+		r.Version = spec.DataVersionElectra
+		r.Electra = electraRequest
+		return nil
+	}
+
 	denebRequest := new(eth2ApiV1Deneb.SignedBlockContents)
 	if err = denebRequest.UnmarshalSSZ(input); err == nil {
 		r.Version = spec.DataVersionDeneb
@@ -391,6 +524,8 @@ func (r *VersionedSignedProposal) MarshalJSON() ([]byte, error) {
 		return json.Marshal(r.Capella)
 	case spec.DataVersionDeneb:
 		return json.Marshal(r.Deneb)
+	case spec.DataVersionElectra:
+		return json.Marshal(r.Electra)
 	default:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", r.Version))
 	}
@@ -399,6 +534,13 @@ func (r *VersionedSignedProposal) MarshalJSON() ([]byte, error) {
 func (r *VersionedSignedProposal) UnmarshalJSON(input []byte) error {
 	var err error
 
+	electraContents := new(eth2ApiV1Electra.SignedBlockContents)
+	if err = json.Unmarshal(input, electraContents); err == nil {
+		r.Version = spec.DataVersionElectra
+		r.Electra = electraContents
+		return nil
+	}
+
 	denebContents := new(eth2ApiV1Deneb.SignedBlockContents)
 	if err = json.Unmarshal(input, denebContents); err == nil {
 		r.Version = spec.DataVersionDeneb
@@ -425,6 +567,8 @@ func (r *VersionedSignedBlindedBeaconBlock) MarshalJSON() ([]byte, error) {
 		return json.Marshal(r.Capella)
 	case spec.DataVersionDeneb:
 		return json.Marshal(r.Deneb)
+	case spec.DataVersionElectra:
+		return json.Marshal(r.Electra)
 	default:
 		return nil, errors.Wrap(ErrInvalidVersion, fmt.Sprintf("%s is not supported", r.Version))
 	}
@@ -433,6 +577,13 @@ func (r *VersionedSignedBlindedBeaconBlock) MarshalJSON() ([]byte, error) {
 func (r *VersionedSignedBlindedBeaconBlock) UnmarshalJSON(input []byte) error {
 	var err error
 
+	electraBlock := new(eth2ApiV1Electra.SignedBlindedBeaconBlock)
+	if err = json.Unmarshal(input, electraBlock); err == nil {
+		r.Version = spec.DataVersionElectra
+		r.Electra = electraBlock
+		return nil
+	}
+
 	denebBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
 	if err = json.Unmarshal(input, denebBlock); err == nil {
 		r.Version = spec.DataVersionDeneb
@@ -5,7 +5,15 @@ import (
 	"encoding/json"
 	"testing"
 
+	builderApi "github.com/attestantio/go-builder-client/api"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
 	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -135,3 +143,118 @@ func TestBuildGetPayloadResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestElectraSubmitBlockRequest(t *testing.T) {
+	denebSubmission := new(VersionedSubmitBlockRequest)
+	LoadGzippedJSON(t, "../testdata/submitBlockPayloadDeneb_Goerli.json.gz", denebSubmission)
+	submission := ElectraSubmitBlockRequestFromDeneb(denebSubmission, TestExecutionRequests())
+
+	// JSON and SSZ round trips keep the version and the execution requests
+	jsonBytes, err := json.Marshal(submission)
+	require.NoError(t, err)
+	fromJSON := new(VersionedSubmitBlockRequest)
+	require.NoError(t, json.Unmarshal(jsonBytes, fromJSON))
+	require.Equal(t, spec.DataVersionElectra, fromJSON.Version)
+	require.Equal(t, submission.Electra.ExecutionRequests, fromJSON.Electra.ExecutionRequests)
+
+	sszBytes, err := submission.MarshalSSZ()
+	require.NoError(t, err)
+	fromSSZ := new(VersionedSubmitBlockRequest)
+	require.NoError(t, fromSSZ.UnmarshalSSZ(sszBytes))
+	require.Equal(t, spec.DataVersionElectra, fromSSZ.Version)
+	require.Equal(t, submission.Electra.ExecutionRequests, fromSSZ.Electra.ExecutionRequests)
+
+	htr, err := submission.HashTreeRoot()
+	require.NoError(t, err)
+	electraHtr, err := submission.Electra.HashTreeRoot()
+	require.NoError(t, err)
+	require.Equal(t, phase0.Root(electraHtr), htr)
+
+	// The getPayload response has the payload and blobs bundle
+	resp, err := BuildGetPayloadResponse(submission)
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionElectra, resp.Version)
+	require.Equal(t, submission.Electra.ExecutionPayload, resp.Electra.ExecutionPayload)
+	require.Equal(t, submission.Electra.BlobsBundle, resp.Electra.BlobsBundle)
+
+	// The getHeader response has the execution requests, and is signed by the relay
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := utils.BlsPublicKeyToPublicKey(blsPubkey)
+	require.NoError(t, err)
+	bid, err := BuildGetHeaderResponse(submission, sk, &pubkey, ssz.DomainBuilder)
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionElectra, bid.Version)
+	require.Equal(t, submission.Electra.ExecutionRequests, bid.Electra.Message.ExecutionRequests)
+	require.Equal(t, submission.Electra.BlobsBundle.Commitments, bid.Electra.Message.BlobKZGCommitments)
+	require.Equal(t, submission.Electra.ExecutionPayload.BlockHash, bid.Electra.Message.Header.BlockHash)
+	ok, err := ssz.VerifySignature(bid.Electra.Message, ssz.DomainBuilder, pubkey[:], bid.Electra.Signature[:])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The block validation request has the execution requests
+	validationJSON, err := json.Marshal(&BuilderBlockValidationRequest{VersionedSubmitBlockRequest: submission, ParentBeaconBlockRoot: &phase0.Root{}})
+	require.NoError(t, err)
+	validationRequest := struct {
+		ExecutionRequests *electra.ExecutionRequests `json:"execution_requests"`
+	}{}
+	require.NoError(t, json.Unmarshal(validationJSON, &validationRequest))
+	require.Equal(t, submission.Electra.ExecutionRequests, validationRequest.ExecutionRequests)
+}
+
+func TestElectraUnblindSignedBlock(t *testing.T) {
+	denebSubmission := new(VersionedSubmitBlockRequest)
+	LoadGzippedJSON(t, "../testdata/submitBlockPayloadDeneb_Goerli.json.gz", denebSubmission)
+	submission := ElectraSubmitBlockRequestFromDeneb(denebSubmission, TestExecutionRequests())
+	payload, err := BuildGetPayloadResponse(submission)
+	require.NoError(t, err)
+	header, err := utils.PayloadToPayloadHeader(&builderApi.VersionedExecutionPayload{Version: spec.DataVersionElectra, Electra: submission.Electra.ExecutionPayload})
+	require.NoError(t, err)
+
+	denebBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	LoadGzippedJSON(t, "../testdata/signedBlindedBeaconBlockDeneb_Goerli.json.gz", denebBlock)
+	blindedBlock := ElectraBlindedBeaconBlockFromDeneb(denebBlock.Message, TestExecutionRequests())
+	blindedBlock.Body.ExecutionPayloadHeader = header.Electra
+	blindedBlock.Body.BlobKZGCommitments = submission.Electra.BlobsBundle.Commitments
+	signedBlindedBlock := &VersionedSignedBlindedBeaconBlock{}
+	signedBlindedBlock.Version = spec.DataVersionElectra
+	signedBlindedBlock.Electra = &eth2ApiV1Electra.SignedBlindedBeaconBlock{Message: blindedBlock, Signature: denebBlock.Signature}
+
+	// The blinded block is decoded as electra
+	blindedJSON, err := json.Marshal(signedBlindedBlock)
+	require.NoError(t, err)
+	decodedBlindedBlock := new(VersionedSignedBlindedBeaconBlock)
+	require.NoError(t, json.Unmarshal(blindedJSON, decodedBlindedBlock))
+	require.Equal(t, spec.DataVersionElectra, decodedBlindedBlock.Version)
+
+	// The unblinded block has the payload, the blobs and the execution requests, and the same root
+	proposal, err := SignedBlindedBeaconBlockToBeaconBlock(signedBlindedBlock, payload)
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionElectra, proposal.Version)
+	block := proposal.Electra.SignedBlock.Message
+	require.Equal(t, submission.Electra.ExecutionPayload, block.Body.ExecutionPayload)
+	require.Equal(t, blindedBlock.Body.ExecutionRequests, block.Body.ExecutionRequests)
+	require.Equal(t, submission.Electra.BlobsBundle.Blobs, proposal.Electra.Blobs)
+	blindedRoot, err := blindedBlock.HashTreeRoot()
+	require.NoError(t, err)
+	root, err := block.HashTreeRoot()
+	require.NoError(t, err)
+	require.Equal(t, blindedRoot, root)
+
+	// The proposal is decoded as electra, from JSON and SSZ
+	proposalJSON, err := json.Marshal(proposal)
+	require.NoError(t, err)
+	decodedProposal := new(VersionedSignedProposal)
+	require.NoError(t, json.Unmarshal(proposalJSON, decodedProposal))
+	require.Equal(t, spec.DataVersionElectra, decodedProposal.Version)
+	proposalSSZ, err := proposal.MarshalSSZ()
+	require.NoError(t, err)
+	decodedProposal = new(VersionedSignedProposal)
+	require.NoError(t, decodedProposal.UnmarshalSSZ(proposalSSZ))
+	require.Equal(t, spec.DataVersionElectra, decodedProposal.Version)
+
+	// The blob count must match
+	signedBlindedBlock.Electra.Message.Body.BlobKZGCommitments = nil
+	_, err = SignedBlindedBeaconBlockToBeaconBlock(signedBlindedBlock, payload)
+	require.Error(t, err)
+}
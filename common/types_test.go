@@ -1,6 +1,8 @@
 package common
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
@@ -8,6 +10,8 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/ssz"
 	"github.com/flashbots/go-boost-utils/utils"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
@@ -25,7 +29,7 @@ func makeTestSubmitBlockRequestV2Optimistic(t *testing.T) *SubmitBlockRequestV2O
 	require.NoError(t, err)
 	testBuilderPubkey, err := utils.HexToPubkey("0xae7bde4839fa905b7d8125fd84cfdcd0c32cd74e1be3fa24263d71b520fc78113326ce0a90b95d73f19e6d8150a2f73b")
 	require.NoError(t, err)
-	testProposerPubkey, err := utils.HexToPubkey("0xbb8e223239fa905b7d8125fd84cfdcd0c32cd74e1be3fa24263d71b520fc78113326ce0a90b95d73f19e6d8150a2f73b")
+	testProposerPubkey, err := utils.HexToPubkey("0xb372bb1e1e66489d12f4565d97e82d368d2229a337531a2499119630d082b112443e1c96c99ba9eec33174c53300c6b1")
 	require.NoError(t, err)
 	testAddress, err := utils.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5")
 	require.NoError(t, err)
@@ -132,3 +136,94 @@ func TestSubmitBlockRequestV2Optimistic(t *testing.T) {
 	// Make sure size is correct (must have 32 bytes of ExtraData).
 	require.Equal(t, 944, unmarshalHeader.SizeSSZ())
 }
+
+func TestForkVersionSchedule(t *testing.T) {
+	schedule := ForkVersionSchedule{CapellaEpoch: 1, DenebEpoch: 2, ElectraEpoch: -1}
+	require.Equal(t, spec.DataVersionBellatrix, schedule.ForkAtSlot(0))
+	require.Equal(t, spec.DataVersionCapella, schedule.ForkAtSlot(SlotsPerEpoch))
+	require.Equal(t, spec.DataVersionCapella, schedule.ForkAtSlot(2*SlotsPerEpoch-1))
+	require.Equal(t, spec.DataVersionDeneb, schedule.ForkAtSlot(2*SlotsPerEpoch))
+
+	schedule.ElectraEpoch = 3
+	require.Equal(t, spec.DataVersionDeneb, schedule.ForkAtSlot(3*SlotsPerEpoch-1))
+	require.Equal(t, spec.DataVersionElectra, schedule.ForkAtSlot(3*SlotsPerEpoch))
+
+	// Deneb not scheduled
+	schedule = ForkVersionSchedule{CapellaEpoch: 0, DenebEpoch: -1, ElectraEpoch: -1}
+	require.Equal(t, spec.DataVersionCapella, schedule.ForkAtSlot(100*SlotsPerEpoch))
+
+	mainnet, err := NewEthNetworkDetails(EthNetworkMainnet)
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionDeneb, mainnet.ForkSchedule.ForkAtSlot(uint64(DenebForkEpochMainnet)*SlotsPerEpoch))
+	require.Equal(t, spec.DataVersionCapella, mainnet.ForkSchedule.ForkAtSlot(uint64(DenebForkEpochMainnet)*SlotsPerEpoch-1))
+	domain, err := mainnet.DomainBeaconProposer(spec.DataVersionDeneb)
+	require.NoError(t, err)
+	require.Equal(t, mainnet.DomainBeaconProposerDeneb, domain)
+
+	require.Equal(t, spec.DataVersionElectra, mainnet.ForkSchedule.ForkAtSlot(uint64(ElectraForkEpochMainnet)*SlotsPerEpoch))
+	domain, err = mainnet.DomainBeaconProposer(spec.DataVersionElectra)
+	require.NoError(t, err)
+	require.Equal(t, mainnet.DomainBeaconProposerElectra, domain)
+	require.NotEqual(t, mainnet.DomainBeaconProposerDeneb, domain)
+
+	// Goerli has no electra fork
+	goerli, err := NewEthNetworkDetails(EthNetworkGoerli)
+	require.NoError(t, err)
+	_, err = goerli.DomainBeaconProposer(spec.DataVersionElectra)
+	require.ErrorIs(t, err, ErrInvalidForkVersion)
+}
+
+func TestCustomNetworkConfigFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "network.yaml")
+	cfg := `genesis_fork_version: "0x10000038"
+genesis_validators_root: "0x83431ec7fcf92cfc44947fc0418e831c25e1d0806590231c439830db7ad54fda"
+bellatrix_fork_version: "0x30000038"
+capella_fork_version: "0x40000038"
+capella_fork_epoch: 10
+deneb_fork_version: "0x50000038"
+`
+	require.NoError(t, os.WriteFile(fn, []byte(cfg), 0o600))
+	t.Setenv("NETWORK_CONFIG_FILE", fn)
+
+	network, err := NewEthNetworkDetails(EthNetworkCustom)
+	require.NoError(t, err)
+	require.Equal(t, "0x10000038", network.GenesisForkVersionHex)
+	require.Equal(t, "0x40000038", network.CapellaForkVersionHex)
+	require.Equal(t, ForkVersionSchedule{CapellaEpoch: 10, DenebEpoch: -1, ElectraEpoch: -1}, network.ForkSchedule)
+
+	// The builder domain is computed from the genesis fork version unless set explicitly
+	domain, err := ComputeDomain(ssz.DomainTypeAppBuilder, "0x10000038", phase0.Root{}.String())
+	require.NoError(t, err)
+	require.Equal(t, domain, network.DomainBuilder)
+
+	builderDomain := "0x00000001d3010778cd08ee514b08fe67b6c503b510987a4ce43f42306d97c67c"
+	require.NoError(t, os.WriteFile(fn, []byte(cfg+"builder_domain: \""+builderDomain+"\"\n"), 0o600))
+	network, err = NewEthNetworkDetails(EthNetworkCustom)
+	require.NoError(t, err)
+	require.Equal(t, builderDomain, hexutil.Encode(network.DomainBuilder[:]))
+}
+
+func TestBuilderPreferencesAllowsBuilder(t *testing.T) {
+	builder1 := phase0.BLSPubKey{0x01}
+	builder2 := phase0.BLSPubKey{0x02}
+
+	preferences := &BuilderPreferences{}
+	require.True(t, preferences.IsEmpty())
+	require.True(t, preferences.AllowsBuilder(builder1.String()))
+
+	preferences.DeniedBuilders = []phase0.BLSPubKey{builder1}
+	require.False(t, preferences.AllowsBuilder(builder1.String()))
+	require.True(t, preferences.AllowsBuilder(builder2.String()))
+
+	preferences.DeniedBuilders = nil
+	preferences.AllowedBuilders = []phase0.BLSPubKey{builder1}
+	require.True(t, preferences.AllowsBuilder(builder1.String()))
+	require.False(t, preferences.AllowsBuilder(builder2.String()))
+
+	// Lists are limited to MaxBuilderPreferencesPubkeys entries
+	_, err := preferences.HashTreeRoot()
+	require.NoError(t, err)
+	preferences.AllowedBuilders = make([]phase0.BLSPubKey, MaxBuilderPreferencesPubkeys+1)
+	_, err = preferences.HashTreeRoot()
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"os"
 	"strconv"
@@ -17,7 +18,9 @@ import (
 	builderApi "github.com/attestantio/go-builder-client/api"
 	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
 	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -84,6 +87,19 @@ func ComputeDomain(domainType phase0.DomainType, forkVersionHex, genesisValidato
 	return ssz.ComputeDomain(domainType, forkVersion, genesisValidatorsRoot), nil
 }
 
+// HexToDomain parses a hex-encoded signing domain
+func HexToDomain(s string) (domain phase0.Domain, err error) {
+	domainBytes, err := hexutil.Decode(s)
+	if err != nil {
+		return domain, err
+	}
+	if len(domainBytes) != len(domain) {
+		return domain, ErrIncorrectLength
+	}
+	copy(domain[:], domainBytes)
+	return domain, nil
+}
+
 func GetEnv(key, defaultValue string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -128,6 +144,12 @@ func U256StrToUint256(s types.U256Str) *uint256.Int {
 	return i
 }
 
+// RelayFee returns the relay fee for a bid value, given the fee of the builder agreement in basis points
+func RelayFee(value *big.Int, relayFeeBps uint64) *big.Int {
+	fee := new(big.Int).Mul(value, new(big.Int).SetUint64(relayFeeBps))
+	return fee.Div(fee, big.NewInt(10_000))
+}
+
 func reverse(src []byte) []byte {
 	dst := make([]byte, len(src))
 	copy(dst, src)
@@ -229,20 +251,35 @@ func GetBlockSubmissionInfo(submission *VersionedSubmitBlockRequest) (*BlockSubm
 	}
 	// TODO (deneb): after deneb fork error if no blob fields
 	var (
-		blobs         []deneb.Blob
-		blobGasUsed   uint64
-		excessBlobGas uint64
+		feeRecipient      bellatrix.ExecutionAddress
+		blobs             []deneb.Blob
+		blobGasUsed       uint64
+		excessBlobGas     uint64
+		executionRequests *electra.ExecutionRequests
 	)
-	if submission.Version == spec.DataVersionDeneb {
+	switch submission.Version {
+	case spec.DataVersionCapella:
+		feeRecipient = submission.Capella.ExecutionPayload.FeeRecipient
+	case spec.DataVersionDeneb:
+		feeRecipient = submission.Deneb.ExecutionPayload.FeeRecipient
 		blobs = submission.Deneb.BlobsBundle.Blobs
 		blobGasUsed = submission.Deneb.ExecutionPayload.BlobGasUsed
 		excessBlobGas = submission.Deneb.ExecutionPayload.ExcessBlobGas
+	case spec.DataVersionElectra:
+		feeRecipient = submission.Electra.ExecutionPayload.FeeRecipient
+		blobs = submission.Electra.BlobsBundle.Blobs
+		blobGasUsed = submission.Electra.ExecutionPayload.BlobGasUsed
+		excessBlobGas = submission.Electra.ExecutionPayload.ExcessBlobGas
+		executionRequests = submission.Electra.ExecutionRequests
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
+		return nil, ErrInvalidForkVersion
 	}
 	return &BlockSubmissionInfo{
 		BidTrace:                   bidTrace,
 		Signature:                  signature,
 		ExecutionPayloadBlockHash:  executionPayloadBlockHash,
 		ExecutionPayloadParentHash: executionPayloadParentHash,
+		FeeRecipient:               feeRecipient,
 		GasUsed:                    gasUsed,
 		GasLimit:                   gasLimit,
 		Timestamp:                  timestamp,
@@ -253,6 +290,7 @@ func GetBlockSubmissionInfo(submission *VersionedSubmitBlockRequest) (*BlockSubm
 		Blobs:                      blobs,
 		BlobGasUsed:                blobGasUsed,
 		ExcessBlobGas:              excessBlobGas,
+		ExecutionRequests:          executionRequests,
 	}, nil
 }
 
@@ -271,6 +309,14 @@ func GetBlockSubmissionExecutionPayload(submission *VersionedSubmitBlockRequest)
 				BlobsBundle:      submission.Deneb.BlobsBundle,
 			},
 		}, nil
+	case spec.DataVersionElectra:
+		return &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionElectra,
+			Electra: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+				ExecutionPayload: submission.Electra.ExecutionPayload,
+				BlobsBundle:      submission.Electra.BlobsBundle,
+			},
+		}, nil
 	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
 		return nil, ErrInvalidForkVersion
 	}
@@ -3,17 +3,22 @@ package common
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"net/http"
 	"os"
 	"testing"
 
 	builderApiBellatrix "github.com/attestantio/go-builder-client/api/bellatrix"
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	builderSpec "github.com/attestantio/go-builder-client/spec"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/ethereum/go-ethereum/common"
 	boostTypes "github.com/flashbots/go-boost-utils/types"
 	"github.com/stretchr/testify/require"
@@ -87,6 +92,13 @@ func TestU256StrToUint256(t *testing.T) {
 	}
 }
 
+func TestRelayFee(t *testing.T) {
+	require.Equal(t, "0", RelayFee(big.NewInt(1_000_000), 0).String())
+	require.Equal(t, "2500", RelayFee(big.NewInt(1_000_000), 25).String())
+	require.Equal(t, "1000000", RelayFee(big.NewInt(1_000_000), 10_000).String())
+	require.Equal(t, "0", RelayFee(big.NewInt(399), 25).String()) // rounded down
+}
+
 func TestGetEnvStrSlice(t *testing.T) {
 	testEnvVar := "TESTENV_TestGetEnvStrSlice"
 	os.Unsetenv(testEnvVar)
@@ -128,6 +140,26 @@ func TestGetBlockSubmissionInfo(t *testing.T) {
 				BidTrace: &builderApiV1.BidTrace{},
 			},
 		},
+		{
+			name: "valid builderApiElectra",
+			payload: &VersionedSubmitBlockRequest{
+				VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{
+					Version: spec.DataVersionElectra,
+					Electra: &builderApiElectra.SubmitBlockRequest{
+						Message:           &builderApiV1.BidTrace{},
+						ExecutionPayload:  &deneb.ExecutionPayload{BlobGasUsed: 1, ExcessBlobGas: 2},
+						BlobsBundle:       &builderApiDeneb.BlobsBundle{},
+						ExecutionRequests: &electra.ExecutionRequests{},
+					},
+				},
+			},
+			expected: &BlockSubmissionInfo{
+				BidTrace:          &builderApiV1.BidTrace{},
+				BlobGasUsed:       1,
+				ExcessBlobGas:     2,
+				ExecutionRequests: &electra.ExecutionRequests{},
+			},
+		},
 		{
 			name: "unsupported version",
 			payload: &VersionedSubmitBlockRequest{
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+)
+
+// BuilderSubmissionWithPayloadEntry is a block submission joined with its stored execution payload
+type BuilderSubmissionWithPayloadEntry struct {
+	ID          int64  `db:"id"`
+	Slot        uint64 `db:"slot"`
+	BlockHash   string `db:"block_hash"`
+	BlockNumber uint64 `db:"block_number"`
+	GasUsed     uint64 `db:"gas_used"`
+	GasLimit    uint64 `db:"gas_limit"`
+	NumTx       uint64 `db:"num_tx"`
+	NumBlobs    uint64 `db:"num_blobs"`
+
+	PayloadVersion string `db:"payload_version"`
+	Payload        string `db:"payload"`
+}
+
+// BuilderSubmissionBackfill are the columns of a block submission which are derived from its execution payload
+type BuilderSubmissionBackfill struct {
+	ID       int64  `db:"id"`
+	NumTx    uint64 `db:"num_tx"`
+	NumBlobs uint64 `db:"num_blobs"`
+}
+
+// ComputeBuilderSubmissionBackfill derives the columns of a block submission from its stored execution payload. It
+// returns whether they differ from the stored ones, and the mismatches between the bid trace and the payload (block
+// hash, block number and gas), which are not fixed.
+func ComputeBuilderSubmissionBackfill(entry *BuilderSubmissionWithPayloadEntry) (backfill *BuilderSubmissionBackfill, changed bool, mismatches []string, err error) {
+	payload, err := ExecutionPayloadEntryToExecutionPayload(&ExecutionPayloadEntry{Version: entry.PayloadVersion, Payload: entry.Payload})
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	var blockHash string
+	var blockNumber, gasUsed, gasLimit uint64
+	backfill = &BuilderSubmissionBackfill{ID: entry.ID}
+	switch payload.Version {
+	case spec.DataVersionCapella:
+		blockHash = payload.Capella.BlockHash.String()
+		blockNumber = payload.Capella.BlockNumber
+		gasUsed = payload.Capella.GasUsed
+		gasLimit = payload.Capella.GasLimit
+		backfill.NumTx = uint64(len(payload.Capella.Transactions))
+	case spec.DataVersionDeneb:
+		executionPayload := payload.Deneb.ExecutionPayload
+		blockHash = executionPayload.BlockHash.String()
+		blockNumber = executionPayload.BlockNumber
+		gasUsed = executionPayload.GasUsed
+		gasLimit = executionPayload.GasLimit
+		backfill.NumTx = uint64(len(executionPayload.Transactions))
+		if payload.Deneb.BlobsBundle != nil {
+			backfill.NumBlobs = uint64(len(payload.Deneb.BlobsBundle.Blobs))
+		}
+	case spec.DataVersionElectra:
+		executionPayload := payload.Electra.ExecutionPayload
+		blockHash = executionPayload.BlockHash.String()
+		blockNumber = executionPayload.BlockNumber
+		gasUsed = executionPayload.GasUsed
+		gasLimit = executionPayload.GasLimit
+		backfill.NumTx = uint64(len(executionPayload.Transactions))
+		if payload.Electra.BlobsBundle != nil {
+			backfill.NumBlobs = uint64(len(payload.Electra.BlobsBundle.Blobs))
+		}
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
+		return nil, false, nil, fmt.Errorf("%w: %s", ErrUnsupportedExecutionPayload, entry.PayloadVersion)
+	}
+
+	if blockHash != entry.BlockHash {
+		mismatches = append(mismatches, fmt.Sprintf("block hash %s, payload %s", entry.BlockHash, blockHash))
+	}
+	if blockNumber != entry.BlockNumber {
+		mismatches = append(mismatches, fmt.Sprintf("block number %d, payload %d", entry.BlockNumber, blockNumber))
+	}
+	if gasUsed != entry.GasUsed {
+		mismatches = append(mismatches, fmt.Sprintf("gas used %d, payload %d", entry.GasUsed, gasUsed))
+	}
+	if gasLimit != entry.GasLimit {
+		mismatches = append(mismatches, fmt.Sprintf("gas limit %d, payload %d", entry.GasLimit, gasLimit))
+	}
+
+	changed = backfill.NumTx != entry.NumTx || backfill.NumBlobs != entry.NumBlobs
+	return backfill, changed, mismatches, nil
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeBuilderSubmissionBackfill(t *testing.T) {
+	payloadBytes := common.LoadGzippedBytes(t, "../testdata/executionPayloadAndBlobsBundleDeneb_Goerli.json.gz")
+	payload, err := ExecutionPayloadEntryToExecutionPayload(&ExecutionPayloadEntry{Version: common.ForkVersionStringDeneb, Payload: string(payloadBytes)})
+	require.NoError(t, err)
+	executionPayload := payload.Deneb.ExecutionPayload
+
+	entry := &BuilderSubmissionWithPayloadEntry{
+		ID:          1,
+		BlockHash:   executionPayload.BlockHash.String(),
+		BlockNumber: executionPayload.BlockNumber,
+		GasUsed:     executionPayload.GasUsed,
+		GasLimit:    executionPayload.GasLimit,
+
+		PayloadVersion: common.ForkVersionStringDeneb,
+		Payload:        string(payloadBytes),
+	}
+
+	backfill, changed, mismatches, err := ComputeBuilderSubmissionBackfill(entry)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Empty(t, mismatches)
+	require.Equal(t, uint64(len(executionPayload.Transactions)), backfill.NumTx)
+	require.Equal(t, uint64(1), backfill.NumBlobs)
+
+	// already backfilled, but with a wrong block number
+	entry.NumTx = backfill.NumTx
+	entry.NumBlobs = backfill.NumBlobs
+	entry.BlockNumber++
+	_, changed, mismatches, err = ComputeBuilderSubmissionBackfill(entry)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Len(t, mismatches, 1)
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	eth2UtilBellatrix "github.com/attestantio/go-eth2-client/util/bellatrix"
+	eth2UtilCapella "github.com/attestantio/go-eth2-client/util/capella"
+)
+
+const (
+	// ProgressCompactExecutionPayloads is the progress of the compaction job, the last execution payload id it looked at
+	ProgressCompactExecutionPayloads = "compact-execution-payloads"
+
+	// ProgressArchiveExecutionPayloads is the last execution payload id exported by the archive tool. With
+	// PAYLOAD_COMPACTION_REQUIRE_ARCHIVE, only archived payloads are compacted.
+	ProgressArchiveExecutionPayloads = "archive-execution-payloads"
+)
+
+var ErrExecutionPayloadCompacted = errors.New("execution payload was compacted")
+
+// ExecutionPayloadCompactionEntry is an execution payload, and whether it was delivered to a proposer (these are never
+// compacted)
+type ExecutionPayloadCompactionEntry struct {
+	ExecutionPayloadEntry
+	Delivered bool `db:"delivered"`
+}
+
+// CompactedExecutionPayload replaces the full payload of a compacted execution payload: the header fields, and the
+// SSZ roots of the transactions, the withdrawals and the whole payload
+type CompactedExecutionPayload struct {
+	Compacted        bool   `json:"compacted"`
+	ParentHash       string `json:"parent_hash"`
+	BlockHash        string `json:"block_hash"`
+	FeeRecipient     string `json:"fee_recipient"`
+	BlockNumber      uint64 `json:"block_number,string"`
+	GasLimit         uint64 `json:"gas_limit,string"`
+	GasUsed          uint64 `json:"gas_used,string"`
+	Timestamp        uint64 `json:"timestamp,string"`
+	NumTransactions  uint64 `json:"num_transactions,string"`
+	TransactionsRoot string `json:"transactions_root"`
+	NumWithdrawals   uint64 `json:"num_withdrawals,string"`
+	WithdrawalsRoot  string `json:"withdrawals_root"`
+	NumBlobs         uint64 `json:"num_blobs,string"`
+	PayloadRoot      string `json:"payload_root"`
+}
+
+// CompactExecutionPayload returns the compacted payload of an execution payload entry
+func CompactExecutionPayload(entry *ExecutionPayloadEntry) (string, error) {
+	payload, err := ExecutionPayloadEntryToExecutionPayload(entry)
+	if err != nil {
+		return "", err
+	}
+
+	compacted := CompactedExecutionPayload{Compacted: true}
+	var transactions []bellatrix.Transaction
+	var withdrawals []*capella.Withdrawal
+	var payloadRoot phase0.Root
+	switch payload.Version {
+	case spec.DataVersionCapella:
+		p := payload.Capella
+		compacted.ParentHash = p.ParentHash.String()
+		compacted.BlockHash = p.BlockHash.String()
+		compacted.FeeRecipient = p.FeeRecipient.String()
+		compacted.BlockNumber = p.BlockNumber
+		compacted.GasLimit = p.GasLimit
+		compacted.GasUsed = p.GasUsed
+		compacted.Timestamp = p.Timestamp
+		transactions, withdrawals = p.Transactions, p.Withdrawals
+		payloadRoot, err = p.HashTreeRoot()
+	case spec.DataVersionDeneb:
+		p := payload.Deneb.ExecutionPayload
+		compacted.ParentHash = p.ParentHash.String()
+		compacted.BlockHash = p.BlockHash.String()
+		compacted.FeeRecipient = p.FeeRecipient.String()
+		compacted.BlockNumber = p.BlockNumber
+		compacted.GasLimit = p.GasLimit
+		compacted.GasUsed = p.GasUsed
+		compacted.Timestamp = p.Timestamp
+		transactions, withdrawals = p.Transactions, p.Withdrawals
+		if payload.Deneb.BlobsBundle != nil {
+			compacted.NumBlobs = uint64(len(payload.Deneb.BlobsBundle.Blobs))
+		}
+		payloadRoot, err = p.HashTreeRoot()
+	case spec.DataVersionElectra:
+		p := payload.Electra.ExecutionPayload
+		compacted.ParentHash = p.ParentHash.String()
+		compacted.BlockHash = p.BlockHash.String()
+		compacted.FeeRecipient = p.FeeRecipient.String()
+		compacted.BlockNumber = p.BlockNumber
+		compacted.GasLimit = p.GasLimit
+		compacted.GasUsed = p.GasUsed
+		compacted.Timestamp = p.Timestamp
+		transactions, withdrawals = p.Transactions, p.Withdrawals
+		if payload.Electra.BlobsBundle != nil {
+			compacted.NumBlobs = uint64(len(payload.Electra.BlobsBundle.Blobs))
+		}
+		payloadRoot, err = p.HashTreeRoot()
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedExecutionPayload, entry.Version)
+	}
+	if err != nil {
+		return "", err
+	}
+	compacted.PayloadRoot = payloadRoot.String()
+
+	payloadTransactions := eth2UtilBellatrix.ExecutionPayloadTransactions{Transactions: transactions}
+	transactionsRoot, err := payloadTransactions.HashTreeRoot()
+	if err != nil {
+		return "", err
+	}
+	compacted.NumTransactions = uint64(len(transactions))
+	compacted.TransactionsRoot = phase0.Root(transactionsRoot).String()
+
+	payloadWithdrawals := eth2UtilCapella.ExecutionPayloadWithdrawals{Withdrawals: withdrawals}
+	withdrawalsRoot, err := payloadWithdrawals.HashTreeRoot()
+	if err != nil {
+		return "", err
+	}
+	compacted.NumWithdrawals = uint64(len(withdrawals))
+	compacted.WithdrawalsRoot = phase0.Root(withdrawalsRoot).String()
+
+	compactedJSON, err := json.Marshal(compacted)
+	return string(compactedJSON), err
+}
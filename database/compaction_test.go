@@ -0,0 +1,44 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactExecutionPayload(t *testing.T) {
+	payloadBytes := common.LoadGzippedBytes(t, "../testdata/executionPayloadAndBlobsBundleDeneb_Goerli.json.gz")
+	entry := &ExecutionPayloadEntry{ID: 1, Version: common.ForkVersionStringDeneb, Payload: string(payloadBytes)}
+	payload, err := ExecutionPayloadEntryToExecutionPayload(entry)
+	require.NoError(t, err)
+	executionPayload := payload.Deneb.ExecutionPayload
+	payloadRoot, err := executionPayload.HashTreeRoot()
+	require.NoError(t, err)
+
+	compactedJSON, err := CompactExecutionPayload(entry)
+	require.NoError(t, err)
+	require.Less(t, len(compactedJSON), len(payloadBytes))
+
+	compacted := new(CompactedExecutionPayload)
+	require.NoError(t, json.Unmarshal([]byte(compactedJSON), compacted))
+	require.True(t, compacted.Compacted)
+	require.Equal(t, executionPayload.BlockHash.String(), compacted.BlockHash)
+	require.Equal(t, executionPayload.BlockNumber, compacted.BlockNumber)
+	require.Equal(t, uint64(len(executionPayload.Transactions)), compacted.NumTransactions)
+	require.Equal(t, uint64(len(executionPayload.Withdrawals)), compacted.NumWithdrawals)
+	require.Equal(t, uint64(1), compacted.NumBlobs)
+	require.Equal(t, phase0.Root(payloadRoot).String(), compacted.PayloadRoot)
+
+	// compacted payloads can't be decoded or compacted again
+	entry.Payload = compactedJSON
+	entry.CompactedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	_, err = ExecutionPayloadEntryToExecutionPayload(entry)
+	require.ErrorIs(t, err, ErrExecutionPayloadCompacted)
+	_, err = CompactExecutionPayload(entry)
+	require.ErrorIs(t, err, ErrExecutionPayloadCompacted)
+}
@@ -3,8 +3,11 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"strings"
 	"time"
@@ -19,40 +22,97 @@ import (
 )
 
 type IDatabaseService interface {
+	Ping() error
+	Close() error
+	Stats() sql.DBStats
+
 	NumRegisteredValidators() (count uint64, err error)
 	SaveValidatorRegistration(entry ValidatorRegistrationEntry) error
 	GetLatestValidatorRegistrations(timestampOnly bool) ([]*ValidatorRegistrationEntry, error)
 	GetValidatorRegistration(pubkey string) (*ValidatorRegistrationEntry, error)
 	GetValidatorRegistrationsForPubkeys(pubkeys []string) ([]*ValidatorRegistrationEntry, error)
+	GetValidatorRegistrationHistory(pubkey string, limit uint64) ([]*ValidatorRegistrationHistoryEntry, error)
 
-	SaveBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest, requestError, validationError error, receivedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission bool) (entry *BuilderBlockSubmissionEntry, err error)
+	SaveBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest, requestError, validationError error, receivedAt, decodedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission, trustedSubmission bool) (entry *BuilderBlockSubmissionEntry, err error)
 	GetBlockSubmissionEntry(slot uint64, proposerPubkey, blockHash string) (entry *BuilderBlockSubmissionEntry, err error)
 	GetBuilderSubmissions(filters GetBuilderSubmissionsFilters) ([]*BuilderBlockSubmissionEntry, error)
 	GetBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (entries []*BuilderBlockSubmissionEntry, err error)
+	GetBuilderSubmissionsWithPayloadBySlots(slotFrom, slotTo uint64) (entries []*BuilderBlockSubmissionEntry, err error)
+	GetAllBuilderSubmissionsBySlot(slot uint64) (entries []*BuilderBlockSubmissionEntry, err error)
+	DeleteBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (numDeleted int64, err error)
+	MarkBuilderSubmissionsReorged(slot uint64, parentHash string) (numMarked int64, err error)
 	GetExecutionPayloadEntryByID(executionPayloadID int64) (entry *ExecutionPayloadEntry, err error)
 	GetExecutionPayloadEntryBySlotPkHash(slot uint64, proposerPubkey, blockHash string) (entry *ExecutionPayloadEntry, err error)
 	GetExecutionPayloads(idFirst, idLast uint64) (entries []*ExecutionPayloadEntry, err error)
 	DeleteExecutionPayloads(idFirst, idLast uint64) error
-
-	SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBlobFields, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64) error
+	PruneExecutionPayloads(olderThan time.Time, batchSize uint64) (numDeleted int64, err error)
+	GetExecutionPayloadsForCompaction(afterID int64, limit uint64) ([]*ExecutionPayloadCompactionEntry, error)
+	CompactExecutionPayloads(entries []*ExecutionPayloadEntry) error
+	GetBuilderSubmissionsWithPayloadAfterID(afterID int64, limit uint64) (entries []*BuilderSubmissionWithPayloadEntry, err error)
+	UpdateBuilderSubmissionsBackfill(updates []*BuilderSubmissionBackfill) error
+	GetBackfillProgress(name string) (lastID int64, err error)
+	SetBackfillProgress(name string, lastID int64) error
+
+	SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBlobFields, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64, publishOutcomes []common.BeaconPublishOutcome, msIntoSlot int64, relayMode string) error
 	GetNumDeliveredPayloads() (uint64, error)
 	GetRecentDeliveredPayloads(filters GetPayloadsFilters) ([]*DeliveredPayloadEntry, error)
+	GetDeliveredPayloadsByBuilderOperator(filters GetPayloadsFilters) ([]*BuilderOperatorDeliveredEntry, error)
 	GetDeliveredPayloads(idFirst, idLast uint64) (entries []*DeliveredPayloadEntry, err error)
+	GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (entries []*DeliveredPayloadEntry, err error)
+	DeleteDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (numDeleted int64, err error)
+	InsertImportedDeliveredPayload(entry *DeliveredPayloadEntry) (inserted bool, err error)
+	GetTopBuilders(since time.Time, limit uint64) (entries []*TopBuilderEntry, err error)
 
 	GetBlockBuilders() ([]*BlockBuilderEntry, error)
 	GetBlockBuilderByPubkey(pubkey string) (*BlockBuilderEntry, error)
 	SetBlockBuilderStatus(pubkey string, status common.BuilderStatus) error
 	SetBlockBuilderIDStatusIsOptimistic(pubkey string, isOptimistic bool) error
 	SetBlockBuilderCollateral(pubkey, builderID, collateral string) error
+	SetBlockBuilderRelayFee(pubkey string, relayFeeBps uint64) error
+	SetBlockBuilderLabels(pubkey string, labels common.BuilderLabels) error
+	GetBuilderOperatorStats() ([]*BuilderOperatorStatsEntry, error)
 	UpsertBlockBuilderEntryAfterSubmission(lastSubmission *BuilderBlockSubmissionEntry, isError bool) error
 	IncBlockBuilderStatsAfterGetPayload(builderPubkey string) error
 
 	InsertBuilderDemotion(submitBlockRequest *common.VersionedSubmitBlockRequest, simError error) error
 	UpdateBuilderDemotion(trace *common.BidTraceV2WithBlobFields, signedBlock *common.VersionedSignedProposal, signedRegistration *builderApiV1.SignedValidatorRegistration) error
 	GetBuilderDemotion(trace *common.BidTraceV2WithBlobFields) (*BuilderDemotionEntry, error)
+	InsertBuilderDemotionFromBidTrace(trace *common.BidTraceV2WithBlobFields, demotionErr error) error
+	GetBuilderDemotions(builderPubkey string, limit uint64) ([]*BuilderDemotionEntry, error)
 
 	GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayloadEntry, err error)
 	InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) error
+
+	InsertBidArchiveEntry(entry *BidArchiveEntry) error
+	GetBidArchiveEntries(slot, limit uint64) (entries []*BidArchiveEntry, err error)
+
+	GetDeliveredPayloadsWithoutPaymentVerification(maxBlockNumber, limit uint64) (entries []*DeliveredPayloadEntry, err error)
+	InsertPaymentVerification(entry *PaymentVerificationEntry) error
+	GetPaymentVerifications(filters GetPaymentVerificationsFilters) (entries []*PaymentVerificationEntry, err error)
+	InsertBidPaymentDiscrepancy(entry *BidPaymentDiscrepancyEntry) error
+	GetBidPaymentDiscrepancies(slot uint64) (entries []*BidPaymentDiscrepancyEntry, err error)
+
+	AggregateSlotSummary(slot uint64) (*SlotSummaryEntry, error)
+	UpsertSlotSummary(entry *SlotSummaryEntry) error
+	GetSlotSummaries(filters GetSlotSummariesFilters) (entries []*SlotSummaryEntry, err error)
+
+	AggregateEpochSummary(epoch uint64) (*EpochSummaryEntry, error)
+	UpsertEpochSummary(entry *EpochSummaryEntry) error
+	GetEpochSummaries(filters GetEpochSummariesFilters) (entries []*EpochSummaryEntry, err error)
+
+	GetProposerPreferences(proposerPubkey string) (*ProposerPreferencesEntry, error)
+	GetAllProposerPreferences() (entries []*ProposerPreferencesEntry, err error)
+	SetProposerMinBid(proposerPubkey, minBidValue string) error
+	SetProposerBuilderPreferences(signedPreferences *common.SignedBuilderPreferences) error
+
+	GetGetPayloadEquivocations(slot uint64) (entries []*GetPayloadEquivocationEntry, err error)
+	InsertGetPayloadEquivocation(slot uint64, proposerPubkey, firstBlockHash, blockHash string, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, msIntoSlot int64) error
+
+	InsertProposerRequests(entries []*ProposerRequestEntry) error
+	GetProposerRequests(slot uint64) (entries []*ProposerRequestEntry, err error)
+
+	InsertFeatureFlagChange(entry *FeatureFlagChangeEntry) error
+	GetFeatureFlagChanges(limit uint64) (entries []*FeatureFlagChangeEntry, err error)
 }
 
 type DatabaseService struct {
@@ -67,10 +127,7 @@ func NewDatabaseService(dsn string) (*DatabaseService, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	db.DB.SetMaxOpenConns(50)
-	db.DB.SetMaxIdleConns(10)
-	db.DB.SetConnMaxIdleTime(0)
+	setConnectionPoolLimits(db)
 
 	if os.Getenv("DB_DONT_APPLY_SCHEMA") == "" {
 		migrate.SetTable(vars.TableMigrations)
@@ -85,6 +142,23 @@ func NewDatabaseService(dsn string) (*DatabaseService, error) {
 	return dbService, err
 }
 
+// NewReadReplicaDatabaseService opens a connection pool to a read replica, for read-only queries. Neither the schema is
+// applied, nor is the replica required to be reachable yet.
+func NewReadReplicaDatabaseService(dsn string) (*DatabaseService, error) {
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	setConnectionPoolLimits(db)
+	return &DatabaseService{DB: db}, nil //nolint:exhaustruct
+}
+
+func setConnectionPoolLimits(db *sqlx.DB) {
+	db.DB.SetMaxOpenConns(50)
+	db.DB.SetMaxIdleConns(10)
+	db.DB.SetConnMaxIdleTime(0)
+}
+
 func (s *DatabaseService) prepareNamedQueries() (err error) {
 	// Insert execution payload
 	query := `INSERT INTO ` + vars.TableExecutionPayload + `
@@ -99,17 +173,26 @@ func (s *DatabaseService) prepareNamedQueries() (err error) {
 
 	// Insert block builder submission
 	query = `INSERT INTO ` + vars.TableBuilderBlockSubmission + `
-	(received_at, eligible_at, execution_payload_id, was_simulated, sim_success, sim_error, sim_req_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, optimistic_submission) VALUES
-	(:received_at, :eligible_at, :execution_payload_id, :was_simulated, :sim_success, :sim_error, :sim_req_error, :signature, :slot, :parent_hash, :block_hash, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :gas_used, :gas_limit, :num_tx, :value, :epoch, :block_number, :decode_duration, :prechecks_duration, :simulation_duration, :redis_update_duration, :total_duration, :optimistic_submission)
+	(received_at, received_at_ns, decoded_at_ns, eligible_at, eligible_at_ns, execution_payload_id, was_simulated, sim_success, sim_error, sim_req_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, num_blobs, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, optimistic_submission, trusted_submission, instance_id) VALUES
+	(:received_at, :received_at_ns, :decoded_at_ns, :eligible_at, :eligible_at_ns, :execution_payload_id, :was_simulated, :sim_success, :sim_error, :sim_req_error, :signature, :slot, :parent_hash, :block_hash, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :gas_used, :gas_limit, :num_tx, :num_blobs, :value, :epoch, :block_number, :decode_duration, :prechecks_duration, :simulation_duration, :redis_update_duration, :total_duration, :optimistic_submission, :trusted_submission, :instance_id)
 	RETURNING id`
 	s.nstmtInsertBlockBuilderSubmission, err = s.DB.PrepareNamed(query)
 	return err
 }
 
+func (s *DatabaseService) Ping() error {
+	return s.DB.Ping()
+}
+
 func (s *DatabaseService) Close() error {
 	return s.DB.Close()
 }
 
+// Stats returns the connection pool stats
+func (s *DatabaseService) Stats() sql.DBStats {
+	return s.DB.Stats()
+}
+
 // NumRegisteredValidators returns the number of unique pubkeys that have registered
 func (s *DatabaseService) NumRegisteredValidators() (count uint64, err error) {
 	query := `SELECT COUNT(*) FROM (SELECT DISTINCT pubkey FROM ` + vars.TableValidatorRegistration + `) AS temp;`
@@ -126,18 +209,35 @@ func (s *DatabaseService) NumValidatorRegistrationRows() (count uint64, err erro
 }
 
 func (s *DatabaseService) SaveValidatorRegistration(entry ValidatorRegistrationEntry) error {
+	// a change of the fee recipient is also added to the registration history, in the same statement
 	query := `WITH latest_registration AS (
 		SELECT DISTINCT ON (pubkey) pubkey, fee_recipient, timestamp, gas_limit, signature FROM ` + vars.TableValidatorRegistration + ` WHERE pubkey=:pubkey ORDER BY pubkey, timestamp DESC limit 1
+	), new_registration AS (
+		INSERT INTO ` + vars.TableValidatorRegistration + ` (pubkey, fee_recipient, timestamp, gas_limit, signature)
+		SELECT :pubkey, :fee_recipient, :timestamp, :gas_limit, :signature
+		WHERE NOT EXISTS (
+			SELECT 1 from latest_registration WHERE pubkey=:pubkey AND :timestamp <= latest_registration.timestamp OR (:fee_recipient = latest_registration.fee_recipient AND :gas_limit = latest_registration.gas_limit)
+		)
+		RETURNING pubkey, fee_recipient, timestamp
 	)
-	INSERT INTO ` + vars.TableValidatorRegistration + ` (pubkey, fee_recipient, timestamp, gas_limit, signature)
-	SELECT :pubkey, :fee_recipient, :timestamp, :gas_limit, :signature
-	WHERE NOT EXISTS (
-		SELECT 1 from latest_registration WHERE pubkey=:pubkey AND :timestamp <= latest_registration.timestamp OR (:fee_recipient = latest_registration.fee_recipient AND :gas_limit = latest_registration.gas_limit)
-	);`
+	INSERT INTO ` + vars.TableValidatorRegistrationHistory + ` (pubkey, old_fee_recipient, new_fee_recipient, timestamp, source_ip)
+	SELECT new_registration.pubkey, latest_registration.fee_recipient, new_registration.fee_recipient, new_registration.timestamp, :source_ip
+	FROM new_registration JOIN latest_registration ON latest_registration.pubkey = new_registration.pubkey
+	WHERE latest_registration.fee_recipient <> new_registration.fee_recipient;`
 	_, err := s.DB.NamedExec(query, entry)
 	return err
 }
 
+// GetValidatorRegistrationHistory returns the most recent fee recipient changes of a validator
+func (s *DatabaseService) GetValidatorRegistrationHistory(pubkey string, limit uint64) (entries []*ValidatorRegistrationHistoryEntry, err error) {
+	query := `SELECT id, inserted_at, pubkey, old_fee_recipient, new_fee_recipient, timestamp, source_ip FROM ` + vars.TableValidatorRegistrationHistory + `
+	WHERE pubkey=$1
+	ORDER BY id DESC
+	LIMIT $2`
+	err = s.DB.Select(&entries, query, pubkey, limit)
+	return entries, err
+}
+
 func (s *DatabaseService) GetValidatorRegistration(pubkey string) (*ValidatorRegistrationEntry, error) {
 	query := `SELECT DISTINCT ON (pubkey) pubkey, fee_recipient, timestamp, gas_limit, signature
 		FROM ` + vars.TableValidatorRegistration + `
@@ -175,7 +275,7 @@ func (s *DatabaseService) GetLatestValidatorRegistrations(timestampOnly bool) ([
 	return registrations, err
 }
 
-func (s *DatabaseService) SaveBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest, requestError, validationError error, receivedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission bool) (entry *BuilderBlockSubmissionEntry, err error) {
+func (s *DatabaseService) SaveBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest, requestError, validationError error, receivedAt, decodedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission, trustedSubmission bool) (entry *BuilderBlockSubmissionEntry, err error) {
 	// Save execution_payload: insert, or if already exists update to be able to return the id ('on conflict do nothing' doesn't return an id)
 	execPayloadEntry, err := PayloadToExecPayloadEntry(payload)
 	if err != nil {
@@ -207,7 +307,10 @@ func (s *DatabaseService) SaveBuilderBlockSubmission(payload *common.VersionedSu
 
 	blockSubmissionEntry := &BuilderBlockSubmissionEntry{
 		ReceivedAt:         NewNullTime(receivedAt),
+		ReceivedAtNs:       unixNanoOrZero(receivedAt),
+		DecodedAtNs:        unixNanoOrZero(decodedAt),
 		EligibleAt:         NewNullTime(eligibleAt),
+		EligibleAtNs:       unixNanoOrZero(eligibleAt),
 		ExecutionPayloadID: NewNullInt64(execPayloadEntry.ID),
 
 		WasSimulated: wasSimulated,
@@ -228,8 +331,9 @@ func (s *DatabaseService) SaveBuilderBlockSubmission(payload *common.VersionedSu
 		GasUsed:  submission.GasUsed,
 		GasLimit: submission.GasLimit,
 
-		NumTx: uint64(len(submission.Transactions)),
-		Value: submission.BidTrace.Value.Dec(),
+		NumTx:    uint64(len(submission.Transactions)),
+		NumBlobs: uint64(len(submission.Blobs)),
+		Value:    submission.BidTrace.Value.Dec(),
 
 		Epoch:       submission.BidTrace.Slot / common.SlotsPerEpoch,
 		BlockNumber: submission.BlockNumber,
@@ -240,13 +344,15 @@ func (s *DatabaseService) SaveBuilderBlockSubmission(payload *common.VersionedSu
 		RedisUpdateDuration:  profile.RedisUpdate,
 		TotalDuration:        profile.Total,
 		OptimisticSubmission: optimisticSubmission,
+		TrustedSubmission:    trustedSubmission,
+		InstanceID:           common.InstanceID,
 	}
 	err = s.nstmtInsertBlockBuilderSubmission.QueryRow(blockSubmissionEntry).Scan(&blockSubmissionEntry.ID)
 	return blockSubmissionEntry, err
 }
 
 func (s *DatabaseService) GetBlockSubmissionEntry(slot uint64, proposerPubkey, blockHash string) (entry *BuilderBlockSubmissionEntry, err error) {
-	query := `SELECT id, inserted_at, received_at, eligible_at, execution_payload_id, sim_success, sim_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, optimistic_submission 
+	query := `SELECT id, inserted_at, received_at, received_at_ns, decoded_at_ns, eligible_at, eligible_at_ns, execution_payload_id, sim_success, sim_error, signature, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, epoch, block_number, decode_duration, prechecks_duration, simulation_duration, redis_update_duration, total_duration, optimistic_submission 
 	FROM ` + vars.TableBuilderBlockSubmission + `
 	WHERE slot=$1 AND proposer_pubkey=$2 AND block_hash=$3
 	ORDER BY builder_pubkey ASC
@@ -257,14 +363,14 @@ func (s *DatabaseService) GetBlockSubmissionEntry(slot uint64, proposerPubkey, b
 }
 
 func (s *DatabaseService) GetExecutionPayloadEntryByID(executionPayloadID int64) (entry *ExecutionPayloadEntry, err error) {
-	query := `SELECT id, inserted_at, slot, proposer_pubkey, block_hash, version, payload FROM ` + vars.TableExecutionPayload + ` WHERE id=$1`
+	query := `SELECT id, inserted_at, slot, proposer_pubkey, block_hash, version, payload, compacted_at FROM ` + vars.TableExecutionPayload + ` WHERE id=$1`
 	entry = &ExecutionPayloadEntry{}
 	err = s.DB.Get(entry, query, executionPayloadID)
 	return entry, err
 }
 
 func (s *DatabaseService) GetExecutionPayloadEntryBySlotPkHash(slot uint64, proposerPubkey, blockHash string) (entry *ExecutionPayloadEntry, err error) {
-	query := `SELECT id, inserted_at, slot, proposer_pubkey, block_hash, version, payload
+	query := `SELECT id, inserted_at, slot, proposer_pubkey, block_hash, version, payload, compacted_at
 	FROM ` + vars.TableExecutionPayload + `
 	WHERE slot=$1 AND proposer_pubkey=$2 AND block_hash=$3`
 	entry = &ExecutionPayloadEntry{}
@@ -272,12 +378,30 @@ func (s *DatabaseService) GetExecutionPayloadEntryBySlotPkHash(slot uint64, prop
 	return entry, err
 }
 
-func (s *DatabaseService) SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBlobFields, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64) error {
+func (s *DatabaseService) SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBlobFields, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64, publishOutcomes []common.BeaconPublishOutcome, msIntoSlot int64, relayMode string) error {
 	_signedBlindedBeaconBlock, err := json.Marshal(signedBlindedBeaconBlock)
 	if err != nil {
 		return err
 	}
 
+	var _publishOutcomes sql.NullString
+	if len(publishOutcomes) > 0 {
+		outcomes, err := json.Marshal(publishOutcomes)
+		if err != nil {
+			return err
+		}
+		_publishOutcomes = NewNullString(string(outcomes))
+	}
+
+	// Relay fee of the builder agreement (if any)
+	var relayFeeBps uint64
+	err = s.DB.Get(&relayFeeBps, `SELECT relay_fee_bps FROM `+vars.TableBlockBuilder+` WHERE builder_pubkey=$1`, bidTrace.BuilderPubkey.String())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	value := bidTrace.Value.ToBig()
+	relayFee := common.RelayFee(value, relayFeeBps)
+
 	deliveredPayloadEntry := DeliveredPayloadEntry{
 		SignedAt:                 NewNullTime(signedAt),
 		SignedBlindedBeaconBlock: NewNullString(string(_signedBlindedBeaconBlock)),
@@ -297,25 +421,35 @@ func (s *DatabaseService) SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBl
 		GasLimit: bidTrace.GasLimit,
 
 		NumTx: bidTrace.NumTx,
-		Value: bidTrace.Value.ToBig().String(),
+		Value: value.String(),
+
+		RelayFee:      relayFee.String(),
+		AdjustedValue: NewNullString(new(big.Int).Sub(value, relayFee).String()),
 
 		NumBlobs:      bidTrace.NumBlobs,
 		BlobGasUsed:   bidTrace.BlobGasUsed,
 		ExcessBlobGas: bidTrace.ExcessBlobGas,
 
-		PublishMs: publishMs,
+		PublishMs:       publishMs,
+		PublishOutcomes: _publishOutcomes,
+		MsIntoSlot:      msIntoSlot,
+		RelayMode:       relayMode,
+
+		RelayPubkey: bidTrace.RelayPubkey,
+		InstanceID:  common.InstanceID,
 	}
 
 	query := `INSERT INTO ` + vars.TableDeliveredPayload + `
-		(signed_at, signed_blinded_beacon_block, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value, num_blobs, blob_gas_used, excess_blob_gas, publish_ms) VALUES
-		(:signed_at, :signed_blinded_beacon_block, :slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value, :num_blobs, :blob_gas_used, :excess_blob_gas, :publish_ms)
+		(signed_at, signed_blinded_beacon_block, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value, relay_fee, adjusted_value, num_blobs, blob_gas_used, excess_blob_gas, publish_ms, publish_outcomes, ms_into_slot, relay_mode, relay_pubkey, instance_id) VALUES
+		(:signed_at, :signed_blinded_beacon_block, :slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value, :relay_fee, :adjusted_value, :num_blobs, :blob_gas_used, :excess_blob_gas, :publish_ms, :publish_outcomes, :ms_into_slot, :relay_mode, :relay_pubkey, :instance_id)
 		ON CONFLICT DO NOTHING`
 	_, err = s.DB.NamedExec(query, deliveredPayloadEntry)
 	return err
 }
 
-func (s *DatabaseService) GetRecentDeliveredPayloads(queryArgs GetPayloadsFilters) ([]*DeliveredPayloadEntry, error) {
-	arg := map[string]interface{}{
+// deliveredPayloadsWhere returns the named query arguments and the WHERE clause of the delivered payload filters
+func deliveredPayloadsWhere(queryArgs GetPayloadsFilters) (arg map[string]interface{}, where string) {
+	arg = map[string]interface{}{
 		"limit":           queryArgs.Limit,
 		"slot":            queryArgs.Slot,
 		"cursor":          queryArgs.Cursor,
@@ -325,8 +459,6 @@ func (s *DatabaseService) GetRecentDeliveredPayloads(queryArgs GetPayloadsFilter
 		"builder_pubkey":  queryArgs.BuilderPubkey,
 	}
 
-	fields := "id, inserted_at, signed_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, num_blobs, blob_gas_used, excess_blob_gas, gas_used, gas_limit, publish_ms"
-
 	whereConds := []string{}
 	if queryArgs.Slot > 0 {
 		whereConds = append(whereConds, "slot = :slot")
@@ -346,10 +478,15 @@ func (s *DatabaseService) GetRecentDeliveredPayloads(queryArgs GetPayloadsFilter
 		whereConds = append(whereConds, "builder_pubkey = :builder_pubkey")
 	}
 
-	where := ""
 	if len(whereConds) > 0 {
 		where = "WHERE " + strings.Join(whereConds, " AND ")
 	}
+	return arg, where
+}
+
+func (s *DatabaseService) GetRecentDeliveredPayloads(queryArgs GetPayloadsFilters) ([]*DeliveredPayloadEntry, error) {
+	arg, where := deliveredPayloadsWhere(queryArgs)
+	fields := "id, inserted_at, signed_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, relay_fee, adjusted_value, num_blobs, blob_gas_used, excess_blob_gas, gas_used, gas_limit, publish_ms, ms_into_slot, relay_mode, relay_pubkey, source"
 
 	orderBy := "slot DESC"
 	if queryArgs.OrderByValue == 1 {
@@ -378,8 +515,38 @@ func (s *DatabaseService) GetRecentDeliveredPayloads(queryArgs GetPayloadsFilter
 	return entries, nil
 }
 
+// GetDeliveredPayloadsByBuilderOperator sums up the most recent delivered payloads matching the filters (up to the
+// limit) per builder operator, using the operator labels of the builder pubkeys. Builders without operator label are
+// grouped under an empty operator.
+func (s *DatabaseService) GetDeliveredPayloadsByBuilderOperator(queryArgs GetPayloadsFilters) ([]*BuilderOperatorDeliveredEntry, error) {
+	arg, where := deliveredPayloadsWhere(queryArgs)
+	query := fmt.Sprintf(`SELECT COALESCE(b.operator, '') AS operator, COUNT(DISTINCT d.builder_pubkey) AS num_pubkeys, COUNT(*) AS num_payloads,
+		SUM(d.value)::text AS total_value, MIN(d.slot) AS first_slot, MAX(d.slot) AS last_slot
+	FROM (SELECT slot, builder_pubkey, value FROM %s %s ORDER BY slot DESC LIMIT :limit) d
+	LEFT JOIN %s b ON b.builder_pubkey = d.builder_pubkey
+	GROUP BY 1
+	ORDER BY SUM(d.value) DESC, operator ASC`, vars.TableDeliveredPayload, where, vars.TableBlockBuilder)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	entries := []*BuilderOperatorDeliveredEntry{}
+	rows, err := s.DB.NamedQueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry := new(BuilderOperatorDeliveredEntry)
+		if err := rows.StructScan(entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
 func (s *DatabaseService) GetDeliveredPayloads(idFirst, idLast uint64) (entries []*DeliveredPayloadEntry, err error) {
-	query := `SELECT id, inserted_at, signed_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, num_blobs, blob_gas_used, excess_blob_gas, gas_used, gas_limit, publish_ms
+	query := `SELECT id, inserted_at, signed_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, relay_fee, adjusted_value, num_blobs, blob_gas_used, excess_blob_gas, gas_used, gas_limit, publish_ms, ms_into_slot, relay_mode, relay_pubkey, source
 	FROM ` + vars.TableDeliveredPayload + `
 	WHERE id >= $1 AND id <= $2
 	ORDER BY slot ASC`
@@ -388,12 +555,58 @@ func (s *DatabaseService) GetDeliveredPayloads(idFirst, idLast uint64) (entries
 	return entries, err
 }
 
+func (s *DatabaseService) GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (entries []*DeliveredPayloadEntry, err error) {
+	query := `SELECT id, inserted_at, signed_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, relay_fee, adjusted_value, num_blobs, blob_gas_used, excess_blob_gas, gas_used, gas_limit, publish_ms, ms_into_slot, relay_mode, relay_pubkey, source
+	FROM ` + vars.TableDeliveredPayload + `
+	WHERE slot >= $1 AND slot <= $2
+	ORDER BY slot ASC`
+
+	err = s.DB.Select(&entries, query, slotFrom, slotTo)
+	return entries, err
+}
+
+func (s *DatabaseService) DeleteDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (numDeleted int64, err error) {
+	query := `DELETE FROM ` + vars.TableDeliveredPayload + ` WHERE slot >= $1 AND slot <= $2`
+	res, err := s.DB.Exec(query, slotFrom, slotTo)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// InsertImportedDeliveredPayload inserts a delivered payload imported from another relay, and returns whether it was
+// inserted (i.e. the payload was not known yet)
+func (s *DatabaseService) InsertImportedDeliveredPayload(entry *DeliveredPayloadEntry) (inserted bool, err error) {
+	query := `INSERT INTO ` + vars.TableDeliveredPayload + `
+		(slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value, relay_fee, adjusted_value, relay_mode, relay_pubkey, source) VALUES
+		(:slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value, :relay_fee, :adjusted_value, :relay_mode, :relay_pubkey, :source)
+		ON CONFLICT DO NOTHING`
+	res, err := s.DB.NamedExec(query, entry)
+	if err != nil {
+		return false, err
+	}
+	numInserted, err := res.RowsAffected()
+	return numInserted > 0, err
+}
+
 func (s *DatabaseService) GetNumDeliveredPayloads() (uint64, error) {
 	var count uint64
 	err := s.DB.QueryRow("SELECT COUNT(*) FROM " + vars.TableDeliveredPayload).Scan(&count)
 	return count, err
 }
 
+// GetTopBuilders returns the builders with the highest total value of delivered payloads since the given time
+func (s *DatabaseService) GetTopBuilders(since time.Time, limit uint64) (entries []*TopBuilderEntry, err error) {
+	query := `SELECT builder_pubkey, COUNT(*) AS num_payloads, SUM(value) AS total_value FROM ` + vars.TableDeliveredPayload + `
+	WHERE inserted_at >= $1
+	GROUP BY builder_pubkey
+	ORDER BY total_value DESC
+	LIMIT $2;`
+	entries = []*TopBuilderEntry{}
+	err = s.DB.Select(&entries, query, since.UTC(), limit)
+	return entries, err
+}
+
 func (s *DatabaseService) GetBuilderSubmissions(filters GetBuilderSubmissionsFilters) ([]*BuilderBlockSubmissionEntry, error) {
 	arg := map[string]interface{}{
 		"limit":          filters.Limit,
@@ -403,7 +616,7 @@ func (s *DatabaseService) GetBuilderSubmissions(filters GetBuilderSubmissionsFil
 		"builder_pubkey": filters.BuilderPubkey,
 	}
 
-	fields := "id, inserted_at, received_at, eligible_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit, optimistic_submission"
+	fields := "id, inserted_at, received_at, received_at_ns, decoded_at_ns, eligible_at, eligible_at_ns, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit, optimistic_submission"
 	limit := "LIMIT :limit"
 
 	whereConds := []string{
@@ -451,7 +664,7 @@ func (s *DatabaseService) GetBuilderSubmissions(filters GetBuilderSubmissionsFil
 }
 
 func (s *DatabaseService) GetBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (entries []*BuilderBlockSubmissionEntry, err error) {
-	query := `SELECT id, inserted_at, received_at, eligible_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit
+	query := `SELECT id, inserted_at, received_at, received_at_ns, decoded_at_ns, eligible_at, eligible_at_ns, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit
 	FROM ` + vars.TableBuilderBlockSubmission + `
 	WHERE sim_success = true AND slot >= $1 AND slot <= $2
 	ORDER BY slot ASC, inserted_at ASC`
@@ -460,6 +673,98 @@ func (s *DatabaseService) GetBuilderSubmissionsBySlots(slotFrom, slotTo uint64)
 	return entries, err
 }
 
+// GetBuilderSubmissionsWithPayloadBySlots returns the submissions of a slot range whose execution payload is stored,
+// including their signature and simulation result, i.e. to replay them or to restore their bids
+func (s *DatabaseService) GetBuilderSubmissionsWithPayloadBySlots(slotFrom, slotTo uint64) (entries []*BuilderBlockSubmissionEntry, err error) {
+	query := `SELECT id, inserted_at, received_at, received_at_ns, decoded_at_ns, eligible_at, eligible_at_ns, was_simulated, sim_success, execution_payload_id, signature, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit, optimistic_submission
+	FROM ` + vars.TableBuilderBlockSubmission + `
+	WHERE execution_payload_id IS NOT NULL AND slot >= $1 AND slot <= $2
+	ORDER BY slot ASC, inserted_at ASC`
+
+	err = s.DB.Select(&entries, query, slotFrom, slotTo)
+	return entries, err
+}
+
+// GetAllBuilderSubmissionsBySlot returns all submissions of a slot including the failed ones, with their simulation
+// result, i.e. to give builders feedback on their bids
+func (s *DatabaseService) GetAllBuilderSubmissionsBySlot(slot uint64) (entries []*BuilderBlockSubmissionEntry, err error) {
+	query := `SELECT id, inserted_at, received_at, received_at_ns, decoded_at_ns, eligible_at, eligible_at_ns, was_simulated, sim_success, sim_error, sim_req_error, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit, optimistic_submission
+	FROM ` + vars.TableBuilderBlockSubmission + `
+	WHERE slot = $1
+	ORDER BY inserted_at ASC`
+
+	err = s.DB.Select(&entries, query, slot)
+	return entries, err
+}
+
+// DeleteBuilderSubmissionsBySlots deletes the same (successfully simulated) submissions that GetBuilderSubmissionsBySlots returns
+func (s *DatabaseService) DeleteBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (numDeleted int64, err error) {
+	query := `DELETE FROM ` + vars.TableBuilderBlockSubmission + ` WHERE sim_success = true AND slot >= $1 AND slot <= $2`
+	res, err := s.DB.Exec(query, slotFrom, slotTo)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MarkBuilderSubmissionsReorged marks the submissions of a slot which were built on the given parent, after the parent
+// was reorged away
+func (s *DatabaseService) MarkBuilderSubmissionsReorged(slot uint64, parentHash string) (numMarked int64, err error) {
+	query := `UPDATE ` + vars.TableBuilderBlockSubmission + ` SET reorged = true WHERE slot = $1 AND parent_hash = $2 AND NOT reorged`
+	res, err := s.DB.Exec(query, slot, parentHash)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetBuilderSubmissionsWithPayloadAfterID returns the next batch of block submissions with a stored execution payload,
+// ordered by id, i.e. to backfill their derived columns
+func (s *DatabaseService) GetBuilderSubmissionsWithPayloadAfterID(afterID int64, limit uint64) (entries []*BuilderSubmissionWithPayloadEntry, err error) {
+	query := `SELECT s.id, s.slot, s.block_hash, s.block_number, s.gas_used, s.gas_limit, s.num_tx, s.num_blobs, p.version AS payload_version, p.payload
+	FROM ` + vars.TableBuilderBlockSubmission + ` s
+	JOIN ` + vars.TableExecutionPayload + ` p ON p.id = s.execution_payload_id AND p.compacted_at IS NULL
+	WHERE s.id > $1
+	ORDER BY s.id ASC
+	LIMIT $2`
+	err = s.DB.Select(&entries, query, afterID, limit)
+	return entries, err
+}
+
+// UpdateBuilderSubmissionsBackfill updates the derived columns of block submissions in a single transaction
+func (s *DatabaseService) UpdateBuilderSubmissionsBackfill(updates []*BuilderSubmissionBackfill) error {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	query := `UPDATE ` + vars.TableBuilderBlockSubmission + ` SET num_tx=:num_tx, num_blobs=:num_blobs WHERE id=:id`
+	for _, update := range updates {
+		if _, err := tx.NamedExec(query, update); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetBackfillProgress returns the last processed id of the named backfill, or 0 if it never ran
+func (s *DatabaseService) GetBackfillProgress(name string) (lastID int64, err error) {
+	query := `SELECT last_id FROM ` + vars.TableBackfillProgress + ` WHERE name=$1`
+	err = s.DB.Get(&lastID, query, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return lastID, err
+}
+
+// SetBackfillProgress stores the last processed id of the named backfill, to resume from there
+func (s *DatabaseService) SetBackfillProgress(name string, lastID int64) error {
+	query := `INSERT INTO ` + vars.TableBackfillProgress + ` (name, last_id) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET last_id = EXCLUDED.last_id, updated_at = now()`
+	_, err := s.DB.Exec(query, name, lastID)
+	return err
+}
+
 func (s *DatabaseService) UpsertBlockBuilderEntryAfterSubmission(lastSubmission *BuilderBlockSubmissionEntry, isError bool) error {
 	entry := BlockBuilderEntry{
 		BuilderPubkey:          lastSubmission.BuilderPubkey,
@@ -487,14 +792,14 @@ func (s *DatabaseService) UpsertBlockBuilderEntryAfterSubmission(lastSubmission
 }
 
 func (s *DatabaseService) GetBlockBuilders() ([]*BlockBuilderEntry, error) {
-	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` ORDER BY id ASC;`
+	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, relay_fee_bps, operator, region, tier, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` ORDER BY id ASC;`
 	entries := []*BlockBuilderEntry{}
 	err := s.DB.Select(&entries, query)
 	return entries, err
 }
 
 func (s *DatabaseService) GetBlockBuilderByPubkey(pubkey string) (*BlockBuilderEntry, error) {
-	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` WHERE builder_pubkey=$1;`
+	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, relay_fee_bps, operator, region, tier, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` WHERE builder_pubkey=$1;`
 	entry := &BlockBuilderEntry{}
 	err := s.DB.Get(entry, query, pubkey)
 	return entry, err
@@ -506,6 +811,25 @@ func (s *DatabaseService) SetBlockBuilderStatus(pubkey string, status common.Bui
 	return err
 }
 
+func (s *DatabaseService) SetBlockBuilderLabels(pubkey string, labels common.BuilderLabels) error {
+	query := `UPDATE ` + vars.TableBlockBuilder + ` SET operator=$1, region=$2, tier=$3 WHERE builder_pubkey=$4;`
+	_, err := s.DB.Exec(query, labels.Operator, labels.Region, labels.Tier, pubkey)
+	return err
+}
+
+// GetBuilderOperatorStats returns the builder stats summed up per operator (builders without operator are grouped
+// under an empty operator)
+func (s *DatabaseService) GetBuilderOperatorStats() (entries []*BuilderOperatorStatsEntry, err error) {
+	query := `SELECT operator, COUNT(*) AS num_pubkeys, SUM(num_submissions_total) AS num_submissions_total, SUM(num_submissions_simerror) AS num_submissions_simerror,
+		SUM(num_sent_getpayload) AS num_sent_getpayload, MAX(last_submission_slot) AS last_submission_slot
+	FROM ` + vars.TableBlockBuilder + `
+	GROUP BY operator
+	ORDER BY num_sent_getpayload DESC, operator ASC;`
+	entries = []*BuilderOperatorStatsEntry{}
+	err = s.DB.Select(&entries, query)
+	return entries, err
+}
+
 func (s *DatabaseService) SetBlockBuilderIDStatusIsOptimistic(pubkey string, isOptimistic bool) error {
 	builder, err := s.GetBlockBuilderByPubkey(pubkey)
 	if err != nil {
@@ -525,6 +849,12 @@ func (s *DatabaseService) SetBlockBuilderCollateral(pubkey, builderID, collatera
 	return err
 }
 
+func (s *DatabaseService) SetBlockBuilderRelayFee(pubkey string, relayFeeBps uint64) error {
+	query := `UPDATE ` + vars.TableBlockBuilder + ` SET relay_fee_bps=$1 WHERE builder_pubkey=$2;`
+	_, err := s.DB.Exec(query, relayFeeBps, pubkey)
+	return err
+}
+
 func (s *DatabaseService) IncBlockBuilderStatsAfterGetPayload(builderPubkey string) error {
 	query := `UPDATE ` + vars.TableBlockBuilder + `
 		SET num_sent_getpayload=num_sent_getpayload+1
@@ -534,7 +864,7 @@ func (s *DatabaseService) IncBlockBuilderStatsAfterGetPayload(builderPubkey stri
 }
 
 func (s *DatabaseService) GetExecutionPayloads(idFirst, idLast uint64) (entries []*ExecutionPayloadEntry, err error) {
-	query := `SELECT id, inserted_at, slot, proposer_pubkey, block_hash, version, payload FROM ` + vars.TableExecutionPayload + ` WHERE id >= $1 AND id <= $2 ORDER BY id ASC`
+	query := `SELECT id, inserted_at, slot, proposer_pubkey, block_hash, version, payload, compacted_at FROM ` + vars.TableExecutionPayload + ` WHERE id >= $1 AND id <= $2 ORDER BY id ASC`
 	err = s.DB.Select(&entries, query, idFirst, idLast)
 	return entries, err
 }
@@ -545,8 +875,58 @@ func (s *DatabaseService) DeleteExecutionPayloads(idFirst, idLast uint64) error
 	return err
 }
 
+// PruneExecutionPayloads deletes a batch of execution payloads inserted before the given time, and returns the number
+// of deleted rows. The block submissions (bid traces) are kept, and so are the payloads of delivered blocks (like the
+// compaction does). Call repeatedly until it returns less than batchSize to prune all.
+func (s *DatabaseService) PruneExecutionPayloads(olderThan time.Time, batchSize uint64) (numDeleted int64, err error) {
+	query := `DELETE FROM ` + vars.TableExecutionPayload + ` WHERE id IN (
+		SELECT p.id FROM ` + vars.TableExecutionPayload + ` p
+		WHERE p.inserted_at < $1 AND NOT EXISTS (
+			SELECT 1 FROM ` + vars.TableDeliveredPayload + ` d WHERE d.slot = p.slot AND d.proposer_pubkey = p.proposer_pubkey AND d.block_hash = p.block_hash
+		)
+		ORDER BY p.id ASC LIMIT $2
+	)`
+	res, err := s.DB.Exec(query, olderThan.UTC(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetExecutionPayloadsForCompaction returns the next batch of execution payloads after the given id, ordered by id,
+// and whether they were delivered
+func (s *DatabaseService) GetExecutionPayloadsForCompaction(afterID int64, limit uint64) (entries []*ExecutionPayloadCompactionEntry, err error) {
+	query := `SELECT p.id, p.inserted_at, p.slot, p.proposer_pubkey, p.block_hash, p.version, p.payload, p.compacted_at,
+		EXISTS (
+			SELECT 1 FROM ` + vars.TableDeliveredPayload + ` d WHERE d.slot = p.slot AND d.proposer_pubkey = p.proposer_pubkey AND d.block_hash = p.block_hash
+		) AS delivered
+	FROM ` + vars.TableExecutionPayload + ` p
+	WHERE p.id > $1
+	ORDER BY p.id ASC
+	LIMIT $2`
+	err = s.DB.Select(&entries, query, afterID, limit)
+	return entries, err
+}
+
+// CompactExecutionPayloads replaces the payloads of execution payloads with their compacted payload (by id) in a
+// single transaction
+func (s *DatabaseService) CompactExecutionPayloads(entries []*ExecutionPayloadEntry) error {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	query := `UPDATE ` + vars.TableExecutionPayload + ` SET payload=:payload, compacted_at=now() WHERE id=:id AND compacted_at IS NULL`
+	for _, entry := range entries {
+		if _, err := tx.NamedExec(query, entry); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (s *DatabaseService) InsertBuilderDemotion(submitBlockRequest *common.VersionedSubmitBlockRequest, simError error) error {
-	_submitBlockRequest, err := json.Marshal(submitBlockRequest.Capella)
+	_submitBlockRequest, err := json.Marshal(submitBlockRequest)
 	if err != nil {
 		return err
 	}
@@ -607,6 +987,41 @@ func (s *DatabaseService) GetBuilderDemotion(trace *common.BidTraceV2WithBlobFie
 	return entry, nil
 }
 
+// InsertBuilderDemotionFromBidTrace records a demotion for a delivered block without the original submission (i.e. if
+// the block was rejected on publishing). The signed beacon block contains the execution payload.
+func (s *DatabaseService) InsertBuilderDemotionFromBidTrace(trace *common.BidTraceV2WithBlobFields, demotionErr error) error {
+	builderDemotionEntry := BuilderDemotionEntry{
+		Epoch: trace.Slot / common.SlotsPerEpoch,
+		Slot:  trace.Slot,
+
+		BuilderPubkey:  trace.BuilderPubkey.String(),
+		ProposerPubkey: trace.ProposerPubkey.String(),
+
+		Value:        trace.Value.Dec(),
+		FeeRecipient: trace.ProposerFeeRecipient.String(),
+
+		BlockHash: trace.BlockHash.String(),
+		SimError:  demotionErr.Error(),
+	}
+
+	query := `INSERT INTO ` + vars.TableBuilderDemotions + `
+		(epoch, slot, builder_pubkey, proposer_pubkey, value, fee_recipient, block_hash, sim_error) VALUES
+		(:epoch, :slot, :builder_pubkey, :proposer_pubkey, :value, :fee_recipient, :block_hash, :sim_error);
+	`
+	_, err := s.DB.NamedExec(query, builderDemotionEntry)
+	return err
+}
+
+// GetBuilderDemotions returns the most recent demotions of a builder, including the refund evidence
+func (s *DatabaseService) GetBuilderDemotions(builderPubkey string, limit uint64) (entries []*BuilderDemotionEntry, err error) {
+	query := `SELECT id, inserted_at, submit_block_request, signed_beacon_block, signed_validator_registration, epoch, slot, builder_pubkey, proposer_pubkey, value, fee_recipient, block_hash, sim_error FROM ` + vars.TableBuilderDemotions + `
+	WHERE builder_pubkey=$1
+	ORDER BY slot DESC, id DESC
+	LIMIT $2`
+	err = s.DB.Select(&entries, query, builderPubkey, limit)
+	return entries, err
+}
+
 func (s *DatabaseService) GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayloadEntry, err error) {
 	query := `SELECT id, inserted_at, slot, slot_start_timestamp, request_timestamp, decode_timestamp, proposer_pubkey, block_hash, ms_into_slot FROM ` + vars.TableTooLateGetPayload + ` WHERE slot = $1`
 	err = s.DB.Select(&entries, query, slot)
@@ -631,3 +1046,362 @@ func (s *DatabaseService) InsertTooLateGetPayload(slot uint64, proposerPubkey, b
 	_, err := s.DB.NamedExec(query, entry)
 	return err
 }
+
+func (s *DatabaseService) InsertBidArchiveEntry(entry *BidArchiveEntry) error {
+	query := `INSERT INTO ` + vars.TableBidArchive + `
+		(received_at, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, block_number, accepted, status_code, error_code, rejection_reason) VALUES
+		(:received_at, :slot, :parent_hash, :block_hash, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :gas_used, :gas_limit, :num_tx, :value, :block_number, :accepted, :status_code, :error_code, :rejection_reason)`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+func (s *DatabaseService) GetBidArchiveEntries(slot, limit uint64) (entries []*BidArchiveEntry, err error) {
+	query := `SELECT id, inserted_at, received_at, slot, parent_hash, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, gas_used, gas_limit, num_tx, value, block_number, accepted, status_code, error_code, rejection_reason
+	FROM ` + vars.TableBidArchive + `
+	WHERE slot = $1
+	ORDER BY received_at ASC, id ASC
+	LIMIT $2`
+	err = s.DB.Select(&entries, query, slot, limit)
+	return entries, err
+}
+
+// GetDeliveredPayloadsWithoutPaymentVerification returns the delivered payloads up to maxBlockNumber, for which the
+// proposer payment was not verified yet (oldest first)
+func (s *DatabaseService) GetDeliveredPayloadsWithoutPaymentVerification(maxBlockNumber, limit uint64) (entries []*DeliveredPayloadEntry, err error) {
+	query := `SELECT p.id, p.inserted_at, p.signed_at, p.slot, p.epoch, p.builder_pubkey, p.proposer_pubkey, p.proposer_fee_recipient, p.parent_hash, p.block_hash, p.block_number, p.num_tx, p.value, p.gas_used, p.gas_limit, p.publish_ms, p.ms_into_slot
+	FROM ` + vars.TableDeliveredPayload + ` p
+	LEFT JOIN ` + vars.TablePaymentVerification + ` v ON v.slot = p.slot AND v.block_hash = p.block_hash
+	WHERE v.id IS NULL AND p.block_number <= $1
+	ORDER BY p.slot ASC
+	LIMIT $2`
+	err = s.DB.Select(&entries, query, maxBlockNumber, limit)
+	return entries, err
+}
+
+func (s *DatabaseService) InsertPaymentVerification(entry *PaymentVerificationEntry) error {
+	query := `INSERT INTO ` + vars.TablePaymentVerification + `
+		(slot, block_number, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, expected_value, received_value, method, status) VALUES
+		(:slot, :block_number, :block_hash, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :expected_value, :received_value, :method, :status)
+		ON CONFLICT (slot, block_hash) DO NOTHING`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+func (s *DatabaseService) GetPaymentVerifications(filters GetPaymentVerificationsFilters) (entries []*PaymentVerificationEntry, err error) {
+	arg := map[string]interface{}{
+		"slot":   filters.Slot,
+		"status": filters.Status,
+		"ok":     PaymentStatusOK,
+		"limit":  filters.Limit,
+	}
+
+	whereConds := []string{}
+	if filters.Slot > 0 {
+		whereConds = append(whereConds, "slot = :slot")
+	}
+	if filters.Status != "" {
+		whereConds = append(whereConds, "status = :status")
+	}
+	if filters.DiscrepanciesOnly {
+		whereConds = append(whereConds, "status != :ok")
+	}
+
+	where := ""
+	if len(whereConds) > 0 {
+		where = "WHERE " + strings.Join(whereConds, " AND ")
+	}
+
+	fields := "id, inserted_at, slot, block_number, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, expected_value, received_value, method, status"
+	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY slot DESC LIMIT :limit", fields, vars.TablePaymentVerification, where)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := s.DB.NamedQueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := new(PaymentVerificationEntry)
+		err = rows.StructScan(entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// InsertBidPaymentDiscrepancy saves a block submission whose bid value exceeds the payment to the proposer
+func (s *DatabaseService) InsertBidPaymentDiscrepancy(entry *BidPaymentDiscrepancyEntry) error {
+	query := `INSERT INTO ` + vars.TableBidPaymentDiscrepancy + `
+		(slot, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, claimed_value, paid_value) VALUES
+		(:slot, :block_hash, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :claimed_value, :paid_value)
+		ON CONFLICT (slot, builder_pubkey, block_hash) DO NOTHING`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+func (s *DatabaseService) GetBidPaymentDiscrepancies(slot uint64) (entries []*BidPaymentDiscrepancyEntry, err error) {
+	query := `SELECT id, inserted_at, slot, block_hash, builder_pubkey, proposer_pubkey, proposer_fee_recipient, claimed_value, paid_value FROM ` + vars.TableBidPaymentDiscrepancy + ` WHERE slot = $1 ORDER BY id ASC`
+	err = s.DB.Select(&entries, query, slot)
+	return entries, err
+}
+
+// AggregateSlotSummary summarizes the block submissions and the delivered payload of a slot. The getHeader and
+// getPayload counts are not stored in the database, and left at zero.
+func (s *DatabaseService) AggregateSlotSummary(slot uint64) (*SlotSummaryEntry, error) {
+	query := `WITH submissions AS (
+		SELECT COUNT(*) AS num_bids, COUNT(DISTINCT builder_pubkey) AS num_builders, COUNT(*) FILTER (WHERE was_simulated AND NOT sim_success) AS num_sim_failures, COALESCE(MAX(value), 0) AS highest_value
+		FROM ` + vars.TableBuilderBlockSubmission + `
+		WHERE slot = $1
+	)
+	SELECT $1 AS slot, s.num_bids, s.num_builders, s.num_sim_failures, s.highest_value, COALESCE(p.block_hash, '') AS winning_block_hash, COALESCE(p.value, 0) AS winning_value
+	FROM submissions s
+	LEFT JOIN ` + vars.TableDeliveredPayload + ` p ON p.slot = $1`
+	entry := new(SlotSummaryEntry)
+	err := s.DB.Get(entry, query, slot)
+	return entry, err
+}
+
+// UpsertSlotSummary saves the summary of a slot, replacing a previous summary of the same slot
+func (s *DatabaseService) UpsertSlotSummary(entry *SlotSummaryEntry) error {
+	query := `INSERT INTO ` + vars.TableSlotSummary + `
+		(slot, num_bids, num_builders, num_sim_failures, highest_value, winning_block_hash, winning_value, num_getheader, num_getpayload) VALUES
+		(:slot, :num_bids, :num_builders, :num_sim_failures, :highest_value, :winning_block_hash, :winning_value, :num_getheader, :num_getpayload)
+		ON CONFLICT (slot) DO UPDATE SET
+			updated_at = current_timestamp,
+			num_bids = :num_bids,
+			num_builders = :num_builders,
+			num_sim_failures = :num_sim_failures,
+			highest_value = :highest_value,
+			winning_block_hash = :winning_block_hash,
+			winning_value = :winning_value,
+			num_getheader = :num_getheader,
+			num_getpayload = :num_getpayload`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+func (s *DatabaseService) GetSlotSummaries(filters GetSlotSummariesFilters) (entries []*SlotSummaryEntry, err error) {
+	arg := map[string]interface{}{
+		"slot":   filters.Slot,
+		"cursor": filters.Cursor,
+		"limit":  filters.Limit,
+	}
+
+	whereConds := []string{}
+	if filters.Slot > 0 {
+		whereConds = append(whereConds, "slot = :slot")
+	} else if filters.Cursor > 0 {
+		whereConds = append(whereConds, "slot <= :cursor")
+	}
+
+	where := ""
+	if len(whereConds) > 0 {
+		where = "WHERE " + strings.Join(whereConds, " AND ")
+	}
+
+	fields := "slot, inserted_at, updated_at, num_bids, num_builders, num_sim_failures, highest_value, winning_block_hash, winning_value, num_getheader, num_getpayload"
+	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY slot DESC LIMIT :limit", fields, vars.TableSlotSummary, where)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := s.DB.NamedQueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := new(SlotSummaryEntry)
+		err = rows.StructScan(entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// AggregateEpochSummary rolls up the delivered payloads of an epoch. The registration and header counts are not stored
+// in the database, and left at zero.
+func (s *DatabaseService) AggregateEpochSummary(epoch uint64) (*EpochSummaryEntry, error) {
+	query := `SELECT $1 AS epoch, COUNT(*) AS num_payloads_delivered, COALESCE(SUM(value), 0) AS delivered_value
+	FROM ` + vars.TableDeliveredPayload + `
+	WHERE epoch = $1`
+	entry := new(EpochSummaryEntry)
+	err := s.DB.Get(entry, query, epoch)
+	return entry, err
+}
+
+// UpsertEpochSummary saves the summary of an epoch, replacing a previous summary of the same epoch
+func (s *DatabaseService) UpsertEpochSummary(entry *EpochSummaryEntry) error {
+	query := `INSERT INTO ` + vars.TableEpochSummary + `
+		(epoch, num_registrations, num_headers_served, num_payloads_delivered, delivered_value) VALUES
+		(:epoch, :num_registrations, :num_headers_served, :num_payloads_delivered, :delivered_value)
+		ON CONFLICT (epoch) DO UPDATE SET
+			updated_at = current_timestamp,
+			num_registrations = :num_registrations,
+			num_headers_served = :num_headers_served,
+			num_payloads_delivered = :num_payloads_delivered,
+			delivered_value = :delivered_value`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+func (s *DatabaseService) GetEpochSummaries(filters GetEpochSummariesFilters) (entries []*EpochSummaryEntry, err error) {
+	arg := map[string]interface{}{
+		"from_epoch": filters.FromEpoch,
+		"to_epoch":   filters.ToEpoch,
+		"limit":      filters.Limit,
+	}
+
+	whereConds := []string{"epoch >= :from_epoch"}
+	if filters.ToEpoch > 0 {
+		whereConds = append(whereConds, "epoch <= :to_epoch")
+	}
+	where := "WHERE " + strings.Join(whereConds, " AND ")
+
+	fields := "epoch, inserted_at, updated_at, num_registrations, num_headers_served, num_payloads_delivered, delivered_value"
+	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY epoch DESC LIMIT :limit", fields, vars.TableEpochSummary, where)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := s.DB.NamedQueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := new(EpochSummaryEntry)
+		err = rows.StructScan(entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *DatabaseService) GetGetPayloadEquivocations(slot uint64) (entries []*GetPayloadEquivocationEntry, err error) {
+	query := `SELECT id, inserted_at, slot, proposer_pubkey, first_block_hash, block_hash, signed_blinded_beacon_block, ms_into_slot FROM ` + vars.TableGetPayloadEquivocation + ` WHERE slot = $1 ORDER BY id ASC`
+	err = s.DB.Select(&entries, query, slot)
+	return entries, err
+}
+
+// InsertGetPayloadEquivocation saves a getPayload request which was signed for a different block hash than the first request for this slot
+func (s *DatabaseService) InsertGetPayloadEquivocation(slot uint64, proposerPubkey, firstBlockHash, blockHash string, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, msIntoSlot int64) error {
+	_signedBlindedBeaconBlock, err := json.Marshal(signedBlindedBeaconBlock)
+	if err != nil {
+		return err
+	}
+
+	entry := GetPayloadEquivocationEntry{
+		Slot:                     slot,
+		ProposerPubkey:           proposerPubkey,
+		FirstBlockHash:           firstBlockHash,
+		BlockHash:                blockHash,
+		SignedBlindedBeaconBlock: NewNullString(string(_signedBlindedBeaconBlock)),
+		MsIntoSlot:               msIntoSlot,
+	}
+
+	query := `INSERT INTO ` + vars.TableGetPayloadEquivocation + `
+		(slot, proposer_pubkey, first_block_hash, block_hash, signed_blinded_beacon_block, ms_into_slot) VALUES
+		(:slot, :proposer_pubkey, :first_block_hash, :block_hash, :signed_blinded_beacon_block, :ms_into_slot)
+		ON CONFLICT (slot, proposer_pubkey, block_hash) DO NOTHING;`
+	_, err = s.DB.NamedExec(query, entry)
+	return err
+}
+
+// InsertProposerRequests saves a batch of getHeader and getPayload requests
+func (s *DatabaseService) InsertProposerRequests(entries []*ProposerRequestEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	query := `INSERT INTO ` + vars.TableProposerRequest + `
+		(received_at, method, request_id, slot, proposer_pubkey, parent_hash, block_hash, ip, forwarded_for, user_agent, ms_into_slot, duration_ms, status_code) VALUES
+		(:received_at, :method, :request_id, :slot, :proposer_pubkey, :parent_hash, :block_hash, :ip, :forwarded_for, :user_agent, :ms_into_slot, :duration_ms, :status_code)`
+	_, err := s.DB.NamedExec(query, entries)
+	return err
+}
+
+// GetProposerRequests returns the getHeader and getPayload requests of a slot, in the order they were received
+func (s *DatabaseService) GetProposerRequests(slot uint64) (entries []*ProposerRequestEntry, err error) {
+	query := `SELECT id, inserted_at, received_at, method, request_id, slot, proposer_pubkey, parent_hash, block_hash, ip, forwarded_for, user_agent, ms_into_slot, duration_ms, status_code
+	FROM ` + vars.TableProposerRequest + `
+	WHERE slot = $1
+	ORDER BY received_at ASC, id ASC`
+	entries = []*ProposerRequestEntry{}
+	err = s.DB.Select(&entries, query, slot)
+	return entries, err
+}
+
+// InsertFeatureFlagChange saves a runtime change of a feature flag to the audit log
+func (s *DatabaseService) InsertFeatureFlagChange(entry *FeatureFlagChangeEntry) error {
+	query := `INSERT INTO ` + vars.TableFeatureFlagChange + `
+		(instance_id, name, enabled, prev_enabled, actor, reason, ip) VALUES
+		(:instance_id, :name, :enabled, :prev_enabled, :actor, :reason, :ip)`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+// GetFeatureFlagChanges returns the latest runtime changes of feature flags, newest first
+func (s *DatabaseService) GetFeatureFlagChanges(limit uint64) (entries []*FeatureFlagChangeEntry, err error) {
+	query := `SELECT id, inserted_at, instance_id, name, enabled, prev_enabled, actor, reason, ip
+	FROM ` + vars.TableFeatureFlagChange + `
+	ORDER BY id DESC
+	LIMIT $1`
+	entries = []*FeatureFlagChangeEntry{}
+	err = s.DB.Select(&entries, query, limit)
+	return entries, err
+}
+
+func (s *DatabaseService) GetProposerPreferences(proposerPubkey string) (*ProposerPreferencesEntry, error) {
+	query := `SELECT inserted_at, updated_at, proposer_pubkey, min_bid_value, allowed_builders, denied_builders, builder_preferences_timestamp, builder_preferences_signature FROM ` + vars.TableProposerPreferences + ` WHERE proposer_pubkey=$1;`
+	entry := &ProposerPreferencesEntry{}
+	err := s.DB.Get(entry, query, proposerPubkey)
+	return entry, err
+}
+
+func (s *DatabaseService) GetAllProposerPreferences() (entries []*ProposerPreferencesEntry, err error) {
+	query := `SELECT inserted_at, updated_at, proposer_pubkey, min_bid_value, allowed_builders, denied_builders, builder_preferences_timestamp, builder_preferences_signature FROM ` + vars.TableProposerPreferences + ` ORDER BY proposer_pubkey ASC;`
+	err = s.DB.Select(&entries, query)
+	return entries, err
+}
+
+// SetProposerMinBid sets the minimum bid value (in wei) of a proposer, creating its preferences if they don't exist yet
+func (s *DatabaseService) SetProposerMinBid(proposerPubkey, minBidValue string) error {
+	query := `INSERT INTO ` + vars.TableProposerPreferences + ` (proposer_pubkey, min_bid_value) VALUES ($1, $2)
+		ON CONFLICT (proposer_pubkey) DO UPDATE SET min_bid_value = EXCLUDED.min_bid_value, updated_at = current_timestamp;`
+	_, err := s.DB.Exec(query, proposerPubkey, minBidValue)
+	return err
+}
+
+// SetProposerBuilderPreferences sets the builder allowlist/blocklist of a proposer, if the signed message is newer than
+// the one of the current preferences
+func (s *DatabaseService) SetProposerBuilderPreferences(signedPreferences *common.SignedBuilderPreferences) error {
+	preferences := signedPreferences.Message
+	entry := ProposerPreferencesEntry{
+		ProposerPubkey:              preferences.Pubkey.String(),
+		AllowedBuilders:             pubkeysToStrings(preferences.AllowedBuilders),
+		DeniedBuilders:              pubkeysToStrings(preferences.DeniedBuilders),
+		BuilderPreferencesTimestamp: preferences.Timestamp,
+		BuilderPreferencesSignature: signedPreferences.Signature.String(),
+	}
+
+	query := `INSERT INTO ` + vars.TableProposerPreferences + `
+		(proposer_pubkey, allowed_builders, denied_builders, builder_preferences_timestamp, builder_preferences_signature) VALUES
+		(:proposer_pubkey, :allowed_builders, :denied_builders, :builder_preferences_timestamp, :builder_preferences_signature)
+		ON CONFLICT (proposer_pubkey) DO UPDATE SET
+			allowed_builders = EXCLUDED.allowed_builders,
+			denied_builders = EXCLUDED.denied_builders,
+			builder_preferences_timestamp = EXCLUDED.builder_preferences_timestamp,
+			builder_preferences_signature = EXCLUDED.builder_preferences_signature,
+			updated_at = current_timestamp
+		WHERE ` + vars.TableProposerPreferences + `.builder_preferences_timestamp < EXCLUDED.builder_preferences_timestamp;`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
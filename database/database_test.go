@@ -88,7 +88,7 @@ func insertTestBuilder(t *testing.T, db IDatabaseService) string {
 			Value:                uint256.NewInt(collateral),
 		},
 	}, spec.DataVersionDeneb)
-	entry, err := db.SaveBuilderBlockSubmission(req, nil, nil, time.Now(), time.Now().Add(time.Second), true, true, profile, optimisticSubmission)
+	entry, err := db.SaveBuilderBlockSubmission(req, nil, nil, time.Now(), time.Now(), time.Now().Add(time.Second), true, true, profile, optimisticSubmission, false)
 	require.NoError(t, err)
 	err = db.UpsertBlockBuilderEntryAfterSubmission(entry, false)
 	require.NoError(t, err)
@@ -133,6 +133,7 @@ func TestSaveValidatorRegistration(t *testing.T) {
 	reg4 := createValidatorRegistration("0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908")
 	reg4.Timestamp = reg1.Timestamp + 2
 	reg4.FeeRecipient = "0xafbb8996515293fcd87ca09b5c6ffe5c17f043c6"
+	reg4.SourceIP = "10.0.0.1"
 
 	// reg5 is reg1 with older timestamp and new fee_recipient - should not insert
 	reg5 := createValidatorRegistration("0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908")
@@ -187,6 +188,15 @@ func TestSaveValidatorRegistration(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, uint64(3), cnt)
 
+	// Only the fee recipient change of reg4 is in the history
+	history, err := db.GetValidatorRegistrationHistory(reg1.Pubkey, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, reg1.FeeRecipient, history[0].OldFeeRecipient)
+	require.Equal(t, reg4.FeeRecipient, history[0].NewFeeRecipient)
+	require.Equal(t, reg4.Timestamp, history[0].Timestamp)
+	require.Equal(t, "10.0.0.1", history[0].SourceIP)
+
 	// Save reg5, should not insert
 	err = db.SaveValidatorRegistration(reg5)
 	require.NoError(t, err)
@@ -198,6 +208,9 @@ func TestSaveValidatorRegistration(t *testing.T) {
 	cnt, err = db.NumValidatorRegistrationRows()
 	require.NoError(t, err)
 	require.Equal(t, uint64(3), cnt)
+	history, err = db.GetValidatorRegistrationHistory(reg1.Pubkey, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
 }
 
 func TestMigrations(t *testing.T) {
@@ -433,6 +446,11 @@ func TestGetBlockSubmissionEntry(t *testing.T) {
 
 	require.True(t, entry.OptimisticSubmission)
 	require.True(t, entry.EligibleAt.Valid)
+
+	// nanosecond timestamps, which the timestamp columns round to microseconds
+	require.InDelta(t, entry.ReceivedAt.Time.UnixMicro(), entry.ReceivedAtNs/1000, 1)
+	require.Positive(t, entry.DecodedAtNs)
+	require.InDelta(t, entry.EligibleAt.Time.UnixMicro(), entry.EligibleAtNs/1000, 1)
 }
 
 func TestGetBuilderSubmissions(t *testing.T) {
@@ -489,3 +507,502 @@ func TestUpsertTooLateGetPayload(t *testing.T) {
 	entry = entries[1]
 	require.Equal(t, hash2, entry.BlockHash)
 }
+
+func TestGetTopBuilders(t *testing.T) {
+	db := resetDatabase(t)
+
+	builderA := "0xa1885d66bef164889a2cb1aac8b4bc3be7bd87d3a3a2c2f0d6a2e10c4d8a9b5b05c14b2ab9a40bcc97c9c3f4a3e7e1b2"
+	builderB := "0xb1885d66bef164889a2cb1aac8b4bc3be7bd87d3a3a2c2f0d6a2e10c4d8a9b5b05c14b2ab9a40bcc97c9c3f4a3e7e1b2"
+	values := []struct {
+		builder string
+		value   string
+	}{
+		{builderA, "1000"},
+		{builderA, "2000"},
+		{builderB, "2500"},
+	}
+
+	query := `INSERT INTO ` + vars.TableDeliveredPayload + `
+		(slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value) VALUES
+		(:slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value)`
+	for i, v := range values {
+		entry := DeliveredPayloadEntry{ //nolint:exhaustruct
+			Slot:          slot + uint64(i),
+			BuilderPubkey: v.builder,
+			BlockHash:     strconv.Itoa(i),
+			Value:         v.value,
+		}
+		_, err := db.DB.NamedExec(query, entry)
+		require.NoError(t, err)
+	}
+
+	entries, err := db.GetTopBuilders(time.Now().Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, builderA, entries[0].BuilderPubkey)
+	require.Equal(t, uint64(2), entries[0].NumPayloads)
+	require.Equal(t, "3000", entries[0].TotalValue)
+	require.Equal(t, builderB, entries[1].BuilderPubkey)
+
+	// Nothing delivered in the future
+	entries, err = db.GetTopBuilders(time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestGetDeliveredPayloadsByBuilderOperator(t *testing.T) {
+	db := resetDatabase(t)
+
+	builderA1 := "0xa1885d66bef164889a2cb1aac8b4bc3be7bd87d3a3a2c2f0d6a2e10c4d8a9b5b05c14b2ab9a40bcc97c9c3f4a3e7e1b2"
+	builderA2 := "0xa2885d66bef164889a2cb1aac8b4bc3be7bd87d3a3a2c2f0d6a2e10c4d8a9b5b05c14b2ab9a40bcc97c9c3f4a3e7e1b2"
+	builderB := "0xb1885d66bef164889a2cb1aac8b4bc3be7bd87d3a3a2c2f0d6a2e10c4d8a9b5b05c14b2ab9a40bcc97c9c3f4a3e7e1b2"
+	for _, pubkey := range []string{builderA1, builderA2} {
+		err := db.UpsertBlockBuilderEntryAfterSubmission(&BuilderBlockSubmissionEntry{BuilderPubkey: pubkey}, false) //nolint:exhaustruct
+		require.NoError(t, err)
+		require.NoError(t, db.SetBlockBuilderLabels(pubkey, common.BuilderLabels{Operator: "acme"}))
+	}
+
+	query := `INSERT INTO ` + vars.TableDeliveredPayload + `
+		(slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value) VALUES
+		(:slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value)`
+	for i, v := range []struct {
+		builder string
+		value   string
+	}{
+		{builderA1, "1000"},
+		{builderA2, "2000"},
+		{builderB, "2500"},
+		{builderA1, "500"},
+	} {
+		entry := DeliveredPayloadEntry{ //nolint:exhaustruct
+			Slot:          slot + uint64(i),
+			BuilderPubkey: v.builder,
+			BlockHash:     strconv.Itoa(i),
+			Value:         v.value,
+		}
+		_, err := db.DB.NamedExec(query, entry)
+		require.NoError(t, err)
+	}
+
+	entries, err := db.GetDeliveredPayloadsByBuilderOperator(GetPayloadsFilters{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "acme", entries[0].Operator)
+	require.Equal(t, uint64(2), entries[0].NumPubkeys)
+	require.Equal(t, uint64(3), entries[0].NumPayloads)
+	require.Equal(t, "3500", entries[0].TotalValue)
+	require.Equal(t, slot, entries[0].FirstSlot)
+	require.Equal(t, slot+3, entries[0].LastSlot)
+	require.Equal(t, "", entries[1].Operator, "builders without labels")
+	require.Equal(t, "2500", entries[1].TotalValue)
+
+	// Only the most recent payloads are summed up
+	entries, err = db.GetDeliveredPayloadsByBuilderOperator(GetPayloadsFilters{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "", entries[0].Operator)
+	require.Equal(t, "acme", entries[1].Operator)
+	require.Equal(t, "500", entries[1].TotalValue)
+}
+
+func TestInsertGetPayloadEquivocation(t *testing.T) {
+	db := resetDatabase(t)
+	slot := uint64(12345)
+	pk := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	firstHash := "0x00bb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012343"
+	hash := "0xFFbb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012343"
+	signedBlock := &common.VersionedSignedBlindedBeaconBlock{
+		VersionedSignedBlindedBeaconBlock: eth2Api.VersionedSignedBlindedBeaconBlock{
+			Version: spec.DataVersionDeneb,
+			Deneb: &eth2ApiV1Deneb.SignedBlindedBeaconBlock{
+				Message: &eth2ApiV1Deneb.BlindedBeaconBlock{}, //nolint:exhaustruct
+			},
+		},
+	}
+
+	err := db.InsertGetPayloadEquivocation(slot, pk, firstHash, hash, signedBlock, 1200)
+	require.NoError(t, err)
+
+	// Duplicate is ignored
+	err = db.InsertGetPayloadEquivocation(slot, pk, firstHash, hash, signedBlock, 1300)
+	require.NoError(t, err)
+
+	entries, err := db.GetGetPayloadEquivocations(slot)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, pk, entries[0].ProposerPubkey)
+	require.Equal(t, firstHash, entries[0].FirstBlockHash)
+	require.Equal(t, hash, entries[0].BlockHash)
+	require.Equal(t, int64(1200), entries[0].MsIntoSlot)
+	require.True(t, entries[0].SignedBlindedBeaconBlock.Valid)
+}
+
+func TestInsertBidPaymentDiscrepancy(t *testing.T) {
+	db := resetDatabase(t)
+	entry := &BidPaymentDiscrepancyEntry{
+		Slot:                 slot,
+		BlockHash:            blockHashStr,
+		BuilderPubkey:        "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908",
+		ProposerPubkey:       "0xb5246e299aeb782fbc7c91b41b3284245b1ed5206134b0028b81dfb974e5900616c67847c2354479934fc4bb75519ee1",
+		ProposerFeeRecipient: feeRecipient.String(),
+		ClaimedValue:         "100",
+		PaidValue:            "99",
+	}
+	require.NoError(t, db.InsertBidPaymentDiscrepancy(entry))
+
+	// Duplicate is ignored
+	require.NoError(t, db.InsertBidPaymentDiscrepancy(entry))
+
+	entries, err := db.GetBidPaymentDiscrepancies(slot)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, blockHashStr, entries[0].BlockHash)
+	require.Equal(t, "100", entries[0].ClaimedValue)
+	require.Equal(t, "99", entries[0].PaidValue)
+}
+
+func TestDeleteBuilderSubmissionsBySlots(t *testing.T) {
+	db := resetDatabase(t)
+	insertTestBuilder(t, db)
+
+	entries, err := db.GetBuilderSubmissionsBySlots(slot, slot)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Other slots are not touched
+	numDeleted, err := db.DeleteBuilderSubmissionsBySlots(slot+1, slot+10)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), numDeleted)
+
+	numDeleted, err = db.DeleteBuilderSubmissionsBySlots(slot, slot)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), numDeleted)
+
+	entries, err = db.GetBuilderSubmissionsBySlots(slot, slot)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestPruneExecutionPayloads(t *testing.T) {
+	db := resetDatabase(t)
+	proposerPubkey := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	now := time.Now().UTC()
+
+	// Payloads of slot+0 to slot+3 are old, slot+4 is recent, and slot+1 was delivered
+	query := `INSERT INTO ` + vars.TableExecutionPayload + ` (inserted_at, slot, proposer_pubkey, block_hash, version, payload) VALUES ($1, $2, $3, $4, 'deneb', '{}')`
+	for i := uint64(0); i < 5; i++ {
+		insertedAt := now.Add(-48 * time.Hour)
+		if i == 4 {
+			insertedAt = now
+		}
+		_, err := db.DB.Exec(query, insertedAt, slot+i, proposerPubkey, strconv.FormatUint(i, 10))
+		require.NoError(t, err)
+	}
+	delivered := DeliveredPayloadEntry{Slot: slot + 1, ProposerPubkey: proposerPubkey, BlockHash: "1", Value: "1"} //nolint:exhaustruct
+	_, err := db.DB.NamedExec(`INSERT INTO `+vars.TableDeliveredPayload+`
+		(slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value) VALUES
+		(:slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value)`, delivered)
+	require.NoError(t, err)
+
+	olderThan := now.Add(-24 * time.Hour)
+	numDeleted, err := db.PruneExecutionPayloads(olderThan, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), numDeleted)
+	numDeleted, err = db.PruneExecutionPayloads(olderThan, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), numDeleted)
+	numDeleted, err = db.PruneExecutionPayloads(olderThan, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), numDeleted)
+
+	var blockHashes []string
+	err = db.DB.Select(&blockHashes, `SELECT block_hash FROM `+vars.TableExecutionPayload+` ORDER BY id ASC`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "4"}, blockHashes, "the delivered and the recent payloads are kept")
+}
+
+func TestMarkBuilderSubmissionsReorged(t *testing.T) {
+	db := resetDatabase(t)
+	insertTestBuilder(t, db)
+	parentHash := phase0.Hash32{}.String()
+
+	// Other parents are not touched
+	numMarked, err := db.MarkBuilderSubmissionsReorged(slot, "0x01")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), numMarked)
+
+	numMarked, err = db.MarkBuilderSubmissionsReorged(slot, parentHash)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), numMarked)
+
+	// Already marked submissions are not counted again
+	numMarked, err = db.MarkBuilderSubmissionsReorged(slot, parentHash)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), numMarked)
+}
+
+func TestBidArchive(t *testing.T) {
+	db := resetDatabase(t)
+	entry := &BidArchiveEntry{
+		ReceivedAt:           time.Now().UTC(),
+		Slot:                 slot,
+		ParentHash:           "0x00bb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012343",
+		BlockHash:            blockHashStr,
+		BuilderPubkey:        "0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4",
+		ProposerPubkey:       "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908",
+		ProposerFeeRecipient: "0xEd33259a056F4fb449FFB7B7E2eCB43a9B5685Bf",
+		Value:                "123456789",
+		Accepted:             true,
+		StatusCode:           200,
+	}
+	require.NoError(t, db.InsertBidArchiveEntry(entry))
+
+	entry.Accepted = false
+	entry.StatusCode = 400
+	entry.ErrorCode = "FEE_RECIPIENT_MISMATCH"
+	entry.RejectionReason = "fee recipient does not match"
+	require.NoError(t, db.InsertBidArchiveEntry(entry))
+
+	entries, err := db.GetBidArchiveEntries(slot, 100)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.True(t, entries[0].Accepted)
+	require.False(t, entries[1].Accepted)
+	require.Equal(t, "FEE_RECIPIENT_MISMATCH", entries[1].ErrorCode)
+
+	entries, err = db.GetBidArchiveEntries(slot, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entries, err = db.GetBidArchiveEntries(slot+1, 100)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestPaymentVerification(t *testing.T) {
+	db := resetDatabase(t)
+
+	query := `INSERT INTO ` + vars.TableDeliveredPayload + `
+		(slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value) VALUES
+		(:slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value)`
+	for i := 0; i < 3; i++ {
+		entry := DeliveredPayloadEntry{ //nolint:exhaustruct
+			Slot:        slot + uint64(i),
+			BlockHash:   strconv.Itoa(i),
+			BlockNumber: 100 + uint64(i),
+			Value:       "1000",
+		}
+		_, err := db.DB.NamedExec(query, entry)
+		require.NoError(t, err)
+	}
+
+	// Only payloads up to the given block number
+	payloads, err := db.GetDeliveredPayloadsWithoutPaymentVerification(101, 10)
+	require.NoError(t, err)
+	require.Len(t, payloads, 2)
+	require.Equal(t, slot, payloads[0].Slot)
+
+	for i, status := range []string{PaymentStatusOK, PaymentStatusUnderpaid} {
+		err = db.InsertPaymentVerification(&PaymentVerificationEntry{
+			Slot:          payloads[i].Slot,
+			BlockNumber:   payloads[i].BlockNumber,
+			BlockHash:     payloads[i].BlockHash,
+			ExpectedValue: payloads[i].Value,
+			ReceivedValue: "500",
+			Method:        PaymentMethodBalanceDiff,
+			Status:        status,
+		})
+		require.NoError(t, err)
+	}
+
+	// Verified payloads are skipped
+	payloads, err = db.GetDeliveredPayloadsWithoutPaymentVerification(102, 10)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	require.Equal(t, slot+2, payloads[0].Slot)
+
+	entries, err := db.GetPaymentVerifications(GetPaymentVerificationsFilters{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	entries, err = db.GetPaymentVerifications(GetPaymentVerificationsFilters{DiscrepanciesOnly: true, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, PaymentStatusUnderpaid, entries[0].Status)
+	require.Equal(t, slot+1, entries[0].Slot)
+
+	entries, err = db.GetPaymentVerifications(GetPaymentVerificationsFilters{Slot: slot, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, PaymentStatusOK, entries[0].Status)
+}
+
+func TestSlotSummary(t *testing.T) {
+	db := resetDatabase(t)
+	builderPubkey := insertTestBuilder(t, db)
+
+	entry, err := db.AggregateSlotSummary(slot)
+	require.NoError(t, err)
+	require.Equal(t, slot, entry.Slot)
+	require.Equal(t, uint64(1), entry.NumBids)
+	require.Equal(t, uint64(1), entry.NumBuilders)
+	require.Equal(t, strconv.Itoa(collateral), entry.HighestValue)
+	require.Equal(t, "", entry.WinningBlockHash)
+	require.Equal(t, "0", entry.WinningValue)
+
+	query := `INSERT INTO ` + vars.TableDeliveredPayload + `
+		(slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value) VALUES
+		(:slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value)`
+	_, err = db.DB.NamedExec(query, DeliveredPayloadEntry{ //nolint:exhaustruct
+		Slot:          slot,
+		BuilderPubkey: builderPubkey,
+		BlockHash:     blockHashStr,
+		Value:         "1000",
+	})
+	require.NoError(t, err)
+
+	entry, err = db.AggregateSlotSummary(slot)
+	require.NoError(t, err)
+	require.Equal(t, blockHashStr, entry.WinningBlockHash)
+	require.Equal(t, "1000", entry.WinningValue)
+
+	entry.NumGetHeader = 3
+	require.NoError(t, db.UpsertSlotSummary(entry))
+	entry.NumGetPayload = 1
+	require.NoError(t, db.UpsertSlotSummary(entry))
+
+	emptyEntry, err := db.AggregateSlotSummary(slot + 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), emptyEntry.NumBids)
+	require.NoError(t, db.UpsertSlotSummary(emptyEntry))
+
+	entries, err := db.GetSlotSummaries(GetSlotSummariesFilters{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, slot+1, entries[0].Slot)
+
+	entries, err = db.GetSlotSummaries(GetSlotSummariesFilters{Slot: slot, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, uint64(3), entries[0].NumGetHeader)
+	require.Equal(t, uint64(1), entries[0].NumGetPayload)
+
+	entries, err = db.GetSlotSummaries(GetSlotSummariesFilters{Cursor: slot, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, slot, entries[0].Slot)
+}
+
+func TestEpochSummary(t *testing.T) {
+	db := resetDatabase(t)
+	epoch := uint64(3)
+
+	query := `INSERT INTO ` + vars.TableDeliveredPayload + `
+		(slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, gas_used, gas_limit, num_tx, value) VALUES
+		(:slot, :epoch, :builder_pubkey, :proposer_pubkey, :proposer_fee_recipient, :parent_hash, :block_hash, :block_number, :gas_used, :gas_limit, :num_tx, :value)`
+	for i := 0; i < 2; i++ {
+		_, err := db.DB.NamedExec(query, DeliveredPayloadEntry{ //nolint:exhaustruct
+			Slot:      epoch*common.SlotsPerEpoch + uint64(i),
+			Epoch:     epoch,
+			BlockHash: strconv.Itoa(i),
+			Value:     "1000",
+		})
+		require.NoError(t, err)
+	}
+
+	entry, err := db.AggregateEpochSummary(epoch)
+	require.NoError(t, err)
+	require.Equal(t, epoch, entry.Epoch)
+	require.Equal(t, uint64(2), entry.NumPayloadsDelivered)
+	require.Equal(t, "2000", entry.DeliveredValue)
+
+	entry.NumRegistrations = 100
+	require.NoError(t, db.UpsertEpochSummary(entry))
+	entry.NumHeadersServed = 10
+	require.NoError(t, db.UpsertEpochSummary(entry))
+
+	for _, e := range []uint64{epoch + 1, epoch + 2} {
+		entry, err = db.AggregateEpochSummary(e)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), entry.NumPayloadsDelivered)
+		require.Equal(t, "0", entry.DeliveredValue)
+		require.NoError(t, db.UpsertEpochSummary(entry))
+	}
+
+	entries, err := db.GetEpochSummaries(GetEpochSummariesFilters{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, epoch+2, entries[0].Epoch)
+
+	entries, err = db.GetEpochSummaries(GetEpochSummariesFilters{FromEpoch: epoch, ToEpoch: epoch + 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, epoch, entries[1].Epoch)
+	require.Equal(t, uint64(100), entries[1].NumRegistrations)
+	require.Equal(t, uint64(10), entries[1].NumHeadersServed)
+}
+
+func TestProposerRequests(t *testing.T) {
+	db := resetDatabase(t)
+	receivedAt := time.Now().UTC()
+	entries := []*ProposerRequestEntry{
+		{
+			ReceivedAt:     receivedAt,
+			Method:         "getHeader",
+			Slot:           slot,
+			ProposerPubkey: "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908",
+			ParentHash:     "0x00bb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012343",
+			BlockHash:      blockHashStr,
+			IP:             "1.2.3.4",
+			UserAgent:      "mev-boost/v1.7",
+			MsIntoSlot:     100,
+			StatusCode:     200,
+		},
+		{
+			ReceivedAt:     receivedAt.Add(time.Second),
+			Method:         "getPayload",
+			Slot:           slot,
+			ProposerPubkey: "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908",
+			BlockHash:      blockHashStr,
+			IP:             "1.2.3.4",
+			MsIntoSlot:     1100,
+			StatusCode:     400,
+		},
+	}
+	require.NoError(t, db.InsertProposerRequests(entries))
+	require.NoError(t, db.InsertProposerRequests(nil))
+
+	saved, err := db.GetProposerRequests(slot)
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	require.Equal(t, "getHeader", saved[0].Method)
+	require.Equal(t, "getPayload", saved[1].Method)
+	require.Equal(t, 400, saved[1].StatusCode)
+
+	saved, err = db.GetProposerRequests(slot + 1)
+	require.NoError(t, err)
+	require.Empty(t, saved)
+}
+
+func TestFeatureFlagChanges(t *testing.T) {
+	db := resetDatabase(t)
+	for _, enabled := range []bool{false, true} {
+		err := db.InsertFeatureFlagChange(&FeatureFlagChangeEntry{
+			InstanceID:  "instance-1",
+			Name:        "ENABLE_BUILDER_CANCELLATIONS",
+			Enabled:     enabled,
+			PrevEnabled: !enabled,
+			Actor:       "admin",
+			Reason:      "test",
+			IP:          "1.2.3.4",
+		})
+		require.NoError(t, err)
+	}
+
+	entries, err := db.GetFeatureFlagChanges(1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Enabled)
+	require.False(t, entries[0].PrevEnabled)
+	require.Equal(t, "admin", entries[0].Actor)
+}
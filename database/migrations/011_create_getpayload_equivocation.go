@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration011CreateGetPayloadEquivocation adds a table to persist getPayload
+// requests for a slot which were signed for a different block hash than the
+// first request received for that slot (potential proposer equivocations).
+var Migration011CreateGetPayloadEquivocation = &migrate.Migration{
+	Id: "011-create-getpayload-equivocation",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableGetPayloadEquivocation + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			slot            bigint NOT NULL,
+			proposer_pubkey varchar(98) NOT NULL,
+
+			first_block_hash varchar(66) NOT NULL,
+			block_hash       varchar(66) NOT NULL,
+
+			signed_blinded_beacon_block json,
+			ms_into_slot                bigint NOT NULL
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS ` + vars.TableGetPayloadEquivocation + `_slot_pk_hash_idx ON ` + vars.TableGetPayloadEquivocation + `(slot, proposer_pubkey, block_hash);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration012PayloadAddMsIntoSlot adds the time at which the getPayload request was
+// received, relative to the start of the slot (can be negative for early requests)
+var Migration012PayloadAddMsIntoSlot = &migrate.Migration{
+	Id: "012-payload-add-ms-into-slot",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD ms_into_slot bigint NOT NULL DEFAULT 0;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration013BuilderSubmissionTrusted adds whether a block submission was received over the trusted builder listener
+var Migration013BuilderSubmissionTrusted = &migrate.Migration{
+	Id: "013-builder-submission-trusted",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD trusted_submission bool NOT NULL DEFAULT false;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
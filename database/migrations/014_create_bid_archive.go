@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration014CreateBidArchive creates the table for the archive of received bids, including the rejected ones
+var Migration014CreateBidArchive = &migrate.Migration{
+	Id: "014-create-bid-archive",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableBidArchive + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+			received_at timestamp NOT NULL,
+
+			slot                   bigint NOT NULL,
+			parent_hash            varchar(66) NOT NULL,
+			block_hash             varchar(66) NOT NULL,
+			builder_pubkey         varchar(98) NOT NULL,
+			proposer_pubkey        varchar(98) NOT NULL,
+			proposer_fee_recipient varchar(42) NOT NULL,
+
+			gas_used     bigint NOT NULL,
+			gas_limit    bigint NOT NULL,
+			num_tx       int NOT NULL,
+			value        NUMERIC(48, 0) NOT NULL,
+			block_number bigint NOT NULL,
+
+			accepted         boolean NOT NULL,
+			status_code      int NOT NULL,
+			error_code       text NOT NULL,
+			rejection_reason text NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TableBidArchive + `_slot_idx ON ` + vars.TableBidArchive + `(slot);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration015CreatePaymentVerification creates the table for the results of verifying the proposer payment of
+// delivered payloads against the execution layer
+var Migration015CreatePaymentVerification = &migrate.Migration{
+	Id: "015-create-payment-verification",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TablePaymentVerification + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			slot                   bigint NOT NULL,
+			block_number           bigint NOT NULL,
+			block_hash             varchar(66) NOT NULL,
+			builder_pubkey         varchar(98) NOT NULL,
+			proposer_pubkey        varchar(98) NOT NULL,
+			proposer_fee_recipient varchar(42) NOT NULL,
+
+			expected_value NUMERIC(48, 0) NOT NULL,
+			received_value NUMERIC(48, 0) NOT NULL,
+			method         text NOT NULL,
+			status         text NOT NULL,
+
+			UNIQUE (slot, block_hash)
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TablePaymentVerification + `_status_idx ON ` + vars.TablePaymentVerification + `(status);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration016RelayFee adds the relay fee of the builder agreement, and the relay fee and adjusted value of delivered
+// payloads
+var Migration016RelayFee = &migrate.Migration{
+	Id: "016-relay-fee",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBlockBuilder + ` ADD relay_fee_bps int NOT NULL DEFAULT 0;
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD relay_fee NUMERIC(48, 0) NOT NULL DEFAULT 0;
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD adjusted_value NUMERIC(48, 0);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
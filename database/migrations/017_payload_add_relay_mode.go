@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration017PayloadAddRelayMode records the relay mode (max_profit or filtered) of delivered payloads
+var Migration017PayloadAddRelayMode = &migrate.Migration{
+	Id: "017-payload-add-relay-mode",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD relay_mode text NOT NULL DEFAULT '';
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration018CreateProposerPreferences creates the table for the per-proposer preferences (i.e. the minimum bid value)
+var Migration018CreateProposerPreferences = &migrate.Migration{
+	Id: "018-create-proposer-preferences",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableProposerPreferences + ` (
+			inserted_at timestamp NOT NULL default current_timestamp,
+			updated_at  timestamp NOT NULL default current_timestamp,
+
+			proposer_pubkey varchar(98) NOT NULL PRIMARY KEY,
+			min_bid_value   NUMERIC(48, 0) NOT NULL DEFAULT 0
+		);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration019ProposerBuilderPreferences adds the builder allowlist/blocklist of proposers, together with the signed
+// message which set them
+var Migration019ProposerBuilderPreferences = &migrate.Migration{
+	Id: "019-proposer-builder-preferences",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableProposerPreferences + ` ADD allowed_builders text[] NOT NULL DEFAULT '{}';
+		ALTER TABLE ` + vars.TableProposerPreferences + ` ADD denied_builders text[] NOT NULL DEFAULT '{}';
+		ALTER TABLE ` + vars.TableProposerPreferences + ` ADD builder_preferences_timestamp bigint NOT NULL DEFAULT 0;
+		ALTER TABLE ` + vars.TableProposerPreferences + ` ADD builder_preferences_signature text NOT NULL DEFAULT '';
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
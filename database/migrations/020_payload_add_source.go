@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration020PayloadAddSource records where delivered payloads come from: empty if delivered by this relay, or else
+// the relay they were imported from
+var Migration020PayloadAddSource = &migrate.Migration{
+	Id: "020-payload-add-source",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD source text NOT NULL DEFAULT '';
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
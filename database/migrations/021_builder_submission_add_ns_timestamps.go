@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration021BuilderSubmissionAddNsTimestamps adds the receive, decode-complete and eligibility times of the
+// submissions in unix nanoseconds (0 if unknown), as the timestamp columns only have microsecond precision
+var Migration021BuilderSubmissionAddNsTimestamps = &migrate.Migration{
+	Id: "021-builder-submission-add-ns-timestamps",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD received_at_ns bigint NOT NULL DEFAULT 0;
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD decoded_at_ns bigint NOT NULL DEFAULT 0;
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD eligible_at_ns bigint NOT NULL DEFAULT 0;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration022PayloadAddRelayPubkey records which relay key signed the bid of delivered payloads, to follow key
+// rotations
+var Migration022PayloadAddRelayPubkey = &migrate.Migration{
+	Id: "022-payload-add-relay-pubkey",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD relay_pubkey text NOT NULL DEFAULT '';
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
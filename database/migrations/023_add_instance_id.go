@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration023AddInstanceID records which relay instance received a block submission, and which one delivered a
+// payload, when several instances share the database
+var Migration023AddInstanceID = &migrate.Migration{
+	Id: "023-add-instance-id",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD instance_id text NOT NULL DEFAULT '';
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD instance_id text NOT NULL DEFAULT '';
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
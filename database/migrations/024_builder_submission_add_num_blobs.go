@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration024BuilderSubmissionAddNumBlobs adds the number of blobs to block submissions, and the progress table of
+// the backfill tool which populates it for older submissions
+var Migration024BuilderSubmissionAddNumBlobs = &migrate.Migration{
+	Id: "024-builder-submission-add-num-blobs",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD num_blobs int NOT NULL DEFAULT 0;
+	`, `
+		CREATE TABLE IF NOT EXISTS ` + vars.TableBackfillProgress + ` (
+			name       text NOT NULL PRIMARY KEY,
+			updated_at timestamp NOT NULL default current_timestamp,
+			last_id    bigint NOT NULL
+		);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
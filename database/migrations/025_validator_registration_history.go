@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration025ValidatorRegistrationHistory adds the audit trail of fee recipient changes of validator registrations,
+// to investigate suspected fee recipient hijacks
+var Migration025ValidatorRegistrationHistory = &migrate.Migration{
+	Id: "025-validator-registration-history",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableValidatorRegistrationHistory + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			pubkey            varchar(98) NOT NULL,
+			old_fee_recipient varchar(42) NOT NULL,
+			new_fee_recipient varchar(42) NOT NULL,
+			timestamp         bigint NOT NULL,
+			source_ip         text NOT NULL
+		);
+	`, `
+		CREATE INDEX IF NOT EXISTS ` + vars.TableValidatorRegistrationHistory + `_pubkey_idx ON ` + vars.TableValidatorRegistrationHistory + `("pubkey");
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration026ExecutionPayloadAddCompactedAt marks execution payloads whose full payload was replaced by its roots and
+// summary fields by the compaction job
+var Migration026ExecutionPayloadAddCompactedAt = &migrate.Migration{
+	Id: "026-execution-payload-add-compacted-at",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableExecutionPayload + ` ADD compacted_at timestamp;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
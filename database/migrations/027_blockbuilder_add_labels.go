@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration027BlockBuilderAddLabels adds operator labels to builder pubkeys, to analyze the auction per organization
+var Migration027BlockBuilderAddLabels = &migrate.Migration{
+	Id: "027-blockbuilder-add-labels",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBlockBuilder + ` ADD operator text NOT NULL DEFAULT '';
+		ALTER TABLE ` + vars.TableBlockBuilder + ` ADD region text NOT NULL DEFAULT '';
+		ALTER TABLE ` + vars.TableBlockBuilder + ` ADD tier text NOT NULL DEFAULT '';
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
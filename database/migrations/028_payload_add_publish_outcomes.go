@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration028PayloadAddPublishOutcomes records the publish outcome of every beacon node for delivered payloads
+var Migration028PayloadAddPublishOutcomes = &migrate.Migration{
+	Id: "028-payload-add-publish-outcomes",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableDeliveredPayload + ` ADD publish_outcomes json;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
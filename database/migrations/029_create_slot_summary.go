@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration029CreateSlotSummary creates the table for the per-slot auction summaries, which the housekeeper writes
+// after each slot
+var Migration029CreateSlotSummary = &migrate.Migration{
+	Id: "029-create-slot-summary",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableSlotSummary + ` (
+			slot        bigint PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+			updated_at  timestamp NOT NULL default current_timestamp,
+
+			num_bids         bigint NOT NULL,
+			num_builders     bigint NOT NULL,
+			num_sim_failures bigint NOT NULL,
+			highest_value    NUMERIC(48, 0) NOT NULL,
+
+			winning_block_hash varchar(66) NOT NULL,
+			winning_value      NUMERIC(48, 0) NOT NULL,
+
+			num_getheader  bigint NOT NULL,
+			num_getpayload bigint NOT NULL
+		);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
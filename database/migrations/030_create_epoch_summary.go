@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration030CreateEpochSummary creates the table for the per-epoch rollups, which the housekeeper writes after each
+// epoch
+var Migration030CreateEpochSummary = &migrate.Migration{
+	Id: "030-create-epoch-summary",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableEpochSummary + ` (
+			epoch       bigint PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+			updated_at  timestamp NOT NULL default current_timestamp,
+
+			num_registrations      bigint NOT NULL,
+			num_headers_served     bigint NOT NULL,
+			num_payloads_delivered bigint NOT NULL,
+			delivered_value        NUMERIC(48, 0) NOT NULL
+		);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration031BuilderSubmissionAddReorged marks block submissions which were built on a parent that was reorged away
+var Migration031BuilderSubmissionAddReorged = &migrate.Migration{
+	Id: "031-builder-submission-add-reorged",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBuilderBlockSubmission + ` ADD reorged boolean NOT NULL DEFAULT false;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration032CreateProposerRequest creates the log of getHeader and getPayload requests, to reconstruct what the
+// relay served to the proposer of a missed slot
+var Migration032CreateProposerRequest = &migrate.Migration{
+	Id: "032-create-proposer-request",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableProposerRequest + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+			received_at timestamp NOT NULL,
+
+			method          varchar(16) NOT NULL,
+			request_id      text NOT NULL,
+			slot            bigint NOT NULL,
+			proposer_pubkey text NOT NULL,
+			parent_hash     text NOT NULL,
+			block_hash      text NOT NULL,
+
+			ip            text NOT NULL,
+			forwarded_for text NOT NULL,
+			user_agent    text NOT NULL,
+			ms_into_slot  bigint NOT NULL,
+			duration_ms   bigint NOT NULL,
+			status_code   integer NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TableProposerRequest + `_slot_idx ON ` + vars.TableProposerRequest + `("slot");
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
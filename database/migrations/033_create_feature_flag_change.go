@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration033CreateFeatureFlagChange creates the audit log of feature flags which were toggled at runtime
+var Migration033CreateFeatureFlagChange = &migrate.Migration{
+	Id: "033-create-feature-flag-change",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableFeatureFlagChange + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			instance_id  text NOT NULL,
+			name         text NOT NULL,
+			enabled      boolean NOT NULL,
+			prev_enabled boolean NOT NULL,
+			actor        text NOT NULL,
+			reason       text NOT NULL,
+			ip           text NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TableFeatureFlagChange + `_name_idx ON ` + vars.TableFeatureFlagChange + `("name");
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
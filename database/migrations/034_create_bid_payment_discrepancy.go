@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration034CreateBidPaymentDiscrepancy creates the table for block submissions which were rejected because the
+// bid value exceeds the payment to the proposer
+var Migration034CreateBidPaymentDiscrepancy = &migrate.Migration{
+	Id: "034-create-bid-payment-discrepancy",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableBidPaymentDiscrepancy + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			slot                   bigint NOT NULL,
+			block_hash             varchar(66) NOT NULL,
+			builder_pubkey         varchar(98) NOT NULL,
+			proposer_pubkey        varchar(98) NOT NULL,
+			proposer_fee_recipient varchar(42) NOT NULL,
+
+			claimed_value NUMERIC(48, 0) NOT NULL,
+			paid_value    NUMERIC(48, 0) NOT NULL,
+
+			UNIQUE (slot, builder_pubkey, block_hash)
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TableBidPaymentDiscrepancy + `_builder_pubkey_idx ON ` + vars.TableBidPaymentDiscrepancy + `(builder_pubkey);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -17,5 +17,29 @@ var Migrations = migrate.MemoryMigrationSource{
 		Migration008Optimistic,
 		Migration009BlockBuilderRemoveReference,
 		Migration010PayloadAddBlobFields,
+		Migration011CreateGetPayloadEquivocation,
+		Migration012PayloadAddMsIntoSlot,
+		Migration013BuilderSubmissionTrusted,
+		Migration014CreateBidArchive,
+		Migration015CreatePaymentVerification,
+		Migration016RelayFee,
+		Migration017PayloadAddRelayMode,
+		Migration018CreateProposerPreferences,
+		Migration019ProposerBuilderPreferences,
+		Migration020PayloadAddSource,
+		Migration021BuilderSubmissionAddNsTimestamps,
+		Migration022PayloadAddRelayPubkey,
+		Migration023AddInstanceID,
+		Migration024BuilderSubmissionAddNumBlobs,
+		Migration025ValidatorRegistrationHistory,
+		Migration026ExecutionPayloadAddCompactedAt,
+		Migration027BlockBuilderAddLabels,
+		Migration028PayloadAddPublishOutcomes,
+		Migration029CreateSlotSummary,
+		Migration030CreateEpochSummary,
+		Migration031BuilderSubmissionAddReorged,
+		Migration032CreateProposerRequest,
+		Migration033CreateFeatureFlagChange,
+		Migration034CreateBidPaymentDiscrepancy,
 	},
 }
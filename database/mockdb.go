@@ -14,6 +14,21 @@ type MockDB struct {
 	Builders     map[string]*BlockBuilderEntry
 	Demotions    map[string]bool
 	Refunds      map[string]bool
+
+	ProposerPreferences  map[string]*ProposerPreferencesEntry
+	PaymentDiscrepancies map[string]*BidPaymentDiscrepancyEntry // by block hash
+}
+
+func (db MockDB) Ping() error {
+	return nil
+}
+
+func (db MockDB) Close() error {
+	return nil
+}
+
+func (db MockDB) Stats() sql.DBStats {
+	return sql.DBStats{}
 }
 
 func (db MockDB) NumRegisteredValidators() (count uint64, err error) {
@@ -32,11 +47,15 @@ func (db MockDB) GetValidatorRegistrationsForPubkeys(pubkeys []string) (entries
 	return nil, nil
 }
 
+func (db MockDB) GetValidatorRegistrationHistory(pubkey string, limit uint64) ([]*ValidatorRegistrationHistoryEntry, error) {
+	return nil, nil
+}
+
 func (db MockDB) GetLatestValidatorRegistrations(timestampOnly bool) ([]*ValidatorRegistrationEntry, error) {
 	return nil, nil
 }
 
-func (db MockDB) SaveBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest, requestError, validationError error, receivedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission bool) (entry *BuilderBlockSubmissionEntry, err error) {
+func (db MockDB) SaveBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest, requestError, validationError error, receivedAt, decodedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission, trustedSubmission bool) (entry *BuilderBlockSubmissionEntry, err error) {
 	return nil, nil
 }
 
@@ -57,6 +76,34 @@ func (db MockDB) GetExecutionPayloads(idFirst, idLast uint64) (entries []*Execut
 	return nil, nil
 }
 
+func (db MockDB) PruneExecutionPayloads(olderThan time.Time, batchSize uint64) (numDeleted int64, err error) {
+	return 0, nil
+}
+
+func (db MockDB) GetExecutionPayloadsForCompaction(afterID int64, limit uint64) ([]*ExecutionPayloadCompactionEntry, error) {
+	return nil, nil
+}
+
+func (db MockDB) CompactExecutionPayloads(entries []*ExecutionPayloadEntry) error {
+	return nil
+}
+
+func (db MockDB) GetBuilderSubmissionsWithPayloadAfterID(afterID int64, limit uint64) (entries []*BuilderSubmissionWithPayloadEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) UpdateBuilderSubmissionsBackfill(updates []*BuilderSubmissionBackfill) error {
+	return nil
+}
+
+func (db MockDB) GetBackfillProgress(name string) (lastID int64, err error) {
+	return 0, nil
+}
+
+func (db MockDB) SetBackfillProgress(name string, lastID int64) error {
+	return nil
+}
+
 func (db MockDB) DeleteExecutionPayloads(idFirst, idLast uint64) error {
 	return nil
 }
@@ -69,10 +116,30 @@ func (db MockDB) GetRecentDeliveredPayloads(filters GetPayloadsFilters) ([]*Deli
 	return nil, nil
 }
 
+func (db MockDB) GetDeliveredPayloadsByBuilderOperator(filters GetPayloadsFilters) ([]*BuilderOperatorDeliveredEntry, error) {
+	return nil, nil
+}
+
 func (db MockDB) GetDeliveredPayloads(idFirst, idLast uint64) (entries []*DeliveredPayloadEntry, err error) {
 	return nil, nil
 }
 
+func (db MockDB) GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (entries []*DeliveredPayloadEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) DeleteDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (numDeleted int64, err error) {
+	return 0, nil
+}
+
+func (db MockDB) InsertImportedDeliveredPayload(entry *DeliveredPayloadEntry) (inserted bool, err error) {
+	return true, nil
+}
+
+func (db MockDB) GetTopBuilders(since time.Time, limit uint64) (entries []*TopBuilderEntry, err error) {
+	return nil, nil
+}
+
 func (db MockDB) GetNumDeliveredPayloads() (uint64, error) {
 	return 0, nil
 }
@@ -85,7 +152,23 @@ func (db MockDB) GetBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (entries
 	return nil, nil
 }
 
-func (db MockDB) SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBlobFields, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64) error {
+func (db MockDB) GetBuilderSubmissionsWithPayloadBySlots(slotFrom, slotTo uint64) (entries []*BuilderBlockSubmissionEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) GetAllBuilderSubmissionsBySlot(slot uint64) (entries []*BuilderBlockSubmissionEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) DeleteBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (numDeleted int64, err error) {
+	return 0, nil
+}
+
+func (db MockDB) MarkBuilderSubmissionsReorged(slot uint64, parentHash string) (numMarked int64, err error) {
+	return 0, nil
+}
+
+func (db MockDB) SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBlobFields, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64, publishOutcomes []common.BeaconPublishOutcome, msIntoSlot int64, relayMode string) error {
 	return nil
 }
 
@@ -145,6 +228,30 @@ func (db MockDB) SetBlockBuilderCollateral(pubkey, builderID, collateral string)
 	return nil
 }
 
+func (db MockDB) SetBlockBuilderRelayFee(pubkey string, relayFeeBps uint64) error {
+	builder, ok := db.Builders[pubkey]
+	if !ok {
+		return fmt.Errorf("builder with pubkey %v not in Builders map", pubkey) //nolint:goerr113
+	}
+	builder.RelayFeeBps = relayFeeBps
+	return nil
+}
+
+func (db MockDB) SetBlockBuilderLabels(pubkey string, labels common.BuilderLabels) error {
+	builder, ok := db.Builders[pubkey]
+	if !ok {
+		return fmt.Errorf("builder with pubkey %v not in Builders map", pubkey) //nolint:goerr113
+	}
+	builder.Operator = labels.Operator
+	builder.Region = labels.Region
+	builder.Tier = labels.Tier
+	return nil
+}
+
+func (db MockDB) GetBuilderOperatorStats() ([]*BuilderOperatorStatsEntry, error) {
+	return nil, nil
+}
+
 func (db MockDB) IncBlockBuilderStatsAfterGetHeader(slot uint64, blockhash string) error {
 	return nil
 }
@@ -187,6 +294,18 @@ func (db MockDB) GetBuilderDemotion(trace *common.BidTraceV2WithBlobFields) (*Bu
 	return nil, nil
 }
 
+func (db MockDB) InsertBuilderDemotionFromBidTrace(trace *common.BidTraceV2WithBlobFields, demotionErr error) error {
+	db.Demotions[trace.BuilderPubkey.String()] = true
+	return nil
+}
+
+func (db MockDB) GetBuilderDemotions(builderPubkey string, limit uint64) ([]*BuilderDemotionEntry, error) {
+	if db.Demotions[builderPubkey] {
+		return []*BuilderDemotionEntry{{BuilderPubkey: builderPubkey}}, nil
+	}
+	return nil, nil
+}
+
 func (db MockDB) GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayloadEntry, err error) {
 	return nil, nil
 }
@@ -194,3 +313,129 @@ func (db MockDB) GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayload
 func (db MockDB) InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) error {
 	return nil
 }
+
+func (db MockDB) InsertProposerRequests(entries []*ProposerRequestEntry) error {
+	return nil
+}
+
+func (db MockDB) GetProposerRequests(slot uint64) (entries []*ProposerRequestEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) InsertFeatureFlagChange(entry *FeatureFlagChangeEntry) error {
+	return nil
+}
+
+func (db MockDB) GetFeatureFlagChanges(limit uint64) (entries []*FeatureFlagChangeEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) GetGetPayloadEquivocations(slot uint64) (entries []*GetPayloadEquivocationEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) InsertGetPayloadEquivocation(slot uint64, proposerPubkey, firstBlockHash, blockHash string, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, msIntoSlot int64) error {
+	return nil
+}
+
+func (db MockDB) InsertBidArchiveEntry(entry *BidArchiveEntry) error {
+	return nil
+}
+
+func (db MockDB) GetBidArchiveEntries(slot, limit uint64) (entries []*BidArchiveEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) GetDeliveredPayloadsWithoutPaymentVerification(maxBlockNumber, limit uint64) (entries []*DeliveredPayloadEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) InsertPaymentVerification(entry *PaymentVerificationEntry) error {
+	return nil
+}
+
+func (db MockDB) GetPaymentVerifications(filters GetPaymentVerificationsFilters) (entries []*PaymentVerificationEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) InsertBidPaymentDiscrepancy(entry *BidPaymentDiscrepancyEntry) error {
+	if db.PaymentDiscrepancies != nil {
+		db.PaymentDiscrepancies[entry.BlockHash] = entry
+	}
+	return nil
+}
+
+func (db MockDB) GetBidPaymentDiscrepancies(slot uint64) (entries []*BidPaymentDiscrepancyEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) AggregateSlotSummary(slot uint64) (*SlotSummaryEntry, error) {
+	return &SlotSummaryEntry{Slot: slot, HighestValue: "0", WinningValue: "0"}, nil
+}
+
+func (db MockDB) UpsertSlotSummary(entry *SlotSummaryEntry) error {
+	return nil
+}
+
+func (db MockDB) GetSlotSummaries(filters GetSlotSummariesFilters) (entries []*SlotSummaryEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) AggregateEpochSummary(epoch uint64) (*EpochSummaryEntry, error) {
+	return &EpochSummaryEntry{Epoch: epoch, DeliveredValue: "0"}, nil
+}
+
+func (db MockDB) UpsertEpochSummary(entry *EpochSummaryEntry) error {
+	return nil
+}
+
+func (db MockDB) GetEpochSummaries(filters GetEpochSummariesFilters) (entries []*EpochSummaryEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) GetProposerPreferences(proposerPubkey string) (*ProposerPreferencesEntry, error) {
+	entry, ok := db.ProposerPreferences[proposerPubkey]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (db MockDB) GetAllProposerPreferences() (entries []*ProposerPreferencesEntry, err error) {
+	for _, entry := range db.ProposerPreferences {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (db MockDB) SetProposerMinBid(proposerPubkey, minBidValue string) error {
+	if db.ProposerPreferences == nil {
+		return nil
+	}
+	entry, ok := db.ProposerPreferences[proposerPubkey]
+	if !ok {
+		entry = &ProposerPreferencesEntry{ProposerPubkey: proposerPubkey}
+		db.ProposerPreferences[proposerPubkey] = entry
+	}
+	entry.MinBidValue = minBidValue
+	return nil
+}
+
+func (db MockDB) SetProposerBuilderPreferences(signedPreferences *common.SignedBuilderPreferences) error {
+	if db.ProposerPreferences == nil {
+		return nil
+	}
+	preferences := signedPreferences.Message
+	entry, ok := db.ProposerPreferences[preferences.Pubkey.String()]
+	if !ok {
+		entry = &ProposerPreferencesEntry{ProposerPubkey: preferences.Pubkey.String()}
+		db.ProposerPreferences[preferences.Pubkey.String()] = entry
+	} else if entry.BuilderPreferencesTimestamp >= preferences.Timestamp {
+		return nil
+	}
+	entry.AllowedBuilders = pubkeysToStrings(preferences.AllowedBuilders)
+	entry.DeniedBuilders = pubkeysToStrings(preferences.DeniedBuilders)
+	entry.BuilderPreferencesTimestamp = preferences.Timestamp
+	entry.BuilderPreferencesSignature = signedPreferences.Signature.String()
+	return nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	uberatomic "go.uber.org/atomic"
+)
+
+// replicaRetryInterval is how long queries go to the primary after the read replica failed
+var replicaRetryInterval = 10 * time.Second
+
+// ReplicaDatabaseService sends the read-only queries of the data API and the website to a read replica, and all
+// other queries to the primary. If a query on the replica fails, it is retried on the primary, which is then used for
+// all queries until replicaRetryInterval has passed.
+type ReplicaDatabaseService struct {
+	IDatabaseService // primary
+
+	log              *logrus.Entry
+	replica          IDatabaseService
+	replicaDownUntil uberatomic.Time
+}
+
+func NewReplicaDatabaseService(log *logrus.Entry, primary, replica IDatabaseService) *ReplicaDatabaseService {
+	return &ReplicaDatabaseService{
+		IDatabaseService: primary,
+		log:              log.WithField("component", "dbReplica"),
+		replica:          replica,
+	}
+}
+
+// readQuery runs the query on the replica, or on the primary if the replica is unavailable
+func readQuery[T any](s *ReplicaDatabaseService, query func(db IDatabaseService) (T, error)) (T, error) {
+	if time.Now().After(s.replicaDownUntil.Load()) {
+		result, err := query(s.replica)
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return result, err
+		}
+		s.log.WithError(err).Warn("query on read replica failed, using the primary")
+		s.replicaDownUntil.Store(time.Now().Add(replicaRetryInterval))
+	}
+	return query(s.IDatabaseService)
+}
+
+func (s *ReplicaDatabaseService) Close() error {
+	return errors.Join(s.replica.Close(), s.IDatabaseService.Close())
+}
+
+func (s *ReplicaDatabaseService) NumRegisteredValidators() (uint64, error) {
+	return readQuery(s, func(db IDatabaseService) (uint64, error) {
+		return db.NumRegisteredValidators()
+	})
+}
+
+func (s *ReplicaDatabaseService) GetValidatorRegistration(pubkey string) (*ValidatorRegistrationEntry, error) {
+	return readQuery(s, func(db IDatabaseService) (*ValidatorRegistrationEntry, error) {
+		return db.GetValidatorRegistration(pubkey)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetBuilderSubmissions(filters GetBuilderSubmissionsFilters) ([]*BuilderBlockSubmissionEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*BuilderBlockSubmissionEntry, error) {
+		return db.GetBuilderSubmissions(filters)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetNumDeliveredPayloads() (uint64, error) {
+	return readQuery(s, func(db IDatabaseService) (uint64, error) {
+		return db.GetNumDeliveredPayloads()
+	})
+}
+
+func (s *ReplicaDatabaseService) GetRecentDeliveredPayloads(filters GetPayloadsFilters) ([]*DeliveredPayloadEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*DeliveredPayloadEntry, error) {
+		return db.GetRecentDeliveredPayloads(filters)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetDeliveredPayloadsByBuilderOperator(filters GetPayloadsFilters) ([]*BuilderOperatorDeliveredEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*BuilderOperatorDeliveredEntry, error) {
+		return db.GetDeliveredPayloadsByBuilderOperator(filters)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetProposerRequests(slot uint64) ([]*ProposerRequestEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*ProposerRequestEntry, error) {
+		return db.GetProposerRequests(slot)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetTopBuilders(since time.Time, limit uint64) ([]*TopBuilderEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*TopBuilderEntry, error) {
+		return db.GetTopBuilders(since, limit)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetBidArchiveEntries(slot, limit uint64) ([]*BidArchiveEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*BidArchiveEntry, error) {
+		return db.GetBidArchiveEntries(slot, limit)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetPaymentVerifications(filters GetPaymentVerificationsFilters) ([]*PaymentVerificationEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*PaymentVerificationEntry, error) {
+		return db.GetPaymentVerifications(filters)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetSlotSummaries(filters GetSlotSummariesFilters) ([]*SlotSummaryEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*SlotSummaryEntry, error) {
+		return db.GetSlotSummaries(filters)
+	})
+}
+
+func (s *ReplicaDatabaseService) GetEpochSummaries(filters GetEpochSummariesFilters) ([]*EpochSummaryEntry, error) {
+	return readQuery(s, func(db IDatabaseService) ([]*EpochSummaryEntry, error) {
+		return db.GetEpochSummaries(filters)
+	})
+}
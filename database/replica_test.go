@@ -0,0 +1,53 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+var errReplicaDown = errors.New("replica down")
+
+// countingDB returns a fixed number of delivered payloads, or an error, and counts the queries
+type countingDB struct {
+	MockDB
+	numPayloads uint64
+	err         error
+	numQueries  *int
+}
+
+func (db countingDB) GetNumDeliveredPayloads() (uint64, error) {
+	*db.numQueries++
+	return db.numPayloads, db.err
+}
+
+func TestReplicaDatabaseService(t *testing.T) {
+	numPrimaryQueries, numReplicaQueries := 0, 0
+	primary := countingDB{numPayloads: 1, numQueries: &numPrimaryQueries}
+	replica := &countingDB{numPayloads: 2, numQueries: &numReplicaQueries}
+	db := NewReplicaDatabaseService(common.TestLog, primary, replica)
+
+	// reads go to the replica
+	num, err := db.GetNumDeliveredPayloads()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), num)
+
+	// if the replica fails, the primary is used until the retry interval has passed
+	replica.err = errReplicaDown
+	num, err = db.GetNumDeliveredPayloads()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), num)
+	_, err = db.GetNumDeliveredPayloads()
+	require.NoError(t, err)
+	require.Equal(t, 2, numReplicaQueries)
+	require.Equal(t, 2, numPrimaryQueries)
+
+	replica.err = nil
+	db.replicaDownUntil.Store(time.Now())
+	num, err = db.GetNumDeliveredPayloads()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), num)
+}
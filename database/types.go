@@ -7,6 +7,7 @@ import (
 
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/lib/pq"
 )
 
 func NewNullInt64(i int64) sql.NullInt64 {
@@ -32,6 +33,14 @@ func NewNullTime(t time.Time) sql.NullTime {
 	}
 }
 
+// unixNanoOrZero returns the unix nanoseconds of the time, or 0 for the zero time
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
 type GetPayloadsFilters struct {
 	Slot           int64
 	Cursor         int64
@@ -60,6 +69,21 @@ type ValidatorRegistrationEntry struct {
 	Timestamp    uint64 `db:"timestamp"`
 	GasLimit     uint64 `db:"gas_limit"`
 	Signature    string `db:"signature"`
+
+	// SourceIP is not stored with the registration, but in the history entry if the fee recipient changed
+	SourceIP string `db:"source_ip"`
+}
+
+// ValidatorRegistrationHistoryEntry is a change of the fee recipient of a validator registration
+type ValidatorRegistrationHistoryEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Pubkey          string `db:"pubkey"`
+	OldFeeRecipient string `db:"old_fee_recipient"`
+	NewFeeRecipient string `db:"new_fee_recipient"`
+	Timestamp       uint64 `db:"timestamp"`
+	SourceIP        string `db:"source_ip"`
 }
 
 func (reg ValidatorRegistrationEntry) ToSignedValidatorRegistration() (*builderApiV1.SignedValidatorRegistration, error) {
@@ -109,6 +133,8 @@ type ExecutionPayloadEntry struct {
 
 	Version string `db:"version"`
 	Payload string `db:"payload"`
+
+	CompactedAt sql.NullTime `db:"compacted_at"` // set if the payload was replaced by its roots and summary fields
 }
 
 var ExecutionPayloadEntryCSVHeader = []string{"id", "inserted_at", "slot", "proposer_pubkey", "block_hash", "version", "payload"}
@@ -131,6 +157,11 @@ type BuilderBlockSubmissionEntry struct {
 	ReceivedAt sql.NullTime `db:"received_at"`
 	EligibleAt sql.NullTime `db:"eligible_at"`
 
+	// Unix nanoseconds of the receive, decode-complete and eligibility times (0 if unknown or never eligible)
+	ReceivedAtNs int64 `db:"received_at_ns"`
+	DecodedAtNs  int64 `db:"decoded_at_ns"`
+	EligibleAtNs int64 `db:"eligible_at_ns"`
+
 	// Delivered ExecutionPayload
 	ExecutionPayloadID sql.NullInt64 `db:"execution_payload_id"`
 
@@ -154,8 +185,9 @@ type BuilderBlockSubmissionEntry struct {
 	GasUsed  uint64 `db:"gas_used"`
 	GasLimit uint64 `db:"gas_limit"`
 
-	NumTx uint64 `db:"num_tx"`
-	Value string `db:"value"`
+	NumTx    uint64 `db:"num_tx"`
+	NumBlobs uint64 `db:"num_blobs"`
+	Value    string `db:"value"`
 
 	// Helpers
 	Epoch       uint64 `db:"epoch"`
@@ -168,6 +200,10 @@ type BuilderBlockSubmissionEntry struct {
 	RedisUpdateDuration  uint64 `db:"redis_update_duration"`
 	TotalDuration        uint64 `db:"total_duration"`
 	OptimisticSubmission bool   `db:"optimistic_submission"`
+	TrustedSubmission    bool   `db:"trusted_submission"`
+
+	// Relay instance which received the submission (empty for submissions from before instances were recorded)
+	InstanceID string `db:"instance_id"`
 }
 
 type DeliveredPayloadEntry struct {
@@ -194,11 +230,41 @@ type DeliveredPayloadEntry struct {
 	NumTx uint64 `db:"num_tx"`
 	Value string `db:"value"`
 
+	// Relay fee of the builder agreement, and the value net of the relay fee (NULL for payloads delivered before
+	// relay fees were introduced)
+	RelayFee      string         `db:"relay_fee"`
+	AdjustedValue sql.NullString `db:"adjusted_value"`
+
 	NumBlobs      uint64 `db:"num_blobs"`
 	BlobGasUsed   uint64 `db:"blob_gas_used"`
 	ExcessBlobGas uint64 `db:"excess_blob_gas"`
 
-	PublishMs uint64 `db:"publish_ms"`
+	PublishMs  uint64 `db:"publish_ms"`
+	MsIntoSlot int64  `db:"ms_into_slot"`
+
+	// Publish outcome of every beacon node as JSON (NULL if the payload was published by another instance, or
+	// delivered before publish outcomes were recorded)
+	PublishOutcomes sql.NullString `db:"publish_outcomes"`
+
+	// Relay mode which was active when the payload was delivered (empty for payloads delivered before relay modes)
+	RelayMode string `db:"relay_mode"`
+
+	// Relay key which signed the bid (empty for payloads delivered before it was recorded)
+	RelayPubkey string `db:"relay_pubkey"`
+
+	// Relay instance which delivered the payload (empty for payloads delivered before instances were recorded, or
+	// imported from another relay)
+	InstanceID string `db:"instance_id"`
+
+	// Relay the payload was imported from (empty if it was delivered by this relay)
+	Source string `db:"source"`
+}
+
+// TopBuilderEntry is the aggregated delivered value of a single builder pubkey
+type TopBuilderEntry struct {
+	BuilderPubkey string `db:"builder_pubkey"`
+	NumPayloads   uint64 `db:"num_payloads"`
+	TotalValue    string `db:"total_value"`
 }
 
 type BlockBuilderEntry struct {
@@ -215,6 +281,12 @@ type BlockBuilderEntry struct {
 	Collateral string `db:"collateral" json:"collateral"`
 	BuilderID  string `db:"builder_id" json:"builder_id"`
 
+	RelayFeeBps uint64 `db:"relay_fee_bps" json:"relay_fee_bps"`
+
+	Operator string `db:"operator" json:"operator"`
+	Region   string `db:"region"   json:"region"`
+	Tier     string `db:"tier"     json:"tier"`
+
 	LastSubmissionID   sql.NullInt64 `db:"last_submission_id"   json:"last_submission_id"`
 	LastSubmissionSlot uint64        `db:"last_submission_slot" json:"last_submission_slot"`
 
@@ -224,6 +296,26 @@ type BlockBuilderEntry struct {
 	NumSentGetPayload uint64 `db:"num_sent_getpayload" json:"num_sent_getpayload"`
 }
 
+// BuilderOperatorStatsEntry are the stats of all builder pubkeys of an operator
+type BuilderOperatorStatsEntry struct {
+	Operator               string `db:"operator"                 json:"operator"`
+	NumPubkeys             uint64 `db:"num_pubkeys"              json:"num_pubkeys"`
+	NumSubmissionsTotal    uint64 `db:"num_submissions_total"    json:"num_submissions_total"`
+	NumSubmissionsSimError uint64 `db:"num_submissions_simerror" json:"num_submissions_simerror"`
+	NumSentGetPayload      uint64 `db:"num_sent_getpayload"      json:"num_sent_getpayload"`
+	LastSubmissionSlot     uint64 `db:"last_submission_slot"     json:"last_submission_slot"`
+}
+
+// BuilderOperatorDeliveredEntry are the payloads delivered by all builder pubkeys of an operator
+type BuilderOperatorDeliveredEntry struct {
+	Operator    string `db:"operator"     json:"builder_operator"`
+	NumPubkeys  uint64 `db:"num_pubkeys"  json:"num_builder_pubkeys,string"`
+	NumPayloads uint64 `db:"num_payloads" json:"num_payloads,string"`
+	TotalValue  string `db:"total_value"  json:"total_value"`
+	FirstSlot   uint64 `db:"first_slot"   json:"first_slot,string"`
+	LastSlot    uint64 `db:"last_slot"    json:"last_slot,string"`
+}
+
 type BuilderDemotionEntry struct {
 	ID         int64     `db:"id"`
 	InsertedAt time.Time `db:"inserted_at"`
@@ -261,3 +353,186 @@ type TooLateGetPayloadEntry struct {
 	BlockHash      string `db:"block_hash"`
 	MsIntoSlot     uint64 `db:"ms_into_slot"`
 }
+
+// ProposerRequestEntry is a getHeader or getPayload request of a proposer, with the response
+type ProposerRequestEntry struct {
+	ID         int64     `db:"id"          json:"-"`
+	InsertedAt time.Time `db:"inserted_at" json:"-"`
+	ReceivedAt time.Time `db:"received_at" json:"received_at"`
+
+	Method         string `db:"method"          json:"method"`
+	RequestID      string `db:"request_id"      json:"request_id"`
+	Slot           uint64 `db:"slot"            json:"slot,string"`
+	ProposerPubkey string `db:"proposer_pubkey" json:"proposer_pubkey"`
+	ParentHash     string `db:"parent_hash"     json:"parent_hash"` // empty for getPayload
+	BlockHash      string `db:"block_hash"      json:"block_hash"`  // block hash of the served bid or requested payload
+
+	// the IPs of proposers are not served by the data API, as they would deanonymize validators
+	IP           string `db:"ip"            json:"-"`
+	ForwardedFor string `db:"forwarded_for" json:"-"`
+	UserAgent    string `db:"user_agent"    json:"user_agent"`
+	MsIntoSlot   int64  `db:"ms_into_slot"  json:"ms_into_slot"`
+	DurationMs   int64  `db:"duration_ms"   json:"duration_ms"`
+	StatusCode   int    `db:"status_code"   json:"status_code"`
+}
+
+type GetPayloadEquivocationEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Slot           uint64 `db:"slot"`
+	ProposerPubkey string `db:"proposer_pubkey"`
+
+	FirstBlockHash string `db:"first_block_hash"`
+	BlockHash      string `db:"block_hash"`
+
+	SignedBlindedBeaconBlock sql.NullString `db:"signed_blinded_beacon_block"`
+	MsIntoSlot               int64          `db:"ms_into_slot"`
+}
+
+type BidArchiveEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+	ReceivedAt time.Time `db:"received_at"`
+
+	Slot                 uint64 `db:"slot"`
+	ParentHash           string `db:"parent_hash"`
+	BlockHash            string `db:"block_hash"`
+	BuilderPubkey        string `db:"builder_pubkey"`
+	ProposerPubkey       string `db:"proposer_pubkey"`
+	ProposerFeeRecipient string `db:"proposer_fee_recipient"`
+
+	GasUsed     uint64 `db:"gas_used"`
+	GasLimit    uint64 `db:"gas_limit"`
+	NumTx       uint64 `db:"num_tx"`
+	Value       string `db:"value"`
+	BlockNumber uint64 `db:"block_number"`
+
+	Accepted        bool   `db:"accepted"`
+	StatusCode      int    `db:"status_code"`
+	ErrorCode       string `db:"error_code"`
+	RejectionReason string `db:"rejection_reason"`
+}
+
+const (
+	PaymentStatusOK               = "ok"
+	PaymentStatusUnderpaid        = "underpaid"
+	PaymentStatusBlockNotIncluded = "block_not_included"
+
+	PaymentMethodLastTx      = "last_tx"
+	PaymentMethodBalanceDiff = "balance_diff"
+)
+
+// PaymentVerificationEntry is the result of verifying the proposer payment of a delivered payload on the execution layer
+type PaymentVerificationEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Slot                 uint64 `db:"slot"`
+	BlockNumber          uint64 `db:"block_number"`
+	BlockHash            string `db:"block_hash"`
+	BuilderPubkey        string `db:"builder_pubkey"`
+	ProposerPubkey       string `db:"proposer_pubkey"`
+	ProposerFeeRecipient string `db:"proposer_fee_recipient"`
+
+	ExpectedValue string `db:"expected_value"`
+	ReceivedValue string `db:"received_value"`
+	Method        string `db:"method"`
+	Status        string `db:"status"`
+}
+
+// BidPaymentDiscrepancyEntry is a block submission which was rejected because the bid value exceeds the payment to
+// the proposer
+type BidPaymentDiscrepancyEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Slot                 uint64 `db:"slot"`
+	BlockHash            string `db:"block_hash"`
+	BuilderPubkey        string `db:"builder_pubkey"`
+	ProposerPubkey       string `db:"proposer_pubkey"`
+	ProposerFeeRecipient string `db:"proposer_fee_recipient"`
+
+	ClaimedValue string `db:"claimed_value"`
+	PaidValue    string `db:"paid_value"`
+}
+
+// ProposerPreferencesEntry contains the preferences of a proposer, which are enforced by the relay in getHeader
+type ProposerPreferencesEntry struct {
+	InsertedAt time.Time `db:"inserted_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+
+	ProposerPubkey string `db:"proposer_pubkey"`
+	MinBidValue    string `db:"min_bid_value"` // in wei, bids below are not returned in getHeader
+
+	// Builder allowlist/blocklist, set by a message signed by the proposer
+	AllowedBuilders             pq.StringArray `db:"allowed_builders"`
+	DeniedBuilders              pq.StringArray `db:"denied_builders"`
+	BuilderPreferencesTimestamp uint64         `db:"builder_preferences_timestamp"`
+	BuilderPreferencesSignature string         `db:"builder_preferences_signature"`
+}
+
+type GetPaymentVerificationsFilters struct {
+	Slot              uint64
+	Status            string
+	DiscrepanciesOnly bool
+	Limit             uint64
+}
+
+// SlotSummaryEntry is the summary of the auction of a slot: the bids received, the delivered payload, and the number
+// of getHeader and getPayload requests
+type SlotSummaryEntry struct {
+	Slot       uint64    `db:"slot"`
+	InsertedAt time.Time `db:"inserted_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+
+	NumBids        uint64 `db:"num_bids"`
+	NumBuilders    uint64 `db:"num_builders"`
+	NumSimFailures uint64 `db:"num_sim_failures"`
+	HighestValue   string `db:"highest_value"`
+
+	WinningBlockHash string `db:"winning_block_hash"` // empty if no payload was delivered
+	WinningValue     string `db:"winning_value"`
+
+	NumGetHeader  uint64 `db:"num_getheader"`
+	NumGetPayload uint64 `db:"num_getpayload"`
+}
+
+type GetSlotSummariesFilters struct {
+	Slot   uint64
+	Cursor uint64
+	Limit  uint64
+}
+
+// EpochSummaryEntry is the rollup of an epoch: the validator registrations processed, the getHeader responses with a
+// bid, and the delivered payloads
+type EpochSummaryEntry struct {
+	Epoch      uint64    `db:"epoch"`
+	InsertedAt time.Time `db:"inserted_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+
+	NumRegistrations     uint64 `db:"num_registrations"`
+	NumHeadersServed     uint64 `db:"num_headers_served"`
+	NumPayloadsDelivered uint64 `db:"num_payloads_delivered"`
+	DeliveredValue       string `db:"delivered_value"`
+}
+
+type GetEpochSummariesFilters struct {
+	FromEpoch uint64
+	ToEpoch   uint64 // 0 means no upper bound
+	Limit     uint64
+}
+
+// FeatureFlagChangeEntry is the audit log entry of a feature flag which was toggled at runtime
+type FeatureFlagChangeEntry struct {
+	ID         int64     `db:"id"          json:"-"`
+	InsertedAt time.Time `db:"inserted_at" json:"inserted_at"`
+
+	InstanceID  string `db:"instance_id"  json:"instance_id"`
+	Name        string `db:"name"         json:"name"`
+	Enabled     bool   `db:"enabled"      json:"enabled"`
+	PrevEnabled bool   `db:"prev_enabled" json:"prev_enabled"`
+	Actor       string `db:"actor"        json:"actor"`
+	Reason      string `db:"reason"       json:"reason"`
+	IP          string `db:"ip"           json:"ip"`
+}
@@ -3,16 +3,33 @@ package database
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
 	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
 )
 
 var ErrUnsupportedExecutionPayload = errors.New("unsupported execution payload version")
 
+// electraExecutionPayloadEntryJSON is the stored payload of an electra submission. It extends the deneb payload and
+// blobs bundle with the execution requests, so that the submission can be reconstructed.
+type electraExecutionPayloadEntryJSON struct {
+	ExecutionPayload  *deneb.ExecutionPayload      `json:"execution_payload"`
+	BlobsBundle       *builderApiDeneb.BlobsBundle `json:"blobs_bundle"`
+	ExecutionRequests *electra.ExecutionRequests   `json:"execution_requests"`
+}
+
 func PayloadToExecPayloadEntry(payload *common.VersionedSubmitBlockRequest) (*ExecutionPayloadEntry, error) {
 	var _payload []byte
 	var version string
@@ -26,7 +43,7 @@ func PayloadToExecPayloadEntry(payload *common.VersionedSubmitBlockRequest) (*Ex
 		}
 		version = common.ForkVersionStringCapella
 	case spec.DataVersionDeneb:
-		_payload, err = json.Marshal(builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+		_payload, err = json.Marshal(&builderApiDeneb.ExecutionPayloadAndBlobsBundle{
 			ExecutionPayload: payload.Deneb.ExecutionPayload,
 			BlobsBundle:      payload.Deneb.BlobsBundle,
 		})
@@ -34,6 +51,16 @@ func PayloadToExecPayloadEntry(payload *common.VersionedSubmitBlockRequest) (*Ex
 			return nil, err
 		}
 		version = common.ForkVersionStringDeneb
+	case spec.DataVersionElectra:
+		_payload, err = json.Marshal(&electraExecutionPayloadEntryJSON{
+			ExecutionPayload:  payload.Electra.ExecutionPayload,
+			BlobsBundle:       payload.Electra.BlobsBundle,
+			ExecutionRequests: payload.Electra.ExecutionRequests,
+		})
+		if err != nil {
+			return nil, err
+		}
+		version = common.ForkVersionStringElectra
 	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
 		return nil, ErrUnsupportedExecutionPayload
 	}
@@ -54,6 +81,10 @@ func PayloadToExecPayloadEntry(payload *common.VersionedSubmitBlockRequest) (*Ex
 }
 
 func DeliveredPayloadEntryToBidTraceV2JSON(payload *DeliveredPayloadEntry) common.BidTraceV2JSON {
+	adjustedValue := payload.Value
+	if payload.AdjustedValue.Valid {
+		adjustedValue = payload.AdjustedValue.String
+	}
 	return common.BidTraceV2JSON{
 		Slot:                 payload.Slot,
 		ParentHash:           payload.ParentHash,
@@ -66,9 +97,48 @@ func DeliveredPayloadEntryToBidTraceV2JSON(payload *DeliveredPayloadEntry) commo
 		Value:                payload.Value,
 		NumTx:                payload.NumTx,
 		BlockNumber:          payload.BlockNumber,
+		RelayFee:             payload.RelayFee,
+		AdjustedValue:        adjustedValue,
+		RelayMode:            payload.RelayMode,
+		RelayPubkey:          payload.RelayPubkey,
 	}
 }
 
+// BidTraceV2JSONToDeliveredPayloadEntry converts a delivered payload from the data API of another relay, to import it
+// with the given source
+func BidTraceV2JSONToDeliveredPayloadEntry(bidTrace common.BidTraceV2JSON, source string) *DeliveredPayloadEntry {
+	entry := &DeliveredPayloadEntry{
+		Slot:  bidTrace.Slot,
+		Epoch: common.SlotToEpoch(bidTrace.Slot),
+
+		BuilderPubkey:        bidTrace.BuilderPubkey,
+		ProposerPubkey:       bidTrace.ProposerPubkey,
+		ProposerFeeRecipient: bidTrace.ProposerFeeRecipient,
+
+		ParentHash:  bidTrace.ParentHash,
+		BlockHash:   bidTrace.BlockHash,
+		BlockNumber: bidTrace.BlockNumber,
+
+		GasUsed:  bidTrace.GasUsed,
+		GasLimit: bidTrace.GasLimit,
+
+		NumTx: bidTrace.NumTx,
+		Value: bidTrace.Value,
+
+		RelayFee:    "0",
+		RelayMode:   bidTrace.RelayMode,
+		RelayPubkey: bidTrace.RelayPubkey,
+		Source:      source,
+	}
+
+	// Only relays with relay fees report them
+	if bidTrace.RelayFee != "" {
+		entry.RelayFee = bidTrace.RelayFee
+		entry.AdjustedValue = NewNullString(bidTrace.AdjustedValue)
+	}
+	return entry
+}
+
 func BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload *BuilderBlockSubmissionEntry) common.BidTraceV2WithTimestampJSON {
 	timestamp := payload.InsertedAt
 	if payload.ReceivedAt.Valid {
@@ -79,6 +149,9 @@ func BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload *BuilderBlockSu
 		Timestamp:            timestamp.Unix(),
 		TimestampMs:          timestamp.UnixMilli(),
 		OptimisticSubmission: payload.OptimisticSubmission,
+		TimestampNs:          payload.ReceivedAtNs,
+		DecodedAtNs:          payload.DecodedAtNs,
+		EligibleAtNs:         payload.EligibleAtNs,
 		BidTraceV2JSON: common.BidTraceV2JSON{
 			Slot:                 payload.Slot,
 			ParentHash:           payload.ParentHash,
@@ -95,9 +168,62 @@ func BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload *BuilderBlockSu
 	}
 }
 
+// DeliveredPayloadEntryToDeliveredPayloadV2JSON converts a delivered payload to the v2 schema of the data API
+func DeliveredPayloadEntryToDeliveredPayloadV2JSON(payload *DeliveredPayloadEntry) common.DeliveredPayloadV2JSON {
+	timestamp := payload.InsertedAt
+	if payload.SignedAt.Valid {
+		timestamp = payload.SignedAt.Time
+	}
+
+	return common.DeliveredPayloadV2JSON{
+		BidTraceV2JSON: DeliveredPayloadEntryToBidTraceV2JSON(payload),
+		TimestampMs:    timestamp.UnixMilli(),
+		MsIntoSlot:     payload.MsIntoSlot,
+		PublishMs:      payload.PublishMs,
+		NumBlobs:       payload.NumBlobs,
+		BlobGasUsed:    payload.BlobGasUsed,
+		ExcessBlobGas:  payload.ExcessBlobGas,
+	}
+}
+
+// BuilderSubmissionEntryToBuilderBidV2JSON converts a block submission to the v2 schema of the data API
+func BuilderSubmissionEntryToBuilderBidV2JSON(payload *BuilderBlockSubmissionEntry) common.BuilderBidV2JSON {
+	v1 := BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload)
+	bid := common.BuilderBidV2JSON{
+		BidTraceV2JSON: v1.BidTraceV2JSON,
+		TimestampMs:    v1.TimestampMs,
+		Optimistic:     payload.OptimisticSubmission,
+		NumBlobs:       payload.NumBlobs,
+	}
+	if payload.ReceivedAtNs != 0 {
+		bid.TimestampMs = time.Unix(0, payload.ReceivedAtNs).UnixMilli()
+	}
+	if payload.DecodedAtNs != 0 {
+		bid.DecodedAtMs = time.Unix(0, payload.DecodedAtNs).UnixMilli()
+	}
+	if payload.EligibleAtNs != 0 {
+		bid.EligibleAtMs = time.Unix(0, payload.EligibleAtNs).UnixMilli()
+	}
+	return bid
+}
+
 func ExecutionPayloadEntryToExecutionPayload(executionPayloadEntry *ExecutionPayloadEntry) (payload *builderApi.VersionedSubmitBlindedBlockResponse, err error) {
+	if executionPayloadEntry.CompactedAt.Valid {
+		return nil, ErrExecutionPayloadCompacted
+	}
 	payloadVersion := executionPayloadEntry.Version
-	if payloadVersion == common.ForkVersionStringDeneb {
+	if payloadVersion == common.ForkVersionStringElectra {
+		// the execution requests are not part of the response, and ignored here
+		executionPayload := new(builderApiDeneb.ExecutionPayloadAndBlobsBundle)
+		err = json.Unmarshal([]byte(executionPayloadEntry.Payload), executionPayload)
+		if err != nil {
+			return nil, err
+		}
+		return &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionElectra,
+			Electra: executionPayload,
+		}, nil
+	} else if payloadVersion == common.ForkVersionStringDeneb {
 		executionPayload := new(builderApiDeneb.ExecutionPayloadAndBlobsBundle)
 		err = json.Unmarshal([]byte(executionPayloadEntry.Payload), executionPayload)
 		if err != nil {
@@ -121,3 +247,224 @@ func ExecutionPayloadEntryToExecutionPayload(executionPayloadEntry *ExecutionPay
 		return nil, ErrUnsupportedExecutionPayload
 	}
 }
+
+// BuilderSubmissionEntryToSubmitBlockRequest reconstructs the submission of a builder from the stored bid trace and
+// execution payload, i.e. to replay it
+func BuilderSubmissionEntryToSubmitBlockRequest(entry *BuilderBlockSubmissionEntry, executionPayloadEntry *ExecutionPayloadEntry) (*common.VersionedSubmitBlockRequest, error) {
+	bidTrace, err := builderSubmissionEntryToBidTrace(entry)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := utils.HexToSignature(entry.Signature)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := ExecutionPayloadEntryToExecutionPayload(executionPayloadEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	request := new(common.VersionedSubmitBlockRequest)
+	request.Version = payload.Version
+	switch payload.Version { //nolint:exhaustive
+	case spec.DataVersionCapella:
+		request.Capella = &builderApiCapella.SubmitBlockRequest{
+			Message:          bidTrace,
+			ExecutionPayload: payload.Capella,
+			Signature:        signature,
+		}
+	case spec.DataVersionDeneb:
+		request.Deneb = &builderApiDeneb.SubmitBlockRequest{
+			Message:          bidTrace,
+			ExecutionPayload: payload.Deneb.ExecutionPayload,
+			BlobsBundle:      payload.Deneb.BlobsBundle,
+			Signature:        signature,
+		}
+	case spec.DataVersionElectra:
+		entryJSON := new(electraExecutionPayloadEntryJSON)
+		err = json.Unmarshal([]byte(executionPayloadEntry.Payload), entryJSON)
+		if err != nil {
+			return nil, err
+		}
+		request.Electra = &builderApiElectra.SubmitBlockRequest{
+			Message:           bidTrace,
+			ExecutionPayload:  payload.Electra.ExecutionPayload,
+			BlobsBundle:       payload.Electra.BlobsBundle,
+			ExecutionRequests: entryJSON.ExecutionRequests,
+			Signature:         signature,
+		}
+	default:
+		return nil, ErrUnsupportedExecutionPayload
+	}
+	return request, nil
+}
+
+func builderSubmissionEntryToBidTrace(entry *BuilderBlockSubmissionEntry) (*builderApiV1.BidTrace, error) {
+	parentHash, err := utils.HexToHash(entry.ParentHash)
+	if err != nil {
+		return nil, err
+	}
+	blockHash, err := utils.HexToHash(entry.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	builderPubkey, err := utils.HexToPubkey(entry.BuilderPubkey)
+	if err != nil {
+		return nil, err
+	}
+	proposerPubkey, err := utils.HexToPubkey(entry.ProposerPubkey)
+	if err != nil {
+		return nil, err
+	}
+	feeRecipient, err := utils.HexToAddress(entry.ProposerFeeRecipient)
+	if err != nil {
+		return nil, err
+	}
+	value, err := uint256.FromDecimal(entry.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &builderApiV1.BidTrace{
+		Slot:                 entry.Slot,
+		ParentHash:           parentHash,
+		BlockHash:            blockHash,
+		BuilderPubkey:        builderPubkey,
+		ProposerPubkey:       proposerPubkey,
+		ProposerFeeRecipient: feeRecipient,
+		GasLimit:             entry.GasLimit,
+		GasUsed:              entry.GasUsed,
+		Value:                value,
+	}, nil
+}
+
+func BidArchiveEntryToArchivedBidJSON(entry *BidArchiveEntry) common.ArchivedBidJSON {
+	return common.ArchivedBidJSON{
+		TimestampMs:     entry.ReceivedAt.UnixMilli(),
+		Accepted:        entry.Accepted,
+		StatusCode:      entry.StatusCode,
+		ErrorCode:       entry.ErrorCode,
+		RejectionReason: entry.RejectionReason,
+		BidTraceV2JSON: common.BidTraceV2JSON{
+			Slot:                 entry.Slot,
+			ParentHash:           entry.ParentHash,
+			BlockHash:            entry.BlockHash,
+			BuilderPubkey:        entry.BuilderPubkey,
+			ProposerPubkey:       entry.ProposerPubkey,
+			ProposerFeeRecipient: entry.ProposerFeeRecipient,
+			GasLimit:             entry.GasLimit,
+			GasUsed:              entry.GasUsed,
+			Value:                entry.Value,
+			NumTx:                entry.NumTx,
+			BlockNumber:          entry.BlockNumber,
+		},
+	}
+}
+
+func PaymentVerificationEntryToPaymentVerificationJSON(entry *PaymentVerificationEntry) common.PaymentVerificationJSON {
+	return common.PaymentVerificationJSON{
+		Slot:                 entry.Slot,
+		BlockNumber:          entry.BlockNumber,
+		BlockHash:            entry.BlockHash,
+		BuilderPubkey:        entry.BuilderPubkey,
+		ProposerPubkey:       entry.ProposerPubkey,
+		ProposerFeeRecipient: entry.ProposerFeeRecipient,
+		ExpectedValue:        entry.ExpectedValue,
+		ReceivedValue:        entry.ReceivedValue,
+		Method:               entry.Method,
+		Status:               entry.Status,
+		VerifiedAt:           entry.InsertedAt.UnixMilli(),
+	}
+}
+
+func SlotSummaryEntryToSlotSummaryJSON(entry *SlotSummaryEntry) common.SlotSummaryJSON {
+	return common.SlotSummaryJSON{
+		Slot:             entry.Slot,
+		NumBids:          entry.NumBids,
+		NumBuilders:      entry.NumBuilders,
+		NumSimFailures:   entry.NumSimFailures,
+		HighestValue:     entry.HighestValue,
+		WinningBlockHash: entry.WinningBlockHash,
+		WinningValue:     entry.WinningValue,
+		NumGetHeader:     entry.NumGetHeader,
+		NumGetPayload:    entry.NumGetPayload,
+		UpdatedAt:        entry.UpdatedAt.UnixMilli(),
+	}
+}
+
+func EpochSummaryEntryToEpochSummaryJSON(entry *EpochSummaryEntry) common.EpochSummaryJSON {
+	return common.EpochSummaryJSON{
+		Epoch:                entry.Epoch,
+		NumRegistrations:     entry.NumRegistrations,
+		NumHeadersServed:     entry.NumHeadersServed,
+		NumPayloadsDelivered: entry.NumPayloadsDelivered,
+		DeliveredValue:       entry.DeliveredValue,
+		UpdatedAt:            entry.UpdatedAt.UnixMilli(),
+	}
+}
+
+func BuilderDemotionEntryToBuilderDemotionJSON(entry *BuilderDemotionEntry) common.BuilderDemotionJSON {
+	ret := common.BuilderDemotionJSON{
+		Slot:           entry.Slot,
+		Epoch:          entry.Epoch,
+		BuilderPubkey:  entry.BuilderPubkey,
+		ProposerPubkey: entry.ProposerPubkey,
+		Value:          entry.Value,
+		FeeRecipient:   entry.FeeRecipient,
+		BlockHash:      entry.BlockHash,
+		Error:          entry.SimError,
+		DemotedAt:      entry.InsertedAt.UnixMilli(),
+		RefundEligible: entry.SignedBeaconBlock.Valid,
+	}
+	if entry.SubmitBlockRequest.Valid {
+		ret.SubmitBlockRequest = json.RawMessage(entry.SubmitBlockRequest.String)
+	}
+	if entry.SignedBeaconBlock.Valid {
+		ret.SignedBeaconBlock = json.RawMessage(entry.SignedBeaconBlock.String)
+	}
+	if entry.SignedValidatorRegistration.Valid {
+		ret.SignedValidatorRegistration = json.RawMessage(entry.SignedValidatorRegistration.String)
+	}
+	return ret
+}
+
+func pubkeysToStrings(pubkeys []phase0.BLSPubKey) []string {
+	ret := make([]string, len(pubkeys))
+	for i, pubkey := range pubkeys {
+		ret[i] = pubkey.String()
+	}
+	return ret
+}
+
+func stringsToPubkeys(pubkeysHex []string) ([]phase0.BLSPubKey, error) {
+	ret := make([]phase0.BLSPubKey, len(pubkeysHex))
+	for i, pubkeyHex := range pubkeysHex {
+		pubkey, err := common.StrToPhase0Pubkey(pubkeyHex)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = pubkey
+	}
+	return ret, nil
+}
+
+func ProposerPreferencesEntryToBuilderPreferences(entry *ProposerPreferencesEntry) (*common.BuilderPreferences, error) {
+	pubkey, err := common.StrToPhase0Pubkey(entry.ProposerPubkey)
+	if err != nil {
+		return nil, err
+	}
+	allowedBuilders, err := stringsToPubkeys(entry.AllowedBuilders)
+	if err != nil {
+		return nil, err
+	}
+	deniedBuilders, err := stringsToPubkeys(entry.DeniedBuilders)
+	if err != nil {
+		return nil, err
+	}
+	return &common.BuilderPreferences{
+		Pubkey:          pubkey,
+		Timestamp:       entry.BuilderPreferencesTimestamp,
+		AllowedBuilders: allowedBuilders,
+		DeniedBuilders:  deniedBuilders,
+	}, nil
+}
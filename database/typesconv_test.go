@@ -1,9 +1,11 @@
 package database
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +48,99 @@ func TestExecutionPayloadEntryToExecutionPayloadDeneb(t *testing.T) {
 	require.Equal(t, "0xbd1ae4f7edb2315d2df70a8d9881fab8d6763fb1c00533ae729050928c38d05a", payload.Deneb.ExecutionPayload.BlockHash.String())
 	require.Len(t, payload.Deneb.BlobsBundle.Blobs, 1)
 }
+
+func TestBidTraceV2JSONToDeliveredPayloadEntry(t *testing.T) {
+	bidTrace := common.BidTraceV2JSON{
+		Slot:           7432891,
+		BlockHash:      "0xbd1ae4f7edb2315d2df70a8d9881fab8d6763fb1c00533ae729050928c38d05a",
+		ProposerPubkey: "0x8559727ee65c295279332198029c939557f4d2aba0751fc55f71d0733b8aa17cd0301232a7f21a895f81eacf55c97ec4",
+		Value:          "1000",
+	}
+	entry := BidTraceV2JSONToDeliveredPayloadEntry(bidTrace, "relay.example.com")
+	require.Equal(t, common.SlotToEpoch(7432891), entry.Epoch)
+	require.Equal(t, "1000", entry.Value)
+	require.Equal(t, "0", entry.RelayFee)
+	require.False(t, entry.AdjustedValue.Valid)
+	require.Equal(t, "relay.example.com", entry.Source)
+
+	bidTrace.RelayFee = "10"
+	bidTrace.AdjustedValue = "990"
+	entry = BidTraceV2JSONToDeliveredPayloadEntry(bidTrace, "relay.example.com")
+	require.Equal(t, "10", entry.RelayFee)
+	require.Equal(t, "990", entry.AdjustedValue.String)
+}
+
+func TestBuilderSubmissionEntryToSubmitBlockRequest(t *testing.T) {
+	filename := "../testdata/executionPayloadCapella_Goerli.json.gz"
+	payloadBytes := common.LoadGzippedBytes(t, filename)
+	blockHash := "0x1bafdc454116b605005364976b134d761dd736cb4788d25c835783b46daeb121"
+	proposerPubkey := "0x8559727ee65c295279332198029c939557f4d2aba0751fc55f71d0733b8aa17cd0301232a7f21a895f81eacf55c97ec4"
+	entry := &BuilderBlockSubmissionEntry{
+		Signature:            "0x80c83707c1cf762a0bc3bf7df2976d092001eeef9efa820ad903085a100e4c1833b73d294e8fd1d8be28522772ee7c4401d4908bbf4baf9670aecc282196233f5e346b5c4bf5a1c8e8d5a2dbb5ebc4834098bc84fd7f64d0fac486befbf7d42d",
+		Slot:                 5552306,
+		ParentHash:           "0x" + strings.Repeat("01", 32),
+		BlockHash:            blockHash,
+		BuilderPubkey:        proposerPubkey,
+		ProposerPubkey:       proposerPubkey,
+		ProposerFeeRecipient: "0x" + strings.Repeat("02", 20),
+		GasUsed:              100,
+		GasLimit:             200,
+		Value:                "12345",
+	}
+	payloadEntry := &ExecutionPayloadEntry{
+		Slot:           5552306,
+		ProposerPubkey: proposerPubkey,
+		BlockHash:      blockHash,
+		Version:        common.ForkVersionStringCapella,
+		Payload:        string(payloadBytes),
+	}
+
+	request, err := BuilderSubmissionEntryToSubmitBlockRequest(entry, payloadEntry)
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionCapella, request.Version)
+	require.Equal(t, uint64(5552306), request.Capella.Message.Slot)
+	require.Equal(t, "12345", request.Capella.Message.Value.Dec())
+	require.Equal(t, entry.Signature, request.Capella.Signature.String())
+	require.Equal(t, blockHash, request.Capella.ExecutionPayload.BlockHash.String())
+}
+
+func TestPayloadToExecPayloadEntryElectra(t *testing.T) {
+	denebSubmission := new(common.VersionedSubmitBlockRequest)
+	common.LoadGzippedJSON(t, "../testdata/submitBlockPayloadDeneb_Goerli.json.gz", denebSubmission)
+	submission := common.ElectraSubmitBlockRequestFromDeneb(denebSubmission, common.TestExecutionRequests())
+
+	payloadEntry, err := PayloadToExecPayloadEntry(submission)
+	require.NoError(t, err)
+	require.Equal(t, common.ForkVersionStringElectra, payloadEntry.Version)
+
+	// The payload and blobs bundle are loaded as the getPayload response
+	payload, err := ExecutionPayloadEntryToExecutionPayload(payloadEntry)
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionElectra, payload.Version)
+	require.Equal(t, submission.Electra.ExecutionPayload.BlockHash, payload.Electra.ExecutionPayload.BlockHash)
+	require.Len(t, payload.Electra.BlobsBundle.Blobs, len(submission.Electra.BlobsBundle.Blobs))
+
+	// The submission is reconstructed with the execution requests
+	message := submission.Electra.Message
+	entry := &BuilderBlockSubmissionEntry{
+		Signature:            submission.Electra.Signature.String(),
+		Slot:                 message.Slot,
+		ParentHash:           message.ParentHash.String(),
+		BlockHash:            message.BlockHash.String(),
+		BuilderPubkey:        message.BuilderPubkey.String(),
+		ProposerPubkey:       message.ProposerPubkey.String(),
+		ProposerFeeRecipient: message.ProposerFeeRecipient.String(),
+		GasUsed:              message.GasUsed,
+		GasLimit:             message.GasLimit,
+		Value:                message.Value.Dec(),
+	}
+	request, err := BuilderSubmissionEntryToSubmitBlockRequest(entry, payloadEntry)
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionElectra, request.Version)
+	require.Equal(t, submission.Electra.ExecutionRequests, request.Electra.ExecutionRequests)
+	htr, err := submission.HashTreeRoot()
+	require.NoError(t, err)
+	requestHtr, err := request.HashTreeRoot()
+	require.NoError(t, err)
+	require.Equal(t, htr, requestHtr)
+}
@@ -6,13 +6,24 @@ import "github.com/flashbots/mev-boost-relay/common"
 var (
 	tableBase = common.GetEnv("DB_TABLE_PREFIX", "dev")
 
-	TableMigrations             = tableBase + "_migrations"
-	TableValidatorRegistration  = tableBase + "_validator_registration"
-	TableExecutionPayload       = tableBase + "_execution_payload"
-	TableBuilderBlockSubmission = tableBase + "_builder_block_submission"
-	TableDeliveredPayload       = tableBase + "_payload_delivered"
-	TableBlockBuilder           = tableBase + "_blockbuilder"
-	TableBuilderDemotions       = tableBase + "_builder_demotions"
-	TableBlockedValidator       = tableBase + "_blocked_validator"
-	TableTooLateGetPayload      = tableBase + "_too_late_get_payload"
+	TableMigrations                   = tableBase + "_migrations"
+	TableValidatorRegistration        = tableBase + "_validator_registration"
+	TableExecutionPayload             = tableBase + "_execution_payload"
+	TableBuilderBlockSubmission       = tableBase + "_builder_block_submission"
+	TableDeliveredPayload             = tableBase + "_payload_delivered"
+	TableBlockBuilder                 = tableBase + "_blockbuilder"
+	TableBuilderDemotions             = tableBase + "_builder_demotions"
+	TableBlockedValidator             = tableBase + "_blocked_validator"
+	TableTooLateGetPayload            = tableBase + "_too_late_get_payload"
+	TableGetPayloadEquivocation       = tableBase + "_getpayload_equivocation"
+	TableBidArchive                   = tableBase + "_bid_archive"
+	TablePaymentVerification          = tableBase + "_payment_verification"
+	TableProposerPreferences          = tableBase + "_proposer_preferences"
+	TableBackfillProgress             = tableBase + "_backfill_progress"
+	TableValidatorRegistrationHistory = tableBase + "_validator_registration_history"
+	TableSlotSummary                  = tableBase + "_slot_summary"
+	TableEpochSummary                 = tableBase + "_epoch_summary"
+	TableProposerRequest              = tableBase + "_proposer_request"
+	TableFeatureFlagChange            = tableBase + "_feature_flag_change"
+	TableBidPaymentDiscrepancy        = tableBase + "_bid_payment_discrepancy"
 )
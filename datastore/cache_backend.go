@@ -0,0 +1,155 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/go-redis/redis/v9"
+)
+
+var ErrCacheMiss = errors.New("not found in cache")
+
+// CacheBackend is a storage backend for the execution payloads and bid traces which getPayload and the data API are
+// served from, and for the validator registration timestamps. The datastore writes to all backends, and reads from
+// them in order before falling back to the database. Alternative backends can be added with AddCacheBackend.
+//
+// Redis is always the first backend, because the top bid of each slot is computed atomically in Redis and it holds the
+// state shared by all relay instances (proposer duties, builder status, ...).
+type CacheBackend interface {
+	Name() string
+	Ping() error
+
+	SaveExecutionPayload(slot uint64, proposerPubkey, blockHash string, payload *builderApi.VersionedSubmitBlindedBlockResponse) error
+	// GetExecutionPayload returns ErrCacheMiss if the payload is not found
+	GetExecutionPayload(slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error)
+
+	SaveBidTrace(trace *common.BidTraceV2WithBlobFields) error
+	// GetBidTrace returns ErrCacheMiss if the bid trace is not found
+	GetBidTrace(slot uint64, proposerPubkey, blockHash string) (*common.BidTraceV2WithBlobFields, error)
+
+	// GetValidatorRegistrationTimestamp returns 0 if the validator has no registration
+	GetValidatorRegistrationTimestamp(proposerPubkey common.PubkeyHex) (uint64, error)
+	SetValidatorRegistrationTimestampIfNewer(proposerPubkey common.PubkeyHex, timestamp uint64) error
+}
+
+// redisBackend adapts RedisCache to the CacheBackend interface
+type redisBackend struct {
+	r *RedisCache
+}
+
+func (b *redisBackend) Name() string {
+	return "redis"
+}
+
+func (b *redisBackend) Ping() error {
+	return b.r.Ping()
+}
+
+func (b *redisBackend) SaveExecutionPayload(slot uint64, proposerPubkey, blockHash string, payload *builderApi.VersionedSubmitBlindedBlockResponse) (err error) {
+	pipe := b.r.NewPipeline()
+	switch payload.Version {
+	case spec.DataVersionCapella:
+		err = b.r.SaveExecutionPayloadCapella(context.Background(), pipe, slot, proposerPubkey, blockHash, payload.Capella)
+	case spec.DataVersionDeneb:
+		err = b.r.SavePayloadContentsDeneb(context.Background(), pipe, slot, proposerPubkey, blockHash, payload.Deneb)
+	case spec.DataVersionElectra:
+		err = b.r.SavePayloadContentsElectra(context.Background(), pipe, slot, proposerPubkey, blockHash, payload.Electra)
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
+		return fmt.Errorf("unsupported payload version: %s", payload.Version) //nolint:goerr113
+	}
+	if err != nil {
+		return err
+	}
+	_, err = pipe.Exec(context.Background())
+	return err
+}
+
+func (b *redisBackend) GetExecutionPayload(slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error) {
+	resp, err := b.r.GetPayloadContents(slot, proposerPubkey, blockHash)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return resp, err
+}
+
+func (b *redisBackend) SaveBidTrace(trace *common.BidTraceV2WithBlobFields) error {
+	pipe := b.r.NewPipeline()
+	if err := b.r.SaveBidTrace(context.Background(), pipe, trace); err != nil {
+		return err
+	}
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+func (b *redisBackend) GetBidTrace(slot uint64, proposerPubkey, blockHash string) (*common.BidTraceV2WithBlobFields, error) {
+	trace, err := b.r.GetBidTrace(slot, proposerPubkey, blockHash)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return trace, err
+}
+
+func (b *redisBackend) GetValidatorRegistrationTimestamp(proposerPubkey common.PubkeyHex) (uint64, error) {
+	return b.r.GetValidatorRegistrationTimestamp(proposerPubkey)
+}
+
+func (b *redisBackend) SetValidatorRegistrationTimestampIfNewer(proposerPubkey common.PubkeyHex, timestamp uint64) error {
+	return b.r.SetValidatorRegistrationTimestampIfNewer(proposerPubkey, timestamp)
+}
+
+// memcachedBackend adapts Memcached to the CacheBackend interface
+type memcachedBackend struct {
+	m *Memcached
+}
+
+func (b *memcachedBackend) Name() string {
+	return "memcached"
+}
+
+func (b *memcachedBackend) Ping() error {
+	return b.m.Ping()
+}
+
+func (b *memcachedBackend) SaveExecutionPayload(slot uint64, proposerPubkey, blockHash string, payload *builderApi.VersionedSubmitBlindedBlockResponse) error {
+	return b.m.SaveExecutionPayload(slot, proposerPubkey, blockHash, payload)
+}
+
+func (b *memcachedBackend) GetExecutionPayload(slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error) {
+	resp, err := b.m.GetExecutionPayload(slot, proposerPubkey, blockHash)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, ErrCacheMiss
+	}
+	return resp, err
+}
+
+func (b *memcachedBackend) SaveBidTrace(trace *common.BidTraceV2WithBlobFields) error {
+	return b.m.SaveBidTrace(trace)
+}
+
+func (b *memcachedBackend) GetBidTrace(slot uint64, proposerPubkey, blockHash string) (*common.BidTraceV2WithBlobFields, error) {
+	trace, err := b.m.GetBidTrace(slot, proposerPubkey, blockHash)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, ErrCacheMiss
+	}
+	return trace, err
+}
+
+func (b *memcachedBackend) GetValidatorRegistrationTimestamp(proposerPubkey common.PubkeyHex) (uint64, error) {
+	return b.m.GetValidatorRegistrationTimestamp(proposerPubkey)
+}
+
+func (b *memcachedBackend) SetValidatorRegistrationTimestampIfNewer(proposerPubkey common.PubkeyHex, timestamp uint64) error {
+	knownTimestamp, err := b.m.GetValidatorRegistrationTimestamp(proposerPubkey)
+	if err != nil {
+		return err
+	}
+	if knownTimestamp >= timestamp {
+		return nil
+	}
+	return b.m.SetValidatorRegistrationTimestamp(proposerPubkey, timestamp)
+}
@@ -3,6 +3,7 @@ package datastore
 
 import (
 	"database/sql"
+	"math/big"
 	"strconv"
 	"strings"
 	"sync"
@@ -10,17 +11,23 @@ import (
 
 	builderApi "github.com/attestantio/go-builder-client/api"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
-	"github.com/bradfitz/gomemcache/memcache"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/mev-boost-relay/beaconclient"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/flashbots/mev-boost-relay/database"
-	"github.com/go-redis/redis/v9"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	uberatomic "go.uber.org/atomic"
 )
 
-var ErrExecutionPayloadNotFound = errors.New("execution payload not found")
+var (
+	ErrExecutionPayloadNotFound = errors.New("execution payload not found")
+	ErrBidTraceNotFound         = errors.New("bid trace not found")
+
+	// Between full refreshes, only the pending validators of the finalized state are added (0 to always do a full refresh)
+	knownValidatorsFullRefreshEpochs = uint64(cli.GetEnvInt("KNOWN_VALIDATORS_FULL_REFRESH_EPOCHS", 0))
+)
 
 type GetHeaderResponseKey struct {
 	Slot           uint64
@@ -34,34 +41,141 @@ type GetPayloadResponseKey struct {
 	BlockHash      string
 }
 
+// TierStats counts which storage tier served a lookup
+type TierStats struct {
+	Redis          uberatomic.Uint64
+	RedisSecondary uberatomic.Uint64 // secondary Redis instances, i.e. in other regions
+	Memcached      uberatomic.Uint64
+	Other          uberatomic.Uint64 // other cache backends
+	Database       uberatomic.Uint64
+	Miss           uberatomic.Uint64
+}
+
+// incBackend counts a lookup served by the cache backend
+func (s *TierStats) incBackend(backend CacheBackend) {
+	if _, isSecondary := backend.(*redisSecondaryBackend); isSecondary {
+		s.RedisSecondary.Inc()
+		return
+	}
+	switch backend.Name() {
+	case "redis":
+		s.Redis.Inc()
+	case "memcached":
+		s.Memcached.Inc()
+	default:
+		s.Other.Inc()
+	}
+}
+
+// LogFields returns the current counters, for adding to log entries
+func (s *TierStats) LogFields() logrus.Fields {
+	return logrus.Fields{
+		"tierHitsRedis":          s.Redis.Load(),
+		"tierHitsRedisSecondary": s.RedisSecondary.Load(),
+		"tierHitsMemcached":      s.Memcached.Load(),
+		"tierHitsOther":          s.Other.Load(),
+		"tierHitsDatabase":       s.Database.Load(),
+		"tierMisses":             s.Miss.Load(),
+	}
+}
+
 // Datastore provides a local memory cache with a Redis and DB backend
 type Datastore struct {
-	redis     *RedisCache
-	memcached *Memcached
-	db        database.IDatabaseService
+	redis    *RedisCache
+	backends []CacheBackend // redis first, then secondary backends like memcached
+	db       database.IDatabaseService
 
 	knownValidatorsByPubkey   map[common.PubkeyHex]uint64
 	knownValidatorsByIndex    map[uint64]common.PubkeyHex
 	knownValidatorsLock       sync.RWMutex
 	knownValidatorsIsUpdating uberatomic.Bool
 	knownValidatorsLastSlot   uberatomic.Uint64
+	knownValidatorsFullSlot   uberatomic.Uint64 // slot of the last full refresh
 
 	// Used for proposer-API readiness check
 	KnownValidatorsWasUpdated uberatomic.Bool
+
+	// Which storage tier served getPayload responses and bid traces
+	GetPayloadResponseStats TierStats
+	BidTraceStats           TierStats
+
+	// In-memory caches for getHeader
+	localBidCache         *ttlCache[GetHeaderResponseKey, *builderSpec.VersionedSignedBuilderBid]
+	localMinBidCache      *ttlCache[string, *big.Int]
+	localPreferencesCache *ttlCache[string, *common.BuilderPreferences]
+	localConstraintsCache *ttlCache[uint64, *common.SignedInclusionConstraints]
 }
 
 func NewDatastore(redisCache *RedisCache, memcached *Memcached, db database.IDatabaseService) (ds *Datastore, err error) {
 	ds = &Datastore{
 		db:                      db,
-		memcached:               memcached,
 		redis:                   redisCache,
 		knownValidatorsByPubkey: make(map[common.PubkeyHex]uint64),
 		knownValidatorsByIndex:  make(map[uint64]common.PubkeyHex),
+
+		localBidCache:         newTTLCache[GetHeaderResponseKey, *builderSpec.VersionedSignedBuilderBid](localCacheSize, localBidCacheTTL),
+		localMinBidCache:      newTTLCache[string, *big.Int](localCacheSize, localProposerCacheTTL),
+		localPreferencesCache: newTTLCache[string, *common.BuilderPreferences](localCacheSize, localProposerCacheTTL),
+		localConstraintsCache: newTTLCache[uint64, *common.SignedInclusionConstraints](localCacheSize, localConstraintsCacheTTL),
 	}
 
+	if redisCache != nil {
+		ds.backends = append(ds.backends, &redisBackend{redisCache})
+	}
+	if memcached != nil {
+		ds.backends = append(ds.backends, &memcachedBackend{memcached})
+	}
 	return ds, err
 }
 
+// AddCacheBackend adds a secondary cache backend, which is written to and read from after the existing ones
+func (ds *Datastore) AddCacheBackend(backend CacheBackend) {
+	ds.backends = append(ds.backends, backend)
+}
+
+// CacheBackends returns all cache backends, in the order they are read from
+func (ds *Datastore) CacheBackends() []CacheBackend {
+	return ds.backends
+}
+
+// redisBackends returns the primary Redis and the secondary Redis instances
+func (ds *Datastore) redisBackends() (backends []CacheBackend) {
+	for _, backend := range ds.backends {
+		if isRedisBackend(backend) {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
+
+// nonRedisBackends returns the cache backends other than the primary Redis and the secondary Redis instances
+func (ds *Datastore) nonRedisBackends() (backends []CacheBackend) {
+	for _, backend := range ds.backends {
+		if !isRedisBackend(backend) {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
+
+// SaveToSecondaryBackends saves the execution payload and bid trace of a bid in all cache backends except Redis, where
+// they are saved together with the top bid update
+func (ds *Datastore) SaveToSecondaryBackends(log *logrus.Entry, payload *builderApi.VersionedSubmitBlindedBlockResponse, trace *common.BidTraceV2WithBlobFields) {
+	for _, backend := range ds.backends {
+		if _, isRedis := backend.(*redisBackend); isRedis {
+			continue
+		}
+		err := backend.SaveExecutionPayload(trace.Slot, trace.ProposerPubkey.String(), trace.BlockHash.String(), payload)
+		if err != nil {
+			log.WithError(err).Errorf("failed saving execution payload in %s", backend.Name())
+		}
+		err = backend.SaveBidTrace(trace)
+		if err != nil {
+			log.WithError(err).Errorf("failed saving bid trace in %s", backend.Name())
+		}
+	}
+}
+
 // RefreshKnownValidators loads known validators from CL client into memory
 //
 // For the CL client this is an expensive operation and takes a bunch of resources.
@@ -107,9 +221,21 @@ func (ds *Datastore) RefreshKnownValidators(log *logrus.Entry, beaconClient beac
 		time.Sleep(6 * time.Second)
 	}
 
+	// Between full refreshes, only add the pending validators of the finalized state (new validators can only become
+	// active after their deposit is finalized, so they show up as pending there well before they can propose)
+	lastFullSlot := ds.knownValidatorsFullSlot.Load()
+	isIncremental := knownValidatorsFullRefreshEpochs > 0 && lastFullSlot > 0 && slot-lastFullSlot < knownValidatorsFullRefreshEpochs*common.SlotsPerEpoch
+	log = log.WithField("isIncremental", isIncremental)
+
 	log.Info("Querying validators from beacon node... (this may take a while)")
 	timeStartFetching := time.Now()
-	validators, err := beaconClient.GetStateValidators(beaconclient.StateIDHead) // head is fastest
+	var validators *beaconclient.GetStateValidatorsResponse
+	var err error
+	if isIncremental {
+		validators, err = beaconClient.GetPendingStateValidators(beaconclient.StateIDFinalized)
+	} else {
+		validators, err = beaconClient.GetStateValidators(beaconclient.StateIDHead) // head is fastest
+	}
 	if err != nil {
 		log.WithError(err).Error("failed to fetch validators from all beacon nodes")
 		return
@@ -117,35 +243,81 @@ func (ds *Datastore) RefreshKnownValidators(log *logrus.Entry, beaconClient beac
 
 	numValidators := len(validators.Data)
 	log = log.WithFields(logrus.Fields{
-		"numKnownValidators":        numValidators,
+		"numReceivedValidators":     numValidators,
 		"durationFetchValidatorsMs": time.Since(timeStartFetching).Milliseconds(),
 	})
 	log.Infof("received known validators from beacon-node")
 
-	err = ds.redis.SetStats(RedisStatsFieldValidatorsTotal, strconv.Itoa(numValidators))
+	// At this point, consider the update successful
+	ds.knownValidatorsLastSlot.Store(slot)
+	if !isIncremental {
+		ds.knownValidatorsFullSlot.Store(slot)
+	}
+
+	newValidatorsByIndex := make(map[uint64]common.PubkeyHex, numValidators)
+	for _, valEntry := range validators.Data {
+		newValidatorsByIndex[valEntry.Index] = common.NewPubkeyHex(valEntry.Validator.Pubkey)
+	}
+
+	ds.knownValidatorsLock.Lock()
+	if !isIncremental {
+		ds.knownValidatorsByPubkey = make(map[common.PubkeyHex]uint64, numValidators)
+		ds.knownValidatorsByIndex = make(map[uint64]common.PubkeyHex, numValidators)
+	}
+	for index, pk := range newValidatorsByIndex {
+		ds.knownValidatorsByPubkey[pk] = index
+		ds.knownValidatorsByIndex[index] = pk
+	}
+	numKnownValidators := len(ds.knownValidatorsByIndex)
+	ds.knownValidatorsLock.Unlock()
+
+	ds.KnownValidatorsWasUpdated.Store(true)
+	log.WithField("numKnownValidators", numKnownValidators).Infof("known validators updated")
+
+	err = ds.redis.SetStats(RedisStatsFieldValidatorsTotal, strconv.Itoa(numKnownValidators))
 	if err != nil {
 		log.WithError(err).Error("failed to set stats for RedisStatsFieldValidatorsTotal")
 	}
 
-	// At this point, consider the update successful
-	ds.knownValidatorsLastSlot.Store(slot)
+	// Persist in Redis, so other instances can start serving before their first refresh
+	err = ds.redis.SetKnownValidators(newValidatorsByIndex, slot, !isIncremental)
+	if err != nil {
+		log.WithError(err).Error("failed to save known validators in Redis")
+	}
+}
 
-	knownValidatorsByPubkey := make(map[common.PubkeyHex]uint64)
-	knownValidatorsByIndex := make(map[uint64]common.PubkeyHex)
+// LoadKnownValidatorsFromRedis loads the known validators that were persisted by a previous refresh (by this or another
+// instance), so the proposer API can start serving before the first (slow) refresh from the beacon node is done.
+func (ds *Datastore) LoadKnownValidatorsFromRedis(log *logrus.Entry) error {
+	validators, slot, err := ds.redis.GetKnownValidators()
+	if err != nil {
+		return err
+	}
+	if len(validators) == 0 {
+		return nil
+	}
 
-	for _, valEntry := range validators.Data {
-		pk := common.NewPubkeyHex(valEntry.Validator.Pubkey)
-		knownValidatorsByPubkey[pk] = valEntry.Index
-		knownValidatorsByIndex[valEntry.Index] = pk
+	knownValidatorsByPubkey := make(map[common.PubkeyHex]uint64, len(validators))
+	for index, pk := range validators {
+		knownValidatorsByPubkey[pk] = index
 	}
 
 	ds.knownValidatorsLock.Lock()
+	if len(ds.knownValidatorsByIndex) > 0 {
+		// Already refreshed from the beacon node
+		ds.knownValidatorsLock.Unlock()
+		return nil
+	}
 	ds.knownValidatorsByPubkey = knownValidatorsByPubkey
-	ds.knownValidatorsByIndex = knownValidatorsByIndex
+	ds.knownValidatorsByIndex = validators
 	ds.knownValidatorsLock.Unlock()
 
 	ds.KnownValidatorsWasUpdated.Store(true)
-	log.Infof("known validators updated")
+	log.WithFields(logrus.Fields{
+		"numKnownValidators":  len(validators),
+		"knownValidatorsSlot": slot,
+	}).Info("known validators loaded from Redis")
+	return nil
 }
 
 func (ds *Datastore) IsKnownValidator(pubkeyHex common.PubkeyHex) bool {
@@ -155,6 +327,15 @@ func (ds *Datastore) IsKnownValidator(pubkeyHex common.PubkeyHex) bool {
 	return found
 }
 
+// AddKnownValidator adds a single validator to the known validators, i.e. after looking it up on the beacon node
+// between two refreshes
+func (ds *Datastore) AddKnownValidator(pubkeyHex common.PubkeyHex, index uint64) {
+	ds.knownValidatorsLock.Lock()
+	defer ds.knownValidatorsLock.Unlock()
+	ds.knownValidatorsByPubkey[pubkeyHex] = index
+	ds.knownValidatorsByIndex[index] = pubkeyHex
+}
+
 func (ds *Datastore) GetKnownValidatorPubkeyByIndex(index uint64) (common.PubkeyHex, bool) {
 	ds.knownValidatorsLock.RLock()
 	defer ds.knownValidatorsLock.RUnlock()
@@ -172,19 +353,24 @@ func (ds *Datastore) NumRegisteredValidators() (uint64, error) {
 	return ds.db.NumRegisteredValidators()
 }
 
-// SaveValidatorRegistration saves a validator registration into both Redis and the database
-func (ds *Datastore) SaveValidatorRegistration(entry builderApiV1.SignedValidatorRegistration) error {
+// SaveValidatorRegistration saves a validator registration into the database (recording a fee recipient change with the
+// source IP), and its timestamp into the cache backends
+func (ds *Datastore) SaveValidatorRegistration(entry builderApiV1.SignedValidatorRegistration, sourceIP string) error {
 	// First save in the database
-	err := ds.db.SaveValidatorRegistration(database.SignedValidatorRegistrationToEntry(entry))
+	dbEntry := database.SignedValidatorRegistrationToEntry(entry)
+	dbEntry.SourceIP = sourceIP
+	err := ds.db.SaveValidatorRegistration(dbEntry)
 	if err != nil {
 		return errors.Wrap(err, "failed saving validator registration to database")
 	}
 
-	// then save in redis
+	// then save in the cache backends
 	pk := common.NewPubkeyHex(entry.Message.Pubkey.String())
-	err = ds.redis.SetValidatorRegistrationTimestampIfNewer(pk, uint64(entry.Message.Timestamp.Unix()))
-	if err != nil {
-		return errors.Wrap(err, "failed saving validator registration to redis")
+	for _, backend := range ds.backends {
+		err = backend.SetValidatorRegistrationTimestampIfNewer(pk, uint64(entry.Message.Timestamp.Unix()))
+		if err != nil {
+			return errors.Wrapf(err, "failed saving validator registration to %s", backend.Name())
+		}
 	}
 
 	return nil
@@ -196,34 +382,37 @@ func (ds *Datastore) GetGetPayloadResponse(log *logrus.Entry, slot uint64, propo
 	_proposerPubkey := strings.ToLower(proposerPubkey)
 	_blockHash := strings.ToLower(blockHash)
 
-	// 1. try to get from Redis
-	resp, err := ds.redis.GetPayloadContents(slot, _proposerPubkey, _blockHash)
-	if errors.Is(err, redis.Nil) {
-		log.WithError(err).Warn("execution payload not found in redis")
-	} else if err != nil {
-		log.WithError(err).Error("error getting execution payload from redis")
-	} else {
-		log.Debug("getPayload response from redis")
-		return resp, nil
+	// 1. try to get from the cache backends (Redis, then secondary backends like Memcached). If there are secondary
+	// Redis instances, the primary and secondary Redis are read at once, and the first response is used.
+	backends := ds.backends
+	if redisBackends := ds.redisBackends(); len(redisBackends) > 1 {
+		resp, backend := getExecutionPayloadFirst(log, redisBackends, slot, _proposerPubkey, _blockHash)
+		if resp != nil {
+			ds.GetPayloadResponseStats.incBackend(backend)
+			log.WithFields(ds.GetPayloadResponseStats.LogFields()).Debugf("getPayload response from %s", backend.Name())
+			return resp, nil
+		}
+		backends = ds.nonRedisBackends()
 	}
-
-	// 2. try to get from Memcached
-	if ds.memcached != nil {
-		resp, err = ds.memcached.GetExecutionPayload(slot, _proposerPubkey, _blockHash)
-		if errors.Is(err, memcache.ErrCacheMiss) {
-			log.WithError(err).Warn("execution payload not found in memcached")
-		} else if err != nil {
-			log.WithError(err).Error("error getting execution payload from memcached")
-		} else if resp != nil {
-			log.Debug("getPayload response from memcached")
+	for _, backend := range backends {
+		resp, err := backend.GetExecutionPayload(slot, _proposerPubkey, _blockHash)
+		if err == nil && resp != nil {
+			ds.GetPayloadResponseStats.incBackend(backend)
+			if isRedisBackend(backend) {
+				log.WithFields(ds.GetPayloadResponseStats.LogFields()).Debugf("getPayload response from %s", backend.Name())
+			} else {
+				log.WithFields(ds.GetPayloadResponseStats.LogFields()).Infof("getPayload response from %s", backend.Name())
+			}
 			return resp, nil
 		}
+		logExecutionPayloadMiss(log, backend, err)
 	}
 
-	// 3. try to get from database (should not happen, it's just a backup)
+	// 2. try to get from database (should not happen, it's just a backup)
 	executionPayloadEntry, err := ds.db.GetExecutionPayloadEntryBySlotPkHash(slot, proposerPubkey, blockHash)
 	if errors.Is(err, sql.ErrNoRows) {
-		log.WithError(err).Warn("execution payload not found in database")
+		ds.GetPayloadResponseStats.Miss.Inc()
+		log.WithError(err).WithFields(ds.GetPayloadResponseStats.LogFields()).Warn("execution payload not found in database")
 		return nil, ErrExecutionPayloadNotFound
 	} else if err != nil {
 		log.WithError(err).Error("error getting execution payload from database")
@@ -231,6 +420,87 @@ func (ds *Datastore) GetGetPayloadResponse(log *logrus.Entry, slot uint64, propo
 	}
 
 	// Got it from database, now deserialize execution payload and compile full response
-	log.Warn("getPayload response from database, primary storage failed")
+	ds.GetPayloadResponseStats.Database.Inc()
+	log.WithFields(ds.GetPayloadResponseStats.LogFields()).Warn("getPayload response from database, primary storage failed")
 	return database.ExecutionPayloadEntryToExecutionPayload(executionPayloadEntry)
 }
+
+// GetBidTrace returns the bid trace for a delivered payload, trying Redis first and falling back to the secondary cache
+// backends like Memcached
+func (ds *Datastore) GetBidTrace(log *logrus.Entry, slot uint64, proposerPubkey, blockHash string) (*common.BidTraceV2WithBlobFields, error) {
+	log = log.WithField("datastoreMethod", "GetBidTrace")
+	_proposerPubkey := strings.ToLower(proposerPubkey)
+	_blockHash := strings.ToLower(blockHash)
+
+	for i, backend := range ds.backends {
+		trace, err := backend.GetBidTrace(slot, _proposerPubkey, _blockHash)
+		if errors.Is(err, ErrCacheMiss) {
+			log.WithError(err).Warnf("bid trace not found in %s", backend.Name())
+		} else if err != nil {
+			log.WithError(err).Errorf("error getting bid trace from %s", backend.Name())
+		} else {
+			ds.BidTraceStats.incBackend(backend)
+			if i > 0 {
+				log.WithFields(ds.BidTraceStats.LogFields()).Infof("bid trace from %s", backend.Name())
+			}
+			return trace, nil
+		}
+	}
+
+	ds.BidTraceStats.Miss.Inc()
+	return nil, ErrBidTraceNotFound
+}
+
+// BuildProposerDuties returns the proposer duties of the epoch and the next epoch which have a validator registration in
+// the database, as saved to Redis for the builder API. The next epoch is skipped if its duties are not available yet.
+func BuildProposerDuties(log *logrus.Entry, beaconClient beaconclient.IMultiBeaconClient, db database.IDatabaseService, epoch uint64) ([]common.BuilderGetValidatorsResponseEntry, error) {
+	// Query current epoch
+	r, err := beaconClient.GetProposerDuties(epoch)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get proposer duties for all beacon nodes")
+	}
+	entries := r.Data
+
+	// Query next epoch
+	r2, err := beaconClient.GetProposerDuties(epoch + 1)
+	if err != nil {
+		log.WithError(err).Error("failed to get proposer duties for next epoch for all beacon nodes")
+	} else if r2 != nil {
+		entries = append(entries, r2.Data...)
+	}
+
+	// Get registrations from database
+	pubkeys := []string{}
+	for _, entry := range entries {
+		pubkeys = append(pubkeys, entry.Pubkey)
+	}
+	validatorRegistrationEntries, err := db.GetValidatorRegistrationsForPubkeys(pubkeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get validator registrations")
+	}
+
+	// Convert db entries to signed validator registration type
+	signedValidatorRegistrations := make(map[string]*builderApiV1.SignedValidatorRegistration)
+	for _, regEntry := range validatorRegistrationEntries {
+		signedEntry, err := regEntry.ToSignedValidatorRegistration()
+		if err != nil {
+			log.WithError(err).Error("failed to convert validator registration entry to signed validator registration")
+			continue
+		}
+		signedValidatorRegistrations[regEntry.Pubkey] = signedEntry
+	}
+
+	// Prepare proposer duties
+	proposerDuties := []common.BuilderGetValidatorsResponseEntry{}
+	for _, duty := range entries {
+		reg := signedValidatorRegistrations[duty.Pubkey]
+		if reg != nil {
+			proposerDuties = append(proposerDuties, common.BuilderGetValidatorsResponseEntry{
+				Slot:           duty.Slot,
+				ValidatorIndex: duty.ValidatorIndex,
+				Entry:          reg,
+			})
+		}
+	}
+	return proposerDuties, nil
+}
@@ -1,11 +1,17 @@
 package datastore
 
 import (
+	"context"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
 )
 
@@ -73,3 +79,154 @@ func TestGetPayloadDatabaseFallback(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBidTrace(t *testing.T) {
+	ds := setupTestDatastore(t, &database.MockDB{})
+
+	// not found anywhere
+	_, err := ds.GetBidTrace(common.TestLog, 1, "a", "b")
+	require.ErrorIs(t, err, ErrBidTraceNotFound)
+	require.Equal(t, uint64(1), ds.BidTraceStats.Miss.Load())
+
+	// found in redis
+	trace := &common.BidTraceV2WithBlobFields{
+		BidTrace: builderApiV1.BidTrace{
+			Slot:  1,
+			Value: uint256.NewInt(123),
+		},
+	}
+	pipeliner := ds.redis.NewPipeline()
+	err = ds.redis.SaveBidTrace(context.Background(), pipeliner, trace)
+	require.NoError(t, err)
+	_, err = pipeliner.Exec(context.Background())
+	require.NoError(t, err)
+
+	resp, err := ds.GetBidTrace(common.TestLog, 1, trace.ProposerPubkey.String(), trace.BlockHash.String())
+	require.NoError(t, err)
+	require.Equal(t, trace.Value.String(), resp.Value.String())
+	require.Equal(t, uint64(1), ds.BidTraceStats.Redis.Load())
+}
+
+func TestLocalProposerCache(t *testing.T) {
+	ds := setupTestDatastore(t, &database.MockDB{})
+	pubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+
+	require.NoError(t, ds.redis.SetProposerMinBid(pubkey, big.NewInt(100)))
+	minBid, err := ds.GetProposerMinBid(pubkey)
+	require.NoError(t, err)
+	require.Equal(t, "100", minBid.String())
+
+	// Served from memory until invalidated
+	require.NoError(t, ds.redis.SetProposerMinBid(pubkey, big.NewInt(200)))
+	minBid, err = ds.GetProposerMinBid(pubkey)
+	require.NoError(t, err)
+	require.Equal(t, "100", minBid.String())
+
+	ds.InvalidateProposerCache(pubkey)
+	minBid, err = ds.GetProposerMinBid(pubkey)
+	require.NoError(t, err)
+	require.Equal(t, "200", minBid.String())
+}
+
+func TestTTLCache(t *testing.T) {
+	cache := newTTLCache[string, int](2, 50*time.Millisecond)
+	cache.Add("a", 1)
+	value, ok := cache.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+
+	// Least recently used entry is evicted
+	cache.Add("b", 2)
+	cache.Add("c", 3)
+	_, ok = cache.Get("a")
+	require.False(t, ok)
+
+	// Entries expire
+	time.Sleep(60 * time.Millisecond)
+	_, ok = cache.Get("c")
+	require.False(t, ok)
+
+	// Disabled cache
+	disabled := newTTLCache[string, int](2, 0)
+	disabled.Add("a", 1)
+	_, ok = disabled.Get("a")
+	require.False(t, ok)
+}
+
+// testCacheBackend is an in-memory cache backend for bid traces
+type testCacheBackend struct {
+	memcachedBackend
+	traces map[string]*common.BidTraceV2WithBlobFields
+}
+
+func (b *testCacheBackend) Name() string {
+	return "test"
+}
+
+func (b *testCacheBackend) SaveBidTrace(trace *common.BidTraceV2WithBlobFields) error {
+	b.traces[trace.BlockHash.String()] = trace
+	return nil
+}
+
+func (b *testCacheBackend) GetBidTrace(slot uint64, proposerPubkey, blockHash string) (*common.BidTraceV2WithBlobFields, error) {
+	trace, ok := b.traces[blockHash]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return trace, nil
+}
+
+func TestSecondaryCacheBackend(t *testing.T) {
+	ds := setupTestDatastore(t, &database.MockDB{})
+	backend := &testCacheBackend{traces: make(map[string]*common.BidTraceV2WithBlobFields)}
+	ds.AddCacheBackend(backend)
+	require.Len(t, ds.CacheBackends(), 2)
+
+	trace := &common.BidTraceV2WithBlobFields{
+		BidTrace: builderApiV1.BidTrace{
+			Slot:  1,
+			Value: uint256.NewInt(123),
+		},
+	}
+	backend.traces[trace.BlockHash.String()] = trace
+
+	// Not in Redis, served by the secondary backend
+	resp, err := ds.GetBidTrace(common.TestLog, 1, trace.ProposerPubkey.String(), trace.BlockHash.String())
+	require.NoError(t, err)
+	require.Equal(t, trace.Value.String(), resp.Value.String())
+	require.Equal(t, uint64(0), ds.BidTraceStats.Redis.Load())
+	require.Equal(t, uint64(1), ds.BidTraceStats.Other.Load())
+}
+
+func TestRedisSecondaryBackend(t *testing.T) {
+	ds := setupTestDatastore(t, &database.MockDB{})
+	secondaryServer, err := miniredis.Run()
+	require.NoError(t, err)
+	secondaryRedis, err := NewRedisCache("", secondaryServer.Addr(), "")
+	require.NoError(t, err)
+	ds.AddCacheBackend(NewRedisSecondaryBackend("redis-secondary-1", secondaryRedis))
+
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, Version: spec.DataVersionDeneb}
+	payload, getPayloadResp, _ := common.CreateTestBlockSubmission(t, "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c", uint256.NewInt(10), &opts)
+	trace := &common.BidTraceV2WithBlobFields{BidTrace: *payload.Deneb.Message}
+	proposerPubkey, blockHash := trace.ProposerPubkey.String(), trace.BlockHash.String()
+
+	// Only saved in the secondary Redis, i.e. the write to the primary Redis failed
+	ds.SaveToSecondaryBackends(common.TestLog, getPayloadResp, trace)
+	resp, err := ds.GetGetPayloadResponse(common.TestLog, trace.Slot, proposerPubkey, blockHash)
+	require.NoError(t, err)
+	require.Equal(t, getPayloadResp.Deneb.ExecutionPayload.BlockHash, resp.Deneb.ExecutionPayload.BlockHash)
+	require.Equal(t, uint64(1), ds.GetPayloadResponseStats.RedisSecondary.Load())
+
+	// Validator registrations are only kept in the primary Redis
+	timestamp, err := ds.CacheBackends()[1].GetValidatorRegistrationTimestamp(common.NewPubkeyHex(proposerPubkey))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), timestamp)
+
+	// Served by the primary Redis while the secondary Redis is down
+	secondaryServer.Close()
+	require.NoError(t, ds.CacheBackends()[0].SaveExecutionPayload(trace.Slot, proposerPubkey, blockHash, getPayloadResp))
+	_, err = ds.GetGetPayloadResponse(common.TestLog, trace.Slot, proposerPubkey, blockHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), ds.GetPayloadResponseStats.Redis.Load())
+}
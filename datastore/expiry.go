@@ -0,0 +1,55 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+var (
+	ErrInvalidExpiry = errors.New("invalid expiry")
+
+	// expiry of the execution payloads and bid traces of submissions, in Redis and memcached. Defaults to the legacy
+	// MEMCACHED_EXPIRY_SECONDS if that is set.
+	expiryPayload = time.Duration(cli.GetEnvInt("EXPIRY_PAYLOAD_SECONDS", cli.GetEnvInt("MEMCACHED_EXPIRY_SECONDS", 45))) * time.Second
+
+	// expiry of the bids of a slot (builder bids, top bid and floor bid)
+	expiryBid = time.Duration(cli.GetEnvInt("EXPIRY_BID_SECONDS", 45)) * time.Second
+
+	// expiry of the validator registration timestamps (0 for no expiry). In Redis, the timestamps of all validators are
+	// stored in one hash, which expires as a whole.
+	expiryRegistration = time.Duration(cli.GetEnvInt("EXPIRY_REGISTRATION_SECONDS", 0)) * time.Second
+
+	// expiry of the proposer duties (0 for no expiry)
+	expiryProposerDuties = time.Duration(cli.GetEnvInt("EXPIRY_PROPOSER_DUTIES_SECONDS", 0)) * time.Second
+)
+
+// checkExpiries makes sure the configured expiries are long enough for the relay to work: payloads must be available
+// for getPayload and bids for getHeader during their slot and the next one, and the proposer duties are computed for
+// the current and the next epoch.
+func checkExpiries() error {
+	minExpiryPerSlot := 2 * common.DurationPerSlot
+	if expiryPayload < minExpiryPerSlot {
+		return fmt.Errorf("%w: payload expiry %s is shorter than 2 slots (%s)", ErrInvalidExpiry, expiryPayload, minExpiryPerSlot)
+	}
+	if expiryBid < minExpiryPerSlot {
+		return fmt.Errorf("%w: bid expiry %s is shorter than 2 slots (%s)", ErrInvalidExpiry, expiryBid, minExpiryPerSlot)
+	}
+
+	minExpiryProposerDuties := 2 * common.DurationPerEpoch
+	if expiryProposerDuties != 0 && expiryProposerDuties < minExpiryProposerDuties {
+		return fmt.Errorf("%w: proposer duties expiry %s is shorter than 2 epochs (%s)", ErrInvalidExpiry, expiryProposerDuties, minExpiryProposerDuties)
+	}
+	if expiryRegistration < 0 {
+		return fmt.Errorf("%w: registration expiry %s is negative", ErrInvalidExpiry, expiryRegistration)
+	}
+	return nil
+}
+
+// memcachedExpiry converts an expiry to the memcached format (seconds, 0 for no expiry)
+func memcachedExpiry(expiry time.Duration) int32 {
+	return int32(expiry / time.Second)
+}
@@ -0,0 +1,108 @@
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+)
+
+var (
+	// LeaseHousekeeper is held by the housekeeper instance which performs the proposer duty updates and other
+	// periodic tasks, when several housekeepers share the Redis
+	LeaseHousekeeper = "housekeeper"
+
+	expiryBlockPublication = 2 * time.Minute
+
+	// sets the lease to the instance if it's free, or extends it if the instance already holds it
+	acquireLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0`)
+)
+
+// InstanceHeartbeat is the last sign of life of a relay instance sharing this Redis
+type InstanceHeartbeat struct {
+	InstanceID string    `json:"instance_id"`
+	Service    string    `json:"service"`
+	Version    string    `json:"version"`
+	StartedAt  time.Time `json:"started_at"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// SetInstanceHeartbeat records that the instance is alive
+func (r *RedisCache) SetInstanceHeartbeat(heartbeat InstanceHeartbeat) error {
+	b, err := json.Marshal(heartbeat)
+	if err != nil {
+		return err
+	}
+	field := heartbeat.Service + "/" + heartbeat.InstanceID
+	return r.client.HSet(context.Background(), r.keyInstances, field, b).Err()
+}
+
+// GetInstanceHeartbeats returns the heartbeats of all instances, and deletes those not seen since before staleBefore
+func (r *RedisCache) GetInstanceHeartbeats(staleBefore time.Time) ([]InstanceHeartbeat, error) {
+	res, err := r.client.HGetAll(context.Background(), r.keyInstances).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	heartbeats := make([]InstanceHeartbeat, 0, len(res))
+	for field, value := range res {
+		heartbeat := InstanceHeartbeat{}
+		err = json.Unmarshal([]byte(value), &heartbeat)
+		if err != nil || heartbeat.LastSeen.Before(staleBefore) {
+			if err := r.client.HDel(context.Background(), r.keyInstances, field).Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		heartbeats = append(heartbeats, heartbeat)
+	}
+	return heartbeats, nil
+}
+
+// AcquireLease makes the instance the holder of the named lease for the given duration, if no other instance holds it.
+// Holders extend their lease by calling it again before it expires. Returns whether the instance holds the lease.
+func (r *RedisCache) AcquireLease(name, instanceID string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s:%s", r.prefixLease, name)
+	res, err := acquireLeaseScript.Run(context.Background(), r.client, []string{key}, instanceID, ttl.Milliseconds()).Int()
+	return res == 1, err
+}
+
+// GetLeaseHolder returns the instance holding the named lease, or an empty string if it's free
+func (r *RedisCache) GetLeaseHolder(name string) (string, error) {
+	key := fmt.Sprintf("%s:%s", r.prefixLease, name)
+	res, err := r.client.Get(context.Background(), key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return res, err
+}
+
+// ClaimBlockPublication claims the publication of the block of a slot for the instance, and returns the instance
+// which holds the claim (this one, or another instance which claimed it before)
+func (r *RedisCache) ClaimBlockPublication(slot uint64, blockHash, instanceID string) (string, error) {
+	key := fmt.Sprintf("%s:%d_%s", r.prefixBlockPublication, slot, blockHash)
+	claimed, err := r.client.SetNX(context.Background(), key, instanceID, expiryBlockPublication).Result()
+	if err != nil {
+		return "", err
+	} else if claimed {
+		return instanceID, nil
+	}
+	return r.client.Get(context.Background(), key).Result()
+}
+
+// ReleaseBlockPublication releases the claim, so that another instance can publish the block after a failure
+func (r *RedisCache) ReleaseBlockPublication(slot uint64, blockHash string) error {
+	key := fmt.Sprintf("%s:%d_%s", r.prefixBlockPublication, slot, blockHash)
+	return r.client.Del(context.Background(), key).Err()
+}
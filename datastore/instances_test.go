@@ -0,0 +1,63 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLease(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	isLeader, err := cache.AcquireLease(LeaseHousekeeper, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, isLeader)
+
+	// renewed by the holder, not taken by another instance
+	isLeader, err = cache.AcquireLease(LeaseHousekeeper, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, isLeader)
+	isLeader, err = cache.AcquireLease(LeaseHousekeeper, "b", time.Minute)
+	require.NoError(t, err)
+	require.False(t, isLeader)
+
+	holder, err := cache.GetLeaseHolder(LeaseHousekeeper)
+	require.NoError(t, err)
+	require.Equal(t, "a", holder)
+}
+
+func TestClaimBlockPublication(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	publisher, err := cache.ClaimBlockPublication(1, "0x01", "a")
+	require.NoError(t, err)
+	require.Equal(t, "a", publisher)
+	publisher, err = cache.ClaimBlockPublication(1, "0x01", "b")
+	require.NoError(t, err)
+	require.Equal(t, "a", publisher)
+
+	require.NoError(t, cache.ReleaseBlockPublication(1, "0x01"))
+	publisher, err = cache.ClaimBlockPublication(1, "0x01", "b")
+	require.NoError(t, err)
+	require.Equal(t, "b", publisher)
+}
+
+func TestInstanceHeartbeats(t *testing.T) {
+	cache := setupTestRedis(t)
+	now := time.Now().UTC()
+
+	require.NoError(t, cache.SetInstanceHeartbeat(InstanceHeartbeat{InstanceID: "a", Service: "api", LastSeen: now}))
+	require.NoError(t, cache.SetInstanceHeartbeat(InstanceHeartbeat{InstanceID: "a", Service: "housekeeper", LastSeen: now}))
+	require.NoError(t, cache.SetInstanceHeartbeat(InstanceHeartbeat{InstanceID: "b", Service: "api", LastSeen: now.Add(-time.Hour)}))
+
+	heartbeats, err := cache.GetInstanceHeartbeats(now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, heartbeats, 2)
+
+	// the stale instance was removed
+	res, err := cache.client.HLen(context.Background(), cache.keyInstances).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res)
+}
@@ -0,0 +1,176 @@
+package datastore
+
+import (
+	"math/big"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+var (
+	// how long getHeader best bids are cached in memory (0 to disable)
+	localBidCacheTTL = time.Duration(cli.GetEnvInt("GETHEADER_BID_CACHE_MS", 50)) * time.Millisecond
+
+	// how long the min bid and builder preferences of proposers are cached in memory (0 to disable)
+	localProposerCacheTTL = time.Duration(cli.GetEnvInt("GETHEADER_PROPOSER_CACHE_MS", 12_000)) * time.Millisecond
+
+	// how long the inclusion constraints of a slot are cached in memory (0 to disable). Kept short, as constraints
+	// registered through another instance only apply here once the cached entry expired.
+	localConstraintsCacheTTL = time.Duration(cli.GetEnvInt("CONSTRAINTS_CACHE_MS", 1_000)) * time.Millisecond
+
+	// maximum number of entries in each of the in-memory caches
+	localCacheSize = cli.GetEnvInt("GETHEADER_CACHE_SIZE", 1_000)
+)
+
+type ttlCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache is an LRU cache whose entries expire after a fixed duration. A cache with a zero duration is disabled.
+type ttlCache[K comparable, V any] struct {
+	ttl   time.Duration
+	cache *lru.Cache[K, ttlCacheEntry[V]]
+}
+
+func newTTLCache[K comparable, V any](size int, ttl time.Duration) *ttlCache[K, V] {
+	return &ttlCache[K, V]{ttl: ttl, cache: lru.NewCache[K, ttlCacheEntry[V]](size)}
+}
+
+func (c *ttlCache[K, V]) Get(key K) (value V, ok bool) {
+	if c.ttl == 0 {
+		return value, false
+	}
+	entry, ok := c.cache.Get(key)
+	if !ok {
+		return value, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.cache.Remove(key)
+		return value, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[K, V]) Add(key K, value V) {
+	if c.ttl == 0 {
+		return
+	}
+	c.cache.Add(key, ttlCacheEntry[V]{value: value, expiresAt: time.Now().Add(c.ttl)})
+}
+
+func (c *ttlCache[K, V]) Remove(key K) {
+	c.cache.Remove(key)
+}
+
+// RemoveIf removes all entries whose key matches
+func (c *ttlCache[K, V]) RemoveIf(match func(key K) bool) {
+	for _, key := range c.cache.Keys() {
+		if match(key) {
+			c.cache.Remove(key)
+		}
+	}
+}
+
+// GetBestBid returns the best bid for getHeader, considering only bids of allowed builders if the proposer has builder
+// preferences. Results are cached in memory for GETHEADER_BID_CACHE_MS, to serve the burst of getHeader requests at
+// the start of a slot without a Redis round trip each.
+func (ds *Datastore) GetBestBid(slot uint64, parentHash, proposerPubkey string, preferences *common.BuilderPreferences) (bid *builderSpec.VersionedSignedBuilderBid, err error) {
+	key := GetHeaderResponseKey{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey}
+	if bid, ok := ds.localBidCache.Get(key); ok {
+		return bid, nil
+	}
+
+	if preferences == nil || preferences.IsEmpty() {
+		bid, err = ds.redis.GetBestBid(slot, parentHash, proposerPubkey)
+	} else {
+		bid, err = ds.redis.GetBestBidOfBuilders(slot, parentHash, proposerPubkey, preferences.AllowsBuilder)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ds.localBidCache.Add(key, bid)
+	return bid, nil
+}
+
+// GetProposerMinBid returns the min bid of the proposer (nil if not set), cached in memory
+func (ds *Datastore) GetProposerMinBid(proposerPubkey string) (*big.Int, error) {
+	if minBid, ok := ds.localMinBidCache.Get(proposerPubkey); ok {
+		return minBid, nil
+	}
+	minBid, err := ds.redis.GetProposerMinBid(proposerPubkey)
+	if err != nil {
+		return nil, err
+	}
+	ds.localMinBidCache.Add(proposerPubkey, minBid)
+	return minBid, nil
+}
+
+// GetProposerBuilderPreferences returns the builder preferences of the proposer (nil if not set), cached in memory
+func (ds *Datastore) GetProposerBuilderPreferences(proposerPubkey string) (*common.BuilderPreferences, error) {
+	if preferences, ok := ds.localPreferencesCache.Get(proposerPubkey); ok {
+		return preferences, nil
+	}
+	preferences, err := ds.redis.GetProposerBuilderPreferences(proposerPubkey)
+	if err != nil {
+		return nil, err
+	}
+	ds.localPreferencesCache.Add(proposerPubkey, preferences)
+	return preferences, nil
+}
+
+// GetInclusionConstraints returns the inclusion constraints of the slot (nil if there are none), cached in memory
+func (ds *Datastore) GetInclusionConstraints(slot uint64) (*common.SignedInclusionConstraints, error) {
+	if constraints, ok := ds.localConstraintsCache.Get(slot); ok {
+		return constraints, nil
+	}
+	constraints, err := ds.redis.GetInclusionConstraints(slot)
+	if err != nil {
+		return nil, err
+	}
+	ds.localConstraintsCache.Add(slot, constraints)
+	return constraints, nil
+}
+
+// SetInclusionConstraints stores the inclusion constraints of a slot, and removes the cached ones and the cached best
+// bids of the slot, which might not satisfy them
+func (ds *Datastore) SetInclusionConstraints(constraints *common.SignedInclusionConstraints) error {
+	if err := ds.redis.SetInclusionConstraints(constraints); err != nil {
+		return err
+	}
+	slot := constraints.Message.Slot
+	ds.localConstraintsCache.Remove(slot)
+	ds.localBidCache.RemoveIf(func(key GetHeaderResponseKey) bool {
+		return key.Slot == slot
+	})
+	return nil
+}
+
+// InvalidateBids removes the bids of a slot+parent+proposer combination, and the cached best bids of the slot, after
+// the parent was reorged away. Returns the block hashes of the removed bids.
+func (ds *Datastore) InvalidateBids(slot uint64, parentHash, proposerPubkey string) ([]string, error) {
+	blockHashes, err := ds.redis.InvalidateBids(slot, parentHash, proposerPubkey)
+	ds.localBidCache.RemoveIf(func(key GetHeaderResponseKey) bool {
+		return key.Slot == slot
+	})
+	return blockHashes, err
+}
+
+// InvalidateBestBid removes the cached best bid of a slot+parent+proposer combination, after a bid was removed through
+// this instance
+func (ds *Datastore) InvalidateBestBid(slot uint64, parentHash, proposerPubkey string) {
+	ds.localBidCache.Remove(GetHeaderResponseKey{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey})
+}
+
+// InvalidateProposerCache removes the cached min bid, builder preferences and best bids of the proposer, after they
+// were updated through this instance. Updates through other instances apply once the cached entries expire.
+func (ds *Datastore) InvalidateProposerCache(proposerPubkey string) {
+	ds.localMinBidCache.Remove(proposerPubkey)
+	ds.localPreferencesCache.Remove(proposerPubkey)
+	ds.localBidCache.RemoveIf(func(key GetHeaderResponseKey) bool {
+		return key.ProposerPubkey == proposerPubkey
+	})
+}
@@ -2,18 +2,20 @@ package datastore
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	builderApi "github.com/attestantio/go-builder-client/api"
 	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
 )
 
 var (
-	defaultMemcachedExpirySeconds = int32(cli.GetEnvInt("MEMCACHED_EXPIRY_SECONDS", 45))
-	defaultMemcachedTimeoutMs     = cli.GetEnvInt("MEMCACHED_CLIENT_TIMEOUT_MS", 250)
-	defaultMemcachedMaxIdleConns  = cli.GetEnvInt("MEMCACHED_MAX_IDLE_CONNS", 10)
+	defaultMemcachedTimeoutMs    = cli.GetEnvInt("MEMCACHED_CLIENT_TIMEOUT_MS", 250)
+	defaultMemcachedMaxIdleConns = cli.GetEnvInt("MEMCACHED_MAX_IDLE_CONNS", 10)
 )
 
 type Memcached struct {
@@ -21,40 +23,100 @@ type Memcached struct {
 	keyPrefix string
 }
 
-// SaveExecutionPayload attempts to insert execution engine payload to memcached using composite key of slot,
-// proposer public key, block hash, and cache prefix if specified. Note that writes to the same key value
-// (i.e. same slot, proposer public key, and block hash) will overwrite the existing entry.
-func (m *Memcached) SaveExecutionPayload(slot uint64, proposerPubKey, blockHash string, payload *builderApi.VersionedSubmitBlindedBlockResponse) error {
-	// TODO: standardize key format with redis cache and re-use the same function(s)
-	key := fmt.Sprintf("boost-relay/%s:cache-getpayload-response:%d_%s_%s", m.keyPrefix, slot, proposerPubKey, blockHash)
+func (m *Memcached) keyGetPayloadResponse(slot uint64, proposerPubKey, blockHash string) string {
+	return fmt.Sprintf("%s/%s:cache-getpayload-response:%d_%s_%s", redisPrefix, m.keyPrefix, slot, proposerPubKey, blockHash)
+}
+
+func (m *Memcached) keyBidTrace(slot uint64, proposerPubKey, blockHash string) string {
+	return fmt.Sprintf("%s/%s:cache-bid-trace:%d_%s_%s", redisPrefix, m.keyPrefix, slot, proposerPubKey, blockHash)
+}
+
+func (m *Memcached) keyValidatorRegistrationTimestamp(proposerPubKey common.PubkeyHex) string {
+	return fmt.Sprintf("%s/%s:validator-registration-timestamp:%s", redisPrefix, m.keyPrefix, proposerPubKey)
+}
+
+// Ping checks the connections to the memcached servers
+func (m *Memcached) Ping() error {
+	return m.client.Ping()
+}
+
+// Close closes the connections to the memcached servers
+func (m *Memcached) Close() error {
+	return m.client.Close()
+}
 
-	bytes, err := json.Marshal(payload)
+// SetObj saves an object (JSON encoded) in memcached. Writes to an existing key overwrite the previous entry.
+func (m *Memcached) SetObj(key string, value any, expiration time.Duration) error {
+	bytes, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
 
 	//nolint:exhaustruct // "Flags" variable unused and opaque server-side
-	return m.client.Set(&memcache.Item{Key: key, Value: bytes, Expiration: defaultMemcachedExpirySeconds})
+	return m.client.Set(&memcache.Item{Key: key, Value: bytes, Expiration: memcachedExpiry(expiration)})
+}
+
+// GetObj loads a JSON encoded object from memcached, returns memcache.ErrCacheMiss if the key does not exist
+func (m *Memcached) GetObj(key string, obj any) error {
+	item, err := m.client.Get(key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(item.Value, obj)
+}
+
+// SaveExecutionPayload attempts to insert execution engine payload to memcached using composite key of slot,
+// proposer public key, block hash, and cache prefix if specified. Note that writes to the same key value
+// (i.e. same slot, proposer public key, and block hash) will overwrite the existing entry.
+func (m *Memcached) SaveExecutionPayload(slot uint64, proposerPubKey, blockHash string, payload *builderApi.VersionedSubmitBlindedBlockResponse) error {
+	return m.SetObj(m.keyGetPayloadResponse(slot, proposerPubKey, blockHash), payload, expiryPayload)
 }
 
 // GetExecutionPayload attempts to fetch execution engine payload from memcached using composite key of slot,
 // proposer public key, block hash, and cache prefix if specified.
 func (m *Memcached) GetExecutionPayload(slot uint64, proposerPubKey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error) {
-	// TODO: standardize key format with redis cache and re-use the same function(s)
-	key := fmt.Sprintf("boost-relay/%s:cache-getpayload-response:%d_%s_%s", m.keyPrefix, slot, proposerPubKey, blockHash)
-	item, err := m.client.Get(key)
+	result := new(builderApi.VersionedSubmitBlindedBlockResponse)
+	err := m.GetObj(m.keyGetPayloadResponse(slot, proposerPubKey, blockHash), result)
 	if err != nil {
 		return nil, err
 	}
+	return result, nil
+}
 
-	result := new(builderApi.VersionedSubmitBlindedBlockResponse)
-	if err = result.UnmarshalJSON(item.Value); err != nil {
+// SaveBidTrace inserts the bid trace of a submission, using the same composite key as the execution payload.
+func (m *Memcached) SaveBidTrace(trace *common.BidTraceV2WithBlobFields) error {
+	return m.SetObj(m.keyBidTrace(trace.Slot, trace.ProposerPubkey.String(), trace.BlockHash.String()), trace, expiryPayload)
+}
+
+// GetBidTrace fetches the bid trace for a given slot, proposer public key and block hash.
+func (m *Memcached) GetBidTrace(slot uint64, proposerPubKey, blockHash string) (*common.BidTraceV2WithBlobFields, error) {
+	result := new(common.BidTraceV2WithBlobFields)
+	err := m.GetObj(m.keyBidTrace(slot, proposerPubKey, blockHash), result)
+	if err != nil {
 		return nil, err
 	}
-
 	return result, nil
 }
 
+// GetValidatorRegistrationTimestamp returns the timestamp of the latest registration of the validator, or 0 if there
+// is none
+func (m *Memcached) GetValidatorRegistrationTimestamp(proposerPubKey common.PubkeyHex) (uint64, error) {
+	item, err := m.client.Get(m.keyValidatorRegistrationTimestamp(proposerPubKey))
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(item.Value), 10, 64)
+}
+
+// SetValidatorRegistrationTimestamp saves the timestamp of the latest registration of the validator
+func (m *Memcached) SetValidatorRegistrationTimestamp(proposerPubKey common.PubkeyHex, timestamp uint64) error {
+	value := []byte(strconv.FormatUint(timestamp, 10))
+	//nolint:exhaustruct // "Flags" variable unused and opaque server-side
+	return m.client.Set(&memcache.Item{Key: m.keyValidatorRegistrationTimestamp(proposerPubKey), Value: value, Expiration: memcachedExpiry(expiryRegistration)})
+}
+
 func NewMemcached(prefix string, servers ...string) (*Memcached, error) {
 	if len(servers) == 0 {
 		return nil, nil
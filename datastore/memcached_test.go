@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -18,7 +19,6 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
-	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/types"
 	"github.com/flashbots/go-boost-utils/utils"
@@ -166,7 +166,7 @@ func TestMemcached(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, mem)
 
-	builderPk, err := utils.HexToPubkey("0xf9716c94aab536227804e859d15207aa7eaaacd839f39dcbdb5adc942842a8d2fb730f9f49fc719fdb86f1873e0ed1c2")
+	builderPk, err := utils.HexToPubkey("0x983649810ed1691509d42918af2918a7042a28537475125a7c4d85a2f567efdba757410eb6546d724506ce42bdda9f1b")
 	require.NoError(t, err)
 
 	builderSk, err := utils.HexToSignature("0x8209b5391cd69f392b1f02dbc03bab61f574bb6bb54bf87b59e2a85bdc0756f7db6a71ce1b41b727a1f46ccc77b213bf0df1426177b5b29926b39956114421eaa36ec4602969f6f6370a44de44a6bce6dae2136e5fb594cce2a476354264d1ea")
@@ -280,7 +280,7 @@ func TestMemcached(t *testing.T) {
 			},
 		},
 		{
-			Description: fmt.Sprintf("Given a valid builder submit block request, memcached entry should expire after %d seconds", defaultMemcachedExpirySeconds),
+			Description: fmt.Sprintf("Given a valid builder submit block request, memcached entry should expire after %s", expiryPayload),
 			Input:       testBuilderSubmitBlockRequest(builderPk, builderSk, spec.DataVersionCapella),
 			TestSuite: func(tc *test) func(*testing.T) {
 				return func(t *testing.T) {
@@ -313,7 +313,7 @@ func TestMemcached(t *testing.T) {
 					require.NoError(t, err)
 					require.Equal(t, len(ret.Capella.Transactions), len(submission.Transactions))
 
-					time.Sleep((time.Duration(defaultMemcachedExpirySeconds) + 2) * time.Second)
+					time.Sleep(expiryPayload + 2*time.Second)
 					expired, err := mem.GetExecutionPayload(submission.BidTrace.Slot, submission.BidTrace.ProposerPubkey.String(), submission.BidTrace.BlockHash.String())
 					require.NoError(t, err)
 					require.NotEqual(t, ret, expired)
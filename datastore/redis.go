@@ -2,6 +2,7 @@ package datastore
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +16,6 @@ import (
 	builderSpec "github.com/attestantio/go-builder-client/spec"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/capella"
-	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/go-redis/redis/v9"
 )
@@ -24,67 +24,148 @@ var (
 	redisScheme = "redis://"
 	redisPrefix = "boost-relay"
 
-	expiryBidCache = 45 * time.Second
+	expiryGetPayloadRequest = 24 * time.Hour
+
+	knownValidatorsRedisBatchSize = 10_000 // number of validators per HSET command
 
 	RedisConfigFieldPubkey         = "pubkey"
 	RedisStatsFieldLatestSlot      = "latest-slot"
 	RedisStatsFieldValidatorsTotal = "validators-total"
 
+	RedisStatsFieldKnownValidatorsSlot = "known-validators-slot"
+	RedisStatsFieldSubmissionDedupHits = "submission-dedup-hits"
+	RedisStatsFieldChainReorgs         = "chain-reorgs"
+	RedisStatsFieldRequestsTooLarge    = "requests-too-large"
+	RedisStatsFieldRequestTimeouts     = "request-timeouts"
+	RedisStatsFieldInflatedBids        = "inflated-bids"
+	RedisStatsFieldHeaderBidsCancelled = "header-bids-cancelled"
+	RedisStatsFieldSlotGCKeysRemoved   = "slot-gc-keys-removed"
+	RedisStatsFieldPayloadsPruned      = "execution-payloads-pruned"
+
+	RedisSlotRequestFieldGetHeader  = "getheader"
+	RedisSlotRequestFieldGetPayload = "getpayload"
+
+	RedisEpochCountFieldRegistrations = "registrations"
+	RedisEpochCountFieldHeadersServed = "headers-served"
+
 	ErrFailedUpdatingTopBidNoBids            = errors.New("failed to update top bid because no bids were found")
 	ErrAnotherPayloadAlreadyDeliveredForSlot = errors.New("another payload block hash for slot was already delivered")
 	ErrPastSlotAlreadyDelivered              = errors.New("payload for past slot was already delivered")
+	ErrGetPayloadEquivocation                = errors.New("getPayload request for a different block hash was already received for this slot")
 
-	// Docs about redis settings: https://redis.io/docs/reference/clients/
-	redisConnectionPoolSize = cli.GetEnvInt("REDIS_CONNECTION_POOL_SIZE", 0) // 0 means use default (10 per CPU)
-	redisMinIdleConnections = cli.GetEnvInt("REDIS_MIN_IDLE_CONNECTIONS", 0) // 0 means use default
-	redisReadTimeoutSec     = cli.GetEnvInt("REDIS_READ_TIMEOUT_SEC", 0)     // 0 means use default (3 sec)
-	redisPoolTimeoutSec     = cli.GetEnvInt("REDIS_POOL_TIMEOUT_SEC", 0)     // 0 means use default (ReadTimeout + 1 sec)
-	redisWriteTimeoutSec    = cli.GetEnvInt("REDIS_WRITE_TIMEOUT_SEC", 0)    // 0 means use default (3 seconds)
+	ErrInvalidRedisMode           = errors.New("invalid redis mode")
+	ErrMissingRedisSentinelMaster = errors.New("redis sentinel mode requires the master name")
 )
 
-func connectRedis(redisURI string) (*redis.Client, error) {
-	// Handle both URIs and full URLs, assume unencrypted connections
-	if !strings.HasPrefix(redisURI, redisScheme) && !strings.HasPrefix(redisURI, "rediss://") {
-		redisURI = redisScheme + redisURI
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeCluster    = "cluster"
+	RedisModeSentinel   = "sentinel"
+)
+
+// RedisOpts are the options to connect to Redis. In cluster and sentinel mode, the URIs are comma separated lists of
+// node (respectively sentinel) addresses. Docs about redis settings: https://redis.io/docs/reference/clients/
+type RedisOpts struct {
+	Mode           string // standalone (default), cluster or sentinel
+	URI            string
+	ReadonlyURI    string // optional, used for read-heavy operations like getHeader
+	SentinelMaster string
+	Username       string // overrides the username of a standalone URI
+	Password       string // overrides the password of a standalone URI
+	TLS            bool   // standalone URIs can also enable TLS with the rediss:// scheme
+
+	PoolSize     int           // 0 means use default (10 per CPU)
+	MinIdleConns int           // 0 means use default
+	ReadTimeout  time.Duration // 0 means use default (3 sec)
+	WriteTimeout time.Duration // 0 means use default (3 sec)
+	PoolTimeout  time.Duration // 0 means use default (ReadTimeout + 1 sec)
+}
+
+func connectRedis(redisURI string, opts RedisOpts) (redis.UniversalClient, error) {
+	universalOpts := &redis.UniversalOptions{
+		Addrs:        strings.Split(redisURI, ","),
+		Username:     opts.Username,
+		Password:     opts.Password,
+		MasterName:   opts.SentinelMaster,
+		PoolSize:     opts.PoolSize,
+		MinIdleConns: opts.MinIdleConns,
+		ReadTimeout:  opts.ReadTimeout,
+		WriteTimeout: opts.WriteTimeout,
+		PoolTimeout:  opts.PoolTimeout,
+	}
+	if opts.TLS {
+		universalOpts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	var redisClient redis.UniversalClient
+	switch opts.Mode {
+	case "", RedisModeStandalone:
+		// Handle both URIs and full URLs, assume unencrypted connections
+		if !strings.HasPrefix(redisURI, redisScheme) && !strings.HasPrefix(redisURI, "rediss://") {
+			redisURI = redisScheme + redisURI
+		}
+		redisOpts, err := redis.ParseURL(redisURI)
+		if err != nil {
+			return nil, err
+		}
+		applyRedisOpts(redisOpts, universalOpts)
+		redisClient = redis.NewClient(redisOpts)
+	case RedisModeCluster:
+		redisClient = redis.NewClusterClient(universalOpts.Cluster())
+	case RedisModeSentinel:
+		if opts.SentinelMaster == "" {
+			return nil, ErrMissingRedisSentinelMaster
+		}
+		redisClient = redis.NewFailoverClient(universalOpts.Failover())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRedisMode, opts.Mode)
 	}
 
-	redisOpts, err := redis.ParseURL(redisURI)
-	if err != nil {
+	if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
+		// unable to connect to redis
 		return nil, err
 	}
+	return redisClient, nil
+}
 
-	if redisConnectionPoolSize > 0 {
-		redisOpts.PoolSize = redisConnectionPoolSize
+// applyRedisOpts sets the options of a standalone connection which are not part of the URI
+func applyRedisOpts(redisOpts *redis.Options, opts *redis.UniversalOptions) {
+	if opts.Username != "" {
+		redisOpts.Username = opts.Username
 	}
-	if redisMinIdleConnections > 0 {
-		redisOpts.MinIdleConns = redisMinIdleConnections
+	if opts.Password != "" {
+		redisOpts.Password = opts.Password
 	}
-	if redisReadTimeoutSec > 0 {
-		redisOpts.ReadTimeout = time.Duration(redisReadTimeoutSec) * time.Second
+	if opts.TLSConfig != nil && redisOpts.TLSConfig == nil {
+		redisOpts.TLSConfig = opts.TLSConfig
 	}
-	if redisPoolTimeoutSec > 0 {
-		redisOpts.PoolTimeout = time.Duration(redisPoolTimeoutSec) * time.Second
+	if opts.PoolSize > 0 {
+		redisOpts.PoolSize = opts.PoolSize
 	}
-	if redisWriteTimeoutSec > 0 {
-		redisOpts.WriteTimeout = time.Duration(redisWriteTimeoutSec) * time.Second
+	if opts.MinIdleConns > 0 {
+		redisOpts.MinIdleConns = opts.MinIdleConns
 	}
-
-	redisClient := redis.NewClient(redisOpts)
-	if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
-		// unable to connect to redis
-		return nil, err
+	if opts.ReadTimeout > 0 {
+		redisOpts.ReadTimeout = opts.ReadTimeout
+	}
+	if opts.PoolTimeout > 0 {
+		redisOpts.PoolTimeout = opts.PoolTimeout
+	}
+	if opts.WriteTimeout > 0 {
+		redisOpts.WriteTimeout = opts.WriteTimeout
 	}
-	return redisClient, nil
 }
 
 type RedisCache struct {
-	client         *redis.Client
-	readonlyClient *redis.Client
+	client         redis.UniversalClient
+	readonlyClient redis.UniversalClient
+	isCluster      bool
 
 	// prefixes (keys generated with a function)
 	prefixGetHeaderResponse           string
 	prefixExecPayloadCapella          string
 	prefixPayloadContentsDeneb        string
+	prefixPayloadContentsElectra      string
 	prefixBidTrace                    string
 	prefixBlockBuilderLatestBids      string // latest bid for a given slot
 	prefixBlockBuilderLatestBidsValue string // value of latest bid for a given slot
@@ -92,6 +173,16 @@ type RedisCache struct {
 	prefixTopBidValue                 string
 	prefixFloorBid                    string
 	prefixFloorBidValue               string
+	prefixGetPayloadRequest           string
+	prefixLease                       string
+	prefixBlockPublication            string
+	prefixGetHeaderCalls              string
+	prefixInclusionConstraints        string
+	prefixPreconfCommitments          string
+	prefixUpstreamBids                string
+	prefixPendingPayloads             string
+	prefixSlotRequestCounts           string
+	prefixEpochCounts                 string
 
 	// keys
 	keyValidatorRegistrationTimestamp string
@@ -100,32 +191,47 @@ type RedisCache struct {
 	keyStats              string
 	keyProposerDuties     string
 	keyBlockBuilderStatus string
+	keyKnownValidators    string
 	keyLastSlotDelivered  string
 	keyLastHashDelivered  string
+	keyProposerMinBid     string
+
+	keyProposerBuilderPreferences string
+	keyInstances                  string
 }
 
+// NewRedisCache connects to a standalone Redis instance, with an optional read replica
 func NewRedisCache(prefix, redisURI, readonlyURI string) (*RedisCache, error) {
-	client, err := connectRedis(redisURI)
+	return NewRedisCacheWithOpts(prefix, RedisOpts{URI: redisURI, ReadonlyURI: readonlyURI})
+}
+
+func NewRedisCacheWithOpts(prefix string, opts RedisOpts) (*RedisCache, error) {
+	if err := checkExpiries(); err != nil {
+		return nil, err
+	}
+
+	client, err := connectRedis(opts.URI, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	roClient := client
-	if readonlyURI != "" {
-		roClient, err = connectRedis(readonlyURI)
+	if opts.ReadonlyURI != "" {
+		roClient, err = connectRedis(opts.ReadonlyURI, opts)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return &RedisCache{
+	r := &RedisCache{
 		client:         client,
 		readonlyClient: roClient,
 
-		prefixGetHeaderResponse:    fmt.Sprintf("%s/%s:cache-gethead-response", redisPrefix, prefix),
-		prefixExecPayloadCapella:   fmt.Sprintf("%s/%s:cache-execpayload-capella", redisPrefix, prefix),
-		prefixPayloadContentsDeneb: fmt.Sprintf("%s/%s:cache-payloadcontents-deneb", redisPrefix, prefix),
-		prefixBidTrace:             fmt.Sprintf("%s/%s:cache-bid-trace", redisPrefix, prefix),
+		prefixGetHeaderResponse:      fmt.Sprintf("%s/%s:cache-gethead-response", redisPrefix, prefix),
+		prefixExecPayloadCapella:     fmt.Sprintf("%s/%s:cache-execpayload-capella", redisPrefix, prefix),
+		prefixPayloadContentsDeneb:   fmt.Sprintf("%s/%s:cache-payloadcontents-deneb", redisPrefix, prefix),
+		prefixPayloadContentsElectra: fmt.Sprintf("%s/%s:cache-payloadcontents-electra", redisPrefix, prefix),
+		prefixBidTrace:               fmt.Sprintf("%s/%s:cache-bid-trace", redisPrefix, prefix),
 
 		prefixBlockBuilderLatestBids:      fmt.Sprintf("%s/%s:block-builder-latest-bid", redisPrefix, prefix),       // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
 		prefixBlockBuilderLatestBidsValue: fmt.Sprintf("%s/%s:block-builder-latest-bid-value", redisPrefix, prefix), // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
@@ -133,6 +239,16 @@ func NewRedisCache(prefix, redisURI, readonlyURI string) (*RedisCache, error) {
 		prefixTopBidValue:                 fmt.Sprintf("%s/%s:top-bid-value", redisPrefix, prefix),                  // prefix:slot_parentHash_proposerPubkey
 		prefixFloorBid:                    fmt.Sprintf("%s/%s:bid-floor", redisPrefix, prefix),                      // prefix:slot_parentHash_proposerPubkey
 		prefixFloorBidValue:               fmt.Sprintf("%s/%s:bid-floor-value", redisPrefix, prefix),                // prefix:slot_parentHash_proposerPubkey
+		prefixGetPayloadRequest:           fmt.Sprintf("%s/%s:getpayload-request", redisPrefix, prefix),             // prefix:slot
+		prefixLease:                       fmt.Sprintf("%s/%s:lease", redisPrefix, prefix),                          // prefix:name
+		prefixBlockPublication:            fmt.Sprintf("%s/%s:block-publication", redisPrefix, prefix),              // prefix:slot_blockHash
+		prefixGetHeaderCalls:              fmt.Sprintf("%s/%s:getheader-calls", redisPrefix, prefix),                // prefix:slot
+		prefixInclusionConstraints:        fmt.Sprintf("%s/%s:inclusion-constraints", redisPrefix, prefix),          // prefix:slot
+		prefixPreconfCommitments:          fmt.Sprintf("%s/%s:preconf-commitments", redisPrefix, prefix),            // hashmap for slot with block hash as field
+		prefixUpstreamBids:                fmt.Sprintf("%s/%s:upstream-bids", redisPrefix, prefix),                  // hashmap for slot with block hash as field and upstream relay as value
+		prefixPendingPayloads:             fmt.Sprintf("%s/%s:pending-payloads", redisPrefix, prefix),               // hashmap for slot with block hash as field
+		prefixSlotRequestCounts:           fmt.Sprintf("%s/%s:slot-request-counts", redisPrefix, prefix),            // hashmap for slot with request type as field and count as value
+		prefixEpochCounts:                 fmt.Sprintf("%s/%s:epoch-counts", redisPrefix, prefix),                   // hashmap for epoch with counter name as field
 
 		keyValidatorRegistrationTimestamp: fmt.Sprintf("%s/%s:validator-registration-timestamp", redisPrefix, prefix),
 		keyRelayConfig:                    fmt.Sprintf("%s/%s:relay-config", redisPrefix, prefix),
@@ -140,13 +256,59 @@ func NewRedisCache(prefix, redisURI, readonlyURI string) (*RedisCache, error) {
 		keyStats:              fmt.Sprintf("%s/%s:stats", redisPrefix, prefix),
 		keyProposerDuties:     fmt.Sprintf("%s/%s:proposer-duties", redisPrefix, prefix),
 		keyBlockBuilderStatus: fmt.Sprintf("%s/%s:block-builder-status", redisPrefix, prefix),
+		keyKnownValidators:    fmt.Sprintf("%s/%s:known-validators", redisPrefix, prefix), // hashmap with validator index as field and pubkey as value
 		keyLastSlotDelivered:  fmt.Sprintf("%s/%s:last-slot-delivered", redisPrefix, prefix),
 		keyLastHashDelivered:  fmt.Sprintf("%s/%s:last-hash-delivered", redisPrefix, prefix),
-	}, nil
+		keyProposerMinBid:     fmt.Sprintf("%s/%s:proposer-min-bid", redisPrefix, prefix), // hashmap with proposer pubkey as field and min bid value (wei) as value
+
+		keyProposerBuilderPreferences: fmt.Sprintf("%s/%s:proposer-builder-preferences", redisPrefix, prefix), // hashmap with proposer pubkey as field and builder preferences (JSON) as value
+		keyInstances:                  fmt.Sprintf("%s/%s:instances", redisPrefix, prefix),                    // hashmap with service/instance id as field and heartbeat (JSON) as value
+	}
+
+	// Keys which are watched together in a transaction must be in the same hash slot of a cluster
+	if opts.Mode == RedisModeCluster {
+		r.isCluster = true
+		r.keyLastSlotDelivered = fmt.Sprintf("%s/%s:{last-delivered}-slot", redisPrefix, prefix)
+		r.keyLastHashDelivered = fmt.Sprintf("%s/%s:{last-delivered}-hash", redisPrefix, prefix)
+	}
+	return r, nil
+}
+
+// Ping checks the connections to Redis
+func (r *RedisCache) Ping() error {
+	if err := r.client.Ping(context.Background()).Err(); err != nil {
+		return err
+	}
+	return r.readonlyClient.Ping(context.Background()).Err()
+}
+
+// PoolStats returns the connection pool stats of the client, and of the readonly client (the same if there is no
+// separate readonly endpoint)
+func (r *RedisCache) PoolStats() (primary, readonly *redis.PoolStats) {
+	return r.client.PoolStats(), r.readonlyClient.PoolStats()
+}
+
+// Close closes the connections to Redis
+func (r *RedisCache) Close() error {
+	if r.readonlyClient != r.client {
+		if err := r.readonlyClient.Close(); err != nil {
+			return err
+		}
+	}
+	return r.client.Close()
+}
+
+// auctionKey returns the key of a prefix for a given slot+parentHash+proposerPubkey. In cluster mode the auction is the
+// hash tag of the key, so that all keys of an auction are in the same hash slot and bids can be copied between them.
+func (r *RedisCache) auctionKey(prefix string, slot uint64, parentHash, proposerPubkey string) string {
+	if r.isCluster {
+		return fmt.Sprintf("%s:{%d_%s_%s}", prefix, slot, parentHash, proposerPubkey)
+	}
+	return fmt.Sprintf("%s:%d_%s_%s", prefix, slot, parentHash, proposerPubkey)
 }
 
 func (r *RedisCache) keyCacheGetHeaderResponse(slot uint64, parentHash, proposerPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s", r.prefixGetHeaderResponse, slot, parentHash, proposerPubkey)
+	return r.auctionKey(r.prefixGetHeaderResponse, slot, parentHash, proposerPubkey)
 }
 
 func (r *RedisCache) keyExecPayloadCapella(slot uint64, proposerPubkey, blockHash string) string {
@@ -157,42 +319,95 @@ func (r *RedisCache) keyPayloadContentsDeneb(slot uint64, proposerPubkey, blockH
 	return fmt.Sprintf("%s:%d_%s_%s", r.prefixPayloadContentsDeneb, slot, proposerPubkey, blockHash)
 }
 
+func (r *RedisCache) keyPayloadContentsElectra(slot uint64, proposerPubkey, blockHash string) string {
+	return fmt.Sprintf("%s:%d_%s_%s", r.prefixPayloadContentsElectra, slot, proposerPubkey, blockHash)
+}
+
 func (r *RedisCache) keyCacheBidTrace(slot uint64, proposerPubkey, blockHash string) string {
 	return fmt.Sprintf("%s:%d_%s_%s", r.prefixBidTrace, slot, proposerPubkey, blockHash)
 }
 
 // keyLatestBidByBuilder returns the key for the getHeader response the latest bid by a specific builder
 func (r *RedisCache) keyLatestBidByBuilder(slot uint64, parentHash, proposerPubkey, builderPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s/%s", r.prefixBlockBuilderLatestBids, slot, parentHash, proposerPubkey, builderPubkey)
+	return r.auctionKey(r.prefixBlockBuilderLatestBids, slot, parentHash, proposerPubkey) + "/" + builderPubkey
 }
 
 // keyBlockBuilderLatestBidValue returns the hashmap key for the value of the latest bid by a specific builder
 func (r *RedisCache) keyBlockBuilderLatestBidsValue(slot uint64, parentHash, proposerPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s", r.prefixBlockBuilderLatestBidsValue, slot, parentHash, proposerPubkey)
+	return r.auctionKey(r.prefixBlockBuilderLatestBidsValue, slot, parentHash, proposerPubkey)
 }
 
 // keyBlockBuilderLatestBidValue returns the hashmap key for the time of the latest bid by a specific builder
 func (r *RedisCache) keyBlockBuilderLatestBidsTime(slot uint64, parentHash, proposerPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s", r.prefixBlockBuilderLatestBidsTime, slot, parentHash, proposerPubkey)
+	return r.auctionKey(r.prefixBlockBuilderLatestBidsTime, slot, parentHash, proposerPubkey)
 }
 
 // keyTopBidValue returns the hashmap key for the time of the latest bid by a specific builder
 func (r *RedisCache) keyTopBidValue(slot uint64, parentHash, proposerPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s", r.prefixTopBidValue, slot, parentHash, proposerPubkey)
+	return r.auctionKey(r.prefixTopBidValue, slot, parentHash, proposerPubkey)
 }
 
 // keyFloorBid returns the key for the highest non-cancellable bid of a given slot+parentHash+proposerPubkey
 func (r *RedisCache) keyFloorBid(slot uint64, parentHash, proposerPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBid, slot, parentHash, proposerPubkey)
+	return r.auctionKey(r.prefixFloorBid, slot, parentHash, proposerPubkey)
 }
 
 // keyFloorBidValue returns the key for the highest non-cancellable value of a given slot+parentHash+proposerPubkey
 func (r *RedisCache) keyFloorBidValue(slot uint64, parentHash, proposerPubkey string) string {
-	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBidValue, slot, parentHash, proposerPubkey)
+	return r.auctionKey(r.prefixFloorBidValue, slot, parentHash, proposerPubkey)
+}
+
+// keyGetPayloadRequest returns the key for the first getPayload request received for a given slot
+func (r *RedisCache) keyGetPayloadRequest(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixGetPayloadRequest, slot)
+}
+
+// keyGetHeaderCalls returns the key for the list of delayed getHeader calls of a given slot
+func (r *RedisCache) keyGetHeaderCalls(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixGetHeaderCalls, slot)
+}
+
+// keyInclusionConstraints returns the key for the signed inclusion constraints of a given slot
+func (r *RedisCache) keyInclusionConstraints(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixInclusionConstraints, slot)
+}
+
+// keyPreconfCommitments returns the key for the preconfirmation commitments of the bids of a given slot
+func (r *RedisCache) keyPreconfCommitments(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixPreconfCommitments, slot)
+}
+
+// keyUpstreamBids returns the key for the upstream relays of the bids of a given slot which were served in getHeader
+func (r *RedisCache) keyUpstreamBids(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixUpstreamBids, slot)
+}
+
+// keyPendingPayloads returns the key for the header-only bids of a given slot whose payload was not received yet
+func (r *RedisCache) keyPendingPayloads(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixPendingPayloads, slot)
+}
+
+// keySlotRequestCounts returns the key for the number of getHeader and getPayload requests of a given slot
+func (r *RedisCache) keySlotRequestCounts(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixSlotRequestCounts, slot)
+}
+
+// keyEpochCounts returns the key for the counters of the epoch summary of a given epoch
+func (r *RedisCache) keyEpochCounts(epoch uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixEpochCounts, epoch)
 }
 
 func (r *RedisCache) GetObj(key string, obj any) (err error) {
-	value, err := r.client.Get(context.Background(), key).Result()
+	return getObj(r.client, key, obj)
+}
+
+// getReadonlyObj is GetObj using the readonly client, for read-heavy operations
+func (r *RedisCache) getReadonlyObj(key string, obj any) (err error) {
+	return getObj(r.readonlyClient, key, obj)
+}
+
+func getObj(client redis.UniversalClient, key string, obj any) (err error) {
+	value, err := client.Get(context.Background(), key).Result()
 	if err != nil {
 		return err
 	}
@@ -253,7 +468,12 @@ func (r *RedisCache) SetValidatorRegistrationTimestampIfNewer(proposerPubkey com
 }
 
 func (r *RedisCache) SetValidatorRegistrationTimestamp(proposerPubkey common.PubkeyHex, timestamp uint64) error {
-	return r.client.HSet(context.Background(), r.keyValidatorRegistrationTimestamp, proposerPubkey.String(), timestamp).Err()
+	err := r.client.HSet(context.Background(), r.keyValidatorRegistrationTimestamp, proposerPubkey.String(), timestamp).Err()
+	if err != nil || expiryRegistration == 0 {
+		return err
+	}
+	// only set the expiry if the hash has none yet, so that it isn't extended by every registration
+	return r.client.ExpireNX(context.Background(), r.keyValidatorRegistrationTimestamp, expiryRegistration).Err()
 }
 
 func (r *RedisCache) CheckAndSetLastSlotAndHashDelivered(slot uint64, hash string) (err error) {
@@ -295,6 +515,238 @@ func (r *RedisCache) CheckAndSetLastSlotAndHashDelivered(slot uint64, hash strin
 	return r.client.Watch(context.Background(), txf, r.keyLastSlotDelivered, r.keyLastHashDelivered)
 }
 
+// GetPayloadRequestRecord is the first signed blinded beacon block received in a getPayload request for a slot
+type GetPayloadRequestRecord struct {
+	BlockHash                string          `json:"block_hash"`
+	SignedBlindedBeaconBlock json.RawMessage `json:"signed_blinded_beacon_block"`
+}
+
+// GetHeaderCall is a getHeader call whose response was held until a target time into the slot
+type GetHeaderCall struct {
+	ProposerPubkey string `json:"proposer_pubkey"`
+	ParentHash     string `json:"parent_hash"`
+	RequestedAtMs  int64  `json:"requested_at_ms,string"`
+	ServedAtMs     int64  `json:"served_at_ms,string"`
+	BlockHash      string `json:"block_hash,omitempty"` // empty if no bid was served
+	Value          string `json:"value,omitempty"`
+}
+
+// AddGetHeaderCall records a delayed getHeader call of a slot
+func (r *RedisCache) AddGetHeaderCall(slot uint64, call GetHeaderCall) error {
+	marshalledCall, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+	key := r.keyGetHeaderCalls(slot)
+	pipe := r.client.TxPipeline()
+	pipe.RPush(context.Background(), key, marshalledCall)
+	pipe.Expire(context.Background(), key, expiryGetPayloadRequest)
+	_, err = pipe.Exec(context.Background())
+	return err
+}
+
+// GetGetHeaderCalls returns the delayed getHeader calls of a slot, in the order they were served
+func (r *RedisCache) GetGetHeaderCalls(slot uint64) ([]GetHeaderCall, error) {
+	items, err := r.client.LRange(context.Background(), r.keyGetHeaderCalls(slot), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]GetHeaderCall, len(items))
+	for i, item := range items {
+		if err := json.Unmarshal([]byte(item), &calls[i]); err != nil {
+			return nil, err
+		}
+	}
+	return calls, nil
+}
+
+// SetInclusionConstraints stores the signed inclusion constraints of a slot, replacing previous ones
+func (r *RedisCache) SetInclusionConstraints(constraints *common.SignedInclusionConstraints) error {
+	return r.SetObj(r.keyInclusionConstraints(constraints.Message.Slot), constraints, expiryGetPayloadRequest)
+}
+
+// GetInclusionConstraints returns the signed inclusion constraints of a slot, or nil if there are none
+func (r *RedisCache) GetInclusionConstraints(slot uint64) (*common.SignedInclusionConstraints, error) {
+	constraints := new(common.SignedInclusionConstraints)
+	err := r.getReadonlyObj(r.keyInclusionConstraints(slot), constraints)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return constraints, err
+}
+
+// SavePreconfCommitment stores the preconfirmation commitment of a bid
+func (r *RedisCache) SavePreconfCommitment(commitment *common.PreconfCommitment) error {
+	marshalledValue, err := json.Marshal(commitment)
+	if err != nil {
+		return err
+	}
+	key := r.keyPreconfCommitments(commitment.Slot)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(context.Background(), key, commitment.BlockHash, marshalledValue)
+	pipe.Expire(context.Background(), key, expiryGetPayloadRequest)
+	_, err = pipe.Exec(context.Background())
+	return err
+}
+
+// GetPreconfCommitments returns the preconfirmation commitments of the bids of a slot, by block hash
+func (r *RedisCache) GetPreconfCommitments(slot uint64) (map[string]*common.PreconfCommitment, error) {
+	items, err := r.readonlyClient.HGetAll(context.Background(), r.keyPreconfCommitments(slot)).Result()
+	if err != nil {
+		return nil, err
+	}
+	commitments := make(map[string]*common.PreconfCommitment, len(items))
+	for blockHash, item := range items {
+		commitment := new(common.PreconfCommitment)
+		if err := json.Unmarshal([]byte(item), commitment); err != nil {
+			return nil, err
+		}
+		commitments[blockHash] = commitment
+	}
+	return commitments, nil
+}
+
+// SetUpstreamBid records that the bid with the given block hash was served in getHeader on behalf of an upstream relay,
+// to which getPayload requests for it are proxied
+func (r *RedisCache) SetUpstreamBid(slot uint64, blockHash, upstreamRelay string) error {
+	key := r.keyUpstreamBids(slot)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(context.Background(), key, blockHash, upstreamRelay)
+	pipe.Expire(context.Background(), key, expiryBid)
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+// GetUpstreamBid returns the upstream relay of a bid which was served in getHeader, or an empty string for local bids
+func (r *RedisCache) GetUpstreamBid(slot uint64, blockHash string) (string, error) {
+	upstreamRelay, err := r.client.HGet(context.Background(), r.keyUpstreamBids(slot), blockHash).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return upstreamRelay, err
+}
+
+// SavePendingPayload records a header-only bid whose payload was not received yet
+func (r *RedisCache) SavePendingPayload(pending *common.PendingPayload) error {
+	marshalledValue, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	key := r.keyPendingPayloads(pending.Slot)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(context.Background(), key, pending.BlockHash, marshalledValue)
+	pipe.Expire(context.Background(), key, expiryBid)
+	_, err = pipe.Exec(context.Background())
+	return err
+}
+
+// GetPendingPayload returns the header-only bid of a block whose payload was not received yet, or nil if there is none
+func (r *RedisCache) GetPendingPayload(slot uint64, blockHash string) (*common.PendingPayload, error) {
+	item, err := r.client.HGet(context.Background(), r.keyPendingPayloads(slot), blockHash).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	pending := new(common.PendingPayload)
+	err = json.Unmarshal([]byte(item), pending)
+	return pending, err
+}
+
+// GetPendingPayloads returns the header-only bids of a slot whose payload was not received yet
+func (r *RedisCache) GetPendingPayloads(slot uint64) ([]*common.PendingPayload, error) {
+	items, err := r.client.HGetAll(context.Background(), r.keyPendingPayloads(slot)).Result()
+	if err != nil {
+		return nil, err
+	}
+	pendingPayloads := make([]*common.PendingPayload, 0, len(items))
+	for _, item := range items {
+		pending := new(common.PendingPayload)
+		if err := json.Unmarshal([]byte(item), pending); err != nil {
+			return nil, err
+		}
+		pendingPayloads = append(pendingPayloads, pending)
+	}
+	return pendingPayloads, nil
+}
+
+// DelPendingPayload removes the pending header-only bid of a block. Returns whether it was still pending, so that only
+// one caller resolves or cancels it.
+func (r *RedisCache) DelPendingPayload(slot uint64, blockHash string) (bool, error) {
+	n, err := r.client.HDel(context.Background(), r.keyPendingPayloads(slot), blockHash).Result()
+	return n > 0, err
+}
+
+// IncSlotRequestCount increments the number of requests of a type (RedisSlotRequestField...) received for a slot
+func (r *RedisCache) IncSlotRequestCount(slot uint64, field string) error {
+	key := r.keySlotRequestCounts(slot)
+	pipe := r.client.TxPipeline()
+	pipe.HIncrBy(context.Background(), key, field, 1)
+	pipe.Expire(context.Background(), key, expiryGetPayloadRequest)
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+// GetSlotRequestCount returns the number of requests of a type (RedisSlotRequestField...) received for a slot
+func (r *RedisCache) GetSlotRequestCount(slot uint64, field string) (uint64, error) {
+	count, err := r.client.HGet(context.Background(), r.keySlotRequestCounts(slot), field).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// IncEpochCount adds n to a counter (RedisEpochCountField...) of an epoch
+func (r *RedisCache) IncEpochCount(epoch uint64, field string, n int64) error {
+	key := r.keyEpochCounts(epoch)
+	pipe := r.client.TxPipeline()
+	pipe.HIncrBy(context.Background(), key, field, n)
+	pipe.Expire(context.Background(), key, expiryGetPayloadRequest)
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+// GetEpochCount returns a counter (RedisEpochCountField...) of an epoch
+func (r *RedisCache) GetEpochCount(epoch uint64, field string) (uint64, error) {
+	count, err := r.client.HGet(context.Background(), r.keyEpochCounts(epoch), field).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// CheckAndSetGetPayloadRequest records the first getPayload request for a slot. Repeated requests for the
+// same block hash are allowed (retries), while a request for a different block hash returns the first
+// record together with ErrGetPayloadEquivocation.
+func (r *RedisCache) CheckAndSetGetPayloadRequest(slot uint64, blockHash string, signedBlindedBeaconBlock []byte) (first *GetPayloadRequestRecord, err error) {
+	record := &GetPayloadRequestRecord{
+		BlockHash:                blockHash,
+		SignedBlindedBeaconBlock: signedBlindedBeaconBlock,
+	}
+	marshalledRecord, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	key := r.keyGetPayloadRequest(slot)
+	wasSet, err := r.client.SetNX(context.Background(), key, marshalledRecord, expiryGetPayloadRequest).Result()
+	if err != nil {
+		return nil, err
+	} else if wasSet {
+		return record, nil
+	}
+
+	first = new(GetPayloadRequestRecord)
+	err = r.GetObj(key, first)
+	if err != nil {
+		return nil, err
+	}
+	if first.BlockHash != blockHash {
+		return first, ErrGetPayloadEquivocation
+	}
+	return first, nil
+}
+
 func (r *RedisCache) GetLastSlotDelivered(ctx context.Context, pipeliner redis.Pipeliner) (slot uint64, err error) {
 	c := pipeliner.Get(ctx, r.keyLastSlotDelivered)
 	_, err = pipeliner.Exec(ctx)
@@ -312,6 +764,11 @@ func (r *RedisCache) SetStats(field string, value any) (err error) {
 	return r.client.HSet(context.Background(), r.keyStats, field, value).Err()
 }
 
+// IncStats increments the numeric stats field by n
+func (r *RedisCache) IncStats(field string, n int64) (err error) {
+	return r.client.HIncrBy(context.Background(), r.keyStats, field, n).Err()
+}
+
 func (r *RedisCache) GetStats(field string) (value string, err error) {
 	return r.client.HGet(context.Background(), r.keyStats, field).Result()
 }
@@ -328,7 +785,7 @@ func (r *RedisCache) GetStatsUint64(field string) (value uint64, err error) {
 }
 
 func (r *RedisCache) SetProposerDuties(proposerDuties []common.BuilderGetValidatorsResponseEntry) (err error) {
-	return r.SetObj(r.keyProposerDuties, proposerDuties, 0)
+	return r.SetObj(r.keyProposerDuties, proposerDuties, expiryProposerDuties)
 }
 
 func (r *RedisCache) GetProposerDuties() (proposerDuties []common.BuilderGetValidatorsResponseEntry, err error) {
@@ -340,6 +797,54 @@ func (r *RedisCache) GetProposerDuties() (proposerDuties []common.BuilderGetVali
 	return proposerDuties, err
 }
 
+// SetKnownValidators stores the known validators (index -> pubkey). If replace is true, the previously stored validators
+// are removed, otherwise the given validators are added to them.
+func (r *RedisCache) SetKnownValidators(validators map[uint64]common.PubkeyHex, slot uint64, replace bool) error {
+	pipe := r.client.TxPipeline()
+	if replace {
+		pipe.Del(context.Background(), r.keyKnownValidators)
+	}
+	values := make([]any, 0, 2*knownValidatorsRedisBatchSize)
+	for index, pubkey := range validators {
+		values = append(values, strconv.FormatUint(index, 10), pubkey.String())
+		if len(values) >= 2*knownValidatorsRedisBatchSize {
+			pipe.HSet(context.Background(), r.keyKnownValidators, values...)
+			values = make([]any, 0, 2*knownValidatorsRedisBatchSize)
+		}
+	}
+	if len(values) > 0 {
+		pipe.HSet(context.Background(), r.keyKnownValidators, values...)
+	}
+	pipe.HSet(context.Background(), r.keyStats, RedisStatsFieldKnownValidatorsSlot, slot)
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+// GetKnownValidators returns the stored known validators (index -> pubkey), and the slot at which they were last updated
+func (r *RedisCache) GetKnownValidators() (validators map[uint64]common.PubkeyHex, slot uint64, err error) {
+	slot, err = r.GetStatsUint64(RedisStatsFieldKnownValidatorsSlot)
+	if errors.Is(err, redis.Nil) {
+		return map[uint64]common.PubkeyHex{}, 0, nil
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	res, err := r.client.HGetAll(context.Background(), r.keyKnownValidators).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	validators = make(map[uint64]common.PubkeyHex, len(res))
+	for indexStr, pubkey := range res {
+		index, err := strconv.ParseUint(indexStr, 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		validators[index] = common.PubkeyHex(pubkey)
+	}
+	return validators, slot, nil
+}
+
 func (r *RedisCache) SetRelayConfig(field, value string) (err error) {
 	return r.client.HSet(context.Background(), r.keyRelayConfig, field, value).Err()
 }
@@ -352,18 +857,126 @@ func (r *RedisCache) GetRelayConfig(field string) (string, error) {
 	return res, err
 }
 
+// SetProposerMinBids stores the minimum bid values (wei) of the proposers, replacing all previously stored values
+func (r *RedisCache) SetProposerMinBids(minBids map[string]string) error {
+	pipe := r.client.TxPipeline()
+	pipe.Del(context.Background(), r.keyProposerMinBid)
+	for proposerPubkey, value := range minBids {
+		pipe.HSet(context.Background(), r.keyProposerMinBid, proposerPubkey, value)
+	}
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+// SetProposerMinBid stores the minimum bid value (wei) of a proposer. A value of 0 removes it.
+func (r *RedisCache) SetProposerMinBid(proposerPubkey string, value *big.Int) error {
+	if value.Sign() == 0 {
+		return r.client.HDel(context.Background(), r.keyProposerMinBid, proposerPubkey).Err()
+	}
+	return r.client.HSet(context.Background(), r.keyProposerMinBid, proposerPubkey, value.String()).Err()
+}
+
+// GetProposerMinBid returns the minimum bid value (wei) of a proposer, or nil if it has none
+func (r *RedisCache) GetProposerMinBid(proposerPubkey string) (*big.Int, error) {
+	valueStr, err := r.readonlyClient.HGet(context.Background(), r.keyProposerMinBid, proposerPubkey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	value, ok := new(big.Int).SetString(valueStr, 10)
+	if !ok {
+		return nil, fmt.Errorf("could not set min bid value from %s", valueStr) //nolint:goerr113
+	}
+	return value, nil
+}
+
 func (r *RedisCache) GetBestBid(slot uint64, parentHash, proposerPubkey string) (*builderSpec.VersionedSignedBuilderBid, error) {
 	key := r.keyCacheGetHeaderResponse(slot, parentHash, proposerPubkey)
 	resp := new(builderSpec.VersionedSignedBuilderBid)
-	err := r.GetObj(key, resp)
+	err := r.getReadonlyObj(key, resp)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return resp, err
+}
+
+// GetBestBidOfBuilders returns the highest of the latest bids of the builders for which isAllowed returns true, or nil
+// if there is none
+func (r *RedisCache) GetBestBidOfBuilders(slot uint64, parentHash, proposerPubkey string, isAllowed func(builderPubkey string) bool) (*builderSpec.VersionedSignedBuilderBid, error) {
+	bidValues, err := r.readonlyClient.HGetAll(context.Background(), r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	builderBids := NewBuilderBids(bidValues)
+	for builderPubkey := range builderBids.bidValues {
+		if !isAllowed(builderPubkey) {
+			delete(builderBids.bidValues, builderPubkey)
+		}
+	}
+	topBidBuilder, _ := builderBids.getTopBid()
+	if topBidBuilder == "" {
+		return nil, nil
+	}
+
+	resp := new(builderSpec.VersionedSignedBuilderBid)
+	err = r.getReadonlyObj(r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, topBidBuilder), resp)
 	if errors.Is(err, redis.Nil) {
 		return nil, nil
 	}
 	return resp, err
 }
 
+// SetAllProposerBuilderPreferences stores the builder preferences of the proposers, replacing all previously stored ones
+func (r *RedisCache) SetAllProposerBuilderPreferences(preferences []*common.BuilderPreferences) error {
+	pipe := r.client.TxPipeline()
+	pipe.Del(context.Background(), r.keyProposerBuilderPreferences)
+	for _, p := range preferences {
+		if p.IsEmpty() {
+			continue
+		}
+		marshalledValue, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		pipe.HSet(context.Background(), r.keyProposerBuilderPreferences, p.Pubkey.String(), marshalledValue)
+	}
+	_, err := pipe.Exec(context.Background())
+	return err
+}
+
+// SetProposerBuilderPreferences stores the builder preferences of a proposer. Empty preferences remove them.
+func (r *RedisCache) SetProposerBuilderPreferences(preferences *common.BuilderPreferences) error {
+	if preferences.IsEmpty() {
+		return r.client.HDel(context.Background(), r.keyProposerBuilderPreferences, preferences.Pubkey.String()).Err()
+	}
+	marshalledValue, err := json.Marshal(preferences)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(context.Background(), r.keyProposerBuilderPreferences, preferences.Pubkey.String(), marshalledValue).Err()
+}
+
+// GetProposerBuilderPreferences returns the builder preferences of a proposer, or nil if it has none
+func (r *RedisCache) GetProposerBuilderPreferences(proposerPubkey string) (*common.BuilderPreferences, error) {
+	value, err := r.readonlyClient.HGet(context.Background(), r.keyProposerBuilderPreferences, proposerPubkey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	preferences := new(common.BuilderPreferences)
+	err = json.Unmarshal([]byte(value), preferences)
+	return preferences, err
+}
+
 func (r *RedisCache) GetPayloadContents(slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error) {
-	resp, err := r.GetPayloadContentsDeneb(slot, proposerPubkey, blockHash)
+	resp, err := r.GetPayloadContentsElectra(slot, proposerPubkey, blockHash)
+	if errors.Is(err, redis.Nil) {
+		// can't find electra payload, try find deneb payload
+		resp, err = r.GetPayloadContentsDeneb(slot, proposerPubkey, blockHash)
+	}
 	if errors.Is(err, redis.Nil) {
 		// can't find deneb payload, try find capella payload
 		return r.GetExecutionPayloadCapella(slot, proposerPubkey, blockHash)
@@ -371,13 +984,44 @@ func (r *RedisCache) GetPayloadContents(slot uint64, proposerPubkey, blockHash s
 	return resp, err
 }
 
+// SavePayloadContentsElectra saves the execution payload and blobs bundle of an electra block, which are the same as
+// for deneb. The execution requests are part of the blinded block, and are checked against the bid trace.
+func (r *RedisCache) SavePayloadContentsElectra(ctx context.Context, tx redis.Pipeliner, slot uint64, proposerPubkey, blockHash string, execPayload *builderApiDeneb.ExecutionPayloadAndBlobsBundle) (err error) {
+	key := r.keyPayloadContentsElectra(slot, proposerPubkey, blockHash)
+	b, err := execPayload.MarshalSSZ()
+	if err != nil {
+		return err
+	}
+	return tx.Set(ctx, key, b, expiryPayload).Err()
+}
+
+func (r *RedisCache) GetPayloadContentsElectra(slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error) {
+	electraPayloadContents := new(builderApiDeneb.ExecutionPayloadAndBlobsBundle)
+
+	key := r.keyPayloadContentsElectra(slot, proposerPubkey, blockHash)
+	val, err := r.client.Get(context.Background(), key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	err = electraPayloadContents.UnmarshalSSZ([]byte(val))
+	if err != nil {
+		return nil, err
+	}
+
+	return &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionElectra,
+		Electra: electraPayloadContents,
+	}, nil
+}
+
 func (r *RedisCache) SavePayloadContentsDeneb(ctx context.Context, tx redis.Pipeliner, slot uint64, proposerPubkey, blockHash string, execPayload *builderApiDeneb.ExecutionPayloadAndBlobsBundle) (err error) {
 	key := r.keyPayloadContentsDeneb(slot, proposerPubkey, blockHash)
 	b, err := execPayload.MarshalSSZ()
 	if err != nil {
 		return err
 	}
-	return tx.Set(ctx, key, b, expiryBidCache).Err()
+	return tx.Set(ctx, key, b, expiryPayload).Err()
 }
 
 func (r *RedisCache) GetPayloadContentsDeneb(slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error) {
@@ -406,7 +1050,7 @@ func (r *RedisCache) SaveExecutionPayloadCapella(ctx context.Context, pipeliner
 	if err != nil {
 		return err
 	}
-	return pipeliner.Set(ctx, key, b, expiryBidCache).Err()
+	return pipeliner.Set(ctx, key, b, expiryPayload).Err()
 }
 
 func (r *RedisCache) GetExecutionPayloadCapella(slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, error) {
@@ -431,7 +1075,7 @@ func (r *RedisCache) GetExecutionPayloadCapella(slot uint64, proposerPubkey, blo
 
 func (r *RedisCache) SaveBidTrace(ctx context.Context, pipeliner redis.Pipeliner, trace *common.BidTraceV2WithBlobFields) (err error) {
 	key := r.keyCacheBidTrace(trace.Slot, trace.ProposerPubkey.String(), trace.BlockHash.String())
-	return r.SetObjPipelined(ctx, pipeliner, key, trace, expiryBidCache)
+	return r.SetObjPipelined(ctx, pipeliner, key, trace, expiryPayload)
 }
 
 // GetBidTrace returns (trace, nil), or (nil, redis.Nil) if the trace does not exist
@@ -458,7 +1102,7 @@ func (r *RedisCache) GetBuilderLatestPayloadReceivedAt(ctx context.Context, pipe
 func (r *RedisCache) SaveBuilderBid(ctx context.Context, pipeliner redis.Pipeliner, slot uint64, parentHash, proposerPubkey, builderPubkey string, receivedAt time.Time, headerResp *builderSpec.VersionedSignedBuilderBid) (err error) {
 	// save the actual bid
 	keyLatestBid := r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, builderPubkey)
-	err = r.SetObjPipelined(ctx, pipeliner, keyLatestBid, headerResp, expiryBidCache)
+	err = r.SetObjPipelined(ctx, pipeliner, keyLatestBid, headerResp, expiryBid)
 	if err != nil {
 		return err
 	}
@@ -469,7 +1113,7 @@ func (r *RedisCache) SaveBuilderBid(ctx context.Context, pipeliner redis.Pipelin
 	if err != nil {
 		return err
 	}
-	err = pipeliner.Expire(ctx, keyLatestBidsTime, expiryBidCache).Err()
+	err = pipeliner.Expire(ctx, keyLatestBidsTime, expiryBid).Err()
 	if err != nil {
 		return err
 	}
@@ -484,7 +1128,7 @@ func (r *RedisCache) SaveBuilderBid(ctx context.Context, pipeliner redis.Pipelin
 	if err != nil {
 		return err
 	}
-	return pipeliner.Expire(ctx, keyLatestBidsValue, expiryBidCache).Err()
+	return pipeliner.Expire(ctx, keyLatestBidsValue, expiryBid).Err()
 }
 
 type SaveBidAndUpdateTopBidResponse struct {
@@ -495,14 +1139,15 @@ type SaveBidAndUpdateTopBidResponse struct {
 	TopBidValue     *big.Int
 	PrevTopBidValue *big.Int
 
-	TimePrep         time.Duration
-	TimeSavePayload  time.Duration
-	TimeSaveBid      time.Duration
-	TimeSaveTrace    time.Duration
-	TimeUpdateTopBid time.Duration
-	TimeUpdateFloor  time.Duration
+	TimePrep         time.Duration // loading the latest bids and the floor value
+	TimeSavePayload  time.Duration // encoding the payload
+	TimeSaveBid      time.Duration // encoding the bid
+	TimeSaveTrace    time.Duration // encoding the bid trace
+	TimeUpdateTopBid time.Duration // saving the payload, bid and trace, and updating the top bid and floor bid
 }
 
+// SaveBidAndUpdateTopBid saves the payload, bid and bid trace of a block submission, and updates the top bid and the
+// floor bid, in two round trips: one to load the latest bids and the floor value, and one to execute all writes.
 func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, pipeliner redis.Pipeliner, trace *common.BidTraceV2WithBlobFields, payload *common.VersionedSubmitBlockRequest, getPayloadResponse *builderApi.VersionedSubmitBlindedBlockResponse, getHeaderResponse *builderSpec.VersionedSignedBuilderBid, reqReceivedAt time.Time, isCancellationEnabled bool, floorValue *big.Int) (state SaveBidAndUpdateTopBidResponse, err error) {
 	var prevTime, nextTime time.Time
 	prevTime = time.Now()
@@ -511,21 +1156,14 @@ func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, pipeliner redis
 	if err != nil {
 		return state, err
 	}
+	slot, parentHash, proposerPubkey, builderPubkey := submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String(), submission.BidTrace.BuilderPubkey.String()
 
-	// Load latest bids for a given slot+parent+proposer
-	builderBids, err := NewBuilderBidsFromRedis(ctx, r, pipeliner, submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String())
+	// Load latest bids for a given slot+parent+proposer, and the floor value (if not passed in already)
+	builderBids, floorValue, err := r.getBuilderBidsAndFloorValue(ctx, pipeliner, slot, parentHash, proposerPubkey, floorValue)
 	if err != nil {
 		return state, err
 	}
 
-	// Load floor value (if not passed in already)
-	if floorValue == nil {
-		floorValue, err = r.GetFloorBidValue(ctx, pipeliner, submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String())
-		if err != nil {
-			return state, err
-		}
-	}
-
 	// Get the reference top bid value
 	_, state.TopBidValue = builderBids.getTopBid()
 	if floorValue.Cmp(state.TopBidValue) == 1 {
@@ -539,23 +1177,37 @@ func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, pipeliner redis
 		return state, nil
 	}
 
+	// If top bid value doesn't change, abort now. Unless the top bid is by this builder, as it then has to be replaced
+	// even at the same value (i.e. the payload submission of a header-only bid)
+	builderBids.bidValues[builderPubkey] = submission.BidTrace.Value.ToBig()
+	topBidBuilder, topBidValue := builderBids.getTopBid()
+	if topBidValue.Cmp(state.PrevTopBidValue) == 0 && topBidBuilder != builderPubkey {
+		state.TopBidValue = topBidValue
+		return state, nil
+	}
+
 	// Record time needed
 	nextTime = time.Now().UTC()
 	state.TimePrep = nextTime.Sub(prevTime)
 	prevTime = nextTime
 
 	//
-	// Time to save things in Redis
+	// Time to save things in Redis: all writes are queued, and executed together with the top bid update
 	//
 	// 1. Save the execution payload
 	switch payload.Version {
 	case spec.DataVersionCapella:
-		err = r.SaveExecutionPayloadCapella(ctx, pipeliner, submission.BidTrace.Slot, submission.BidTrace.ProposerPubkey.String(), submission.BidTrace.BlockHash.String(), getPayloadResponse.Capella)
+		err = r.SaveExecutionPayloadCapella(ctx, pipeliner, slot, proposerPubkey, submission.BidTrace.BlockHash.String(), getPayloadResponse.Capella)
 		if err != nil {
 			return state, err
 		}
 	case spec.DataVersionDeneb:
-		err = r.SavePayloadContentsDeneb(ctx, pipeliner, submission.BidTrace.Slot, submission.BidTrace.ProposerPubkey.String(), submission.BidTrace.BlockHash.String(), getPayloadResponse.Deneb)
+		err = r.SavePayloadContentsDeneb(ctx, pipeliner, slot, proposerPubkey, submission.BidTrace.BlockHash.String(), getPayloadResponse.Deneb)
+		if err != nil {
+			return state, err
+		}
+	case spec.DataVersionElectra:
+		err = r.SavePayloadContentsElectra(ctx, pipeliner, slot, proposerPubkey, submission.BidTrace.BlockHash.String(), getPayloadResponse.Electra)
 		if err != nil {
 			return state, err
 		}
@@ -569,11 +1221,10 @@ func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, pipeliner redis
 	prevTime = nextTime
 
 	// 2. Save latest bid for this builder
-	err = r.SaveBuilderBid(ctx, pipeliner, submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String(), submission.BidTrace.BuilderPubkey.String(), reqReceivedAt, getHeaderResponse)
+	err = r.SaveBuilderBid(ctx, pipeliner, slot, parentHash, proposerPubkey, builderPubkey, reqReceivedAt, getHeaderResponse)
 	if err != nil {
 		return state, err
 	}
-	builderBids.bidValues[submission.BidTrace.BuilderPubkey.String()] = submission.BidTrace.Value.ToBig()
 
 	// Record time needed to save bid
 	nextTime = time.Now().UTC()
@@ -591,88 +1242,113 @@ func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, pipeliner redis
 	state.TimeSaveTrace = nextTime.Sub(prevTime)
 	prevTime = nextTime
 
-	// If top bid value hasn't change, abort now
-	_, state.TopBidValue = builderBids.getTopBid()
-	if state.TopBidValue.Cmp(state.PrevTopBidValue) == 0 {
-		return state, nil
+	// 4. Update the top bid
+	state, cTopBid, keyTopBidSource := r._queueTopBidUpdate(ctx, pipeliner, state, builderBids, slot, parentHash, proposerPubkey, floorValue)
+
+	// 5. Non-cancellable bid above floor should set new floor
+	var cFloorBid *redis.IntCmd
+	keyBidSource := r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, builderPubkey)
+	keyFloorBid := r.keyFloorBid(slot, parentHash, proposerPubkey)
+	if !isCancellationEnabled && isBidAboveFloor {
+		cFloorBid = pipeliner.Copy(ctx, keyBidSource, keyFloorBid, 0, true)
+		pipeliner.Expire(ctx, keyFloorBid, expiryBid)
+		pipeliner.Set(ctx, r.keyFloorBidValue(slot, parentHash, proposerPubkey), submission.BidTrace.Value.Dec(), expiryBid)
 	}
 
-	state, err = r._updateTopBid(ctx, pipeliner, state, builderBids, submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String(), floorValue)
+	// Execute all writes
+	_, err = pipeliner.Exec(ctx)
 	if err != nil {
 		return state, err
 	}
+	if err = checkCopied(cTopBid, keyTopBidSource, "top bid"); err != nil {
+		return state, err
+	}
+	if cFloorBid != nil {
+		if err = checkCopied(cFloorBid, keyBidSource, "floor bid"); err != nil {
+			return state, err
+		}
+	}
 	state.IsNewTopBid = submission.BidTrace.Value.ToBig().Cmp(state.TopBidValue) == 0
-	// An Exec happens in _updateTopBid.
 	state.WasBidSaved = true
 
-	// Record time needed to update top bid
+	// Record time needed to save everything and update the top bid
 	nextTime = time.Now().UTC()
 	state.TimeUpdateTopBid = nextTime.Sub(prevTime)
-	prevTime = nextTime
 
-	if isCancellationEnabled || !isBidAboveFloor {
-		return state, nil
+	return state, nil
+}
+
+// getBuilderBidsAndFloorValue loads the latest bids for a given slot+parent+proposer, and the floor value (if not passed
+// in already), in a single round trip
+func (r *RedisCache) getBuilderBidsAndFloorValue(ctx context.Context, pipeliner redis.Pipeliner, slot uint64, parentHash, proposerPubkey string, floorValue *big.Int) (*BuilderBids, *big.Int, error) {
+	cBidValues := pipeliner.HGetAll(ctx, r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey))
+	var cFloorValue *redis.StringCmd
+	if floorValue == nil {
+		cFloorValue = pipeliner.Get(ctx, r.keyFloorBidValue(slot, parentHash, proposerPubkey))
+	}
+	_, err := pipeliner.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, nil, err
 	}
 
-	// Non-cancellable bid above floor should set new floor
-	keyBidSource := r.keyLatestBidByBuilder(submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String(), submission.BidTrace.BuilderPubkey.String())
-	keyFloorBid := r.keyFloorBid(submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String())
-	c := pipeliner.Copy(ctx, keyBidSource, keyFloorBid, 0, true)
-	_, err = pipeliner.Exec(ctx)
+	bidValueMap, err := cBidValues.Result()
 	if err != nil {
-		return state, err
+		return nil, nil, err
 	}
 
-	wasCopied, copyErr := c.Result()
-	if copyErr != nil {
-		return state, copyErr
-	} else if wasCopied == 0 {
-		return state, fmt.Errorf("could not copy floor bid from %s to %s", keyBidSource, keyFloorBid) //nolint:goerr113
-	}
-	err = pipeliner.Expire(ctx, keyFloorBid, expiryBidCache).Err()
-	if err != nil {
-		return state, err
+	if cFloorValue != nil {
+		floorValue = big.NewInt(0)
+		floorValueStr, err := cFloorValue.Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, nil, err
+		} else if err == nil {
+			floorValue.SetString(floorValueStr, 10)
+		}
 	}
+	return NewBuilderBids(bidValueMap), floorValue, nil
+}
 
-	keyFloorBidValue := r.keyFloorBidValue(submission.BidTrace.Slot, submission.BidTrace.ParentHash.String(), submission.BidTrace.ProposerPubkey.String())
-	err = pipeliner.Set(ctx, keyFloorBidValue, submission.BidTrace.Value.Dec(), expiryBidCache).Err()
+// checkCopied returns an error if a bid wasn't copied, i.e. because the source key doesn't exist
+func checkCopied(c *redis.IntCmd, keySource, name string) error {
+	wasCopied, err := c.Result()
 	if err != nil {
-		return state, err
+		return err
+	} else if wasCopied == 0 {
+		return fmt.Errorf("could not copy %s from %s", name, keySource) //nolint:goerr113
 	}
-
-	// Execute setting the floor bid
-	_, err = pipeliner.Exec(ctx)
-
-	// Record time needed to update floor
-	nextTime = time.Now().UTC()
-	state.TimeUpdateFloor = nextTime.Sub(prevTime)
-
-	return state, err
+	return nil
 }
 
 func (r *RedisCache) _updateTopBid(ctx context.Context, pipeliner redis.Pipeliner, state SaveBidAndUpdateTopBidResponse, builderBids *BuilderBids, slot uint64, parentHash, proposerPubkey string, floorValue *big.Int) (resp SaveBidAndUpdateTopBidResponse, err error) {
-	if builderBids == nil {
-		builderBids, err = NewBuilderBidsFromRedis(ctx, r, pipeliner, slot, parentHash, proposerPubkey)
+	if builderBids == nil || floorValue == nil {
+		var loadedBids *BuilderBids
+		loadedBids, floorValue, err = r.getBuilderBidsAndFloorValue(ctx, pipeliner, slot, parentHash, proposerPubkey, floorValue)
 		if err != nil {
 			return state, err
 		}
+		if builderBids == nil {
+			builderBids = loadedBids
+		}
 	}
 
 	if len(builderBids.bidValues) == 0 {
 		return state, nil
 	}
 
-	// Load floor value (if not passed in already)
-	if floorValue == nil {
-		floorValue, err = r.GetFloorBidValue(ctx, pipeliner, slot, parentHash, proposerPubkey)
-		if err != nil {
-			return state, err
-		}
+	state, c, keyBidSource := r._queueTopBidUpdate(ctx, pipeliner, state, builderBids, slot, parentHash, proposerPubkey, floorValue)
+	_, err = pipeliner.Exec(ctx)
+	if err != nil {
+		return state, err
 	}
+	return state, checkCopied(c, keyBidSource, "top bid")
+}
 
+// _queueTopBidUpdate queues copying the top bid (or the floor bid, if higher) to the getHeader response, and setting the
+// top bid value. The returned copy command has to be checked once the pipeline was executed.
+func (r *RedisCache) _queueTopBidUpdate(ctx context.Context, pipeliner redis.Pipeliner, state SaveBidAndUpdateTopBidResponse, builderBids *BuilderBids, slot uint64, parentHash, proposerPubkey string, floorValue *big.Int) (resp SaveBidAndUpdateTopBidResponse, c *redis.IntCmd, keyBidSource string) {
 	topBidBuilder := ""
 	topBidBuilder, state.TopBidValue = builderBids.getTopBid()
-	keyBidSource := r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, topBidBuilder)
+	keyBidSource = r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, topBidBuilder)
 
 	// If floor value is higher than this bid, use floor bid instead
 	if floorValue.Cmp(state.TopBidValue) == 1 {
@@ -682,33 +1358,14 @@ func (r *RedisCache) _updateTopBid(ctx context.Context, pipeliner redis.Pipeline
 
 	// Copy winning bid to top bid cache
 	keyTopBid := r.keyCacheGetHeaderResponse(slot, parentHash, proposerPubkey)
-	c := pipeliner.Copy(context.Background(), keyBidSource, keyTopBid, 0, true)
-	_, err = pipeliner.Exec(ctx)
-	if err != nil {
-		return state, err
-	}
-	wasCopied, err := c.Result()
-	if err != nil {
-		return state, err
-	} else if wasCopied == 0 {
-		return state, fmt.Errorf("could not copy top bid from %s to %s", keyBidSource, keyTopBid) //nolint:goerr113
-	}
-	err = pipeliner.Expire(context.Background(), keyTopBid, expiryBidCache).Err()
-	if err != nil {
-		return state, err
-	}
+	c = pipeliner.Copy(ctx, keyBidSource, keyTopBid, 0, true)
+	pipeliner.Expire(ctx, keyTopBid, expiryBid)
 
 	state.WasTopBidUpdated = state.PrevTopBidValue == nil || state.PrevTopBidValue.Cmp(state.TopBidValue) != 0
 
-	// 6. Finally, update the global top bid value
-	keyTopBidValue := r.keyTopBidValue(slot, parentHash, proposerPubkey)
-	err = pipeliner.Set(context.Background(), keyTopBidValue, state.TopBidValue.String(), expiryBidCache).Err()
-	if err != nil {
-		return state, err
-	}
-
-	_, err = pipeliner.Exec(ctx)
-	return state, err
+	// Finally, update the global top bid value
+	pipeliner.Set(ctx, r.keyTopBidValue(slot, parentHash, proposerPubkey), state.TopBidValue.String(), expiryBid)
+	return state, c, keyBidSource
 }
 
 // GetTopBidValue gets the top bid value for a given slot+parent+proposer combination
@@ -773,6 +1430,131 @@ func (r *RedisCache) DelBuilderBid(ctx context.Context, pipeliner redis.Pipeline
 	return err
 }
 
+// SaveHeaderBidAndUpdateTopBid saves the bid of a header-only submission and updates the top bid. The payload is saved
+// once it's submitted. Header-only bids are always cancellable, so they never set the floor bid.
+func (r *RedisCache) SaveHeaderBidAndUpdateTopBid(ctx context.Context, pipeliner redis.Pipeliner, trace *common.BidTraceV2WithBlobFields, getHeaderResponse *builderSpec.VersionedSignedBuilderBid, reqReceivedAt time.Time, floorValue *big.Int) (state SaveBidAndUpdateTopBidResponse, err error) {
+	slot, parentHash, proposerPubkey, builderPubkey := trace.Slot, trace.ParentHash.String(), trace.ProposerPubkey.String(), trace.BuilderPubkey.String()
+
+	// Load latest bids for a given slot+parent+proposer, and the floor value (if not passed in already)
+	builderBids, floorValue, err := r.getBuilderBidsAndFloorValue(ctx, pipeliner, slot, parentHash, proposerPubkey, floorValue)
+	if err != nil {
+		return state, err
+	}
+
+	// Get the reference top bid value
+	_, state.TopBidValue = builderBids.getTopBid()
+	if floorValue.Cmp(state.TopBidValue) == 1 {
+		state.TopBidValue = floorValue
+	}
+	state.PrevTopBidValue = state.TopBidValue
+
+	err = r.SaveBuilderBid(ctx, pipeliner, slot, parentHash, proposerPubkey, builderPubkey, reqReceivedAt, getHeaderResponse)
+	if err != nil {
+		return state, err
+	}
+	builderBids.bidValues[builderPubkey] = trace.Value.ToBig()
+
+	err = r.SaveBidTrace(ctx, pipeliner, trace)
+	if err != nil {
+		return state, err
+	}
+
+	// Always update the top bid, as the previous bid of the builder might have been the top bid
+	state, err = r._updateTopBid(ctx, pipeliner, state, builderBids, slot, parentHash, proposerPubkey, floorValue)
+	if err != nil {
+		return state, err
+	}
+	state.IsNewTopBid = trace.Value.ToBig().Cmp(state.TopBidValue) == 0
+	state.WasBidSaved = true
+	return state, nil
+}
+
+// DelHeaderBid removes the header-only bid of a pending payload if it's still the latest bid of the builder, and updates
+// the top bid. Returns whether the bid was removed.
+func (r *RedisCache) DelHeaderBid(ctx context.Context, pipeliner redis.Pipeliner, pending *common.PendingPayload) (bool, error) {
+	latestBid := new(builderSpec.VersionedSignedBuilderBid)
+	err := r.GetObj(r.keyLatestBidByBuilder(pending.Slot, pending.ParentHash, pending.ProposerPubkey, pending.BuilderPubkey), latestBid)
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if latestBlockHash, err := latestBid.BlockHash(); err != nil || latestBlockHash.String() != pending.BlockHash {
+		return false, err // the builder submitted a newer bid
+	}
+
+	err = r.DelBuilderBid(ctx, pipeliner, pending.Slot, pending.ParentHash, pending.ProposerPubkey, pending.BuilderPubkey)
+	if err != nil {
+		return false, err
+	}
+
+	// Without any other bids the top bid is not recomputed, so it has to be removed if it's still the header-only bid
+	keyTopBid := r.keyCacheGetHeaderResponse(pending.Slot, pending.ParentHash, pending.ProposerPubkey)
+	topBid := new(builderSpec.VersionedSignedBuilderBid)
+	err = r.GetObj(keyTopBid, topBid)
+	if errors.Is(err, redis.Nil) {
+		return true, nil
+	} else if err != nil {
+		return true, err
+	}
+	if topBlockHash, err := topBid.BlockHash(); err != nil || topBlockHash.String() != pending.BlockHash {
+		return true, err
+	}
+	keyTopBidValue := r.keyTopBidValue(pending.Slot, pending.ParentHash, pending.ProposerPubkey)
+	return true, r.client.Del(ctx, keyTopBid, keyTopBidValue).Err()
+}
+
+// InvalidateBids removes all bids for a given slot+parent+proposer combination, together with the payloads and bid
+// traces of their blocks, so that none of them can be served anymore. Returns the block hashes of the removed bids.
+func (r *RedisCache) InvalidateBids(slot uint64, parentHash, proposerPubkey string) (blockHashes []string, err error) {
+	ctx := context.Background()
+	keyLatestValue := r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey)
+	builderPubkeys, err := r.client.HKeys(ctx, keyLatestValue).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	keys := []string{
+		r.keyCacheGetHeaderResponse(slot, parentHash, proposerPubkey),
+		keyLatestValue,
+		r.keyBlockBuilderLatestBidsTime(slot, parentHash, proposerPubkey),
+		r.keyTopBidValue(slot, parentHash, proposerPubkey),
+		r.keyFloorBid(slot, parentHash, proposerPubkey),
+		r.keyFloorBidValue(slot, parentHash, proposerPubkey),
+	}
+	for _, builderPubkey := range builderPubkeys {
+		keyLatestBid := r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, builderPubkey)
+		keys = append(keys, keyLatestBid)
+
+		bid := new(builderSpec.VersionedSignedBuilderBid)
+		err = r.GetObj(keyLatestBid, bid)
+		if errors.Is(err, redis.Nil) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		blockHash, err := bid.BlockHash()
+		if err != nil {
+			return nil, err
+		}
+		blockHashes = append(blockHashes, blockHash.String())
+		keys = append(keys,
+			r.keyExecPayloadCapella(slot, proposerPubkey, blockHash.String()),
+			r.keyPayloadContentsDeneb(slot, proposerPubkey, blockHash.String()),
+			r.keyPayloadContentsElectra(slot, proposerPubkey, blockHash.String()),
+			r.keyCacheBidTrace(slot, proposerPubkey, blockHash.String()),
+		)
+	}
+
+	// keys are deleted one by one, as they may be spread over several cluster nodes
+	pipe := r.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	_, err = pipe.Exec(ctx)
+	return blockHashes, err
+}
+
 // GetFloorBidValue returns the value of the highest non-cancellable bid
 func (r *RedisCache) GetFloorBidValue(ctx context.Context, pipeliner redis.Pipeliner, slot uint64, parentHash, proposerPubkey string) (floorValue *big.Int, err error) {
 	keyFloorBidValue := r.keyFloorBidValue(slot, parentHash, proposerPubkey)
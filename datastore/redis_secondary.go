@@ -0,0 +1,87 @@
+package datastore
+
+import (
+	"errors"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+// redisSecondaryBackend is a secondary Redis, typically in another region, holding copies of the execution payloads and
+// bid traces. They are written to it in the background after the primary Redis, and getPayload reads from the primary
+// and all secondaries at once, so that a regional Redis failure during the slot doesn't cause a missed block.
+//
+// Validator registration timestamps and the state shared by the relay instances are only kept in the primary Redis.
+type redisSecondaryBackend struct {
+	redisBackend
+	name string
+}
+
+// NewRedisSecondaryBackend returns a cache backend for a secondary Redis, to be added with AddCacheBackend. The name
+// identifies it in the logs and health checks, i.e. "redis-eu"
+func NewRedisSecondaryBackend(name string, r *RedisCache) CacheBackend {
+	return &redisSecondaryBackend{redisBackend: redisBackend{r}, name: name}
+}
+
+func (b *redisSecondaryBackend) Name() string {
+	return b.name
+}
+
+func (b *redisSecondaryBackend) Close() error {
+	return b.r.Close()
+}
+
+func (b *redisSecondaryBackend) GetValidatorRegistrationTimestamp(proposerPubkey common.PubkeyHex) (uint64, error) {
+	return 0, nil
+}
+
+func (b *redisSecondaryBackend) SetValidatorRegistrationTimestampIfNewer(proposerPubkey common.PubkeyHex, timestamp uint64) error {
+	return nil
+}
+
+// isRedisBackend returns whether the backend is the primary Redis or a secondary Redis
+func isRedisBackend(backend CacheBackend) bool {
+	switch backend.(type) {
+	case *redisBackend, *redisSecondaryBackend:
+		return true
+	}
+	return false
+}
+
+// getExecutionPayloadFirst reads the execution payload from all the backends at once, and returns the first one found
+// together with the backend which served it
+func getExecutionPayloadFirst(log *logrus.Entry, backends []CacheBackend, slot uint64, proposerPubkey, blockHash string) (*builderApi.VersionedSubmitBlindedBlockResponse, CacheBackend) {
+	type result struct {
+		backend CacheBackend
+		resp    *builderApi.VersionedSubmitBlindedBlockResponse
+		err     error
+	}
+
+	// buffered, so the slower backends don't block once a payload is found
+	results := make(chan result, len(backends))
+	for _, backend := range backends {
+		go func(backend CacheBackend) {
+			resp, err := backend.GetExecutionPayload(slot, proposerPubkey, blockHash)
+			results <- result{backend: backend, resp: resp, err: err}
+		}(backend)
+	}
+
+	for range backends {
+		res := <-results
+		if res.err == nil && res.resp != nil {
+			return res.resp, res.backend
+		}
+		logExecutionPayloadMiss(log, res.backend, res.err)
+	}
+	return nil, nil
+}
+
+// logExecutionPayloadMiss logs a backend which didn't return the execution payload
+func logExecutionPayloadMiss(log *logrus.Entry, backend CacheBackend, err error) {
+	if err == nil || errors.Is(err, ErrCacheMiss) {
+		log.WithError(err).Warnf("execution payload not found in %s", backend.Name())
+	} else {
+		log.WithError(err).Errorf("error getting execution payload from %s", backend.Name())
+	}
+}
@@ -3,12 +3,16 @@ package datastore
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	builderApi "github.com/attestantio/go-builder-client/api"
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	builderSpec "github.com/attestantio/go-builder-client/spec"
@@ -21,7 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func setupTestRedis(t *testing.T) *RedisCache {
+func setupTestRedis(t testing.TB) *RedisCache {
 	t.Helper()
 	var err error
 
@@ -113,16 +117,80 @@ func TestRedisProposerDuties(t *testing.T) {
 	require.Equal(t, duties[0].Entry.Message.FeeRecipient, duties2[0].Entry.Message.FeeRecipient)
 }
 
+func TestRedisKnownValidators(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	// Nothing stored yet
+	validators, slot, err := cache.GetKnownValidators()
+	require.NoError(t, err)
+	require.Empty(t, validators)
+	require.Equal(t, uint64(0), slot)
+
+	err = cache.SetKnownValidators(map[uint64]common.PubkeyHex{1: "0x01", 2: "0x02"}, 100, true)
+	require.NoError(t, err)
+
+	// Incremental update adds to the stored validators
+	err = cache.SetKnownValidators(map[uint64]common.PubkeyHex{3: "0x03"}, 110, false)
+	require.NoError(t, err)
+	validators, slot, err = cache.GetKnownValidators()
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]common.PubkeyHex{1: "0x01", 2: "0x02", 3: "0x03"}, validators)
+	require.Equal(t, uint64(110), slot)
+
+	// Full update replaces the stored validators
+	err = cache.SetKnownValidators(map[uint64]common.PubkeyHex{4: "0x04"}, 120, true)
+	require.NoError(t, err)
+	validators, slot, err = cache.GetKnownValidators()
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]common.PubkeyHex{4: "0x04"}, validators)
+	require.Equal(t, uint64(120), slot)
+}
+
+func TestRedisProposerMinBid(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	// Nothing stored yet
+	value, err := cache.GetProposerMinBid("0x01")
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	err = cache.SetProposerMinBid("0x01", big.NewInt(1000))
+	require.NoError(t, err)
+	value, err = cache.GetProposerMinBid("0x01")
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000), value)
+
+	// A value of 0 removes the min bid
+	err = cache.SetProposerMinBid("0x01", big.NewInt(0))
+	require.NoError(t, err)
+	value, err = cache.GetProposerMinBid("0x01")
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	// Bulk update replaces the stored values
+	err = cache.SetProposerMinBid("0x01", big.NewInt(1000))
+	require.NoError(t, err)
+	err = cache.SetProposerMinBids(map[string]string{"0x02": "2000"})
+	require.NoError(t, err)
+	value, err = cache.GetProposerMinBid("0x01")
+	require.NoError(t, err)
+	require.Nil(t, value)
+	value, err = cache.GetProposerMinBid("0x02")
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(2000), value)
+}
+
 func TestBuilderBids(t *testing.T) {
 	versions := []spec.DataVersion{
 		spec.DataVersionCapella,
 		spec.DataVersionDeneb,
+		spec.DataVersionElectra,
 	}
 
 	for _, version := range versions {
 		slot := uint64(2)
 		parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
-		proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+		proposerPubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
 		opts := common.CreateTestBlockSubmissionOpts{
 			Slot:           2,
 			ParentHash:     parentHash,
@@ -142,8 +210,8 @@ func TestBuilderBids(t *testing.T) {
 		//
 		// test 1: ba1=10 -> ba2=5 -> ba3c=5 -> bb1=20 -> ba4c=3 -> bb2c=2
 		//
-		bApubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
-		bBpubkey := "0x2e02be2c9f9eccf9856478fdb7876598fed2da09f45c233969ba647a250231150ecf38bce5771adb6171c86b79a92f16"
+		bApubkey := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+		bBpubkey := "0x8e11b7a25502292572cb19aae26b4ea7bab862f4b2667c58ae6e124808b67ab2ca3eef5311c07330c018851228c26563"
 
 		// Setup redis instance
 		cache := setupTestRedis(t)
@@ -282,6 +350,40 @@ func TestRedisURIs(t *testing.T) {
 	malformURL = "redis://" + username + ":" + "wrongpass" + "@" + redisTestServer.Addr()
 	_, err = NewRedisCache("", malformURL, "")
 	require.Error(t, err)
+
+	// credentials as options
+	_, err = NewRedisCacheWithOpts("", RedisOpts{URI: redisTestServer.Addr(), Username: username, Password: password, PoolSize: 5})
+	require.NoError(t, err)
+	_, err = NewRedisCacheWithOpts("", RedisOpts{URI: redisTestServer.Addr(), Username: username, Password: "wrongpass"})
+	require.Error(t, err)
+
+	// invalid modes
+	_, err = NewRedisCacheWithOpts("", RedisOpts{Mode: "foo", URI: redisTestServer.Addr()})
+	require.ErrorIs(t, err, ErrInvalidRedisMode)
+	_, err = NewRedisCacheWithOpts("", RedisOpts{Mode: RedisModeSentinel, URI: redisTestServer.Addr()})
+	require.ErrorIs(t, err, ErrMissingRedisSentinelMaster)
+}
+
+func TestCheckExpiries(t *testing.T) {
+	defer func(payload, bid, duties time.Duration) {
+		expiryPayload, expiryBid, expiryProposerDuties = payload, bid, duties
+	}(expiryPayload, expiryBid, expiryProposerDuties)
+
+	require.NoError(t, checkExpiries())
+
+	expiryPayload = common.DurationPerSlot
+	require.ErrorIs(t, checkExpiries(), ErrInvalidExpiry)
+	expiryPayload = 2 * common.DurationPerSlot
+	require.NoError(t, checkExpiries())
+
+	expiryBid = 0
+	require.ErrorIs(t, checkExpiries(), ErrInvalidExpiry)
+	expiryBid = 2 * common.DurationPerSlot
+
+	expiryProposerDuties = common.DurationPerEpoch
+	require.ErrorIs(t, checkExpiries(), ErrInvalidExpiry)
+	expiryProposerDuties = 2 * common.DurationPerEpoch
+	require.NoError(t, checkExpiries())
 }
 
 func TestCheckAndSetLastSlotAndHashDelivered(t *testing.T) {
@@ -322,6 +424,99 @@ func TestCheckAndSetLastSlotAndHashDelivered(t *testing.T) {
 	require.ErrorIs(t, err, ErrPastSlotAlreadyDelivered)
 }
 
+func TestCheckAndSetGetPayloadRequest(t *testing.T) {
+	cache := setupTestRedis(t)
+	slot := uint64(123)
+	hash1 := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	hash2 := "0x0000000000000000000000000000000000000000000000000000000000000002"
+
+	// first request is recorded
+	first, err := cache.CheckAndSetGetPayloadRequest(slot, hash1, []byte(`{"version":"deneb"}`))
+	require.NoError(t, err)
+	require.Equal(t, hash1, first.BlockHash)
+
+	// retry of the identical request is allowed
+	first, err = cache.CheckAndSetGetPayloadRequest(slot, hash1, []byte(`{"version":"deneb"}`))
+	require.NoError(t, err)
+	require.Equal(t, hash1, first.BlockHash)
+
+	// request for a different block hash is an equivocation, and returns the first request
+	first, err = cache.CheckAndSetGetPayloadRequest(slot, hash2, []byte(`{"version":"capella"}`))
+	require.ErrorIs(t, err, ErrGetPayloadEquivocation)
+	require.Equal(t, hash1, first.BlockHash)
+	require.JSONEq(t, `{"version":"deneb"}`, string(first.SignedBlindedBeaconBlock))
+
+	// other slots are independent
+	_, err = cache.CheckAndSetGetPayloadRequest(slot+1, hash2, []byte(`{}`))
+	require.NoError(t, err)
+}
+
+func TestGetHeaderCalls(t *testing.T) {
+	cache := setupTestRedis(t)
+	slot := uint64(123)
+
+	calls, err := cache.GetGetHeaderCalls(slot)
+	require.NoError(t, err)
+	require.Empty(t, calls)
+
+	first := GetHeaderCall{ProposerPubkey: "0x01", ParentHash: "0x02", RequestedAtMs: 1000, ServedAtMs: 1500, BlockHash: "0x03", Value: "123"}
+	second := GetHeaderCall{ProposerPubkey: "0x01", ParentHash: "0x02", RequestedAtMs: 1100, ServedAtMs: 1600}
+	require.NoError(t, cache.AddGetHeaderCall(slot, first))
+	require.NoError(t, cache.AddGetHeaderCall(slot, second))
+
+	calls, err = cache.GetGetHeaderCalls(slot)
+	require.NoError(t, err)
+	require.Equal(t, []GetHeaderCall{first, second}, calls)
+
+	calls, err = cache.GetGetHeaderCalls(slot + 1)
+	require.NoError(t, err)
+	require.Empty(t, calls)
+}
+
+func TestSlotRequestCounts(t *testing.T) {
+	cache := setupTestRedis(t)
+	slot := uint64(123)
+
+	count, err := cache.GetSlotRequestCount(slot, RedisSlotRequestFieldGetHeader)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), count)
+
+	require.NoError(t, cache.IncSlotRequestCount(slot, RedisSlotRequestFieldGetHeader))
+	require.NoError(t, cache.IncSlotRequestCount(slot, RedisSlotRequestFieldGetHeader))
+	require.NoError(t, cache.IncSlotRequestCount(slot, RedisSlotRequestFieldGetPayload))
+
+	count, err = cache.GetSlotRequestCount(slot, RedisSlotRequestFieldGetHeader)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+	count, err = cache.GetSlotRequestCount(slot, RedisSlotRequestFieldGetPayload)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), count)
+
+	count, err = cache.GetSlotRequestCount(slot+1, RedisSlotRequestFieldGetHeader)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), count)
+}
+
+func TestEpochCounts(t *testing.T) {
+	cache := setupTestRedis(t)
+	epoch := uint64(3)
+
+	require.NoError(t, cache.IncEpochCount(epoch, RedisEpochCountFieldRegistrations, 100))
+	require.NoError(t, cache.IncEpochCount(epoch, RedisEpochCountFieldRegistrations, 5))
+	require.NoError(t, cache.IncEpochCount(epoch, RedisEpochCountFieldHeadersServed, 1))
+
+	count, err := cache.GetEpochCount(epoch, RedisEpochCountFieldRegistrations)
+	require.NoError(t, err)
+	require.Equal(t, uint64(105), count)
+	count, err = cache.GetEpochCount(epoch, RedisEpochCountFieldHeadersServed)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), count)
+
+	count, err = cache.GetEpochCount(epoch+1, RedisEpochCountFieldRegistrations)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), count)
+}
+
 // Test_CheckAndSetLastSlotAndHashDeliveredForTesting ensures the optimistic locking works
 // i.e. running CheckAndSetLastSlotAndHashDelivered leading to err == redis.TxFailedErr
 func Test_CheckAndSetLastSlotAndHashDeliveredForTesting(t *testing.T) {
@@ -406,8 +601,8 @@ func TestGetBuilderLatestValue(t *testing.T) {
 
 	slot := uint64(123)
 	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
-	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
-	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	proposerPubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+	builderPubkey := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
 
 	// With no bids, should return "0".
 	v, err := cache.GetBuilderLatestValue(slot, parentHash, proposerPubkey, builderPubkey)
@@ -437,6 +632,354 @@ func TestGetBuilderLatestValue(t *testing.T) {
 	require.Zero(t, v.Cmp(newVal.ToBig()))
 }
 
+func TestInvalidateBids(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+	builderPubkey := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+		Version:        spec.DataVersionDeneb,
+	}
+	trace := &common.BidTraceV2WithBlobFields{
+		BidTrace: builderApiV1.BidTrace{
+			Value: uint256.NewInt(123),
+		},
+	}
+
+	// invalidating without bids should not error
+	blockHashes, err := cache.InvalidateBids(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Empty(t, blockHashes)
+
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, uint256.NewInt(10), &opts)
+	_, err = cache.SaveBidAndUpdateTopBid(context.Background(), cache.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	require.NoError(t, err)
+	blockHash, err := getHeaderResp.BlockHash()
+	require.NoError(t, err)
+	_, err = cache.GetPayloadContents(slot, proposerPubkey, blockHash.String())
+	require.NoError(t, err)
+
+	blockHashes, err = cache.InvalidateBids(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, []string{blockHash.String()}, blockHashes)
+
+	bestBid, err := cache.GetBestBid(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Nil(t, bestBid)
+	topBidValue, err := cache.GetTopBidValue(context.Background(), cache.client.Pipeline(), slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(0), topBidValue)
+	floorValue, err := cache.GetFloorBidValue(context.Background(), cache.client.Pipeline(), slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(0), floorValue)
+	_, err = cache.GetPayloadContents(slot, proposerPubkey, blockHash.String())
+	require.Error(t, err)
+}
+
+func TestPayloadContentsElectra(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, ParentHash: testParentHash, ProposerPubkey: testProposerPubkey, Version: spec.DataVersionElectra}
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, testBuilderPubkeys[0], uint256.NewInt(10), &opts)
+	blockHash, err := getHeaderResp.BlockHash()
+	require.NoError(t, err)
+
+	executionRequestsRoot := phase0.Root{0x01}
+	trace := &common.BidTraceV2WithBlobFields{BidTrace: *payload.Electra.Message, ExecutionRequestsRoot: &executionRequestsRoot}
+	trace.BlockHash = blockHash
+	_, err = cache.SaveBidAndUpdateTopBid(context.Background(), cache.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	require.NoError(t, err)
+
+	// The payload contents are saved with the electra version
+	resp, err := cache.GetPayloadContents(2, testProposerPubkey, blockHash.String())
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionElectra, resp.Version)
+	respBlockHash, err := resp.BlockHash()
+	require.NoError(t, err)
+	require.Equal(t, blockHash, respBlockHash)
+
+	// The bid trace keeps the root of the execution requests
+	savedTrace, err := cache.GetBidTrace(2, testProposerPubkey, blockHash.String())
+	require.NoError(t, err)
+	require.Equal(t, &executionRequestsRoot, savedTrace.ExecutionRequestsRoot)
+
+	_, err = cache.InvalidateBids(2, testParentHash, testProposerPubkey)
+	require.NoError(t, err)
+	_, err = cache.GetPayloadContents(2, testProposerPubkey, blockHash.String())
+	require.ErrorIs(t, err, redis.Nil)
+}
+
+func TestHeaderBids(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+	builderPubkey := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+		Version:        spec.DataVersionDeneb,
+	}
+
+	saveHeaderBid := func(value uint64) *common.PendingPayload {
+		payload, _, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, uint256.NewInt(value), &opts)
+		bidTrace, err := payload.BidTrace()
+		require.NoError(t, err)
+		bidTrace.BlockHash = phase0.Hash32{byte(value)}
+		getHeaderResp.Deneb.Message.Header.BlockHash = bidTrace.BlockHash
+		state, err := cache.SaveHeaderBidAndUpdateTopBid(context.Background(), cache.NewPipeline(), &common.BidTraceV2WithBlobFields{BidTrace: *bidTrace}, getHeaderResp, time.Now(), nil)
+		require.NoError(t, err)
+		require.True(t, state.WasBidSaved)
+		require.True(t, state.IsNewTopBid)
+
+		pending := &common.PendingPayload{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey, BuilderPubkey: builderPubkey, BlockHash: bidTrace.BlockHash.String()}
+		require.NoError(t, cache.SavePendingPayload(pending))
+		return pending
+	}
+
+	// The header-only bid becomes the top bid
+	pending := saveHeaderBid(10)
+	bestBid, err := cache.GetBestBid(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	value, err := bestBid.Value()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), value.Uint64())
+	saved, err := cache.GetPendingPayload(slot, pending.BlockHash)
+	require.NoError(t, err)
+	require.Equal(t, pending, saved)
+	all, err := cache.GetPendingPayloads(slot)
+	require.NoError(t, err)
+	require.Equal(t, []*common.PendingPayload{pending}, all)
+
+	// Only the first caller removes the pending payload
+	wasPending, err := cache.DelPendingPayload(slot, pending.BlockHash)
+	require.NoError(t, err)
+	require.True(t, wasPending)
+	wasPending, err = cache.DelPendingPayload(slot, pending.BlockHash)
+	require.NoError(t, err)
+	require.False(t, wasPending)
+	saved, err = cache.GetPendingPayload(slot, pending.BlockHash)
+	require.NoError(t, err)
+	require.Nil(t, saved)
+	all, err = cache.GetPendingPayloads(slot)
+	require.NoError(t, err)
+	require.Empty(t, all)
+
+	// Cancelling the only bid removes the top bid
+	wasRemoved, err := cache.DelHeaderBid(context.Background(), cache.NewPipeline(), pending)
+	require.NoError(t, err)
+	require.True(t, wasRemoved)
+	bestBid, err = cache.GetBestBid(slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Nil(t, bestBid)
+
+	// A header-only bid which was replaced by a newer bid of the builder is not removed
+	pending = saveHeaderBid(20)
+	saveHeaderBid(30)
+	wasRemoved, err = cache.DelHeaderBid(context.Background(), cache.NewPipeline(), pending)
+	require.NoError(t, err)
+	require.False(t, wasRemoved)
+	topBidValue, err := cache.GetTopBidValue(context.Background(), cache.NewPipeline(), slot, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(30), topBidValue)
+}
+
+const (
+	testParentHash     = "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	testProposerPubkey = "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+)
+
+var testBuilderPubkeys = []string{
+	"0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c",
+	"0x8e11b7a25502292572cb19aae26b4ea7bab862f4b2667c58ae6e124808b67ab2ca3eef5311c07330c018851228c26563",
+}
+
+// latencyHook counts the round trips to Redis, and adds a simulated network latency to each of them
+type latencyHook struct {
+	latency    time.Duration
+	roundTrips atomic.Int64
+}
+
+func (h *latencyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *latencyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.roundTrips.Add(1)
+		time.Sleep(h.latency)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *latencyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.roundTrips.Add(1)
+		time.Sleep(h.latency)
+		return next(ctx, cmds)
+	}
+}
+
+func TestSaveBidRoundTrips(t *testing.T) {
+	cache := setupTestRedis(t)
+	hook := &latencyHook{}
+	cache.client.AddHook(hook)
+
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, ParentHash: testParentHash, ProposerPubkey: testProposerPubkey, Version: spec.DataVersionDeneb}
+	trace := &common.BidTraceV2WithBlobFields{BidTrace: builderApiV1.BidTrace{Value: uint256.NewInt(10)}}
+
+	// Loading the bids and floor value is one round trip, saving the bid and updating the top bid and floor another
+	for i, cancellations := range []bool{false, true} {
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, testBuilderPubkeys[i], uint256.NewInt(uint64(10+i)), &opts)
+		hook.roundTrips.Store(0)
+		resp, err := cache.SaveBidAndUpdateTopBid(context.Background(), cache.NewTxPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), cancellations, nil)
+		require.NoError(t, err)
+		require.True(t, resp.WasBidSaved)
+		require.True(t, resp.IsNewTopBid)
+		require.Equal(t, int64(2), hook.roundTrips.Load())
+	}
+}
+
+// crossSlotHook fails COPY commands between keys in different hash slots, like a Redis cluster does
+type crossSlotHook struct{}
+
+func (crossSlotHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (crossSlotHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := checkCrossSlot(cmd); err != nil {
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (crossSlotHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := checkCrossSlot(cmd); err != nil {
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func checkCrossSlot(cmd redis.Cmder) error {
+	if cmd.Name() != "copy" {
+		return nil
+	}
+	src, dst := cmd.Args()[1].(string), cmd.Args()[2].(string)
+	if clusterHashSlot(src) != clusterHashSlot(dst) {
+		err := fmt.Errorf("CROSSSLOT Keys in request don't hash to the same slot: %s, %s", src, dst) //nolint:goerr113
+		cmd.SetErr(err)
+		return err
+	}
+	return nil
+}
+
+// clusterHashSlot returns the hash slot of a key: the CRC16 of the key, or of its hash tag, modulo 16384
+func clusterHashSlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	crc := uint16(0)
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc % 16384
+}
+
+func TestSaveBidAndUpdateTopBidCluster(t *testing.T) {
+	redisTestServer, err := miniredis.Run()
+	require.NoError(t, err)
+	cache, err := NewRedisCacheWithOpts("", RedisOpts{Mode: RedisModeCluster, URI: redisTestServer.Addr()})
+	require.NoError(t, err)
+	cache.client.AddHook(crossSlotHook{})
+
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, ParentHash: testParentHash, ProposerPubkey: testProposerPubkey, Version: spec.DataVersionDeneb}
+	trace := &common.BidTraceV2WithBlobFields{BidTrace: builderApiV1.BidTrace{Value: uint256.NewInt(10)}}
+
+	// The first bid is non-cancellable, so that it's also copied to the floor bid
+	for i, cancellations := range []bool{false, true} {
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, testBuilderPubkeys[i], uint256.NewInt(uint64(10+i)), &opts)
+		resp, err := cache.SaveBidAndUpdateTopBid(context.Background(), cache.NewTxPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), cancellations, nil)
+		require.NoError(t, err)
+		require.True(t, resp.WasBidSaved)
+		require.True(t, resp.IsNewTopBid)
+	}
+
+	bestBid, err := cache.GetBestBid(2, testParentHash, testProposerPubkey)
+	require.NoError(t, err)
+	value, err := bestBid.Value()
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), value.Uint64())
+
+	floorValue, err := cache.GetFloorBidValue(context.Background(), cache.NewPipeline(), 2, testParentHash, testProposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(10), floorValue)
+
+	// All keys of the auction have the same hash slot
+	keyTopBid := cache.keyCacheGetHeaderResponse(2, testParentHash, testProposerPubkey)
+	require.Equal(t, clusterHashSlot(keyTopBid), clusterHashSlot(cache.keyFloorBid(2, testParentHash, testProposerPubkey)))
+	require.Equal(t, clusterHashSlot(keyTopBid), clusterHashSlot(cache.keyLatestBidByBuilder(2, testParentHash, testProposerPubkey, testBuilderPubkeys[0])))
+}
+
+// BenchmarkSaveBidAndUpdateTopBid measures saving bids of concurrent submissions, without and with a simulated network
+// latency to Redis, where the number of round trips per bid dominates
+func BenchmarkSaveBidAndUpdateTopBid(b *testing.B) {
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, ParentHash: testParentHash, ProposerPubkey: testProposerPubkey, Version: spec.DataVersionDeneb}
+	trace := &common.BidTraceV2WithBlobFields{BidTrace: builderApiV1.BidTrace{Value: uint256.NewInt(10)}}
+
+	type bid struct {
+		payload        *common.VersionedSubmitBlockRequest
+		getPayloadResp *builderApi.VersionedSubmitBlindedBlockResponse
+		getHeaderResp  *builderSpec.VersionedSignedBuilderBid
+	}
+	bids := make([]bid, 0, 64)
+	for i := 0; i < cap(bids); i++ {
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(b, testBuilderPubkeys[i%len(testBuilderPubkeys)], uint256.NewInt(uint64(100+i)), &opts)
+		bids = append(bids, bid{payload, getPayloadResp, getHeaderResp})
+	}
+
+	for _, latency := range []time.Duration{0, 200 * time.Microsecond} {
+		b.Run(fmt.Sprintf("latency=%s", latency), func(b *testing.B) {
+			cache := setupTestRedis(b)
+			cache.client.AddHook(&latencyHook{latency: latency})
+
+			var n atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					bid := bids[int(n.Add(1))%len(bids)]
+					_, err := cache.SaveBidAndUpdateTopBid(context.Background(), cache.NewTxPipeline(), trace, bid.payload, bid.getPayloadResp, bid.getHeaderResp, time.Now(), true, nil)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
 func TestPipelineNilCheck(t *testing.T) {
 	cache := setupTestRedis(t)
 	f, err := cache.GetFloorBidValue(context.Background(), cache.NewPipeline(), 0, "1", "2")
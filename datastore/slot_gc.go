@@ -0,0 +1,157 @@
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v9"
+)
+
+const (
+	// kinds of per-slot keys which are deleted once their slot is over
+	SlotKeysBids              = "bids"
+	SlotKeysFloors            = "floors"
+	SlotKeysPendingPayloads   = "pending-payloads"
+	SlotKeysBlockPublications = "block-publications"
+
+	slotGCScanCount = 1000 // number of keys per SCAN call
+	slotGCBatchSize = 1000 // number of keys per UNLINK pipeline
+)
+
+// slotKeyPrefixes returns the prefixes of the per-slot auction keys by kind. The keys are only used until their slot
+// is over, but are kept until they expire, so that they pile up with many builders and long expiries. The getPayload
+// requests, getHeader calls and request counts are kept until they expire, as they're used for the equivocation
+// checks and the slot summaries.
+func (r *RedisCache) slotKeyPrefixes() map[string][]string {
+	return map[string][]string{
+		SlotKeysBids: {
+			r.prefixGetHeaderResponse,
+			r.prefixBlockBuilderLatestBids,
+			r.prefixBlockBuilderLatestBidsValue,
+			r.prefixBlockBuilderLatestBidsTime,
+			r.prefixTopBidValue,
+			r.prefixUpstreamBids,
+		},
+		SlotKeysFloors:            {r.prefixFloorBid, r.prefixFloorBidValue},
+		SlotKeysPendingPayloads:   {r.prefixPendingPayloads},
+		SlotKeysBlockPublications: {r.prefixBlockPublication},
+	}
+}
+
+// slotOfKey returns the slot of a per-slot key (prefix:slot, prefix:slot_... or prefix:{slot_...}), if the key has the
+// prefix
+func slotOfKey(key, prefix string) (slot uint64, ok bool) {
+	rest, found := strings.CutPrefix(key, prefix+":")
+	if !found {
+		return 0, false
+	}
+	rest = strings.TrimPrefix(rest, "{") // hash tag of the auction keys in cluster mode
+	if i := strings.IndexByte(rest, '_'); i >= 0 {
+		rest = rest[:i]
+	}
+	slot, err := strconv.ParseUint(rest, 10, 64)
+	return slot, err == nil
+}
+
+// slotKeysMatch returns the SCAN pattern matching the keys of all the prefixes, which share the relay's key prefix
+func slotKeysMatch(prefixes []string) string {
+	match := prefixes[0]
+	for _, prefix := range prefixes[1:] {
+		for !strings.HasPrefix(prefix, match) {
+			match = match[:len(match)-1]
+		}
+	}
+	return match + "*"
+}
+
+// DeleteSlotKeysBefore deletes the per-slot auction keys (bids, floor bids, pending payloads of header-only
+// submissions and block publication claims) of all slots before the given slot, and returns the number of deleted
+// keys by kind. The keyspace is scanned once for all prefixes.
+func (r *RedisCache) DeleteSlotKeysBefore(ctx context.Context, slot uint64) (map[string]int64, error) {
+	kinds := r.slotKeyPrefixes()
+	kindOfPrefix := make(map[string]string)
+	prefixes := []string{}
+	for kind, kindPrefixes := range kinds {
+		for _, prefix := range kindPrefixes {
+			kindOfPrefix[prefix] = kind
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	keys := make(map[string][]string)
+	err := r.scanKeys(ctx, slotKeysMatch(prefixes), func(scanned []string) error {
+		for _, key := range scanned {
+			for _, prefix := range prefixes {
+				if keySlot, ok := slotOfKey(key, prefix); ok {
+					if keySlot < slot {
+						kind := kindOfPrefix[prefix]
+						keys[kind] = append(keys[kind], key)
+					}
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[string]int64, len(kinds))
+	for kind := range kinds {
+		n, err := r.unlinkKeys(ctx, keys[kind])
+		deleted[kind] = n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// scanKeys calls fn with the keys matching the pattern, on every master node in cluster mode
+func (r *RedisCache) scanKeys(ctx context.Context, match string, fn func(keys []string) error) error {
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		cursor := uint64(0)
+		for {
+			keys, nextCursor, err := client.Scan(ctx, cursor, match, slotGCScanCount).Result()
+			if err != nil {
+				return err
+			}
+			if err := fn(keys); err != nil {
+				return err
+			}
+			if nextCursor == 0 {
+				return nil
+			}
+			cursor = nextCursor
+		}
+	}
+
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+	}
+	return scan(ctx, r.client)
+}
+
+// unlinkKeys deletes the keys in batches, one UNLINK per key so that the keys can be on different cluster nodes, and
+// returns the number of keys which still existed
+func (r *RedisCache) unlinkKeys(ctx context.Context, keys []string) (deleted int64, err error) {
+	for start := 0; start < len(keys); start += slotGCBatchSize {
+		end := min(start+slotGCBatchSize, len(keys))
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.IntCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, pipe.Unlink(ctx, key))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return deleted, err
+		}
+		for _, cmd := range cmds {
+			deleted += cmd.Val()
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,106 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlotOfKey(t *testing.T) {
+	slot, ok := slotOfKey("boost-relay/:bid-floor:12_0xab_0xcd", "boost-relay/:bid-floor")
+	require.True(t, ok)
+	require.Equal(t, uint64(12), slot)
+	slot, ok = slotOfKey("boost-relay/:pending-payloads:13", "boost-relay/:pending-payloads")
+	require.True(t, ok)
+	require.Equal(t, uint64(13), slot)
+	slot, ok = slotOfKey("boost-relay/:bid-floor:{14_0xab_0xcd}", "boost-relay/:bid-floor")
+	require.True(t, ok)
+	require.Equal(t, uint64(14), slot)
+
+	// keys of other prefixes which start with the prefix
+	_, ok = slotOfKey("boost-relay/:bid-floor-value:12_0xab_0xcd", "boost-relay/:bid-floor")
+	require.False(t, ok)
+	_, ok = slotOfKey("boost-relay/:bid-floor:latest", "boost-relay/:bid-floor")
+	require.False(t, ok)
+}
+
+func TestSlotKeysMatch(t *testing.T) {
+	cache := setupTestRedis(t)
+	prefixes := []string{}
+	for _, kindPrefixes := range cache.slotKeyPrefixes() {
+		prefixes = append(prefixes, kindPrefixes...)
+	}
+	require.Equal(t, "boost-relay/:*", slotKeysMatch(prefixes))
+	require.Equal(t, "boost-relay/:bid-floor*", slotKeysMatch([]string{"boost-relay/:bid-floor", "boost-relay/:bid-floor-value"}))
+}
+
+func TestDeleteSlotKeysBefore(t *testing.T) {
+	cache := setupTestRedis(t)
+
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+	builderPubkey := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	saveBid := func(slot uint64) string {
+		opts := common.CreateTestBlockSubmissionOpts{
+			Slot:           slot,
+			ParentHash:     parentHash,
+			ProposerPubkey: proposerPubkey,
+			Version:        spec.DataVersionDeneb,
+		}
+		trace := &common.BidTraceV2WithBlobFields{BidTrace: builderApiV1.BidTrace{Value: uint256.NewInt(10)}}
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, uint256.NewInt(10), &opts)
+		_, err := cache.SaveBidAndUpdateTopBid(context.Background(), cache.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+		require.NoError(t, err)
+		blockHash, err := getHeaderResp.BlockHash()
+		require.NoError(t, err)
+
+		require.NoError(t, cache.SavePendingPayload(&common.PendingPayload{Slot: slot, BlockHash: blockHash.String()}))
+		_, err = cache.ClaimBlockPublication(slot, blockHash.String(), "instance")
+		require.NoError(t, err)
+		_, err = cache.CheckAndSetGetPayloadRequest(slot, blockHash.String(), []byte("{}"))
+		require.NoError(t, err)
+		return blockHash.String()
+	}
+	oldBlockHash := saveBid(10)
+	saveBid(11)
+
+	deleted, err := cache.DeleteSlotKeysBefore(context.Background(), 11)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{
+		SlotKeysBids:              5, // getHeader response, builder bid, bid values and times, top bid value
+		SlotKeysFloors:            2,
+		SlotKeysPendingPayloads:   1,
+		SlotKeysBlockPublications: 1,
+	}, deleted)
+
+	// The bids of the old slot are deleted, the bids of the newer slot are kept
+	bestBid, err := cache.GetBestBid(10, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Nil(t, bestBid)
+	floorValue, err := cache.GetFloorBidValue(context.Background(), cache.NewPipeline(), 10, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.Zero(t, floorValue.Sign())
+	pending, err := cache.GetPendingPayload(10, oldBlockHash)
+	require.NoError(t, err)
+	require.Nil(t, pending)
+	bestBid, err = cache.GetBestBid(11, parentHash, proposerPubkey)
+	require.NoError(t, err)
+	require.NotNil(t, bestBid)
+
+	// Payloads and getPayload requests are kept until they expire
+	_, err = cache.GetPayloadContents(10, proposerPubkey, oldBlockHash)
+	require.NoError(t, err)
+	_, err = cache.CheckAndSetGetPayloadRequest(10, "0x01", []byte("{}"))
+	require.ErrorIs(t, err, ErrGetPayloadEquivocation)
+
+	// Nothing left to delete
+	deleted, err = cache.DeleteSlotKeysBefore(context.Background(), 11)
+	require.NoError(t, err)
+	require.Zero(t, deleted[SlotKeysBids])
+}
@@ -0,0 +1,352 @@
+// Package decoder decodes the request bodies of the public endpoints of the relay (block submissions, validator
+// registrations and signed blinded blocks), and validates the decoded requests strictly enough that the handlers can
+// access all fields without further nil checks. All decoders are safe to call with arbitrary input.
+package decoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/buger/jsonparser"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+// Bounds of the consensus specs, which SSZ decoding enforces but JSON decoding does not
+const (
+	MaxTransactionsPerPayload  = 1 << 20
+	MaxBytesPerTransaction     = 1 << 30
+	MaxWithdrawalsPerPayload   = 16
+	MaxExtraDataBytes          = 32
+	MaxBlobCommitmentsPerBlock = 4096
+
+	MaxDepositRequestsPerPayload       = 8192
+	MaxWithdrawalRequestsPerPayload    = 16
+	MaxConsolidationRequestsPerPayload = 2
+)
+
+var (
+	ErrEmptyBody            = errors.New("empty request body")
+	ErrMissingField         = errors.New("missing field")
+	ErrUnsupportedVersion   = errors.New("unsupported fork version")
+	ErrTooManyTransactions  = fmt.Errorf("more than %d transactions", MaxTransactionsPerPayload)
+	ErrInvalidTransaction   = errors.New("invalid transaction length")
+	ErrTooManyWithdrawals   = fmt.Errorf("more than %d withdrawals", MaxWithdrawalsPerPayload)
+	ErrExtraDataTooLarge    = fmt.Errorf("extra data longer than %d bytes", MaxExtraDataBytes)
+	ErrTooManyBlobs         = fmt.Errorf("more than %d blobs", MaxBlobCommitmentsPerBlock)
+	ErrBlobsBundleMismatch  = errors.New("blobs bundle has different numbers of commitments, proofs and blobs")
+	ErrTooManyRequests      = errors.New("too many execution requests")
+	ErrNegativeTimestamp    = errors.New("timestamp cannot be negative")
+	ErrInvalidRegistrations = errors.New("registrations must be a JSON array")
+)
+
+// Encoding is the encoding a request body was decoded from
+type Encoding string
+
+const (
+	EncodingJSON Encoding = "json"
+	EncodingSSZ  Encoding = "ssz"
+)
+
+// DecodeSubmitBlockRequest decodes a block submission, SSZ-encoded if the content type is application/octet-stream and
+// JSON-encoded otherwise. SSZ bodies which fail to decode are decoded as JSON, because some builders used the SSZ
+// content type for JSON before.
+func DecodeSubmitBlockRequest(body []byte, contentType string) (*common.VersionedSubmitBlockRequest, Encoding, error) {
+	if len(body) == 0 {
+		return nil, EncodingJSON, ErrEmptyBody
+	}
+
+	payload := new(common.VersionedSubmitBlockRequest)
+	encoding := EncodingJSON
+	if contentType == "application/octet-stream" {
+		if err := payload.UnmarshalSSZ(body); err == nil {
+			encoding = EncodingSSZ
+		} else if err2 := json.Unmarshal(body, payload); err2 != nil {
+			return nil, EncodingSSZ, fmt.Errorf("%w / %w", err, err2)
+		}
+	} else if err := json.Unmarshal(body, payload); err != nil {
+		return nil, EncodingJSON, err
+	}
+
+	if err := validateSubmitBlockRequest(payload); err != nil {
+		return nil, encoding, err
+	}
+	return payload, encoding, nil
+}
+
+// DecodeSubmitBlockRequestSSZ decodes an SSZ-encoded block submission, for transports where the encoding is known
+func DecodeSubmitBlockRequestSSZ(body []byte) (*common.VersionedSubmitBlockRequest, error) {
+	if len(body) == 0 {
+		return nil, ErrEmptyBody
+	}
+
+	payload := new(common.VersionedSubmitBlockRequest)
+	if err := payload.UnmarshalSSZ(body); err != nil {
+		return nil, err
+	}
+	if err := validateSubmitBlockRequest(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func validateSubmitBlockRequest(payload *common.VersionedSubmitBlockRequest) error {
+	var (
+		bidTrace    *builderApiV1.BidTrace
+		extraData   []byte
+		txs         []bellatrix.Transaction
+		withdrawals []*capella.Withdrawal
+	)
+	switch payload.Version { //nolint:exhaustive
+	case spec.DataVersionCapella:
+		if payload.Capella == nil || payload.Capella.ExecutionPayload == nil {
+			return fmt.Errorf("%w: execution payload", ErrMissingField)
+		}
+		bidTrace = payload.Capella.Message
+		extraData = payload.Capella.ExecutionPayload.ExtraData
+		txs = payload.Capella.ExecutionPayload.Transactions
+		withdrawals = payload.Capella.ExecutionPayload.Withdrawals
+	case spec.DataVersionDeneb:
+		if payload.Deneb == nil || payload.Deneb.ExecutionPayload == nil {
+			return fmt.Errorf("%w: execution payload", ErrMissingField)
+		}
+		if payload.Deneb.ExecutionPayload.BaseFeePerGas == nil {
+			return fmt.Errorf("%w: base fee per gas", ErrMissingField)
+		}
+		if err := validateBlobsBundle(payload.Deneb.BlobsBundle); err != nil {
+			return err
+		}
+		bidTrace = payload.Deneb.Message
+		extraData = payload.Deneb.ExecutionPayload.ExtraData
+		txs = payload.Deneb.ExecutionPayload.Transactions
+		withdrawals = payload.Deneb.ExecutionPayload.Withdrawals
+	case spec.DataVersionElectra:
+		if payload.Electra == nil || payload.Electra.ExecutionPayload == nil {
+			return fmt.Errorf("%w: execution payload", ErrMissingField)
+		}
+		if payload.Electra.ExecutionPayload.BaseFeePerGas == nil {
+			return fmt.Errorf("%w: base fee per gas", ErrMissingField)
+		}
+		if err := validateBlobsBundle(payload.Electra.BlobsBundle); err != nil {
+			return err
+		}
+		if err := validateExecutionRequests(payload.Electra.ExecutionRequests); err != nil {
+			return err
+		}
+		bidTrace = payload.Electra.Message
+		extraData = payload.Electra.ExecutionPayload.ExtraData
+		txs = payload.Electra.ExecutionPayload.Transactions
+		withdrawals = payload.Electra.ExecutionPayload.Withdrawals
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedVersion, payload.Version)
+	}
+
+	if bidTrace == nil {
+		return fmt.Errorf("%w: message", ErrMissingField)
+	}
+	if bidTrace.Value == nil {
+		return fmt.Errorf("%w: message value", ErrMissingField)
+	}
+	if len(extraData) > MaxExtraDataBytes {
+		return ErrExtraDataTooLarge
+	}
+	if len(txs) > MaxTransactionsPerPayload {
+		return ErrTooManyTransactions
+	}
+	for i, tx := range txs {
+		if len(tx) == 0 || len(tx) > MaxBytesPerTransaction {
+			return fmt.Errorf("%w: transaction %d has %d bytes", ErrInvalidTransaction, i, len(tx))
+		}
+	}
+	if len(withdrawals) > MaxWithdrawalsPerPayload {
+		return ErrTooManyWithdrawals
+	}
+	for i, withdrawal := range withdrawals {
+		if withdrawal == nil {
+			return fmt.Errorf("%w: withdrawal %d", ErrMissingField, i)
+		}
+	}
+	return nil
+}
+
+func validateBlobsBundle(bundle *builderApiDeneb.BlobsBundle) error {
+	if bundle == nil {
+		return fmt.Errorf("%w: blobs bundle", ErrMissingField)
+	}
+	if len(bundle.Commitments) > MaxBlobCommitmentsPerBlock {
+		return ErrTooManyBlobs
+	}
+	if len(bundle.Commitments) != len(bundle.Proofs) || len(bundle.Commitments) != len(bundle.Blobs) {
+		return ErrBlobsBundleMismatch
+	}
+	return nil
+}
+
+func validateExecutionRequests(requests *electra.ExecutionRequests) error {
+	if requests == nil {
+		return fmt.Errorf("%w: execution requests", ErrMissingField)
+	}
+	if len(requests.Deposits) > MaxDepositRequestsPerPayload {
+		return fmt.Errorf("%w: %d deposit requests", ErrTooManyRequests, len(requests.Deposits))
+	}
+	if len(requests.Withdrawals) > MaxWithdrawalRequestsPerPayload {
+		return fmt.Errorf("%w: %d withdrawal requests", ErrTooManyRequests, len(requests.Withdrawals))
+	}
+	if len(requests.Consolidations) > MaxConsolidationRequestsPerPayload {
+		return fmt.Errorf("%w: %d consolidation requests", ErrTooManyRequests, len(requests.Consolidations))
+	}
+	for i, deposit := range requests.Deposits {
+		if deposit == nil {
+			return fmt.Errorf("%w: deposit request %d", ErrMissingField, i)
+		}
+	}
+	for i, withdrawal := range requests.Withdrawals {
+		if withdrawal == nil {
+			return fmt.Errorf("%w: withdrawal request %d", ErrMissingField, i)
+		}
+	}
+	for i, consolidation := range requests.Consolidations {
+		if consolidation == nil {
+			return fmt.Errorf("%w: consolidation request %d", ErrMissingField, i)
+		}
+	}
+	return nil
+}
+
+// DecodeSignedBlindedBeaconBlock decodes the JSON-encoded signed blinded block of a getPayload request. Unlike a
+// json.Decoder, it rejects data after the block.
+func DecodeSignedBlindedBeaconBlock(body []byte) (*common.VersionedSignedBlindedBeaconBlock, error) {
+	if len(body) == 0 {
+		return nil, ErrEmptyBody
+	}
+
+	payload := new(common.VersionedSignedBlindedBeaconBlock)
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, err
+	}
+
+	switch payload.Version { //nolint:exhaustive
+	case spec.DataVersionCapella:
+		block := payload.Capella
+		if block == nil || block.Message == nil || block.Message.Body == nil || block.Message.Body.ExecutionPayloadHeader == nil {
+			return nil, fmt.Errorf("%w: execution payload header", ErrMissingField)
+		}
+	case spec.DataVersionDeneb:
+		block := payload.Deneb
+		if block == nil || block.Message == nil || block.Message.Body == nil || block.Message.Body.ExecutionPayloadHeader == nil {
+			return nil, fmt.Errorf("%w: execution payload header", ErrMissingField)
+		}
+		if len(block.Message.Body.BlobKZGCommitments) > MaxBlobCommitmentsPerBlock {
+			return nil, ErrTooManyBlobs
+		}
+	case spec.DataVersionElectra:
+		block := payload.Electra
+		if block == nil || block.Message == nil || block.Message.Body == nil || block.Message.Body.ExecutionPayloadHeader == nil {
+			return nil, fmt.Errorf("%w: execution payload header", ErrMissingField)
+		}
+		if len(block.Message.Body.BlobKZGCommitments) > MaxBlobCommitmentsPerBlock {
+			return nil, ErrTooManyBlobs
+		}
+		if err := validateExecutionRequests(block.Message.Body.ExecutionRequests); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedVersion, payload.Version)
+	}
+	return payload, nil
+}
+
+// CheckValidatorRegistrations checks that the body of a registerValidator request is a JSON array, whose entries can
+// then be decoded one by one with DecodeValidatorRegistration
+func CheckValidatorRegistrations(body []byte) error {
+	if len(body) == 0 {
+		return ErrEmptyBody
+	}
+	_, dataType, _, err := jsonparser.Get(body)
+	if err != nil || dataType != jsonparser.Array {
+		return ErrInvalidRegistrations
+	}
+	return nil
+}
+
+// DecodeValidatorRegistration decodes a single JSON-encoded signed validator registration. Only the fields needed by
+// the relay are decoded, which is a lot faster than decoding the full registration with encoding/json.
+func DecodeValidatorRegistration(value []byte) (*builderApiV1.SignedValidatorRegistration, error) {
+	// Pubkey
+	_pubkey, err := jsonparser.GetUnsafeString(value, "message", "pubkey")
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (pubkey): %w", err)
+	}
+
+	pubkey, err := utils.HexToPubkey(_pubkey)
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (pubkey): %w", err)
+	}
+
+	// Timestamp
+	_timestamp, err := jsonparser.GetUnsafeString(value, "message", "timestamp")
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (timestamp): %w", err)
+	}
+
+	timestamp, err := strconv.ParseInt(_timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	if timestamp < 0 {
+		return nil, ErrNegativeTimestamp
+	}
+
+	// GasLimit
+	_gasLimit, err := jsonparser.GetUnsafeString(value, "message", "gas_limit")
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (gasLimit): %w", err)
+	}
+
+	gasLimit, err := strconv.ParseUint(_gasLimit, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gasLimit: %w", err)
+	}
+
+	// FeeRecipient
+	_feeRecipient, err := jsonparser.GetUnsafeString(value, "message", "fee_recipient")
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (fee_recipient): %w", err)
+	}
+
+	feeRecipient, err := utils.HexToAddress(_feeRecipient)
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (fee_recipient): %w", err)
+	}
+
+	// Signature
+	_signature, err := jsonparser.GetUnsafeString(value, "signature")
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (signature): %w", err)
+	}
+
+	signature, err := utils.HexToSignature(_signature)
+	if err != nil {
+		return nil, fmt.Errorf("registration message error (signature): %w", err)
+	}
+
+	// Construct and return full registration object
+	return &builderApiV1.SignedValidatorRegistration{
+		Message: &builderApiV1.ValidatorRegistration{
+			FeeRecipient: feeRecipient,
+			GasLimit:     gasLimit,
+			Timestamp:    time.Unix(timestamp, 0),
+			Pubkey:       pubkey,
+		},
+		Signature: signature,
+	}, nil
+}
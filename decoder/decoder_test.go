@@ -0,0 +1,295 @@
+package decoder
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/buger/jsonparser"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestdata(tb testing.TB, filename string) []byte {
+	tb.Helper()
+	f, err := os.Open("../testdata/" + filename)
+	require.NoError(tb, err)
+	defer f.Close()
+	var r io.Reader = f
+	if filename[len(filename)-3:] == ".gz" {
+		r, err = gzip.NewReader(f)
+		require.NoError(tb, err)
+	}
+	b, err := io.ReadAll(r)
+	require.NoError(tb, err)
+	return b
+}
+
+func TestDecodeSubmitBlockRequest(t *testing.T) {
+	jsonBytes := loadTestdata(t, "submitBlockPayloadDeneb_Goerli.json.gz")
+	sszBytes := loadTestdata(t, "submitBlockPayloadDeneb_Goerli.ssz.gz")
+
+	payload, encoding, err := DecodeSubmitBlockRequest(jsonBytes, "application/json")
+	require.NoError(t, err)
+	require.Equal(t, EncodingJSON, encoding)
+	require.NotNil(t, payload.Deneb)
+
+	payload, encoding, err = DecodeSubmitBlockRequest(sszBytes, "application/octet-stream")
+	require.NoError(t, err)
+	require.Equal(t, EncodingSSZ, encoding)
+	require.NotNil(t, payload.Deneb)
+
+	// JSON with the SSZ content type is accepted
+	_, encoding, err = DecodeSubmitBlockRequest(jsonBytes, "application/octet-stream")
+	require.NoError(t, err)
+	require.Equal(t, EncodingJSON, encoding)
+
+	_, _, err = DecodeSubmitBlockRequest(nil, "application/json")
+	require.ErrorIs(t, err, ErrEmptyBody)
+	_, _, err = DecodeSubmitBlockRequest(sszBytes, "application/json")
+	require.Error(t, err)
+	_, _, err = DecodeSubmitBlockRequest(append(jsonBytes, []byte("{}")...), "application/json")
+	require.Error(t, err)
+}
+
+func TestDecodeSubmitBlockRequestSSZ(t *testing.T) {
+	payload, err := DecodeSubmitBlockRequestSSZ(loadTestdata(t, "submitBlockPayloadDeneb_Goerli.ssz.gz"))
+	require.NoError(t, err)
+	require.NotNil(t, payload.Deneb)
+
+	// No fallback to JSON
+	_, err = DecodeSubmitBlockRequestSSZ(loadTestdata(t, "submitBlockPayloadDeneb_Goerli.json.gz"))
+	require.Error(t, err)
+	_, err = DecodeSubmitBlockRequestSSZ(nil)
+	require.ErrorIs(t, err, ErrEmptyBody)
+}
+
+func TestDecodeSubmitBlockRequestValidation(t *testing.T) {
+	jsonBytes := loadTestdata(t, "submitBlockPayloadDeneb_Goerli.json.gz")
+	decode := func(modify func(payload *common.VersionedSubmitBlockRequest)) error {
+		payload := new(common.VersionedSubmitBlockRequest)
+		require.NoError(t, json.Unmarshal(jsonBytes, payload))
+		modify(payload)
+		body, err := payload.MarshalSSZ()
+		if err != nil {
+			// not encodable as SSZ, i.e. violating the bounds of the specs
+			body, err = json.Marshal(payload)
+			require.NoError(t, err)
+		}
+		_, _, err = DecodeSubmitBlockRequest(body, "application/octet-stream")
+		return err
+	}
+
+	require.NoError(t, decode(func(payload *common.VersionedSubmitBlockRequest) {}))
+	require.ErrorIs(t, decode(func(payload *common.VersionedSubmitBlockRequest) {
+		payload.Deneb.ExecutionPayload.Transactions[0] = nil
+	}), ErrInvalidTransaction)
+	require.ErrorIs(t, decode(func(payload *common.VersionedSubmitBlockRequest) {
+		payload.Deneb.ExecutionPayload.Withdrawals = append(payload.Deneb.ExecutionPayload.Withdrawals, payload.Deneb.ExecutionPayload.Withdrawals...)
+	}), ErrTooManyWithdrawals)
+	require.ErrorIs(t, decode(func(payload *common.VersionedSubmitBlockRequest) {
+		payload.Deneb.BlobsBundle.Proofs = payload.Deneb.BlobsBundle.Proofs[1:]
+	}), ErrBlobsBundleMismatch)
+}
+
+// electraSubmission returns the deneb test submission as an electra submission
+func electraSubmission(t *testing.T) *common.VersionedSubmitBlockRequest {
+	t.Helper()
+	payload := new(common.VersionedSubmitBlockRequest)
+	require.NoError(t, json.Unmarshal(loadTestdata(t, "submitBlockPayloadDeneb_Goerli.json.gz"), payload))
+	return common.ElectraSubmitBlockRequestFromDeneb(payload, common.TestExecutionRequests())
+}
+
+func TestDecodeSubmitBlockRequestElectra(t *testing.T) {
+	jsonBytes, err := json.Marshal(electraSubmission(t))
+	require.NoError(t, err)
+	sszBytes, err := electraSubmission(t).MarshalSSZ()
+	require.NoError(t, err)
+
+	payload, encoding, err := DecodeSubmitBlockRequest(jsonBytes, "application/json")
+	require.NoError(t, err)
+	require.Equal(t, EncodingJSON, encoding)
+	require.Equal(t, spec.DataVersionElectra, payload.Version)
+	require.Len(t, payload.Electra.ExecutionRequests.Consolidations, 1)
+
+	payload, encoding, err = DecodeSubmitBlockRequest(sszBytes, "application/octet-stream")
+	require.NoError(t, err)
+	require.Equal(t, EncodingSSZ, encoding)
+	require.Equal(t, spec.DataVersionElectra, payload.Version)
+	require.Len(t, payload.Electra.ExecutionRequests.Deposits, 1)
+
+	// Deneb submissions are still decoded as deneb
+	payload, _, err = DecodeSubmitBlockRequest(loadTestdata(t, "submitBlockPayloadDeneb_Goerli.json.gz"), "application/json")
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionDeneb, payload.Version)
+	payload, err = DecodeSubmitBlockRequestSSZ(loadTestdata(t, "submitBlockPayloadDeneb_Goerli.ssz.gz"))
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionDeneb, payload.Version)
+}
+
+func TestDecodeSubmitBlockRequestElectraValidation(t *testing.T) {
+	decode := func(modify func(payload *common.VersionedSubmitBlockRequest)) error {
+		payload := electraSubmission(t)
+		modify(payload)
+		body, err := payload.MarshalSSZ()
+		if err != nil {
+			body, err = json.Marshal(payload)
+			require.NoError(t, err)
+		}
+		_, _, err = DecodeSubmitBlockRequest(body, "application/octet-stream")
+		return err
+	}
+
+	require.NoError(t, decode(func(payload *common.VersionedSubmitBlockRequest) {}))
+	require.ErrorIs(t, decode(func(payload *common.VersionedSubmitBlockRequest) {
+		requests := payload.Electra.ExecutionRequests
+		requests.Consolidations = append(requests.Consolidations, requests.Consolidations[0], requests.Consolidations[0])
+	}), ErrTooManyRequests)
+	require.ErrorIs(t, decode(func(payload *common.VersionedSubmitBlockRequest) {
+		payload.Electra.BlobsBundle.Proofs = payload.Electra.BlobsBundle.Proofs[1:]
+	}), ErrBlobsBundleMismatch)
+
+	// Execution requests are required
+	payload := electraSubmission(t)
+	payload.Electra.ExecutionRequests = nil
+	require.ErrorIs(t, validateSubmitBlockRequest(payload), ErrMissingField)
+	payload = electraSubmission(t)
+	payload.Electra.ExecutionRequests.Withdrawals[0] = nil
+	require.ErrorIs(t, validateSubmitBlockRequest(payload), ErrMissingField)
+}
+
+func TestDecodeSignedBlindedBeaconBlock(t *testing.T) {
+	for _, filename := range []string{"signedBlindedBeaconBlockCapella_Goerli.json.gz", "signedBlindedBeaconBlockDeneb_Goerli.json.gz"} {
+		payload, err := DecodeSignedBlindedBeaconBlock(loadTestdata(t, filename))
+		require.NoError(t, err, filename)
+		_, err = payload.Slot()
+		require.NoError(t, err)
+	}
+
+	_, err := DecodeSignedBlindedBeaconBlock(nil)
+	require.ErrorIs(t, err, ErrEmptyBody)
+	_, err = DecodeSignedBlindedBeaconBlock([]byte("{}"))
+	require.Error(t, err)
+
+	// Data after the block is rejected
+	body := loadTestdata(t, "signedBlindedBeaconBlockDeneb_Goerli.json.gz")
+	_, err = DecodeSignedBlindedBeaconBlock(append(body, []byte("{}")...))
+	require.Error(t, err)
+}
+
+func TestDecodeSignedBlindedBeaconBlockElectra(t *testing.T) {
+	denebBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, json.Unmarshal(loadTestdata(t, "signedBlindedBeaconBlockDeneb_Goerli.json.gz"), denebBlock))
+	encode := func(executionRequests *electra.ExecutionRequests) []byte {
+		block := &eth2ApiV1Electra.SignedBlindedBeaconBlock{
+			Message:   common.ElectraBlindedBeaconBlockFromDeneb(denebBlock.Message, executionRequests),
+			Signature: denebBlock.Signature,
+		}
+		body, err := json.Marshal(block)
+		require.NoError(t, err)
+		return body
+	}
+
+	payload, err := DecodeSignedBlindedBeaconBlock(encode(common.TestExecutionRequests()))
+	require.NoError(t, err)
+	require.Equal(t, spec.DataVersionElectra, payload.Version)
+	require.Len(t, payload.Electra.Message.Body.ExecutionRequests.Withdrawals, 1)
+
+	executionRequests := common.TestExecutionRequests()
+	executionRequests.Consolidations = append(executionRequests.Consolidations, executionRequests.Consolidations[0], executionRequests.Consolidations[0])
+	_, err = DecodeSignedBlindedBeaconBlock(encode(executionRequests))
+	require.ErrorIs(t, err, ErrTooManyRequests)
+}
+
+func TestDecodeValidatorRegistration(t *testing.T) {
+	body := loadTestdata(t, "valreg1.json")
+	require.NoError(t, CheckValidatorRegistrations(body))
+	require.ErrorIs(t, CheckValidatorRegistrations(nil), ErrEmptyBody)
+	require.ErrorIs(t, CheckValidatorRegistrations([]byte(`{"message":{}}`)), ErrInvalidRegistrations)
+
+	value, _, _, err := jsonparser.Get(body, "[0]")
+	require.NoError(t, err)
+	reg, err := DecodeValidatorRegistration(value)
+	require.NoError(t, err)
+	require.Equal(t, uint64(30_000_000), reg.Message.GasLimit)
+	require.Equal(t, int64(1656684360), reg.Message.Timestamp.Unix())
+
+	negative, err := jsonparser.Set(value, []byte(`"-1"`), "message", "timestamp")
+	require.NoError(t, err)
+	_, err = DecodeValidatorRegistration(negative)
+	require.ErrorIs(t, err, ErrNegativeTimestamp)
+
+	shortPubkey, err := jsonparser.Set(value, []byte(`"0xb824"`), "message", "pubkey")
+	require.NoError(t, err)
+	_, err = DecodeValidatorRegistration(shortPubkey)
+	require.Error(t, err)
+}
+
+func FuzzDecodeSubmitBlockRequest(f *testing.F) {
+	for _, filename := range []string{"submitBlockPayloadCapella_Goerli.json.gz", "submitBlockPayloadDeneb_Goerli.json.gz"} {
+		f.Add(loadTestdata(f, filename), "application/json")
+	}
+	for _, filename := range []string{"submitBlockPayloadCapella_Goerli.ssz.gz", "submitBlockPayloadDeneb_Goerli.ssz.gz"} {
+		f.Add(loadTestdata(f, filename), "application/octet-stream")
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte, contentType string) {
+		payload, _, err := DecodeSubmitBlockRequest(body, contentType)
+		if err != nil {
+			return
+		}
+		// Decoded submissions can be processed without further checks
+		_, err = common.GetBlockSubmissionInfo(payload)
+		require.NoError(t, err)
+		_, err = payload.HashTreeRoot()
+		require.NoError(t, err)
+	})
+}
+
+func FuzzDecodeSignedBlindedBeaconBlock(f *testing.F) {
+	for _, filename := range []string{"signedBlindedBeaconBlockCapella_Goerli.json.gz", "signedBlindedBeaconBlockDeneb_Goerli.json.gz"} {
+		f.Add(loadTestdata(f, filename))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		payload, err := DecodeSignedBlindedBeaconBlock(body)
+		if err != nil {
+			return
+		}
+		_, err = payload.Slot()
+		require.NoError(t, err)
+		_, err = payload.ExecutionBlockHash()
+		require.NoError(t, err)
+	})
+}
+
+func FuzzDecodeValidatorRegistrations(f *testing.F) {
+	f.Add(loadTestdata(f, "valreg0.json"))
+	f.Add(loadTestdata(f, "valreg1.json"))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		if CheckValidatorRegistrations(body) != nil {
+			return
+		}
+		_, _ = jsonparser.ArrayEach(body, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+			reg, err := DecodeValidatorRegistration(value)
+			if err != nil {
+				return
+			}
+			// Decoded registrations survive a round trip
+			encoded, err := json.Marshal(reg)
+			require.NoError(t, err)
+			reg2, err := DecodeValidatorRegistration(encoded)
+			require.NoError(t, err)
+			require.Equal(t, reg.Message.Pubkey, reg2.Message.Pubkey)
+			require.Equal(t, reg.Message.Timestamp.Unix(), reg2.Message.Timestamp.Unix())
+			require.Equal(t, reg.Signature, reg2.Signature)
+		})
+	})
+}
@@ -0,0 +1,121 @@
+// Package events publishes relay activity (bids, delivered payloads, demotions, reorgs) to external sinks, i.e. for monitoring
+// and builder-facing dashboards
+package events
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	TypeBidAccepted      = "bid_accepted"
+	TypeBidRejected      = "bid_rejected"
+	TypePayloadDelivered = "payload_delivered"
+	TypeBuilderDemoted   = "builder_demoted"
+	TypeChainReorg       = "chain_reorg"
+
+	TypeBuilderQuarantined = "builder_quarantined"
+	TypeBuilderReleased    = "builder_released"
+
+	TypeFeatureFlagChanged = "feature_flag_changed"
+)
+
+// Event is the JSON message which is published to the sinks
+type Event struct {
+	Type        string `json:"type"`
+	TimestampMs int64  `json:"timestamp_ms"`
+
+	Slot           uint64 `json:"slot,string"`
+	BuilderPubkey  string `json:"builder_pubkey,omitempty"`
+	ProposerPubkey string `json:"proposer_pubkey,omitempty"`
+	BlockHash      string `json:"block_hash,omitempty"`
+	Value          string `json:"value,omitempty"`
+
+	// Only for rejected bids, demotions, quarantines, reorgs and feature flag changes
+	ErrorCode string `json:"error_code,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+
+	// Only for feature flag changes
+	FeatureFlag string `json:"feature_flag,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+	Actor       string `json:"actor,omitempty"`
+}
+
+// Sink is a destination for the events
+type Sink interface {
+	Name() string
+	Publish(event *Event) error
+	Close() error
+}
+
+// Publisher forwards events to the sinks in the background. Each sink has its own queue, so that a slow sink doesn't
+// hold up the others, and events are dropped if the queue of a sink is full.
+type Publisher struct {
+	log    *logrus.Entry
+	queues []chan *Event
+	wg     sync.WaitGroup
+}
+
+// NewPublisher creates the sinks from a comma separated list of URIs (see NewSink), and starts publishing
+func NewPublisher(log *logrus.Entry, sinkURIs string, queueSize int) (*Publisher, error) {
+	var sinks []Sink
+	for _, uri := range strings.Split(sinkURIs, ",") {
+		uri = strings.TrimSpace(uri)
+		if uri == "" {
+			continue
+		}
+		sink, err := NewSink(uri)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return NewPublisherWithSinks(log, sinks, queueSize), nil
+}
+
+func NewPublisherWithSinks(log *logrus.Entry, sinks []Sink, queueSize int) *Publisher {
+	p := &Publisher{
+		log: log.WithField("component", "events"),
+	}
+	for _, sink := range sinks {
+		queue := make(chan *Event, queueSize)
+		p.queues = append(p.queues, queue)
+		p.wg.Add(1)
+		go p.run(sink, queue)
+	}
+	return p
+}
+
+func (p *Publisher) run(sink Sink, queue chan *Event) {
+	defer p.wg.Done()
+	log := p.log.WithField("sink", sink.Name())
+	for event := range queue {
+		if err := sink.Publish(event); err != nil {
+			log.WithError(err).WithField("eventType", event.Type).Warn("failed to publish event")
+		}
+	}
+	if err := sink.Close(); err != nil {
+		log.WithError(err).Warn("failed to close event sink")
+	}
+}
+
+// Publish queues the event for all sinks, without blocking
+func (p *Publisher) Publish(event *Event) {
+	for _, queue := range p.queues {
+		select {
+		case queue <- event:
+		default:
+			p.log.WithField("eventType", event.Type).Warn("event queue full, dropping event")
+		}
+	}
+}
+
+// Close publishes the queued events and closes the sinks. Publish must not be called afterwards.
+func (p *Publisher) Close() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
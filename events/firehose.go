@@ -0,0 +1,113 @@
+package events
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BidTraceRecordSchemaVersion is incremented on incompatible changes of BidTraceRecord. Fields may be added without
+// changing the version.
+const BidTraceRecordSchemaVersion = 1
+
+// BidTraceRecord is the message of the bid firehose, for every received block submission
+type BidTraceRecord struct {
+	SchemaVersion int `json:"schema_version"`
+
+	Slot                 uint64 `json:"slot,string"`
+	ParentHash           string `json:"parent_hash"`
+	BlockHash            string `json:"block_hash"`
+	BuilderPubkey        string `json:"builder_pubkey"`
+	ProposerPubkey       string `json:"proposer_pubkey"`
+	ProposerFeeRecipient string `json:"proposer_fee_recipient"`
+	GasLimit             uint64 `json:"gas_limit,string"`
+	GasUsed              uint64 `json:"gas_used,string"`
+	Value                string `json:"value"`
+	BlockNumber          uint64 `json:"block_number,string"`
+	NumTx                uint64 `json:"num_tx,string"`
+
+	ReceivedAtMs  int64  `json:"received_at_ms"`
+	EligibleAtMs  int64  `json:"eligible_at_ms"`  // 0 if the bid never became eligible
+	SimDurationUs uint64 `json:"sim_duration_us"` // 0 if the block wasn't simulated
+
+	Accepted        bool   `json:"accepted"`
+	StatusCode      int    `json:"status_code"`
+	ErrorCode       string `json:"error_code,omitempty"`
+	RejectionReason string `json:"rejection_reason,omitempty"`
+}
+
+// BidFirehose streams the bid trace records to a Kafka topic, in batches. Records are dropped if the queue is full,
+// so that a slow Kafka proxy can't hold up block submissions.
+type BidFirehose struct {
+	log           *logrus.Entry
+	sink          *KafkaRESTSink
+	queue         chan *BidTraceRecord
+	batchSize     int
+	flushInterval time.Duration
+	wg            sync.WaitGroup
+}
+
+func NewBidFirehose(log *logrus.Entry, sink *KafkaRESTSink, queueSize, batchSize int, flushInterval time.Duration) *BidFirehose {
+	f := &BidFirehose{
+		log:           log.WithField("component", "bidFirehose"),
+		sink:          sink,
+		queue:         make(chan *BidTraceRecord, queueSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+	f.wg.Add(1)
+	go f.run()
+	return f
+}
+
+// Add queues a record, without blocking
+func (f *BidFirehose) Add(record *BidTraceRecord) {
+	record.SchemaVersion = BidTraceRecordSchemaVersion
+	select {
+	case f.queue <- record:
+	default:
+		f.log.Warn("bid firehose queue full, dropping record")
+	}
+}
+
+func (f *BidFirehose) run() {
+	defer f.wg.Done()
+	ticker := time.NewTicker(f.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]KafkaRecord, 0, f.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := f.sink.Produce(batch); err != nil {
+			f.log.WithError(err).WithField("numRecords", len(batch)).Warn("failed to produce bid trace records")
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case record, ok := <-f.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, KafkaRecord{Key: strconv.FormatUint(record.Slot, 10), Value: record})
+			if len(batch) >= f.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Close produces the queued records. Add must not be called afterwards.
+func (f *BidFirehose) Close() {
+	close(f.queue)
+	f.wg.Wait()
+	_ = f.sink.Close()
+}
@@ -0,0 +1,135 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var ErrNATSServer = errors.New("NATS server error")
+
+// NATSSink publishes the events to a NATS subject. It speaks the plain text core NATS protocol (CONNECT, PUB and
+// PING/PONG), and reconnects on the next event if the connection is lost.
+type NATSSink struct {
+	addr     string
+	subject  string
+	user     string
+	password string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewNATSSink(u *url.URL, subject string) *NATSSink {
+	s := &NATSSink{
+		addr:    u.Host,
+		subject: subject,
+	}
+	if u.User != nil {
+		s.user = u.User.Username()
+		s.password, _ = u.User.Password()
+	}
+	return s
+}
+
+func (s *NATSSink) Name() string {
+	return "nats " + s.subject
+}
+
+func (s *NATSSink) Publish(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\n", s.subject, len(payload), payload)
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// connect opens the connection and sends CONNECT after the INFO of the server. Must be called with the lock held.
+func (s *NATSSink) connect() error {
+	conn, err := net.DialTimeout("tcp", s.addr, defaultSinkTimeout)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("%w: unexpected greeting %q", ErrNATSServer, strings.TrimSpace(line))
+	}
+
+	connectOpts, err := json.Marshal(map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "mev-boost-relay",
+		"user":     s.user,
+		"pass":     s.password,
+	})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := conn.Write([]byte("CONNECT " + string(connectOpts) + "\r\n")); err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.conn = conn
+	go s.readLoop(conn, r)
+	return nil
+}
+
+// readLoop answers the PINGs of the server (which closes the connection otherwise), and drops the connection on errors
+func (s *NATSSink) readLoop(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil || strings.HasPrefix(line, "-ERR") {
+			s.mu.Lock()
+			if s.conn == conn {
+				s.conn.Close()
+				s.conn = nil
+			}
+			s.mu.Unlock()
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			s.mu.Lock()
+			_, _ = conn.Write([]byte("PONG\r\n"))
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
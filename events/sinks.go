@@ -0,0 +1,164 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSinkURI    = errors.New("invalid event sink URI")
+	ErrUnexpectedStatus  = errors.New("unexpected status code")
+	defaultSinkTimeout   = 5 * time.Second
+	kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+)
+
+// NewSink creates a sink from its URI:
+//
+//   - http(s)://host/path - webhook, each event is POSTed as JSON
+//   - nats://[user:password@]host:port/subject - published to the NATS subject
+//   - kafka+http(s)://host:port/topic - produced to the Kafka topic through a Kafka REST proxy (i.e. the Redpanda HTTP
+//     proxy or the Confluent REST proxy)
+func NewSink(uri string) (Sink, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSinkURI, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: missing host in %s", ErrInvalidSinkURI, uri)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return NewWebhookSink(uri), nil
+	case "nats":
+		subject := strings.TrimPrefix(u.Path, "/")
+		if subject == "" {
+			return nil, fmt.Errorf("%w: missing NATS subject in %s", ErrInvalidSinkURI, uri)
+		}
+		return NewNATSSink(u, subject), nil
+	case "kafka+http", "kafka+https":
+		return newKafkaRESTSinkFromURL(u)
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %s", ErrInvalidSinkURI, u.Scheme)
+	}
+}
+
+// WebhookSink POSTs the events as JSON to a URL
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(webhookURL string) *WebhookSink {
+	return &WebhookSink{
+		url:    webhookURL,
+		client: &http.Client{Timeout: defaultSinkTimeout},
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return "webhook"
+	}
+	return "webhook " + u.Host // without credentials and path
+}
+
+func (s *WebhookSink) Publish(event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return postJSON(s.client, s.url, "application/json", body)
+}
+
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// NewKafkaRESTSinkFromURI creates a Kafka sink from a kafka+http(s)://host:port/topic URI
+func NewKafkaRESTSinkFromURI(uri string) (*KafkaRESTSink, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSinkURI, err)
+	}
+	if u.Scheme != "kafka+http" && u.Scheme != "kafka+https" {
+		return nil, fmt.Errorf("%w: unsupported scheme %s for Kafka", ErrInvalidSinkURI, u.Scheme)
+	}
+	return newKafkaRESTSinkFromURL(u)
+}
+
+func newKafkaRESTSinkFromURL(u *url.URL) (*KafkaRESTSink, error) {
+	topic := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || topic == "" {
+		return nil, fmt.Errorf("%w: missing Kafka REST proxy host or topic in %s", ErrInvalidSinkURI, u.Redacted())
+	}
+	proxyURL := strings.TrimPrefix(u.Scheme, "kafka+") + "://" + u.Host
+	return NewKafkaRESTSink(proxyURL, topic), nil
+}
+
+// KafkaRESTSink produces the events to a Kafka topic through the v2 API of a Kafka REST proxy, keyed by the slot
+type KafkaRESTSink struct {
+	proxyURL string
+	topic    string
+	client   *http.Client
+}
+
+func NewKafkaRESTSink(proxyURL, topic string) *KafkaRESTSink {
+	return &KafkaRESTSink{
+		proxyURL: proxyURL,
+		topic:    topic,
+		client:   &http.Client{Timeout: defaultSinkTimeout},
+	}
+}
+
+func (s *KafkaRESTSink) Name() string {
+	return "kafka " + s.topic
+}
+
+// KafkaRecord is a message to produce to Kafka, the value is JSON encoded
+type KafkaRecord struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+func (s *KafkaRESTSink) Publish(event *Event) error {
+	return s.Produce([]KafkaRecord{{Key: strconv.FormatUint(event.Slot, 10), Value: event}})
+}
+
+// Produce sends a batch of records to the topic in one request
+func (s *KafkaRESTSink) Produce(records []KafkaRecord) error {
+	body, err := json.Marshal(map[string][]KafkaRecord{"records": records})
+	if err != nil {
+		return err
+	}
+	return postJSON(s.client, s.proxyURL+"/topics/"+url.PathEscape(s.topic), kafkaRESTContentType, body)
+}
+
+func (s *KafkaRESTSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func postJSON(client *http.Client, targetURL, contentType string, body []byte) error {
+	resp, err := client.Post(targetURL, contentType, bytes.NewReader(body)) //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+	return nil
+}
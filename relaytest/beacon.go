@@ -0,0 +1,245 @@
+package relaytest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+// BeaconClient is a beacon node with a scripted chain: the cluster moves the head and emits the events, the validators
+// propose in turns (round-robin by slot), and published blocks are recorded.
+type BeaconClient struct {
+	mu sync.Mutex
+
+	genesisTime uint64
+	validators  []*Validator
+	headSlot    uint64
+	slots       map[uint64]*Slot // by proposal slot
+	started     bool
+
+	headC    []chan beaconclient.HeadEventData
+	payloadC []chan beaconclient.PayloadAttributesEvent
+	reorgC   []chan beaconclient.ChainReorgEvent
+
+	publishCode int
+	published   []*common.VersionedSignedProposal
+}
+
+func newBeaconClient(genesisTime, headSlot uint64, validators []*Validator) *BeaconClient {
+	return &BeaconClient{
+		genesisTime: genesisTime,
+		validators:  validators,
+		headSlot:    headSlot,
+		slots:       make(map[uint64]*Slot),
+		publishCode: 200,
+	}
+}
+
+// proposer returns the validator proposing at the slot
+func (b *BeaconClient) proposer(slot uint64) *Validator {
+	return b.validators[slot%uint64(len(b.validators))]
+}
+
+func (b *BeaconClient) isSubscribed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.headC) > 0 && len(b.payloadC) > 0 && len(b.reorgC) > 0
+}
+
+// markStarted switches the sync status to the wall clock, once the relay has read the scripted head on startup
+func (b *BeaconClient) markStarted() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.started = true
+}
+
+// emitHead moves the head, and sends the head event to the subscribers
+func (b *BeaconClient) emitHead(slot uint64) {
+	b.mu.Lock()
+	b.headSlot = slot
+	subscribers := append([]chan beaconclient.HeadEventData(nil), b.headC...)
+	b.mu.Unlock()
+
+	for _, c := range subscribers {
+		c <- beaconclient.HeadEventData{Slot: slot} //nolint:exhaustruct
+	}
+}
+
+// emitPayloadAttributes sends the payload attributes of the slot to the subscribers
+func (b *BeaconClient) emitPayloadAttributes(slot *Slot) {
+	b.mu.Lock()
+	b.slots[slot.Slot] = slot
+	subscribers := append([]chan beaconclient.PayloadAttributesEvent(nil), b.payloadC...)
+	b.mu.Unlock()
+
+	event := beaconclient.PayloadAttributesEvent{
+		Version: "deneb",
+		Data: beaconclient.PayloadAttributesEventData{
+			ProposerIndex:     slot.Proposer.Index,
+			ProposalSlot:      slot.Slot,
+			ParentBlockNumber: slot.ParentBlockNumber,
+			ParentBlockRoot:   slot.ParentBeaconRoot.String(),
+			ParentBlockHash:   slot.ParentHash.String(),
+			PayloadAttributes: beaconclient.PayloadAttributes{
+				Timestamp:             slot.Timestamp,
+				PrevRandao:            slot.PrevRandao.String(),
+				SuggestedFeeRecipient: slot.Proposer.FeeRecipient.String(),
+				Withdrawals:           slot.Withdrawals,
+				ParentBeaconBlockRoot: slot.ParentBeaconRoot.String(),
+			},
+		},
+	}
+	for _, c := range subscribers {
+		c <- event
+	}
+}
+
+// EmitReorg sends a chain reorg event to the subscribers
+func (b *BeaconClient) EmitReorg(event beaconclient.ChainReorgEvent) {
+	b.mu.Lock()
+	subscribers := append([]chan beaconclient.ChainReorgEvent(nil), b.reorgC...)
+	b.mu.Unlock()
+
+	for _, c := range subscribers {
+		c <- event
+	}
+}
+
+// SetPublishCode sets the status code of PublishBlock, i.e. to simulate a beacon node rejecting blocks
+func (b *BeaconClient) SetPublishCode(code int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.publishCode = code
+}
+
+// PublishedBlocks returns the blocks which were published so far
+func (b *BeaconClient) PublishedBlocks() []*common.VersionedSignedProposal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*common.VersionedSignedProposal(nil), b.published...)
+}
+
+// BestSyncStatus reports the scripted head until the relay has started. Afterwards it reports the wall clock slot, as
+// the scripted slots are far in the past (so that getPayload doesn't wait for the slot to start), and the relay would
+// otherwise consider the beacon node to be lagging behind.
+func (b *BeaconClient) BestSyncStatus() (*beaconclient.SyncStatusPayloadData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	headSlot := b.headSlot
+	if wallClockSlot := (uint64(time.Now().Unix()) - b.genesisTime) / common.SecondsPerSlot; b.started && wallClockSlot > headSlot {
+		headSlot = wallClockSlot
+	}
+	return &beaconclient.SyncStatusPayloadData{HeadSlot: headSlot, IsSyncing: false}, nil
+}
+
+func (b *BeaconClient) SubscribeToHeadEvents(slotC chan beaconclient.HeadEventData) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.headC = append(b.headC, slotC)
+}
+
+func (b *BeaconClient) SubscribeToPayloadAttributesEvents(payloadAttrC chan beaconclient.PayloadAttributesEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.payloadC = append(b.payloadC, payloadAttrC)
+}
+
+func (b *BeaconClient) SubscribeToChainReorgEvents(reorgC chan beaconclient.ChainReorgEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reorgC = append(b.reorgC, reorgC)
+}
+
+func (b *BeaconClient) GetStateValidators(stateID string) (*beaconclient.GetStateValidatorsResponse, error) {
+	resp := &beaconclient.GetStateValidatorsResponse{} //nolint:exhaustruct
+	for _, v := range b.validators {
+		resp.Data = append(resp.Data, v.stateEntry())
+	}
+	return resp, nil
+}
+
+func (b *BeaconClient) GetPendingStateValidators(stateID string) (*beaconclient.GetStateValidatorsResponse, error) {
+	return &beaconclient.GetStateValidatorsResponse{}, nil //nolint:exhaustruct
+}
+
+func (b *BeaconClient) GetStateValidator(stateID, pubkey string) (*beaconclient.GetStateValidatorResponse, error) {
+	for _, v := range b.validators {
+		if v.Pubkey.String() == pubkey {
+			return &beaconclient.GetStateValidatorResponse{Data: v.stateEntry()}, nil //nolint:exhaustruct
+		}
+	}
+	return nil, beaconclient.ErrValidatorNotFound
+}
+
+func (b *BeaconClient) GetProposerDuties(epoch uint64) (*beaconclient.ProposerDutiesResponse, error) {
+	resp := &beaconclient.ProposerDutiesResponse{}
+	for slot := epoch * common.SlotsPerEpoch; slot < (epoch+1)*common.SlotsPerEpoch; slot++ {
+		v := b.proposer(slot)
+		resp.Data = append(resp.Data, beaconclient.ProposerDutiesResponseData{
+			Slot:           slot,
+			Pubkey:         v.Pubkey.String(),
+			ValidatorIndex: v.Index,
+		})
+	}
+	return resp, nil
+}
+
+func (b *BeaconClient) PublishBlock(block *common.VersionedSignedProposal) (code int, results *beaconclient.PublishResults, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, block)
+	return b.publishCode, nil, nil
+}
+
+func (b *BeaconClient) GetGenesis() (*beaconclient.GetGenesisResponse, error) {
+	resp := &beaconclient.GetGenesisResponse{} //nolint:exhaustruct
+	resp.Data.GenesisTime = b.genesisTime
+	return resp, nil
+}
+
+func (b *BeaconClient) GetSpec() (spec *beaconclient.GetSpecResponse, err error) {
+	resp := &beaconclient.GetSpecResponse{} //nolint:exhaustruct
+	resp.Data.SecondsPerSlot = common.SecondsPerSlot
+	resp.Data.SlotsPerEpoch = common.SlotsPerEpoch
+	return resp, nil
+}
+
+// GetForkSchedule returns capella and deneb at genesis
+func (b *BeaconClient) GetForkSchedule() (spec *beaconclient.GetForkScheduleResponse, err error) {
+	resp := &beaconclient.GetForkScheduleResponse{
+		Data: []struct {
+			PreviousVersion string `json:"previous_version"`
+			CurrentVersion  string `json:"current_version"`
+			Epoch           uint64 `json:"epoch,string"`
+		}{
+			{PreviousVersion: "0x02000000", CurrentVersion: "0x03000000", Epoch: 0},
+			{PreviousVersion: "0x03000000", CurrentVersion: "0x04000000", Epoch: 0},
+		},
+	}
+	return resp, nil
+}
+
+// GetRandao returns the prev_randao of the payload attributes which were built on the slot
+func (b *BeaconClient) GetRandao(slot uint64) (spec *beaconclient.GetRandaoResponse, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := &beaconclient.GetRandaoResponse{}
+	if s, ok := b.slots[slot+1]; ok {
+		resp.Data.Randao = s.PrevRandao.String()
+	}
+	return resp, nil
+}
+
+func (b *BeaconClient) GetWithdrawals(slot uint64) (spec *beaconclient.GetWithdrawalsResponse, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := &beaconclient.GetWithdrawalsResponse{}
+	resp.Data.Withdrawals = []*capella.Withdrawal{}
+	if s, ok := b.slots[slot]; ok {
+		resp.Data.Withdrawals = s.Withdrawals
+	}
+	return resp, nil
+}
@@ -0,0 +1,377 @@
+// Package relaytest runs the full relay stack in-process for integration tests, without Docker: the API service on a
+// local port with Redis (miniredis), an in-memory database, a beacon node with a scripted chain, and a block simulation
+// node. The cluster drives the slot lifecycle like mev-boost and the builders do:
+//
+//	c := relaytest.NewCluster(t, relaytest.Options{})
+//	c.RegisterValidators(c.Validators...)
+//	slot := c.NextSlot()
+//	c.SubmitBlock(c.BuildBlock(relaytest.NewBuilder(t), slot, 100))
+//	bid := c.GetHeader(slot)
+//	payload := c.GetPayload(slot, bid)
+package relaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/flashbots/mev-boost-relay/services/api"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// head slot of the scripted chain when the relay starts (more than an epoch, so that the known validators are
+	// loaded on startup)
+	startSlot = uint64(63)
+
+	// the relay updates its proposer duties every this many slots
+	proposerDutiesInterval = uint64(8)
+
+	waitTimeout  = 10 * time.Second
+	pollInterval = 10 * time.Millisecond
+)
+
+// Options of the cluster, all optional
+type Options struct {
+	// Number of validators of the scripted chain (default 1), which propose in turns
+	NumValidators int
+
+	// Database of the relay (default a MemoryDB), i.e. a database.DatabaseService for a test database
+	DB database.IDatabaseService
+
+	// ConfigureRelay is called with the options of the relay before it's created, i.e. to set tunables or feature flags
+	ConfigureRelay func(opts *api.RelayAPIOpts)
+}
+
+// Cluster is a running relay with its dependencies
+type Cluster struct {
+	URL        string
+	Relay      *api.RelayAPI
+	Redis      *miniredis.Miniredis
+	RedisCache *datastore.RedisCache
+	Datastore  *datastore.Datastore
+	DB         database.IDatabaseService
+	Beacon     *BeaconClient
+	SimNode    *SimNode
+	Network    *common.EthNetworkDetails
+	Validators []*Validator
+
+	t      testing.TB
+	client *http.Client
+
+	headSlot          uint64
+	parentHash        phase0.Hash32
+	parentBlockNumber uint64
+	dutiesSlot        uint64 // head slot of the last proposer duties update of the relay
+	dutiesChanged     bool   // if registrations changed since
+	registered        map[phase0.BLSPubKey]bool
+}
+
+// Response is the response of the relay to a request
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// NewCluster starts a relay with the proposer, builder, data and internal APIs, and returns once it's ready. The
+// relay stops serving at the end of the test.
+func NewCluster(t testing.TB, opts Options) *Cluster {
+	t.Helper()
+	if opts.NumValidators == 0 {
+		opts.NumValidators = 1
+	}
+	if opts.DB == nil {
+		opts.DB = NewMemoryDB()
+	}
+
+	network, err := common.NewEthNetworkDetails(common.EthNetworkMainnet)
+	require.NoError(t, err)
+
+	redis, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(redis.Close)
+	redisCache, err := datastore.NewRedisCache("", redis.Addr(), "")
+	require.NoError(t, err)
+	ds, err := datastore.NewDatastore(redisCache, nil, opts.DB)
+	require.NoError(t, err)
+
+	validators := make([]*Validator, opts.NumValidators)
+	for i := range validators {
+		validators[i] = newValidator(t, uint64(i))
+	}
+
+	// The scripted slots are in the past, so that getPayload doesn't wait for the slot to start
+	genesisTime := uint64(time.Now().Add(-24 * time.Hour).Unix())
+	beacon := newBeaconClient(genesisTime, startSlot, validators)
+
+	simNode := newSimNode()
+	t.Cleanup(simNode.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	sk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+
+	tunables := api.DefaultTunables()
+	tunables.GetHeaderRequestCutoffMs = 0
+	tunables.GetPayloadRequestCutoffMs = 0
+	tunables.GetPayloadResponseDelayMs = 0
+
+	relayOpts := api.RelayAPIOpts{
+		Log:             common.TestLog,
+		ListenAddr:      listener.Addr().String(),
+		Listener:        listener,
+		BlockSimURL:     simNode.URL,
+		BeaconClient:    beacon,
+		Datastore:       ds,
+		Redis:           redisCache,
+		DB:              opts.DB,
+		SecretKey:       sk,
+		EthNetDetails:   *network,
+		ProposerAPI:     true,
+		BlockBuilderAPI: true,
+		DataAPI:         true,
+		InternalAPI:     true,
+		Tunables:        &tunables,
+	}
+	if opts.ConfigureRelay != nil {
+		opts.ConfigureRelay(&relayOpts)
+	}
+	relay, err := api.NewRelayAPI(relayOpts)
+	require.NoError(t, err)
+
+	c := &Cluster{
+		URL:        "http://" + listener.Addr().String(),
+		Relay:      relay,
+		Redis:      redis,
+		RedisCache: redisCache,
+		Datastore:  ds,
+		DB:         opts.DB,
+		Beacon:     beacon,
+		SimNode:    simNode,
+		Network:    network,
+		Validators: validators,
+
+		t:          t,
+		client:     &http.Client{Timeout: waitTimeout},
+		headSlot:   startSlot,
+		dutiesSlot: startSlot,
+		registered: make(map[phase0.BLSPubKey]bool),
+	}
+
+	go func() {
+		if err := relay.StartServer(); err != nil && !errors.Is(err, net.ErrClosed) {
+			common.TestLog.WithError(err).Error("relay stopped")
+		}
+	}()
+	c.waitFor("relay to be ready", func() bool {
+		return c.Request(http.MethodGet, "/readyz", nil).StatusCode == http.StatusOK && beacon.isSubscribed()
+	})
+	beacon.markStarted()
+	return c
+}
+
+// Request sends a request to the relay, with the body encoded as JSON unless it's a byte slice
+func (c *Cluster) Request(method, path string, body any) *Response {
+	c.t.Helper()
+	return c.RequestWithHeaders(method, path, body, nil)
+}
+
+// RequestWithHeaders sends a request to the relay with additional headers, i.e. the ones of a specific client
+func (c *Cluster) RequestWithHeaders(method, path string, body any, headers map[string]string) *Response {
+	c.t.Helper()
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	default:
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(c.t, err)
+	}
+
+	req, err := http.NewRequest(method, c.URL+path, bytes.NewReader(payload))
+	require.NoError(c.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &Response{StatusCode: 0, Body: []byte(err.Error())}
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}
+}
+
+// RegisterValidators registers the validators with the relay, and waits for the registrations to be saved
+func (c *Cluster) RegisterValidators(validators ...*Validator) {
+	c.t.Helper()
+	registrations := make([]builderApiV1.SignedValidatorRegistration, len(validators))
+	for i, v := range validators {
+		registrations[i] = c.signedRegistration(v)
+	}
+	resp := c.Request(http.MethodPost, "/eth/v1/builder/validators", registrations)
+	require.Equal(c.t, http.StatusOK, resp.StatusCode, string(resp.Body))
+
+	for i, v := range validators {
+		timestamp := uint64(registrations[i].Message.Timestamp.Unix())
+		c.waitFor("registration to be saved", func() bool {
+			entry, err := c.DB.GetValidatorRegistration(v.Pubkey.String())
+			return err == nil && entry != nil && entry.Timestamp >= timestamp
+		})
+		c.registered[v.Pubkey] = true
+	}
+	c.dutiesChanged = true
+}
+
+// NextSlot moves the head of the chain, sends the payload attributes of the following slot, and returns that slot
+// once the relay has processed them. Slots are skipped until the relay updates its proposer duties if registrations
+// changed, so that submissions for the returned slot are accepted.
+func (c *Cluster) NextSlot() *Slot {
+	c.t.Helper()
+	headSlot := c.headSlot + 1
+	for c.dutiesChanged && headSlot%proposerDutiesInterval != 0 && headSlot-c.dutiesSlot < proposerDutiesInterval {
+		headSlot++
+	}
+	if headSlot%proposerDutiesInterval == 0 || headSlot-c.dutiesSlot >= proposerDutiesInterval {
+		c.dutiesSlot = headSlot
+		c.dutiesChanged = false
+	}
+
+	// Update the proposer duties in Redis like the housekeeper does, and move the head
+	duties, err := datastore.BuildProposerDuties(common.TestLog, c.Beacon, c.DB, common.SlotToEpoch(headSlot))
+	require.NoError(c.t, err)
+	require.NoError(c.t, c.RedisCache.SetProposerDuties(duties))
+	c.Beacon.emitHead(headSlot)
+	c.headSlot = headSlot
+	c.waitFor("head slot", func() bool {
+		var status api.RelayStatusResponse
+		resp := c.Request(http.MethodGet, "/relay/v1/status", nil)
+		return resp.StatusCode == http.StatusOK && json.Unmarshal(resp.Body, &status) == nil && status.HeadSlot == headSlot
+	})
+
+	slot := &Slot{
+		Slot:              headSlot + 1,
+		Proposer:          c.Beacon.proposer(headSlot + 1),
+		ParentHash:        c.parentHash,
+		ParentBlockNumber: c.parentBlockNumber,
+		ParentBeaconRoot:  phase0.Root(randomBytes(c.t, 32)),
+		PrevRandao:        phase0.Hash32(randomBytes(c.t, 32)),
+		Timestamp:         c.Beacon.genesisTime + (headSlot+1)*common.SecondsPerSlot,
+		Withdrawals:       []*capella.Withdrawal{},
+	}
+	if slot.ParentHash == (phase0.Hash32{}) {
+		// no payload was delivered in the last slot, the head is a block of another relay
+		slot.ParentHash = phase0.Hash32(randomBytes(c.t, 32))
+		slot.ParentBlockNumber = 1000 + headSlot
+	}
+	c.parentHash = phase0.Hash32{}
+
+	if c.registered[slot.Proposer.Pubkey] {
+		c.waitFor("proposer duty", func() bool {
+			var duties []common.BuilderGetValidatorsResponseEntry
+			resp := c.Request(http.MethodGet, "/relay/v1/builder/validators", nil)
+			if resp.StatusCode != http.StatusOK || json.Unmarshal(resp.Body, &duties) != nil {
+				return false
+			}
+			for _, duty := range duties {
+				if duty.Slot == slot.Slot {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	c.Beacon.emitPayloadAttributes(slot)
+	c.waitFor("payload attributes", func() bool {
+		var slotCtx api.SlotContextJSON
+		resp := c.Request(http.MethodGet, "/internal/v1/slot_context", nil)
+		return resp.StatusCode == http.StatusOK && json.Unmarshal(resp.Body, &slotCtx) == nil &&
+			slotCtx.Slot == slot.Slot && slotCtx.ParentHash == slot.ParentHash.String()
+	})
+	return slot
+}
+
+// SubmitBlock submits the block to the relay
+func (c *Cluster) SubmitBlock(payload *common.VersionedSubmitBlockRequest) *Response {
+	c.t.Helper()
+	return c.Request(http.MethodPost, "/relay/v1/builder/blocks", payload)
+}
+
+// GetHeader requests the best bid for the slot like the proposer does, and returns nil if there is none
+func (c *Cluster) GetHeader(slot *Slot) *builderSpec.VersionedSignedBuilderBid {
+	c.t.Helper()
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot.Slot, slot.ParentHash.String(), slot.Proposer.Pubkey.String())
+	resp := c.Request(http.MethodGet, path, nil)
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	require.Equal(c.t, http.StatusOK, resp.StatusCode, string(resp.Body))
+	bid := new(builderSpec.VersionedSignedBuilderBid)
+	require.NoError(c.t, json.Unmarshal(resp.Body, bid))
+	return bid
+}
+
+// GetPayload signs the blinded block of the bid like the proposer does, and returns the payload of the relay. The next
+// slot is built on the delivered block.
+func (c *Cluster) GetPayload(slot *Slot, bid *builderSpec.VersionedSignedBuilderBid) *builderApi.VersionedSubmitBlindedBlockResponse {
+	c.t.Helper()
+	resp := c.Request(http.MethodPost, "/eth/v1/builder/blinded_blocks", c.SignBlindedBlock(slot, bid))
+	require.Equal(c.t, http.StatusOK, resp.StatusCode, string(resp.Body))
+	payload := new(builderApi.VersionedSubmitBlindedBlockResponse)
+	require.NoError(c.t, json.Unmarshal(resp.Body, payload))
+
+	if slot.Slot == c.headSlot+1 {
+		header := bid.Deneb.Message.Header
+		c.parentHash = header.BlockHash
+		c.parentBlockNumber = header.BlockNumber
+	}
+	return payload
+}
+
+// RunSlot runs the lifecycle of the next slot: the builder submits a block with the value, and the proposer gets the
+// header and the payload. The proposer of the slot must be registered.
+func (c *Cluster) RunSlot(builder *Builder, value uint64) (*Slot, *builderApi.VersionedSubmitBlindedBlockResponse) {
+	c.t.Helper()
+	slot := c.NextSlot()
+	resp := c.SubmitBlock(c.BuildBlock(builder, slot, value))
+	require.Equal(c.t, http.StatusOK, resp.StatusCode, string(resp.Body))
+	bid := c.GetHeader(slot)
+	require.NotNil(c.t, bid)
+	return slot, c.GetPayload(slot, bid)
+}
+
+func (c *Cluster) waitFor(what string, condition func() bool) {
+	c.t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			require.FailNow(c.t, "timeout waiting for "+what)
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,62 @@
+package relaytest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSlotLifecycle(t *testing.T) {
+	c := NewCluster(t, Options{NumValidators: 2})
+	db := c.DB.(*MemoryDB)
+	builder1, builder2 := NewBuilder(t), NewBuilder(t)
+
+	// Submissions are rejected for proposers which are not registered
+	slot := c.NextSlot()
+	resp := c.SubmitBlock(c.BuildBlock(builder1, slot, 100))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Contains(t, string(resp.Body), "could not find slot duty")
+
+	c.RegisterValidators(c.Validators...)
+	for i := 0; i < 2; i++ {
+		slot = c.NextSlot()
+		payload1 := c.BuildBlock(builder1, slot, 100)
+		payload2 := c.BuildBlock(builder2, slot, 200)
+		resp = c.SubmitBlock(payload1)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(resp.Body))
+		resp = c.SubmitBlock(payload2)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(resp.Body))
+
+		// The top bid is served and delivered
+		bid := c.GetHeader(slot)
+		require.NotNil(t, bid)
+		blockHash, err := bid.BlockHash()
+		require.NoError(t, err)
+		require.Equal(t, payload2.Deneb.ExecutionPayload.BlockHash, blockHash)
+
+		getPayloadResp := c.GetPayload(slot, bid)
+		require.Equal(t, payload2.Deneb.ExecutionPayload.BlockHash, getPayloadResp.Deneb.ExecutionPayload.BlockHash)
+		require.Len(t, db.DeliveredPayloads(slot.Slot), 1)
+	}
+	require.Len(t, c.Beacon.PublishedBlocks(), 2)
+	require.Equal(t, 4, c.SimNode.NumRequests("flashbots_validateBuilderSubmissionV3"))
+
+	// The next slot is built on the delivered block
+	parentHash := slot.ParentHash
+	_, getPayloadResp := c.RunSlot(builder1, 300)
+	require.Equal(t, c.NextSlot().ParentHash, getPayloadResp.Deneb.ExecutionPayload.BlockHash)
+	require.NotEqual(t, parentHash, getPayloadResp.Deneb.ExecutionPayload.BlockHash)
+}
+
+func TestClusterSimulationFailure(t *testing.T) {
+	c := NewCluster(t, Options{})
+	c.RegisterValidators(c.Validators...)
+	slot := c.NextSlot()
+
+	c.SimNode.SetError("invalid block")
+	resp := c.SubmitBlock(c.BuildBlock(NewBuilder(t), slot, 100))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Contains(t, string(resp.Body), "invalid block")
+	require.Nil(t, c.GetHeader(slot))
+}
@@ -0,0 +1,304 @@
+package relaytest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+)
+
+// MemoryDB is an in-memory database for the cluster. It stores the validator registrations, block submissions and
+// delivered payloads which the slot lifecycle depends on, and behaves like database.MockDB otherwise.
+type MemoryDB struct {
+	database.MockDB
+
+	mu                sync.Mutex
+	registrations     map[string]*database.ValidatorRegistrationEntry
+	submissions       []*database.BuilderBlockSubmissionEntry
+	execPayloads      []*database.ExecutionPayloadEntry
+	deliveredPayloads []*database.DeliveredPayloadEntry
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		MockDB: database.MockDB{
+			ExecPayloads:        make(map[string]*database.ExecutionPayloadEntry),
+			Builders:            make(map[string]*database.BlockBuilderEntry),
+			Demotions:           make(map[string]bool),
+			Refunds:             make(map[string]bool),
+			ProposerPreferences: make(map[string]*database.ProposerPreferencesEntry),
+		},
+		registrations: make(map[string]*database.ValidatorRegistrationEntry),
+	}
+}
+
+func (db *MemoryDB) NumRegisteredValidators() (count uint64, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return uint64(len(db.registrations)), nil
+}
+
+func (db *MemoryDB) SaveValidatorRegistration(entry database.ValidatorRegistrationEntry) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if prev, ok := db.registrations[entry.Pubkey]; ok && prev.Timestamp >= entry.Timestamp {
+		return nil
+	}
+	entry.InsertedAt = time.Now()
+	db.registrations[entry.Pubkey] = &entry
+	return nil
+}
+
+func (db *MemoryDB) GetValidatorRegistration(pubkey string) (*database.ValidatorRegistrationEntry, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	entry, ok := db.registrations[pubkey]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (db *MemoryDB) GetValidatorRegistrationsForPubkeys(pubkeys []string) (entries []*database.ValidatorRegistrationEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, pubkey := range pubkeys {
+		if entry, ok := db.registrations[pubkey]; ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (db *MemoryDB) GetLatestValidatorRegistrations(timestampOnly bool) ([]*database.ValidatorRegistrationEntry, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	entries := make([]*database.ValidatorRegistrationEntry, 0, len(db.registrations))
+	for _, entry := range db.registrations {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (db *MemoryDB) SaveBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest, requestError, validationError error, receivedAt, decodedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission, trustedSubmission bool) (entry *database.BuilderBlockSubmissionEntry, err error) {
+	execPayloadEntry, err := database.PayloadToExecPayloadEntry(payload)
+	if err != nil {
+		return nil, err
+	}
+	submission, err := common.GetBlockSubmissionInfo(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if saveExecPayload {
+		execPayloadEntry.ID = int64(len(db.execPayloads) + 1)
+		execPayloadEntry.InsertedAt = time.Now()
+		db.execPayloads = append(db.execPayloads, execPayloadEntry)
+		db.ExecPayloads[fmt.Sprintf("%d-%s-%s", execPayloadEntry.Slot, execPayloadEntry.ProposerPubkey, execPayloadEntry.BlockHash)] = execPayloadEntry
+	}
+
+	entry = &database.BuilderBlockSubmissionEntry{
+		ID:                 int64(len(db.submissions) + 1),
+		InsertedAt:         time.Now(),
+		ReceivedAt:         database.NewNullTime(receivedAt),
+		EligibleAt:         database.NewNullTime(eligibleAt),
+		ExecutionPayloadID: sql.NullInt64{Int64: execPayloadEntry.ID, Valid: saveExecPayload},
+
+		WasSimulated: wasSimulated,
+		SimSuccess:   wasSimulated && validationError == nil,
+
+		Signature: submission.Signature.String(),
+
+		Slot:       submission.BidTrace.Slot,
+		BlockHash:  submission.BidTrace.BlockHash.String(),
+		ParentHash: submission.BidTrace.ParentHash.String(),
+
+		BuilderPubkey:        submission.BidTrace.BuilderPubkey.String(),
+		ProposerPubkey:       submission.BidTrace.ProposerPubkey.String(),
+		ProposerFeeRecipient: submission.BidTrace.ProposerFeeRecipient.String(),
+
+		GasUsed:  submission.GasUsed,
+		GasLimit: submission.GasLimit,
+
+		NumTx:    uint64(len(submission.Transactions)),
+		NumBlobs: uint64(len(submission.Blobs)),
+		Value:    submission.BidTrace.Value.Dec(),
+
+		Epoch:       submission.BidTrace.Slot / common.SlotsPerEpoch,
+		BlockNumber: submission.BlockNumber,
+
+		OptimisticSubmission: optimisticSubmission,
+		TrustedSubmission:    trustedSubmission,
+		InstanceID:           common.InstanceID,
+	}
+	if validationError != nil {
+		entry.SimError = validationError.Error()
+	}
+	if requestError != nil {
+		entry.SimReqError = requestError.Error()
+	}
+	db.submissions = append(db.submissions, entry)
+	return entry, nil
+}
+
+func (db *MemoryDB) GetExecutionPayloadEntryByID(executionPayloadID int64) (entry *database.ExecutionPayloadEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if executionPayloadID < 1 || executionPayloadID > int64(len(db.execPayloads)) {
+		return nil, sql.ErrNoRows
+	}
+	return db.execPayloads[executionPayloadID-1], nil
+}
+
+func (db *MemoryDB) GetExecutionPayloadEntryBySlotPkHash(slot uint64, proposerPubkey, blockHash string) (entry *database.ExecutionPayloadEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.MockDB.GetExecutionPayloadEntryBySlotPkHash(slot, proposerPubkey, blockHash)
+}
+
+func (db *MemoryDB) GetBlockSubmissionEntry(slot uint64, proposerPubkey, blockHash string) (entry *database.BuilderBlockSubmissionEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, entry := range db.submissions {
+		if entry.Slot == slot && entry.ProposerPubkey == proposerPubkey && entry.BlockHash == blockHash {
+			return entry, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (db *MemoryDB) GetBuilderSubmissionsBySlots(slotFrom, slotTo uint64) (entries []*database.BuilderBlockSubmissionEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, entry := range db.submissions {
+		if entry.Slot >= slotFrom && entry.Slot <= slotTo {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (db *MemoryDB) GetBuilderSubmissionsWithPayloadBySlots(slotFrom, slotTo uint64) (entries []*database.BuilderBlockSubmissionEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, entry := range db.submissions {
+		if entry.Slot >= slotFrom && entry.Slot <= slotTo && entry.ExecutionPayloadID.Valid {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (db *MemoryDB) GetAllBuilderSubmissionsBySlot(slot uint64) (entries []*database.BuilderBlockSubmissionEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, entry := range db.submissions {
+		if entry.Slot == slot {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (db *MemoryDB) SaveDeliveredPayload(bidTrace *common.BidTraceV2WithBlobFields, signedBlindedBeaconBlock *common.VersionedSignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64, publishOutcomes []common.BeaconPublishOutcome, msIntoSlot int64, relayMode string) error {
+	_signedBlindedBeaconBlock, err := json.Marshal(signedBlindedBeaconBlock)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.deliveredPayloads = append(db.deliveredPayloads, &database.DeliveredPayloadEntry{
+		ID:                       int64(len(db.deliveredPayloads) + 1),
+		InsertedAt:               time.Now(),
+		SignedAt:                 database.NewNullTime(signedAt),
+		SignedBlindedBeaconBlock: database.NewNullString(string(_signedBlindedBeaconBlock)),
+
+		Slot:  bidTrace.Slot,
+		Epoch: bidTrace.Slot / common.SlotsPerEpoch,
+
+		BuilderPubkey:        bidTrace.BuilderPubkey.String(),
+		ProposerPubkey:       bidTrace.ProposerPubkey.String(),
+		ProposerFeeRecipient: bidTrace.ProposerFeeRecipient.String(),
+
+		ParentHash:  bidTrace.ParentHash.String(),
+		BlockHash:   bidTrace.BlockHash.String(),
+		BlockNumber: bidTrace.BlockNumber,
+
+		GasUsed:  bidTrace.GasUsed,
+		GasLimit: bidTrace.GasLimit,
+
+		NumTx: bidTrace.NumTx,
+		Value: bidTrace.Value.Dec(),
+
+		NumBlobs:      bidTrace.NumBlobs,
+		BlobGasUsed:   bidTrace.BlobGasUsed,
+		ExcessBlobGas: bidTrace.ExcessBlobGas,
+
+		PublishMs:  publishMs,
+		MsIntoSlot: msIntoSlot,
+		RelayMode:  relayMode,
+
+		RelayPubkey: bidTrace.RelayPubkey,
+		InstanceID:  common.InstanceID,
+	})
+	return nil
+}
+
+func (db *MemoryDB) GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (entries []*database.DeliveredPayloadEntry, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, entry := range db.deliveredPayloads {
+		if entry.Slot >= slotFrom && entry.Slot <= slotTo {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// GetBuilderDemotion returns no demotion, like the database does for builders which were never demoted
+func (db *MemoryDB) GetBuilderDemotion(trace *common.BidTraceV2WithBlobFields) (*database.BuilderDemotionEntry, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.Demotions[trace.BuilderPubkey.String()] {
+		return &database.BuilderDemotionEntry{}, nil //nolint:exhaustruct
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (db *MemoryDB) InsertBuilderDemotion(submitBlockRequest *common.VersionedSubmitBlockRequest, simError error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.MockDB.InsertBuilderDemotion(submitBlockRequest, simError)
+}
+
+func (db *MemoryDB) UpdateBuilderDemotion(trace *common.BidTraceV2WithBlobFields, signedBlock *common.VersionedSignedProposal, signedRegistration *builderApiV1.SignedValidatorRegistration) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.MockDB.UpdateBuilderDemotion(trace, signedBlock, signedRegistration)
+}
+
+func (db *MemoryDB) InsertBuilderDemotionFromBidTrace(trace *common.BidTraceV2WithBlobFields, demotionErr error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.MockDB.InsertBuilderDemotionFromBidTrace(trace, demotionErr)
+}
+
+// BlockSubmissions returns the saved block submissions of the slot
+func (db *MemoryDB) BlockSubmissions(slot uint64) []*database.BuilderBlockSubmissionEntry {
+	entries, _ := db.GetBuilderSubmissionsBySlots(slot, slot)
+	return entries
+}
+
+// DeliveredPayloads returns the delivered payloads of the slot
+func (db *MemoryDB) DeliveredPayloads(slot uint64) []*database.DeliveredPayloadEntry {
+	entries, _ := db.GetDeliveredPayloadsBySlots(slot, slot)
+	return entries
+}
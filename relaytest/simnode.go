@@ -0,0 +1,66 @@
+package relaytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/flashbots/go-utils/jsonrpc"
+)
+
+// SimNode is a block simulation node which accepts all blocks, unless an error is set
+type SimNode struct {
+	URL string
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	errorMsg string
+	requests map[string]int // by method
+}
+
+func newSimNode() *SimNode {
+	n := &SimNode{requests: make(map[string]int)}
+	n.srv = httptest.NewServer(http.HandlerFunc(n.handleRequest))
+	n.URL = n.srv.URL
+	return n
+}
+
+func (n *SimNode) handleRequest(w http.ResponseWriter, req *http.Request) {
+	jsonReq := new(jsonrpc.JSONRPCRequest)
+	if err := json.NewDecoder(req.Body).Decode(jsonReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n.mu.Lock()
+	n.requests[jsonReq.Method]++
+	errorMsg := n.errorMsg
+	n.mu.Unlock()
+
+	resp := jsonrpc.NewJSONRPCResponse(jsonReq.ID, json.RawMessage("null"))
+	if errorMsg != "" && jsonReq.Method != "eth_blockNumber" {
+		resp = jsonrpc.NewJSONRPCErrorResponse(jsonReq.ID, -32000, errorMsg)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// SetError makes the simulation of all following blocks fail with the message (empty to accept blocks again)
+func (n *SimNode) SetError(msg string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.errorMsg = msg
+}
+
+// NumRequests returns the number of requests for the JSON-RPC method, i.e. flashbots_validateBuilderSubmissionV3
+func (n *SimNode) NumRequests(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.requests[method]
+}
+
+func (n *SimNode) Close() {
+	n.srv.Close()
+}
@@ -0,0 +1,205 @@
+package relaytest
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	eth2Api "github.com/attestantio/go-eth2-client/api"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultGasLimit = uint64(30_000_000)
+
+// Validator is a validator of the scripted chain, with the preferences it registers with
+type Validator struct {
+	Index        uint64
+	SecretKey    *bls.SecretKey
+	Pubkey       phase0.BLSPubKey
+	FeeRecipient bellatrix.ExecutionAddress
+	GasLimit     uint64
+}
+
+func newValidator(t testing.TB, index uint64) *Validator {
+	t.Helper()
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := utils.BlsPublicKeyToPublicKey(pk)
+	require.NoError(t, err)
+	return &Validator{
+		Index:        index,
+		SecretKey:    sk,
+		Pubkey:       pubkey,
+		FeeRecipient: bellatrix.ExecutionAddress(randomBytes(t, 20)),
+		GasLimit:     defaultGasLimit,
+	}
+}
+
+func (v *Validator) stateEntry() beaconclient.ValidatorResponseEntry {
+	return beaconclient.ValidatorResponseEntry{ //nolint:exhaustruct
+		Index:     v.Index,
+		Status:    "active_ongoing",
+		Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: v.Pubkey.String()}, //nolint:exhaustruct
+	}
+}
+
+// Builder is a block builder, which submits blocks signed with its key
+type Builder struct {
+	SecretKey *bls.SecretKey
+	Pubkey    phase0.BLSPubKey
+}
+
+func NewBuilder(t testing.TB) *Builder {
+	t.Helper()
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := utils.BlsPublicKeyToPublicKey(pk)
+	require.NoError(t, err)
+	return &Builder{SecretKey: sk, Pubkey: pubkey}
+}
+
+// Slot is a slot which blocks can be built for, with the payload attributes that were sent to the relay
+type Slot struct {
+	Slot     uint64 // the proposal slot, the head is the slot before
+	Proposer *Validator
+
+	ParentHash        phase0.Hash32
+	ParentBlockNumber uint64
+	ParentBeaconRoot  phase0.Root
+	PrevRandao        phase0.Hash32
+	Timestamp         uint64
+	Withdrawals       []*capella.Withdrawal
+}
+
+// signedRegistration returns a registration of the validator, signed with the builder domain of the network
+func (c *Cluster) signedRegistration(v *Validator) builderApiV1.SignedValidatorRegistration {
+	c.t.Helper()
+	msg := &builderApiV1.ValidatorRegistration{
+		FeeRecipient: v.FeeRecipient,
+		GasLimit:     v.GasLimit,
+		Timestamp:    time.Now(),
+		Pubkey:       v.Pubkey,
+	}
+	sig, err := ssz.SignMessage(msg, c.Network.DomainBuilder, v.SecretKey)
+	require.NoError(c.t, err)
+	return builderApiV1.SignedValidatorRegistration{Message: msg, Signature: sig}
+}
+
+// BuildBlock returns a signed deneb block submission of the builder for the slot, which passes the checks of the relay
+// (i.e. it pays the registered fee recipient, and matches the payload attributes and the registered gas limit)
+func (c *Cluster) BuildBlock(builder *Builder, slot *Slot, value uint64) *common.VersionedSubmitBlockRequest {
+	c.t.Helper()
+	gasLimit := slot.Proposer.GasLimit
+	payload := &deneb.ExecutionPayload{
+		ParentHash:    slot.ParentHash,
+		FeeRecipient:  bellatrix.ExecutionAddress(builder.Pubkey[:20]),
+		StateRoot:     phase0.Root(randomBytes(c.t, 32)),
+		ReceiptsRoot:  phase0.Root(randomBytes(c.t, 32)),
+		LogsBloom:     [256]byte{},
+		PrevRandao:    slot.PrevRandao,
+		BlockNumber:   slot.ParentBlockNumber + 1,
+		GasLimit:      gasLimit,
+		GasUsed:       gasLimit / 2,
+		Timestamp:     slot.Timestamp,
+		ExtraData:     []byte("relaytest"),
+		BaseFeePerGas: uint256.NewInt(7),
+		BlockHash:     phase0.Hash32(randomBytes(c.t, 32)),
+		Transactions:  []bellatrix.Transaction{randomBytes(c.t, 32)},
+		Withdrawals:   slot.Withdrawals,
+	}
+	bidTrace := &builderApiV1.BidTrace{
+		Slot:                 slot.Slot,
+		ParentHash:           payload.ParentHash,
+		BlockHash:            payload.BlockHash,
+		BuilderPubkey:        builder.Pubkey,
+		ProposerPubkey:       slot.Proposer.Pubkey,
+		ProposerFeeRecipient: slot.Proposer.FeeRecipient,
+		GasLimit:             payload.GasLimit,
+		GasUsed:              payload.GasUsed,
+		Value:                uint256.NewInt(value),
+	}
+	sig, err := ssz.SignMessage(bidTrace, c.Network.DomainBuilder, builder.SecretKey)
+	require.NoError(c.t, err)
+
+	return &common.VersionedSubmitBlockRequest{
+		VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{ //nolint:exhaustruct
+			Version: spec.DataVersionDeneb,
+			Deneb: &builderApiDeneb.SubmitBlockRequest{
+				Message:          bidTrace,
+				ExecutionPayload: payload,
+				BlobsBundle: &builderApiDeneb.BlobsBundle{
+					Commitments: []deneb.KZGCommitment{},
+					Proofs:      []deneb.KZGProof{},
+					Blobs:       []deneb.Blob{},
+				},
+				Signature: sig,
+			},
+		},
+	}
+}
+
+// SignBlindedBlock returns the blinded block of the bid, signed by the proposer of the slot
+func (c *Cluster) SignBlindedBlock(slot *Slot, bid *builderSpec.VersionedSignedBuilderBid) *common.VersionedSignedBlindedBeaconBlock {
+	c.t.Helper()
+	require.Equal(c.t, spec.DataVersionDeneb, bid.Version)
+	block := &eth2ApiV1Deneb.BlindedBeaconBlock{
+		Slot:          phase0.Slot(slot.Slot),
+		ProposerIndex: phase0.ValidatorIndex(slot.Proposer.Index),
+		ParentRoot:    slot.ParentBeaconRoot,
+		StateRoot:     phase0.Root(randomBytes(c.t, 32)),
+		Body: &eth2ApiV1Deneb.BlindedBeaconBlockBody{
+			ETH1Data: &phase0.ETH1Data{
+				DepositRoot: phase0.Root{},
+				BlockHash:   make([]byte, 32),
+			},
+			ProposerSlashings: []*phase0.ProposerSlashing{},
+			AttesterSlashings: []*phase0.AttesterSlashing{},
+			Attestations:      []*phase0.Attestation{},
+			Deposits:          []*phase0.Deposit{},
+			VoluntaryExits:    []*phase0.SignedVoluntaryExit{},
+			SyncAggregate: &altair.SyncAggregate{
+				SyncCommitteeBits: make([]byte, 64),
+			},
+			ExecutionPayloadHeader: bid.Deneb.Message.Header,
+			BLSToExecutionChanges:  []*capella.SignedBLSToExecutionChange{},
+			BlobKZGCommitments:     bid.Deneb.Message.BlobKZGCommitments,
+		},
+	}
+
+	sig, err := ssz.SignMessage(block, c.Network.DomainBeaconProposerDeneb, slot.Proposer.SecretKey)
+	require.NoError(c.t, err)
+
+	return &common.VersionedSignedBlindedBeaconBlock{
+		VersionedSignedBlindedBeaconBlock: eth2Api.VersionedSignedBlindedBeaconBlock{ //nolint:exhaustruct
+			Version: spec.DataVersionDeneb,
+			Deneb: &eth2ApiV1Deneb.SignedBlindedBeaconBlock{
+				Message:   block,
+				Signature: sig,
+			},
+		},
+	}
+}
+
+func randomBytes(t testing.TB, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+var (
+	// the sync status of the beacon nodes is checked in this interval (0 to disable). If none of them is synced, or the
+	// best head slot lags the wall clock by more than the maximum, getHeader and block submissions are paused.
+	beaconSyncCheckInterval = time.Duration(cli.GetEnvInt("BEACON_SYNC_CHECK_INTERVAL_MS", 6000)) * time.Millisecond
+	beaconMaxHeadSlotLag    = uint64(cli.GetEnvInt("BEACON_MAX_HEAD_SLOT_LAG", 2))
+)
+
+func (api *RelayAPI) startBeaconSyncCheck() {
+	ticker := time.NewTicker(beaconSyncCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		api.updateBeaconSyncStatus(time.Now())
+	}
+}
+
+// updateBeaconSyncStatus checks whether the beacon nodes are synced, and pauses or resumes serving bids accordingly
+func (api *RelayAPI) updateBeaconSyncStatus(now time.Time) {
+	log := api.log.WithField("method", "updateBeaconSyncStatus")
+
+	reason := ""
+	syncStatus, err := api.beaconClient.BestSyncStatus()
+	if err != nil {
+		reason = err.Error()
+	} else if syncStatus.IsSyncing {
+		reason = ErrBeaconNodeSyncing.Error()
+	} else if wallClockSlot := api.wallClockSlot(now); syncStatus.HeadSlot+beaconMaxHeadSlotLag < wallClockSlot {
+		reason = fmt.Sprintf("head slot %d is %d slots behind the wall clock", syncStatus.HeadSlot, wallClockSlot-syncStatus.HeadSlot)
+	}
+
+	wasUnsynced := api.beaconUnsynced.Swap(reason != "")
+	if reason != "" && !wasUnsynced {
+		log.WithField("reason", reason).Error("beacon nodes are not synced, pausing getHeader and block submissions")
+	} else if reason == "" && wasUnsynced {
+		log.Info("beacon nodes are synced again, resuming getHeader and block submissions")
+	}
+}
+
+// wallClockSlot returns the slot of the given time
+func (api *RelayAPI) wallClockSlot(now time.Time) uint64 {
+	genesisTime := int64(api.genesisInfo.Data.GenesisTime)
+	if now.Unix() < genesisTime {
+		return 0
+	}
+	return uint64(now.Unix()-genesisTime) / common.SecondsPerSlot
+}
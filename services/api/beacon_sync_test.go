@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateBeaconSyncStatus(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.beaconClient = beaconclient.NewMockMultiBeaconClient() // at head slot 1
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{}
+
+	now := time.Unix(int64(common.SecondsPerSlot*(1+beaconMaxHeadSlotLag)), 0)
+	backend.relay.updateBeaconSyncStatus(now)
+	require.False(t, backend.relay.beaconUnsynced.Load())
+
+	backend.relay.updateBeaconSyncStatus(now.Add(common.DurationPerSlot))
+	require.True(t, backend.relay.beaconUnsynced.Load())
+
+	backend.relay.updateBeaconSyncStatus(now)
+	require.False(t, backend.relay.beaconUnsynced.Load())
+}
+
+func TestBeaconUnsyncedPausesBids(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.beaconUnsynced.Store(true)
+
+	path := "/eth/v1/builder/header/1/0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7/0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = backend.request(http.MethodPost, pathSubmitNewBlock, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeBeaconNotSynced))
+}
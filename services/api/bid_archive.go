@@ -0,0 +1,122 @@
+package api
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+)
+
+var (
+	// percentage of accepted bids to archive (0 disables the bid archive). Rejected bids are always archived.
+	bidArchiveSamplePercent = cli.GetEnvInt("BID_ARCHIVE_SAMPLE_PERCENT", 0)
+
+	// maximum number of bids to archive per slot
+	bidArchiveMaxPerSlot = cli.GetEnvInt("BID_ARCHIVE_MAX_PER_SLOT", 10_000)
+
+	// number of goroutines writing archived bids to the database
+	numBidArchiveProcessors = cli.GetEnvInt("NUM_BID_ARCHIVE_PROCESSORS", 2)
+)
+
+// submissionResponseWriter records the status code and error of a block submission response, to archive the bid
+// together with the outcome and to answer duplicate submissions of the block. The timings of the submission are
+// returned in the Server-Timing header.
+type submissionResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	errCode    ErrorCode
+	message    string
+	timings    submissionTimings
+
+	onWriteHeader func() // optional, called once the outcome is recorded
+}
+
+func (w *submissionResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	if w.onWriteHeader != nil {
+		w.onWriteHeader()
+	}
+	if serverTiming := w.timings.serverTiming(); serverTiming != "" {
+		w.Header().Set(HeaderServerTiming, serverTiming)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// bidArchiveCounter limits the number of archived bids per slot
+type bidArchiveCounter struct {
+	mu    sync.Mutex
+	slot  uint64
+	count int
+}
+
+// inc returns false if the limit for the slot is reached
+func (c *bidArchiveCounter) inc(slot uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if slot > c.slot {
+		c.slot = slot
+		c.count = 0
+	} else if slot < c.slot {
+		// late submission for an old slot, which is rejected anyway
+		return false
+	}
+	if c.count >= bidArchiveMaxPerSlot {
+		return false
+	}
+	c.count++
+	return true
+}
+
+func (api *RelayAPI) isBidArchiveEnabled() bool {
+	return api.bidArchiveC != nil
+}
+
+// archiveBid queues a received bid for the bid archive, with the outcome recorded by the response writer
+func (api *RelayAPI) archiveBid(w *submissionResponseWriter, submission *common.BlockSubmissionInfo, receivedAt time.Time) {
+	accepted := w.statusCode < http.StatusMultipleChoices
+	if accepted && rand.Intn(100) >= bidArchiveSamplePercent { //nolint:gosec
+		return
+	}
+	if !api.bidArchiveCounter.inc(submission.BidTrace.Slot) {
+		return
+	}
+
+	entry := &database.BidArchiveEntry{
+		ReceivedAt:           receivedAt,
+		Slot:                 submission.BidTrace.Slot,
+		ParentHash:           submission.BidTrace.ParentHash.String(),
+		BlockHash:            submission.BidTrace.BlockHash.String(),
+		BuilderPubkey:        submission.BidTrace.BuilderPubkey.String(),
+		ProposerPubkey:       submission.BidTrace.ProposerPubkey.String(),
+		ProposerFeeRecipient: submission.BidTrace.ProposerFeeRecipient.String(),
+		GasUsed:              submission.GasUsed,
+		GasLimit:             submission.GasLimit,
+		NumTx:                uint64(len(submission.Transactions)),
+		Value:                submission.BidTrace.Value.Dec(),
+		BlockNumber:          submission.BlockNumber,
+		Accepted:             accepted,
+		StatusCode:           w.statusCode,
+		ErrorCode:            string(w.errCode),
+		RejectionReason:      w.message,
+	}
+
+	select {
+	case api.bidArchiveC <- entry:
+	default:
+		api.log.Error("bid archive channel full")
+	}
+}
+
+func (api *RelayAPI) startBidArchiveDBProcessor() {
+	defer api.bidArchiveProcessorsWG.Done()
+	for entry := range api.bidArchiveC {
+		err := api.db.InsertBidArchiveEntry(entry)
+		if err != nil {
+			api.log.WithError(err).WithField("slot", entry.Slot).Error("error saving bid to archive")
+		}
+	}
+}
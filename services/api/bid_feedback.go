@@ -0,0 +1,218 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// number of slots before the head slot which builders can get feedback on their bids for
+	bidFeedbackMaxSlotAge = uint64(cli.GetEnvInt("BID_FEEDBACK_MAX_SLOT_AGE", 300))
+
+	// maximum age of the timestamp of a bid feedback request, to limit replaying signed requests
+	bidFeedbackMaxRequestAge = 60 * time.Second
+
+	// maximum size of a bid feedback request, which is a small signed message
+	maxBidFeedbackRequestBytes int64 = 4 * 1024
+)
+
+func (api *RelayAPI) handleBuilderBidFeedback(w http.ResponseWriter, req *http.Request) {
+	log := api.log.WithFields(logrus.Fields{
+		"method":    "builderBidFeedback",
+		"requestID": getRequestID(req.Context()),
+	})
+
+	requestBytes, err := readSubmissionBody(req.Body, maxBidFeedbackRequestBytes)
+	if err != nil {
+		api.respondBodyReadError(w, log, req, err)
+		return
+	}
+
+	signedRequest := new(common.SignedBidFeedbackRequest)
+	if err := json.Unmarshal(requestBytes, signedRequest); err != nil || signedRequest.Message == nil {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, "failed to decode bid feedback request")
+		return
+	}
+	feedbackReq := signedRequest.Message
+	builderPubkey := feedbackReq.BuilderPubkey.String()
+	log = log.WithFields(logrus.Fields{
+		"builderPubkey": builderPubkey,
+		"slot":          feedbackReq.Slot,
+		"timestamp":     feedbackReq.Timestamp,
+	})
+
+	now := time.Now().UTC()
+	if feedbackReq.Timestamp > uint64(now.Unix()+10) {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp too far in the future")
+		return
+	} else if feedbackReq.Timestamp < uint64(now.Add(-bidFeedbackMaxRequestAge).Unix()) {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp too old")
+		return
+	}
+
+	// Only builders known to the relay can get feedback, before spending a signature check and a database query
+	if _, ok := api.blockBuildersCache[builderPubkey]; !ok {
+		log.Info("bid feedback request of an unknown builder")
+		api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeForbidden, "unknown builder")
+		return
+	}
+
+	headSlot := api.headSlot.Load()
+	if feedbackReq.Slot > headSlot || feedbackReq.Slot+bidFeedbackMaxSlotAge < headSlot {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSlot, fmt.Sprintf("slot must be one of the last %d slots up to the head slot %d", bidFeedbackMaxSlotAge, headSlot))
+		return
+	}
+
+	ok, err := ssz.VerifySignature(feedbackReq, api.opts.EthNetDetails.DomainBuilder, feedbackReq.BuilderPubkey[:], signedRequest.Signature[:])
+	if err != nil || !ok {
+		log.WithError(err).Info("invalid bid feedback request signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "invalid signature")
+		return
+	}
+
+	submissions, err := api.db.GetAllBuilderSubmissionsBySlot(feedbackReq.Slot)
+	if err != nil {
+		log.WithError(err).Error("failed to get block submissions")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	delivered, err := api.db.GetDeliveredPayloadsBySlots(feedbackReq.Slot, feedbackReq.Slot)
+	if err != nil {
+		log.WithError(err).Error("failed to get delivered payloads")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var deliveredPayload *database.DeliveredPayloadEntry
+	if len(delivered) > 0 {
+		deliveredPayload = delivered[0]
+	}
+	feedback := computeBidFeedback(builderPubkey, feedbackReq.Slot, submissions, deliveredPayload)
+	if feedback == nil {
+		api.RespondErrorCode(w, http.StatusNotFound, ErrorCodeNotFound, "no submissions of the builder for the slot")
+		return
+	}
+	api.RespondOK(w, feedback)
+}
+
+// isEligibleSubmission returns true if a submission could be served in getHeader, the same condition as the one of
+// the builder_blocks_received data API
+func isEligibleSubmission(entry *database.BuilderBlockSubmissionEntry) bool {
+	return entry.SimSuccess || entry.OptimisticSubmission
+}
+
+// submissionRejectionReason returns why a submission wasn't eligible
+func submissionRejectionReason(entry *database.BuilderBlockSubmissionEntry) string {
+	switch {
+	case entry.SimError != "":
+		return entry.SimError
+	case entry.SimReqError != "":
+		return entry.SimReqError
+	case !entry.WasSimulated:
+		return "not simulated"
+	default:
+		return "simulation failed"
+	}
+}
+
+// unixMilli returns a nanosecond timestamp in milliseconds, or the fallback time if the timestamp is unknown (0 if
+// neither is known)
+func unixMilli(ns int64, fallback sql.NullTime) int64 {
+	if ns > 0 {
+		return time.Unix(0, ns).UnixMilli()
+	} else if fallback.Valid {
+		return fallback.Time.UnixMilli()
+	}
+	return 0
+}
+
+// computeBidFeedback returns the feedback on the best bid of the builder among the submissions of the slot, or nil
+// if the builder didn't submit for the slot. The winning bid is the delivered payload if there is one, and the
+// highest eligible bid otherwise.
+func computeBidFeedback(builderPubkey string, slot uint64, submissions []*database.BuilderBlockSubmissionEntry, delivered *database.DeliveredPayloadEntry) *common.BidFeedbackJSON {
+	type bid struct {
+		entry *database.BuilderBlockSubmissionEntry
+		value *big.Int
+	}
+
+	// Highest eligible bid of every builder, and the highest bid of the builder regardless of the outcome
+	bestBids := make(map[string]bid)
+	var ownBestRejected bid
+	numOwnSubmissions := uint64(0)
+	for _, entry := range submissions {
+		value, ok := new(big.Int).SetString(entry.Value, 10)
+		if !ok {
+			continue
+		}
+		eligible := isEligibleSubmission(entry)
+		if eligible && (bestBids[entry.BuilderPubkey].value == nil || value.Cmp(bestBids[entry.BuilderPubkey].value) > 0) {
+			bestBids[entry.BuilderPubkey] = bid{entry, value}
+		}
+		if entry.BuilderPubkey != builderPubkey {
+			continue
+		}
+		numOwnSubmissions++
+		if !eligible && (ownBestRejected.value == nil || value.Cmp(ownBestRejected.value) > 0) {
+			ownBestRejected = bid{entry, value}
+		}
+	}
+	if numOwnSubmissions == 0 {
+		return nil
+	}
+
+	ownBest, isEligible := bestBids[builderPubkey]
+	if !isEligible {
+		ownBest = ownBestRejected
+	}
+	feedback := &common.BidFeedbackJSON{
+		Slot:           slot,
+		BuilderPubkey:  builderPubkey,
+		BlockHash:      ownBest.entry.BlockHash,
+		Value:          ownBest.value.String(),
+		NumBuilders:    uint64(len(bestBids)),
+		NumSubmissions: numOwnSubmissions,
+		ReceivedAt:     unixMilli(ownBest.entry.ReceivedAtNs, ownBest.entry.ReceivedAt),
+	}
+	if isEligible {
+		feedback.Position = 1
+		feedback.EligibleAt = unixMilli(ownBest.entry.EligibleAtNs, ownBest.entry.EligibleAt)
+	} else {
+		feedback.RejectionReason = submissionRejectionReason(ownBest.entry)
+	}
+
+	var winner bid
+	for _, best := range bestBids {
+		if isEligible && best.value.Cmp(ownBest.value) > 0 {
+			feedback.Position++
+		}
+		if winner.value == nil || best.value.Cmp(winner.value) > 0 {
+			winner = best
+		}
+	}
+
+	if delivered != nil {
+		if value, ok := new(big.Int).SetString(delivered.Value, 10); ok {
+			feedback.WinningBlockHash = delivered.BlockHash
+			feedback.WinningValue = value.String()
+			feedback.WinnerDelivered = true
+			feedback.DeltaToWinner = new(big.Int).Sub(value, ownBest.value).String()
+			return feedback
+		}
+	}
+	if winner.value != nil {
+		feedback.WinningBlockHash = winner.entry.BlockHash
+		feedback.WinningValue = winner.value.String()
+		feedback.DeltaToWinner = new(big.Int).Sub(winner.value, ownBest.value).String()
+	}
+	return feedback
+}
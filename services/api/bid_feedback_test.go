@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+type bidFeedbackDB struct {
+	database.MockDB
+	submissions []*database.BuilderBlockSubmissionEntry
+	delivered   []*database.DeliveredPayloadEntry
+	numQueries  *int
+}
+
+func (db bidFeedbackDB) GetAllBuilderSubmissionsBySlot(slot uint64) ([]*database.BuilderBlockSubmissionEntry, error) {
+	if db.numQueries != nil {
+		*db.numQueries++
+	}
+	return db.submissions, nil
+}
+
+func (db bidFeedbackDB) GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) ([]*database.DeliveredPayloadEntry, error) {
+	return db.delivered, nil
+}
+
+func TestComputeBidFeedback(t *testing.T) {
+	receivedAt := time.Unix(1_700_000_000, 0)
+	submission := func(builder, blockHash, value string, simSuccess bool, simError string) *database.BuilderBlockSubmissionEntry {
+		return &database.BuilderBlockSubmissionEntry{
+			ReceivedAt:    database.NewNullTime(receivedAt),
+			EligibleAtNs:  receivedAt.Add(50 * time.Millisecond).UnixNano(),
+			WasSimulated:  true,
+			SimSuccess:    simSuccess,
+			SimError:      simError,
+			BuilderPubkey: builder,
+			BlockHash:     blockHash,
+			Value:         value,
+		}
+	}
+	submissions := []*database.BuilderBlockSubmissionEntry{
+		submission("builder1", "0x11", "100", true, ""),
+		submission("builder1", "0x12", "300", false, "invalid block"),
+		submission("builder1", "0x13", "150", true, ""),
+		submission("builder2", "0x21", "200", true, ""),
+		submission("builder3", "0x31", "120", true, ""),
+		submission("builder4", "0x41", "500", false, "invalid block"),
+	}
+
+	// The highest eligible bid is ranked, the winner is the top eligible bid if nothing was delivered
+	feedback := computeBidFeedback("builder1", 10, submissions, nil)
+	require.Equal(t, "0x13", feedback.BlockHash)
+	require.Equal(t, "150", feedback.Value)
+	require.Equal(t, uint64(2), feedback.Position)
+	require.Equal(t, uint64(3), feedback.NumBuilders)
+	require.Equal(t, uint64(3), feedback.NumSubmissions)
+	require.Empty(t, feedback.RejectionReason)
+	require.Equal(t, receivedAt.UnixMilli(), feedback.ReceivedAt)
+	require.Equal(t, receivedAt.UnixMilli()+50, feedback.EligibleAt)
+	require.Equal(t, "0x21", feedback.WinningBlockHash)
+	require.Equal(t, "50", feedback.DeltaToWinner)
+	require.False(t, feedback.WinnerDelivered)
+
+	// The delivered payload is the winner
+	feedback = computeBidFeedback("builder2", 10, submissions, &database.DeliveredPayloadEntry{BlockHash: "0x31", Value: "120"})
+	require.Equal(t, uint64(1), feedback.Position)
+	require.Equal(t, "0x31", feedback.WinningBlockHash)
+	require.Equal(t, "-80", feedback.DeltaToWinner)
+	require.True(t, feedback.WinnerDelivered)
+
+	// Builders without eligible bids get the rejection reason of their highest bid
+	feedback = computeBidFeedback("builder4", 10, submissions, nil)
+	require.Equal(t, uint64(0), feedback.Position)
+	require.Equal(t, "invalid block", feedback.RejectionReason)
+	require.Zero(t, feedback.EligibleAt)
+	require.Equal(t, "-300", feedback.DeltaToWinner)
+
+	require.Nil(t, computeBidFeedback("builder5", 10, submissions, nil))
+}
+
+func TestBuilderBidFeedback(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	builderPubkey, err := utils.BlsPublicKeyToPublicKey(blsPubkey)
+	require.NoError(t, err)
+	backend.relay.db = bidFeedbackDB{submissions: []*database.BuilderBlockSubmissionEntry{{
+		ReceivedAt:    database.NewNullTime(time.Now()),
+		WasSimulated:  true,
+		SimSuccess:    true,
+		BuilderPubkey: builderPubkey.String(),
+		BlockHash:     "0x01",
+		Value:         "100",
+	}}}
+	backend.relay.headSlot.Store(1000)
+	backend.relay.blockBuildersCache = map[string]*blockBuilderCacheEntry{builderPubkey.String(): {}}
+
+	requestOf := func(builderPubkey phase0.BLSPubKey, slot uint64, timestamp time.Time, signingKey *bls.SecretKey) *http.Response {
+		msg := &common.BidFeedbackRequest{BuilderPubkey: builderPubkey, Slot: slot, Timestamp: uint64(timestamp.Unix())}
+		signature, err := ssz.SignMessage(msg, backend.relay.opts.EthNetDetails.DomainBuilder, signingKey)
+		require.NoError(t, err)
+		rr := backend.request(http.MethodPost, pathBuilderBidFeedback, &common.SignedBidFeedbackRequest{Message: msg, Signature: signature})
+		return rr.Result()
+	}
+	request := func(slot uint64, timestamp time.Time, signingKey *bls.SecretKey) *http.Response {
+		return requestOf(builderPubkey, slot, timestamp, signingKey)
+	}
+
+	resp := request(999, time.Now(), sk)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	feedback := new(common.BidFeedbackJSON)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(feedback))
+	require.Equal(t, uint64(999), feedback.Slot)
+	require.Equal(t, uint64(1), feedback.Position)
+	require.Equal(t, "0x01", feedback.WinningBlockHash)
+
+	// Requests must be signed by the builder, recent, and for a recent slot
+	otherSk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, request(999, time.Now(), otherSk).StatusCode)
+	require.Equal(t, http.StatusBadRequest, request(999, time.Now().Add(-2*bidFeedbackMaxRequestAge), sk).StatusCode)
+	require.Equal(t, http.StatusBadRequest, request(1001, time.Now(), sk).StatusCode)
+	require.Equal(t, http.StatusBadRequest, request(1000-bidFeedbackMaxSlotAge-1, time.Now(), sk).StatusCode)
+
+	// Builders without submissions for the slot
+	backend.relay.db = bidFeedbackDB{}
+	require.Equal(t, http.StatusNotFound, request(999, time.Now(), sk).StatusCode)
+
+	// Unknown builders are rejected before the database is queried, even with a valid signature
+	numQueries := 0
+	backend.relay.db = bidFeedbackDB{numQueries: &numQueries}
+	unknownSk, unknownBlsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	unknownPubkey, err := utils.BlsPublicKeyToPublicKey(unknownBlsPubkey)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, requestOf(unknownPubkey, 999, time.Now(), unknownSk).StatusCode)
+	require.Equal(t, 0, numQueries)
+
+	// Large bodies are rejected
+	rr := backend.requestBytes(http.MethodPost, pathBuilderBidFeedback, bytes.Repeat([]byte(" "), int(maxBidFeedbackRequestBytes)+1), nil)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Equal(t, 0, numQueries)
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidRelayMode          = errors.New("invalid relay mode")
+	ErrMissingBlocklist          = errors.New("filtered relay mode requires a blocklist")
+	ErrInvalidBlocklistAddr      = errors.New("invalid address in blocklist")
+	ErrBlocklistHTTPError        = errors.New("blocklist request failed")
+	ErrInvalidBlocklistSigKey    = errors.New("invalid blocklist signature pubkey")
+	ErrInvalidBlocklistSignature = errors.New("invalid blocklist signature")
+
+	blocklistReloadInterval = time.Duration(cli.GetEnvInt("BLOCKLIST_RELOAD_INTERVAL_SEC", 60)) * time.Second
+
+	// hex encoded ed25519 pubkey which must have signed blocklists loaded from a URL (empty for no verification)
+	blocklistSignaturePubkey = os.Getenv("BLOCKLIST_SIGNATURE_PUBKEY")
+
+	// whether to accept blocks with the last blocklist (or none) when it can't be loaded, instead of rejecting them
+	blocklistFailOpen = os.Getenv("BLOCKLIST_FAIL_OPEN") == "1"
+
+	// in fail-closed mode, maximum time since the last successful blocklist reload before blocks are rejected (0 for
+	// no maximum)
+	blocklistMaxAge = time.Duration(cli.GetEnvInt("BLOCKLIST_MAX_AGE_SEC", 0)) * time.Second
+
+	// metrics of the blocklist, served on the diagnostics listener
+	blocklistExpvar = expvar.NewMap("blocklist")
+)
+
+// HeaderBlocklistSignature is the hex encoded ed25519 signature of the body of a blocklist response
+const HeaderBlocklistSignature = "X-Blocklist-Signature"
+
+// BlocklistOpts configure how a blocklist is verified and what happens if it can't be loaded
+type BlocklistOpts struct {
+	SignaturePubkey ed25519.PublicKey // verifies blocklists loaded from a URL, if set
+	FailOpen        bool              // start with an empty list if the initial load fails, and never become stale
+	MaxAge          time.Duration     // the list is stale if it wasn't reloaded for longer (0 for never)
+}
+
+// BlocklistOptsFromEnv returns the blocklist options from the environment variables
+func BlocklistOptsFromEnv() (opts BlocklistOpts, err error) {
+	opts.FailOpen = blocklistFailOpen
+	opts.MaxAge = blocklistMaxAge
+	if blocklistSignaturePubkey != "" {
+		key, err := hex.DecodeString(strings.TrimPrefix(blocklistSignaturePubkey, "0x"))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return opts, fmt.Errorf("%w: %s", ErrInvalidBlocklistSigKey, blocklistSignaturePubkey)
+		}
+		opts.SignaturePubkey = key
+	}
+	return opts, nil
+}
+
+// BlocklistStatus is the version of the loaded blocklist
+type BlocklistStatus struct {
+	Version      string `json:"version"` // sha256 of the list
+	NumAddresses int    `json:"num_addresses"`
+	LoadedAt     string `json:"loaded_at,omitempty"`    // when the current version was loaded
+	RefreshedAt  string `json:"refreshed_at,omitempty"` // when the list was last loaded (or found unchanged)
+	LastError    string `json:"last_error,omitempty"`   // error of the last reload, if it failed
+	Stale        bool   `json:"stale"`
+}
+
+// Blocklist is a set of addresses which may not be part of any transaction in filtered relay mode. It is loaded from a
+// file or URL, either a JSON list of addresses or one address per line (with # comments), and reloaded regularly.
+// Lists loaded from a URL are only downloaded again if their ETag changed, and may be required to be signed.
+type Blocklist struct {
+	log    *logrus.Entry
+	opts   BlocklistOpts
+	client http.Client
+
+	mu          sync.RWMutex
+	source      string
+	addresses   map[ethcommon.Address]struct{}
+	etag        string
+	version     string
+	loadedAt    time.Time
+	refreshedAt time.Time
+}
+
+// blocklistResponse is a fetched blocklist, or notModified if it didn't change since the given ETag
+type blocklistResponse struct {
+	data        []byte
+	etag        string
+	notModified bool
+}
+
+// NewBlocklist creates the blocklist and loads it from the source (file path or http(s) URL). In fail-open mode, a
+// failed initial load is only logged and the blocklist starts out empty.
+func NewBlocklist(log *logrus.Entry, source string, opts BlocklistOpts) (*Blocklist, error) {
+	b := &Blocklist{
+		log:    log.WithField("blocklist", source),
+		opts:   opts,
+		source: source,
+		client: http.Client{Timeout: 10 * time.Second},
+	}
+	if err := b.Load(); err != nil {
+		if !opts.FailOpen {
+			return nil, err
+		}
+		b.log.WithError(err).Error("failed to load blocklist, starting with an empty list (fail open)")
+	}
+	return b, nil
+}
+
+// Load (re)loads the blocklist from the source. On error, the previous addresses are kept.
+func (b *Blocklist) Load() error {
+	b.mu.RLock()
+	source, etag := b.source, b.etag
+	b.mu.RUnlock()
+
+	resp, err := b.fetch(source, etag)
+	if err != nil {
+		return err
+	}
+	if resp.notModified {
+		b.mu.Lock()
+		b.refreshedAt = time.Now().UTC()
+		b.mu.Unlock()
+		b.log.Debug("blocklist not modified")
+		return nil
+	}
+	addresses, err := parseBlocklist(resp.data)
+	if err != nil {
+		return err
+	}
+
+	b.swap(source, addresses, resp)
+	return nil
+}
+
+// SetSource loads the blocklist from a new source, which is then used for reloading. On error, the previous source and
+// addresses are kept.
+func (b *Blocklist) SetSource(source string) error {
+	resp, err := b.fetch(source, "")
+	if err != nil {
+		return err
+	}
+	addresses, err := parseBlocklist(resp.data)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.source = source
+	b.mu.Unlock()
+	b.swap(source, addresses, resp)
+	b.log.WithField("newSource", source).Info("blocklist source changed")
+	return nil
+}
+
+// swap replaces the addresses with a newly loaded list, unless the source was changed in the meantime
+func (b *Blocklist) swap(source string, addresses map[ethcommon.Address]struct{}, resp *blocklistResponse) {
+	hash := sha256.Sum256(resp.data)
+	version := hex.EncodeToString(hash[:])
+	now := time.Now().UTC()
+
+	b.mu.Lock()
+	if source != b.source {
+		b.mu.Unlock()
+		return
+	}
+	changed := version != b.version
+	b.addresses = addresses
+	b.etag = resp.etag
+	if changed {
+		b.version = version
+		b.loadedAt = now
+	}
+	b.refreshedAt = now
+	b.mu.Unlock()
+
+	versionVar, numAddressesVar, refreshedAtVar := new(expvar.String), new(expvar.Int), new(expvar.String)
+	versionVar.Set(version)
+	numAddressesVar.Set(int64(len(addresses)))
+	refreshedAtVar.Set(now.Format(time.RFC3339))
+	blocklistExpvar.Set("version", versionVar)
+	blocklistExpvar.Set("num_addresses", numAddressesVar)
+	blocklistExpvar.Set("refreshed_at", refreshedAtVar)
+	if changed {
+		b.log.WithFields(logrus.Fields{"numAddresses": len(addresses), "version": version}).Info("blocklist loaded")
+	}
+}
+
+// fetch reads the blocklist from a file, or downloads it from a URL unless its ETag is unchanged
+func (b *Blocklist) fetch(source, etag string) (*blocklistResponse, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		return &blocklistResponse{data: data}, nil
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return &blocklistResponse{etag: etag, notModified: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", ErrBlocklistHTTPError, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.opts.SignaturePubkey != nil {
+		sig, err := hex.DecodeString(strings.TrimPrefix(resp.Header.Get(HeaderBlocklistSignature), "0x"))
+		if err != nil || !ed25519.Verify(b.opts.SignaturePubkey, data, sig) {
+			return nil, ErrInvalidBlocklistSignature
+		}
+	}
+	return &blocklistResponse{data: data, etag: resp.Header.Get("ETag")}, nil
+}
+
+func parseBlocklist(data []byte) (map[ethcommon.Address]struct{}, error) {
+	var entries []string
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, line := range strings.Split(string(data), "\n") {
+			line, _, _ = strings.Cut(line, "#")
+			if line = strings.TrimSpace(line); line != "" {
+				entries = append(entries, line)
+			}
+		}
+	}
+
+	addresses := make(map[ethcommon.Address]struct{}, len(entries))
+	for _, entry := range entries {
+		if !ethcommon.IsHexAddress(entry) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBlocklistAddr, entry)
+		}
+		addresses[ethcommon.HexToAddress(entry)] = struct{}{}
+	}
+	return addresses, nil
+}
+
+// startReloading reloads the blocklist in regular intervals (blocking)
+func (b *Blocklist) startReloading() {
+	ticker := time.NewTicker(blocklistReloadInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := b.Load(); err != nil {
+			b.log.WithError(err).WithField("stale", b.IsStale()).Error("failed to reload blocklist, keeping the previous one")
+		}
+	}
+}
+
+// IsStale returns true if, in fail-closed mode, the blocklist wasn't (re)loaded successfully for longer than the
+// maximum age. Blocks must not be accepted with a stale blocklist.
+func (b *Blocklist) IsStale() bool {
+	if b.opts.FailOpen || b.opts.MaxAge == 0 {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return time.Since(b.refreshedAt) > b.opts.MaxAge
+}
+
+// Status returns the version of the loaded blocklist
+func (b *Blocklist) Status() BlocklistStatus {
+	stale := b.IsStale()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	status := BlocklistStatus{
+		Version:      b.version,
+		NumAddresses: len(b.addresses),
+		Stale:        stale,
+	}
+	if !b.loadedAt.IsZero() {
+		status.LoadedAt = b.loadedAt.Format(time.RFC3339)
+		status.RefreshedAt = b.refreshedAt.Format(time.RFC3339)
+	}
+	return status
+}
+
+func (b *Blocklist) Contains(address ethcommon.Address) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.addresses[address]
+	return ok
+}
+
+// CheckTransactions returns the first blocklisted address which is the sender or recipient of any of the transactions
+func (b *Blocklist) CheckTransactions(txs []bellatrix.Transaction) (*ethcommon.Address, error) {
+	for _, rawTx := range txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(rawTx); err != nil {
+			return nil, err
+		}
+
+		if to := tx.To(); to != nil && b.Contains(*to) {
+			return to, nil
+		}
+
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, err
+		}
+		if b.Contains(from) {
+			return &from, nil
+		}
+	}
+	return nil, nil
+}
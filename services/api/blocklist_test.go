@@ -0,0 +1,173 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklistLoad(t *testing.T) {
+	addr1 := ethcommon.HexToAddress("0x8b5a3e2a6d0e1c7a3f9c0e3c1b6e1d6a7c2b5f11")
+	addr2 := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	// Text file with comments
+	fn := filepath.Join(t.TempDir(), "blocklist.txt")
+	err := os.WriteFile(fn, []byte("# sanctioned addresses\n"+addr1.Hex()+" # first\n\n"+addr2.Hex()+"\n"), 0o600)
+	require.NoError(t, err)
+	blocklist, err := NewBlocklist(common.TestLog, fn, BlocklistOpts{})
+	require.NoError(t, err)
+	require.True(t, blocklist.Contains(addr1))
+	require.True(t, blocklist.Contains(addr2))
+	require.False(t, blocklist.Contains(ethcommon.Address{}))
+
+	// Reloading an invalid file keeps the previous list
+	err = os.WriteFile(fn, []byte("not-an-address\n"), 0o600)
+	require.NoError(t, err)
+	require.ErrorIs(t, blocklist.Load(), ErrInvalidBlocklistAddr)
+	require.True(t, blocklist.Contains(addr1))
+
+	// JSON list from URL
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`["` + addr2.Hex() + `"]`))
+	}))
+	defer srv.Close()
+	blocklist, err = NewBlocklist(common.TestLog, srv.URL, BlocklistOpts{})
+	require.NoError(t, err)
+	require.False(t, blocklist.Contains(addr1))
+	require.True(t, blocklist.Contains(addr2))
+
+	// Changing to an invalid source keeps the previous source and list
+	require.ErrorIs(t, blocklist.SetSource(fn), ErrInvalidBlocklistAddr)
+	require.True(t, blocklist.Contains(addr2))
+	require.NoError(t, blocklist.Load())
+
+	// Changing the source
+	err = os.WriteFile(fn, []byte(addr1.Hex()+"\n"), 0o600)
+	require.NoError(t, err)
+	require.NoError(t, blocklist.SetSource(fn))
+	require.True(t, blocklist.Contains(addr1))
+	require.False(t, blocklist.Contains(addr2))
+}
+
+func TestBlocklistURL(t *testing.T) {
+	addr1 := ethcommon.HexToAddress("0x8b5a3e2a6d0e1c7a3f9c0e3c1b6e1d6a7c2b5f11")
+	addr2 := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	pubkey, sk, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	body := []byte(`["` + addr1.Hex() + `"]`)
+	etag := `"v1"`
+	signature := hex.EncodeToString(ed25519.Sign(sk, body))
+	numDownloads := 0
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if req.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		numDownloads++
+		w.Header().Set("ETag", etag)
+		w.Header().Set(HeaderBlocklistSignature, signature)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	blocklist, err := NewBlocklist(common.TestLog, srv.URL, BlocklistOpts{SignaturePubkey: pubkey, MaxAge: time.Hour})
+	require.NoError(t, err)
+	require.True(t, blocklist.Contains(addr1))
+	status := blocklist.Status()
+	require.Equal(t, 1, status.NumAddresses)
+	require.NotEmpty(t, status.Version)
+	require.False(t, status.Stale)
+
+	// Unchanged lists are not downloaded again
+	require.NoError(t, blocklist.Load())
+	require.Equal(t, 1, numDownloads)
+
+	// A new version with an invalid signature is rejected, keeping the previous list
+	body, etag = []byte(`["`+addr2.Hex()+`"]`), `"v2"`
+	require.ErrorIs(t, blocklist.Load(), ErrInvalidBlocklistSignature)
+	require.True(t, blocklist.Contains(addr1))
+
+	signature = hex.EncodeToString(ed25519.Sign(sk, body))
+	require.NoError(t, blocklist.Load())
+	require.False(t, blocklist.Contains(addr1))
+	require.True(t, blocklist.Contains(addr2))
+	require.NotEqual(t, status.Version, blocklist.Status().Version)
+
+	// Fail closed: the list becomes stale if it can't be reloaded
+	fail = true
+	require.ErrorIs(t, blocklist.Load(), ErrBlocklistHTTPError)
+	require.False(t, blocklist.IsStale())
+	blocklist.refreshedAt = time.Now().Add(-2 * time.Hour)
+	require.True(t, blocklist.IsStale())
+
+	_, err = NewBlocklist(common.TestLog, srv.URL, BlocklistOpts{})
+	require.ErrorIs(t, err, ErrBlocklistHTTPError)
+
+	// Fail open: start with an empty list, which never becomes stale
+	blocklist, err = NewBlocklist(common.TestLog, srv.URL, BlocklistOpts{FailOpen: true, MaxAge: time.Hour})
+	require.NoError(t, err)
+	require.False(t, blocklist.Contains(addr2))
+	require.False(t, blocklist.IsStale())
+}
+
+func TestBlocklistCheckTransactions(t *testing.T) {
+	sk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(sk.PublicKey)
+	recipient := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	chainID := big.NewInt(1)
+	tx, err := types.SignNewTx(sk, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+		To:        &recipient,
+		Value:     big.NewInt(1),
+	})
+	require.NoError(t, err)
+	rawTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	txs := []bellatrix.Transaction{rawTx}
+
+	blocklist := &Blocklist{addresses: map[ethcommon.Address]struct{}{}}
+	address, err := blocklist.CheckTransactions(txs)
+	require.NoError(t, err)
+	require.Nil(t, address)
+
+	// Blocklisted recipient
+	blocklist.addresses = map[ethcommon.Address]struct{}{recipient: {}}
+	address, err = blocklist.CheckTransactions(txs)
+	require.NoError(t, err)
+	require.Equal(t, recipient, *address)
+
+	// Blocklisted sender
+	blocklist.addresses = map[ethcommon.Address]struct{}{sender: {}}
+	address, err = blocklist.CheckTransactions(txs)
+	require.NoError(t, err)
+	require.Equal(t, sender, *address)
+
+	// Invalid transaction
+	_, err = blocklist.CheckTransactions([]bellatrix.Transaction{{0x02, 0x01}})
+	require.Error(t, err)
+}
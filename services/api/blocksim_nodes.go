@@ -0,0 +1,282 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/go-utils/jsonrpc"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNoSimNodes         = errors.New("no block simulation nodes")
+	ErrSimNodeExists      = errors.New("block simulation node already exists")
+	ErrSimNodeNotFound    = errors.New("block simulation node not found")
+	ErrInvalidSimNodeURL  = errors.New("invalid block simulation node URL")
+	ErrLastSimNodeRemoval = errors.New("cannot remove the last block simulation node")
+
+	// health scoring of the block simulation nodes: a node is taken out of rotation if, over its last requests, the
+	// rate of request errors or the p99 latency exceeds the maximum
+	simNodeHealthWindow      = cli.GetEnvInt("BLOCKSIM_HEALTH_WINDOW", 100)
+	simNodeHealthMinRequests = cli.GetEnvInt("BLOCKSIM_HEALTH_MIN_REQUESTS", 20)
+	simNodeMaxErrorRate      = float64(cli.GetEnvInt("BLOCKSIM_MAX_ERROR_RATE_PERCENT", 50)) / 100
+	simNodeMaxP99Latency     = time.Duration(cli.GetEnvInt("BLOCKSIM_MAX_P99_LATENCY_MS", 5000)) * time.Millisecond // 0 to disable
+	simNodeProbeInterval     = time.Duration(cli.GetEnvInt("BLOCKSIM_PROBE_INTERVAL_MS", 5000)) * time.Millisecond
+)
+
+// simNodeProbeRequestMethod is the JSON-RPC method of the probe requests to unhealthy nodes
+const simNodeProbeRequestMethod = "eth_blockNumber"
+
+// SimNodeStatus is the health of a block simulation node, as served by the internal sim nodes endpoint
+type SimNodeStatus struct {
+	URL            string  `json:"url"`
+	Healthy        bool    `json:"healthy"`
+	NumRequests    int     `json:"num_requests"`
+	ErrorRate      float64 `json:"error_rate"`
+	P99LatencyMs   int64   `json:"p99_latency_ms"`
+	UnhealthySince string  `json:"unhealthy_since,omitempty"`
+}
+
+type simNodeResult struct {
+	latency time.Duration
+	failed  bool
+}
+
+// simNode is a block simulation node, with the results of its last requests for health scoring
+type simNode struct {
+	url string
+
+	mu             sync.Mutex
+	results        []simNodeResult // ring buffer of the last simNodeHealthWindow results
+	nextResult     int
+	healthy        bool
+	unhealthySince time.Time
+}
+
+func newSimNode(nodeURL string) (*simNode, error) {
+	u, err := url.Parse(nodeURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSimNodeURL, nodeURL)
+	}
+	return &simNode{url: nodeURL, healthy: true}, nil
+}
+
+// record adds the result of a request, and takes the node out of rotation if it became unhealthy. Only request errors
+// count as failures, as invalid blocks are no fault of the node.
+func (n *simNode) record(latency time.Duration, requestErr error) (becameUnhealthy bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	result := simNodeResult{latency: latency, failed: requestErr != nil}
+	if len(n.results) < simNodeHealthWindow {
+		n.results = append(n.results, result)
+	} else {
+		n.results[n.nextResult] = result
+		n.nextResult = (n.nextResult + 1) % simNodeHealthWindow
+	}
+
+	if !n.healthy || len(n.results) < simNodeHealthMinRequests {
+		return false
+	}
+	errorRate, p99Latency := n.scoreLocked()
+	if errorRate > simNodeMaxErrorRate || (simNodeMaxP99Latency > 0 && p99Latency > simNodeMaxP99Latency) {
+		n.healthy = false
+		n.unhealthySince = time.Now()
+		return true
+	}
+	return false
+}
+
+// recover puts the node back into rotation, with a clean health score
+func (n *simNode) recover() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.results = nil
+	n.nextResult = 0
+	n.healthy = true
+	n.unhealthySince = time.Time{}
+}
+
+func (n *simNode) isHealthy() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.healthy
+}
+
+// scoreLocked returns the error rate and the p99 latency of the recorded results
+func (n *simNode) scoreLocked() (errorRate float64, p99Latency time.Duration) {
+	if len(n.results) == 0 {
+		return 0, 0
+	}
+	latencies := make([]time.Duration, len(n.results))
+	numFailed := 0
+	for i, result := range n.results {
+		latencies[i] = result.latency
+		if result.failed {
+			numFailed++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	p99Index := (len(latencies)*99+99)/100 - 1
+	return float64(numFailed) / float64(len(n.results)), latencies[p99Index]
+}
+
+func (n *simNode) status() SimNodeStatus {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	errorRate, p99Latency := n.scoreLocked()
+	status := SimNodeStatus{
+		URL:          n.url,
+		Healthy:      n.healthy,
+		NumRequests:  len(n.results),
+		ErrorRate:    errorRate,
+		P99LatencyMs: p99Latency.Milliseconds(),
+	}
+	if !n.healthy {
+		status.UnhealthySince = n.unhealthySince.UTC().Format(time.RFC3339)
+	}
+	return status
+}
+
+// simNodePool rotates block simulation requests over the healthy nodes. Nodes can be added and removed at runtime.
+type simNodePool struct {
+	mu    sync.RWMutex
+	nodes []*simNode
+	next  atomic.Uint64
+}
+
+// newSimNodePool creates a pool of the comma separated node URLs
+func newSimNodePool(nodeURLs string) (*simNodePool, error) {
+	pool := new(simNodePool)
+	for _, nodeURL := range strings.Split(nodeURLs, ",") {
+		if nodeURL = strings.TrimSpace(nodeURL); nodeURL == "" {
+			continue
+		}
+		if err := pool.add(nodeURL); err != nil {
+			return nil, err
+		}
+	}
+	return pool, nil
+}
+
+// pick returns the next healthy node. If all nodes are unhealthy, they are all used, as no block can be accepted
+// without simulation.
+func (p *simNodePool) pick() (*simNode, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.nodes) == 0 {
+		return nil, ErrNoSimNodes
+	}
+
+	healthy := make([]*simNode, 0, len(p.nodes))
+	for _, node := range p.nodes {
+		if node.isHealthy() {
+			healthy = append(healthy, node)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = p.nodes
+	}
+	return healthy[p.next.Add(1)%uint64(len(healthy))], nil
+}
+
+func (p *simNodePool) add(nodeURL string) error {
+	node, err := newSimNode(nodeURL)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, existing := range p.nodes {
+		if existing.url == nodeURL {
+			return fmt.Errorf("%w: %s", ErrSimNodeExists, nodeURL)
+		}
+	}
+	p.nodes = append(p.nodes, node)
+	return nil
+}
+
+func (p *simNodePool) remove(nodeURL string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, node := range p.nodes {
+		if node.url == nodeURL {
+			if len(p.nodes) == 1 {
+				return ErrLastSimNodeRemoval
+			}
+			p.nodes = append(p.nodes[:i:i], p.nodes[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSimNodeNotFound, nodeURL)
+}
+
+func (p *simNodePool) all() []*simNode {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*simNode(nil), p.nodes...)
+}
+
+// probe sends a cheap request to each unhealthy node, and puts the nodes which answer back into rotation. Returns the
+// URLs of the recovered nodes.
+func (p *simNodePool) probe(client *http.Client) (recovered []string) {
+	for _, node := range p.all() {
+		if node.isHealthy() {
+			continue
+		}
+		req := jsonrpc.NewJSONRPCRequest("1", simNodeProbeRequestMethod, nil)
+		if _, requestErr, validationErr := SendJSONRPCRequest(context.Background(), client, *req, node.url, nil); requestErr != nil || validationErr != nil {
+			continue
+		}
+		node.recover()
+		recovered = append(recovered, node.url)
+	}
+	return recovered
+}
+
+func (api *RelayAPI) startSimNodeProbing() {
+	ticker := time.NewTicker(simNodeProbeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, nodeURL := range api.blockSimRateLimiter.ProbeSimNodes() {
+			api.log.WithField("simNode", nodeURL).Info("block simulation node recovered")
+		}
+	}
+}
+
+// handleInternalSimNodes lists the block simulation nodes with their health (GET), and adds (POST) or removes (DELETE)
+// the node given by the url argument
+func (api *RelayAPI) handleInternalSimNodes(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		nodeURL := req.URL.Query().Get("url")
+		log := api.log.WithFields(logrus.Fields{
+			"method":  "internalSimNodes",
+			"simNode": nodeURL,
+		})
+
+		var err error
+		if req.Method == http.MethodDelete {
+			err = api.blockSimRateLimiter.RemoveSimNode(nodeURL)
+		} else {
+			err = api.blockSimRateLimiter.AddSimNode(nodeURL)
+		}
+		if errors.Is(err, ErrSimNodeNotFound) {
+			api.RespondErrorCode(w, http.StatusNotFound, ErrorCodeNotFound, err.Error())
+			return
+		} else if err != nil {
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+			return
+		}
+		log.WithField("httpMethod", req.Method).Info("updated block simulation nodes")
+	}
+	api.RespondOK(w, api.blockSimRateLimiter.SimNodes())
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimNodeHealth(t *testing.T) {
+	node, err := newSimNode("http://localhost:8545")
+	require.NoError(t, err)
+
+	// Not scored before the minimum number of requests
+	for i := 0; i < simNodeHealthMinRequests-1; i++ {
+		require.False(t, node.record(time.Millisecond, errors.New("timeout")))
+	}
+	require.True(t, node.isHealthy())
+
+	// Unhealthy once the error rate exceeds the maximum
+	require.True(t, node.record(time.Millisecond, errors.New("timeout")))
+	require.False(t, node.isHealthy())
+	status := node.status()
+	require.False(t, status.Healthy)
+	require.Equal(t, 1.0, status.ErrorRate)
+	require.NotEmpty(t, status.UnhealthySince)
+
+	node.recover()
+	require.True(t, node.isHealthy())
+	require.Equal(t, 0, node.status().NumRequests)
+
+	// Unhealthy if the p99 latency exceeds the maximum
+	for i := 0; i < simNodeHealthMinRequests-1; i++ {
+		node.record(time.Millisecond, nil)
+	}
+	require.True(t, node.record(simNodeMaxP99Latency+time.Second, nil))
+}
+
+func TestSimNodePool(t *testing.T) {
+	_, err := newSimNodePool("http://node1,not a url")
+	require.ErrorIs(t, err, ErrInvalidSimNodeURL)
+
+	pool, err := newSimNodePool("http://node1, http://node2")
+	require.NoError(t, err)
+	require.Len(t, pool.all(), 2)
+	require.ErrorIs(t, pool.add("http://node1"), ErrSimNodeExists)
+
+	// Requests are rotated over the healthy nodes
+	picked := map[string]int{}
+	for i := 0; i < 4; i++ {
+		node, err := pool.pick()
+		require.NoError(t, err)
+		picked[node.url]++
+	}
+	require.Equal(t, map[string]int{"http://node1": 2, "http://node2": 2}, picked)
+
+	pool.all()[0].healthy = false
+	for i := 0; i < 4; i++ {
+		node, err := pool.pick()
+		require.NoError(t, err)
+		require.Equal(t, "http://node2", node.url)
+	}
+
+	// All nodes are used if none is healthy
+	pool.all()[1].healthy = false
+	_, err = pool.pick()
+	require.NoError(t, err)
+
+	require.NoError(t, pool.remove("http://node1"))
+	require.ErrorIs(t, pool.remove("http://node1"), ErrSimNodeNotFound)
+	require.ErrorIs(t, pool.remove("http://node2"), ErrLastSimNodeRemoval)
+}
+
+func TestSimNodeProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`)) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	pool, err := newSimNodePool(srv.URL + ",http://127.0.0.1:1")
+	require.NoError(t, err)
+	for _, node := range pool.all() {
+		node.healthy = false
+	}
+	require.Equal(t, []string{srv.URL}, pool.probe(srv.Client()))
+	require.True(t, pool.all()[0].isHealthy())
+	require.False(t, pool.all()[1].isHealthy())
+}
+
+func TestInternalSimNodes(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	blockSimRateLimiter, err := NewBlockSimulationRateLimiter("http://node1")
+	require.NoError(t, err)
+	backend.relay.blockSimRateLimiter = blockSimRateLimiter
+
+	rr := backend.request(http.MethodPost, pathInternalSimNodes+"?url=http://node2", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var nodes []SimNodeStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &nodes))
+	require.Len(t, nodes, 2)
+	require.Equal(t, "http://node2", nodes[1].URL)
+	require.True(t, nodes[1].Healthy)
+
+	rr = backend.request(http.MethodPost, pathInternalSimNodes+"?url=http://node2", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodDelete, pathInternalSimNodes+"?url=http://node1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = backend.request(http.MethodDelete, pathInternalSimNodes+"?url=http://node1", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = backend.request(http.MethodGet, pathInternalSimNodes, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &nodes))
+	require.Len(t, nodes, 1)
+}
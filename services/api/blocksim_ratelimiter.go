@@ -24,6 +24,7 @@ var (
 	ErrJSONDecodeFailed = errors.New("json error")
 	ErrNoCapellaPayload = errors.New("capella payload is nil")
 	ErrNoDenebPayload   = errors.New("deneb payload is nil")
+	ErrNoElectraPayload = errors.New("electra payload is nil")
 
 	maxConcurrentBlocks = int64(cli.GetEnvInt("BLOCKSIM_MAX_CONCURRENT", 4)) // 0 for no maximum
 	simRequestTimeout   = time.Duration(cli.GetEnvInt("BLOCKSIM_TIMEOUT_MS", 10000)) * time.Millisecond
@@ -32,20 +33,35 @@ var (
 type IBlockSimRateLimiter interface {
 	Send(context context.Context, payload *common.BuilderBlockValidationRequest, isHighPrio, fastTrack bool) (error, error)
 	CurrentCounter() int64
+	SetMaxConcurrent(maxConcurrent int64)
+
+	// Block simulation nodes, which can be changed at runtime
+	SimNodes() []SimNodeStatus
+	AddSimNode(nodeURL string) error
+	RemoveSimNode(nodeURL string) error
+	ProbeSimNodes() (recovered []string)
 }
 
 type BlockSimulationRateLimiter struct {
-	cv          *sync.Cond
-	counter     int64
-	blockSimURL string
-	client      http.Client
+	cv            *sync.Cond
+	counter       int64
+	maxConcurrent int64
+	nodes         *simNodePool
+	client        http.Client
 }
 
-func NewBlockSimulationRateLimiter(blockSimURL string) *BlockSimulationRateLimiter {
+// NewBlockSimulationRateLimiter creates a rate limiter for the comma separated block simulation node URLs. Requests
+// are rotated over the healthy nodes.
+func NewBlockSimulationRateLimiter(blockSimURLs string) (*BlockSimulationRateLimiter, error) {
+	nodes, err := newSimNodePool(blockSimURLs)
+	if err != nil {
+		return nil, err
+	}
 	return &BlockSimulationRateLimiter{
-		cv:          sync.NewCond(&sync.Mutex{}),
-		counter:     0,
-		blockSimURL: blockSimURL,
+		cv:            sync.NewCond(&sync.Mutex{}),
+		counter:       0,
+		maxConcurrent: maxConcurrentBlocks,
+		nodes:         nodes,
 		client: http.Client{ //nolint:exhaustruct
 			Timeout: simRequestTimeout,
 			Transport: &http.Transport{
@@ -55,13 +71,13 @@ func NewBlockSimulationRateLimiter(blockSimURL string) *BlockSimulationRateLimit
 				IdleConnTimeout:     90 * time.Second,
 			},
 		},
-	}
+	}, nil
 }
 
 func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *common.BuilderBlockValidationRequest, isHighPrio, fastTrack bool) (requestErr, validationErr error) {
 	b.cv.L.Lock()
 	cnt := atomic.AddInt64(&b.counter, 1)
-	if maxConcurrentBlocks > 0 && cnt > maxConcurrentBlocks {
+	if maxConcurrent := atomic.LoadInt64(&b.maxConcurrent); maxConcurrent > 0 && cnt > maxConcurrent {
 		b.cv.Wait()
 	}
 	b.cv.L.Unlock()
@@ -86,6 +102,10 @@ func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *comm
 		return ErrNoDenebPayload, nil
 	}
 
+	if payload.Version == spec.DataVersionElectra && payload.Electra == nil {
+		return ErrNoElectraPayload, nil
+	}
+
 	submission, err := common.GetBlockSubmissionInfo(payload.VersionedSubmitBlockRequest)
 	if err != nil {
 		return err, nil
@@ -94,6 +114,9 @@ func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *comm
 	// Prepare headers
 	headers := http.Header{}
 	headers.Add("X-Request-ID", fmt.Sprintf("%d/%s", submission.BidTrace.Slot, submission.BidTrace.BlockHash.String()))
+	if requestID := getRequestID(context); requestID != "" {
+		headers.Add(HeaderRelayRequestID, requestID)
+	}
 	if isHighPrio {
 		headers.Add("X-High-Priority", "true")
 	}
@@ -102,12 +125,25 @@ func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *comm
 	}
 
 	// Create and fire off JSON-RPC request
-	if payload.Version == spec.DataVersionDeneb {
+	switch payload.Version { //nolint:exhaustive
+	case spec.DataVersionElectra:
+		simReq = jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV4", payload)
+	case spec.DataVersionDeneb:
 		simReq = jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV3", payload)
-	} else {
+	default:
 		simReq = jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV2", payload)
 	}
-	_, requestErr, validationErr = SendJSONRPCRequest(&b.client, *simReq, b.blockSimURL, headers)
+	node, err := b.nodes.pick()
+	if err != nil {
+		return err, nil
+	}
+	start := time.Now()
+	_, requestErr, validationErr = SendJSONRPCRequest(context, &b.client, *simReq, node.url, headers)
+	if context.Err() == nil {
+		// requests cancelled by the caller, i.e. when the timeout budget of the submission ran out, don't count
+		// against the health of the node
+		node.record(time.Since(start), requestErr)
+	}
 	return requestErr, validationErr
 }
 
@@ -116,14 +152,44 @@ func (b *BlockSimulationRateLimiter) CurrentCounter() int64 {
 	return atomic.LoadInt64(&b.counter)
 }
 
+// SetMaxConcurrent changes the maximum number of concurrent simulation requests (0 for no maximum)
+func (b *BlockSimulationRateLimiter) SetMaxConcurrent(maxConcurrent int64) {
+	atomic.StoreInt64(&b.maxConcurrent, maxConcurrent)
+}
+
+// SimNodes returns the block simulation nodes with their health
+func (b *BlockSimulationRateLimiter) SimNodes() []SimNodeStatus {
+	nodes := b.nodes.all()
+	ret := make([]SimNodeStatus, len(nodes))
+	for i, node := range nodes {
+		ret[i] = node.status()
+	}
+	return ret
+}
+
+// AddSimNode adds a block simulation node to the rotation
+func (b *BlockSimulationRateLimiter) AddSimNode(nodeURL string) error {
+	return b.nodes.add(nodeURL)
+}
+
+// RemoveSimNode removes a block simulation node from the rotation. The last node can't be removed.
+func (b *BlockSimulationRateLimiter) RemoveSimNode(nodeURL string) error {
+	return b.nodes.remove(nodeURL)
+}
+
+// ProbeSimNodes puts the unhealthy nodes which answer a probe request back into rotation
+func (b *BlockSimulationRateLimiter) ProbeSimNodes() (recovered []string) {
+	return b.nodes.probe(&b.client)
+}
+
 // SendJSONRPCRequest sends the request to URL and returns the general JsonRpcResponse, or an error (note: not the JSONRPCError)
-func SendJSONRPCRequest(client *http.Client, req jsonrpc.JSONRPCRequest, url string, headers http.Header) (res *jsonrpc.JSONRPCResponse, requestErr, validationErr error) {
+func SendJSONRPCRequest(ctx context.Context, client *http.Client, req jsonrpc.JSONRPCRequest, url string, headers http.Header) (res *jsonrpc.JSONRPCResponse, requestErr, validationErr error) {
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err, nil
 	}
 
-	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(buf))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(buf))
 	if err != nil {
 		return nil, err, nil
 	}
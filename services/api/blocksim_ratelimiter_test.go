@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockSimulationMethod(t *testing.T) {
+	var request struct {
+		Method string `json:"method"`
+		Params []struct {
+			ExecutionRequests json.RawMessage `json:"execution_requests"`
+		} `json:"params"`
+	}
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+	}))
+	defer node.Close()
+
+	sk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	bidTrace := getTestBidTrace(phase0.BLSPubKey{}, 1, 1)
+
+	cases := []struct {
+		version                 spec.DataVersion
+		method                  string
+		expectExecutionRequests bool
+	}{
+		{spec.DataVersionCapella, "flashbots_validateBuilderSubmissionV2", false},
+		{spec.DataVersionDeneb, "flashbots_validateBuilderSubmissionV3", false},
+		{spec.DataVersionElectra, "flashbots_validateBuilderSubmissionV4", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.version.String(), func(t *testing.T) {
+			blockSimRateLimiter, err := NewBlockSimulationRateLimiter(node.URL)
+			require.NoError(t, err)
+			req := &common.BuilderBlockValidationRequest{
+				VersionedSubmitBlockRequest: common.TestBuilderSubmitBlockRequest(sk, bidTrace, tc.version),
+				ParentBeaconBlockRoot:       &phase0.Root{},
+			}
+			request.Method, request.Params = "", nil
+			requestErr, validationErr := blockSimRateLimiter.Send(context.Background(), req, false, false)
+			require.NoError(t, requestErr)
+			require.NoError(t, validationErr)
+			require.Equal(t, tc.method, request.Method)
+			require.Len(t, request.Params, 1)
+			require.Equal(t, tc.expectExecutionRequests, request.Params[0].ExecutionRequests != nil)
+		})
+	}
+}
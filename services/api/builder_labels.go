@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// builderOperatorCounter counts block submissions per builder operator (empty for builders without labels)
+type builderOperatorCounter struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+func (c *builderOperatorCounter) inc(operator string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]uint64)
+	}
+	c.counts[operator]++
+}
+
+// reset returns the counts since the last reset
+func (c *builderOperatorCounter) reset() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := c.counts
+	c.counts = nil
+	return counts
+}
+
+// reportBuilderOperatorSubmissions logs the number of block submissions per builder operator since the last call
+func (api *RelayAPI) reportBuilderOperatorSubmissions(slot uint64) {
+	counts := api.builderOperatorSubmissions.reset()
+	if len(counts) == 0 {
+		return
+	}
+	fields := logrus.Fields{"slot": slot}
+	for operator, count := range counts {
+		if operator == "" {
+			operator = "unlabeled"
+		}
+		fields["submissions_"+operator] = count
+	}
+	api.log.WithFields(fields).Info("block submissions per builder operator in previous slot")
+}
+
+// handleInternalBuilderLabels sets the operator, region and tier labels of a builder pubkey. Labels which are not
+// given are kept.
+func (api *RelayAPI) handleInternalBuilderLabels(w http.ResponseWriter, req *http.Request) {
+	builderPubkey := mux.Vars(req)["pubkey"]
+	builderEntry, err := api.db.GetBlockBuilderByPubkey(builderPubkey)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "builder not found")
+		return
+	}
+
+	labels := common.BuilderLabels{
+		Operator: builderEntry.Operator,
+		Region:   builderEntry.Region,
+		Tier:     builderEntry.Tier,
+	}
+	args := req.URL.Query()
+	if args.Has("operator") {
+		labels.Operator = args.Get("operator")
+	}
+	if args.Has("region") {
+		labels.Region = args.Get("region")
+	}
+	if args.Has("tier") {
+		labels.Tier = args.Get("tier")
+	}
+
+	log := api.log.WithFields(logrus.Fields{
+		"pubkey":   builderPubkey,
+		"operator": labels.Operator,
+		"region":   labels.Region,
+		"tier":     labels.Tier,
+	})
+	log.Info("updating builder labels")
+	if err := api.db.SetBlockBuilderLabels(builderPubkey, labels); err != nil {
+		fullErr := fmt.Errorf("unable to set labels in db for pubkey: %v: %w", builderPubkey, err)
+		log.Error(fullErr.Error())
+		api.RespondError(w, http.StatusInternalServerError, fullErr.Error())
+		return
+	}
+	api.RespondOK(w, labels)
+}
+
+// handleInternalBuilderOperators returns the builder stats summed up per operator
+func (api *RelayAPI) handleInternalBuilderOperators(w http.ResponseWriter, req *http.Request) {
+	entries, err := api.db.GetBuilderOperatorStats()
+	if err != nil {
+		api.log.WithError(err).Error("error getting builder operator stats")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.RespondOK(w, entries)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderOperatorCounter(t *testing.T) {
+	var c builderOperatorCounter
+	c.inc("op1")
+	c.inc("op1")
+	c.inc("")
+	require.Equal(t, map[string]uint64{"op1": 2, "": 1}, c.reset())
+	require.Empty(t, c.reset())
+}
+
+func TestInternalBuilderLabels(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	builderPubkey := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	builder := &database.BlockBuilderEntry{BuilderPubkey: builderPubkey, Region: "eu"}
+	backend.relay.db = database.MockDB{Builders: map[string]*database.BlockBuilderEntry{builderPubkey: builder}}
+
+	rr := backend.request(http.MethodPost, "/internal/v1/builder/labels/"+builderPubkey+"?operator=acme&tier=1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "acme", builder.Operator)
+	require.Equal(t, "eu", builder.Region, "labels which are not given are kept")
+	require.Equal(t, "1", builder.Tier)
+
+	rr = backend.request(http.MethodPost, "/internal/v1/builder/labels/0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4?operator=acme", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,316 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/events"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrInvalidBuilderQuarantineMode = errors.New("invalid BUILDER_QUARANTINE_MODE, must be deprioritize or block")
+
+var (
+	// anomaly detection of builders: a builder is quarantined if, over its last submissions, the rate of failed
+	// simulations, stale-slot submissions or inflated bids exceeds the maximum
+	builderQuarantineWindow         = cli.GetEnvInt("BUILDER_QUARANTINE_WINDOW", 100)
+	builderQuarantineMinSubmissions = cli.GetEnvInt("BUILDER_QUARANTINE_MIN_SUBMISSIONS", 20)
+	builderQuarantineMaxSimFailRate = float64(cli.GetEnvInt("BUILDER_QUARANTINE_MAX_SIM_FAILURE_PERCENT", 50)) / 100
+	builderQuarantineMaxStaleRate   = float64(cli.GetEnvInt("BUILDER_QUARANTINE_MAX_STALE_SLOT_PERCENT", 50)) / 100
+	builderQuarantineMaxInflateRate = float64(cli.GetEnvInt("BUILDER_QUARANTINE_MAX_INFLATED_PERCENT", 10)) / 100
+	builderQuarantineDuration       = time.Duration(cli.GetEnvInt("BUILDER_QUARANTINE_DURATION_MS", 300_000)) * time.Millisecond
+
+	// deprioritize (submissions are processed as low-prio and non-optimistic) or block (submissions are rejected)
+	builderQuarantineMode = common.GetEnv("BUILDER_QUARANTINE_MODE", builderQuarantineModeDeprioritize)
+)
+
+const (
+	builderQuarantineModeDeprioritize = "deprioritize"
+	builderQuarantineModeBlock        = "block"
+)
+
+// outcomes of block submissions which count towards the quarantine of a builder
+const (
+	builderOutcomeOK = iota
+	builderOutcomeSimFailed
+	builderOutcomeStaleSlot
+	builderOutcomeInflated
+)
+
+// BuilderQuarantineStatus is the anomaly score of a builder, as served by the internal quarantine endpoint
+type BuilderQuarantineStatus struct {
+	BuilderPubkey    string  `json:"builder_pubkey"`
+	Quarantined      bool    `json:"quarantined"`
+	QuarantinedUntil string  `json:"quarantined_until,omitempty"`
+	Reason           string  `json:"reason,omitempty"`
+	Exempt           bool    `json:"exempt"`
+	NumSubmissions   int     `json:"num_submissions"`
+	SimFailureRate   float64 `json:"sim_failure_rate"`
+	StaleSlotRate    float64 `json:"stale_slot_rate"`
+	InflatedRate     float64 `json:"inflated_rate"`
+}
+
+// builderScore holds the outcomes of the last submissions of a builder
+type builderScore struct {
+	outcomes         []int // ring buffer of the last builderQuarantineWindow outcomes
+	nextOutcome      int
+	quarantinedUntil time.Time
+	reason           string
+	exempt           bool // set by an admin, never quarantined automatically
+}
+
+func (s *builderScore) ratesLocked() (simFailRate, staleRate, inflatedRate float64) {
+	if len(s.outcomes) == 0 {
+		return 0, 0, 0
+	}
+	counts := make(map[int]int)
+	for _, outcome := range s.outcomes {
+		counts[outcome]++
+	}
+	n := float64(len(s.outcomes))
+	return float64(counts[builderOutcomeSimFailed]) / n, float64(counts[builderOutcomeStaleSlot]) / n, float64(counts[builderOutcomeInflated]) / n
+}
+
+// builderQuarantine tracks the submission outcomes of the builders, and quarantines the builders whose rate of
+// anomalies exceeds the thresholds for builderQuarantineDuration. The state is kept per API instance.
+type builderQuarantine struct {
+	mu     sync.Mutex
+	scores map[string]*builderScore
+}
+
+func newBuilderQuarantine() *builderQuarantine {
+	return &builderQuarantine{scores: make(map[string]*builderScore)}
+}
+
+func (q *builderQuarantine) getScoreLocked(builderPubkey string) *builderScore {
+	score, ok := q.scores[builderPubkey]
+	if !ok {
+		score = &builderScore{}
+		q.scores[builderPubkey] = score
+	}
+	return score
+}
+
+// record adds the outcome of a submission, and returns the reason if the builder became quarantined
+func (q *builderQuarantine) record(builderPubkey string, outcome int, now time.Time) (reason string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	score := q.getScoreLocked(builderPubkey)
+	if len(score.outcomes) < builderQuarantineWindow {
+		score.outcomes = append(score.outcomes, outcome)
+	} else {
+		score.outcomes[score.nextOutcome] = outcome
+		score.nextOutcome = (score.nextOutcome + 1) % builderQuarantineWindow
+	}
+
+	if score.exempt || now.Before(score.quarantinedUntil) || len(score.outcomes) < builderQuarantineMinSubmissions {
+		return ""
+	}
+	simFailRate, staleRate, inflatedRate := score.ratesLocked()
+	switch {
+	case simFailRate > builderQuarantineMaxSimFailRate:
+		reason = fmt.Sprintf("sim failure rate %.2f exceeds %.2f", simFailRate, builderQuarantineMaxSimFailRate)
+	case staleRate > builderQuarantineMaxStaleRate:
+		reason = fmt.Sprintf("stale-slot submission rate %.2f exceeds %.2f", staleRate, builderQuarantineMaxStaleRate)
+	case inflatedRate > builderQuarantineMaxInflateRate:
+		reason = fmt.Sprintf("inflated bid rate %.2f exceeds %.2f", inflatedRate, builderQuarantineMaxInflateRate)
+	default:
+		return ""
+	}
+	reason = fmt.Sprintf("%s over the last %d submissions", reason, len(score.outcomes))
+	q.quarantineLocked(score, reason, now)
+	return reason
+}
+
+// quarantineLocked quarantines a builder, starting over with a clean score once the quarantine ends
+func (q *builderQuarantine) quarantineLocked(score *builderScore, reason string, now time.Time) {
+	score.quarantinedUntil = now.Add(builderQuarantineDuration)
+	score.reason = reason
+	score.outcomes = nil
+	score.nextOutcome = 0
+}
+
+func (q *builderQuarantine) isQuarantined(builderPubkey string, now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	score, ok := q.scores[builderPubkey]
+	return ok && now.Before(score.quarantinedUntil)
+}
+
+// set applies an admin override: quarantine or release a builder, and/or exempt it from automatic quarantine
+func (q *builderQuarantine) set(builderPubkey string, quarantined, exempt *bool, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	score := q.getScoreLocked(builderPubkey)
+	if exempt != nil {
+		score.exempt = *exempt
+	}
+	if quarantined != nil {
+		if *quarantined {
+			q.quarantineLocked(score, "quarantined by admin", now)
+		} else {
+			score.quarantinedUntil = time.Time{}
+			score.reason = ""
+			score.outcomes = nil
+			score.nextOutcome = 0
+		}
+	}
+}
+
+func (q *builderQuarantine) statusLocked(builderPubkey string, score *builderScore, now time.Time) BuilderQuarantineStatus {
+	simFailRate, staleRate, inflatedRate := score.ratesLocked()
+	status := BuilderQuarantineStatus{
+		BuilderPubkey:  builderPubkey,
+		Quarantined:    now.Before(score.quarantinedUntil),
+		Exempt:         score.exempt,
+		NumSubmissions: len(score.outcomes),
+		SimFailureRate: simFailRate,
+		StaleSlotRate:  staleRate,
+		InflatedRate:   inflatedRate,
+	}
+	if status.Quarantined {
+		status.QuarantinedUntil = score.quarantinedUntil.UTC().Format(time.RFC3339)
+		status.Reason = score.reason
+	}
+	return status
+}
+
+// statuses returns the anomaly scores of all builders, quarantined builders first
+func (q *builderQuarantine) statuses(now time.Time) []BuilderQuarantineStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	statuses := make([]BuilderQuarantineStatus, 0, len(q.scores))
+	for builderPubkey, score := range q.scores {
+		statuses = append(statuses, q.statusLocked(builderPubkey, score, now))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Quarantined != statuses[j].Quarantined {
+			return statuses[i].Quarantined
+		}
+		return statuses[i].BuilderPubkey < statuses[j].BuilderPubkey
+	})
+	return statuses
+}
+
+// getBuilderOutcome classifies the response to a block submission for the anomaly detection
+func getBuilderOutcome(errCode ErrorCode) int {
+	switch errCode { //nolint:exhaustive
+	case ErrorCodeSimFailed:
+		return builderOutcomeSimFailed
+	case ErrorCodeSlotMismatch, ErrorCodePayloadAlreadyDelivered:
+		return builderOutcomeStaleSlot
+	case ErrorCodeBidValueMismatch:
+		return builderOutcomeInflated
+	default:
+		return builderOutcomeOK
+	}
+}
+
+// recordBuilderOutcome records the outcome of a block submission for the anomaly detection, and quarantines the builder
+// if it exceeds a threshold. Submissions rejected before their signature was checked are only counted if the signature
+// is valid, so that builders can't be quarantined by forged submissions.
+func (api *RelayAPI) recordBuilderOutcome(log *logrus.Entry, w *submissionResponseWriter, submission *common.BlockSubmissionInfo, isSigVerified *bool) {
+	outcome := getBuilderOutcome(w.errCode)
+	if !*isSigVerified {
+		if outcome == builderOutcomeOK {
+			return
+		}
+		ok, err := api.builderSigVerifier.Verify(submission.BidTrace, submission.Signature)
+		if err != nil || !ok {
+			return
+		}
+	}
+
+	builderPubkey := submission.BidTrace.BuilderPubkey.String()
+	reason := api.builderQuarantine.record(builderPubkey, outcome, time.Now())
+	if reason == "" {
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"quarantineReason": reason,
+		"quarantineMode":   builderQuarantineMode,
+	}).Warn("quarantining builder")
+	api.publishEvent(&events.Event{
+		Type:          events.TypeBuilderQuarantined,
+		Slot:          submission.BidTrace.Slot,
+		BuilderPubkey: builderPubkey,
+		Reason:        reason,
+	})
+}
+
+// isBuilderQuarantined returns whether submissions of the builder are deprioritized or blocked
+func (api *RelayAPI) isBuilderQuarantined(builderPubkey string) bool {
+	return api.builderQuarantine != nil && api.builderQuarantine.isQuarantined(builderPubkey, time.Now())
+}
+
+// handleInternalBuilderQuarantine lists the anomaly scores of the builders, or (POST/PUT) quarantines or releases a
+// builder (quarantined=true|false) and exempts it from automatic quarantine (exempt=true|false)
+func (api *RelayAPI) handleInternalBuilderQuarantine(w http.ResponseWriter, req *http.Request) {
+	if api.builderQuarantine == nil {
+		api.RespondErrorCode(w, http.StatusNotFound, ErrorCodeNotFound, "builder quarantine is disabled")
+		return
+	}
+
+	builderPubkey, ok := mux.Vars(req)["pubkey"]
+	if !ok {
+		api.RespondOK(w, api.builderQuarantine.statuses(time.Now()))
+		return
+	}
+	builderPubkey = strings.ToLower(builderPubkey)
+
+	if req.Method != http.MethodGet {
+		args := req.URL.Query()
+		parseBool := func(name string) (*bool, bool) {
+			switch args.Get(name) {
+			case "":
+				return nil, true
+			case "true":
+				v := true
+				return &v, true
+			case "false":
+				v := false
+				return &v, true
+			}
+			return nil, false
+		}
+		quarantined, ok1 := parseBool("quarantined")
+		exempt, ok2 := parseBool("exempt")
+		if !ok1 || !ok2 {
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "quarantined and exempt must be true or false")
+			return
+		}
+
+		api.log.WithFields(logrus.Fields{
+			"method":        "internalBuilderQuarantine",
+			"builderPubkey": builderPubkey,
+			"quarantined":   args.Get("quarantined"),
+			"exempt":        args.Get("exempt"),
+		}).Info("updating builder quarantine")
+		api.builderQuarantine.set(builderPubkey, quarantined, exempt, time.Now())
+		if quarantined != nil {
+			event := &events.Event{Type: events.TypeBuilderReleased, BuilderPubkey: builderPubkey, Reason: "released by admin"}
+			if *quarantined {
+				event.Type = events.TypeBuilderQuarantined
+				event.Reason = "quarantined by admin"
+			}
+			api.publishEvent(event)
+		}
+	}
+
+	for _, status := range api.builderQuarantine.statuses(time.Now()) {
+		if status.BuilderPubkey == builderPubkey {
+			api.RespondOK(w, status)
+			return
+		}
+	}
+	api.RespondErrorCode(w, http.StatusNotFound, ErrorCodeNotFound, "builder not found")
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderQuarantine(t *testing.T) {
+	q := newBuilderQuarantine()
+	now := time.Now()
+
+	// Not scored before the minimum number of submissions
+	for i := 0; i < builderQuarantineMinSubmissions-1; i++ {
+		require.Empty(t, q.record(testBuilderPubkey, builderOutcomeSimFailed, now))
+	}
+	require.False(t, q.isQuarantined(testBuilderPubkey, now))
+
+	// Quarantined once the sim failure rate exceeds the maximum, starting over with a clean window
+	require.Contains(t, q.record(testBuilderPubkey, builderOutcomeSimFailed, now), "sim failure rate")
+	require.True(t, q.isQuarantined(testBuilderPubkey, now))
+	status := q.statuses(now)[0]
+	require.True(t, status.Quarantined)
+	require.NotEmpty(t, status.Reason)
+	require.Equal(t, 0, status.NumSubmissions)
+
+	// Released after the quarantine duration
+	require.False(t, q.isQuarantined(testBuilderPubkey, now.Add(builderQuarantineDuration)))
+
+	// Inflated bids have a lower threshold, and exempt builders are never quarantined
+	exempt := true
+	q.set(testBuilderPubkey, nil, &exempt, now)
+	q.set(testBuilderPubkey, &exempt, nil, now)
+	require.True(t, q.isQuarantined(testBuilderPubkey, now))
+	released := false
+	q.set(testBuilderPubkey, &released, nil, now)
+	for i := 0; i < builderQuarantineMinSubmissions; i++ {
+		require.Empty(t, q.record(testBuilderPubkey, builderOutcomeInflated, now))
+	}
+	q.set(testBuilderPubkey, nil, &released, now)
+	require.Contains(t, q.record(testBuilderPubkey, builderOutcomeInflated, now), "inflated bid rate")
+}
+
+func TestCheckBuilderEntryQuarantined(t *testing.T) {
+	defer func(mode string) { builderQuarantineMode = mode }(builderQuarantineMode)
+
+	_, _, backend := startTestBackend(t)
+	backend.relay.builderQuarantine = newBuilderQuarantine()
+	builderPubkey, err := utils.HexToPubkey(testBuilderPubkey)
+	require.NoError(t, err)
+	backend.relay.blockBuildersCache[testBuilderPubkey] = &blockBuilderCacheEntry{
+		status: common.BuilderStatus{IsHighPrio: true, IsOptimistic: true},
+	}
+	quarantined := true
+	backend.relay.builderQuarantine.set(testBuilderPubkey, &quarantined, nil, time.Now())
+
+	// Deprioritized by default, without changing the cached status of the builder
+	w := httptest.NewRecorder()
+	builderEntry, ok := backend.relay.checkBuilderEntry(w, common.TestLog, builderPubkey)
+	require.True(t, ok)
+	require.False(t, builderEntry.status.IsHighPrio)
+	require.False(t, builderEntry.status.IsOptimistic)
+	require.True(t, backend.relay.blockBuildersCache[testBuilderPubkey].status.IsOptimistic)
+
+	builderQuarantineMode = builderQuarantineModeBlock
+	w = httptest.NewRecorder()
+	_, ok = backend.relay.checkBuilderEntry(w, common.TestLog, builderPubkey)
+	require.False(t, ok)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), string(ErrorCodeBuilderQuarantined))
+}
+
+func TestInternalBuilderQuarantine(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	path := "/internal/v1/builder/quarantine/" + testBuilderPubkey
+
+	rr := backend.request(http.MethodGet, pathInternalQuarantine, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	backend.relay.builderQuarantine = newBuilderQuarantine()
+	rr = backend.request(http.MethodPost, path+"?quarantined=yes", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodPost, path+"?quarantined=true&exempt=true", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var status BuilderQuarantineStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	require.True(t, status.Quarantined)
+	require.True(t, status.Exempt)
+
+	rr = backend.request(http.MethodPost, path+"?quarantined=false", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = backend.request(http.MethodGet, pathInternalQuarantine, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var statuses []BuilderQuarantineStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 1)
+	require.False(t, statuses[0].Quarantined)
+
+	rr = backend.request(http.MethodGet, "/internal/v1/builder/quarantine/0x01", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
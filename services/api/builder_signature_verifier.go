@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+)
+
+var ErrInvalidBuilderPubkey = errors.New("invalid builder pubkey")
+
+// BuilderSignatureVerifier verifies the signatures of block submissions. Builders submit many blocks per slot with
+// the same pubkey, so deserialized (and subgroup-checked) public keys are cached to avoid repeating that work for
+// every submission.
+type BuilderSignatureVerifier struct {
+	domain phase0.Domain
+
+	// builder pubkey -> *bls.PublicKey
+	pubkeys sync.Map
+}
+
+func NewBuilderSignatureVerifier(domain phase0.Domain) *BuilderSignatureVerifier {
+	return &BuilderSignatureVerifier{
+		domain: domain,
+	}
+}
+
+// Verify returns true if the signature over the bid trace is valid for the builder pubkey of the bid trace
+func (v *BuilderSignatureVerifier) Verify(bidTrace *builderApiV1.BidTrace, signature phase0.BLSSignature) (bool, error) {
+	pubkey, err := v.publicKey(bidTrace.BuilderPubkey)
+	if err != nil {
+		return false, err
+	}
+
+	sig, err := bls.SignatureFromBytes(signature[:])
+	if err != nil {
+		return false, err
+	}
+
+	root, err := ssz.ComputeSigningRoot(bidTrace, v.domain)
+	if err != nil {
+		return false, err
+	}
+
+	return bls.VerifySignature(sig, pubkey, root[:])
+}
+
+func (v *BuilderSignatureVerifier) publicKey(builderPubkey phase0.BLSPubKey) (*bls.PublicKey, error) {
+	if cached, ok := v.pubkeys.Load(builderPubkey); ok {
+		if pubkey, ok := cached.(*bls.PublicKey); ok {
+			return pubkey, nil
+		}
+	}
+
+	pubkey, err := bls.PublicKeyFromBytes(builderPubkey[:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBuilderPubkey, err)
+	}
+	v.pubkeys.Store(builderPubkey, pubkey)
+	return pubkey, nil
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderSignatureVerifier(t *testing.T) {
+	domain, err := common.ComputeDomain(ssz.DomainTypeAppBuilder, common.GenesisForkVersionMainnet, phase0.Root{}.String())
+	require.NoError(t, err)
+	verifier := NewBuilderSignatureVerifier(domain)
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	var builderPubkey phase0.BLSPubKey
+	copy(builderPubkey[:], bls.PublicKeyToBytes(pk))
+
+	bidTrace := &builderApiV1.BidTrace{
+		Slot:          1,
+		BuilderPubkey: builderPubkey,
+		Value:         uint256.NewInt(100),
+	}
+	signature, err := ssz.SignMessage(bidTrace, domain, sk)
+	require.NoError(t, err)
+
+	ok, err := verifier.Verify(bidTrace, signature)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The pubkey is cached, and still used for the next verification
+	_, ok = verifier.pubkeys.Load(builderPubkey)
+	require.True(t, ok)
+	bidTrace.Slot = 2
+	ok, err = verifier.Verify(bidTrace, signature)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Invalid pubkeys are not cached
+	bidTrace.BuilderPubkey = phase0.BLSPubKey{0x01}
+	_, err = verifier.Verify(bidTrace, signature)
+	require.ErrorIs(t, err, ErrInvalidBuilderPubkey)
+	_, ok = verifier.pubkeys.Load(bidTrace.BuilderPubkey)
+	require.False(t, ok)
+}
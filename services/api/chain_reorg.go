@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/flashbots/mev-boost-relay/events"
+	"github.com/sirupsen/logrus"
+)
+
+// processChainReorg invalidates everything built on the reorged-away head: its payload attributes are removed, so no
+// more blocks are accepted for it, and the bids and payloads built on it are removed, so getHeader doesn't serve them.
+// The event is received from every beacon node, but only the first one finds payload attributes to invalidate.
+func (api *RelayAPI) processChainReorg(reorg beaconclient.ChainReorgEvent) {
+	log := api.log.WithFields(logrus.Fields{
+		"reorgSlot":    reorg.Slot,
+		"reorgDepth":   reorg.Depth,
+		"oldHeadBlock": reorg.OldHeadBlock,
+		"newHeadBlock": reorg.NewHeadBlock,
+	})
+
+	if reorg.OldHeadBlock == "" {
+		log.Warn("chain reorg event without old head block")
+		return
+	}
+
+	reorgedAttrs := api.removeReorgedPayloadAttributes(reorg.OldHeadBlock)
+	if len(reorgedAttrs) == 0 {
+		log.Debug("chain reorg, no payload attributes built on the old head")
+		return
+	}
+
+	for _, attrs := range reorgedAttrs {
+		log := log.WithFields(logrus.Fields{
+			"slot":       attrs.slot,
+			"parentHash": attrs.parentHash,
+		})
+
+		var proposerPubkey string
+		if duty := api.getProposerDuty(attrs.slot); duty != nil && duty.Entry != nil && duty.Entry.Message != nil {
+			proposerPubkey = duty.Entry.Message.Pubkey.String()
+		}
+		log = log.WithField("proposerPubkey", proposerPubkey)
+
+		var blockHashes []string
+		if proposerPubkey != "" {
+			var err error
+			blockHashes, err = api.datastore.InvalidateBids(attrs.slot, attrs.parentHash, proposerPubkey)
+			if err != nil {
+				log.WithError(err).Error("failed to invalidate bids of the reorged head")
+			}
+		} else {
+			log.Warn("unknown proposer duty, cannot invalidate bids of the reorged head")
+		}
+
+		numMarked, err := api.db.MarkBuilderSubmissionsReorged(attrs.slot, attrs.parentHash)
+		if err != nil {
+			log.WithError(err).Error("failed to mark submissions of the reorged head")
+		}
+
+		log.WithFields(logrus.Fields{
+			"numInvalidatedBids":    len(blockHashes),
+			"numReorgedSubmissions": numMarked,
+		}).Warn("chain reorg, invalidated bids built on the old head")
+
+		api.publishEvent(&events.Event{
+			Type:           events.TypeChainReorg,
+			Slot:           attrs.slot,
+			ProposerPubkey: proposerPubkey,
+			Reason:         fmt.Sprintf("reorg of depth %d at slot %d, old head %s, %d bids invalidated", reorg.Depth, reorg.Slot, reorg.OldHeadBlock, len(blockHashes)),
+		})
+	}
+
+	if err := api.redis.IncStats(datastore.RedisStatsFieldChainReorgs, 1); err != nil {
+		log.WithError(err).Error("failed to increment the chain reorg count")
+	}
+}
+
+// removeReorgedPayloadAttributes removes the payload attributes built on the given beacon block, including from the
+// slot context, and returns them
+func (api *RelayAPI) removeReorgedPayloadAttributes(parentBlockRoot string) (removed []payloadAttributesHelper) {
+	api.payloadAttributesLock.Lock()
+	for key, attrs := range api.payloadAttributes {
+		if attrs.parentBlockRoot == parentBlockRoot {
+			delete(api.payloadAttributes, key)
+			removed = append(removed, attrs)
+		}
+	}
+	api.payloadAttributesLock.Unlock()
+
+	if ctx := api.slotCtx.Load(); ctx != nil && ctx.attrs.parentBlockRoot == parentBlockRoot {
+		api.slotCtx.CompareAndSwap(ctx, nil)
+	}
+	return removed
+}
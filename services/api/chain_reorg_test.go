@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessChainReorg(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{
+			GenesisTime: uint64(time.Now().UTC().Unix()),
+		},
+	}
+	backend.relay.forkSchedule = common.ForkVersionSchedule{CapellaEpoch: 0, DenebEpoch: -1, ElectraEpoch: -1}
+
+	_, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	proposerPubkey, err := utils.BlsPublicKeyToPublicKey(blsPubkey)
+	require.NoError(t, err)
+
+	slot := uint64(2)
+	backend.relay.headSlot.Store(slot - 1)
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		slot: {
+			Slot:  slot,
+			Entry: &builderApiV1.SignedValidatorRegistration{Message: &builderApiV1.ValidatorRegistration{Pubkey: proposerPubkey}},
+		},
+	}
+
+	oldHeadRoot := "0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2"
+	newHeadRoot := "0x76262e91970d375a19bfe8a867288d7b9cde43c8635f598d93d39d041706fc76"
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	newParentHash := "0x9a2fefd2fdb57f74993c7780ea5b9030d2897b615b89f808011ca5aebed54eaf"
+	reorgedAttrs := payloadAttributesHelper{slot: slot, parentHash: parentHash, parentBlockRoot: oldHeadRoot}
+	backend.relay.payloadAttributes[getPayloadAttributesKey(parentHash, slot)] = reorgedAttrs
+	backend.relay.payloadAttributes[getPayloadAttributesKey(newParentHash, slot)] = payloadAttributesHelper{slot: slot, parentHash: newParentHash, parentBlockRoot: newHeadRoot}
+	backend.relay.updateSlotContextAttrs(reorgedAttrs)
+
+	// A bid built on the old head
+	builder := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	bidValue := uint256.NewInt(100)
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey.String(),
+		Version:        spec.DataVersionCapella,
+	}
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builder, bidValue, &opts)
+	trace := &common.BidTraceV2WithBlobFields{BidTrace: builderApiV1.BidTrace{Value: bidValue}}
+	_, err = backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	require.NoError(t, err)
+
+	getHeaderPath := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey.String())
+	rr := backend.request(http.MethodGet, getHeaderPath, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	backend.relay.processChainReorg(beaconclient.ChainReorgEvent{Slot: slot - 1, Depth: 1, OldHeadBlock: oldHeadRoot, NewHeadBlock: newHeadRoot})
+
+	// Blocks and bids built on the old head are gone, the ones built on the new head are kept
+	_, ok := backend.relay.getPayloadAttributes(parentHash, slot)
+	require.False(t, ok)
+	_, ok = backend.relay.getPayloadAttributes(newParentHash, slot)
+	require.True(t, ok)
+	require.Nil(t, backend.relay.slotCtx.Load())
+
+	rr = backend.request(http.MethodGet, getHeaderPath, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	numReorgs, err := backend.redis.GetStatsUint64(datastore.RedisStatsFieldChainReorgs)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), numReorgs)
+
+	// The same reorg received from another beacon node is a no-op
+	backend.relay.processChainReorg(beaconclient.ChainReorgEvent{Slot: slot - 1, Depth: 1, OldHeadBlock: oldHeadRoot, NewHeadBlock: newHeadRoot})
+	numReorgs, err = backend.redis.GetStatsUint64(datastore.RedisStatsFieldChainReorgs)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), numReorgs)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+// handleProposerConstraints registers the inclusion constraints of an upcoming slot. They must be signed by the
+// proposer of the slot, and replace previously registered constraints of the slot.
+func (api *RelayAPI) handleProposerConstraints(w http.ResponseWriter, req *http.Request) {
+	log := api.log.WithFields(logrus.Fields{
+		"method":    "proposerConstraints",
+		"requestID": getRequestID(req.Context()),
+	})
+
+	signedConstraints := new(common.SignedInclusionConstraints)
+	if err := json.NewDecoder(req.Body).Decode(signedConstraints); err != nil || signedConstraints.Message == nil {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, "failed to decode inclusion constraints")
+		return
+	}
+	constraints := signedConstraints.Message
+	log = log.WithFields(logrus.Fields{
+		"pubkey":          constraints.Pubkey.String(),
+		"slot":            constraints.Slot,
+		"numTxHashes":     len(constraints.TxHashes),
+		"numTransactions": len(constraints.Transactions),
+	})
+
+	if len(constraints.TxHashes) > common.MaxConstraintsTransactions || len(constraints.Transactions) > common.MaxConstraintsTransactions {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, fmt.Sprintf("maximum number of transactions is %d", common.MaxConstraintsTransactions))
+		return
+	}
+
+	if constraints.Slot <= api.headSlot.Load() {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeSlotMismatch, "slot is too old")
+		return
+	}
+
+	duty := api.getProposerDuty(constraints.Slot)
+	if duty == nil || duty.Entry == nil || duty.Entry.Message == nil {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeUnknownProposerDuty, "unknown proposer duty for the slot")
+		return
+	}
+	if duty.Entry.Message.Pubkey != constraints.Pubkey {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeProposerMismatch, "pubkey is not the proposer of the slot")
+		return
+	}
+
+	ok, err := ssz.VerifySignature(constraints, api.opts.EthNetDetails.DomainBuilder, constraints.Pubkey[:], signedConstraints.Signature[:])
+	if err != nil || !ok {
+		log.WithError(err).Info("invalid inclusion constraints signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "invalid signature")
+		return
+	}
+
+	log.Info("updating inclusion constraints")
+	if err := api.datastore.SetInclusionConstraints(signedConstraints); err != nil {
+		log.WithError(err).Error("failed to save inclusion constraints")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.RespondOK(w, NilResponse)
+}
+
+// handleBuilderConstraints returns the inclusion constraints of a slot, which blocks submitted for it must satisfy
+func (api *RelayAPI) handleBuilderConstraints(w http.ResponseWriter, req *http.Request) {
+	slot, err := strconv.ParseUint(req.URL.Query().Get("slot"), 10, 64)
+	if err != nil {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSlot, "invalid slot argument")
+		return
+	}
+
+	constraints, err := api.datastore.GetInclusionConstraints(slot)
+	if err != nil {
+		api.log.WithError(err).Error("failed to get inclusion constraints")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if constraints == nil {
+		api.RespondErrorCode(w, http.StatusNotFound, ErrorCodeNotFound, "no inclusion constraints for the slot")
+		return
+	}
+	api.RespondOK(w, constraints)
+}
+
+// checkSubmissionConstraints rejects a block which doesn't include all transactions of the inclusion constraints of
+// its slot. Returns true if the block satisfies them, or if there are none.
+func (api *RelayAPI) checkSubmissionConstraints(w http.ResponseWriter, log *logrus.Entry, submission *common.BlockSubmissionInfo) bool {
+	constraints, err := api.datastore.GetInclusionConstraints(submission.BidTrace.Slot)
+	if err != nil {
+		log.WithError(err).Error("failed to get inclusion constraints")
+		api.RespondError(w, http.StatusInternalServerError, "failed to get inclusion constraints")
+		return false
+	} else if constraints == nil {
+		return true
+	}
+
+	if missing := constraints.Message.MissingTxHashes(submission.Transactions); len(missing) > 0 {
+		log.WithField("numMissingTxs", len(missing)).Info("block does not satisfy the inclusion constraints")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeConstraintsNotSatisfied, fmt.Sprintf("block does not include constrained transaction %s", missing[0].String()))
+		return false
+	}
+	return true
+}
+
+// checkBidConstraints returns true if the block of a bid satisfies the inclusion constraints of the slot, or if there
+// are none. The bid might have been received before the constraints were registered, so its payload is checked again
+// before serving it in getHeader.
+func (api *RelayAPI) checkBidConstraints(log *logrus.Entry, slot uint64, proposerPubkey, blockHash string) bool {
+	constraints, err := api.datastore.GetInclusionConstraints(slot)
+	if err != nil {
+		log.WithError(err).Error("failed to get inclusion constraints")
+		return false
+	} else if constraints == nil {
+		return true
+	}
+
+	payload, err := api.datastore.GetGetPayloadResponse(log, slot, proposerPubkey, blockHash)
+	if err != nil {
+		log.WithError(err).Error("failed to get payload to check the inclusion constraints")
+		return false
+	}
+	txs, err := payload.Transactions()
+	if err != nil {
+		log.WithError(err).Error("failed to get transactions to check the inclusion constraints")
+		return false
+	}
+	if missing := constraints.Message.MissingTxHashes(txs); len(missing) > 0 {
+		log.WithField("numMissingTxs", len(missing)).Warn("best bid does not satisfy the inclusion constraints")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInclusionConstraints(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{
+			GenesisTime: uint64(time.Now().UTC().Unix()),
+		},
+	}
+	backend.relay.forkSchedule = common.ForkVersionSchedule{CapellaEpoch: 0, DenebEpoch: -1, ElectraEpoch: -1}
+
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	proposerPubkey, err := utils.BlsPublicKeyToPublicKey(blsPubkey)
+	require.NoError(t, err)
+
+	slot := uint64(2)
+	backend.relay.headSlot.Store(slot - 1)
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		slot: {
+			Slot:  slot,
+			Entry: &builderApiV1.SignedValidatorRegistration{Message: &builderApiV1.ValidatorRegistration{Pubkey: proposerPubkey}},
+		},
+	}
+
+	// A bid whose block doesn't include the constrained transaction
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	builder := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	bidValue := uint256.NewInt(100)
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey.String(),
+		Version:        spec.DataVersionCapella,
+	}
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builder, bidValue, &opts)
+	trace := &common.BidTraceV2WithBlobFields{BidTrace: builderApiV1.BidTrace{Value: bidValue}}
+	_, err = backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	require.NoError(t, err)
+
+	getHeaderPath := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey.String())
+	rr := backend.request(http.MethodGet, getHeaderPath, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// No constraints registered yet
+	rr = backend.request(http.MethodGet, fmt.Sprintf("%s?slot=%d", pathBuilderConstraints, slot), nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	tx := bellatrix.Transaction{0x02, 0x01}
+	constraints := &common.InclusionConstraints{Pubkey: proposerPubkey, Slot: slot, Transactions: []hexutil.Bytes{hexutil.Bytes(tx)}}
+	signature, err := ssz.SignMessage(constraints, backend.relay.opts.EthNetDetails.DomainBuilder, sk)
+	require.NoError(t, err)
+
+	t.Run("invalid signature", func(t *testing.T) {
+		otherConstraints := &common.InclusionConstraints{Pubkey: proposerPubkey, Slot: slot}
+		rr := backend.request(http.MethodPost, pathProposerConstraints, &common.SignedInclusionConstraints{Message: otherConstraints, Signature: signature})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("not the proposer of the slot", func(t *testing.T) {
+		otherConstraints := &common.InclusionConstraints{Pubkey: proposerPubkey, Slot: slot + 1}
+		otherSignature, err := ssz.SignMessage(otherConstraints, backend.relay.opts.EthNetDetails.DomainBuilder, sk)
+		require.NoError(t, err)
+		rr := backend.request(http.MethodPost, pathProposerConstraints, &common.SignedInclusionConstraints{Message: otherConstraints, Signature: otherSignature})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("register constraints", func(t *testing.T) {
+		rr := backend.request(http.MethodPost, pathProposerConstraints, &common.SignedInclusionConstraints{Message: constraints, Signature: signature})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = backend.request(http.MethodGet, fmt.Sprintf("%s?slot=%d", pathBuilderConstraints, slot), nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := new(common.SignedInclusionConstraints)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, constraints, resp.Message)
+		require.Equal(t, signature, resp.Signature)
+	})
+
+	t.Run("bids without the constrained transaction are not served", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, getHeaderPath, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("submission check", func(t *testing.T) {
+		submission := &common.BlockSubmissionInfo{BidTrace: &builderApiV1.BidTrace{Slot: slot}}
+		w := httptest.NewRecorder()
+		require.False(t, backend.relay.checkSubmissionConstraints(w, common.TestLog, submission))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), string(ErrorCodeConstraintsNotSatisfied))
+
+		submission.Transactions = []bellatrix.Transaction{{0x03}, tx}
+		w = httptest.NewRecorder()
+		require.True(t, backend.relay.checkSubmissionConstraints(w, common.TestLog, submission))
+
+		// Slots without constraints
+		submission.BidTrace.Slot = slot + 1
+		submission.Transactions = nil
+		require.True(t, backend.relay.checkSubmissionConstraints(w, common.TestLog, submission))
+	})
+}
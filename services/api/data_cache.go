@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+var (
+	// how long data API responses are cached in memory (0 to disable)
+	dataAPICacheTTL = time.Duration(cli.GetEnvInt("DATA_API_CACHE_MS", 2_000)) * time.Millisecond
+
+	// maximum number of cached data API responses
+	dataAPICacheSize = cli.GetEnvInt("DATA_API_CACHE_SIZE", 1_000)
+
+	// requests with this value in the X-Cache-Bypass header are not served from the cache (empty to disable the bypass)
+	dataAPICacheBypassToken = common.GetEnv("DATA_API_CACHE_BYPASS_TOKEN", "")
+
+	// maximum data API requests per second per client IP and in total (0 for no limit)
+	dataAPIRateLimitPerIP  = cli.GetEnvInt("DATA_API_RATE_LIMIT_PER_IP", 0)
+	dataAPIRateLimitGlobal = cli.GetEnvInt("DATA_API_RATE_LIMIT_GLOBAL", 0)
+
+	// IPs or CIDR ranges of reverse proxies whose X-Forwarded-For header is trusted by the rate limiter
+	rateLimitTrustedProxies = common.GetEnvStrSlice("RATE_LIMIT_TRUSTED_PROXIES", nil)
+)
+
+const (
+	HeaderCacheBypass = "X-Cache-Bypass"
+	HeaderCacheStatus = "X-Cache"
+)
+
+type dataAPICacheEntry struct {
+	contentType string
+	body        []byte
+	expiresAt   time.Time
+}
+
+// dataAPICache caches the successful responses of the data API for a short time, keyed by the path and the normalized
+// query parameters, so that heavy public traffic doesn't hit the database with the same queries
+type dataAPICache struct {
+	ttl         time.Duration
+	bypassToken string
+	cache       *lru.Cache[string, dataAPICacheEntry]
+}
+
+func newDataAPICache(size int, ttl time.Duration, bypassToken string) *dataAPICache {
+	return &dataAPICache{
+		ttl:         ttl,
+		bypassToken: bypassToken,
+		cache:       lru.NewCache[string, dataAPICacheEntry](size),
+	}
+}
+
+// dataAPICacheKey returns the path with the sorted query parameters, whose names are lower case
+func dataAPICacheKey(req *http.Request) string {
+	query := req.URL.Query()
+	for name, values := range query {
+		if lower := strings.ToLower(name); lower != name {
+			query[lower] = append(query[lower], values...)
+			delete(query, name)
+		}
+	}
+	return req.URL.Path + "?" + query.Encode()
+}
+
+// cacheControl is the value of the Cache-Control header, in whole seconds
+func (c *dataAPICache) cacheControl() string {
+	return fmt.Sprintf("public, max-age=%d", int(c.ttl.Seconds()))
+}
+
+// wrap returns the handler which serves responses from the cache, and caches the successful responses
+func (c *dataAPICache) wrap(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if c.bypassToken != "" && req.Header.Get(HeaderCacheBypass) == c.bypassToken {
+			handler(w, req)
+			return
+		}
+
+		key := dataAPICacheKey(req)
+		if entry, ok := c.cache.Get(key); ok && time.Now().Before(entry.expiresAt) {
+			w.Header().Set("Content-Type", entry.contentType)
+			w.Header().Set("Cache-Control", c.cacheControl())
+			w.Header().Set(HeaderCacheStatus, "HIT")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(entry.body)
+			return
+		}
+
+		w.Header().Set(HeaderCacheStatus, "MISS")
+		recorder := &dataAPICacheRecorder{ResponseWriter: w, cacheControl: c.cacheControl(), statusCode: http.StatusOK}
+		handler(recorder, req)
+		if recorder.statusCode == http.StatusOK {
+			c.cache.Add(key, dataAPICacheEntry{
+				contentType: w.Header().Get("Content-Type"),
+				body:        recorder.body.Bytes(),
+				expiresAt:   time.Now().Add(c.ttl),
+			})
+		}
+	}
+}
+
+// dataAPICacheRecorder records the response for the cache, and adds the Cache-Control header to successful responses
+type dataAPICacheRecorder struct {
+	http.ResponseWriter
+	cacheControl string
+	statusCode   int
+	wroteHeader  bool
+	body         bytes.Buffer
+}
+
+func (w *dataAPICacheRecorder) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.wroteHeader = true
+	if statusCode == http.StatusOK {
+		w.Header().Set("Cache-Control", w.cacheControl)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *dataAPICacheRecorder) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// dataAPIHandler wraps a data API handler with the rate limiter and the response cache, if enabled. Rate limited
+// requests are rejected before reaching the cache.
+func (api *RelayAPI) dataAPIHandler(handler http.HandlerFunc) http.Handler {
+	if api.dataCache != nil {
+		handler = api.dataCache.wrap(handler)
+	}
+	if api.dataRateLimiter != nil {
+		return api.dataRateLimiter.Wrap(handler)
+	}
+	return handler
+}
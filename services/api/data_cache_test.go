@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataAPICacheKey(t *testing.T) {
+	req1 := httptest.NewRequest(http.MethodGet, pathDataBids+"?slot=1&limit=10", nil)
+	req2 := httptest.NewRequest(http.MethodGet, pathDataBids+"?Limit=10&slot=1", nil)
+	req3 := httptest.NewRequest(http.MethodGet, pathDataBids+"?slot=2&limit=10", nil)
+	require.Equal(t, dataAPICacheKey(req1), dataAPICacheKey(req2))
+	require.NotEqual(t, dataAPICacheKey(req1), dataAPICacheKey(req3))
+}
+
+func TestDataAPICache(t *testing.T) {
+	numCalls := 0
+	status := http.StatusOK
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"slot":"` + req.URL.Query().Get("slot") + `"}`))
+	}
+	cache := newDataAPICache(10, 2*time.Second, "secret")
+	cached := cache.wrap(handler)
+
+	get := func(url, bypassToken string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if bypassToken != "" {
+			req.Header.Set(HeaderCacheBypass, bypassToken)
+		}
+		rr := httptest.NewRecorder()
+		cached(rr, req)
+		return rr
+	}
+
+	t.Run("miss then hit", func(t *testing.T) {
+		rr := get(pathDataBids+"?slot=1", "")
+		require.Equal(t, "MISS", rr.Header().Get(HeaderCacheStatus))
+		require.Equal(t, "public, max-age=2", rr.Header().Get("Cache-Control"))
+
+		rr = get(pathDataBids+"?slot=1", "")
+		require.Equal(t, "HIT", rr.Header().Get(HeaderCacheStatus))
+		require.Equal(t, "public, max-age=2", rr.Header().Get("Cache-Control"))
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		require.Equal(t, `{"slot":"1"}`, rr.Body.String())
+		require.Equal(t, 1, numCalls)
+	})
+
+	t.Run("bypass", func(t *testing.T) {
+		rr := get(pathDataBids+"?slot=1", "secret")
+		require.Equal(t, "", rr.Header().Get(HeaderCacheStatus))
+		require.Equal(t, 2, numCalls)
+
+		rr = get(pathDataBids+"?slot=1", "wrong")
+		require.Equal(t, "HIT", rr.Header().Get(HeaderCacheStatus))
+		require.Equal(t, 2, numCalls)
+	})
+
+	t.Run("errors aren't cached", func(t *testing.T) {
+		status = http.StatusBadRequest
+		rr := get(pathDataBids+"?slot=2", "")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, "", rr.Header().Get("Cache-Control"))
+
+		get(pathDataBids+"?slot=2", "")
+		require.Equal(t, 4, numCalls)
+	})
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/gorilla/mux"
+)
+
+const pathDiagnosticsRuntime = "/debug/runtime"
+
+// RuntimeDiagnostics is the response of the runtime diagnostics endpoint
+type RuntimeDiagnostics struct {
+	NumGoroutines int `json:"num_goroutines"`
+	NumCPU        int `json:"num_cpu"`
+	GOMAXPROCS    int `json:"gomaxprocs"`
+
+	HeapAllocBytes  uint64  `json:"heap_alloc_bytes"`
+	HeapInuseBytes  uint64  `json:"heap_inuse_bytes"`
+	HeapObjects     uint64  `json:"heap_objects"`
+	StackInuseBytes uint64  `json:"stack_inuse_bytes"`
+	SysBytes        uint64  `json:"sys_bytes"`
+	TotalAllocBytes uint64  `json:"total_alloc_bytes"`
+	NumGC           int64   `json:"num_gc"`
+	LastGC          string  `json:"last_gc,omitempty"`
+	GCPauseTotalMs  int64   `json:"gc_pause_total_ms"`
+	GCLastPauseUs   int64   `json:"gc_last_pause_us"`
+	GCCPUFraction   float64 `json:"gc_cpu_fraction"`
+
+	RedisPool         RedisPoolStats    `json:"redis_pool"`
+	RedisReadonlyPool RedisPoolStats    `json:"redis_readonly_pool"`
+	PostgresPool      PostgresPoolStats `json:"postgres_pool"`
+}
+
+type RedisPoolStats struct {
+	Hits       uint32 `json:"hits"`
+	Misses     uint32 `json:"misses"`
+	Timeouts   uint32 `json:"timeouts"`
+	TotalConns uint32 `json:"total_conns"`
+	IdleConns  uint32 `json:"idle_conns"`
+	StaleConns uint32 `json:"stale_conns"`
+}
+
+type PostgresPoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+func toRedisPoolStats(stats *redis.PoolStats) RedisPoolStats {
+	if stats == nil {
+		return RedisPoolStats{}
+	}
+	return RedisPoolStats{
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Timeouts:   stats.Timeouts,
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		StaleConns: stats.StaleConns,
+	}
+}
+
+// getRuntimeDiagnostics collects the runtime, GC and connection pool stats. Reading the memory stats briefly stops the
+// world, so it's only served on the diagnostics listener.
+func (api *RelayAPI) getRuntimeDiagnostics() RuntimeDiagnostics {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+
+	diag := RuntimeDiagnostics{
+		NumGoroutines:   runtime.NumGoroutine(),
+		NumCPU:          runtime.NumCPU(),
+		GOMAXPROCS:      runtime.GOMAXPROCS(0),
+		HeapAllocBytes:  memStats.HeapAlloc,
+		HeapInuseBytes:  memStats.HeapInuse,
+		HeapObjects:     memStats.HeapObjects,
+		StackInuseBytes: memStats.StackInuse,
+		SysBytes:        memStats.Sys,
+		TotalAllocBytes: memStats.TotalAlloc,
+		NumGC:           gcStats.NumGC,
+		GCPauseTotalMs:  gcStats.PauseTotal.Milliseconds(),
+		GCCPUFraction:   memStats.GCCPUFraction,
+	}
+	if !gcStats.LastGC.IsZero() {
+		diag.LastGC = gcStats.LastGC.UTC().Format(time.RFC3339Nano)
+	}
+	if len(gcStats.Pause) > 0 {
+		diag.GCLastPauseUs = gcStats.Pause[0].Microseconds()
+	}
+
+	if api.redis != nil {
+		primary, readonly := api.redis.PoolStats()
+		diag.RedisPool = toRedisPoolStats(primary)
+		diag.RedisReadonlyPool = toRedisPoolStats(readonly)
+	}
+	if api.db != nil {
+		stats := api.db.Stats()
+		diag.PostgresPool = PostgresPoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		}
+	}
+	return diag
+}
+
+func (api *RelayAPI) handleDiagnosticsRuntime(w http.ResponseWriter, req *http.Request) {
+	api.RespondOK(w, api.getRuntimeDiagnostics())
+}
+
+// getDiagnosticsRouter serves pprof, the expvar stats and the runtime diagnostics. It must only be reachable by
+// operators, as profiles can be expensive and reveal internals.
+func (api *RelayAPI) getDiagnosticsRouter() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	r.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+	r.Handle("/debug/vars", expvar.Handler()).Methods(http.MethodGet)
+	r.HandleFunc(pathDiagnosticsRuntime, api.handleDiagnosticsRuntime).Methods(http.MethodGet)
+	return r
+}
+
+// startDiagnosticsServer starts the diagnostics listener (blocking). There is no write timeout, as CPU profiles and
+// traces are streamed for as long as requested.
+func (api *RelayAPI) startDiagnosticsServer() error {
+	api.log.WithField("listenAddr", api.opts.DiagnosticsListenAddr).Info("starting diagnostics listener")
+	api.diagnosticsSrv = &http.Server{
+		Addr:              api.opts.DiagnosticsListenAddr,
+		Handler:           api.getDiagnosticsRouter(),
+		ReadHeaderTimeout: time.Duration(apiReadHeaderTimeoutMs) * time.Millisecond,
+		IdleTimeout:       time.Duration(apiIdleTimeoutMs) * time.Millisecond,
+	}
+	return api.diagnosticsSrv.ListenAndServe()
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsRouter(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	router := backend.relay.getDiagnosticsRouter()
+
+	req, err := http.NewRequest(http.MethodGet, pathDiagnosticsRuntime, nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(RuntimeDiagnostics)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Positive(t, resp.NumGoroutines)
+	require.Positive(t, resp.HeapAllocBytes)
+
+	for _, path := range []string{"/debug/vars", "/debug/pprof/", "/debug/pprof/goroutine"} {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, path)
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import "net/http"
+
+// ErrorCode is a stable, machine-readable error code which is returned in the JSON body of error responses, so that
+// builder and proposer software can branch on it instead of parsing the error message
+type ErrorCode string
+
+const (
+	// Generic error codes
+	ErrorCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	ErrorCodeInternalError      ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrorCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrorCodeForbidden          ErrorCode = "FORBIDDEN"
+	ErrorCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrorCodeShuttingDown       ErrorCode = "SHUTTING_DOWN"
+	ErrorCodeBeaconNotSynced    ErrorCode = "BEACON_NOT_SYNCED"
+	ErrorCodeRequestTimeout     ErrorCode = "REQUEST_TIMEOUT"
+	ErrorCodeHeadersTooLarge    ErrorCode = "HEADERS_TOO_LARGE"
+
+	// Request validation
+	ErrorCodeDecodeFailed     ErrorCode = "DECODE_FAILED"
+	ErrorCodeInvalidSlot      ErrorCode = "INVALID_SLOT"
+	ErrorCodeInvalidPubkey    ErrorCode = "INVALID_PUBKEY"
+	ErrorCodeInvalidHash      ErrorCode = "INVALID_HASH"
+	ErrorCodeInvalidSignature ErrorCode = "INVALID_SIGNATURE"
+	ErrorCodeInvalidTimestamp ErrorCode = "INVALID_TIMESTAMP"
+	ErrorCodeSlotMismatch     ErrorCode = "SLOT_MISMATCH"
+	ErrorCodeForkMismatch     ErrorCode = "FORK_MISMATCH"
+	ErrorCodeRequestTooLate   ErrorCode = "REQUEST_TOO_LATE"
+
+	// Proposer API
+	ErrorCodeUnknownValidator          ErrorCode = "UNKNOWN_VALIDATOR"
+	ErrorCodeProposerMismatch          ErrorCode = "PROPOSER_MISMATCH"
+	ErrorCodeGetPayloadEquivocation    ErrorCode = "GETPAYLOAD_EQUIVOCATION"
+	ErrorCodePayloadNotFound           ErrorCode = "PAYLOAD_NOT_FOUND"
+	ErrorCodePayloadAlreadyDelivered   ErrorCode = "PAYLOAD_ALREADY_DELIVERED"
+	ErrorCodePayloadMismatch           ErrorCode = "PAYLOAD_MISMATCH"
+	ErrorCodeParentBeaconRootMismatch  ErrorCode = "PARENT_BEACON_ROOT_MISMATCH"
+	ErrorCodeExecutionRequestsMismatch ErrorCode = "EXECUTION_REQUESTS_MISMATCH"
+	ErrorCodePublishFailed             ErrorCode = "PUBLISH_FAILED"
+
+	// Builder API
+	ErrorCodeUnknownProposerDuty       ErrorCode = "UNKNOWN_PROPOSER_DUTY"
+	ErrorCodeFeeRecipientMismatch      ErrorCode = "FEE_RECIPIENT_MISMATCH"
+	ErrorCodePayloadAttributesUnknown  ErrorCode = "PAYLOAD_ATTRIBUTES_UNKNOWN"
+	ErrorCodePayloadAttributesMismatch ErrorCode = "PAYLOAD_ATTRIBUTES_MISMATCH"
+	ErrorCodeCancellationsDisabled     ErrorCode = "CANCELLATIONS_DISABLED"
+	ErrorCodeBuilderNotAllowed         ErrorCode = "BUILDER_NOT_ALLOWED"
+	ErrorCodeBuilderQuarantined        ErrorCode = "BUILDER_QUARANTINED"
+	ErrorCodeSanityCheckFailed         ErrorCode = "SANITY_CHECK_FAILED"
+	ErrorCodeSimFailed                 ErrorCode = "SIM_FAILED"
+	ErrorCodeSimRequestFailed          ErrorCode = "SIM_REQUEST_FAILED"
+	ErrorCodeSimTimeout                ErrorCode = "SIM_TIMEOUT"
+	ErrorCodeNewerPayloadExists        ErrorCode = "NEWER_PAYLOAD_EXISTS"
+	ErrorCodeBlocklistedTransaction    ErrorCode = "BLOCKLISTED_TRANSACTION"
+	ErrorCodeBlocklistUnavailable      ErrorCode = "BLOCKLIST_UNAVAILABLE"
+	ErrorCodePayloadTooLarge           ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrorCodeGasLimitMismatch          ErrorCode = "GAS_LIMIT_MISMATCH"
+	ErrorCodeGasUsedExceedsGasLimit    ErrorCode = "GAS_USED_EXCEEDS_GAS_LIMIT"
+	ErrorCodeParentHashMismatch        ErrorCode = "PARENT_HASH_MISMATCH"
+	ErrorCodeBlockNumberMismatch       ErrorCode = "BLOCK_NUMBER_MISMATCH"
+	ErrorCodeWithdrawalsRootMismatch   ErrorCode = "WITHDRAWALS_ROOT_MISMATCH"
+	ErrorCodePrevRandaoMismatch        ErrorCode = "PREV_RANDAO_MISMATCH"
+	ErrorCodeConstraintsNotSatisfied   ErrorCode = "CONSTRAINTS_NOT_SATISFIED"
+	ErrorCodePreconfDisabled           ErrorCode = "PRECONF_DISABLED"
+	ErrorCodePreconfCommitmentMismatch ErrorCode = "PRECONF_COMMITMENT_MISMATCH"
+	ErrorCodeBidValueMismatch          ErrorCode = "BID_VALUE_MISMATCH"
+	ErrorCodeInsufficientCollateral    ErrorCode = "INSUFFICIENT_COLLATERAL"
+)
+
+// errorCodeForStatus returns the generic error code for responses without a specific error code
+func errorCodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusServiceUnavailable:
+		return ErrorCodeServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrorCodeSimTimeout
+	case http.StatusRequestTimeout:
+		return ErrorCodeRequestTimeout
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodePayloadTooLarge
+	case http.StatusRequestHeaderFieldsTooLarge:
+		return ErrorCodeHeadersTooLarge
+	}
+	if status >= http.StatusInternalServerError {
+		return ErrorCodeInternalError
+	}
+	return ErrorCodeInvalidRequest
+}
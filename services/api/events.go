@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/events"
+)
+
+var (
+	// comma separated list of sinks to publish relay events to (webhook, NATS or Kafka REST proxy URIs, see events.NewSink)
+	eventSinks = common.GetEnv("EVENT_SINKS", "")
+
+	// number of events to queue per sink, before dropping events
+	eventQueueSize = cli.GetEnvInt("EVENT_QUEUE_SIZE", 10_000)
+
+	// Kafka topic to stream all received bid traces to, through a Kafka REST proxy (kafka+http://host:port/topic)
+	bidFirehoseKafka         = common.GetEnv("BID_FIREHOSE_KAFKA", "")
+	bidFirehoseQueueSize     = cli.GetEnvInt("BID_FIREHOSE_QUEUE_SIZE", 50_000)
+	bidFirehoseBatchSize     = cli.GetEnvInt("BID_FIREHOSE_BATCH_SIZE", 500)
+	bidFirehoseFlushInterval = time.Duration(cli.GetEnvInt("BID_FIREHOSE_FLUSH_MS", 100)) * time.Millisecond
+)
+
+func (api *RelayAPI) isEventPublishingEnabled() bool {
+	return api.events != nil
+}
+
+func (api *RelayAPI) publishEvent(event *events.Event) {
+	if !api.isEventPublishingEnabled() {
+		return
+	}
+	event.TimestampMs = time.Now().UTC().UnixMilli()
+	api.events.Publish(event)
+}
+
+// publishBidEvent publishes a received bid, with the outcome recorded by the response writer
+func (api *RelayAPI) publishBidEvent(w *submissionResponseWriter, submission *common.BlockSubmissionInfo) {
+	event := &events.Event{
+		Type:           events.TypeBidAccepted,
+		Slot:           submission.BidTrace.Slot,
+		BuilderPubkey:  submission.BidTrace.BuilderPubkey.String(),
+		ProposerPubkey: submission.BidTrace.ProposerPubkey.String(),
+		BlockHash:      submission.BidTrace.BlockHash.String(),
+		Value:          submission.BidTrace.Value.Dec(),
+	}
+	if w.statusCode >= http.StatusMultipleChoices {
+		event.Type = events.TypeBidRejected
+		event.ErrorCode = string(w.errCode)
+		event.Reason = w.message
+	}
+	api.publishEvent(event)
+}
+
+// publishDemotionEvent publishes the demotion of a builder because of the given block
+func (api *RelayAPI) publishDemotionEvent(bidTrace *builderApiV1.BidTrace, demotionErr error) {
+	event := &events.Event{
+		Type:           events.TypeBuilderDemoted,
+		Slot:           bidTrace.Slot,
+		BuilderPubkey:  bidTrace.BuilderPubkey.String(),
+		ProposerPubkey: bidTrace.ProposerPubkey.String(),
+		BlockHash:      bidTrace.BlockHash.String(),
+		Value:          bidTrace.Value.Dec(),
+	}
+	if demotionErr != nil {
+		event.Reason = demotionErr.Error()
+	}
+	api.publishEvent(event)
+}
+
+func (api *RelayAPI) isBidFirehoseEnabled() bool {
+	return api.bidFirehose != nil
+}
+
+// streamBidTrace adds a received bid to the firehose, with the outcome recorded by the response writer
+func (api *RelayAPI) streamBidTrace(w *submissionResponseWriter, submission *common.BlockSubmissionInfo, receivedAt, eligibleAt time.Time, simDurationUs uint64) {
+	record := &events.BidTraceRecord{
+		Slot:                 submission.BidTrace.Slot,
+		ParentHash:           submission.BidTrace.ParentHash.String(),
+		BlockHash:            submission.BidTrace.BlockHash.String(),
+		BuilderPubkey:        submission.BidTrace.BuilderPubkey.String(),
+		ProposerPubkey:       submission.BidTrace.ProposerPubkey.String(),
+		ProposerFeeRecipient: submission.BidTrace.ProposerFeeRecipient.String(),
+		GasLimit:             submission.GasLimit,
+		GasUsed:              submission.GasUsed,
+		Value:                submission.BidTrace.Value.Dec(),
+		BlockNumber:          submission.BlockNumber,
+		NumTx:                uint64(len(submission.Transactions)),
+		ReceivedAtMs:         receivedAt.UnixMilli(),
+		SimDurationUs:        simDurationUs,
+		Accepted:             w.statusCode < http.StatusMultipleChoices,
+		StatusCode:           w.statusCode,
+		ErrorCode:            string(w.errCode),
+		RejectionReason:      w.message,
+	}
+	if !eligibleAt.IsZero() {
+		record.EligibleAtMs = eligibleAt.UnixMilli()
+	}
+	api.bidFirehose.Add(record)
+}
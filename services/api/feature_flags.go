@@ -0,0 +1,169 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/events"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	uberatomic "go.uber.org/atomic"
+)
+
+// bearer token for changing feature flags with the internal API (empty to disable runtime changes)
+var featureFlagsAuthToken = common.GetEnv("FEATURE_FLAGS_AUTH_TOKEN", "")
+
+// maximum number of audit log entries served by the internal API
+const featureFlagChangesLimit = 100
+
+// runtimeFeatureFlag is a feature flag which can be toggled at runtime. Its value is read atomically in the hot paths.
+type runtimeFeatureFlag struct {
+	name        string
+	description string
+	value       *uberatomic.Bool
+}
+
+// FeatureFlagStatus is the state of a runtime feature flag, as served by the internal API
+type FeatureFlagStatus struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// featureFlagsResponse is the response of the feature flags endpoint of the internal API
+type featureFlagsResponse struct {
+	Flags   []FeatureFlagStatus                `json:"flags"`
+	Changes []*database.FeatureFlagChangeEntry `json:"changes"`
+}
+
+// runtimeFeatureFlags returns the feature flags which can be toggled at runtime, named like the environment variables
+// which set their initial value
+func (api *RelayAPI) runtimeFeatureFlags() []runtimeFeatureFlag {
+	return []runtimeFeatureFlag{
+		{"ENABLE_BUILDER_CANCELLATIONS", "accept block submissions with cancellations", &api.ffEnableCancellations},
+		{"DISABLE_OPTIMISTIC_SUBMISSIONS", "process block submissions of optimistic builders like those of other builders", &api.ffDisableOptimisticSubmissions},
+		{"DISABLE_DENEB_SUBMISSIONS", "reject deneb block and header submissions", &api.ffDisableDenebSubmissions},
+		{"FORCE_GET_HEADER_204", "don't serve bids on getHeader", &api.ffForceGetHeader204},
+		{"DISABLE_LOWPRIO_BUILDERS", "only accept block submissions of high-prio builders", &api.ffDisableLowPrioBuilders},
+	}
+}
+
+func (api *RelayAPI) featureFlagStatuses() []FeatureFlagStatus {
+	flags := api.runtimeFeatureFlags()
+	statuses := make([]FeatureFlagStatus, 0, len(flags))
+	for _, flag := range flags {
+		statuses = append(statuses, FeatureFlagStatus{
+			Name:        flag.name,
+			Description: flag.description,
+			Enabled:     flag.value.Load(),
+		})
+	}
+	return statuses
+}
+
+// isFeatureFlagsRequestAuthorized checks the bearer token of a request to change a feature flag
+func isFeatureFlagsRequestAuthorized(req *http.Request) bool {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(featureFlagsAuthToken)) == 1
+}
+
+// handleInternalFeatureFlags serves the runtime feature flags with the latest changes, and toggles a flag with
+// POST /internal/v1/feature_flags/{name}?enabled=true|false&actor=..&reason=..
+func (api *RelayAPI) handleInternalFeatureFlags(w http.ResponseWriter, req *http.Request) {
+	name, ok := mux.Vars(req)["name"]
+	if ok && req.Method != http.MethodGet {
+		if !api.setFeatureFlag(w, req, strings.ToUpper(name)) {
+			return
+		}
+	}
+
+	changes, err := api.db.GetFeatureFlagChanges(featureFlagChangesLimit)
+	if err != nil {
+		api.log.WithError(err).Error("failed to get feature flag changes")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternalError, err.Error())
+		return
+	}
+	api.RespondOK(w, featureFlagsResponse{
+		Flags:   api.featureFlagStatuses(),
+		Changes: changes,
+	})
+}
+
+// setFeatureFlag changes a feature flag after saving the change to the audit log, and publishes an event
+func (api *RelayAPI) setFeatureFlag(w http.ResponseWriter, req *http.Request, name string) bool {
+	args := req.URL.Query()
+	log := api.log.WithFields(logrus.Fields{
+		"method":      "internalFeatureFlags",
+		"featureFlag": name,
+		"enabled":     args.Get("enabled"),
+		"actor":       args.Get("actor"),
+		"reason":      args.Get("reason"),
+		"ip":          common.GetIPXForwardedFor(req),
+	})
+
+	if featureFlagsAuthToken == "" {
+		api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeForbidden, "runtime feature flag changes are disabled")
+		return false
+	}
+	if !isFeatureFlagsRequestAuthorized(req) {
+		log.Warn("unauthorized feature flag change")
+		api.RespondErrorCode(w, http.StatusUnauthorized, ErrorCodeUnauthorized, "invalid or missing bearer token")
+		return false
+	}
+
+	var flag *runtimeFeatureFlag
+	flags := api.runtimeFeatureFlags()
+	for i := range flags {
+		if flags[i].name == name {
+			flag = &flags[i]
+			break
+		}
+	}
+	if flag == nil {
+		api.RespondErrorCode(w, http.StatusNotFound, ErrorCodeNotFound, "unknown feature flag")
+		return false
+	}
+	enabled := args.Get("enabled") == "true"
+	if !enabled && args.Get("enabled") != "false" {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "enabled must be true or false")
+		return false
+	}
+	if args.Get("actor") == "" {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "actor is required")
+		return false
+	}
+
+	api.featureFlagsLock.Lock()
+	defer api.featureFlagsLock.Unlock()
+
+	// The change is only applied once it's in the audit log
+	prevEnabled := flag.value.Load()
+	err := api.db.InsertFeatureFlagChange(&database.FeatureFlagChangeEntry{
+		InstanceID:  common.InstanceID,
+		Name:        name,
+		Enabled:     enabled,
+		PrevEnabled: prevEnabled,
+		Actor:       args.Get("actor"),
+		Reason:      args.Get("reason"),
+		IP:          common.GetIPXForwardedFor(req),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to save feature flag change")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternalError, "failed to save feature flag change")
+		return false
+	}
+	flag.value.Store(enabled)
+
+	log.WithField("prevEnabled", prevEnabled).Warn("feature flag changed")
+	api.publishEvent(&events.Event{
+		Type:        events.TypeFeatureFlagChanged,
+		FeatureFlag: name,
+		Enabled:     &enabled,
+		Actor:       args.Get("actor"),
+		Reason:      args.Get("reason"),
+	})
+	return true
+}
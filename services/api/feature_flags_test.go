@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+type featureFlagsDB struct {
+	database.MockDB
+	changes []*database.FeatureFlagChangeEntry
+}
+
+func (db *featureFlagsDB) InsertFeatureFlagChange(entry *database.FeatureFlagChangeEntry) error {
+	db.changes = append([]*database.FeatureFlagChangeEntry{entry}, db.changes...)
+	return nil
+}
+
+func (db *featureFlagsDB) GetFeatureFlagChanges(limit uint64) ([]*database.FeatureFlagChangeEntry, error) {
+	return db.changes, nil
+}
+
+func TestInternalFeatureFlags(t *testing.T) {
+	defer func(token string) { featureFlagsAuthToken = token }(featureFlagsAuthToken)
+	featureFlagsAuthToken = ""
+
+	db := &featureFlagsDB{}
+	backend := newTestBackend(t, 1)
+	backend.relay.db = db
+	path := "/internal/v1/feature_flags/ENABLE_BUILDER_CANCELLATIONS?enabled=true&actor=alice&reason=test"
+	auth := map[string]string{"Authorization": "Bearer secret"}
+
+	// Changes are disabled without a token, and need the token otherwise
+	rr := backend.requestBytes(http.MethodPost, path, nil, auth)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	featureFlagsAuthToken = "secret"
+	rr = backend.requestBytes(http.MethodPost, path, nil, map[string]string{"Authorization": "Bearer wrong"})
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeUnauthorized))
+
+	rr = backend.requestBytes(http.MethodPost, "/internal/v1/feature_flags/UNKNOWN?enabled=true&actor=alice", nil, auth)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	rr = backend.requestBytes(http.MethodPost, "/internal/v1/feature_flags/ENABLE_BUILDER_CANCELLATIONS?enabled=yes&actor=alice", nil, auth)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = backend.requestBytes(http.MethodPost, "/internal/v1/feature_flags/ENABLE_BUILDER_CANCELLATIONS?enabled=true", nil, auth)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.False(t, backend.relay.ffEnableCancellations.Load())
+	require.Empty(t, db.changes)
+
+	// The change is applied and audited
+	rr = backend.requestBytes(http.MethodPost, path, nil, auth)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.True(t, backend.relay.ffEnableCancellations.Load())
+	require.Len(t, db.changes, 1)
+	require.Equal(t, "ENABLE_BUILDER_CANCELLATIONS", db.changes[0].Name)
+	require.True(t, db.changes[0].Enabled)
+	require.False(t, db.changes[0].PrevEnabled)
+	require.Equal(t, "alice", db.changes[0].Actor)
+	require.Equal(t, "test", db.changes[0].Reason)
+
+	rr = backend.request(http.MethodGet, pathInternalFeatureFlags, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp featureFlagsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Flags, len(backend.relay.runtimeFeatureFlags()))
+	for _, flag := range resp.Flags {
+		require.Equal(t, flag.Name == "ENABLE_BUILDER_CANCELLATIONS", flag.Enabled, flag.Name)
+	}
+	require.Len(t, resp.Changes, 1)
+}
+
+func TestRuntimeFeatureFlagsInHotPaths(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	backend.relay.blockBuildersCache[pubkey.String()].status.IsOptimistic = true
+
+	// Optimistic builders are processed without collateral while optimistic submissions are disabled
+	backend.relay.ffDisableOptimisticSubmissions.Store(true)
+	builderEntry, ok := backend.relay.checkBuilderEntry(httptest.NewRecorder(), common.TestLog, *pubkey)
+	require.True(t, ok)
+	require.False(t, builderEntry.status.IsOptimistic)
+	require.True(t, backend.relay.blockBuildersCache[pubkey.String()].status.IsOptimistic)
+
+	backend.relay.ffDisableOptimisticSubmissions.Store(false)
+	builderEntry, ok = backend.relay.checkBuilderEntry(httptest.NewRecorder(), common.TestLog, *pubkey)
+	require.True(t, ok)
+	require.True(t, builderEntry.status.IsOptimistic)
+
+	// Deneb submissions are rejected while disabled
+	payload := common.TestBuilderSubmitBlockRequest(secretkey, getTestBidTrace(*pubkey, 100, slot), spec.DataVersionDeneb)
+	submission, err := common.GetBlockSubmissionInfo(payload)
+	require.NoError(t, err)
+	backend.relay.ffDisableDenebSubmissions.Store(true)
+	w := httptest.NewRecorder()
+	require.False(t, backend.relay.checkSubmissionSlotDetails(w, common.TestLog, slot-1, payload, submission))
+	require.Contains(t, w.Body.String(), "deneb submissions are disabled")
+}
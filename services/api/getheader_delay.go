@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// getHeaderDelay returns how long to hold a getHeader response: until the response target time into the slot plus a
+// random jitter, so that all proposers get the best bid at about the same time regardless of when they ask for it. The
+// delay is at most the target plus jitter, for requests sent before the slot started.
+func getHeaderDelay(tunables *Tunables, slotStartTimestamp uint64, now time.Time) time.Duration {
+	targetMs := int64(tunables.GetHeaderResponseTargetMs)
+	if tunables.GetHeaderResponseJitterMs > 0 {
+		targetMs += rand.Int63n(int64(tunables.GetHeaderResponseJitterMs) + 1) //nolint:gosec
+	}
+	target := time.UnixMilli(int64(slotStartTimestamp*1000) + targetMs)
+	delay := target.Sub(now)
+	if delay <= 0 {
+		return 0
+	}
+	return min(delay, time.Duration(targetMs)*time.Millisecond)
+}
+
+// waitForGetHeaderTarget holds a getHeader request for the delay. Returns false if the request was cancelled meanwhile.
+func waitForGetHeaderTarget(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recordGetHeaderCall stores when a delayed getHeader call was requested and served
+func (api *RelayAPI) recordGetHeaderCall(log *logrus.Entry, slot uint64, call *datastore.GetHeaderCall) {
+	call.ServedAtMs = time.Now().UTC().UnixMilli()
+	go func() {
+		if err := api.redis.AddGetHeaderCall(slot, *call); err != nil {
+			log.WithError(err).Error("failed to record getHeader call")
+		}
+	}()
+}
+
+// handleInternalGetHeaderCalls returns the delayed getHeader calls of the slot given in the query
+func (api *RelayAPI) handleInternalGetHeaderCalls(w http.ResponseWriter, req *http.Request) {
+	slot, err := strconv.ParseUint(req.URL.Query().Get("slot"), 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot")
+		return
+	}
+	calls, err := api.redis.GetGetHeaderCalls(slot)
+	if err != nil {
+		api.log.WithError(err).Error("failed to get getHeader calls")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.RespondOK(w, calls)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHeaderDelay(t *testing.T) {
+	slotStart := uint64(1_700_000_000)
+	slotStartTime := time.Unix(int64(slotStart), 0)
+	tunables := &Tunables{GetHeaderResponseTargetMs: 1000}
+
+	// held until the target time into the slot
+	require.Equal(t, 800*time.Millisecond, getHeaderDelay(tunables, slotStart, slotStartTime.Add(200*time.Millisecond)))
+
+	// not held after the target time
+	require.Equal(t, time.Duration(0), getHeaderDelay(tunables, slotStart, slotStartTime.Add(1200*time.Millisecond)))
+
+	// requests sent before the slot started are held at most for the target
+	require.Equal(t, time.Second, getHeaderDelay(tunables, slotStart, slotStartTime.Add(-5*time.Second)))
+
+	// jitter is added to the target
+	tunables.GetHeaderResponseJitterMs = 100
+	for i := 0; i < 10; i++ {
+		delay := getHeaderDelay(tunables, slotStart, slotStartTime)
+		require.GreaterOrEqual(t, delay, time.Second)
+		require.LessOrEqual(t, delay, 1100*time.Millisecond)
+	}
+}
+
+func TestWaitForGetHeaderTarget(t *testing.T) {
+	require.True(t, waitForGetHeaderTarget(context.Background(), 0))
+	require.True(t, waitForGetHeaderTarget(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.False(t, waitForGetHeaderTarget(ctx, time.Minute))
+}
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// getPayloadRequest is a getPayload request of a proposer client, with the block it's for and the expected response
+type getPayloadRequest struct {
+	Client     string            `json:"client"`
+	Headers    map[string]string `json:"headers"`
+	Block      string            `json:"block"`
+	StatusCode int               `json:"status_code"`
+	Response   string            `json:"response"`
+}
+
+// getPayloadTestBlock is a signed blinded block of Goerli, and the payload the relay has for it
+type getPayloadTestBlock struct {
+	proposerIndex  uint64
+	proposerPubkey string
+	body           []byte                                          // the getPayload request body
+	payload        *builderApi.VersionedSubmitBlindedBlockResponse // saved in Redis
+	responses      map[string]string                               // expected response per version
+}
+
+// capellaTestBlock is the blinded block sent to the relay for Goerli slot 5610357, with the valid signature of the
+// proposer, and the execution payload of the block
+func capellaTestBlock(t *testing.T) *getPayloadTestBlock {
+	t.Helper()
+	body := common.LoadGzippedBytes(t, "../../testdata/signedBlindedBeaconBlockCapella_Goerli.json.gz")
+	block := struct {
+		Message struct {
+			Body struct {
+				ExecutionPayload json.RawMessage `json:"execution_payload"`
+			} `json:"body"`
+		} `json:"message"`
+	}{}
+	common.LoadGzippedJSON(t, "../../testdata/signedBeaconBlockCapella_Goerli.json.gz", &block)
+	payload := new(capella.ExecutionPayload)
+	require.NoError(t, json.Unmarshal(block.Message.Body.ExecutionPayload, payload))
+
+	response := `{"version":"capella","data":` + string(block.Message.Body.ExecutionPayload) + `}`
+	return &getPayloadTestBlock{
+		proposerIndex:  400031,
+		proposerPubkey: "0xa8afcb5313602f936864b30600f568e04069e596ceed9b55e2a1c872c959ddcb90589636469c15d97e7565344d9ed4ad",
+		body:           body,
+		payload:        &builderApi.VersionedSubmitBlindedBlockResponse{Version: spec.DataVersionCapella, Capella: payload},
+		responses:      map[string]string{"v1": response, "v2": response},
+	}
+}
+
+// denebTestBlock is the blinded block of Goerli slot 7470051 (a teku block with five blobs), and its execution
+// payload and blobs bundle. The pubkey of the proposer isn't known, so the blinded block is signed with a test key.
+func denebTestBlock(t *testing.T, network *common.EthNetworkDetails) *getPayloadTestBlock {
+	t.Helper()
+	contentsJSON := common.LoadGzippedBytes(t, "../../testdata/signedBeaconBlockContentsDeneb_Goerli.json.gz")
+	contents := new(eth2ApiV1Deneb.SignedBlockContents)
+	require.NoError(t, json.Unmarshal(contentsJSON, contents))
+	raw := struct {
+		SignedBlock struct {
+			Message struct {
+				Body struct {
+					ExecutionPayload   json.RawMessage `json:"execution_payload"`
+					BlobKZGCommitments json.RawMessage `json:"blob_kzg_commitments"`
+				} `json:"body"`
+			} `json:"message"`
+		} `json:"signed_block"`
+		KZGProofs json.RawMessage `json:"kzg_proofs"`
+		Blobs     json.RawMessage `json:"blobs"`
+	}{}
+	require.NoError(t, json.Unmarshal(contentsJSON, &raw))
+
+	block := contents.SignedBlock.Message
+	header, err := utils.PayloadToPayloadHeader(&builderApi.VersionedExecutionPayload{Version: spec.DataVersionDeneb, Deneb: block.Body.ExecutionPayload})
+	require.NoError(t, err)
+	blindedBlock := &eth2ApiV1Deneb.BlindedBeaconBlock{
+		Slot:          block.Slot,
+		ProposerIndex: block.ProposerIndex,
+		ParentRoot:    block.ParentRoot,
+		StateRoot:     block.StateRoot,
+		Body: &eth2ApiV1Deneb.BlindedBeaconBlockBody{
+			RANDAOReveal:           block.Body.RANDAOReveal,
+			ETH1Data:               block.Body.ETH1Data,
+			Graffiti:               block.Body.Graffiti,
+			ProposerSlashings:      block.Body.ProposerSlashings,
+			AttesterSlashings:      block.Body.AttesterSlashings,
+			Attestations:           block.Body.Attestations,
+			Deposits:               block.Body.Deposits,
+			VoluntaryExits:         block.Body.VoluntaryExits,
+			SyncAggregate:          block.Body.SyncAggregate,
+			ExecutionPayloadHeader: header.Deneb,
+			BLSToExecutionChanges:  block.Body.BLSToExecutionChanges,
+			BlobKZGCommitments:     block.Body.BlobKZGCommitments,
+		},
+	}
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := utils.BlsPublicKeyToPublicKey(pk)
+	require.NoError(t, err)
+	sig, err := ssz.SignMessage(blindedBlock, network.DomainBeaconProposerDeneb, sk)
+	require.NoError(t, err)
+	body, err := json.Marshal(&eth2ApiV1Deneb.SignedBlindedBeaconBlock{Message: blindedBlock, Signature: sig})
+	require.NoError(t, err)
+
+	return &getPayloadTestBlock{
+		proposerIndex:  uint64(block.ProposerIndex),
+		proposerPubkey: pubkey.String(),
+		body:           body,
+		payload: &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionDeneb,
+			Deneb: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+				ExecutionPayload: block.Body.ExecutionPayload,
+				BlobsBundle: &builderApiDeneb.BlobsBundle{
+					Commitments: block.Body.BlobKZGCommitments,
+					Proofs:      contents.KZGProofs,
+					Blobs:       contents.Blobs,
+				},
+			},
+		},
+		responses: map[string]string{
+			"v1": `{"version":"deneb","data":` + string(raw.SignedBlock.Message.Body.ExecutionPayload) + `}`,
+			"v2": `{"version":"deneb","data":{"execution_payload":` + string(raw.SignedBlock.Message.Body.ExecutionPayload) +
+				`,"blobs_bundle":{"commitments":` + string(raw.SignedBlock.Message.Body.BlobKZGCommitments) +
+				`,"proofs":` + string(raw.KZGProofs) + `,"blobs":` + string(raw.Blobs) + `}}}`,
+		},
+	}
+}
+
+// electraNetwork returns Goerli with an electra fork at the slot of the deneb test block, as there is no electra
+// block of a public network in the testdata yet
+func electraNetwork(t *testing.T) *common.EthNetworkDetails {
+	t.Helper()
+	network, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
+	require.NoError(t, err)
+	network.ElectraForkVersionHex = "0x05001020"
+	network.ForkSchedule.ElectraEpoch = int64(common.SlotToEpoch(7470051))
+	network.DomainBeaconProposerElectra, err = common.ComputeDomain(ssz.DomainTypeBeaconProposer, network.ElectraForkVersionHex, network.GenesisValidatorsRootHex)
+	require.NoError(t, err)
+	return network
+}
+
+// electraTestBlock is the deneb test block as an electra blinded block with the given execution requests, signed with
+// a test key
+func electraTestBlock(t *testing.T, network *common.EthNetworkDetails, executionRequests *electra.ExecutionRequests) *getPayloadTestBlock {
+	t.Helper()
+	denebBlock := denebTestBlock(t, network)
+	signedBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, json.Unmarshal(denebBlock.body, signedBlock))
+	blindedBlock := common.ElectraBlindedBeaconBlockFromDeneb(signedBlock.Message, executionRequests)
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := utils.BlsPublicKeyToPublicKey(pk)
+	require.NoError(t, err)
+	sig, err := ssz.SignMessage(blindedBlock, network.DomainBeaconProposerElectra, sk)
+	require.NoError(t, err)
+	body, err := json.Marshal(&eth2ApiV1Electra.SignedBlindedBeaconBlock{Message: blindedBlock, Signature: sig})
+	require.NoError(t, err)
+
+	// From electra, both response versions have the blobs bundle
+	response := strings.Replace(denebBlock.responses["v2"], `"version":"deneb"`, `"version":"electra"`, 1)
+	return &getPayloadTestBlock{
+		proposerIndex:  uint64(blindedBlock.ProposerIndex),
+		proposerPubkey: pubkey.String(),
+		body:           body,
+		payload: &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionElectra,
+			Electra: denebBlock.payload.Deneb,
+		},
+		responses: map[string]string{"v1": response, "v2": response},
+	}
+}
+
+// newGetPayloadTestBackend returns a relay for Goerli which has the payload of the block, and publishes blocks
+func newGetPayloadTestBackend(t *testing.T, network *common.EthNetworkDetails, block *getPayloadTestBlock) *testBackend {
+	t.Helper()
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.EthNetDetails = *network
+	backend.relay.forkSchedule = network.ForkSchedule
+	tunables := DefaultTunables()
+	tunables.GetPayloadRequestCutoffMs = 0
+	tunables.GetPayloadResponseDelayMs = 0
+	backend.relay.tunables.Store(&tunables)
+
+	beaconInstance := beaconclient.NewMockBeaconInstance()
+	beaconInstance.MockPublishBlock = func() (int, error) { return http.StatusOK, nil }
+	backend.relay.beaconClient = beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconInstance})
+	backend.datastore.AddKnownValidator(common.PubkeyHex(block.proposerPubkey), block.proposerIndex)
+
+	blockHash, err := block.payload.BlockHash()
+	require.NoError(t, err)
+	blindedBlock := new(common.VersionedSignedBlindedBeaconBlock)
+	require.NoError(t, json.Unmarshal(block.body, blindedBlock))
+	blockSlot, err := blindedBlock.Slot()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	pipe := backend.redis.NewPipeline()
+	switch {
+	case block.payload.Electra != nil:
+		err = backend.redis.SavePayloadContentsElectra(ctx, pipe, uint64(blockSlot), block.proposerPubkey, blockHash.String(), block.payload.Electra)
+	case block.payload.Deneb != nil:
+		err = backend.redis.SavePayloadContentsDeneb(ctx, pipe, uint64(blockSlot), block.proposerPubkey, blockHash.String(), block.payload.Deneb)
+	default:
+		err = backend.redis.SaveExecutionPayloadCapella(ctx, pipe, uint64(blockSlot), block.proposerPubkey, blockHash.String(), block.payload.Capella)
+	}
+	require.NoError(t, err)
+	_, err = pipe.Exec(ctx)
+	require.NoError(t, err)
+	return backend
+}
+
+// TestGetPayloadClientRequests replays the getPayload requests of proposer clients, which differ in the headers, and
+// checks the exact response body
+func TestGetPayloadClientRequests(t *testing.T) {
+	requests := []*getPayloadRequest{}
+	requestsJSON, err := os.ReadFile("../../testdata/getPayloadRequests.json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(requestsJSON, &requests))
+
+	goerli, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
+	require.NoError(t, err)
+	blocks := map[string]*getPayloadTestBlock{
+		"capella": capellaTestBlock(t),
+		"deneb":   denebTestBlock(t, goerli),
+	}
+
+	for _, request := range requests {
+		t.Run(request.Client, func(t *testing.T) {
+			block := blocks[request.Block]
+			require.NotNil(t, block, request.Block)
+			backend := newGetPayloadTestBackend(t, goerli, block)
+
+			rr := backend.requestBytes(http.MethodPost, pathGetPayload, block.body, request.Headers)
+			require.Equal(t, request.StatusCode, rr.Code, rr.Body.String())
+			if rr.Code != http.StatusOK {
+				return
+			}
+			require.Equal(t, strings.ToLower(block.payload.Version.String()), rr.Header().Get(HeaderEthConsensusVersion))
+			require.JSONEq(t, block.responses[request.Response], rr.Body.String())
+		})
+	}
+}
+
+// TestGetPayloadDefaultResponseV1 checks that clients without the Eth-Consensus-Version header get only the
+// execution payload of a deneb block if that's the configured default
+func TestGetPayloadDefaultResponseV1(t *testing.T) {
+	defer func(version getPayloadResponseVersion) { getPayloadDefaultResponseVersion = version }(getPayloadDefaultResponseVersion)
+	getPayloadDefaultResponseVersion = getPayloadResponseV1
+
+	goerli, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
+	require.NoError(t, err)
+	block := denebTestBlock(t, goerli)
+	backend := newGetPayloadTestBackend(t, goerli, block)
+
+	rr := backend.requestBytes(http.MethodPost, pathGetPayload, block.body, map[string]string{"Content-Type": "application/json"})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, block.responses["v1"], rr.Body.String())
+
+	// Clients with the header still get the blobs bundle
+	backend = newGetPayloadTestBackend(t, goerli, block)
+	rr = backend.requestBytes(http.MethodPost, pathGetPayload, block.body, map[string]string{HeaderEthConsensusVersion: "deneb"})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, block.responses["v2"], rr.Body.String())
+}
+
+func TestInvalidGetPayloadDefaultResponseVersion(t *testing.T) {
+	opts := newTestBackend(t, 1).relay.opts
+	defer func(version getPayloadResponseVersion) { getPayloadDefaultResponseVersion = version }(getPayloadDefaultResponseVersion)
+	getPayloadDefaultResponseVersion = "v3"
+
+	_, err := NewRelayAPI(opts)
+	require.ErrorIs(t, err, ErrInvalidGetPayloadResponseVersion)
+}
+
+// TestGetPayloadElectra checks that the execution requests of an electra block must be the ones of the payload
+func TestGetPayloadElectra(t *testing.T) {
+	network := electraNetwork(t)
+	executionRequests := common.TestExecutionRequests()
+	htr, err := executionRequests.HashTreeRoot()
+	require.NoError(t, err)
+	executionRequestsRoot := phase0.Root(htr)
+
+	saveBidTrace := func(backend *testBackend, block *getPayloadTestBlock, root *phase0.Root) {
+		blockHash, err := block.payload.BlockHash()
+		require.NoError(t, err)
+		pubkey, err := utils.HexToPubkey(block.proposerPubkey)
+		require.NoError(t, err)
+		trace := &common.BidTraceV2WithBlobFields{ExecutionRequestsRoot: root}
+		trace.Slot = 7470051
+		trace.BlockHash = blockHash
+		trace.ProposerPubkey = pubkey
+		trace.Value = uint256.NewInt(1)
+		pipe := backend.redis.NewPipeline()
+		require.NoError(t, backend.redis.SaveBidTrace(context.Background(), pipe, trace))
+		_, err = pipe.Exec(context.Background())
+		require.NoError(t, err)
+	}
+	numUnchecked := func() int64 {
+		if v, ok := getPayloadExpvar.Get("execution_requests_unchecked").(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+
+	for _, version := range []string{"v1", "v2"} {
+		t.Run("matching execution requests "+version, func(t *testing.T) {
+			defer func(version getPayloadResponseVersion) { getPayloadDefaultResponseVersion = version }(getPayloadDefaultResponseVersion)
+			getPayloadDefaultResponseVersion = getPayloadResponseVersion(version)
+
+			block := electraTestBlock(t, network, executionRequests)
+			backend := newGetPayloadTestBackend(t, network, block)
+			saveBidTrace(backend, block, &executionRequestsRoot)
+			before := numUnchecked()
+
+			rr := backend.requestBytes(http.MethodPost, pathGetPayload, block.body, map[string]string{"Content-Type": "application/json"})
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			require.Equal(t, "electra", rr.Header().Get(HeaderEthConsensusVersion))
+			require.JSONEq(t, block.responses[version], rr.Body.String())
+			require.Equal(t, before, numUnchecked())
+		})
+	}
+
+	t.Run("other execution requests", func(t *testing.T) {
+		otherRequests := common.TestExecutionRequests()
+		otherRequests.Consolidations = nil
+		block := electraTestBlock(t, network, otherRequests)
+		backend := newGetPayloadTestBackend(t, network, block)
+		saveBidTrace(backend, block, &executionRequestsRoot)
+
+		rr := backend.requestBytes(http.MethodPost, pathGetPayload, block.body, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+		require.Contains(t, rr.Body.String(), ErrorCodeExecutionRequestsMismatch)
+	})
+
+	t.Run("unknown execution requests", func(t *testing.T) {
+		block := electraTestBlock(t, network, executionRequests)
+		backend := newGetPayloadTestBackend(t, network, block)
+		before := numUnchecked()
+
+		rr := backend.requestBytes(http.MethodPost, pathGetPayload, block.body, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, before+1, numUnchecked())
+	})
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+// HeaderEthConsensusVersion is the fork of the request and response body of the getPayload request
+const HeaderEthConsensusVersion = "Eth-Consensus-Version"
+
+// getPayloadResponseVersion is the shape of the getPayload response. Before deneb, both are the execution payload, and
+// from electra, both are the execution payload and the blobs bundle, as there are no proposer clients for electra
+// which expect the execution payload only.
+type getPayloadResponseVersion string
+
+const (
+	getPayloadResponseV1 getPayloadResponseVersion = "v1" // the execution payload only
+	getPayloadResponseV2 getPayloadResponseVersion = "v2" // the execution payload and the blobs bundle (from deneb)
+)
+
+var (
+	ErrConsensusVersionMismatch         = errors.New("consensus version does not match the block")
+	ErrInvalidGetPayloadResponseVersion = errors.New("invalid GETPAYLOAD_DEFAULT_RESPONSE_VERSION, must be v1 or v2")
+)
+
+// shape of the getPayload response for proposer clients which don't send the Eth-Consensus-Version header, i.e. which
+// don't negotiate the response for the fork
+var getPayloadDefaultResponseVersion = getPayloadResponseVersion(common.GetEnv("GETPAYLOAD_DEFAULT_RESPONSE_VERSION", string(getPayloadResponseV2)))
+
+// isValid returns whether the version is one of the known response shapes
+func (v getPayloadResponseVersion) isValid() bool {
+	return v == getPayloadResponseV1 || v == getPayloadResponseV2
+}
+
+// denebPayloadOnlyResponse is the v1 getPayload response of a deneb block, without the blobs bundle
+type denebPayloadOnlyResponse struct {
+	Version spec.DataVersion        `json:"version"`
+	Data    *deneb.ExecutionPayload `json:"data"`
+}
+
+// negotiateGetPayloadResponse returns the response version for the Eth-Consensus-Version header of a getPayload
+// request, which must match the fork of the signed blinded block if it's set
+func negotiateGetPayloadResponse(header string, blockVersion spec.DataVersion) (getPayloadResponseVersion, error) {
+	if header == "" {
+		return getPayloadDefaultResponseVersion, nil
+	}
+	if !strings.EqualFold(header, blockVersion.String()) {
+		return "", fmt.Errorf("%w: %s header %s, %s block", ErrConsensusVersionMismatch, HeaderEthConsensusVersion, header, blockVersion.String())
+	}
+	return getPayloadResponseV2, nil
+}
+
+// getPayloadResponseBody returns the getPayload response in the requested shape
+func getPayloadResponseBody(resp *builderApi.VersionedSubmitBlindedBlockResponse, version getPayloadResponseVersion) any {
+	if version == getPayloadResponseV1 && resp.Version == spec.DataVersionDeneb && resp.Deneb != nil {
+		return &denebPayloadOnlyResponse{Version: resp.Version, Data: resp.Deneb.ExecutionPayload}
+	}
+	return resp
+}
+
+// respondGetPayload writes the getPayload response in the negotiated shape, with the fork in the
+// Eth-Consensus-Version header
+func (api *RelayAPI) respondGetPayload(w http.ResponseWriter, resp *builderApi.VersionedSubmitBlindedBlockResponse, version getPayloadResponseVersion) {
+	w.Header().Set(HeaderEthConsensusVersion, strings.ToLower(resp.Version.String()))
+	api.RespondOK(w, getPayloadResponseBody(resp, version))
+}
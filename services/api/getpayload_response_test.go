@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateGetPayloadResponse(t *testing.T) {
+	defer func(version getPayloadResponseVersion) { getPayloadDefaultResponseVersion = version }(getPayloadDefaultResponseVersion)
+	getPayloadDefaultResponseVersion = getPayloadResponseV1
+
+	// Clients without the header get the configured default
+	version, err := negotiateGetPayloadResponse("", spec.DataVersionDeneb)
+	require.NoError(t, err)
+	require.Equal(t, getPayloadResponseV1, version)
+
+	// Clients with the header get the full object of the fork, which must match the block
+	version, err = negotiateGetPayloadResponse("Deneb", spec.DataVersionDeneb)
+	require.NoError(t, err)
+	require.Equal(t, getPayloadResponseV2, version)
+	_, err = negotiateGetPayloadResponse("capella", spec.DataVersionDeneb)
+	require.ErrorIs(t, err, ErrConsensusVersionMismatch)
+	_, err = negotiateGetPayloadResponse("electra", spec.DataVersionDeneb)
+	require.ErrorIs(t, err, ErrConsensusVersionMismatch)
+}
+
+func TestGetPayloadResponseBody(t *testing.T) {
+	denebResp := &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionDeneb,
+		Deneb: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: &deneb.ExecutionPayload{
+				BaseFeePerGas: uint256.NewInt(7),
+				ExtraData:     []byte{},
+				Transactions:  []bellatrix.Transaction{},
+				Withdrawals:   []*capella.Withdrawal{},
+			},
+			BlobsBundle: &builderApiDeneb.BlobsBundle{
+				Commitments: []deneb.KZGCommitment{},
+				Proofs:      []deneb.KZGProof{},
+				Blobs:       []deneb.Blob{},
+			},
+		},
+	}
+	decode := func(version getPayloadResponseVersion) map[string]json.RawMessage {
+		b, err := json.Marshal(getPayloadResponseBody(denebResp, version))
+		require.NoError(t, err)
+		resp := struct {
+			Version string                     `json:"version"`
+			Data    map[string]json.RawMessage `json:"data"`
+		}{}
+		require.NoError(t, json.Unmarshal(b, &resp))
+		require.Equal(t, "deneb", resp.Version)
+		return resp.Data
+	}
+
+	// v2 is the execution payload and the blobs bundle
+	data := decode(getPayloadResponseV2)
+	require.Contains(t, data, "execution_payload")
+	require.Contains(t, data, "blobs_bundle")
+
+	// v1 is only the execution payload
+	data = decode(getPayloadResponseV1)
+	require.NotContains(t, data, "blobs_bundle")
+	require.Contains(t, data, "block_hash")
+
+	// Capella responses are the execution payload in both versions
+	capellaResp := &builderApi.VersionedSubmitBlindedBlockResponse{Version: spec.DataVersionCapella, Capella: &capella.ExecutionPayload{}}
+	require.Equal(t, capellaResp, getPayloadResponseBody(capellaResp, getPayloadResponseV1))
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/decoder"
+	"github.com/sirupsen/logrus"
+)
+
+// GRPCListenerOpts contains the options for the optional gRPC listener for block submissions (see proto/relay/v1)
+type GRPCListenerOpts struct {
+	ListenAddr string
+
+	// TLS server certificate. Without it, the listener is plaintext.
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// bufferedResponseWriter keeps the response of a handler, for transports other than HTTP
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header), statusCode: http.StatusOK}
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// submitBlockSSZ runs an SSZ encoded block submission through the same checks, simulation and bid update as the REST
+// API, and returns the recorded response
+func (api *RelayAPI) submitBlockSSZ(ctx context.Context, remoteAddr string, sszPayload []byte, cancellations bool) *bufferedResponseWriter {
+	headSlot := api.headSlot.Load()
+	receivedAt := time.Now().UTC()
+	requestID := newRequestID()
+
+	log := api.log.WithFields(logrus.Fields{
+		"method":                "submitNewBlock",
+		"transport":             "grpc",
+		"requestID":             requestID,
+		"remoteAddr":            remoteAddr,
+		"contentLength":         len(sszPayload),
+		"headSlot":              headSlot,
+		"cancellationEnabled":   cancellations,
+		"timestampRequestStart": receivedAt.UnixMilli(),
+	})
+
+	// Log at start and end of request
+	log.Info("request initiated")
+	defer func() {
+		log.WithFields(logrus.Fields{
+			"timestampRequestFin": time.Now().UTC().UnixMilli(),
+			"requestDurationMs":   time.Since(receivedAt).Milliseconds(),
+		}).Info("request finished")
+	}()
+
+	w := newBufferedResponseWriter()
+	w.Header().Set(HeaderRequestID, requestID)
+	respW := &submissionResponseWriter{ResponseWriter: w, statusCode: http.StatusOK, timings: submissionTimings{receivedAt: receivedAt}}
+	if !api.checkSubmissionAccepted(respW, log, cancellations) {
+		return w
+	}
+
+	payload, err := decoder.DecodeSubmitBlockRequestSSZ(sszPayload)
+	log = log.WithField("reqContentType", decoder.EncodingSSZ)
+	if err != nil {
+		log.WithError(err).Warn("could not decode payload")
+		api.RespondErrorCode(respW, http.StatusBadRequest, ErrorCodeDecodeFailed, err.Error())
+		return w
+	}
+	decodedAt := time.Now().UTC()
+	respW.timings.decode = decodedAt.Sub(receivedAt)
+
+	api.processBlockSubmission(respW, log, &blockSubmission{
+		ctx:                  ctx,
+		requestID:            requestID,
+		payload:              payload,
+		payloadBytes:         len(sszPayload),
+		cancellationsEnabled: cancellations,
+		headSlot:             headSlot,
+		receivedAt:           receivedAt,
+		decodedAt:            decodedAt,
+		profile:              common.Profile{Decode: uint64(decodedAt.Sub(receivedAt).Microseconds())},
+	})
+	return w
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	relayv1 "github.com/flashbots/mev-boost-relay/proto/relay/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestSubmitBlockSSZ(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	// Cancellations are disabled
+	w := backend.relay.submitBlockSSZ(context.Background(), "1.2.3.4:5678", []byte{1, 2, 3}, true)
+	require.Equal(t, http.StatusBadRequest, w.statusCode)
+	require.NotEmpty(t, w.header.Get(HeaderRequestID))
+
+	resp := new(HTTPErrorResp)
+	require.NoError(t, json.Unmarshal(w.body.Bytes(), resp))
+	require.Equal(t, ErrorCodeCancellationsDisabled, resp.ErrorCode)
+
+	// Invalid SSZ payload
+	w = backend.relay.submitBlockSSZ(context.Background(), "1.2.3.4:5678", []byte{1, 2, 3}, false)
+	require.Equal(t, http.StatusBadRequest, w.statusCode)
+
+	resp = new(HTTPErrorResp)
+	require.NoError(t, json.Unmarshal(w.body.Bytes(), resp))
+	require.Equal(t, ErrorCodeDecodeFailed, resp.ErrorCode)
+}
+
+func TestGRPCBuilderService(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.GRPCListener = &GRPCListenerOpts{}
+
+	// Serve the builder service on an in-memory connection
+	srv, err := backend.relay.newGRPCServer()
+	require.NoError(t, err)
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := relayv1.NewBuilderServiceClient(conn)
+
+	// Prepare the relay for the Capella test payload
+	headSlot := uint64(32)
+	submissionSlot := headSlot + 1
+	parentHash := "0xbd3291854dc822b7ec585925cda0e18f06af28fa2886e15f52d52dd4b6f94ed6"
+	feeRec, err := utils.HexToAddress("0x5cc0dde14e7256340cc820415a6022a7d1c93a35")
+	require.NoError(t, err)
+	withdrawalsRoot, err := utils.HexToHash("0xb15ed76298ff84a586b1d875df08b6676c98dfe9c7cd73fab88450348d8e70c8")
+	require.NoError(t, err)
+
+	backend.relay.headSlot.Store(headSlot)
+	backend.relay.forkSchedule.CapellaEpoch = 0
+	backend.relay.forkSchedule.DenebEpoch = 2
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		submissionSlot: {
+			Slot:  headSlot,
+			Entry: &builderApiV1.SignedValidatorRegistration{Message: &builderApiV1.ValidatorRegistration{FeeRecipient: feeRec}},
+		},
+	}
+	backend.relay.payloadAttributes = map[string]payloadAttributesHelper{
+		getPayloadAttributesKey(parentHash, submissionSlot): {
+			slot:              submissionSlot,
+			parentHash:        parentHash,
+			payloadAttributes: beaconclient.PayloadAttributes{PrevRandao: "0x9962816e9d0a39fd4c80935338a741dc916d1545694e41eb5a505e1a3098f9e4"},
+			withdrawalsRoot:   phase0.Root(withdrawalsRoot),
+		},
+	}
+
+	payload := new(common.VersionedSubmitBlockRequest)
+	require.NoError(t, json.Unmarshal(common.LoadGzippedBytes(t, "../../testdata/submitBlockPayloadCapella_Goerli.json.gz"), payload))
+	payload.Capella.Message.Slot = submissionSlot
+	payload.Capella.ExecutionPayload.Timestamp = 1606824419
+	sszPayload, err := payload.MarshalSSZ()
+	require.NoError(t, err)
+
+	// The submission goes through the same checks as on the REST API, up to the signature
+	resp, err := client.SubmitBlock(context.Background(), &relayv1.SubmitBlockRequest{SszPayload: sszPayload})
+	require.NoError(t, err)
+	require.Equal(t, int32(http.StatusBadRequest), resp.GetStatusCode())
+	require.Equal(t, string(ErrorCodeInvalidSignature), resp.GetErrorCode())
+	require.Equal(t, "invalid signature", resp.GetMessage())
+	require.Contains(t, resp.GetServerTiming(), "sigverify;dur=")
+
+	// No bid for the slot yet
+	_, err = client.GetTopBid(context.Background(), &relayv1.TopBidRequest{Slot: submissionSlot, ParentHash: parentHash, ProposerPubkey: payload.Capella.Message.ProposerPubkey.String()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
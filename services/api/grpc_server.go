@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	relayv1 "github.com/flashbots/mev-boost-relay/proto/relay/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// how often StreamTopBids checks for a new top bid
+var grpcTopBidPollInterval = time.Duration(cli.GetEnvInt("GRPC_TOP_BID_POLL_MS", 50)) * time.Millisecond
+
+type grpcBuilderService struct {
+	relayv1.UnimplementedBuilderServiceServer
+	api *RelayAPI
+}
+
+func (s *grpcBuilderService) SubmitBlock(ctx context.Context, req *relayv1.SubmitBlockRequest) (*relayv1.SubmitBlockResponse, error) {
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	w := s.api.submitBlockSSZ(ctx, remoteAddr, req.GetSszPayload(), req.GetCancellations())
+
+	resp := &relayv1.SubmitBlockResponse{
+		StatusCode:   int32(w.statusCode),
+		ServerTiming: w.header.Get(HeaderServerTiming),
+	}
+	if w.statusCode >= http.StatusMultipleChoices {
+		errResp := new(HTTPErrorResp)
+		if err := json.Unmarshal(w.body.Bytes(), errResp); err == nil {
+			resp.ErrorCode = string(errResp.ErrorCode)
+			resp.Message = errResp.Message
+		}
+	}
+	return resp, nil
+}
+
+func (s *grpcBuilderService) getTopBid(req *relayv1.TopBidRequest) (*relayv1.TopBid, error) {
+	bid, err := s.api.redis.GetBestBid(req.GetSlot(), req.GetParentHash(), req.GetProposerPubkey())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	} else if bid == nil {
+		return nil, nil
+	}
+	blockHash, err := bid.BlockHash()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	value, err := bid.Value()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &relayv1.TopBid{Slot: req.GetSlot(), BlockHash: blockHash.String(), Value: value.Dec()}, nil
+}
+
+func (s *grpcBuilderService) GetTopBid(ctx context.Context, req *relayv1.TopBidRequest) (*relayv1.TopBid, error) {
+	topBid, err := s.getTopBid(req)
+	if err != nil {
+		return nil, err
+	} else if topBid == nil {
+		return nil, status.Error(codes.NotFound, "no bid")
+	}
+	return topBid, nil
+}
+
+func (s *grpcBuilderService) StreamTopBids(req *relayv1.TopBidRequest, stream relayv1.BuilderService_StreamTopBidsServer) error {
+	ticker := time.NewTicker(grpcTopBidPollInterval)
+	defer ticker.Stop()
+
+	lastBlockHash := ""
+	for {
+		if s.api.headSlot.Load() >= req.GetSlot() {
+			return nil
+		}
+		topBid, err := s.getTopBid(req)
+		if err != nil {
+			return err
+		}
+		if topBid != nil && topBid.BlockHash != lastBlockHash {
+			if err := stream.Send(topBid); err != nil {
+				return err
+			}
+			lastBlockHash = topBid.BlockHash
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// newGRPCServer returns the gRPC server for block submissions, to be started with Serve
+func (api *RelayAPI) newGRPCServer() (*grpc.Server, error) {
+	opts := api.opts.GRPCListener
+	var serverOpts []grpc.ServerOption
+	if opts.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(opts.TLSCertFile, opts.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+	srv := grpc.NewServer(serverOpts...)
+	relayv1.RegisterBuilderServiceServer(srv, &grpcBuilderService{api: api})
+	return srv, nil
+}
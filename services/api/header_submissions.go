@@ -0,0 +1,344 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// time after a header-only submission until its payload has to be submitted, before the bid is cancelled
+	headerPayloadDeadline = time.Duration(cli.GetEnvInt("HEADER_SUBMISSION_PAYLOAD_DEADLINE_MS", 2000)) * time.Millisecond
+
+	// interval in which the pending payloads in Redis are checked for passed deadlines
+	pendingPayloadCheckInterval = time.Duration(cli.GetEnvInt("HEADER_SUBMISSION_DEADLINE_CHECK_INTERVAL_MS", 100)) * time.Millisecond
+
+	// maximum size of a header-only submission
+	maxHeaderSubmissionBytes int64 = 64 * 1024
+
+	ErrHeaderPayloadMissing = errors.New("payload of header-only bid was not submitted before the deadline")
+	ErrHeaderPayloadInvalid = errors.New("payload submission of header-only bid failed")
+)
+
+// handleSubmitHeader accepts a header-only submission of an optimistic builder: the bid becomes eligible right away,
+// and is cancelled if the payload isn't submitted to the regular block submission endpoint before the deadline
+func (api *RelayAPI) handleSubmitHeader(w http.ResponseWriter, req *http.Request) {
+	headSlot := api.headSlot.Load()
+	receivedAt := time.Now().UTC()
+	log := api.log.WithFields(logrus.Fields{
+		"method":                "submitHeader",
+		"requestID":             getRequestID(req.Context()),
+		"contentLength":         req.ContentLength,
+		"headSlot":              headSlot,
+		"timestampRequestStart": receivedAt.UnixMilli(),
+	})
+
+	if api.srvShutdown.Load() {
+		log.Info("rejecting header submission during shutdown")
+		api.RespondErrorCode(w, http.StatusServiceUnavailable, ErrorCodeShuttingDown, "relay is shutting down")
+		return
+	}
+
+	if api.beaconUnsynced.Load() {
+		log.Info("rejecting header submission while the beacon nodes are not synced")
+		api.RespondErrorCode(w, http.StatusServiceUnavailable, ErrorCodeBeaconNotSynced, "beacon nodes are not synced")
+		return
+	}
+
+	requestBytes, err := readSubmissionBody(req.Body, maxHeaderSubmissionBytes)
+	if err != nil {
+		api.respondBodyReadError(w, log, req, err)
+		return
+	}
+
+	headerReq := new(common.SubmitHeaderRequest)
+	if err := json.Unmarshal(requestBytes, headerReq); err != nil {
+		log.WithError(err).Warn("could not decode header submission")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, err.Error())
+		return
+	}
+
+	submission, err := headerReq.BlockSubmissionInfo()
+	if err != nil {
+		log.WithError(err).Warn("missing fields in header submission")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	log = log.WithFields(logrus.Fields{
+		"slot":           submission.BidTrace.Slot,
+		"builderPubkey":  submission.BidTrace.BuilderPubkey.String(),
+		"blockHash":      submission.BidTrace.BlockHash.String(),
+		"proposerPubkey": submission.BidTrace.ProposerPubkey.String(),
+		"parentHash":     submission.BidTrace.ParentHash.String(),
+		"value":          submission.BidTrace.Value.Dec(),
+	})
+
+	if !api.isDeneb(submission.BidTrace.Slot) {
+		log.Info("rejecting header submission - only supported in deneb")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeForkMismatch, "header submissions are only supported in deneb")
+		return
+	}
+
+	if api.ffDisableDenebSubmissions.Load() {
+		log.Info("rejecting header submission - deneb submissions are disabled")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeForkMismatch, "deneb submissions are disabled")
+		return
+	}
+
+	if !api.checkSubmissionSlotTimestamp(w, log, headSlot, submission) {
+		return
+	}
+
+	builderPubkey := submission.BidTrace.BuilderPubkey
+	builderEntry, ok := api.checkBuilderEntry(w, log, builderPubkey)
+	if !ok {
+		return
+	}
+
+	// Until the payload is received, the bid is only backed by the collateral of the builder
+	if !builderEntry.status.IsOptimistic ||
+		builderEntry.collateral.Cmp(submission.BidTrace.Value.ToBig()) < 0 ||
+		submission.BidTrace.Slot != api.optimisticSlot.Load() {
+		log.Info("rejecting header submission of builder without sufficient optimistic collateral")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInsufficientCollateral, "header submissions require an optimistic builder with sufficient collateral")
+		return
+	}
+
+	gasLimit, ok := api.checkSubmissionFeeRecipient(w, log, submission.BidTrace)
+	if !ok {
+		return
+	}
+
+	if submission.BidTrace.Value.ToBig().Cmp(ZeroU256.BigInt()) == 0 {
+		log.Info("submitHeader failed: bid with 0 value")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if submission.BidTrace.BlockHash != submission.ExecutionPayloadBlockHash || submission.BidTrace.ParentHash != submission.ExecutionPayloadParentHash {
+		log.Info("bid trace block hash or parent hash do not match the header")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "block hash or parent hash do not match the header")
+		return
+	}
+
+	attrs, ok := api.checkSubmissionPayloadAttrs(w, log, submission)
+	if !ok {
+		return
+	}
+
+	if !api.checkSubmissionGas(w, log, submission, gasLimit, attrs) {
+		return
+	}
+
+	ok, err = api.builderSigVerifier.Verify(submission.BidTrace, submission.Signature)
+	if err != nil {
+		log.WithError(err).Warn("failed verifying builder signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "failed verifying builder signature")
+		return
+	} else if !ok {
+		log.Warn("invalid builder signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "invalid signature")
+		return
+	}
+
+	tx := api.redis.NewTxPipeline()
+	floorBidValue, ok := api.checkFloorBidValue(bidFloorOpts{
+		w:                    w,
+		tx:                   tx,
+		log:                  log,
+		cancellationsEnabled: true,
+		simResultC:           make(chan *blockSimResult, 1),
+		submission:           submission,
+	})
+	if !ok {
+		return
+	}
+
+	getHeaderResponse, err := common.BuildHeaderGetHeaderResponse(headerReq, api.blsSk, api.publicKey, api.opts.EthNetDetails.DomainBuilder)
+	if err != nil {
+		log.WithError(err).Error("could not sign builder bid")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Record the pending payload before the bid is eligible, so that the payload submission can't miss it
+	pending := &common.PendingPayload{
+		Slot:           submission.BidTrace.Slot,
+		ParentHash:     submission.BidTrace.ParentHash.String(),
+		ProposerPubkey: submission.BidTrace.ProposerPubkey.String(),
+		BuilderPubkey:  builderPubkey.String(),
+		BlockHash:      submission.BidTrace.BlockHash.String(),
+		Value:          submission.BidTrace.Value.Dec(),
+		ReceivedAtMs:   receivedAt.UnixMilli(),
+		DeadlineMs:     receivedAt.Add(headerPayloadDeadline).UnixMilli(),
+	}
+	if err := api.redis.SavePendingPayload(pending); err != nil {
+		log.WithError(err).Error("could not save pending payload")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternalError, "failed saving bid")
+		return
+	}
+
+	bidTrace := common.BidTraceV2WithBlobFields{
+		BidTrace:      *submission.BidTrace,
+		BlockNumber:   submission.BlockNumber,
+		NumBlobs:      uint64(len(headerReq.BlobKZGCommitments)),
+		BlobGasUsed:   submission.BlobGasUsed,
+		ExcessBlobGas: submission.ExcessBlobGas,
+		RelayPubkey:   api.publicKey.String(),
+		InstanceID:    common.InstanceID,
+	}
+	updateBidResult, err := api.redis.SaveHeaderBidAndUpdateTopBid(context.Background(), tx, &bidTrace, getHeaderResponse, receivedAt, floorBidValue)
+	if err != nil {
+		log.WithError(err).Error("could not save header bid and update top bids")
+		if _, err := api.redis.DelPendingPayload(pending.Slot, pending.BlockHash); err != nil {
+			log.WithError(err).Error("could not remove pending payload")
+		}
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternalError, "failed saving and updating bid")
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"wasTopBidUpdated": updateBidResult.WasTopBidUpdated,
+		"isNewTopBid":      updateBidResult.IsNewTopBid,
+		"topBidValue":      updateBidResult.TopBidValue,
+		"prevTopBidValue":  updateBidResult.PrevTopBidValue,
+		"deadlineMs":       pending.DeadlineMs,
+	}).Info("received header from builder")
+	w.WriteHeader(http.StatusOK)
+}
+
+// processPendingPayload is called after a block submission, to resolve the pending header-only bid of the block once
+// its payload was submitted successfully, or to cancel it if the payload submission failed
+func (api *RelayAPI) processPendingPayload(log *logrus.Entry, w *submissionResponseWriter, submission *common.BlockSubmissionInfo) {
+	pending, err := api.redis.GetPendingPayload(submission.BidTrace.Slot, submission.BidTrace.BlockHash.String())
+	if err != nil {
+		log.WithError(err).Error("failed to get pending payload")
+		return
+	} else if pending == nil || pending.BuilderPubkey != submission.BidTrace.BuilderPubkey.String() {
+		return
+	}
+
+	if w.statusCode >= http.StatusMultipleChoices {
+		api.cancelPendingPayload(log, pending, ErrHeaderPayloadInvalid, true)
+		return
+	}
+	if _, err := api.redis.GetPayloadContents(pending.Slot, pending.ProposerPubkey, pending.BlockHash); err != nil {
+		// Accepted without being stored, i.e. below the floor bid (which might have been raised after the header was
+		// received), so the bid is removed without demoting the builder
+		api.cancelPendingPayload(log, pending, ErrHeaderPayloadInvalid, false)
+		return
+	}
+	if _, err := api.redis.DelPendingPayload(pending.Slot, pending.BlockHash); err != nil {
+		log.WithError(err).Error("failed to remove pending payload")
+		return
+	}
+	log.Info("received payload of header-only bid")
+}
+
+// cancelPendingPayload removes the header-only bid of a pending payload and optionally demotes the builder, unless the
+// payload was received in the meantime
+func (api *RelayAPI) cancelPendingPayload(log *logrus.Entry, pending *common.PendingPayload, reason error, demote bool) {
+	log = log.WithField("cancelReason", reason.Error())
+	wasPending, err := api.redis.DelPendingPayload(pending.Slot, pending.BlockHash)
+	if err != nil {
+		log.WithError(err).Error("failed to remove pending payload")
+		return
+	} else if !wasPending { // already resolved or cancelled
+		return
+	}
+	if _, err := api.redis.GetPayloadContents(pending.Slot, pending.ProposerPubkey, pending.BlockHash); err == nil {
+		log.Info("received payload of header-only bid")
+		return
+	}
+
+	wasRemoved, err := api.redis.DelHeaderBid(context.Background(), api.redis.NewPipeline(), pending)
+	if err != nil {
+		log.WithError(err).Error("failed to remove header-only bid")
+	}
+	api.datastore.InvalidateBestBid(pending.Slot, pending.ParentHash, pending.ProposerPubkey)
+	if err := api.redis.IncStats(datastore.RedisStatsFieldHeaderBidsCancelled, 1); err != nil {
+		log.WithError(err).Error("failed to increment cancelled header bids")
+	}
+	log.WithFields(logrus.Fields{
+		"wasBidRemoved": wasRemoved,
+		"demoteBuilder": demote,
+	}).Warn("cancelled header-only bid")
+	if !demote {
+		return
+	}
+
+	api.setBuilderNonOptimistic(pending.BuilderPubkey)
+	bidTrace, err := api.redis.GetBidTrace(pending.Slot, pending.ProposerPubkey, pending.BlockHash)
+	if err != nil {
+		log.WithError(err).Error("failed to get bid trace of header-only bid")
+		return
+	}
+	if err := api.db.InsertBuilderDemotionFromBidTrace(bidTrace, reason); err != nil {
+		log.WithError(err).WithField("errorWritingDemotionToDB", true).Error("failed to save demotion to database")
+	}
+	api.publishDemotionEvent(&bidTrace.BidTrace, reason)
+}
+
+// startPendingPayloadDeadlineCheck regularly cancels the header-only bids whose payload deadline passed. The deadline is
+// enforced from the pending payloads in Redis by every instance, so that it doesn't depend on the instance which
+// received the header still running, or receiving the payload.
+func (api *RelayAPI) startPendingPayloadDeadlineCheck() {
+	ticker := time.NewTicker(pendingPayloadCheckInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		api.cancelExpiredPendingPayloads(now)
+	}
+}
+
+// cancelExpiredPendingPayloads cancels the header-only bids of the head slot and the next slot whose payload deadline
+// passed before the given time
+func (api *RelayAPI) cancelExpiredPendingPayloads(now time.Time) {
+	headSlot := api.headSlot.Load()
+	for _, slot := range []uint64{headSlot, headSlot + 1} {
+		pendingPayloads, err := api.redis.GetPendingPayloads(slot)
+		if err != nil {
+			api.log.WithError(err).WithField("slot", slot).Error("failed to get pending payloads")
+			continue
+		}
+		for _, pending := range pendingPayloads {
+			if pending.DeadlineMs <= now.UnixMilli() {
+				api.cancelPendingPayload(pendingPayloadLog(api.log, pending), pending, ErrHeaderPayloadMissing, true)
+			}
+		}
+	}
+}
+
+// cancelExpiredHeaderBid cancels the bid if it's a header-only bid whose payload deadline passed before the given time,
+// so that it's not served in getHeader before the deadline check removed it. Returns whether the bid was cancelled.
+func (api *RelayAPI) cancelExpiredHeaderBid(log *logrus.Entry, slot uint64, bid *builderSpec.VersionedSignedBuilderBid, now time.Time) bool {
+	blockHash, err := bid.BlockHash()
+	if err != nil {
+		return false
+	}
+	pending, err := api.redis.GetPendingPayload(slot, blockHash.String())
+	if err != nil {
+		log.WithError(err).Error("failed to get pending payload")
+		return false
+	} else if pending == nil || pending.DeadlineMs > now.UnixMilli() {
+		return false
+	}
+	api.cancelPendingPayload(pendingPayloadLog(log, pending), pending, ErrHeaderPayloadMissing, true)
+	return true
+}
+
+func pendingPayloadLog(log *logrus.Entry, pending *common.PendingPayload) *logrus.Entry {
+	return log.WithFields(logrus.Fields{
+		"slot":           pending.Slot,
+		"builderPubkey":  pending.BuilderPubkey,
+		"blockHash":      pending.BlockHash,
+		"proposerPubkey": pending.ProposerPubkey,
+		"deadlineMs":     pending.DeadlineMs,
+	})
+}
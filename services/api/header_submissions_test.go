@@ -0,0 +1,147 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitHeader(t *testing.T) {
+	headerSlot := slot + 32
+	setup := func(t *testing.T) (blockRequestOpts, *testBackend, *common.SubmitHeaderRequest) {
+		t.Helper()
+		pubkey, secretkey, backend := startTestBackend(t)
+		backend.relay.ffEnableHeaderSubmissions = true
+		backend.relay.optimisticSlot.Store(headerSlot)
+		backend.relay.forkSchedule.CapellaEpoch = 1
+		backend.relay.forkSchedule.DenebEpoch = 2
+		backend.relay.proposerDutiesMap[headerSlot] = backend.relay.proposerDutiesMap[slot]
+
+		randaoHash, err := utils.HexToHash(randao)
+		require.NoError(t, err)
+		withRoot, err := ComputeWithdrawalsRoot([]*capella.Withdrawal{})
+		require.NoError(t, err)
+		backend.relay.payloadAttributes[getPayloadAttributesKey(emptyHash, headerSlot)] = payloadAttributesHelper{
+			slot:              headerSlot,
+			withdrawalsRoot:   withRoot,
+			payloadAttributes: beaconclient.PayloadAttributes{PrevRandao: randaoHash.String()},
+		}
+
+		opts := blockRequestOpts{pubkey: *pubkey, secretkey: secretkey, blockValue: 100, slot: headerSlot, version: spec.DataVersionDeneb}
+		payload := common.TestBuilderSubmitBlockRequest(secretkey, getTestBidTrace(*pubkey, opts.blockValue, headerSlot), spec.DataVersionDeneb)
+		header, err := utils.PayloadToPayloadHeader(&builderApi.VersionedExecutionPayload{Version: spec.DataVersionDeneb, Deneb: payload.Deneb.ExecutionPayload})
+		require.NoError(t, err)
+		return opts, backend, &common.SubmitHeaderRequest{
+			Message:                payload.Deneb.Message,
+			ExecutionPayloadHeader: header.Deneb,
+			BlobKZGCommitments:     []deneb.KZGCommitment{},
+			Signature:              payload.Deneb.Signature,
+		}
+	}
+
+	getTopBidValue := func(t *testing.T, backend *testBackend) uint64 {
+		t.Helper()
+		bid, err := backend.relay.redis.GetBestBid(headerSlot, emptyHash, phase0.BLSPubKey{}.String())
+		require.NoError(t, err)
+		if bid == nil {
+			return 0
+		}
+		value, err := bid.Value()
+		require.NoError(t, err)
+		return value.Uint64()
+	}
+
+	t.Run("payload received", func(t *testing.T) {
+		opts, backend, headerReq := setup(t)
+		rr := backend.request(http.MethodPost, pathSubmitHeader, headerReq)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, opts.blockValue, getTopBidValue(t, backend))
+		pending, err := backend.relay.redis.GetPendingPayload(headerSlot, emptyHash)
+		require.NoError(t, err)
+		require.NotNil(t, pending)
+
+		rr = runOptimisticBlockSubmission(t, opts, nil, backend)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		pending, err = backend.relay.redis.GetPendingPayload(headerSlot, emptyHash)
+		require.NoError(t, err)
+		require.Nil(t, pending)
+		require.Equal(t, opts.blockValue, getTopBidValue(t, backend))
+		require.False(t, backend.relay.db.(*database.MockDB).Demotions[opts.pubkey.String()])
+	})
+
+	t.Run("payload missing", func(t *testing.T) {
+		opts, backend, headerReq := setup(t)
+		backend.relay.headSlot.Store(headerSlot - 1)
+		rr := backend.request(http.MethodPost, pathSubmitHeader, headerReq)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, opts.blockValue, getTopBidValue(t, backend))
+
+		// The bid is kept until the deadline
+		backend.relay.cancelExpiredPendingPayloads(time.Now())
+		require.Equal(t, opts.blockValue, getTopBidValue(t, backend))
+
+		// The bid is cancelled after the deadline, by any instance, and the builder demoted
+		backend.relay.cancelExpiredPendingPayloads(time.Now().Add(headerPayloadDeadline))
+		require.Equal(t, uint64(0), getTopBidValue(t, backend))
+		require.True(t, backend.relay.db.(*database.MockDB).Demotions[opts.pubkey.String()])
+		cancelled, err := backend.relay.redis.GetStatsUint64(datastore.RedisStatsFieldHeaderBidsCancelled)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), cancelled)
+	})
+
+	t.Run("payload missing at getHeader", func(t *testing.T) {
+		defer func(deadline time.Duration) { headerPayloadDeadline = deadline }(headerPayloadDeadline)
+		headerPayloadDeadline = 0
+
+		opts, backend, headerReq := setup(t)
+		rr := backend.request(http.MethodPost, pathSubmitHeader, headerReq)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, opts.blockValue, getTopBidValue(t, backend))
+
+		// The expired bid is not served, even before the deadline check cancelled it
+		bid, err := backend.relay.getBestBid(backend.relay.log, headerSlot, emptyHash, phase0.BLSPubKey{}.String())
+		require.NoError(t, err)
+		require.Nil(t, bid)
+		require.True(t, backend.relay.db.(*database.MockDB).Demotions[opts.pubkey.String()])
+	})
+
+	t.Run("payload failed", func(t *testing.T) {
+		opts, backend, headerReq := setup(t)
+		rr := backend.request(http.MethodPost, pathSubmitHeader, headerReq)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// The last transaction of the payload doesn't pay the proposer
+		backend.relay.ffVerifyProposerPayment = true
+		rr = runOptimisticBlockSubmission(t, opts, nil, backend)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, uint64(0), getTopBidValue(t, backend))
+		require.True(t, backend.relay.db.(*database.MockDB).Demotions[opts.pubkey.String()])
+	})
+
+	t.Run("builder not optimistic", func(t *testing.T) {
+		opts, backend, headerReq := setup(t)
+		backend.relay.blockBuildersCache[opts.pubkey.String()].status.IsOptimistic = false
+		rr := backend.request(http.MethodPost, pathSubmitHeader, headerReq)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), string(ErrorCodeInsufficientCollateral))
+	})
+
+	t.Run("header mismatch", func(t *testing.T) {
+		_, backend, headerReq := setup(t)
+		headerReq.ExecutionPayloadHeader.BlockHash[0] = 0x01
+		rr := backend.request(http.MethodPost, pathSubmitHeader, headerReq)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
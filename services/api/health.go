@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var ErrBeaconNodeSyncing = errors.New("beacon node is syncing")
+
+const (
+	healthStatusOK    = "ok"
+	healthStatusError = "error"
+)
+
+// DependencyStatus is the health of a single dependency of the relay
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse is the response of /healthz and /readyz
+type HealthResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+
+	SubmissionQueue *SubmissionQueueStatus `json:"submission_queue,omitempty"`
+}
+
+// checkDependencies checks the connectivity of all dependencies in parallel, and returns whether all of them are healthy
+func (api *RelayAPI) checkDependencies() (healthy bool, statuses map[string]DependencyStatus) {
+	checks := map[string]func() error{
+		"beacon": func() error {
+			syncStatus, err := api.beaconClient.BestSyncStatus()
+			if err != nil {
+				return err
+			}
+			if syncStatus.IsSyncing {
+				return ErrBeaconNodeSyncing
+			}
+			return nil
+		},
+	}
+	for _, backend := range api.datastore.CacheBackends() {
+		checks[backend.Name()] = backend.Ping
+	}
+	if api.db != nil {
+		checks["database"] = api.db.Ping
+	}
+
+	healthy = true
+	statuses = make(map[string]DependencyStatus, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func() error) {
+			defer wg.Done()
+			status := DependencyStatus{Status: healthStatusOK}
+			if err := check(); err != nil {
+				status = DependencyStatus{Status: healthStatusError, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			statuses[name] = status
+			if status.Status != healthStatusOK {
+				healthy = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return healthy, statuses
+}
+
+func (api *RelayAPI) handleHealthz(w http.ResponseWriter, req *http.Request) {
+	healthy, statuses := api.checkDependencies()
+	if !healthy {
+		api.Respond(w, http.StatusServiceUnavailable, HealthResponse{Status: "unhealthy", Dependencies: statuses, SubmissionQueue: api.getSubmissionQueueStatus()})
+		return
+	}
+	api.RespondOK(w, HealthResponse{Status: "healthy", Dependencies: statuses, SubmissionQueue: api.getSubmissionQueueStatus()})
+}
+
+func (api *RelayAPI) handleReadyz(w http.ResponseWriter, req *http.Request) {
+	healthy, statuses := api.checkDependencies()
+	if !healthy || !api.IsReady() {
+		api.Respond(w, http.StatusServiceUnavailable, HealthResponse{Status: "not ready", Dependencies: statuses, SubmissionQueue: api.getSubmissionQueueStatus()})
+		return
+	}
+	api.RespondOK(w, HealthResponse{Status: "ready", Dependencies: statuses, SubmissionQueue: api.getSubmissionQueueStatus()})
+}
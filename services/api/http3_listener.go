@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/flashbots/go-utils/httplogger"
+	"github.com/gorilla/mux"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// HTTP3ListenerOpts contains the options for the optional HTTP/3 (QUIC) listener for block submissions, which saves
+// distant builders the TCP and TLS handshakes and avoids head-of-line blocking on lossy connections
+type HTTP3ListenerOpts struct {
+	ListenAddr  string // UDP address
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+func (api *RelayAPI) getHTTP3Router() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc(pathSubmitNewBlock, api.handleSubmitNewBlock).Methods(http.MethodPost)
+
+	loggedRouter := httplogger.LoggingMiddlewareLogrus(api.log.WithField("listener", "http3"), r)
+	return requestIDMiddleware(loggedRouter)
+}
+
+// newHTTP3Server returns the HTTP/3 server for block submissions, to be started with ListenAndServeTLS
+func (api *RelayAPI) newHTTP3Server() *http3.Server {
+	return &http3.Server{
+		Addr:           api.opts.HTTP3Listener.ListenAddr,
+		Handler:        api.getHTTP3Router(),
+		MaxHeaderBytes: apiMaxHeaderBytes,
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP3Router(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	router := backend.relay.getHTTP3Router()
+
+	// only block submissions are served
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, pathBuilderGetValidators, nil))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, pathSubmitNewBlock, nil))
+	require.NotEqual(t, http.StatusNotFound, rr.Code)
+}
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 and its key, and returns the file names and the
+// certificate
+func writeTestCertificate(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "relay.test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+func TestHTTP3Server(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	certFile, keyFile, cert := writeTestCertificate(t)
+
+	// find a free UDP port
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	backend.relay.opts.HTTP3Listener = &HTTP3ListenerOpts{ListenAddr: addr, TLSCertFile: certFile, TLSKeyFile: keyFile}
+	srv := backend.relay.newHTTP3Server()
+	go func() {
+		_ = srv.ListenAndServeTLS(certFile, keyFile)
+	}()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	transport := &http3.RoundTripper{TLSClientConfig: &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS13}}
+	defer transport.Close()
+	client := &http.Client{Transport: transport, Timeout: time.Second}
+
+	// an invalid submission goes through the block submission handler
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = client.Post("https://"+addr+pathSubmitNewBlock, "application/json", bytes.NewReader([]byte("{}")))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get(HeaderRequestID))
+	require.Equal(t, "HTTP/3.0", resp.Proto)
+
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// how often instances write their heartbeat to Redis, and after which time without heartbeat they are considered gone
+	instanceHeartbeatInterval = time.Duration(cli.GetEnvInt("INSTANCE_HEARTBEAT_INTERVAL_SEC", 5)) * time.Second
+	instanceStaleAfter        = time.Duration(cli.GetEnvInt("INSTANCE_STALE_AFTER_SEC", 60)) * time.Second
+)
+
+// InstanceJSON is an active relay instance on the internal instances endpoint
+type InstanceJSON struct {
+	datastore.InstanceHeartbeat
+	Leader bool `json:"leader"`
+	Self   bool `json:"self"`
+}
+
+func (api *RelayAPI) startInstanceHeartbeat() {
+	startedAt := time.Now().UTC()
+	for {
+		err := api.redis.SetInstanceHeartbeat(datastore.InstanceHeartbeat{
+			InstanceID: common.InstanceID,
+			Service:    "api",
+			Version:    api.opts.Version,
+			StartedAt:  startedAt,
+			LastSeen:   time.Now().UTC(),
+		})
+		if err != nil {
+			api.log.WithError(err).Error("failed to write instance heartbeat")
+		}
+		time.Sleep(instanceHeartbeatInterval)
+	}
+}
+
+// publishBlockOnce publishes the block unless another instance sharing the Redis already claimed its publication, i.e.
+// when the proposer sent getPayload for the same block to several instances behind the load balancer. The results are
+// nil if another instance publishes the block.
+func (api *RelayAPI) publishBlockOnce(log *logrus.Entry, slot uint64, blockHash string, block *common.VersionedSignedProposal) (code int, results *beaconclient.PublishResults, err error) {
+	publisher, err := api.redis.ClaimBlockPublication(slot, blockHash, common.InstanceID)
+	if err != nil {
+		log.WithError(err).Warn("failed to claim block publication, publishing anyway")
+	} else if publisher != common.InstanceID {
+		log.WithField("publisherInstance", publisher).Info("block is published by another instance")
+		return http.StatusOK, nil, nil
+	}
+
+	code, results, err = api.beaconClient.PublishBlock(block) // errors are logged inside
+	if publisher == common.InstanceID && (err != nil || (code != http.StatusOK && code != http.StatusAccepted)) {
+		// let another instance retry
+		if err := api.redis.ReleaseBlockPublication(slot, blockHash); err != nil {
+			log.WithError(err).Error("failed to release block publication")
+		}
+	}
+	return code, results, err
+}
+
+func (api *RelayAPI) handleInternalInstances(w http.ResponseWriter, req *http.Request) {
+	heartbeats, err := api.redis.GetInstanceHeartbeats(time.Now().Add(-instanceStaleAfter))
+	if err != nil {
+		api.log.WithError(err).Error("failed to get instance heartbeats")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	leader, err := api.redis.GetLeaseHolder(datastore.LeaseHousekeeper)
+	if err != nil {
+		api.log.WithError(err).Error("failed to get lease holder")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	instances := make([]InstanceJSON, len(heartbeats))
+	for i, heartbeat := range heartbeats {
+		instances[i] = InstanceJSON{
+			InstanceHeartbeat: heartbeat,
+			Leader:            heartbeat.Service == "housekeeper" && heartbeat.InstanceID == leader,
+			Self:              heartbeat.InstanceID == common.InstanceID,
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].Service != instances[j].Service {
+			return instances[i].Service < instances[j].Service
+		}
+		return instances[i].InstanceID < instances[j].InstanceID
+	})
+	api.RespondOK(w, instances)
+}
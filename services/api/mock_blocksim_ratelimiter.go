@@ -17,3 +17,21 @@ func (m *MockBlockSimulationRateLimiter) Send(context context.Context, payload *
 func (m *MockBlockSimulationRateLimiter) CurrentCounter() int64 {
 	return 0
 }
+
+func (m *MockBlockSimulationRateLimiter) SetMaxConcurrent(maxConcurrent int64) {}
+
+func (m *MockBlockSimulationRateLimiter) SimNodes() []SimNodeStatus {
+	return nil
+}
+
+func (m *MockBlockSimulationRateLimiter) AddSimNode(nodeURL string) error {
+	return nil
+}
+
+func (m *MockBlockSimulationRateLimiter) RemoveSimNode(nodeURL string) error {
+	return nil
+}
+
+func (m *MockBlockSimulationRateLimiter) ProbeSimNodes() []string {
+	return nil
+}
@@ -456,14 +456,41 @@ func TestBuilderApiSubmitNewBlockOptimistic(t *testing.T) {
 			slot:            slot + 32,
 			version:         spec.DataVersionDeneb,
 		},
+		{
+			description: "success_value_less_than_collateral_electra",
+			wantStatus: common.BuilderStatus{
+				IsOptimistic: true,
+				IsHighPrio:   true,
+			},
+			simulationError: nil,
+			expectDemotion:  false,
+			httpCode:        200, // success
+			blockValue:      collateral - 1,
+			slot:            slot + 64,
+			version:         spec.DataVersionElectra,
+		},
+		{
+			description: "failure_value_more_than_collateral_electra",
+			wantStatus: common.BuilderStatus{
+				IsOptimistic: true,
+				IsHighPrio:   true,
+			},
+			simulationError: errFake,
+			expectDemotion:  false,
+			httpCode:        400, // failure (in pessimistic mode, block sim failure happens in response path)
+			blockValue:      collateral + 1,
+			slot:            slot + 64,
+			version:         spec.DataVersionElectra,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			pubkey, secretkey, backend := startTestBackend(t)
 			backend.relay.optimisticSlot.Store(tc.slot)
-			backend.relay.capellaEpoch = 1
-			backend.relay.denebEpoch = 2
+			backend.relay.forkSchedule.CapellaEpoch = 1
+			backend.relay.forkSchedule.DenebEpoch = 2
+			backend.relay.forkSchedule.ElectraEpoch = 3
 			backend.relay.proposerDutiesMap[tc.slot] = backend.relay.proposerDutiesMap[slot]
 
 			randaoHash, err := utils.HexToHash(randao)
@@ -501,6 +528,17 @@ func TestBuilderApiSubmitNewBlockOptimistic(t *testing.T) {
 			require.True(t, ok)
 			require.Equal(t, mockDB.Demotions[pkStr], tc.expectDemotion)
 			require.False(t, mockDB.Refunds[pkStr])
+
+			// Check that the bid of an electra block has the execution requests.
+			if tc.version == spec.DataVersionElectra && tc.httpCode == 200 {
+				bid, err := backend.relay.redis.GetBestBid(tc.slot, emptyHash, phase0.BLSPubKey{}.String())
+				require.NoError(t, err)
+				require.Equal(t, spec.DataVersionElectra, bid.Version)
+				require.NotNil(t, bid.Electra.Message.ExecutionRequests)
+				trace, err := backend.relay.redis.GetBidTrace(tc.slot, phase0.BLSPubKey{}.String(), emptyHash)
+				require.NoError(t, err)
+				require.NotNil(t, trace.ExecutionRequestsRoot)
+			}
 		})
 	}
 }
@@ -548,3 +586,46 @@ func TestInternalBuilderCollateral(t *testing.T) {
 	require.Equal(t, "builder0x69", resp.BuilderID)
 	require.Equal(t, "10000", resp.Collateral)
 }
+
+func TestInternalBuilderDemotions(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	path := "/internal/v1/builder/demotions/" + pubkey.String()
+
+	// No demotions yet.
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := []common.BuilderDemotionJSON{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Empty(t, resp)
+
+	req := common.TestBuilderSubmitBlockRequest(secretkey, getTestBidTrace(*pubkey, collateral, slot), spec.DataVersionDeneb)
+	backend.relay.demoteBuilder(pubkey.String(), req, errFake)
+
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	require.Equal(t, pubkey.String(), resp[0].BuilderPubkey)
+
+	rr = backend.request(http.MethodGet, path+"?limit=101", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestInternalBuilderRelayFee(t *testing.T) {
+	pubkey, _, backend := startTestBackend(t)
+	path := "/internal/v1/builder/relay_fee/" + pubkey.String()
+
+	rr := backend.request(http.MethodPost, path+"?bps=25", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = backend.request(http.MethodGet, "/internal/v1/builder/"+pubkey.String(), nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := &database.BlockBuilderEntry{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, uint64(25), resp.RelayFeeBps)
+
+	for _, query := range []string{"", "?bps=abc", "?bps=10001"} {
+		rr = backend.request(http.MethodPost, path+query, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+)
+
+var ErrBeaconPayloadAttributesMismatch = errors.New("payload attributes don't match the beacon node state")
+
+// verifyPayloadAttributesWithBeacon checks the prev_randao and the withdrawals of a payload_attributes event against the
+// state of the beacon node: the randao mix of the parent slot, and the expected withdrawals of the proposal slot.
+// Returns ErrBeaconPayloadAttributesMismatch if they differ, or another error if the beacon node couldn't be queried.
+func (api *RelayAPI) verifyPayloadAttributesWithBeacon(slot uint64, attrs beaconclient.PayloadAttributes, withdrawalsRoot phase0.Root) error {
+	randaoResp, err := api.beaconClient.GetRandao(slot - 1)
+	if err != nil {
+		return err
+	}
+	if randaoResp.Data.Randao != attrs.PrevRandao {
+		return fmt.Errorf("%w: prev_randao %s, beacon randao %s", ErrBeaconPayloadAttributesMismatch, attrs.PrevRandao, randaoResp.Data.Randao)
+	}
+
+	if !hasReachedFork(slot, api.forkSchedule.CapellaEpoch) {
+		return nil
+	}
+	withdrawalsResp, err := api.beaconClient.GetWithdrawals(slot)
+	if err != nil {
+		return err
+	}
+	beaconWithdrawalsRoot, err := ComputeWithdrawalsRoot(withdrawalsResp.Data.Withdrawals)
+	if err != nil {
+		return err
+	}
+	if beaconWithdrawalsRoot != withdrawalsRoot {
+		return fmt.Errorf("%w: withdrawals root %s, beacon withdrawals root %s", ErrBeaconPayloadAttributesMismatch, withdrawalsRoot.String(), beaconWithdrawalsRoot.String())
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPayloadAttributesWithBeacon(t *testing.T) {
+	randao := "0x9962816e9d0a39fd4c80935338a741dc916d1545694e41eb5a505e1a3098f9e4"
+	withdrawals := []*capella.Withdrawal{{Index: 1, ValidatorIndex: 2, Amount: 3}}
+	withdrawalsRoot, err := ComputeWithdrawalsRoot(withdrawals)
+	require.NoError(t, err)
+
+	beaconInstance := beaconclient.NewMockBeaconInstance()
+	beaconInstance.MockRandao = &beaconclient.GetRandaoResponse{}
+	beaconInstance.MockRandao.Data.Randao = randao
+	beaconInstance.MockWithdrawals = &beaconclient.GetWithdrawalsResponse{}
+	beaconInstance.MockWithdrawals.Data.Withdrawals = withdrawals
+
+	backend := newTestBackend(t, 1)
+	backend.relay.beaconClient = beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconInstance})
+	backend.relay.forkSchedule.CapellaEpoch = 0
+
+	err = backend.relay.verifyPayloadAttributesWithBeacon(10, beaconclient.PayloadAttributes{PrevRandao: randao}, withdrawalsRoot)
+	require.NoError(t, err)
+
+	err = backend.relay.verifyPayloadAttributesWithBeacon(10, beaconclient.PayloadAttributes{PrevRandao: "0x01"}, withdrawalsRoot)
+	require.ErrorIs(t, err, ErrBeaconPayloadAttributesMismatch)
+
+	err = backend.relay.verifyPayloadAttributesWithBeacon(10, beaconclient.PayloadAttributes{PrevRandao: randao}, [32]byte{1})
+	require.ErrorIs(t, err, ErrBeaconPayloadAttributesMismatch)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// Registrations of unknown validators (i.e. whose deposit wasn't processed at the last refresh of the known
+	// validators) are kept and processed once the validator is known, instead of being rejected (0 to reject them)
+	pendingRegistrationsMax = cli.GetEnvInt("PENDING_REGISTRATIONS_MAX", 0)
+	pendingRegistrationsTTL = time.Duration(cli.GetEnvInt("PENDING_REGISTRATIONS_TTL_MIN", 60)) * time.Minute
+
+	// Look up unknown validators on the beacon node before rejecting or queuing their registrations
+	pendingRegistrationsBeaconLookup = os.Getenv("PENDING_REGISTRATIONS_BEACON_LOOKUP") == "1"
+)
+
+type pendingRegistration struct {
+	queuedValidatorRegistration
+	addedAt time.Time
+}
+
+// pendingRegistrations holds the signature-checked registrations of validators which are not known yet, until they
+// show up in a refresh of the known validators, or expire
+type pendingRegistrations struct {
+	mu      sync.Mutex
+	max     int
+	ttl     time.Duration
+	entries map[common.PubkeyHex]pendingRegistration
+}
+
+func newPendingRegistrations(maxEntries int, ttl time.Duration) *pendingRegistrations {
+	return &pendingRegistrations{
+		max:     maxEntries,
+		ttl:     ttl,
+		entries: make(map[common.PubkeyHex]pendingRegistration),
+	}
+}
+
+// add queues the registration, replacing an older one of the same validator. Returns false if the queue is full.
+func (p *pendingRegistrations) add(reg queuedValidatorRegistration, now time.Time) bool {
+	pubkey := common.NewPubkeyHex(reg.registration.Message.Pubkey.String())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, found := p.entries[pubkey]; !found && len(p.entries) >= p.max {
+		return false
+	}
+	p.entries[pubkey] = pendingRegistration{queuedValidatorRegistration: reg, addedAt: now}
+	return true
+}
+
+// takeKnown removes and returns the registrations of the validators which are now known, and drops expired ones
+func (p *pendingRegistrations) takeKnown(isKnown func(common.PubkeyHex) bool, now time.Time) (known []queuedValidatorRegistration, numExpired int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for pubkey, entry := range p.entries {
+		if isKnown(pubkey) {
+			known = append(known, entry.queuedValidatorRegistration)
+			delete(p.entries, pubkey)
+		} else if now.Sub(entry.addedAt) > p.ttl {
+			numExpired++
+			delete(p.entries, pubkey)
+		}
+	}
+	return known, numExpired
+}
+
+func (p *pendingRegistrations) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
+
+// lookupValidator checks the beacon node for a validator which isn't in the known validators (yet), and adds it if
+// it's active or pending activation
+func (api *RelayAPI) lookupValidator(log *logrus.Entry, pubkey common.PubkeyHex) bool {
+	resp, err := api.beaconClient.GetStateValidator(beaconclient.StateIDHead, pubkey.String())
+	if err != nil {
+		log.WithError(err).Debug("validator not found on the beacon node")
+		return false
+	}
+	switch resp.Data.Status {
+	case "pending_initialized", "pending_queued", "active_ongoing", "active_exiting", "active_slashed":
+		api.datastore.AddKnownValidator(pubkey, resp.Data.Index)
+		log.WithField("status", resp.Data.Status).Info("added validator found on the beacon node")
+		return true
+	}
+	return false
+}
+
+// processPendingRegistrations processes the queued registrations of validators which are known after a refresh
+func (api *RelayAPI) processPendingRegistrations() {
+	registrations, numExpired := api.pendingRegs.takeKnown(api.datastore.IsKnownValidator, time.Now())
+	for _, reg := range registrations {
+		select {
+		case api.validatorRegC <- reg:
+		default:
+			api.log.WithField("pubkey", reg.registration.Message.Pubkey.String()).Error("validator registration channel full")
+		}
+	}
+	if len(registrations) > 0 || numExpired > 0 {
+		api.log.WithFields(logrus.Fields{
+			"numProcessed": len(registrations),
+			"numExpired":   numExpired,
+			"numPending":   api.pendingRegs.len(),
+		}).Info("processed pending validator registrations")
+	}
+}
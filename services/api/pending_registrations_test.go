@@ -0,0 +1,47 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingRegistrations(t *testing.T) {
+	now := time.Now()
+	reg := func(b byte) queuedValidatorRegistration {
+		return queuedValidatorRegistration{
+			registration: builderApiV1.SignedValidatorRegistration{
+				Message: &builderApiV1.ValidatorRegistration{Pubkey: phase0.BLSPubKey{b}},
+			},
+		}
+	}
+	pubkey := func(b byte) common.PubkeyHex {
+		return common.NewPubkeyHex(phase0.BLSPubKey{b}.String())
+	}
+
+	p := newPendingRegistrations(2, time.Hour)
+	require.True(t, p.add(reg(1), now))
+	require.True(t, p.add(reg(2), now.Add(30*time.Minute)))
+	require.False(t, p.add(reg(3), now))
+	require.True(t, p.add(reg(1), now), "replacing a registration of the same validator is allowed when full")
+	require.Equal(t, 2, p.len())
+
+	known, numExpired := p.takeKnown(func(pk common.PubkeyHex) bool { return false }, now.Add(time.Minute))
+	require.Empty(t, known)
+	require.Equal(t, 0, numExpired)
+
+	known, numExpired = p.takeKnown(func(pk common.PubkeyHex) bool { return pk == pubkey(2) }, now.Add(time.Minute))
+	require.Len(t, known, 1)
+	require.Equal(t, phase0.BLSPubKey{2}, known[0].registration.Message.Pubkey)
+	require.Equal(t, 0, numExpired)
+	require.Equal(t, 1, p.len())
+
+	known, numExpired = p.takeKnown(func(pk common.PubkeyHex) bool { return false }, now.Add(2*time.Hour))
+	require.Empty(t, known)
+	require.Equal(t, 1, numExpired)
+	require.Equal(t, 0, p.len())
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+)
+
+var errPreconfArgs = errors.New("preconf_commitment and preconf_tx_indices must be given together")
+
+// Preconfirmation status of a slot
+const (
+	PreconfStatusPending     = "pending"     // no payload was delivered for the slot yet
+	PreconfStatusCommitted   = "committed"   // the delivered payload has a validated commitment
+	PreconfStatusUncommitted = "uncommitted" // the delivered payload has no commitment
+)
+
+// preconfArgs are the optional preconfirmation arguments of a block submission: the commitment to the preconfirmed
+// transactions, and their indices in the payload
+type preconfArgs struct {
+	commitment phase0.Hash32
+	txIndices  []int
+}
+
+// PreconfStatusResponse is the response of the preconfirmation status data endpoint
+type PreconfStatusResponse struct {
+	Slot                uint64                      `json:"slot,string"`
+	Status              string                      `json:"status"`
+	DeliveredBlockHash  string                      `json:"delivered_block_hash,omitempty"`
+	DeliveredCommitment *common.PreconfCommitment   `json:"delivered_commitment,omitempty"`
+	Commitments         []*common.PreconfCommitment `json:"commitments"`
+}
+
+// parsePreconfArgs returns the preconfirmation arguments of a block submission, or nil if there are none
+func parsePreconfArgs(args url.Values) (*preconfArgs, error) {
+	commitmentArg, indicesArg := args.Get("preconf_commitment"), args.Get("preconf_tx_indices")
+	if commitmentArg == "" && indicesArg == "" {
+		return nil, nil
+	} else if commitmentArg == "" || indicesArg == "" {
+		return nil, errPreconfArgs
+	}
+
+	commitment, err := common.StrToPhase0Hash(commitmentArg)
+	if err != nil {
+		return nil, err
+	}
+	txIndices, err := common.ParsePreconfTxIndices(indicesArg)
+	if err != nil {
+		return nil, err
+	}
+	return &preconfArgs{commitment: commitment, txIndices: txIndices}, nil
+}
+
+// checkSubmissionPreconf validates the preconfirmation commitment of a block submission against its transactions.
+// Returns the commitment to save once the bid is eligible (nil if the submission has none), and false if the
+// commitment doesn't match, in which case the error response was already sent.
+func (api *RelayAPI) checkSubmissionPreconf(w http.ResponseWriter, log *logrus.Entry, submission *common.BlockSubmissionInfo, preconf *preconfArgs, receivedAt time.Time) (*common.PreconfCommitment, bool) {
+	if preconf == nil {
+		return nil, true
+	}
+
+	txHashes, err := common.VerifyPreconfCommitment(submission.Transactions, preconf.txIndices, preconf.commitment)
+	if err != nil {
+		log.WithError(err).Info("invalid preconfirmation commitment")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePreconfCommitmentMismatch, err.Error())
+		return nil, false
+	}
+	return &common.PreconfCommitment{
+		Slot:          submission.BidTrace.Slot,
+		BlockHash:     submission.BidTrace.BlockHash.String(),
+		BuilderPubkey: submission.BidTrace.BuilderPubkey.String(),
+		Value:         submission.BidTrace.Value.Dec(),
+		Commitment:    preconf.commitment,
+		TxHashes:      txHashes,
+		ReceivedAtMs:  receivedAt.UnixMilli(),
+	}, true
+}
+
+func (api *RelayAPI) handleDataPreconfStatus(w http.ResponseWriter, req *http.Request) {
+	slot, err := strconv.ParseUint(req.URL.Query().Get("slot"), 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+		return
+	}
+
+	commitments, err := api.redis.GetPreconfCommitments(slot)
+	if err != nil {
+		api.log.WithError(err).Error("error getting preconfirmation commitments")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := PreconfStatusResponse{
+		Slot:        slot,
+		Status:      PreconfStatusPending,
+		Commitments: make([]*common.PreconfCommitment, 0, len(commitments)),
+	}
+	for _, commitment := range commitments {
+		response.Commitments = append(response.Commitments, commitment)
+	}
+	sort.Slice(response.Commitments, func(i, j int) bool {
+		return response.Commitments[i].ReceivedAtMs < response.Commitments[j].ReceivedAtMs
+	})
+
+	delivered, err := api.db.GetRecentDeliveredPayloads(database.GetPayloadsFilters{Slot: int64(slot), Limit: 1})
+	if err != nil {
+		api.log.WithError(err).Error("error getting delivered payload")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(delivered) > 0 {
+		response.DeliveredBlockHash = delivered[0].BlockHash
+		response.DeliveredCommitment = commitments[delivered[0].BlockHash]
+		if response.DeliveredCommitment != nil {
+			response.Status = PreconfStatusCommitted
+		} else {
+			response.Status = PreconfStatusUncommitted
+		}
+	}
+	api.RespondOK(w, response)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreconfArgs(t *testing.T) {
+	preconf, err := parsePreconfArgs(url.Values{})
+	require.NoError(t, err)
+	require.Nil(t, preconf)
+
+	_, err = parsePreconfArgs(url.Values{"preconf_tx_indices": {"0"}})
+	require.ErrorIs(t, err, errPreconfArgs)
+
+	commitment := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	preconf, err = parsePreconfArgs(url.Values{"preconf_commitment": {commitment}, "preconf_tx_indices": {"0,3"}})
+	require.NoError(t, err)
+	require.Equal(t, commitment, preconf.commitment.String())
+	require.Equal(t, []int{0, 3}, preconf.txIndices)
+
+	_, err = parsePreconfArgs(url.Values{"preconf_commitment": {"0x01"}, "preconf_tx_indices": {"0"}})
+	require.Error(t, err)
+}
+
+func TestPreconfStatus(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	slot := uint64(10)
+
+	txs := []bellatrix.Transaction{{0x02, 0x01}, {0x02, 0x02}}
+	commitment, _, err := common.ComputePreconfCommitment(txs, []int{1})
+	require.NoError(t, err)
+	submission := &common.BlockSubmissionInfo{
+		BidTrace: &builderApiV1.BidTrace{
+			Slot:      slot,
+			BlockHash: phase0.Hash32{0x01},
+			Value:     uint256.NewInt(100),
+		},
+		Transactions: txs,
+	}
+
+	t.Run("commitment mismatch", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		_, ok := backend.relay.checkSubmissionPreconf(w, common.TestLog, submission, &preconfArgs{commitment: commitment, txIndices: []int{0}}, time.Now())
+		require.False(t, ok)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), string(ErrorCodePreconfCommitmentMismatch))
+	})
+
+	t.Run("valid commitment", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		preconfCommitment, ok := backend.relay.checkSubmissionPreconf(w, common.TestLog, submission, &preconfArgs{commitment: commitment, txIndices: []int{1}}, time.Now())
+		require.True(t, ok)
+		require.Equal(t, commitment, preconfCommitment.Commitment)
+		require.Equal(t, "100", preconfCommitment.Value)
+		require.NoError(t, backend.redis.SavePreconfCommitment(preconfCommitment))
+
+		rr := backend.request(http.MethodGet, fmt.Sprintf("%s?slot=%d", pathDataPreconfStatus, slot), nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := new(PreconfStatusResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, PreconfStatusPending, resp.Status)
+		require.Len(t, resp.Commitments, 1)
+		require.Equal(t, submission.BidTrace.BlockHash.String(), resp.Commitments[0].BlockHash)
+		require.Len(t, resp.Commitments[0].TxHashes, 1)
+	})
+
+	t.Run("no commitment", func(t *testing.T) {
+		preconfCommitment, ok := backend.relay.checkSubmissionPreconf(httptest.NewRecorder(), common.TestLog, submission, nil, time.Now())
+		require.True(t, ok)
+		require.Nil(t, preconfCommitment)
+	})
+}
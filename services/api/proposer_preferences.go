@@ -0,0 +1,247 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ProposerMinBidResponse is the response of the internal proposer min-bid endpoint
+type ProposerMinBidResponse struct {
+	ProposerPubkey string `json:"proposer_pubkey"`
+	MinBidValue    string `json:"min_bid_value"`
+}
+
+// syncProposerPreferences loads the preferences of all proposers (min bid and builder preferences) from the database
+// into Redis, where they are read by getHeader
+func (api *RelayAPI) syncProposerPreferences() error {
+	entries, err := api.db.GetAllProposerPreferences()
+	if err != nil {
+		return err
+	}
+
+	minBids := make(map[string]string, len(entries))
+	builderPreferences := make([]*common.BuilderPreferences, 0, len(entries))
+	for _, entry := range entries {
+		if entry.MinBidValue != "" && entry.MinBidValue != "0" {
+			minBids[entry.ProposerPubkey] = entry.MinBidValue
+		}
+		preferences, err := database.ProposerPreferencesEntryToBuilderPreferences(entry)
+		if err != nil {
+			api.log.WithError(err).WithField("pubkey", entry.ProposerPubkey).Error("invalid proposer builder preferences")
+			continue
+		}
+		if !preferences.IsEmpty() {
+			builderPreferences = append(builderPreferences, preferences)
+		}
+	}
+
+	api.log.WithFields(logrus.Fields{
+		"numMinBids":            len(minBids),
+		"numBuilderPreferences": len(builderPreferences),
+	}).Info("loaded proposer preferences")
+	err = api.redis.SetProposerMinBids(minBids)
+	if err != nil {
+		return err
+	}
+	return api.redis.SetAllProposerBuilderPreferences(builderPreferences)
+}
+
+// getBestBid returns the best bid for the proposer, only considering the builders allowed by the proposer's builder
+// preferences, and skipping a header-only bid whose payload deadline passed. Errors loading the preferences are logged,
+// and the overall best bid is returned to not miss a slot because of a cache problem.
+func (api *RelayAPI) getBestBid(log *logrus.Entry, slot uint64, parentHash, proposerPubkey string) (*builderSpec.VersionedSignedBuilderBid, error) {
+	preferences, err := api.datastore.GetProposerBuilderPreferences(proposerPubkey)
+	if err != nil {
+		log.WithError(err).Error("failed to get proposer builder preferences")
+	}
+	bid, err := api.datastore.GetBestBid(slot, parentHash, proposerPubkey, preferences)
+	if err != nil || bid == nil {
+		return bid, err
+	}
+
+	// A header-only bid is not served once its payload deadline passed, even if it wasn't cancelled yet
+	if api.cancelExpiredHeaderBid(log, slot, bid, time.Now()) {
+		return api.datastore.GetBestBid(slot, parentHash, proposerPubkey, preferences)
+	}
+	return bid, nil
+}
+
+// checkProposerMinBid returns false if the bid value is below the min bid of the proposer. Errors are logged, and the
+// bid is returned to not miss a slot because of a cache problem.
+func (api *RelayAPI) checkProposerMinBid(log *logrus.Entry, proposerPubkey string, value *big.Int) bool {
+	minBid, err := api.datastore.GetProposerMinBid(proposerPubkey)
+	if err != nil {
+		log.WithError(err).Error("failed to get proposer min bid")
+		return true
+	}
+	if minBid != nil && value.Cmp(minBid) < 0 {
+		log.WithFields(logrus.Fields{
+			"value":  value.String(),
+			"minBid": minBid.String(),
+		}).Info("bid below proposer min bid")
+		return false
+	}
+	return true
+}
+
+func (api *RelayAPI) handleInternalProposerMinBid(w http.ResponseWriter, req *http.Request) {
+	proposerPubkey := mux.Vars(req)["pubkey"]
+	if len(proposerPubkey) != 98 {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPubkey, "invalid proposer pubkey")
+		return
+	}
+
+	if req.Method == http.MethodGet {
+		entry, err := api.db.GetProposerPreferences(proposerPubkey)
+		if errors.Is(err, sql.ErrNoRows) {
+			api.RespondOK(w, ProposerMinBidResponse{ProposerPubkey: proposerPubkey, MinBidValue: "0"})
+			return
+		} else if err != nil {
+			api.log.WithError(err).Error("error getting proposer preferences")
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondOK(w, ProposerMinBidResponse{ProposerPubkey: proposerPubkey, MinBidValue: entry.MinBidValue})
+		return
+	}
+
+	minBid, ok := new(big.Int).SetString(req.URL.Query().Get("value"), 10)
+	if !ok || minBid.Sign() < 0 {
+		api.RespondError(w, http.StatusBadRequest, "invalid value argument, must be the min bid value in wei")
+		return
+	}
+
+	log := api.log.WithFields(logrus.Fields{
+		"pubkey": proposerPubkey,
+		"minBid": minBid.String(),
+	})
+	log.Info("updating proposer min bid")
+	if err := api.db.SetProposerMinBid(proposerPubkey, minBid.String()); err != nil {
+		fullErr := fmt.Errorf("unable to set min bid in db for pubkey: %v: %w", proposerPubkey, err)
+		log.Error(fullErr.Error())
+		api.RespondError(w, http.StatusInternalServerError, fullErr.Error())
+		return
+	}
+	if err := api.redis.SetProposerMinBid(proposerPubkey, minBid); err != nil {
+		fullErr := fmt.Errorf("unable to set min bid in redis for pubkey: %v: %w", proposerPubkey, err)
+		log.Error(fullErr.Error())
+		api.RespondError(w, http.StatusInternalServerError, fullErr.Error())
+		return
+	}
+	api.datastore.InvalidateProposerCache(proposerPubkey)
+	api.RespondOK(w, ProposerMinBidResponse{ProposerPubkey: proposerPubkey, MinBidValue: minBid.String()})
+}
+
+func (api *RelayAPI) handleProposerBuilderPreferences(w http.ResponseWriter, req *http.Request) {
+	log := api.log.WithFields(logrus.Fields{
+		"method":    "proposerBuilderPreferences",
+		"requestID": getRequestID(req.Context()),
+	})
+
+	signedPreferences := new(common.SignedBuilderPreferences)
+	if err := json.NewDecoder(req.Body).Decode(signedPreferences); err != nil || signedPreferences.Message == nil {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, "failed to decode builder preferences")
+		return
+	}
+	preferences := signedPreferences.Message
+	pubkey := common.PubkeyHex(preferences.Pubkey.String())
+	log = log.WithFields(logrus.Fields{
+		"pubkey":             pubkey,
+		"timestamp":          preferences.Timestamp,
+		"numAllowedBuilders": len(preferences.AllowedBuilders),
+		"numDeniedBuilders":  len(preferences.DeniedBuilders),
+	})
+
+	if len(preferences.AllowedBuilders) > common.MaxBuilderPreferencesPubkeys || len(preferences.DeniedBuilders) > common.MaxBuilderPreferencesPubkeys {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, fmt.Sprintf("maximum number of builders is %d", common.MaxBuilderPreferencesPubkeys))
+		return
+	}
+
+	if preferences.Timestamp > uint64(time.Now().Unix()+10) {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp too far in the future")
+		return
+	}
+
+	if !api.datastore.IsKnownValidator(pubkey) {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeUnknownValidator, fmt.Sprintf("not a known validator: %s", pubkey))
+		return
+	}
+
+	ok, err := ssz.VerifySignature(preferences, api.opts.EthNetDetails.DomainBuilder, preferences.Pubkey[:], signedPreferences.Signature[:])
+	if err != nil || !ok {
+		log.WithError(err).Info("invalid builder preferences signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "invalid signature")
+		return
+	}
+
+	// Only accept preferences which are newer than the current ones, to prevent replaying old messages
+	entry, err := api.db.GetProposerPreferences(pubkey.String())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).Error("error getting proposer preferences")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if err == nil && preferences.Timestamp <= entry.BuilderPreferencesTimestamp {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp must be newer than the one of the current builder preferences")
+		return
+	}
+
+	log.Info("updating proposer builder preferences")
+	if err := api.db.SetProposerBuilderPreferences(signedPreferences); err != nil {
+		log.WithError(err).Error("failed to save proposer builder preferences")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := api.redis.SetProposerBuilderPreferences(preferences); err != nil {
+		log.WithError(err).Error("failed to cache proposer builder preferences")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.datastore.InvalidateProposerCache(pubkey.String())
+	api.RespondOK(w, NilResponse)
+}
+
+func (api *RelayAPI) handleDataProposerBuilderPreferences(w http.ResponseWriter, req *http.Request) {
+	pubkey := req.URL.Query().Get("pubkey")
+	if len(pubkey) != 98 {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPubkey, "invalid pubkey argument")
+		return
+	}
+
+	entry, err := api.db.GetProposerPreferences(strings.ToLower(pubkey))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && entry.BuilderPreferencesSignature == "") {
+		api.RespondErrorCode(w, http.StatusNotFound, ErrorCodeNotFound, "no builder preferences found")
+		return
+	} else if err != nil {
+		api.log.WithError(err).Error("error getting proposer preferences")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	preferences, err := database.ProposerPreferencesEntryToBuilderPreferences(entry)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	signature, err := hexutil.Decode(entry.BuilderPreferencesSignature)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := common.SignedBuilderPreferences{Message: preferences}
+	copy(response.Signature[:], signature)
+	api.RespondOK(w, response)
+}
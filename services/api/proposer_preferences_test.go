@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposerBuilderPreferences(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.db = database.MockDB{ProposerPreferences: make(map[string]*database.ProposerPreferencesEntry)}
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{
+			GenesisTime: uint64(time.Now().UTC().Unix()),
+		},
+	}
+	backend.relay.forkSchedule = common.ForkVersionSchedule{CapellaEpoch: 0, DenebEpoch: -1, ElectraEpoch: -1}
+
+	// Proposer is a known validator
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	proposerPubkey, err := utils.BlsPublicKeyToPublicKey(blsPubkey)
+	require.NoError(t, err)
+	err = backend.redis.SetKnownValidators(map[uint64]common.PubkeyHex{1: common.PubkeyHex(proposerPubkey.String())}, 1, true)
+	require.NoError(t, err)
+	require.NoError(t, backend.relay.datastore.LoadKnownValidatorsFromRedis(common.TestLog))
+
+	// Bids of two builders, builder2 has the higher bid
+	slot := uint64(2)
+	backend.relay.headSlot.Store(slot)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	builder1 := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	builder2 := "0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4"
+	// (the lower bid first, as bids below the floor value are not saved)
+	for _, bid := range []struct {
+		builder string
+		value   uint64
+	}{{builder1, 100}, {builder2, 200}} {
+		builder := bid.builder
+		bidValue := uint256.NewInt(bid.value)
+		trace := &common.BidTraceV2WithBlobFields{BidTrace: builderApiV1.BidTrace{Value: bidValue}}
+		opts := common.CreateTestBlockSubmissionOpts{
+			Slot:           slot,
+			ParentHash:     parentHash,
+			ProposerPubkey: proposerPubkey.String(),
+			Version:        spec.DataVersionCapella,
+		}
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builder, bidValue, &opts)
+		_, err = backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+		require.NoError(t, err)
+	}
+
+	getHeaderValue := func() string {
+		path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey.String())
+		rr := backend.request(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := builderSpec.VersionedSignedBuilderBid{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		return value.String()
+	}
+	require.Equal(t, "200", getHeaderValue())
+
+	// Deny builder2
+	builder2Pubkey, err := common.StrToPhase0Pubkey(builder2)
+	require.NoError(t, err)
+	preferences := &common.BuilderPreferences{
+		Pubkey:         proposerPubkey,
+		Timestamp:      uint64(time.Now().Unix()),
+		DeniedBuilders: []phase0.BLSPubKey{builder2Pubkey},
+	}
+	signature, err := ssz.SignMessage(preferences, backend.relay.opts.EthNetDetails.DomainBuilder, sk)
+	require.NoError(t, err)
+	signedPreferences := &common.SignedBuilderPreferences{Message: preferences, Signature: signature}
+
+	rr := backend.request(http.MethodPost, pathProposerBuilderPreferences, signedPreferences)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, "100", getHeaderValue())
+
+	// Preferences are public, with the signature
+	rr = backend.request(http.MethodGet, pathDataBuilderPreferences+"?pubkey="+proposerPubkey.String(), nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(common.SignedBuilderPreferences)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, preferences.Timestamp, resp.Message.Timestamp)
+	require.Equal(t, preferences.DeniedBuilders, resp.Message.DeniedBuilders)
+	require.Empty(t, resp.Message.AllowedBuilders)
+	require.Equal(t, signature, resp.Signature)
+
+	// Replayed preferences are rejected
+	rr = backend.request(http.MethodPost, pathProposerBuilderPreferences, signedPreferences)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Invalid signature
+	preferences2 := &common.BuilderPreferences{Pubkey: proposerPubkey, Timestamp: preferences.Timestamp + 1}
+	rr = backend.request(http.MethodPost, pathProposerBuilderPreferences, &common.SignedBuilderPreferences{Message: preferences2, Signature: signature})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Empty preferences allow all builders again
+	signature, err = ssz.SignMessage(preferences2, backend.relay.opts.EthNetDetails.DomainBuilder, sk)
+	require.NoError(t, err)
+	rr = backend.request(http.MethodPost, pathProposerBuilderPreferences, &common.SignedBuilderPreferences{Message: preferences2, Signature: signature})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "200", getHeaderValue())
+}
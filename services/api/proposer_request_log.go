@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/database"
+)
+
+var (
+	// size of the queue of proposer requests waiting to be written to the database
+	proposerRequestLogQueueSize = cli.GetEnvInt("PROPOSER_REQUEST_LOG_QUEUE_SIZE", 10_000)
+
+	// maximum number of proposer requests written to the database at once
+	proposerRequestLogBatchSize = cli.GetEnvInt("PROPOSER_REQUEST_LOG_BATCH_SIZE", 100)
+
+	// interval to write the queued proposer requests to the database, if the batch is not full
+	proposerRequestLogFlushInterval = time.Duration(cli.GetEnvInt("PROPOSER_REQUEST_LOG_FLUSH_MS", 1000)) * time.Millisecond
+)
+
+// proposerResponseWriter records the status code of a getHeader or getPayload response, for the proposer request log
+type proposerResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *proposerResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (api *RelayAPI) isProposerRequestLogEnabled() bool {
+	return api.proposerRequestLogC != nil
+}
+
+// newProposerRequestEntry returns the proposer request log entry of a request, to be completed by the handler
+func newProposerRequestEntry(req *http.Request, method string, receivedAt time.Time) *database.ProposerRequestEntry {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	return &database.ProposerRequestEntry{
+		ReceivedAt:   receivedAt,
+		Method:       method,
+		RequestID:    getRequestID(req.Context()),
+		IP:           ip,
+		ForwardedFor: req.Header.Get("X-Forwarded-For"),
+		UserAgent:    req.UserAgent(),
+	}
+}
+
+// logProposerRequest queues a proposer request for the proposer request log, with the status code recorded by the
+// response writer
+func (api *RelayAPI) logProposerRequest(w *proposerResponseWriter, entry *database.ProposerRequestEntry) {
+	entry.StatusCode = w.statusCode
+	entry.DurationMs = time.Since(entry.ReceivedAt).Milliseconds()
+
+	select {
+	case api.proposerRequestLogC <- entry:
+	default:
+		api.log.Error("proposer request log channel full")
+	}
+}
+
+// startProposerRequestLogDBProcessor writes the queued proposer requests to the database in batches, until the
+// channel is closed
+func (api *RelayAPI) startProposerRequestLogDBProcessor() {
+	defer api.proposerRequestLogWG.Done()
+	ticker := time.NewTicker(proposerRequestLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*database.ProposerRequestEntry, 0, proposerRequestLogBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := api.db.InsertProposerRequests(batch); err != nil {
+			api.log.WithError(err).WithField("numRequests", len(batch)).Error("error saving proposer requests to database")
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-api.proposerRequestLogC:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= proposerRequestLogBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+// proposerRequestsDB records the batches of saved proposer requests
+type proposerRequestsDB struct {
+	database.MockDB
+	mu      sync.Mutex
+	batches [][]*database.ProposerRequestEntry
+}
+
+func (db *proposerRequestsDB) InsertProposerRequests(entries []*database.ProposerRequestEntry) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.batches = append(db.batches, append([]*database.ProposerRequestEntry{}, entries...))
+	return nil
+}
+
+func TestLogProposerRequest(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.proposerRequestLogC = make(chan *database.ProposerRequestEntry, 10)
+
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, testBuilderPubkey)
+	rr := backend.requestBytes(http.MethodGet, path, nil, map[string]string{"User-Agent": "mev-boost/v1.7", "X-Forwarded-For": "1.2.3.4"})
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	entry := <-backend.relay.proposerRequestLogC
+	require.Equal(t, "getHeader", entry.Method)
+	require.Equal(t, slot, entry.Slot)
+	require.Equal(t, testBuilderPubkey, entry.ProposerPubkey)
+	require.Equal(t, parentHash, entry.ParentHash)
+	require.Equal(t, "mev-boost/v1.7", entry.UserAgent)
+	require.Equal(t, "1.2.3.4", entry.ForwardedFor)
+	require.Equal(t, http.StatusNoContent, entry.StatusCode)
+	require.Empty(t, entry.BlockHash)
+
+	// Requests with an invalid pubkey are logged with the error status
+	rr = backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/0x01", slot, parentHash), nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	entry = <-backend.relay.proposerRequestLogC
+	require.Equal(t, http.StatusBadRequest, entry.StatusCode)
+}
+
+func TestProposerRequestLogDBProcessor(t *testing.T) {
+	defer func(batchSize int) { proposerRequestLogBatchSize = batchSize }(proposerRequestLogBatchSize)
+	proposerRequestLogBatchSize = 2
+
+	db := &proposerRequestsDB{}
+	api := &RelayAPI{log: common.TestLog, db: db, proposerRequestLogC: make(chan *database.ProposerRequestEntry, 10)}
+	for slot := uint64(1); slot <= 3; slot++ {
+		api.proposerRequestLogC <- &database.ProposerRequestEntry{Slot: slot}
+	}
+
+	// Full batches are saved right away, the rest when the channel is closed
+	api.proposerRequestLogWG.Add(1)
+	go api.startProposerRequestLogDBProcessor()
+	close(api.proposerRequestLogC)
+	api.proposerRequestLogWG.Wait()
+
+	require.Len(t, db.batches, 2)
+	require.Len(t, db.batches[0], 2)
+	require.Equal(t, uint64(3), db.batches[1][0].Slot)
+}
+
+func TestDataProposerRequests(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	rr := backend.request(http.MethodGet, pathDataProposerRequests, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "missing slot argument")
+
+	rr = backend.request(http.MethodGet, pathDataProposerRequests+"?slot=foo", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodGet, pathDataProposerRequests+"?slot=2", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var entries []*database.ProposerRequestEntry
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	require.Empty(t, entries)
+
+	// The IPs of proposers are not served
+	resp, err := json.Marshal(&database.ProposerRequestEntry{IP: "1.2.3.4", ForwardedFor: "5.6.7.8"})
+	require.NoError(t, err)
+	require.NotContains(t, string(resp), "1.2.3.4")
+	require.NotContains(t, string(resp), "5.6.7.8")
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/gorilla/mux"
+)
+
+// queuedValidatorRegistration is a registration to be saved, with the IP it was submitted from to record fee
+// recipient changes
+type queuedValidatorRegistration struct {
+	registration builderApiV1.SignedValidatorRegistration
+	sourceIP     string
+}
+
+// RegistrationHistoryJSON is a fee recipient change of a validator registration
+type RegistrationHistoryJSON struct {
+	Pubkey          string `json:"pubkey"`
+	OldFeeRecipient string `json:"old_fee_recipient"`
+	NewFeeRecipient string `json:"new_fee_recipient"`
+	Timestamp       uint64 `json:"timestamp,string"`
+	SourceIP        string `json:"source_ip"`
+	RecordedAt      string `json:"recorded_at"`
+}
+
+// handleInternalRegistrationHistory returns the fee recipient changes of a validator, most recent first, to
+// investigate suspected fee recipient hijacks
+func (api *RelayAPI) handleInternalRegistrationHistory(w http.ResponseWriter, req *http.Request) {
+	pubkey := strings.ToLower(mux.Vars(req)["pubkey"])
+
+	limit := uint64(100)
+	if args := req.URL.Query(); args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if _limit > limit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", limit))
+			return
+		}
+		limit = _limit
+	}
+
+	entries, err := api.db.GetValidatorRegistrationHistory(pubkey, limit)
+	if err != nil {
+		api.log.WithError(err).Error("error getting validator registration history")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]RegistrationHistoryJSON, len(entries))
+	for i, entry := range entries {
+		response[i] = RegistrationHistoryJSON{
+			Pubkey:          entry.Pubkey,
+			OldFeeRecipient: entry.OldFeeRecipient,
+			NewFeeRecipient: entry.NewFeeRecipient,
+			Timestamp:       entry.Timestamp,
+			SourceIP:        entry.SourceIP,
+			RecordedAt:      entry.InsertedAt.UTC().Format(time.RFC3339),
+		}
+	}
+	api.RespondOK(w, response)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"crypto/sha256"
+	"errors"
+	"runtime"
+	"sync"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-utils/cli"
+)
+
+var (
+	ErrInvalidRegistrationSignature = errors.New("invalid validator registration signature")
+
+	numRegistrationVerifyWorkers = cli.GetEnvInt("NUM_REGISTRATION_VERIFY_WORKERS", runtime.NumCPU())
+)
+
+// RegistrationVerifier verifies validator registration signatures concurrently with a pool of workers. It remembers
+// the last successfully verified registration of each validator, so unchanged registrations are not verified again.
+type RegistrationVerifier struct {
+	domain     phase0.Domain
+	numWorkers int
+
+	// validator pubkey -> hash of the last verified registration
+	verified sync.Map
+}
+
+func NewRegistrationVerifier(domain phase0.Domain) *RegistrationVerifier {
+	numWorkers := numRegistrationVerifyWorkers
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	return &RegistrationVerifier{
+		domain:     domain,
+		numWorkers: numWorkers,
+	}
+}
+
+// Verify checks the signatures of all registrations, and returns a slice with an error for each registration (nil if the
+// signature is valid). ErrInvalidRegistrationSignature is returned for registrations with an invalid signature.
+func (v *RegistrationVerifier) Verify(registrations []*builderApiV1.SignedValidatorRegistration) []error {
+	errs := make([]error, len(registrations))
+
+	numWorkers := v.numWorkers
+	if numWorkers > len(registrations) {
+		numWorkers = len(registrations)
+	}
+
+	jobC := make(chan int, len(registrations))
+	for i := range registrations {
+		jobC <- i
+	}
+	close(jobC)
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobC {
+				errs[i] = v.verifyOne(registrations[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func (v *RegistrationVerifier) verifyOne(registration *builderApiV1.SignedValidatorRegistration) error {
+	msgRoot, err := registration.Message.HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	regHash := sha256.Sum256(append(msgRoot[:], registration.Signature[:]...))
+
+	// Skip verification if exactly this registration was verified before
+	if prev, ok := v.verified.Load(registration.Message.Pubkey); ok {
+		if prevHash, ok := prev.([32]byte); ok && prevHash == regHash {
+			return nil
+		}
+	}
+
+	ok, err := ssz.VerifySignature(registration.Message, v.domain, registration.Message.Pubkey[:], registration.Signature[:])
+	if err != nil {
+		return err
+	} else if !ok {
+		return ErrInvalidRegistrationSignature
+	}
+
+	v.verified.Store(registration.Message.Pubkey, regHash)
+	return nil
+}
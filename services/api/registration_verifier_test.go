@@ -0,0 +1,67 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/stretchr/testify/require"
+)
+
+func signedTestRegistration(t *testing.T, gasLimit uint64) *builderApiV1.SignedValidatorRegistration {
+	t.Helper()
+	sk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	blsPubkey, err := bls.PublicKeyFromSecretKey(sk)
+	require.NoError(t, err)
+
+	msg := &builderApiV1.ValidatorRegistration{
+		FeeRecipient: testAddress,
+		GasLimit:     gasLimit,
+		Timestamp:    time.Unix(1606824043, 0),
+	}
+	msg.Pubkey = blsPubkey.Bytes()
+
+	sig, err := ssz.SignMessage(msg, ssz.DomainBuilder, sk)
+	require.NoError(t, err)
+	return &builderApiV1.SignedValidatorRegistration{
+		Message:   msg,
+		Signature: sig,
+	}
+}
+
+func TestRegistrationVerifier(t *testing.T) {
+	v := NewRegistrationVerifier(ssz.DomainBuilder)
+
+	regs := make([]*builderApiV1.SignedValidatorRegistration, 20)
+	for i := range regs {
+		regs[i] = signedTestRegistration(t, 30_000_000)
+	}
+
+	// Tamper with one of the registrations
+	regs[7].Message.GasLimit = 1
+
+	errs := v.Verify(regs)
+	require.Len(t, errs, len(regs))
+	for i, err := range errs {
+		if i == 7 {
+			require.ErrorIs(t, err, ErrInvalidRegistrationSignature)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	// A valid registration is cached, and not verified again if unchanged
+	_, ok := v.verified.Load(regs[0].Message.Pubkey)
+	require.True(t, ok)
+	_, ok = v.verified.Load(regs[7].Message.Pubkey)
+	require.False(t, ok)
+
+	// A changed registration with the same pubkey is verified again
+	regs[0].Signature = phase0.BLSSignature{}
+	errs = v.Verify(regs[:1])
+	require.Error(t, errs[0])
+}
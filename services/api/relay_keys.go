@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/datastore"
+)
+
+// RelayPubkeysResponse is the response of the relay pubkeys endpoint: the key which signs bids, and the previous keys
+// of an ongoing rotation
+type RelayPubkeysResponse struct {
+	Active   string   `json:"active"`
+	Previous []string `json:"previous"`
+}
+
+func publicKeyFromSecretKey(sk *bls.SecretKey) (phase0.BLSPubKey, error) {
+	blsPubkey, err := bls.PublicKeyFromSecretKey(sk)
+	if err != nil {
+		return phase0.BLSPubKey{}, err
+	}
+	return utils.BlsPublicKeyToPublicKey(blsPubkey)
+}
+
+// checkRelayPubkey ensures the pubkey is the same across all relay instances. During a key rotation, instances with the
+// new key start while others still use the old one: the pubkey in Redis may then be any of the old keys, and is
+// switched to the new key.
+func checkRelayPubkey(redis *datastore.RedisCache, publicKey phase0.BLSPubKey, oldPublicKeys []phase0.BLSPubKey) error {
+	_pubkey, err := redis.GetRelayConfig(datastore.RedisConfigFieldPubkey)
+	if err != nil {
+		return err
+	}
+	if _pubkey == publicKey.String() {
+		return nil
+	}
+
+	isKnown := _pubkey == ""
+	for _, oldPublicKey := range oldPublicKeys {
+		isKnown = isKnown || _pubkey == oldPublicKey.String()
+	}
+	if !isKnown {
+		return fmt.Errorf("%w: new=%s old=%s", ErrRelayPubkeyMismatch, publicKey.String(), _pubkey)
+	}
+	return redis.SetRelayConfig(datastore.RedisConfigFieldPubkey, publicKey.String())
+}
+
+func (api *RelayAPI) handleRelayPubkeys(w http.ResponseWriter, req *http.Request) {
+	resp := RelayPubkeysResponse{
+		Active:   api.publicKey.String(),
+		Previous: make([]string, len(api.oldPublicKeys)),
+	}
+	for i, oldPublicKey := range api.oldPublicKeys {
+		resp.Previous[i] = oldPublicKey.String()
+	}
+	api.RespondOK(w, resp)
+}
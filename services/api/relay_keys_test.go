@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRelayPubkey(t *testing.T) {
+	redisClient, err := miniredis.Run()
+	require.NoError(t, err)
+	redisCache, err := datastore.NewRedisCache("", redisClient.Addr(), "")
+	require.NoError(t, err)
+
+	oldPubkey := phase0.BLSPubKey{1}
+	newPubkey := phase0.BLSPubKey{2}
+
+	// first instance sets the pubkey
+	require.NoError(t, checkRelayPubkey(redisCache, oldPubkey, nil))
+	require.ErrorIs(t, checkRelayPubkey(redisCache, newPubkey, nil), ErrRelayPubkeyMismatch)
+
+	// rotation: the old key is accepted, and the pubkey switched to the new one
+	require.NoError(t, checkRelayPubkey(redisCache, newPubkey, []phase0.BLSPubKey{oldPubkey}))
+	pubkey, err := redisCache.GetRelayConfig(datastore.RedisConfigFieldPubkey)
+	require.NoError(t, err)
+	require.Equal(t, newPubkey.String(), pubkey)
+
+	require.ErrorIs(t, checkRelayPubkey(redisCache, oldPubkey, nil), ErrRelayPubkeyMismatch)
+}
+
+func TestRelayPubkeys(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.oldPublicKeys = []phase0.BLSPubKey{{1}}
+
+	rr := backend.request(http.MethodGet, pathRelayPubkeys, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(RelayPubkeysResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, backend.relay.publicKey.String(), resp.Active)
+	require.Equal(t, []string{phase0.BLSPubKey{1}.String()}, resp.Previous)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// RelayStatusResponse is the response of the relay status endpoint, for builders and proposers to discover the
+// capabilities of the relay
+type RelayStatusResponse struct {
+	Version      string            `json:"version"`
+	GitCommit    string            `json:"git_commit"`
+	Network      string            `json:"network"`
+	RelayPubkey  string            `json:"relay_pubkey,omitempty"`
+	RelayMode    string            `json:"relay_mode"`
+	HeadSlot     uint64            `json:"head_slot,string"`
+	Capabilities RelayCapabilities `json:"capabilities"`
+	Blocklist    *BlocklistStatus  `json:"blocklist,omitempty"`
+}
+
+// RelayCapabilities are the enabled APIs and features of the relay
+type RelayCapabilities struct {
+	ProposerAPI   bool `json:"proposer_api"`
+	BuilderAPI    bool `json:"builder_api"`
+	DataAPI       bool `json:"data_api"`
+	Cancellations bool `json:"cancellations"`
+	Optimistic    bool `json:"optimistic"`
+	SSZ           bool `json:"ssz"`
+	Deneb         bool `json:"deneb"`
+	Electra       bool `json:"electra"`
+}
+
+// gitCommit returns the VCS revision the binary was built from, or "unknown" if it was built without VCS info
+func gitCommit() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	commit := "unknown"
+	dirty := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			commit = setting.Value
+		case "vcs.modified":
+			dirty = setting.Value == "true"
+		}
+	}
+	if dirty {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+func (api *RelayAPI) handleRelayStatus(w http.ResponseWriter, req *http.Request) {
+	headSlot := api.headSlot.Load()
+	resp := RelayStatusResponse{
+		Version:   api.opts.Version,
+		GitCommit: api.gitCommit,
+		Network:   api.opts.EthNetDetails.Name,
+		RelayMode: api.opts.RelayMode,
+		HeadSlot:  headSlot,
+		Capabilities: RelayCapabilities{
+			ProposerAPI:   api.opts.ProposerAPI,
+			BuilderAPI:    api.opts.BlockBuilderAPI,
+			DataAPI:       api.opts.DataAPI,
+			Cancellations: api.opts.BlockBuilderAPI && api.ffEnableCancellations.Load(),
+			Optimistic:    api.opts.BlockBuilderAPI,
+			SSZ:           api.opts.BlockBuilderAPI,
+			Deneb:         hasReachedFork(headSlot, api.forkSchedule.DenebEpoch),
+			Electra:       hasReachedFork(headSlot, api.forkSchedule.ElectraEpoch),
+		},
+	}
+	if api.blsSk != nil {
+		resp.RelayPubkey = api.publicKey.String()
+	}
+	if api.blocklist != nil {
+		blocklistStatus := api.blocklist.Status()
+		resp.Blocklist = &blocklistStatus
+	}
+	api.RespondOK(w, resp)
+}
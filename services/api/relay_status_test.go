@@ -0,0 +1,30 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayStatus(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.Version = "v1.2.3"
+	backend.relay.ffEnableCancellations.Store(true)
+	backend.relay.forkSchedule.DenebEpoch = 1
+	backend.relay.headSlot.Store(32)
+
+	rr := backend.request(http.MethodGet, pathRelayStatus, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(RelayStatusResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, "v1.2.3", resp.Version)
+	require.Equal(t, backend.relay.opts.EthNetDetails.Name, resp.Network)
+	require.Equal(t, backend.relay.publicKey.String(), resp.RelayPubkey)
+	require.Equal(t, uint64(32), resp.HeadSlot)
+	require.True(t, resp.Capabilities.BuilderAPI)
+	require.True(t, resp.Capabilities.Cancellations)
+	require.True(t, resp.Capabilities.Deneb)
+	require.False(t, resp.Capabilities.Electra)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// HeaderRequestID is the header with the request ID, which is also returned in error responses so that builders
+// and proposers can quote it when reporting issues
+const HeaderRequestID = "X-Request-ID"
+
+// HeaderRelayRequestID is the header with the request ID of the originating request, sent to the block simulation node
+const HeaderRelayRequestID = "X-Relay-Request-ID"
+
+// incoming request IDs are reused if they look sane
+var reValidRequestID = regexp.MustCompile(`^[a-zA-Z0-9_.:/-]{1,64}$`)
+
+type requestIDContextKey struct{}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func contextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// getRequestID returns the request ID of the context, or an empty string if there is none
+func getRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// requestIDMiddleware assigns a request ID to every request (or uses the one sent by the client), and sets it as
+// response header and in the request context
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestID := req.Header.Get(HeaderRequestID)
+		if !reValidRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+		next.ServeHTTP(w, req.WithContext(contextWithRequestID(req.Context(), requestID)))
+	})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// requestLimits are the limits of the requests to an endpoint, on top of the server-wide timeouts and header size.
+// Zero values mean no endpoint-specific limit.
+type requestLimits struct {
+	endpoint       string
+	maxBodyBytes   int64
+	readTimeout    time.Duration // time to read the body, replacing API_TIMEOUT_READ_MS
+	writeTimeout   time.Duration // time to handle the request and write the response, replacing API_TIMEOUT_WRITE_MS
+	maxHeaderBytes int           // can only be lower than API_MAX_HEADER_BYTES
+}
+
+// requestLimitsFromEnv returns the limits of an endpoint from the environment variables with the given prefix, i.e.
+// API_SUBMIT_BLOCK_MAX_BODY_BYTES
+func requestLimitsFromEnv(endpoint, envPrefix string, maxBodyBytes int) requestLimits {
+	return requestLimits{
+		endpoint:       endpoint,
+		maxBodyBytes:   int64(cli.GetEnvInt(envPrefix+"_MAX_BODY_BYTES", maxBodyBytes)),
+		readTimeout:    time.Duration(cli.GetEnvInt(envPrefix+"_TIMEOUT_READ_MS", 0)) * time.Millisecond,
+		writeTimeout:   time.Duration(cli.GetEnvInt(envPrefix+"_TIMEOUT_WRITE_MS", 0)) * time.Millisecond,
+		maxHeaderBytes: cli.GetEnvInt(envPrefix+"_MAX_HEADER_BYTES", 0),
+	}
+}
+
+var (
+	submitBlockLimits       = requestLimitsFromEnv("submitBlock", "API_SUBMIT_BLOCK", 10*1024*1024)
+	registerValidatorLimits = requestLimitsFromEnv("registerValidator", "API_REGISTER_VALIDATOR", 64*1024*1024)
+	getPayloadLimits        = requestLimitsFromEnv("getPayload", "API_GET_PAYLOAD", 1024*1024)
+	getHeaderLimits         = requestLimitsFromEnv("getHeader", "API_GET_HEADER", 0)
+)
+
+// pathPrefixGetHeader is the path of getHeader up to the slot, parent hash and pubkey
+var pathPrefixGetHeader = pathGetHeader[:strings.Index(pathGetHeader, "{")]
+
+// getRequestLimits returns the limits of the endpoint of a request, or nil for endpoints without specific limits
+func getRequestLimits(req *http.Request) *requestLimits {
+	switch {
+	case req.URL.Path == pathSubmitNewBlock:
+		return &submitBlockLimits
+	case req.URL.Path == pathRegisterValidator:
+		return &registerValidatorLimits
+	case req.URL.Path == pathGetPayload:
+		return &getPayloadLimits
+	case strings.HasPrefix(req.URL.Path, pathPrefixGetHeader):
+		return &getHeaderLimits
+	}
+	return nil
+}
+
+// requestHeaderBytes returns the size of the request headers, as counted against MaxHeaderBytes by the http server
+func requestHeaderBytes(req *http.Request) int {
+	size := len(req.Method) + len(req.RequestURI) + len(req.Proto) + 4
+	for name, values := range req.Header {
+		for _, value := range values {
+			size += len(name) + len(value) + 4
+		}
+	}
+	return size
+}
+
+// requestLimitsMiddleware enforces the limits of the endpoint of a request. Requests whose headers or announced body
+// are too large are rejected right away, larger bodies without a content length fail while being read. The deadlines
+// are set on the connection, so it must not be wrapped by other middlewares.
+func (api *RelayAPI) requestLimitsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		limits := getRequestLimits(req)
+		if limits == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if limits.maxHeaderBytes > 0 && requestHeaderBytes(req) > limits.maxHeaderBytes {
+			api.incRequestLimitStats(datastore.RedisStatsFieldRequestsTooLarge, limits.endpoint)
+			api.RespondErrorCode(w, http.StatusRequestHeaderFieldsTooLarge, ErrorCodeHeadersTooLarge, fmt.Sprintf("request headers larger than %d bytes", limits.maxHeaderBytes))
+			return
+		}
+		if limits.maxBodyBytes > 0 {
+			if req.ContentLength > limits.maxBodyBytes {
+				api.incRequestLimitStats(datastore.RedisStatsFieldRequestsTooLarge, limits.endpoint)
+				api.RespondErrorCode(w, http.StatusRequestEntityTooLarge, ErrorCodePayloadTooLarge, fmt.Sprintf("request body larger than %d bytes", limits.maxBodyBytes))
+				return
+			}
+			req.Body = http.MaxBytesReader(w, req.Body, limits.maxBodyBytes)
+		}
+
+		rc := http.NewResponseController(w)
+		if limits.readTimeout > 0 {
+			if err := rc.SetReadDeadline(time.Now().Add(limits.readTimeout)); err != nil {
+				api.log.WithError(err).WithField("endpoint", limits.endpoint).Warn("could not set read deadline")
+			}
+		}
+		if limits.writeTimeout > 0 {
+			if err := rc.SetWriteDeadline(time.Now().Add(limits.writeTimeout)); err != nil {
+				api.log.WithError(err).WithField("endpoint", limits.endpoint).Warn("could not set write deadline")
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// respondBodyReadError responds to a request whose body could not be read: with 413 if it exceeded the limit of the
+// endpoint, with 408 if it wasn't read before the deadline, and with 400 otherwise
+func (api *RelayAPI) respondBodyReadError(w http.ResponseWriter, log *logrus.Entry, req *http.Request, err error) {
+	endpoint := ""
+	if limits := getRequestLimits(req); limits != nil {
+		endpoint = limits.endpoint
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr) || errors.Is(err, ErrSubmissionTooLarge):
+		log.WithError(err).Info("request body too large")
+		api.incRequestLimitStats(datastore.RedisStatsFieldRequestsTooLarge, endpoint)
+		api.RespondErrorCode(w, http.StatusRequestEntityTooLarge, ErrorCodePayloadTooLarge, err.Error())
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		log.WithError(err).Warn("request body read timeout")
+		api.incRequestLimitStats(datastore.RedisStatsFieldRequestTimeouts, endpoint)
+		api.RespondErrorCode(w, http.StatusRequestTimeout, ErrorCodeRequestTimeout, "timeout reading request body")
+	default:
+		log.WithError(err).WithField("contentLength", req.ContentLength).Warn("failed to read request body")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+	}
+}
+
+// incRequestLimitStats counts a request rejected by the limits of its endpoint, in the stats field of the endpoint
+func (api *RelayAPI) incRequestLimitStats(field, endpoint string) {
+	if endpoint != "" {
+		field += ":" + endpoint
+	}
+	if err := api.redis.IncStats(field, 1); err != nil {
+		api.log.WithError(err).Error("failed to increment request limit stats")
+	}
+}
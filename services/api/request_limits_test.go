@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRequestLimits(t *testing.T) {
+	for path, expected := range map[string]*requestLimits{
+		pathSubmitNewBlock:                   &submitBlockLimits,
+		pathRegisterValidator:                &registerValidatorLimits,
+		pathGetPayload:                       &getPayloadLimits,
+		"/eth/v1/builder/header/1/0x01/0x02": &getHeaderLimits,
+		pathStatus:                           nil,
+	} {
+		req, err := http.NewRequest(http.MethodPost, path, nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, getRequestLimits(req), path)
+	}
+}
+
+func TestRequestLimits(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	prevGetPayloadLimits, prevGetHeaderLimits := getPayloadLimits, getHeaderLimits
+	t.Cleanup(func() {
+		getPayloadLimits, getHeaderLimits = prevGetPayloadLimits, prevGetHeaderLimits
+	})
+	getPayloadLimits.maxBodyBytes = 10
+	getHeaderLimits.maxHeaderBytes = 100
+
+	t.Run("body larger than the content length limit", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodPost, pathGetPayload, bytes.Repeat([]byte("a"), 20), nil)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		require.Contains(t, rr.Body.String(), string(ErrorCodePayloadTooLarge))
+	})
+
+	t.Run("body without content length exceeding the limit while read", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, pathGetPayload, io.NopCloser(strings.NewReader(strings.Repeat("a", 20))))
+		require.NoError(t, err)
+		require.Equal(t, int64(0), req.ContentLength)
+		rr := httptest.NewRecorder()
+		backend.relay.getRouter().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("headers larger than the limit", func(t *testing.T) {
+		path := "/eth/v1/builder/header/1/0x01/0x02"
+		rr := backend.requestBytes(http.MethodGet, path, nil, map[string]string{"X-Large": strings.Repeat("a", 100)})
+		require.Equal(t, http.StatusRequestHeaderFieldsTooLarge, rr.Code)
+		require.Contains(t, rr.Body.String(), string(ErrorCodeHeadersTooLarge))
+	})
+
+	numTooLarge, err := backend.redis.GetStatsUint64(fmt.Sprintf("%s:%s", datastore.RedisStatsFieldRequestsTooLarge, "getPayload"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), numTooLarge)
+
+	t.Run("body read timeout", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, pathGetPayload, nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		backend.relay.respondBodyReadError(rr, backend.relay.log, req, fmt.Errorf("read: %w", os.ErrDeadlineExceeded))
+		require.Equal(t, http.StatusRequestTimeout, rr.Code)
+		require.Contains(t, rr.Body.String(), string(ErrorCodeRequestTimeout))
+	})
+}
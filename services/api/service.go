@@ -2,14 +2,15 @@
 package api
 
 import (
-	"bytes"
 	"compress/gzip"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"io"
 	"math/big"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -22,11 +23,12 @@ import (
 	"github.com/NYTimes/gziphandler"
 	builderApi "github.com/attestantio/go-builder-client/api"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/buger/jsonparser"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/flashbots/go-boost-utils/bls"
-	"github.com/flashbots/go-boost-utils/ssz"
 	"github.com/flashbots/go-boost-utils/utils"
 	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/go-utils/httplogger"
@@ -34,13 +36,17 @@ import (
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/flashbots/mev-boost-relay/database"
 	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/flashbots/mev-boost-relay/decoder"
+	"github.com/flashbots/mev-boost-relay/events"
 	"github.com/go-redis/redis/v9"
 	"github.com/gorilla/mux"
 	"github.com/holiman/uint256"
 	"github.com/pkg/errors"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/sirupsen/logrus"
 	uberatomic "go.uber.org/atomic"
 	"golang.org/x/exp/slices"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -49,14 +55,22 @@ const (
 	ErrMissingTrieNode    = "missing trie node"
 )
 
+const (
+	// data API aggregation of delivered payloads per builder operator, over at most a week of slots
+	dataAggregateBuilderOperator = "builder_operator"
+	dataAggregateMaxPayloads     = 7 * 7200
+)
+
 var (
-	ErrMissingLogOpt              = errors.New("log parameter is nil")
-	ErrMissingBeaconClientOpt     = errors.New("beacon-client is nil")
-	ErrMissingDatastoreOpt        = errors.New("proposer datastore is nil")
-	ErrRelayPubkeyMismatch        = errors.New("relay pubkey does not match existing one")
-	ErrServerAlreadyStarted       = errors.New("server was already started")
-	ErrBuilderAPIWithoutSecretKey = errors.New("cannot start builder API without secret key")
-	ErrNegativeTimestamp          = errors.New("timestamp cannot be negative")
+	ErrMissingLogOpt                  = errors.New("log parameter is nil")
+	ErrMissingBeaconClientOpt         = errors.New("beacon-client is nil")
+	ErrMissingDatastoreOpt            = errors.New("proposer datastore is nil")
+	ErrRelayPubkeyMismatch            = errors.New("relay pubkey does not match existing one")
+	ErrServerAlreadyStarted           = errors.New("server was already started")
+	ErrBuilderAPIWithoutSecretKey     = errors.New("cannot start builder API without secret key")
+	ErrBlockVersionMismatch           = errors.New("block version does not match the fork of the slot")
+	ErrBlockRejectedOnPublish         = errors.New("block rejected by beacon node on publishing")
+	ErrUpstreamRelaysWithoutSecretKey = errors.New("cannot use upstream relays without secret key")
 )
 
 var (
@@ -66,27 +80,66 @@ var (
 	pathGetHeader         = "/eth/v1/builder/header/{slot:[0-9]+}/{parent_hash:0x[a-fA-F0-9]+}/{pubkey:0x[a-fA-F0-9]+}"
 	pathGetPayload        = "/eth/v1/builder/blinded_blocks"
 
+	// Relay status (build info, network and capabilities)
+	pathRelayStatus = "/relay/v1/status"
+
+	// Proposer preferences
+	pathProposerBuilderPreferences = "/relay/v1/proposer/builder_preferences"
+	pathProposerConstraints        = "/relay/v1/proposer/constraints"
+
 	// Block builder API
 	pathBuilderGetValidators = "/relay/v1/builder/validators"
 	pathSubmitNewBlock       = "/relay/v1/builder/blocks"
+	pathRelayPubkeys         = "/relay/v1/builder/relay_pubkeys"
+	pathBuilderConstraints   = "/relay/v1/builder/constraints"
+	pathSubmitHeader         = "/relay/v1/builder/headers"
+	pathBuilderBidFeedback   = "/relay/v1/builder/bid_feedback"
 
 	// Data API
 	pathDataProposerPayloadDelivered = "/relay/v1/data/bidtraces/proposer_payload_delivered"
 	pathDataBuilderBidsReceived      = "/relay/v1/data/bidtraces/builder_blocks_received"
 	pathDataValidatorRegistration    = "/relay/v1/data/validator_registration"
+	pathDataBids                     = "/relay/v1/data/bids"
+	pathDataPaymentVerification      = "/relay/v1/data/payment_verification"
+	pathDataSlotSummary              = "/relay/v1/data/slot_summary"
+	pathDataEpochSummary             = "/relay/v1/data/epoch_summary"
+	pathDataBuilderPreferences       = "/relay/v1/data/builder_preferences"
+	pathDataPreconfStatus            = "/relay/v1/data/preconf_status"
+	pathDataProposerRequests         = "/relay/v1/data/proposer_requests"
+
+	// Data API v2, with the same query arguments as v1
+	pathDataV2ProposerPayloadDelivered = "/relay/v2/data/bidtraces/proposer_payload_delivered"
+	pathDataV2BuilderBidsReceived      = "/relay/v2/data/bidtraces/builder_blocks_received"
 
 	// Internal API
 	pathInternalBuilderStatus     = "/internal/v1/builder/{pubkey:0x[a-fA-F0-9]+}"
 	pathInternalBuilderCollateral = "/internal/v1/builder/collateral/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalBuilderDemotions  = "/internal/v1/builder/demotions/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalBuilderRelayFee   = "/internal/v1/builder/relay_fee/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalProposerMinBid    = "/internal/v1/proposer/min_bid/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalSlotContext       = "/internal/v1/slot_context"
+	pathInternalInstances         = "/internal/v1/instances"
+	pathInternalRegistrationHist  = "/internal/v1/validator/registration_history/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalBuilderLabels     = "/internal/v1/builder/labels/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalBuilderOperators  = "/internal/v1/builder/operators"
+	pathInternalGetHeaderCalls    = "/internal/v1/getheader_calls"
+	pathInternalSimNodes          = "/internal/v1/sim_nodes"
+	pathInternalQuarantine        = "/internal/v1/builder/quarantine"
+	pathInternalBuilderQuarantine = "/internal/v1/builder/quarantine/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalFeatureFlags      = "/internal/v1/feature_flags"
+	pathInternalFeatureFlag       = "/internal/v1/feature_flags/{name:[a-zA-Z0-9_]+}"
 
 	// number of goroutines to save active validator
 	numValidatorRegProcessors = cli.GetEnvInt("NUM_VALIDATOR_REG_PROCESSORS", 10)
 
 	// various timings
 	timeoutGetPayloadRetryMs  = cli.GetEnvInt("GETPAYLOAD_RETRY_TIMEOUT_MS", 100)
+	getHeaderRequestMinMs     = cli.GetEnvInt("GETHEADER_REQUEST_MIN_MS", 0) // 0 means no minimum
 	getHeaderRequestCutoffMs  = cli.GetEnvInt("GETHEADER_REQUEST_CUTOFF_MS", 3000)
 	getPayloadRequestCutoffMs = cli.GetEnvInt("GETPAYLOAD_REQUEST_CUTOFF_MS", 4000)
 	getPayloadResponseDelayMs = cli.GetEnvInt("GETPAYLOAD_RESPONSE_DELAY_MS", 1000)
+	getHeaderResponseTargetMs = cli.GetEnvInt("GETHEADER_RESPONSE_TARGET_MS", 0) // 0 means no delay
+	getHeaderResponseJitterMs = cli.GetEnvInt("GETHEADER_RESPONSE_JITTER_MS", 0)
 
 	// api settings
 	apiReadTimeoutMs       = cli.GetEnvInt("API_TIMEOUT_READ_MS", 1500)
@@ -104,6 +157,9 @@ var (
 	// maximum payload bytes for a block submission to be fast-tracked (large payloads slow down other fast-tracked requests!)
 	fastTrackPayloadSizeLimit = cli.GetEnvInt("FAST_TRACK_PAYLOAD_SIZE_LIMIT", 230_000)
 
+	// number of getPayload requests by outcome which is not a delivered payload, served on the diagnostics listener
+	getPayloadExpvar = expvar.NewMap("getpayload")
+
 	// user-agents which shouldn't receive bids
 	apiNoHeaderUserAgents = common.GetEnvStrSlice("NO_HEADER_USERAGENTS", []string{
 		"mev-boost/v1.5.0 Go-http-client/1.1", // Prysm v4.0.1 (Shapella signing issue)
@@ -117,6 +173,9 @@ type RelayAPIOpts struct {
 	ListenAddr  string
 	BlockSimURL string
 
+	// Optional listener to serve the API on instead of ListenAddr, i.e. on a random port in tests
+	Listener net.Listener
+
 	BeaconClient beaconclient.IMultiBeaconClient
 	Datastore    *datastore.Datastore
 	Redis        *datastore.RedisCache
@@ -125,6 +184,13 @@ type RelayAPIOpts struct {
 
 	SecretKey *bls.SecretKey // used to sign bids (getHeader responses)
 
+	// Version of the relay, reported on the status endpoint
+	Version string
+
+	// Previous keys during a key rotation: the relay pubkey in Redis may still be one of them, and they are listed on
+	// the relay pubkeys endpoint until they are removed from the config (bids are only signed with SecretKey)
+	OldSecretKeys []*bls.SecretKey
+
 	// Network specific variables
 	EthNetDetails common.EthNetworkDetails
 
@@ -134,11 +200,39 @@ type RelayAPIOpts struct {
 	DataAPI         bool
 	PprofAPI        bool
 	InternalAPI     bool
+
+	// Optional second listener for trusted builder submissions (mTLS or IP allowlist)
+	TrustedBuilderListener *TrustedBuilderListenerOpts
+
+	// Optional HTTP/3 listener for block submissions
+	HTTP3Listener *HTTP3ListenerOpts
+
+	// Optional gRPC listener for block submissions
+	GRPCListener *GRPCListenerOpts
+
+	// Optional private listener for pprof, expvar and runtime diagnostics (disabled if empty)
+	DiagnosticsListenAddr string
+
+	// Relay mode (common.RelayModeMaxProfit or common.RelayModeFiltered), and the address blocklist (file or URL)
+	RelayMode string
+	Blocklist string
+
+	// Optional, defaults to DefaultTunables with the Blocklist
+	Tunables *Tunables
+
+	// Feature flags from the config file, by environment variable name. The environment takes precedence.
+	FeatureFlags map[string]bool
+
+	// Optional upstream relays (https://0x<relay pubkey>@<host>) for aggregation mode: their bids are merged with the
+	// local bids in getHeader, and getPayload is proxied to the relay of the winning bid
+	UpstreamRelays []string
 }
 
 type payloadAttributesHelper struct {
 	slot              uint64
 	parentHash        string
+	parentBlockNumber uint64
+	parentBlockRoot   string
 	withdrawalsRoot   phase0.Root
 	parentBeaconRoot  *phase0.Root
 	payloadAttributes beaconclient.PayloadAttributes
@@ -148,6 +242,7 @@ type payloadAttributesHelper struct {
 type blockSimOptions struct {
 	isHighPrio bool
 	fastTrack  bool
+	requestID  string // of the block submission, passed on to the simulation node
 	log        *logrus.Entry
 	builder    *blockBuilderCacheEntry
 	req        *common.BuilderBlockValidationRequest
@@ -156,6 +251,7 @@ type blockSimOptions struct {
 type blockBuilderCacheEntry struct {
 	status     common.BuilderStatus
 	collateral *big.Int
+	labels     common.BuilderLabels
 }
 
 type blockSimResult struct {
@@ -173,9 +269,22 @@ type RelayAPI struct {
 	blsSk     *bls.SecretKey
 	publicKey *phase0.BLSPubKey
 
+	oldPublicKeys []phase0.BLSPubKey
+
+	gitCommit string // VCS revision of the binary, reported on the status endpoint
+
 	srv         *http.Server
 	srvStarted  uberatomic.Bool
 	srvShutdown uberatomic.Bool
+	srvStopped  chan struct{} // closed when StopServer is done, after draining and closing the connections
+
+	beaconUnsynced uberatomic.Bool // getHeader and block submissions are paused while the beacon nodes are not synced
+
+	trustedSrv      *http.Server
+	diagnosticsSrv  *http.Server
+	http3Srv        *http3.Server
+	grpcSrv         *grpc.Server
+	trustedBuilders *trustedBuilders
 
 	beaconClient beaconclient.IMultiBeaconClient
 	datastore    *datastore.Datastore
@@ -185,8 +294,7 @@ type RelayAPI struct {
 
 	headSlot     uberatomic.Uint64
 	genesisInfo  *beaconclient.GetGenesisResponse
-	capellaEpoch int64
-	denebEpoch   int64
+	forkSchedule common.ForkVersionSchedule
 
 	proposerDutiesLock       sync.RWMutex
 	proposerDutiesResponse   *[]byte // raw http response
@@ -195,24 +303,97 @@ type RelayAPI struct {
 	isUpdatingProposerDuties uberatomic.Bool
 
 	blockSimRateLimiter IBlockSimRateLimiter
+	regVerifier         *RegistrationVerifier
+	builderSigVerifier  *BuilderSignatureVerifier
+
+	validatorRegC chan queuedValidatorRegistration
+
+	// address blocklist (nil if not configured)
+	blocklist *Blocklist
+
+	// settings which can be changed at runtime
+	tunables uberatomic.Pointer[Tunables]
+
+	// publisher of relay events to external sinks (nil if disabled)
+	events *events.Publisher
+
+	// stream of all received bid traces to Kafka (nil if disabled)
+	bidFirehose *events.BidFirehose
+
+	// cache of data API responses (nil if disabled)
+	dataCache *dataAPICache
+
+	// rate limiter of the data API (nil if disabled)
+	dataRateLimiter *common.RateLimiter
+
+	// bid archive (nil if disabled)
+	bidArchiveC            chan *database.BidArchiveEntry
+	bidArchiveCounter      bidArchiveCounter
+	bidArchiveProcessorsWG sync.WaitGroup
+
+	// log of the getHeader and getPayload requests of proposers (nil if disabled)
+	proposerRequestLogC  chan *database.ProposerRequestEntry
+	proposerRequestLogWG sync.WaitGroup
+
+	// queue of block submissions to write to the database (nil if disabled)
+	submissionDBC            chan *submissionDBEntry
+	submissionDBProcessorsWG sync.WaitGroup
+	submissionQueuePendingWG sync.WaitGroup // submissions waiting for their simulation result before being queued
+	submissionQueueDropped   uberatomic.Uint64
+
+	// duplicate block submissions of the latest slot
+	submissionDedup     *submissionDedup
+	submissionDedupHits uberatomic.Uint64
+	simCache            *simCache          // nil if disabled
+	builderQuarantine   *builderQuarantine // nil if disabled
 
-	validatorRegC chan builderApiV1.SignedValidatorRegistration
+	// block submissions per builder operator since the last slot, logged on every new slot
+	builderOperatorSubmissions builderOperatorCounter
 
 	// used to wait on any active getPayload calls on shutdown
 	getPayloadCallsInFlight sync.WaitGroup
 
+	// used to flush pending database writes on shutdown
+	validatorRegProcessorsWG sync.WaitGroup
+	backgroundDBWritesWG     sync.WaitGroup
+
+	// Feature flags which can be toggled at runtime (see runtimeFeatureFlags)
+	ffForceGetHeader204            uberatomic.Bool
+	ffDisableLowPrioBuilders       uberatomic.Bool
+	ffEnableCancellations          uberatomic.Bool // whether to enable block builder cancellations
+	ffDisableOptimisticSubmissions uberatomic.Bool // whether to process submissions of optimistic builders without optimistic collateral
+	ffDisableDenebSubmissions      uberatomic.Bool // whether to reject deneb block and header submissions
+	featureFlagsLock               sync.Mutex      // serializes runtime changes, so that the audit log has the correct previous values
+
 	// Feature flags
-	ffForceGetHeader204          bool
-	ffDisableLowPrioBuilders     bool
-	ffDisablePayloadDBStorage    bool // disable storing the execution payloads in the database
-	ffLogInvalidSignaturePayload bool // log payload if getPayload signature validation fails
-	ffEnableCancellations        bool // whether to enable block builder cancellations
-	ffRegValContinueOnInvalidSig bool // whether to continue processing further validators if one fails
-	ffIgnorableValidationErrors  bool // whether to enable ignorable validation errors
+	ffDisablePayloadDBStorage       bool // disable storing the execution payloads in the database
+	ffLogInvalidSignaturePayload    bool // log payload if getPayload signature validation fails
+	ffRegValContinueOnInvalidSig    bool // whether to continue processing further validators if one fails
+	ffIgnorableValidationErrors     bool // whether to enable ignorable validation errors
+	ffReturnPayloadOnPublishFailure bool // whether to still return the payload to the proposer if publishing the block failed
+	ffSkipSigVerifyForMTLSBuilders  bool // whether to skip the builder signature check for submissions over an authenticated mTLS connection
+	ffVerifyPayloadAttributes       bool // whether to check payload attributes events against the randao and withdrawals of the beacon node
+	ffEnablePreconfCommitments      bool // whether to accept preconfirmation commitments with block submissions
+	ffVerifyProposerPayment         bool // whether to check that the bid value is paid to the proposer by the last transaction of the block
+	ffEnableHeaderSubmissions       bool // whether to accept header-only submissions of optimistic builders, with the payload submitted later
+	ffEnableRedisWarmStart          bool // whether to restore proposer duties and top bids from the database into Redis on startup
 
 	payloadAttributes     map[string]payloadAttributesHelper // key:parentBlockHash
 	payloadAttributesLock sync.RWMutex
 
+	// payload attributes and proposer duty of the upcoming slot, validated against by block submissions
+	slotCtx uberatomic.Pointer[slotContext]
+
+	// gas limits of recent eligible blocks, by block hash, to check the gas limit of blocks built on them
+	blockGasLimits *lru.Cache[string, uint64]
+
+	// relays whose bids are merged with the local bids in getHeader (aggregation mode), empty if disabled
+	upstreamRelays []*upstreamRelay
+	upstreamClient *http.Client
+
+	// registrations of not yet known validators, processed after the next known validators refresh (nil if disabled)
+	pendingRegs *pendingRegistrations
+
 	// The slot we are currently optimistically simulating.
 	optimisticSlot uberatomic.Uint64
 	// The number of optimistic blocks being processed (only used for logging).
@@ -237,107 +418,306 @@ func NewRelayAPI(opts RelayAPIOpts) (api *RelayAPI, err error) {
 		return nil, ErrMissingDatastoreOpt
 	}
 
+	// Bids of upstream relays are signed again with the secret key of this relay
+	upstreamRelays := make([]*upstreamRelay, len(opts.UpstreamRelays))
+	for i, relayURL := range opts.UpstreamRelays {
+		upstreamRelays[i], err = newUpstreamRelay(relayURL)
+		if err != nil {
+			return nil, err
+		}
+		opts.Log.Infof("Using upstream relay: %s", upstreamRelays[i].String())
+	}
+	if len(upstreamRelays) > 0 && opts.SecretKey == nil {
+		return nil, ErrUpstreamRelaysWithoutSecretKey
+	}
+
 	// If block-builder API is enabled, then ensure secret key is all set
 	var publicKey phase0.BLSPubKey
-	if opts.BlockBuilderAPI {
+	var oldPublicKeys []phase0.BLSPubKey
+	if opts.BlockBuilderAPI || len(upstreamRelays) > 0 {
 		if opts.SecretKey == nil {
 			return nil, ErrBuilderAPIWithoutSecretKey
 		}
 
 		// If using a secret key, ensure it's the correct one
-		blsPubkey, err := bls.PublicKeyFromSecretKey(opts.SecretKey)
-		if err != nil {
-			return nil, err
-		}
-		publicKey, err = utils.BlsPublicKeyToPublicKey(blsPubkey)
+		publicKey, err = publicKeyFromSecretKey(opts.SecretKey)
 		if err != nil {
 			return nil, err
 		}
 		opts.Log.Infof("Using BLS key: %s", publicKey.String())
 
-		// ensure pubkey is same across all relay instances
-		_pubkey, err := opts.Redis.GetRelayConfig(datastore.RedisConfigFieldPubkey)
-		if err != nil {
-			return nil, err
-		} else if _pubkey == "" {
-			err := opts.Redis.SetRelayConfig(datastore.RedisConfigFieldPubkey, publicKey.String())
+		for _, oldSecretKey := range opts.OldSecretKeys {
+			oldPublicKey, err := publicKeyFromSecretKey(oldSecretKey)
 			if err != nil {
 				return nil, err
 			}
-		} else if _pubkey != publicKey.String() {
-			return nil, fmt.Errorf("%w: new=%s old=%s", ErrRelayPubkeyMismatch, publicKey.String(), _pubkey)
+			opts.Log.Infof("Accepting previous BLS key: %s", oldPublicKey.String())
+			oldPublicKeys = append(oldPublicKeys, oldPublicKey)
+		}
+
+		// ensure pubkey is same across all relay instances
+		err = checkRelayPubkey(opts.Redis, publicKey, oldPublicKeys)
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	blockSimRateLimiter, err := NewBlockSimulationRateLimiter(opts.BlockSimURL)
+	if err != nil {
+		return nil, err
+	}
+
 	api = &RelayAPI{
-		opts:         opts,
-		log:          opts.Log,
-		blsSk:        opts.SecretKey,
-		publicKey:    &publicKey,
-		datastore:    opts.Datastore,
-		beaconClient: opts.BeaconClient,
-		redis:        opts.Redis,
-		memcached:    opts.Memcached,
-		db:           opts.DB,
+		opts:          opts,
+		log:           opts.Log,
+		blsSk:         opts.SecretKey,
+		publicKey:     &publicKey,
+		oldPublicKeys: oldPublicKeys,
+		gitCommit:     gitCommit(),
+		datastore:     opts.Datastore,
+		beaconClient:  opts.BeaconClient,
+		redis:         opts.Redis,
+		memcached:     opts.Memcached,
+		db:            opts.DB,
 
 		payloadAttributes: make(map[string]payloadAttributesHelper),
+		blockGasLimits:    lru.NewCache[string, uint64](blockGasLimitsCacheSize),
+
+		// loaded from the network config and beacon node on startup, electra is not scheduled until then
+		forkSchedule: common.ForkVersionSchedule{ElectraEpoch: -1},
 
 		proposerDutiesResponse: &[]byte{},
-		blockSimRateLimiter:    NewBlockSimulationRateLimiter(opts.BlockSimURL),
+		blockSimRateLimiter:    blockSimRateLimiter,
+		regVerifier:            NewRegistrationVerifier(opts.EthNetDetails.DomainBuilder),
+		builderSigVerifier:     NewBuilderSignatureVerifier(opts.EthNetDetails.DomainBuilder),
+		submissionDedup:        newSubmissionDedup(),
+		upstreamRelays:         upstreamRelays,
+		upstreamClient:         &http.Client{},
+
+		validatorRegC: make(chan queuedValidatorRegistration, 450_000),
+		srvStopped:    make(chan struct{}),
+	}
+
+	if opts.TrustedBuilderListener != nil {
+		api.trustedBuilders, err = newTrustedBuilders(opts.TrustedBuilderListener.Identities)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tunables := DefaultTunables()
+	tunables.Blocklist = opts.Blocklist
+	if opts.Tunables != nil {
+		tunables = *opts.Tunables
+	}
+	if err := tunables.Validate(); err != nil {
+		return nil, err
+	}
+	api.tunables.Store(&tunables)
+	api.blockSimRateLimiter.SetMaxConcurrent(int64(tunables.BlockSimMaxConcurrent))
+
+	switch opts.RelayMode {
+	case "":
+		api.opts.RelayMode = common.RelayModeMaxProfit
+	case common.RelayModeMaxProfit:
+	case common.RelayModeFiltered:
+		if tunables.Blocklist == "" {
+			return nil, ErrMissingBlocklist
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRelayMode, opts.RelayMode)
+	}
+
+	if opts.BlockBuilderAPI && opts.RelayMode == common.RelayModeFiltered {
+		blocklistOpts, err := BlocklistOptsFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		api.blocklist, err = NewBlocklist(api.log, tunables.Blocklist, blocklistOpts)
+		if err != nil {
+			return nil, err
+		}
+	} else if tunables.Blocklist != "" {
+		api.log.Warnf("blocklist is only used in %s relay mode", common.RelayModeFiltered)
+	}
+
+	if err := checkSubmissionQueueDropPolicy(submissionQueueDropPolicy); err != nil {
+		return nil, err
+	}
+
+	if opts.ProposerAPI && pendingRegistrationsMax > 0 {
+		api.log.Infof("keeping up to %d registrations of not yet known validators for %s", pendingRegistrationsMax, pendingRegistrationsTTL)
+		api.pendingRegs = newPendingRegistrations(pendingRegistrationsMax, pendingRegistrationsTTL)
+	}
+
+	if opts.BlockBuilderAPI && bidArchiveSamplePercent > 0 {
+		api.log.Infof("bid archive enabled, archiving %d%% of the accepted bids and all rejected bids (max %d per slot)", bidArchiveSamplePercent, bidArchiveMaxPerSlot)
+		api.bidArchiveC = make(chan *database.BidArchiveEntry, 10_000)
+	}
+
+	if eventSinks != "" {
+		api.events, err = events.NewPublisher(api.log, eventSinks, eventQueueSize)
+		if err != nil {
+			return nil, err
+		}
+		api.log.Info("publishing relay events")
+	}
+
+	if opts.BlockBuilderAPI && bidFirehoseKafka != "" {
+		sink, err := events.NewKafkaRESTSinkFromURI(bidFirehoseKafka)
+		if err != nil {
+			return nil, err
+		}
+		api.log.Infof("streaming all bid traces to %s", sink.Name())
+		api.bidFirehose = events.NewBidFirehose(api.log, sink, bidFirehoseQueueSize, bidFirehoseBatchSize, bidFirehoseFlushInterval)
+	}
+
+	if opts.DataAPI && dataAPICacheTTL > 0 {
+		api.log.Infof("caching data API responses for %s", dataAPICacheTTL)
+		api.dataCache = newDataAPICache(dataAPICacheSize, dataAPICacheTTL, dataAPICacheBypassToken)
+	}
 
-		validatorRegC: make(chan builderApiV1.SignedValidatorRegistration, 450_000),
+	if opts.DataAPI && (dataAPIRateLimitPerIP > 0 || dataAPIRateLimitGlobal > 0) {
+		api.log.Infof("rate limiting the data API to %d requests per second per IP and %d globally (0 for no limit)", dataAPIRateLimitPerIP, dataAPIRateLimitGlobal)
+		api.dataRateLimiter, err = common.NewRateLimiter(common.RateLimiterOpts{
+			PerIPRate:      float64(dataAPIRateLimitPerIP),
+			GlobalRate:     float64(dataAPIRateLimitGlobal),
+			TrustedProxies: rateLimitTrustedProxies,
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if os.Getenv("FORCE_GET_HEADER_204") == "1" {
+	if api.isFeatureFlagEnabled("FORCE_GET_HEADER_204") {
 		api.log.Warn("env: FORCE_GET_HEADER_204 - forcing getHeader to always return 204")
-		api.ffForceGetHeader204 = true
+		api.ffForceGetHeader204.Store(true)
 	}
 
-	if os.Getenv("DISABLE_LOWPRIO_BUILDERS") == "1" {
+	if api.isFeatureFlagEnabled("DISABLE_LOWPRIO_BUILDERS") {
 		api.log.Warn("env: DISABLE_LOWPRIO_BUILDERS - allowing only high-level builders")
-		api.ffDisableLowPrioBuilders = true
+		api.ffDisableLowPrioBuilders.Store(true)
+	}
+
+	if api.isFeatureFlagEnabled("DISABLE_OPTIMISTIC_SUBMISSIONS") {
+		api.log.Warn("env: DISABLE_OPTIMISTIC_SUBMISSIONS - submissions of optimistic builders are simulated before they become eligible")
+		api.ffDisableOptimisticSubmissions.Store(true)
+	}
+
+	if api.isFeatureFlagEnabled("DISABLE_DENEB_SUBMISSIONS") {
+		api.log.Warn("env: DISABLE_DENEB_SUBMISSIONS - rejecting deneb block and header submissions")
+		api.ffDisableDenebSubmissions.Store(true)
 	}
 
-	if os.Getenv("DISABLE_PAYLOAD_DATABASE_STORAGE") == "1" {
+	if api.isFeatureFlagEnabled("DISABLE_PAYLOAD_DATABASE_STORAGE") {
 		api.log.Warn("env: DISABLE_PAYLOAD_DATABASE_STORAGE - disabling storing payloads in the database")
 		api.ffDisablePayloadDBStorage = true
 	}
 
-	if os.Getenv("LOG_INVALID_GETPAYLOAD_SIGNATURE") == "1" {
+	if api.isFeatureFlagEnabled("LOG_INVALID_GETPAYLOAD_SIGNATURE") {
 		api.log.Warn("env: LOG_INVALID_GETPAYLOAD_SIGNATURE - getPayload payloads with invalid proposer signature will be logged")
 		api.ffLogInvalidSignaturePayload = true
 	}
 
-	if os.Getenv("ENABLE_BUILDER_CANCELLATIONS") == "1" {
+	if api.isFeatureFlagEnabled("ENABLE_BUILDER_CANCELLATIONS") {
 		api.log.Warn("env: ENABLE_BUILDER_CANCELLATIONS - builders are allowed to cancel submissions when using ?cancellation=1")
-		api.ffEnableCancellations = true
+		api.ffEnableCancellations.Store(true)
 	}
 
-	if os.Getenv("REGISTER_VALIDATOR_CONTINUE_ON_INVALID_SIG") == "1" {
+	if api.isFeatureFlagEnabled("REGISTER_VALIDATOR_CONTINUE_ON_INVALID_SIG") {
 		api.log.Warn("env: REGISTER_VALIDATOR_CONTINUE_ON_INVALID_SIG - validator registration will continue processing even if one validator has an invalid signature")
 		api.ffRegValContinueOnInvalidSig = true
 	}
 
-	if os.Getenv("ENABLE_IGNORABLE_VALIDATION_ERRORS") == "1" {
+	if api.isFeatureFlagEnabled("SKIP_SIG_VERIFY_FOR_MTLS_BUILDERS") {
+		api.log.Warn("env: SKIP_SIG_VERIFY_FOR_MTLS_BUILDERS - builder signatures of submissions over authenticated mTLS connections are not verified")
+		api.ffSkipSigVerifyForMTLSBuilders = true
+	}
+
+	if api.isFeatureFlagEnabled("ENABLE_IGNORABLE_VALIDATION_ERRORS") {
 		api.log.Warn("env: ENABLE_IGNORABLE_VALIDATION_ERRORS - some validation errors will be ignored")
 		api.ffIgnorableValidationErrors = true
 	}
 
+	if api.isFeatureFlagEnabled("VERIFY_PAYLOAD_ATTRIBUTES") {
+		api.log.Warn("env: VERIFY_PAYLOAD_ATTRIBUTES - checking payload attributes against the beacon node state")
+		api.ffVerifyPayloadAttributes = true
+	}
+
+	if api.isFeatureFlagEnabled("ENABLE_PRECONF_COMMITMENTS") {
+		api.log.Warn("env: ENABLE_PRECONF_COMMITMENTS - builders can submit preconfirmation commitments with their bids")
+		api.ffEnablePreconfCommitments = true
+	}
+
+	if api.isFeatureFlagEnabled("VERIFY_PROPOSER_PAYMENT") {
+		api.log.Warn("env: VERIFY_PROPOSER_PAYMENT - rejecting block submissions which don't pay the bid value to the proposer fee recipient")
+		api.ffVerifyProposerPayment = true
+	}
+
+	if api.isFeatureFlagEnabled("ENABLE_HEADER_SUBMISSIONS") {
+		api.log.Warn("env: ENABLE_HEADER_SUBMISSIONS - optimistic builders can submit headers and the payloads later")
+		api.ffEnableHeaderSubmissions = true
+	}
+
+	if api.isFeatureFlagEnabled("ENABLE_REDIS_WARM_START") {
+		api.log.Warn("env: ENABLE_REDIS_WARM_START - restoring proposer duties and top bids missing in Redis from the database on startup")
+		api.ffEnableRedisWarmStart = true
+	}
+
+	if opts.ProposerAPI && api.isFeatureFlagEnabled("ENABLE_PROPOSER_REQUEST_LOG") {
+		api.log.Warn("env: ENABLE_PROPOSER_REQUEST_LOG - saving getHeader and getPayload requests of proposers to the database")
+		api.proposerRequestLogC = make(chan *database.ProposerRequestEntry, proposerRequestLogQueueSize)
+	}
+
+	if api.isFeatureFlagEnabled("ENABLE_SIM_RESULT_CACHE") {
+		api.log.Warn("env: ENABLE_SIM_RESULT_CACHE - resubmissions of successfully simulated blocks are not simulated again")
+		api.simCache = newSimCache()
+	}
+
+	if !getPayloadDefaultResponseVersion.isValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGetPayloadResponseVersion, getPayloadDefaultResponseVersion)
+	}
+
+	if api.isFeatureFlagEnabled("ENABLE_BUILDER_QUARANTINE") {
+		if builderQuarantineMode != builderQuarantineModeDeprioritize && builderQuarantineMode != builderQuarantineModeBlock {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBuilderQuarantineMode, builderQuarantineMode)
+		}
+		api.log.Warnf("env: ENABLE_BUILDER_QUARANTINE - builders with anomalous failure rates are quarantined (mode: %s)", builderQuarantineMode)
+		api.builderQuarantine = newBuilderQuarantine()
+	}
+
+	if api.isFeatureFlagEnabled("RETURN_PAYLOAD_ON_PUBLISH_FAILURE") {
+		api.log.Warn("env: RETURN_PAYLOAD_ON_PUBLISH_FAILURE - getPayload will return the payload to the proposer even if publishing the block failed")
+		api.ffReturnPayloadOnPublishFailure = true
+	}
+
 	return api, nil
 }
 
+// isFeatureFlagEnabled returns whether the feature flag is enabled in the environment ("1"), or else in the config file
+func (api *RelayAPI) isFeatureFlagEnabled(name string) bool {
+	if value, ok := os.LookupEnv(name); ok {
+		return value == "1"
+	}
+	return api.opts.FeatureFlags[name]
+}
+
 func (api *RelayAPI) getRouter() http.Handler {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/", api.handleRoot).Methods(http.MethodGet)
 	r.HandleFunc("/livez", api.handleLivez).Methods(http.MethodGet)
 	r.HandleFunc("/readyz", api.handleReadyz).Methods(http.MethodGet)
+	r.HandleFunc("/healthz", api.handleHealthz).Methods(http.MethodGet)
+	r.HandleFunc(pathRelayStatus, api.handleRelayStatus).Methods(http.MethodGet)
 
 	// Proposer API
 	if api.opts.ProposerAPI {
 		api.log.Info("proposer API enabled")
 		r.HandleFunc(pathStatus, api.handleStatus).Methods(http.MethodGet)
 		r.HandleFunc(pathRegisterValidator, api.handleRegisterValidator).Methods(http.MethodPost)
+		r.HandleFunc(pathProposerBuilderPreferences, api.handleProposerBuilderPreferences).Methods(http.MethodPost)
+		r.HandleFunc(pathProposerConstraints, api.handleProposerConstraints).Methods(http.MethodPost)
 		r.HandleFunc(pathGetHeader, api.handleGetHeader).Methods(http.MethodGet)
 		r.HandleFunc(pathGetPayload, api.handleGetPayload).Methods(http.MethodPost)
 	}
@@ -347,14 +727,29 @@ func (api *RelayAPI) getRouter() http.Handler {
 		api.log.Info("block builder API enabled")
 		r.HandleFunc(pathBuilderGetValidators, api.handleBuilderGetValidators).Methods(http.MethodGet)
 		r.HandleFunc(pathSubmitNewBlock, api.handleSubmitNewBlock).Methods(http.MethodPost)
+		r.HandleFunc(pathRelayPubkeys, api.handleRelayPubkeys).Methods(http.MethodGet)
+		r.HandleFunc(pathBuilderConstraints, api.handleBuilderConstraints).Methods(http.MethodGet)
+		r.HandleFunc(pathBuilderBidFeedback, api.handleBuilderBidFeedback).Methods(http.MethodPost)
+		if api.ffEnableHeaderSubmissions {
+			r.HandleFunc(pathSubmitHeader, api.handleSubmitHeader).Methods(http.MethodPost)
+		}
 	}
 
 	// Data API
 	if api.opts.DataAPI {
 		api.log.Info("data API enabled")
-		r.HandleFunc(pathDataProposerPayloadDelivered, api.handleDataProposerPayloadDelivered).Methods(http.MethodGet)
-		r.HandleFunc(pathDataBuilderBidsReceived, api.handleDataBuilderBidsReceived).Methods(http.MethodGet)
-		r.HandleFunc(pathDataValidatorRegistration, api.handleDataValidatorRegistration).Methods(http.MethodGet)
+		r.Handle(pathDataProposerPayloadDelivered, api.dataAPIHandler(api.handleDataProposerPayloadDelivered)).Methods(http.MethodGet)
+		r.Handle(pathDataBuilderBidsReceived, api.dataAPIHandler(api.handleDataBuilderBidsReceived)).Methods(http.MethodGet)
+		r.Handle(pathDataValidatorRegistration, api.dataAPIHandler(api.handleDataValidatorRegistration)).Methods(http.MethodGet)
+		r.Handle(pathDataBids, api.dataAPIHandler(api.handleDataBids)).Methods(http.MethodGet)
+		r.Handle(pathDataPaymentVerification, api.dataAPIHandler(api.handleDataPaymentVerification)).Methods(http.MethodGet)
+		r.Handle(pathDataSlotSummary, api.dataAPIHandler(api.handleDataSlotSummary)).Methods(http.MethodGet)
+		r.Handle(pathDataEpochSummary, api.dataAPIHandler(api.handleDataEpochSummary)).Methods(http.MethodGet)
+		r.Handle(pathDataBuilderPreferences, api.dataAPIHandler(api.handleDataProposerBuilderPreferences)).Methods(http.MethodGet)
+		r.Handle(pathDataPreconfStatus, api.dataAPIHandler(api.handleDataPreconfStatus)).Methods(http.MethodGet)
+		r.Handle(pathDataProposerRequests, api.dataAPIHandler(api.handleDataProposerRequests)).Methods(http.MethodGet)
+		r.Handle(pathDataV2ProposerPayloadDelivered, api.dataAPIHandler(api.handleDataV2ProposerPayloadDelivered)).Methods(http.MethodGet)
+		r.Handle(pathDataV2BuilderBidsReceived, api.dataAPIHandler(api.handleDataV2BuilderBidsReceived)).Methods(http.MethodGet)
 	}
 
 	// Pprof
@@ -368,6 +763,20 @@ func (api *RelayAPI) getRouter() http.Handler {
 		api.log.Info("internal API enabled")
 		r.HandleFunc(pathInternalBuilderStatus, api.handleInternalBuilderStatus).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
 		r.HandleFunc(pathInternalBuilderCollateral, api.handleInternalBuilderCollateral).Methods(http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalBuilderDemotions, api.handleInternalBuilderDemotions).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalBuilderRelayFee, api.handleInternalBuilderRelayFee).Methods(http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalProposerMinBid, api.handleInternalProposerMinBid).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalSlotContext, api.handleInternalSlotContext).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalInstances, api.handleInternalInstances).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalRegistrationHist, api.handleInternalRegistrationHistory).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalBuilderLabels, api.handleInternalBuilderLabels).Methods(http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalBuilderOperators, api.handleInternalBuilderOperators).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalGetHeaderCalls, api.handleInternalGetHeaderCalls).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalSimNodes, api.handleInternalSimNodes).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+		r.HandleFunc(pathInternalQuarantine, api.handleInternalBuilderQuarantine).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalBuilderQuarantine, api.handleInternalBuilderQuarantine).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
+		r.HandleFunc(pathInternalFeatureFlags, api.handleInternalFeatureFlags).Methods(http.MethodGet)
+		r.HandleFunc(pathInternalFeatureFlag, api.handleInternalFeatureFlags).Methods(http.MethodPost, http.MethodPut)
 	}
 
 	mresp := common.MustB64Gunzip("H4sICAtOkWQAA2EudHh0AKWVPW+DMBCGd36Fe9fIi5Mt8uqqs4dIlZiCEqosKKhVO2Txj699GBtDcEl4JwTnh/t4dS7YWom2FcVaiETSDEmIC+pWLGRVgKrD3UY0iwnSj6THofQJDomiR13BnPgjvJDqNWX+OtzH7inWEGvr76GOCGtg3Kp7Ak+lus3zxLNtmXaMUncjcj1cwbOH3xBZtJCYG6/w+hdpB6ErpnqzFPZxO4FdXB3SAEgpscoDqWeULKmJA4qyfYFg0QV+p7hD8GGDd6C8+mElGDKab1CWeUQMVVvVDTJVj6nngHmNOmSoe6yH1BM3KZIKpuRaHKrOFd/3ksQwzdK+ejdM4VTzSDfjJsY1STeVTWb0T9JWZbJs8DvsNvwaddKdUy4gzVIzWWaWk3IF8D35kyUDf3FfKipwk/DYUee2nYyWQD0xEKDHeprzeXYwVmZD/lXt1OOg8EYhFfitsmQVcwmbUutpdt3PoqWdMyd2DYHKbgcmPlEYMxPjR6HhxOfuNG52xZr7TtzpygJJKNtWS14Uf0T6XSmzBwAA")
@@ -376,7 +785,7 @@ func (api *RelayAPI) getRouter() http.Handler {
 	// r.Use(mux.CORSMethodMiddleware(r))
 	loggedRouter := httplogger.LoggingMiddlewareLogrus(api.log, r)
 	withGz := gziphandler.GzipHandler(loggedRouter)
-	return withGz
+	return requestIDMiddleware(api.requestLimitsMiddleware(withGz))
 }
 
 // StartServer starts up this API instance and HTTP server
@@ -406,51 +815,118 @@ func (api *RelayAPI) StartServer() (err error) {
 	}
 	log.Infof("genesis info: %d", api.genesisInfo.Data.GenesisTime)
 
+	// Custom networks are configured by the operator, make sure the config matches the beacon node
+	if api.opts.EthNetDetails.Name == common.EthNetworkCustom {
+		spec, err := api.beaconClient.GetSpec()
+		if err != nil {
+			return err
+		}
+		err = beaconclient.ValidateNetworkDetails(&api.opts.EthNetDetails, api.genesisInfo, spec)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Get and prepare fork schedule
 	forkSchedule, err := api.beaconClient.GetForkSchedule()
 	if err != nil {
 		return err
 	}
 
-	api.denebEpoch = -1
-	api.capellaEpoch = -1
+	// Start with the fork epochs of the network config, the beacon node's fork schedule takes precedence
+	api.forkSchedule = api.opts.EthNetDetails.ForkSchedule
 	for _, fork := range forkSchedule.Data {
 		log.Infof("forkSchedule: version=%s / epoch=%d", fork.CurrentVersion, fork.Epoch)
 		switch fork.CurrentVersion {
 		case api.opts.EthNetDetails.CapellaForkVersionHex:
-			api.capellaEpoch = int64(fork.Epoch)
+			api.forkSchedule.CapellaEpoch = int64(fork.Epoch)
 		case api.opts.EthNetDetails.DenebForkVersionHex:
-			api.denebEpoch = int64(fork.Epoch)
+			api.forkSchedule.DenebEpoch = int64(fork.Epoch)
+		case api.opts.EthNetDetails.ElectraForkVersionHex:
+			if fork.CurrentVersion != "" {
+				api.forkSchedule.ElectraEpoch = int64(fork.Epoch)
+			}
 		}
 	}
 
-	if api.denebEpoch == -1 {
+	if api.forkSchedule.DenebEpoch == -1 {
 		// log warning that deneb epoch was not found in CL fork schedule, suggest CL upgrade
 		log.Info("Deneb epoch not found in fork schedule")
 	}
 
 	// Print fork version information
-	if hasReachedFork(currentSlot, api.denebEpoch) {
-		log.Infof("deneb fork detected (currentEpoch: %d / denebEpoch: %d)", common.SlotToEpoch(currentSlot), api.denebEpoch)
-	} else if hasReachedFork(currentSlot, api.capellaEpoch) {
-		log.Infof("capella fork detected (currentEpoch: %d / capellaEpoch: %d)", common.SlotToEpoch(currentSlot), api.capellaEpoch)
+	if hasReachedFork(currentSlot, api.forkSchedule.ElectraEpoch) {
+		log.Infof("electra fork detected (currentEpoch: %d / electraEpoch: %d)", common.SlotToEpoch(currentSlot), api.forkSchedule.ElectraEpoch)
+	} else if hasReachedFork(currentSlot, api.forkSchedule.DenebEpoch) {
+		log.Infof("deneb fork detected (currentEpoch: %d / denebEpoch: %d)", common.SlotToEpoch(currentSlot), api.forkSchedule.DenebEpoch)
+	} else if hasReachedFork(currentSlot, api.forkSchedule.CapellaEpoch) {
+		log.Infof("capella fork detected (currentEpoch: %d / capellaEpoch: %d)", common.SlotToEpoch(currentSlot), api.forkSchedule.CapellaEpoch)
+	}
+
+	// Restore the state of the current and next slot if it's missing in Redis, before serving requests
+	if api.ffEnableRedisWarmStart && (api.opts.ProposerAPI || api.opts.BlockBuilderAPI) {
+		api.warmStartRedis(currentSlot)
 	}
 
 	// start proposer API specific things
 	if api.opts.ProposerAPI {
 		// Update known validators (which can take 10-30 sec). This is a requirement for service readiness, because without them,
 		// getPayload() doesn't have the information it needs (known validators), which could lead to missed slots.
+		// Validators persisted in Redis by a previous refresh are loaded first, to be ready before the refresh is done.
+		err = api.datastore.LoadKnownValidatorsFromRedis(api.log)
+		if err != nil {
+			api.log.WithError(err).Error("failed to load known validators from Redis")
+		}
 		go api.datastore.RefreshKnownValidators(api.log, api.beaconClient, currentSlot)
 
+		// Load the proposer preferences into Redis (they might have been lost since they were set)
+		err = api.syncProposerPreferences()
+		if err != nil {
+			api.log.WithError(err).Error("failed to load proposer preferences")
+		}
+
 		// Start the validator registration db-save processor
 		api.log.Infof("starting %d validator registration processors", numValidatorRegProcessors)
 		for i := 0; i < numValidatorRegProcessors; i++ {
+			api.validatorRegProcessorsWG.Add(1)
 			go api.startValidatorRegistrationDBProcessor()
 		}
+
+		// Start the proposer request log db-save processor
+		if api.isProposerRequestLogEnabled() {
+			api.proposerRequestLogWG.Add(1)
+			go api.startProposerRequestLogDBProcessor()
+		}
 	}
 
 	// start block-builder API specific things
 	if api.opts.BlockBuilderAPI {
+		// Start the block submission db-save processors
+		if submissionQueueSize > 0 {
+			api.log.Infof("starting %d block submission processors (queue size %d, %s)", numSubmissionDBProcessors, submissionQueueSize, submissionQueueDropPolicy)
+			api.submissionDBC = make(chan *submissionDBEntry, submissionQueueSize)
+			for i := 0; i < numSubmissionDBProcessors; i++ {
+				api.submissionDBProcessorsWG.Add(1)
+				go api.startSubmissionDBProcessor()
+			}
+		}
+
+		// Start the bid archive db-save processor
+		if api.isBidArchiveEnabled() {
+			for i := 0; i < numBidArchiveProcessors; i++ {
+				api.bidArchiveProcessorsWG.Add(1)
+				go api.startBidArchiveDBProcessor()
+			}
+		}
+
+		// Probe unhealthy block simulation nodes to put them back into rotation
+		go api.startSimNodeProbing()
+
+		// Cancel the header-only bids whose payload wasn't submitted in time, also those received by other instances
+		if api.ffEnableHeaderSubmissions {
+			go api.startPendingPayloadDeadlineCheck()
+		}
+
 		// Get current proposer duties blocking before starting, to have them ready
 		api.updateProposerDuties(syncStatus.HeadSlot)
 
@@ -463,6 +939,16 @@ func (api *RelayAPI) StartServer() (err error) {
 				api.processPayloadAttributes(payloadAttributes)
 			}
 		}()
+
+		// Subscribe to chain reorg events, to invalidate bids built on a reorged-away head (only for builder-api)
+		go func() {
+			c := make(chan beaconclient.ChainReorgEvent)
+			api.beaconClient.SubscribeToChainReorgEvents(c)
+			for {
+				reorg := <-c
+				api.processChainReorg(reorg)
+			}
+		}()
 	}
 
 	// Process current slot
@@ -478,6 +964,69 @@ func (api *RelayAPI) StartServer() (err error) {
 		}
 	}()
 
+	// pause getHeader and block submissions while the beacon nodes are not synced
+	if beaconSyncCheckInterval > 0 && (api.opts.ProposerAPI || api.opts.BlockBuilderAPI) {
+		go api.startBeaconSyncCheck()
+	}
+
+	// let the other instances know this one is alive
+	go api.startInstanceHeartbeat()
+
+	// reload the blocklist in regular intervals
+	if api.blocklist != nil {
+		go api.blocklist.startReloading()
+	}
+
+	// start the diagnostics listener
+	if api.opts.DiagnosticsListenAddr != "" {
+		go func() {
+			err := api.startDiagnosticsServer()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.WithError(err).Fatal("diagnostics listener failed")
+			}
+		}()
+	}
+
+	// start the trusted builder listener
+	if api.opts.BlockBuilderAPI && api.opts.TrustedBuilderListener != nil {
+		go func() {
+			err := api.startTrustedBuilderServer()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.WithError(err).Fatal("trusted builder listener failed")
+			}
+		}()
+	}
+
+	// start the HTTP/3 listener
+	if opts := api.opts.HTTP3Listener; api.opts.BlockBuilderAPI && opts != nil {
+		api.http3Srv = api.newHTTP3Server()
+		go func() {
+			log.Infof("HTTP/3 listener starting on %s (UDP)", opts.ListenAddr)
+			err := api.http3Srv.ListenAndServeTLS(opts.TLSCertFile, opts.TLSKeyFile)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.WithError(err).Fatal("HTTP/3 listener failed")
+			}
+		}()
+	}
+
+	// start the gRPC listener
+	if opts := api.opts.GRPCListener; api.opts.BlockBuilderAPI && opts != nil {
+		api.grpcSrv, err = api.newGRPCServer()
+		if err != nil {
+			return err
+		}
+		lis, err := net.Listen("tcp", opts.ListenAddr)
+		if err != nil {
+			return err
+		}
+		go func() {
+			log.Infof("gRPC listener starting on %s", opts.ListenAddr)
+			if err := api.grpcSrv.Serve(lis); err != nil {
+				log.WithError(err).Fatal("gRPC listener failed")
+			}
+		}()
+	}
+
 	// create and start HTTP server
 	api.srv = &http.Server{
 		Addr:    api.opts.ListenAddr,
@@ -489,8 +1038,14 @@ func (api *RelayAPI) StartServer() (err error) {
 		IdleTimeout:       time.Duration(apiIdleTimeoutMs) * time.Millisecond,
 		MaxHeaderBytes:    apiMaxHeaderBytes,
 	}
-	err = api.srv.ListenAndServe()
+	if api.opts.Listener != nil {
+		err = api.srv.Serve(api.opts.Listener)
+	} else {
+		err = api.srv.ListenAndServe()
+	}
 	if errors.Is(err, http.ErrServerClosed) {
+		// wait for StopServer to finish draining, flushing and closing connections
+		<-api.srvStopped
 		return nil
 	}
 	return err
@@ -513,21 +1068,26 @@ func (api *RelayAPI) IsReady() bool {
 }
 
 // StopServer gracefully shuts down the HTTP server:
-// - Stop returning bids
-// - Set ready /readyz to negative status
-// - Wait a bit to allow removal of service from load balancer and draining of requests
+// - Stop accepting block submissions
+// - Stop returning bids (optional)
+// - Set ready /readyz to negative status (/livez stays positive)
+// - Wait a bit to allow removal of service from load balancer and draining of requests, while still serving getHeader and getPayload
+// - Shutdown the HTTP servers and flush pending database writes
+// - Close the Redis, memcached and Postgres connections
 func (api *RelayAPI) StopServer() (err error) {
-	// avoid running this twice. setting srvShutdown to true makes /readyz switch to negative status
+	// avoid running this twice. setting srvShutdown to true makes /readyz switch to negative status, and
+	// block submissions are rejected from now on
 	if wasStopping := api.srvShutdown.Swap(true); wasStopping {
 		return nil
 	}
+	defer close(api.srvStopped)
 
 	// start server shutdown
 	api.log.Info("Stopping server...")
 
 	// stop returning bids on getHeader calls (should only be used when running a single instance)
 	if api.opts.ProposerAPI && apiShutdownStopSendingBids {
-		api.ffForceGetHeader204 = true
+		api.ffForceGetHeader204.Store(true)
 		api.log.Info("Disabled returning bids on getHeader")
 	}
 
@@ -538,21 +1098,105 @@ func (api *RelayAPI) StopServer() (err error) {
 	// wait for any active getPayload call to finish
 	api.getPayloadCallsInFlight.Wait()
 
-	// shutdown
-	return api.srv.Shutdown(context.Background())
+	// shutdown, which waits for all active requests to finish
+	if api.trustedSrv != nil {
+		if err := api.trustedSrv.Shutdown(context.Background()); err != nil {
+			api.log.WithError(err).Error("failed to shutdown trusted builder listener")
+		}
+	}
+	if api.http3Srv != nil {
+		if err := api.http3Srv.Close(); err != nil {
+			api.log.WithError(err).Error("failed to close HTTP/3 listener")
+		}
+	}
+	if api.grpcSrv != nil {
+		api.grpcSrv.GracefulStop()
+	}
+	if api.diagnosticsSrv != nil {
+		if err := api.diagnosticsSrv.Close(); err != nil {
+			api.log.WithError(err).Error("failed to close diagnostics listener")
+		}
+	}
+	err = api.srv.Shutdown(context.Background())
+	if err != nil {
+		return err
+	}
+
+	// flush pending database writes
+	api.log.Info("Flushing pending database writes...")
+	api.optimisticBlocksWG.Wait()
+	if api.isSubmissionQueueEnabled() {
+		api.submissionQueuePendingWG.Wait()
+		close(api.submissionDBC)
+		api.submissionDBProcessorsWG.Wait()
+	}
+	close(api.validatorRegC)
+	api.validatorRegProcessorsWG.Wait()
+	if api.isBidArchiveEnabled() {
+		close(api.bidArchiveC)
+		api.bidArchiveProcessorsWG.Wait()
+	}
+	if api.isProposerRequestLogEnabled() {
+		close(api.proposerRequestLogC)
+		api.proposerRequestLogWG.Wait()
+	}
+	api.backgroundDBWritesWG.Wait()
+	if api.isEventPublishingEnabled() {
+		api.events.Close()
+	}
+	if api.isBidFirehoseEnabled() {
+		api.bidFirehose.Close()
+	}
+
+	// close connections
+	api.closeConnections()
+	return nil
+}
+
+func (api *RelayAPI) closeConnections() {
+	if api.redis != nil {
+		if err := api.redis.Close(); err != nil {
+			api.log.WithError(err).Error("failed to close redis connection")
+		}
+	}
+	if api.memcached != nil {
+		if err := api.memcached.Close(); err != nil {
+			api.log.WithError(err).Error("failed to close memcached connection")
+		}
+	}
+	if api.datastore != nil {
+		for _, backend := range api.datastore.CacheBackends() {
+			if closer, ok := backend.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					api.log.WithError(err).Errorf("failed to close %s connection", backend.Name())
+				}
+			}
+		}
+	}
+	if api.db != nil {
+		if err := api.db.Close(); err != nil {
+			api.log.WithError(err).Error("failed to close database connection")
+		}
+	}
 }
 
 func (api *RelayAPI) isCapella(slot uint64) bool {
-	return hasReachedFork(slot, api.capellaEpoch) && !hasReachedFork(slot, api.denebEpoch)
+	return api.forkSchedule.ForkAtSlot(slot) == spec.DataVersionCapella
 }
 
 func (api *RelayAPI) isDeneb(slot uint64) bool {
-	return hasReachedFork(slot, api.denebEpoch)
+	return api.forkSchedule.ForkAtSlot(slot) == spec.DataVersionDeneb
+}
+
+func (api *RelayAPI) isElectra(slot uint64) bool {
+	return api.forkSchedule.ForkAtSlot(slot) == spec.DataVersionElectra
 }
 
 func (api *RelayAPI) startValidatorRegistrationDBProcessor() {
-	for valReg := range api.validatorRegC {
-		err := api.datastore.SaveValidatorRegistration(valReg)
+	defer api.validatorRegProcessorsWG.Done()
+	for reg := range api.validatorRegC {
+		valReg := reg.registration
+		err := api.datastore.SaveValidatorRegistration(valReg, reg.sourceIP)
 		if err != nil {
 			api.log.WithError(err).WithFields(logrus.Fields{
 				"reg_pubkey":       valReg.Message.Pubkey,
@@ -567,6 +1211,9 @@ func (api *RelayAPI) startValidatorRegistrationDBProcessor() {
 // simulateBlock sends a request for a block simulation to blockSimRateLimiter.
 func (api *RelayAPI) simulateBlock(ctx context.Context, opts blockSimOptions) (requestErr, validationErr error) {
 	t := time.Now()
+	if opts.requestID != "" {
+		ctx = contextWithRequestID(ctx, opts.requestID)
+	}
 	requestErr, validationErr = api.blockSimRateLimiter.Send(ctx, opts.req, opts.isHighPrio, opts.fastTrack)
 	log := opts.log.WithFields(logrus.Fields{
 		"durationMs": time.Since(t).Milliseconds(),
@@ -593,20 +1240,8 @@ func (api *RelayAPI) simulateBlock(ctx context.Context, opts blockSimOptions) (r
 }
 
 func (api *RelayAPI) demoteBuilder(pubkey string, req *common.VersionedSubmitBlockRequest, simError error) {
-	builderEntry, ok := api.blockBuildersCache[pubkey]
-	if !ok {
-		api.log.Warnf("builder %v not in the builder cache", pubkey)
-		builderEntry = &blockBuilderCacheEntry{} //nolint:exhaustruct
-	}
-	newStatus := common.BuilderStatus{
-		IsHighPrio:    builderEntry.status.IsHighPrio,
-		IsBlacklisted: builderEntry.status.IsBlacklisted,
-		IsOptimistic:  false,
-	}
-	api.log.Infof("demoted builder, new status: %v", newStatus)
-	if err := api.db.SetBlockBuilderIDStatusIsOptimistic(pubkey, false); err != nil {
-		api.log.Error(fmt.Errorf("error setting builder: %v status: %w", pubkey, err))
-	}
+	api.setBuilderNonOptimistic(pubkey)
+
 	// Write to demotions table.
 	api.log.WithFields(logrus.Fields{"builder_pubkey": pubkey}).Info("demoting builder")
 	bidTrace, err := req.BidTrace()
@@ -620,20 +1255,68 @@ func (api *RelayAPI) demoteBuilder(pubkey string, req *common.VersionedSubmitBlo
 			"simError":                 simError,
 		}).Error("failed to save demotion to database")
 	}
+	if bidTrace != nil {
+		api.publishDemotionEvent(bidTrace, simError)
+	}
 }
 
-// processOptimisticBlock is called on a new goroutine when a optimistic block
-// needs to be simulated.
-func (api *RelayAPI) processOptimisticBlock(opts blockSimOptions, simResultC chan *blockSimResult) {
-	api.optimisticBlocksInFlight.Add(1)
-	defer func() { api.optimisticBlocksInFlight.Sub(1) }()
-	api.optimisticBlocksWG.Add(1)
-	defer api.optimisticBlocksWG.Done()
-
-	ctx := context.Background()
-	submission, err := common.GetBlockSubmissionInfo(opts.req.VersionedSubmitBlockRequest)
+// demoteBuilderAfterRejectedBlock demotes the builder of a delivered block whose execution payload was rejected by the
+// beacon nodes (and thus caused a missed slot), if the block was submitted optimistically and the builder wasn't
+// demoted for it yet
+func (api *RelayAPI) demoteBuilderAfterRejectedBlock(log *logrus.Entry, bidTrace *common.BidTraceV2WithBlobFields, rejectedErr error) {
+	submission, err := api.db.GetBlockSubmissionEntry(bidTrace.Slot, bidTrace.ProposerPubkey.String(), bidTrace.BlockHash.String())
 	if err != nil {
-		opts.log.WithError(err).Error("error getting block submission info")
+		log.WithError(err).Error("failed to get block submission of rejected block")
+		return
+	} else if submission == nil || !submission.OptimisticSubmission {
+		return
+	}
+
+	// Already demoted if the simulation failed
+	demotion, err := api.db.GetBuilderDemotion(bidTrace)
+	if err == nil && demotion != nil {
+		return
+	}
+
+	builderPubkey := bidTrace.BuilderPubkey.String()
+	log.WithField("builderPubkey", builderPubkey).Warn("optimistic block rejected by beacon node, demoting builder")
+	api.setBuilderNonOptimistic(builderPubkey)
+	if err := api.db.InsertBuilderDemotionFromBidTrace(bidTrace, rejectedErr); err != nil {
+		log.WithError(err).WithField("errorWritingDemotionToDB", true).Error("failed to save demotion to database")
+	}
+	api.publishDemotionEvent(&bidTrace.BidTrace, rejectedErr)
+}
+
+// setBuilderNonOptimistic marks the builder as non-optimistic in the database
+func (api *RelayAPI) setBuilderNonOptimistic(pubkey string) {
+	builderEntry, ok := api.blockBuildersCache[pubkey]
+	if !ok {
+		api.log.Warnf("builder %v not in the builder cache", pubkey)
+		builderEntry = &blockBuilderCacheEntry{} //nolint:exhaustruct
+	}
+	newStatus := common.BuilderStatus{
+		IsHighPrio:    builderEntry.status.IsHighPrio,
+		IsBlacklisted: builderEntry.status.IsBlacklisted,
+		IsOptimistic:  false,
+	}
+	api.log.Infof("demoted builder, new status: %v", newStatus)
+	if err := api.db.SetBlockBuilderIDStatusIsOptimistic(pubkey, false); err != nil {
+		api.log.Error(fmt.Errorf("error setting builder: %v status: %w", pubkey, err))
+	}
+}
+
+// processOptimisticBlock is called on a new goroutine when a optimistic block
+// needs to be simulated.
+func (api *RelayAPI) processOptimisticBlock(opts blockSimOptions, simResultC chan *blockSimResult) {
+	api.optimisticBlocksInFlight.Add(1)
+	defer func() { api.optimisticBlocksInFlight.Sub(1) }()
+	api.optimisticBlocksWG.Add(1)
+	defer api.optimisticBlocksWG.Done()
+
+	ctx := context.Background()
+	submission, err := common.GetBlockSubmissionInfo(opts.req.VersionedSubmitBlockRequest)
+	if err != nil {
+		opts.log.WithError(err).Error("error getting block submission info")
 		return
 	}
 	builderPubkey := submission.BidTrace.BuilderPubkey.String()
@@ -688,7 +1371,7 @@ func (api *RelayAPI) processPayloadAttributes(payloadAttributes beaconclient.Pay
 
 	var withdrawalsRoot phase0.Root
 	var err error
-	if hasReachedFork(payloadAttrSlot, api.capellaEpoch) {
+	if hasReachedFork(payloadAttrSlot, api.forkSchedule.CapellaEpoch) {
 		withdrawalsRoot, err = ComputeWithdrawalsRoot(payloadAttributes.Data.PayloadAttributes.Withdrawals)
 		log = log.WithField("withdrawalsRoot", withdrawalsRoot.String())
 		if err != nil {
@@ -698,7 +1381,7 @@ func (api *RelayAPI) processPayloadAttributes(payloadAttributes beaconclient.Pay
 	}
 
 	var parentBeaconRoot *phase0.Root
-	if hasReachedFork(payloadAttrSlot, api.denebEpoch) {
+	if hasReachedFork(payloadAttrSlot, api.forkSchedule.DenebEpoch) {
 		if payloadAttributes.Data.PayloadAttributes.ParentBeaconBlockRoot == "" {
 			log.Error("parent beacon block root in payload attributes is empty")
 			return
@@ -713,24 +1396,36 @@ func (api *RelayAPI) processPayloadAttributes(payloadAttributes beaconclient.Pay
 		parentBeaconRoot = &root
 	}
 
+	// Invalid payload attributes would let blocks with the wrong randao or withdrawals win the auction, so they are
+	// discarded. If the beacon node can't be queried, the event is trusted.
+	if api.ffVerifyPayloadAttributes {
+		err = api.verifyPayloadAttributesWithBeacon(payloadAttrSlot, payloadAttributes.Data.PayloadAttributes, withdrawalsRoot)
+		if errors.Is(err, ErrBeaconPayloadAttributesMismatch) {
+			log.WithError(err).Error("discarding payload attributes")
+			return
+		} else if err != nil {
+			log.WithError(err).Warn("could not verify payload attributes with the beacon node")
+		}
+	}
+
 	api.payloadAttributesLock.Lock()
 	defer api.payloadAttributesLock.Unlock()
 
 	// Step 1: clean up old ones
-	for parentBlockHash, attr := range api.payloadAttributes {
-		if attr.slot < apiHeadSlot {
-			delete(api.payloadAttributes, getPayloadAttributesKey(parentBlockHash, attr.slot))
-		}
-	}
+	api.prunePayloadAttributesLocked(apiHeadSlot)
 
 	// Step 2: save new one
-	api.payloadAttributes[getPayloadAttributesKey(payloadAttributes.Data.ParentBlockHash, payloadAttrSlot)] = payloadAttributesHelper{
+	attrs := payloadAttributesHelper{
 		slot:              payloadAttrSlot,
 		parentHash:        payloadAttributes.Data.ParentBlockHash,
+		parentBlockNumber: payloadAttributes.Data.ParentBlockNumber,
+		parentBlockRoot:   payloadAttributes.Data.ParentBlockRoot,
 		withdrawalsRoot:   withdrawalsRoot,
 		parentBeaconRoot:  parentBeaconRoot,
 		payloadAttributes: payloadAttributes.Data.PayloadAttributes,
 	}
+	api.payloadAttributes[getPayloadAttributesKey(payloadAttributes.Data.ParentBlockHash, payloadAttrSlot)] = attrs
+	api.updateSlotContextAttrs(attrs)
 
 	log.WithFields(logrus.Fields{
 		"randao":    payloadAttributes.Data.PayloadAttributes.PrevRandao,
@@ -763,8 +1458,21 @@ func (api *RelayAPI) processNewSlot(headSlot uint64) {
 		go api.prepareBuildersForSlot(headSlot)
 	}
 
+	// drop the in-memory auction state of past slots
+	go api.pruneSlotState(headSlot)
+
+	if api.opts.BlockBuilderAPI {
+		go api.reportSubmissionDedupHits(prevHeadSlot)
+		go api.reportBuilderOperatorSubmissions(prevHeadSlot)
+	}
+
 	if api.opts.ProposerAPI {
-		go api.datastore.RefreshKnownValidators(api.log, api.beaconClient, headSlot)
+		go func() {
+			api.datastore.RefreshKnownValidators(api.log, api.beaconClient, headSlot)
+			if api.pendingRegs != nil {
+				api.processPendingRegistrations()
+			}
+		}()
 	}
 
 	// log
@@ -815,6 +1523,7 @@ func (api *RelayAPI) updateProposerDuties(headSlot uint64) {
 	api.proposerDutiesMap = dutiesMap
 	api.proposerDutiesSlot = headSlot
 	api.proposerDutiesLock.Unlock()
+	api.updateSlotContextDuty(dutiesMap)
 
 	// pretty-print
 	_duties := make([]string, len(duties))
@@ -846,6 +1555,11 @@ func (api *RelayAPI) prepareBuildersForSlot(headSlot uint64) {
 				IsBlacklisted: v.IsBlacklisted,
 				IsOptimistic:  v.IsOptimistic,
 			},
+			labels: common.BuilderLabels{
+				Operator: v.Operator,
+				Region:   v.Region,
+				Tier:     v.Tier,
+			},
 		}
 		// Try to parse builder collateral string to big int.
 		builderCollateral, ok := big.NewInt(0).SetString(v.Collateral, 10)
@@ -861,7 +1575,15 @@ func (api *RelayAPI) prepareBuildersForSlot(headSlot uint64) {
 }
 
 func (api *RelayAPI) RespondError(w http.ResponseWriter, code int, message string) {
-	api.Respond(w, code, HTTPErrorResp{code, message})
+	api.RespondErrorCode(w, code, errorCodeForStatus(code), message)
+}
+
+func (api *RelayAPI) RespondErrorCode(w http.ResponseWriter, code int, errCode ErrorCode, message string) {
+	if respW, ok := w.(*submissionResponseWriter); ok {
+		respW.errCode = errCode
+		respW.message = message
+	}
+	api.Respond(w, code, HTTPErrorResp{code, errCode, message, w.Header().Get(HeaderRequestID)})
 }
 
 func (api *RelayAPI) RespondOK(w http.ResponseWriter, response any) {
@@ -901,8 +1623,10 @@ func (api *RelayAPI) handleRoot(w http.ResponseWriter, req *http.Request) {
 
 func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Request) {
 	ua := req.UserAgent()
+	sourceIP := common.GetIPXForwardedFor(req)
 	log := api.log.WithFields(logrus.Fields{
 		"method":        "registerValidator",
+		"requestID":     getRequestID(req.Context()),
 		"ua":            ua,
 		"mevBoostV":     common.GetMevBoostVersionFromUserAgent(ua),
 		"headSlot":      api.headSlot.Load(),
@@ -916,101 +1640,35 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 	numRegProcessed := 0
 	numRegActive := 0
 	numRegNew := 0
+	numRegPending := 0
 	processingStoppedByError := false
+	regsToVerify := []*builderApiV1.SignedValidatorRegistration{}
 
 	// Setup error handling
-	handleError := func(_log *logrus.Entry, code int, msg string) {
+	handleError := func(_log *logrus.Entry, code int, errCode ErrorCode, msg string) {
 		processingStoppedByError = true
 		_log.Warnf("error: %s", msg)
-		api.RespondError(w, code, msg)
+		api.RespondErrorCode(w, code, errCode, msg)
 	}
 
 	// Start processing
 	if req.ContentLength == 0 {
 		log.Info("empty request")
-		api.RespondError(w, http.StatusBadRequest, "empty request")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "empty request")
 		return
 	}
 
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		log.WithError(err).WithField("contentLength", req.ContentLength).Warn("failed to read request body")
-		api.RespondError(w, http.StatusBadRequest, "failed to read request body")
+		api.respondBodyReadError(w, log, req, err)
 		return
 	}
 	req.Body.Close()
 
-	parseRegistration := func(value []byte) (reg *builderApiV1.SignedValidatorRegistration, err error) {
-		// Pubkey
-		_pubkey, err := jsonparser.GetUnsafeString(value, "message", "pubkey")
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (pubkey): %w", err)
-		}
-
-		pubkey, err := utils.HexToPubkey(_pubkey)
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (pubkey): %w", err)
-		}
-
-		// Timestamp
-		_timestamp, err := jsonparser.GetUnsafeString(value, "message", "timestamp")
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (timestamp): %w", err)
-		}
-
-		timestamp, err := strconv.ParseInt(_timestamp, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid timestamp: %w", err)
-		}
-		if timestamp < 0 {
-			return nil, ErrNegativeTimestamp
-		}
-
-		// GasLimit
-		_gasLimit, err := jsonparser.GetUnsafeString(value, "message", "gas_limit")
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (gasLimit): %w", err)
-		}
-
-		gasLimit, err := strconv.ParseUint(_gasLimit, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid gasLimit: %w", err)
-		}
-
-		// FeeRecipient
-		_feeRecipient, err := jsonparser.GetUnsafeString(value, "message", "fee_recipient")
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (fee_recipient): %w", err)
-		}
-
-		feeRecipient, err := utils.HexToAddress(_feeRecipient)
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (fee_recipient): %w", err)
-		}
-
-		// Signature
-		_signature, err := jsonparser.GetUnsafeString(value, "signature")
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (signature): %w", err)
-		}
-
-		signature, err := utils.HexToSignature(_signature)
-		if err != nil {
-			return nil, fmt.Errorf("registration message error (signature): %w", err)
-		}
-
-		// Construct and return full registration object
-		reg = &builderApiV1.SignedValidatorRegistration{
-			Message: &builderApiV1.ValidatorRegistration{
-				FeeRecipient: feeRecipient,
-				GasLimit:     gasLimit,
-				Timestamp:    time.Unix(timestamp, 0),
-				Pubkey:       pubkey,
-			},
-			Signature: signature,
-		}
-
-		return reg, nil
+	if err := decoder.CheckValidatorRegistrations(body); err != nil {
+		log.WithError(err).Info("invalid registrations")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, err.Error())
+		return
 	}
 
 	// Iterate over the registrations
@@ -1026,9 +1684,9 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		})
 
 		// Extract immediately necessary registration fields
-		signedValidatorRegistration, err := parseRegistration(value)
+		signedValidatorRegistration, err := decoder.DecodeValidatorRegistration(value)
 		if err != nil {
-			handleError(regLog, http.StatusBadRequest, err.Error())
+			handleError(regLog, http.StatusBadRequest, ErrorCodeDecodeFailed, err.Error())
 			return
 		}
 
@@ -1045,17 +1703,20 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		// Ensure a valid timestamp (not too early, and not too far in the future)
 		registrationTimestamp := signedValidatorRegistration.Message.Timestamp.Unix()
 		if registrationTimestamp < int64(api.genesisInfo.Data.GenesisTime) {
-			handleError(regLog, http.StatusBadRequest, "timestamp too early")
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp too early")
 			return
 		} else if registrationTimestamp > registrationTimestampUpperBound {
-			handleError(regLog, http.StatusBadRequest, "timestamp too far in the future")
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp too far in the future")
 			return
 		}
 
 		// Check if a real validator
 		isKnownValidator := api.datastore.IsKnownValidator(pkHex)
-		if !isKnownValidator {
-			handleError(regLog, http.StatusBadRequest, fmt.Sprintf("not a known validator: %s", pkHex))
+		if !isKnownValidator && pendingRegistrationsBeaconLookup {
+			isKnownValidator = api.lookupValidator(regLog, pkHex)
+		}
+		if !isKnownValidator && api.pendingRegs == nil {
+			handleError(regLog, http.StatusBadRequest, ErrorCodeUnknownValidator, fmt.Sprintf("not a known validator: %s", pkHex))
 			return
 		}
 
@@ -1068,31 +1729,49 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 			return
 		}
 
-		// Verify the signature
-		ok, err := ssz.VerifySignature(signedValidatorRegistration.Message, api.opts.EthNetDetails.DomainBuilder, signedValidatorRegistration.Message.Pubkey[:], signedValidatorRegistration.Signature[:])
-		if err != nil {
-			regLog.WithError(err).Error("error verifying registerValidator signature")
-			return
-		} else if !ok {
-			regLog.Info("invalid validator signature")
-			if api.ffRegValContinueOnInvalidSig {
-				return
-			} else {
-				handleError(regLog, http.StatusBadRequest, fmt.Sprintf("failed to verify validator signature for %s", signedValidatorRegistration.Message.Pubkey.String()))
-				return
+		// Signature is verified afterwards, concurrently for all registrations
+		regsToVerify = append(regsToVerify, signedValidatorRegistration)
+	})
+
+	// Verify the signatures, and process the registrations in the original order
+	if err == nil && !processingStoppedByError {
+		verifyErrs := api.regVerifier.Verify(regsToVerify)
+		for i, signedValidatorRegistration := range regsToVerify {
+			regLog := log.WithField("pubkey", signedValidatorRegistration.Message.Pubkey.String())
+			if errors.Is(verifyErrs[i], ErrInvalidRegistrationSignature) {
+				regLog.Info("invalid validator signature")
+				if api.ffRegValContinueOnInvalidSig {
+					continue
+				}
+				handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidSignature, fmt.Sprintf("failed to verify validator signature for %s", signedValidatorRegistration.Message.Pubkey.String()))
+				break
+			} else if verifyErrs[i] != nil {
+				regLog.WithError(verifyErrs[i]).Error("error verifying registerValidator signature")
+				continue
 			}
-		}
 
-		// Now we have a new registration to process
-		numRegNew += 1
+			// Now we have a new registration to process
+			numRegNew += 1
+
+			// Registrations of validators which are not known yet are processed after the next known validators refresh
+			reg := queuedValidatorRegistration{registration: *signedValidatorRegistration, sourceIP: sourceIP}
+			if api.pendingRegs != nil && !api.datastore.IsKnownValidator(common.NewPubkeyHex(signedValidatorRegistration.Message.Pubkey.String())) {
+				if api.pendingRegs.add(reg, time.Now()) {
+					numRegPending += 1
+				} else {
+					regLog.Warn("pending validator registrations full")
+				}
+				continue
+			}
 
-		// Save to database
-		select {
-		case api.validatorRegC <- *signedValidatorRegistration:
-		default:
-			regLog.Error("validator registration channel full")
+			// Save to database
+			select {
+			case api.validatorRegC <- reg:
+			default:
+				regLog.Error("validator registration channel full")
+			}
 		}
-	})
+	}
 
 	log = log.WithFields(logrus.Fields{
 		"timeNeededSec":             time.Since(start).Seconds(),
@@ -1101,15 +1780,19 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		"numRegistrationsActive":    numRegActive,
 		"numRegistrationsProcessed": numRegProcessed,
 		"numRegistrationsNew":       numRegNew,
+		"numRegistrationsPending":   numRegPending,
 		"processingStoppedByError":  processingStoppedByError,
 	})
 
 	if err != nil {
-		handleError(log, http.StatusBadRequest, "error in traversing json")
+		handleError(log, http.StatusBadRequest, ErrorCodeDecodeFailed, "error in traversing json")
 		return
 	}
 
 	log.Info("validator registrations call processed")
+	if numRegProcessed > 0 {
+		api.countEpoch(log, api.headSlot.Load()/common.SlotsPerEpoch, datastore.RedisEpochCountFieldRegistrations, int64(numRegProcessed))
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -1123,7 +1806,7 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 
 	slot, err := strconv.ParseUint(slotStr, 10, 64)
 	if err != nil {
-		api.RespondError(w, http.StatusBadRequest, common.ErrInvalidSlot.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSlot, common.ErrInvalidSlot.Error())
 		return
 	}
 
@@ -1133,6 +1816,7 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 
 	log := api.log.WithFields(logrus.Fields{
 		"method":           "getHeader",
+		"requestID":        getRequestID(req.Context()),
 		"headSlot":         headSlot,
 		"slot":             slotStr,
 		"parentHash":       parentHashHex,
@@ -1144,22 +1828,36 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 		"msIntoSlot":       msIntoSlot,
 	})
 
+	// Save the request and the served bid to the proposer request log
+	var requestLogEntry *database.ProposerRequestEntry
+	if api.isProposerRequestLogEnabled() {
+		proposerW := &proposerResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		w = proposerW
+		requestLogEntry = newProposerRequestEntry(req, "getHeader", requestTime)
+		requestLogEntry.Slot = slot
+		requestLogEntry.ProposerPubkey = proposerPubkeyHex
+		requestLogEntry.ParentHash = parentHashHex
+		requestLogEntry.MsIntoSlot = msIntoSlot
+		defer api.logProposerRequest(proposerW, requestLogEntry)
+	}
+
 	if len(proposerPubkeyHex) != 98 {
-		api.RespondError(w, http.StatusBadRequest, common.ErrInvalidPubkey.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPubkey, common.ErrInvalidPubkey.Error())
 		return
 	}
 
 	if len(parentHashHex) != 66 {
-		api.RespondError(w, http.StatusBadRequest, common.ErrInvalidHash.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidHash, common.ErrInvalidHash.Error())
 		return
 	}
 
 	if slot < headSlot {
-		api.RespondError(w, http.StatusBadRequest, "slot is too old")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeSlotMismatch, "slot is too old")
 		return
 	}
 
 	log.Debug("getHeader request received")
+	api.countSlotRequest(log, slot, datastore.RedisSlotRequestFieldGetHeader)
 
 	if slices.Contains(apiNoHeaderUserAgents, ua) {
 		log.Info("rejecting getHeader by user agent")
@@ -1167,40 +1865,93 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if api.ffForceGetHeader204 {
+	if api.ffForceGetHeader204.Load() {
 		log.Info("forced getHeader 204 response")
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
+	// Don't serve possibly stale bids while the beacon nodes are not synced
+	if api.beaconUnsynced.Load() {
+		log.Info("beacon nodes are not synced, not serving getHeader")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Only allow requests for the current slot after a minimum time
+	tunables := api.getTunables()
+	if tunables.GetHeaderRequestMinMs != 0 && msIntoSlot < int64(tunables.GetHeaderRequestMinMs) {
+		log.Info("getHeader sent too early")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	// Only allow requests for the current slot until a certain cutoff time
-	if getHeaderRequestCutoffMs > 0 && msIntoSlot > 0 && msIntoSlot > int64(getHeaderRequestCutoffMs) {
+	if tunables.GetHeaderRequestCutoffMs > 0 && msIntoSlot > 0 && msIntoSlot > int64(tunables.GetHeaderRequestCutoffMs) {
 		log.Info("getHeader sent too late")
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	bid, err := api.redis.GetBestBid(slot, parentHashHex, proposerPubkeyHex)
+	// Hold the response until the target time into the slot, and serve the best bid at that moment
+	var delayedCall *datastore.GetHeaderCall
+	if tunables.GetHeaderResponseTargetMs > 0 {
+		delay := getHeaderDelay(tunables, slotStartTimestamp, time.Now())
+		log = log.WithField("responseDelayMs", delay.Milliseconds())
+		if !waitForGetHeaderTarget(req.Context(), delay) {
+			log.Info("getHeader request cancelled while holding the response")
+			return
+		}
+		delayedCall = &datastore.GetHeaderCall{
+			ProposerPubkey: proposerPubkeyHex,
+			ParentHash:     parentHashHex,
+			RequestedAtMs:  requestTime.UnixMilli(),
+		}
+		defer api.recordGetHeaderCall(log, slot, delayedCall)
+	}
+
+	bid, err := api.getBestBid(log, slot, parentHashHex, proposerPubkeyHex)
+	log = log.WithField("timestampAfterLoadBid", time.Now().UTC().UnixMilli())
 	if err != nil {
 		log.WithError(err).Error("could not get bid")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInternalError, err.Error())
 		return
 	}
 
+	// In aggregation mode, serve the best of the local bid and the bids of the upstream relays
+	bidOrigin := bidOriginLocal
+	if len(api.upstreamRelays) > 0 {
+		bid, bidOrigin = api.mergeUpstreamBids(log, slot, parentHashHex, proposerPubkeyHex, bid)
+		log = log.WithFields(logrus.Fields{
+			"bidOrigin":                    bidOrigin,
+			"timestampAfterUpstreamHeader": time.Now().UTC().UnixMilli(),
+		})
+	}
+
 	if bid == nil || bid.IsEmpty() {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
+	// Only return bids of the fork that is active at the requested slot
+	if slotFork := api.forkSchedule.ForkAtSlot(slot); bid.Version != slotFork {
+		log.WithFields(logrus.Fields{
+			"bidVersion":  bid.Version.String(),
+			"slotVersion": slotFork.String(),
+		}).Warn("bid version does not match the fork of the slot")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	value, err := bid.Value()
 	if err != nil {
 		log.WithError(err).Info("could not get bid value")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInternalError, err.Error())
 	}
 	blockHash, err := bid.BlockHash()
 	if err != nil {
 		log.WithError(err).Info("could not get bid block hash")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInternalError, err.Error())
 	}
 
 	// Error on bid without value
@@ -1209,22 +1960,102 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Don't return bids below the min bid of the proposer
+	if !api.checkProposerMinBid(log, proposerPubkeyHex, value.ToBig()) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Don't return bids which don't satisfy the inclusion constraints of the slot (upstream bids can't be checked, as
+	// the payload is not known)
+	if bidOrigin != bidOriginLocal {
+		if constraints, err := api.datastore.GetInclusionConstraints(slot); err != nil || constraints != nil {
+			log.Info("not serving upstream bid for a slot with inclusion constraints")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	} else if !api.checkBidConstraints(log, slot, proposerPubkeyHex, blockHash.String()) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if delayedCall != nil {
+		delayedCall.BlockHash = blockHash.String()
+		delayedCall.Value = value.Dec()
+	}
+	if requestLogEntry != nil {
+		requestLogEntry.BlockHash = blockHash.String()
+	}
+
 	log.WithFields(logrus.Fields{
-		"value":     value.String(),
-		"blockHash": blockHash.String(),
+		"value":             value.String(),
+		"blockHash":         blockHash.String(),
+		"requestDurationMs": time.Since(requestTime).Milliseconds(),
 	}).Info("bid delivered")
+	api.countEpoch(log, slot/common.SlotsPerEpoch, datastore.RedisEpochCountFieldHeadersServed, 1)
 	api.RespondOK(w, bid)
 }
 
+// checkProposerSignature verifies the proposer signature with the signing domain of the block's slot. The block must be
+// of the fork that is active at its slot.
 func (api *RelayAPI) checkProposerSignature(block *common.VersionedSignedBlindedBeaconBlock, pubKey []byte) (bool, error) {
-	switch block.Version { //nolint:exhaustive
-	case spec.DataVersionCapella:
-		return verifyBlockSignature(block, api.opts.EthNetDetails.DomainBeaconProposerCapella, pubKey)
-	case spec.DataVersionDeneb:
-		return verifyBlockSignature(block, api.opts.EthNetDetails.DomainBeaconProposerDeneb, pubKey)
-	default:
+	if block.Version != spec.DataVersionCapella && block.Version != spec.DataVersionDeneb && block.Version != spec.DataVersionElectra {
 		return false, errors.New("unsupported consensus data version")
 	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return false, err
+	}
+	slotFork := api.forkSchedule.ForkAtSlot(uint64(slot))
+	if block.Version != slotFork {
+		return false, fmt.Errorf("%w: got %s, expected %s for slot %d", ErrBlockVersionMismatch, block.Version.String(), slotFork.String(), slot)
+	}
+
+	domain, err := api.opts.EthNetDetails.DomainBeaconProposer(slotFork)
+	if err != nil {
+		return false, err
+	}
+	return verifyBlockSignature(block, domain, pubKey)
+}
+
+// checkParentBeaconRoot checks the parent root of a Deneb or Electra blinded block against the parent beacon block root
+// of the payload attributes the payload was built on. If the payload attributes aren't known (anymore), the check is
+// skipped with a warning and counted as getpayload.parent_beacon_root_unchecked on the diagnostics listener.
+func (api *RelayAPI) checkParentBeaconRoot(log *logrus.Entry, block *common.VersionedSignedBlindedBeaconBlock, payload *builderApi.VersionedSubmitBlindedBlockResponse) error {
+	var parentHash phase0.Hash32
+	var slot phase0.Slot
+	switch {
+	case block.Version == spec.DataVersionDeneb && payload.Deneb != nil:
+		parentHash, slot = payload.Deneb.ExecutionPayload.ParentHash, block.Deneb.Message.Slot
+	case block.Version == spec.DataVersionElectra && payload.Electra != nil:
+		parentHash, slot = payload.Electra.ExecutionPayload.ParentHash, block.Electra.Message.Slot
+	default:
+		return nil
+	}
+	attrs, ok := api.getPayloadAttributes(parentHash.String(), uint64(slot))
+	if !ok || attrs.parentBeaconRoot == nil {
+		log.WithField("hasPayloadAttributes", ok).Warn("unknown parent beacon block root, skipping the parent beacon block root check")
+		getPayloadExpvar.Add("parent_beacon_root_unchecked", 1)
+		return nil
+	}
+	return EqBlindedBlockParentBeaconRoot(block, *attrs.parentBeaconRoot)
+}
+
+// checkExecutionRequests checks the execution requests of an Electra blinded block against the ones the builder
+// submitted with the payload, which are recorded in the bid trace. If the bid trace isn't available, the check is
+// skipped with a warning and counted as getpayload.execution_requests_unchecked on the diagnostics listener.
+func (api *RelayAPI) checkExecutionRequests(log *logrus.Entry, block *common.VersionedSignedBlindedBeaconBlock, proposerPubkey, blockHash string) error {
+	if block.Version != spec.DataVersionElectra {
+		return nil
+	}
+	bidTrace, err := api.datastore.GetBidTrace(log, uint64(block.Electra.Message.Slot), proposerPubkey, blockHash)
+	if err != nil || bidTrace.ExecutionRequestsRoot == nil {
+		log.WithError(err).Warn("unknown execution requests of the payload, skipping the execution requests check")
+		getPayloadExpvar.Add("execution_requests_unchecked", 1)
+		return nil
+	}
+	return EqBlindedBlockExecutionRequests(block, *bidTrace.ExecutionRequestsRoot)
 }
 
 func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request) {
@@ -1236,6 +2067,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	receivedAt := time.Now().UTC()
 	log := api.log.WithFields(logrus.Fields{
 		"method":                "getPayload",
+		"requestID":             getRequestID(req.Context()),
 		"ua":                    ua,
 		"mevBoostV":             common.GetMevBoostVersionFromUserAgent(ua),
 		"contentLength":         req.ContentLength,
@@ -1257,22 +2089,15 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	// Read the body first, so we can decode it later
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		if strings.Contains(err.Error(), "i/o timeout") {
-			log.WithError(err).Error("getPayload request failed to decode (i/o timeout)")
-			api.RespondError(w, http.StatusInternalServerError, err.Error())
-			return
-		}
-
-		log.WithError(err).Error("could not read body of request from the beacon node")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.respondBodyReadError(w, log, req, err)
 		return
 	}
 
 	// Decode payload
-	payload := new(common.VersionedSignedBlindedBeaconBlock)
-	if err := json.NewDecoder(bytes.NewReader(body)).Decode(payload); err != nil {
+	payload, err := decoder.DecodeSignedBlindedBeaconBlock(body)
+	if err != nil {
 		log.WithError(err).Warn("failed to decode getPayload request")
-		api.RespondError(w, http.StatusBadRequest, "failed to decode payload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, "failed to decode payload")
 		return
 	}
 
@@ -1281,19 +2106,19 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	slot, err := payload.Slot()
 	if err != nil {
 		log.WithError(err).Warn("failed to get payload slot")
-		api.RespondError(w, http.StatusBadRequest, "failed to get payload slot")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "failed to get payload slot")
 		return
 	}
 	blockHash, err := payload.ExecutionBlockHash()
 	if err != nil {
 		log.WithError(err).Warn("failed to get payload block hash")
-		api.RespondError(w, http.StatusBadRequest, "failed to get payload block hash")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "failed to get payload block hash")
 		return
 	}
 	proposerIndex, err := payload.ProposerIndex()
 	if err != nil {
 		log.WithError(err).Warn("failed to get payload proposer index")
-		api.RespondError(w, http.StatusBadRequest, "failed to get payload proposer index")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "failed to get payload proposer index")
 		return
 	}
 	slotStartTimestamp := api.genesisInfo.Data.GenesisTime + (uint64(slot) * common.SecondsPerSlot)
@@ -1308,6 +2133,27 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		"proposerIndex":        proposerIndex,
 	})
 
+	// Save the request to the proposer request log
+	var requestLogEntry *database.ProposerRequestEntry
+	if api.isProposerRequestLogEnabled() {
+		proposerW := &proposerResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		w = proposerW
+		requestLogEntry = newProposerRequestEntry(req, "getPayload", receivedAt)
+		requestLogEntry.Slot = uint64(slot)
+		requestLogEntry.BlockHash = blockHash.String()
+		requestLogEntry.MsIntoSlot = msIntoSlot
+		defer api.logProposerRequest(proposerW, requestLogEntry)
+	}
+
+	// The response shape depends on the fork the proposer client expects
+	responseVersion, err := negotiateGetPayloadResponse(req.Header.Get(HeaderEthConsensusVersion), payload.Version)
+	if err != nil {
+		log.WithError(err).Warn("consensus version mismatch")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeForkMismatch, err.Error())
+		return
+	}
+	log = log.WithField("responseVersion", responseVersion)
+
 	// Ensure the proposer index is expected
 	api.proposerDutiesLock.RLock()
 	slotDuty := api.proposerDutiesMap[uint64(slot)]
@@ -1318,7 +2164,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		log = log.WithField("feeRecipient", slotDuty.Entry.Message.FeeRecipient.String())
 		if slotDuty.ValidatorIndex != uint64(proposerIndex) {
 			log.WithField("expectedProposerIndex", slotDuty.ValidatorIndex).Warn("not the expected proposer index")
-			api.RespondError(w, http.StatusBadRequest, "not the expected proposer index")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeProposerMismatch, "not the expected proposer index")
 			return
 		}
 	}
@@ -1327,18 +2173,21 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	proposerPubkey, found := api.datastore.GetKnownValidatorPubkeyByIndex(uint64(proposerIndex))
 	if !found {
 		log.Errorf("could not find proposer pubkey for index %d", proposerIndex)
-		api.RespondError(w, http.StatusBadRequest, "could not match proposer index to pubkey")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeUnknownProposerDuty, "could not match proposer index to pubkey")
 		return
 	}
 
 	// Add proposer pubkey to logs
 	log = log.WithField("proposerPubkey", proposerPubkey.String())
+	if requestLogEntry != nil {
+		requestLogEntry.ProposerPubkey = proposerPubkey.String()
+	}
 
 	// Create a BLS pubkey from the hex pubkey
 	pk, err := utils.HexToPubkey(proposerPubkey.String())
 	if err != nil {
 		log.WithError(err).Warn("could not convert pubkey to phase0.BLSPubKey")
-		api.RespondError(w, http.StatusBadRequest, "could not convert pubkey to phase0.BLSPubKey")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInternalError, "could not convert pubkey to phase0.BLSPubKey")
 		return
 	}
 
@@ -1350,26 +2199,59 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 			log.Info("payload_invalid_sig: ", string(txt), "pubkey:", proposerPubkey.String())
 		}
 		log.WithError(err).Warn("could not verify payload signature")
-		api.RespondError(w, http.StatusBadRequest, "could not verify payload signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "could not verify payload signature")
 		return
 	}
 
 	// Log about received payload (with a valid proposer signature)
 	log = log.WithField("timestampAfterSignatureVerify", time.Now().UTC().UnixMilli())
 	log.Info("getPayload request received")
+	api.countSlotRequest(log, uint64(slot), datastore.RedisSlotRequestFieldGetPayload)
+
+	// Record the first getPayload request for this slot. Retries of the same request are fine, but a request
+	// for a different block hash is a potential equivocation and must not be served.
+	firstRequest, err := api.redis.CheckAndSetGetPayloadRequest(uint64(slot), blockHash.String(), body)
+	if errors.Is(err, datastore.ErrGetPayloadEquivocation) {
+		log.WithField("firstBlockHash", firstRequest.BlockHash).Warn("getPayload equivocation - request for a different block hash than the first request for this slot")
+		api.backgroundDBWritesWG.Add(1)
+		go func() {
+			defer api.backgroundDBWritesWG.Done()
+			err := api.db.InsertGetPayloadEquivocation(uint64(slot), proposerPubkey.String(), firstRequest.BlockHash, blockHash.String(), payload, msIntoSlot)
+			if err != nil {
+				log.WithError(err).Error("failed to insert getPayload equivocation into db")
+			}
+		}()
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeGetPayloadEquivocation, "getPayload for a different block hash was already received for this slot")
+		return
+	} else if err != nil {
+		log.WithError(err).Error("redis.CheckAndSetGetPayloadRequest failed")
+	}
+
+	// Bids of upstream relays (aggregation mode) are delivered by the upstream relay
+	if len(api.upstreamRelays) > 0 {
+		upstreamRelay, err := api.redis.GetUpstreamBid(uint64(slot), blockHash.String())
+		if err != nil {
+			log.WithError(err).Error("failed to get upstream relay of the bid")
+		} else if upstreamRelay != "" {
+			api.proxyUpstreamGetPayload(w, log, upstreamRelay, body)
+			return
+		}
+	}
 
 	var getPayloadResp *builderApi.VersionedSubmitBlindedBlockResponse
 	var msNeededForPublishing uint64
+	var publishResults *beaconclient.PublishResults // outcome per beacon node, nil if not published by this instance
+	var blockRejectedErr error                      // set if no beacon node accepted the block because of its execution payload
 
 	// Save information about delivered payload
 	defer func() {
-		bidTrace, err := api.redis.GetBidTrace(uint64(slot), proposerPubkey.String(), blockHash.String())
+		bidTrace, err := api.datastore.GetBidTrace(log, uint64(slot), proposerPubkey.String(), blockHash.String())
 		if err != nil {
-			log.WithError(err).Info("failed to get bidTrace for delivered payload from redis")
+			log.WithError(err).Info("failed to get bidTrace for delivered payload")
 			return
 		}
 
-		err = api.db.SaveDeliveredPayload(bidTrace, payload, decodeTime, msNeededForPublishing)
+		err = api.db.SaveDeliveredPayload(bidTrace, payload, decodeTime, msNeededForPublishing, publishResults.Outcomes(), msIntoSlot, api.opts.RelayMode)
 		if err != nil {
 			log.WithError(err).WithFields(logrus.Fields{
 				"bidTrace": bidTrace,
@@ -1377,6 +2259,15 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 			}).Error("failed to save delivered payload")
 		}
 
+		api.publishEvent(&events.Event{
+			Type:           events.TypePayloadDelivered,
+			Slot:           bidTrace.Slot,
+			BuilderPubkey:  bidTrace.BuilderPubkey.String(),
+			ProposerPubkey: bidTrace.ProposerPubkey.String(),
+			BlockHash:      bidTrace.BlockHash.String(),
+			Value:          bidTrace.Value.Dec(),
+		})
+
 		// Increment builder stats
 		err = api.db.IncBlockBuilderStatsAfterGetPayload(bidTrace.BuilderPubkey.String())
 		if err != nil {
@@ -1386,6 +2277,11 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		// Wait until optimistic blocks are complete.
 		api.optimisticBlocksWG.Wait()
 
+		// A rejected optimistic block causes a missed slot, demote the builder
+		if blockRejectedErr != nil {
+			api.demoteBuilderAfterRejectedBlock(log, bidTrace, blockRejectedErr)
+		}
+
 		// Check if there is a demotion for the winning block.
 		_, err = api.db.GetBuilderDemotion(bidTrace)
 		// If demotion not found, we are done!
@@ -1410,7 +2306,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		signedBeaconBlock, err := common.SignedBlindedBeaconBlockToBeaconBlock(payload, getPayloadResp)
 		if err != nil {
 			log.WithError(err).Error("failed to convert signed blinded beacon block to beacon block")
-			api.RespondError(w, http.StatusInternalServerError, "failed to convert signed blinded beacon block to beacon block")
+			api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternalError, "failed to convert signed blinded beacon block to beacon block")
 			return
 		}
 
@@ -1458,7 +2354,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 				bid, err := api.db.GetBlockSubmissionEntry(uint64(slot), proposerPubkey.String(), blockHash.String())
 				if errors.Is(err, sql.ErrNoRows) {
 					log.Warn("failed getting execution payload (2/2) - payload not found, block was never submitted to this relay")
-					api.RespondError(w, http.StatusBadRequest, "no execution payload for this request - block was never seen by this relay")
+					api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadNotFound, "no execution payload for this request - block was never seen by this relay")
 				} else if err != nil {
 					log.WithError(err).Error("failed getting execution payload (2/2) - payload not found, and error on checking bids")
 				} else if bid.EligibleAt.Valid {
@@ -1469,7 +2365,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 			} else { // some other error
 				log.WithError(err).Error("failed getting execution payload (2/2) - error")
 			}
-			api.RespondError(w, http.StatusBadRequest, "no execution payload for this request")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadNotFound, "no execution payload for this request")
 			return
 		}
 	}
@@ -1484,17 +2380,17 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		if errors.Is(err, datastore.ErrAnotherPayloadAlreadyDeliveredForSlot) {
 			// BAD VALIDATOR, 2x GETPAYLOAD FOR DIFFERENT PAYLOADS
 			log.Warn("validator called getPayload twice for different payload hashes")
-			api.RespondError(w, http.StatusBadRequest, "another payload for this slot was already delivered")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "another payload for this slot was already delivered")
 			return
 		} else if errors.Is(err, datastore.ErrPastSlotAlreadyDelivered) {
 			// BAD VALIDATOR, 2x GETPAYLOAD FOR PAST SLOT
 			log.Warn("validator called getPayload for past slot")
-			api.RespondError(w, http.StatusBadRequest, "payload for this slot was already delivered")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "payload for this slot was already delivered")
 			return
 		} else if errors.Is(err, redis.TxFailedErr) {
 			// BAD VALIDATOR, 2x GETPAYLOAD + RACE
 			log.Warn("validator called getPayload twice (race)")
-			api.RespondError(w, http.StatusBadRequest, "payload for this slot was already delivered (race)")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "payload for this slot was already delivered (race)")
 			return
 		}
 		log.WithError(err).Error("redis.CheckAndSetLastSlotAndHashDelivered failed")
@@ -1510,12 +2406,15 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 			log.Info("waiting until slot start t=0")
 			time.Sleep(time.Duration(delayMillis) * time.Millisecond)
 		}
-	} else if getPayloadRequestCutoffMs > 0 && msIntoSlot > int64(getPayloadRequestCutoffMs) {
+	} else if cutoffMs := api.getTunables().GetPayloadRequestCutoffMs; cutoffMs > 0 && msIntoSlot > int64(cutoffMs) {
 		// Reject requests after cutoff time
 		log.Warn("getPayload sent too late")
-		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("sent too late - %d ms into slot", msIntoSlot))
+		getPayloadExpvar.Add("too_late", 1)
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeRequestTooLate, fmt.Sprintf("sent too late - %d ms into slot", msIntoSlot))
 
+		api.backgroundDBWritesWG.Add(1)
 		go func() {
+			defer api.backgroundDBWritesWG.Done()
 			err := api.db.InsertTooLateGetPayload(uint64(slot), proposerPubkey.String(), blockHash.String(), slotStartTimestamp, uint64(receivedAt.UnixMilli()), uint64(decodeTime.UnixMilli()), uint64(msIntoSlot))
 			if err != nil {
 				log.WithError(err).Error("failed to insert payload too late into db")
@@ -1526,9 +2425,27 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 
 	// Check that BlindedBlockContent fields (sent by the proposer) match our known BlockContents
 	err = EqBlindedBlockContentsToBlockContents(payload, getPayloadResp)
-	if err != nil {
+	if errors.Is(err, ErrWithdrawalsRootMismatch) {
+		log.WithError(err).Warn("withdrawals root not matching known ExecutionPayload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeWithdrawalsRootMismatch, "invalid withdrawals root")
+		return
+	} else if err != nil {
 		log.WithError(err).Warn("ExecutionPayloadHeader not matching known ExecutionPayload")
-		api.RespondError(w, http.StatusBadRequest, "invalid execution payload header")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadMismatch, "invalid execution payload header")
+		return
+	}
+
+	// Check that the block is built on the parent beacon block root the payload was simulated with
+	if err := api.checkParentBeaconRoot(log, payload, getPayloadResp); err != nil {
+		log.WithError(err).Warn("parent root not matching the parent beacon block root of the payload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeParentBeaconRootMismatch, "invalid parent beacon block root")
+		return
+	}
+
+	// Check that the block includes the execution requests of the payload
+	if err := api.checkExecutionRequests(log, payload, proposerPubkey.String(), blockHash.String()); err != nil {
+		log.WithError(err).Warn("execution requests not matching the execution requests of the payload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeExecutionRequestsMismatch, "invalid execution requests")
 		return
 	}
 
@@ -1538,14 +2455,22 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	signedBeaconBlock, err := common.SignedBlindedBeaconBlockToBeaconBlock(payload, getPayloadResp)
 	if err != nil {
 		log.WithError(err).Error("failed to convert signed blinded beacon block to beacon block")
-		api.RespondError(w, http.StatusInternalServerError, "failed to convert signed blinded beacon block to beacon block")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternalError, "failed to convert signed blinded beacon block to beacon block")
 		return
 	}
-	code, err := api.beaconClient.PublishBlock(signedBeaconBlock) // errors are logged inside
+	code, publishResults, err := api.publishBlockOnce(log, uint64(slot), blockHash.String(), signedBeaconBlock)
+	if reason, ok := publishResults.PayloadRejection(); ok {
+		blockRejectedErr = fmt.Errorf("%w: %s", ErrBlockRejectedOnPublish, reason)
+	}
 	if err != nil || (code != http.StatusOK && code != http.StatusAccepted) {
-		log.WithError(err).WithField("code", code).Error("failed to publish block")
-		api.RespondError(w, http.StatusBadRequest, "failed to publish block")
-		return
+		if !api.ffReturnPayloadOnPublishFailure {
+			log.WithError(err).WithField("code", code).Error("failed to publish block")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePublishFailed, "failed to publish block")
+			return
+		}
+
+		// The proposer can still try to publish the block through its own beacon node
+		log.WithError(err).WithField("code", code).Error("failed to publish block, returning payload to proposer anyway")
 	}
 
 	timeAfterPublish := time.Now().UTC().UnixMilli()
@@ -1554,10 +2479,10 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	log.WithField("msNeededForPublishing", msNeededForPublishing).Info("block published through beacon node")
 
 	// give the beacon network some time to propagate the block
-	time.Sleep(time.Duration(getPayloadResponseDelayMs) * time.Millisecond)
+	time.Sleep(time.Duration(api.getTunables().GetPayloadResponseDelayMs) * time.Millisecond)
 
 	// respond to the HTTP request
-	api.RespondOK(w, getPayloadResp)
+	api.respondGetPayload(w, getPayloadResp, responseVersion)
 	blockNumber, err := payload.ExecutionBlockNumber()
 	if err != nil {
 		log.WithError(err).Info("failed to get block number")
@@ -1571,11 +2496,13 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		"blockNumber": blockNumber,
 	})
 	// deneb specific logging
-	if getPayloadResp.Deneb != nil {
+	if blobsBundle, err := getPayloadResp.BlobsBundle(); err == nil {
+		blobGasUsed, _ := getPayloadResp.BlobGasUsed()
+		excessBlobGas, _ := getPayloadResp.ExcessBlobGas()
 		log = log.WithFields(logrus.Fields{
-			"numBlobs":      len(getPayloadResp.Deneb.BlobsBundle.Blobs),
-			"blobGasUsed":   getPayloadResp.Deneb.ExecutionPayload.BlobGasUsed,
-			"excessBlobGas": getPayloadResp.Deneb.ExecutionPayload.ExcessBlobGas,
+			"numBlobs":      len(blobsBundle.Blobs),
+			"blobGasUsed":   blobGasUsed,
+			"excessBlobGas": excessBlobGas,
 		})
 	}
 	log.Info("execution payload delivered")
@@ -1597,57 +2524,71 @@ func (api *RelayAPI) handleBuilderGetValidators(w http.ResponseWriter, req *http
 }
 
 func (api *RelayAPI) checkSubmissionFeeRecipient(w http.ResponseWriter, log *logrus.Entry, bidTrace *builderApiV1.BidTrace) (uint64, bool) {
-	api.proposerDutiesLock.RLock()
-	slotDuty := api.proposerDutiesMap[bidTrace.Slot]
-	api.proposerDutiesLock.RUnlock()
+	slotDuty := api.getProposerDuty(bidTrace.Slot)
 	if slotDuty == nil {
 		log.Warn("could not find slot duty")
-		api.RespondError(w, http.StatusBadRequest, "could not find slot duty")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeUnknownProposerDuty, "could not find slot duty")
 		return 0, false
 	} else if !strings.EqualFold(slotDuty.Entry.Message.FeeRecipient.String(), bidTrace.ProposerFeeRecipient.String()) {
 		log.WithFields(logrus.Fields{
 			"expectedFeeRecipient": slotDuty.Entry.Message.FeeRecipient.String(),
 			"actualFeeRecipient":   bidTrace.ProposerFeeRecipient.String(),
 		}).Info("fee recipient does not match")
-		api.RespondError(w, http.StatusBadRequest, "fee recipient does not match")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeFeeRecipientMismatch, "fee recipient does not match")
 		return 0, false
 	}
 	return slotDuty.Entry.Message.GasLimit, true
 }
 
 func (api *RelayAPI) checkSubmissionPayloadAttrs(w http.ResponseWriter, log *logrus.Entry, submission *common.BlockSubmissionInfo) (payloadAttributesHelper, bool) {
-	api.payloadAttributesLock.RLock()
-	attrs, ok := api.payloadAttributes[getPayloadAttributesKey(submission.BidTrace.ParentHash.String(), submission.BidTrace.Slot)]
-	api.payloadAttributesLock.RUnlock()
+	attrs, ok := api.getPayloadAttributes(submission.BidTrace.ParentHash.String(), submission.BidTrace.Slot)
+	if !ok && api.hasPayloadAttributesForSlot(submission.BidTrace.Slot) {
+		log.Info("parent hash is not the current head")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeParentHashMismatch, "parent hash is not the current head")
+		return attrs, false
+	}
 	if !ok || submission.BidTrace.Slot != attrs.slot {
 		log.WithFields(logrus.Fields{
 			"attributesFound": ok,
 			"payloadSlot":     submission.BidTrace.Slot,
 			"attrsSlot":       attrs.slot,
 		}).Warn("payload attributes not (yet) known")
-		api.RespondError(w, http.StatusBadRequest, "payload attributes not (yet) known")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAttributesUnknown, "payload attributes not (yet) known")
 		return attrs, false
 	}
 
 	if submission.PrevRandao.String() != attrs.payloadAttributes.PrevRandao {
 		msg := fmt.Sprintf("incorrect prev_randao - got: %s, expected: %s", submission.PrevRandao.String(), attrs.payloadAttributes.PrevRandao)
 		log.Info(msg)
-		api.RespondError(w, http.StatusBadRequest, msg)
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePrevRandaoMismatch, msg)
 		return attrs, false
 	}
 
-	if hasReachedFork(submission.BidTrace.Slot, api.capellaEpoch) { // Capella requires correct withdrawals
-		withdrawalsRoot, err := ComputeWithdrawalsRoot(submission.Withdrawals)
-		if err != nil {
-			log.WithError(err).Warn("could not compute withdrawals root from payload")
-			api.RespondError(w, http.StatusBadRequest, "could not compute withdrawals root")
-			return attrs, false
+	if attrs.parentBlockNumber != 0 && submission.BlockNumber != attrs.parentBlockNumber+1 {
+		msg := fmt.Sprintf("incorrect block number - got: %d, expected: %d", submission.BlockNumber, attrs.parentBlockNumber+1)
+		log.Info(msg)
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeBlockNumberMismatch, msg)
+		return attrs, false
+	}
+
+	if hasReachedFork(submission.BidTrace.Slot, api.forkSchedule.CapellaEpoch) { // Capella requires correct withdrawals
+		var withdrawalsRoot phase0.Root
+		if submission.WithdrawalsRoot != nil { // header-only submission
+			withdrawalsRoot = *submission.WithdrawalsRoot
+		} else {
+			root, err := ComputeWithdrawalsRoot(submission.Withdrawals)
+			if err != nil {
+				log.WithError(err).Warn("could not compute withdrawals root from payload")
+				api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "could not compute withdrawals root")
+				return attrs, false
+			}
+			withdrawalsRoot = root
 		}
 
 		if withdrawalsRoot != attrs.withdrawalsRoot {
 			msg := fmt.Sprintf("incorrect withdrawals root - got: %s, expected: %s", withdrawalsRoot.String(), attrs.withdrawalsRoot.String())
 			log.Info(msg)
-			api.RespondError(w, http.StatusBadRequest, msg)
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeWithdrawalsRootMismatch, msg)
 			return attrs, false
 		}
 	}
@@ -1656,21 +2597,38 @@ func (api *RelayAPI) checkSubmissionPayloadAttrs(w http.ResponseWriter, log *log
 }
 
 func (api *RelayAPI) checkSubmissionSlotDetails(w http.ResponseWriter, log *logrus.Entry, headSlot uint64, payload *common.VersionedSubmitBlockRequest, submission *common.BlockSubmissionInfo) bool {
+	if payload.Deneb != nil && api.ffDisableDenebSubmissions.Load() {
+		log.Info("rejecting submission - deneb submissions are disabled")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeForkMismatch, "deneb submissions are disabled")
+		return false
+	}
+
+	if api.isElectra(submission.BidTrace.Slot) && payload.Electra == nil {
+		log.Info("rejecting submission - non electra payload for electra fork")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeForkMismatch, "not electra payload")
+		return false
+	}
+
 	if api.isDeneb(submission.BidTrace.Slot) && payload.Deneb == nil {
 		log.Info("rejecting submission - non deneb payload for deneb fork")
-		api.RespondError(w, http.StatusBadRequest, "not deneb payload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeForkMismatch, "not deneb payload")
 		return false
 	}
 
 	if api.isCapella(submission.BidTrace.Slot) && payload.Capella == nil {
 		log.Info("rejecting submission - non capella payload for capella fork")
-		api.RespondError(w, http.StatusBadRequest, "not capella payload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeForkMismatch, "not capella payload")
 		return false
 	}
 
+	return api.checkSubmissionSlotTimestamp(w, log, headSlot, submission)
+}
+
+// checkSubmissionSlotTimestamp checks that the submission is for a future slot, with the timestamp of the slot
+func (api *RelayAPI) checkSubmissionSlotTimestamp(w http.ResponseWriter, log *logrus.Entry, headSlot uint64, submission *common.BlockSubmissionInfo) bool {
 	if submission.BidTrace.Slot <= headSlot {
 		log.Info("submitNewBlock failed: submission for past slot")
-		api.RespondError(w, http.StatusBadRequest, "submission for past slot")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeSlotMismatch, "submission for past slot")
 		return false
 	}
 
@@ -1678,7 +2636,7 @@ func (api *RelayAPI) checkSubmissionSlotDetails(w http.ResponseWriter, log *logr
 	expectedTimestamp := api.genesisInfo.Data.GenesisTime + (submission.BidTrace.Slot * common.SecondsPerSlot)
 	if submission.Timestamp != expectedTimestamp {
 		log.Warnf("incorrect timestamp. got %d, expected %d", submission.Timestamp, expectedTimestamp)
-		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("incorrect timestamp. got %d, expected %d", submission.Timestamp, expectedTimestamp))
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidTimestamp, fmt.Sprintf("incorrect timestamp. got %d, expected %d", submission.Timestamp, expectedTimestamp))
 		return false
 	}
 
@@ -1706,8 +2664,40 @@ func (api *RelayAPI) checkBuilderEntry(w http.ResponseWriter, log *logrus.Entry,
 		return builderEntry, false
 	}
 
+	// Quarantined builders are processed as low-prio and without optimistic collateral, or rejected
+	if api.isBuilderQuarantined(builderPubkey.String()) {
+		if builderQuarantineMode == builderQuarantineModeBlock {
+			log.Info("rejecting quarantined builder")
+			api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeBuilderQuarantined, "builder is quarantined")
+			return builderEntry, false
+		}
+		log.Info("processing submission of quarantined builder as low-prio")
+		builderEntry = &blockBuilderCacheEntry{
+			status: common.BuilderStatus{
+				IsHighPrio:    false,
+				IsOptimistic:  false,
+				IsBlacklisted: builderEntry.status.IsBlacklisted,
+			},
+			collateral: builderEntry.collateral,
+			labels:     builderEntry.labels,
+		}
+	}
+
+	// Optimistic processing can be disabled at runtime, i.e. while investigating a missed slot
+	if builderEntry.status.IsOptimistic && api.ffDisableOptimisticSubmissions.Load() {
+		builderEntry = &blockBuilderCacheEntry{
+			status: common.BuilderStatus{
+				IsHighPrio:    builderEntry.status.IsHighPrio,
+				IsOptimistic:  false,
+				IsBlacklisted: builderEntry.status.IsBlacklisted,
+			},
+			collateral: builderEntry.collateral,
+			labels:     builderEntry.labels,
+		}
+	}
+
 	// In case only high-prio requests are accepted, fail others
-	if api.ffDisableLowPrioBuilders && !builderEntry.status.IsHighPrio {
+	if api.ffDisableLowPrioBuilders.Load() && !builderEntry.status.IsHighPrio {
 		log.Info("rejecting low-prio builder (ff-disable-low-prio-builders)")
 		time.Sleep(200 * time.Millisecond)
 		w.WriteHeader(http.StatusOK)
@@ -1733,7 +2723,7 @@ func (api *RelayAPI) checkFloorBidValue(opts bidFloorOpts) (*big.Int, bool) {
 		opts.log.WithError(err).Error("failed to get delivered payload slot from redis")
 	} else if opts.submission.BidTrace.Slot <= slotLastPayloadDelivered {
 		opts.log.Info("rejecting submission because payload for this slot was already delivered")
-		api.RespondError(opts.w, http.StatusBadRequest, "payload for this slot was already delivered")
+		api.RespondErrorCode(opts.w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "payload for this slot was already delivered")
 		return nil, false
 	}
 
@@ -1756,7 +2746,7 @@ func (api *RelayAPI) checkFloorBidValue(opts bidFloorOpts) (*big.Int, bool) {
 		err := api.redis.DelBuilderBid(context.Background(), opts.tx, opts.submission.BidTrace.Slot, opts.submission.BidTrace.ParentHash.String(), opts.submission.BidTrace.ProposerPubkey.String(), opts.submission.BidTrace.BuilderPubkey.String())
 		if err != nil {
 			opts.log.WithError(err).Error("failed processing cancellable bid below floor")
-			api.RespondError(opts.w, http.StatusInternalServerError, "failed processing cancellable bid below floor")
+			api.RespondErrorCode(opts.w, http.StatusInternalServerError, ErrorCodeInternalError, "failed processing cancellable bid below floor")
 			return nil, false
 		}
 		api.Respond(opts.w, http.StatusAccepted, "accepted bid below floor, skipped validation")
@@ -1780,48 +2770,63 @@ type redisUpdateBidOpts struct {
 	payload              *common.VersionedSubmitBlockRequest
 }
 
-func (api *RelayAPI) updateRedisBid(opts redisUpdateBidOpts) (*datastore.SaveBidAndUpdateTopBidResponse, *builderApi.VersionedSubmitBlindedBlockResponse, bool) {
+func (api *RelayAPI) updateRedisBid(opts redisUpdateBidOpts) (*datastore.SaveBidAndUpdateTopBidResponse, *builderApi.VersionedSubmitBlindedBlockResponse, *common.BidTraceV2WithBlobFields, bool) {
 	// Prepare the response data
-	getHeaderResponse, err := common.BuildGetHeaderResponse(opts.payload, api.blsSk, api.publicKey, api.opts.EthNetDetails.DomainBuilder)
+	getHeaderResponse, getPayloadResponse, bidTrace, err := api.buildBidResponses(opts.payload)
 	if err != nil {
-		opts.log.WithError(err).Error("could not sign builder bid")
-		api.RespondError(opts.w, http.StatusBadRequest, err.Error())
-		return nil, nil, false
+		opts.log.WithError(err).Error("could not build bid responses")
+		api.RespondErrorCode(opts.w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+		return nil, nil, nil, false
 	}
 
-	getPayloadResponse, err := common.BuildGetPayloadResponse(opts.payload)
+	//
+	// Save to Redis
+	//
+	updateBidResult, err := api.redis.SaveBidAndUpdateTopBid(context.Background(), opts.tx, bidTrace, opts.payload, getPayloadResponse, getHeaderResponse, opts.receivedAt, opts.cancellationsEnabled, opts.floorBidValue)
 	if err != nil {
-		opts.log.WithError(err).Error("could not build getPayload response")
-		api.RespondError(opts.w, http.StatusBadRequest, err.Error())
-		return nil, nil, false
+		opts.log.WithError(err).Error("could not save bid and update top bids")
+		api.RespondErrorCode(opts.w, http.StatusInternalServerError, ErrorCodeInternalError, "failed saving and updating bid")
+		return nil, nil, nil, false
 	}
+	return &updateBidResult, getPayloadResponse, bidTrace, true
+}
 
-	submission, err := common.GetBlockSubmissionInfo(opts.payload)
+// buildBidResponses returns the signed getHeader response, the getPayload response and the bid trace of a block
+// submission, which are saved to Redis
+func (api *RelayAPI) buildBidResponses(payload *common.VersionedSubmitBlockRequest) (*builderSpec.VersionedSignedBuilderBid, *builderApi.VersionedSubmitBlindedBlockResponse, *common.BidTraceV2WithBlobFields, error) {
+	getHeaderResponse, err := common.BuildGetHeaderResponse(payload, api.blsSk, api.publicKey, api.opts.EthNetDetails.DomainBuilder)
 	if err != nil {
-		opts.log.WithError(err).Error("could not get block submission info")
-		api.RespondError(opts.w, http.StatusBadRequest, err.Error())
-		return nil, nil, false
+		return nil, nil, nil, err
 	}
 
-	bidTrace := common.BidTraceV2WithBlobFields{
-		BidTrace:      *submission.BidTrace,
-		BlockNumber:   submission.BlockNumber,
-		NumTx:         uint64(len(submission.Transactions)),
-		NumBlobs:      uint64(len(submission.Blobs)),
-		BlobGasUsed:   submission.BlobGasUsed,
-		ExcessBlobGas: submission.ExcessBlobGas,
+	getPayloadResponse, err := common.BuildGetPayloadResponse(payload)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	//
-	// Save to Redis
-	//
-	updateBidResult, err := api.redis.SaveBidAndUpdateTopBid(context.Background(), opts.tx, &bidTrace, opts.payload, getPayloadResponse, getHeaderResponse, opts.receivedAt, opts.cancellationsEnabled, opts.floorBidValue)
+	submission, err := common.GetBlockSubmissionInfo(payload)
 	if err != nil {
-		opts.log.WithError(err).Error("could not save bid and update top bids")
-		api.RespondError(opts.w, http.StatusInternalServerError, "failed saving and updating bid")
-		return nil, nil, false
+		return nil, nil, nil, err
+	}
+
+	bidTrace := &common.BidTraceV2WithBlobFields{
+		BidTrace:      *submission.BidTrace,
+		BlockNumber:   submission.BlockNumber,
+		NumTx:         uint64(len(submission.Transactions)),
+		NumBlobs:      uint64(len(submission.Blobs)),
+		BlobGasUsed:   submission.BlobGasUsed,
+		ExcessBlobGas: submission.ExcessBlobGas,
+		RelayPubkey:   api.publicKey.String(),
+		InstanceID:    common.InstanceID,
 	}
-	return &updateBidResult, getPayloadResponse, true
+	if submission.ExecutionRequests != nil {
+		root, err := submission.ExecutionRequests.HashTreeRoot()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		bidTrace.ExecutionRequestsRoot = (*phase0.Root)(&root)
+	}
+	return getHeaderResponse, getPayloadResponse, bidTrace, nil
 }
 
 func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Request) {
@@ -1837,6 +2842,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 
 	log := api.log.WithFields(logrus.Fields{
 		"method":                "submitNewBlock",
+		"requestID":             getRequestID(req.Context()),
 		"contentLength":         req.ContentLength,
 		"headSlot":              headSlot,
 		"cancellationEnabled":   isCancellationEnabled,
@@ -1852,14 +2858,26 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		}).Info("request finished")
 	}()
 
-	// If cancellations are disabled but builder requested it, return error
-	if isCancellationEnabled && !api.ffEnableCancellations {
-		log.Info("builder submitted with cancellations enabled, but feature flag is disabled")
-		api.RespondError(w, http.StatusBadRequest, "cancellations are disabled")
+	// Record the response, to archive the bid together with the outcome and to answer duplicate submissions
+	respW := &submissionResponseWriter{ResponseWriter: w, statusCode: http.StatusOK, timings: submissionTimings{receivedAt: receivedAt}}
+	w = respW
+
+	if !api.checkSubmissionAccepted(w, log, isCancellationEnabled) {
+		return
+	}
+
+	// Optional commitment to preconfirmed transactions of the block, validated against the payload once decoded
+	preconf, err := parsePreconfArgs(args)
+	if err != nil {
+		log.WithError(err).Info("invalid preconfirmation arguments")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+		return
+	} else if preconf != nil && !api.ffEnablePreconfCommitments {
+		log.Info("builder submitted a preconfirmation commitment, but feature flag is disabled")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePreconfDisabled, "preconfirmation commitments are disabled")
 		return
 	}
 
-	var err error
 	var r io.Reader = req.Body
 	isGzip := req.Header.Get("Content-Encoding") == "gzip"
 	log = log.WithField("reqIsGzip", isGzip)
@@ -1867,16 +2885,14 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		r, err = gzip.NewReader(req.Body)
 		if err != nil {
 			log.WithError(err).Warn("could not create gzip reader")
-			api.RespondError(w, http.StatusBadRequest, err.Error())
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 			return
 		}
 	}
 
-	limitReader := io.LimitReader(r, 10*1024*1024) // 10 MB
-	requestPayloadBytes, err := io.ReadAll(limitReader)
+	requestPayloadBytes, err := readSubmissionBody(r, maxSubmissionBytes)
 	if err != nil {
-		log.WithError(err).Warn("could not read payload")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.respondBodyReadError(w, log, req, err)
 		return
 	}
 
@@ -1884,46 +2900,110 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	pf.PayloadLoad = uint64(nextTime.Sub(prevTime).Microseconds())
 	prevTime = nextTime
 
-	payload := new(common.VersionedSubmitBlockRequest)
-
-	// Check for SSZ encoding
-	contentType := req.Header.Get("Content-Type")
-	if contentType == "application/octet-stream" {
-		log = log.WithField("reqContentType", "ssz")
-		if err = payload.UnmarshalSSZ(requestPayloadBytes); err != nil {
-			log.WithError(err).Warn("could not decode payload - SSZ")
-
-			// SSZ decoding failed. try JSON as fallback (some builders used octet-stream for json before)
-			if err2 := json.Unmarshal(requestPayloadBytes, payload); err2 != nil {
-				log.WithError(fmt.Errorf("%w / %w", err, err2)).Warn("could not decode payload - SSZ or JSON")
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-				return
-			}
-			log = log.WithField("reqContentType", "json")
-		} else {
-			log.Debug("received ssz-encoded payload")
-		}
-	} else {
-		log = log.WithField("reqContentType", "json")
-		if err := json.Unmarshal(requestPayloadBytes, payload); err != nil {
-			log.WithError(err).Warn("could not decode payload - JSON")
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+	payload, encoding, err := decoder.DecodeSubmitBlockRequest(requestPayloadBytes, req.Header.Get("Content-Type"))
+	log = log.WithField("reqContentType", encoding)
+	if err != nil {
+		log.WithError(err).Warn("could not decode payload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, err.Error())
+		return
 	}
 
 	nextTime = time.Now().UTC()
 	pf.Decode = uint64(nextTime.Sub(prevTime).Microseconds())
-	prevTime = nextTime
+	respW.timings.decode = nextTime.Sub(receivedAt)
 
-	isLargeRequest := len(requestPayloadBytes) > fastTrackPayloadSizeLimit
+	api.processBlockSubmission(respW, log, &blockSubmission{
+		ctx:                  req.Context(),
+		requestID:            getRequestID(req.Context()),
+		payload:              payload,
+		payloadBytes:         len(requestPayloadBytes),
+		cancellationsEnabled: isCancellationEnabled,
+		preconf:              preconf,
+		trustedBuilder:       getTrustedBuilder(req),
+		headSlot:             headSlot,
+		receivedAt:           receivedAt,
+		decodedAt:            nextTime,
+		profile:              pf,
+	})
+}
+
+// checkSubmissionAccepted rejects block submissions while the relay is shutting down or the beacon nodes are not
+// synced, and submissions with cancellations while they are disabled
+func (api *RelayAPI) checkSubmissionAccepted(w http.ResponseWriter, log *logrus.Entry, isCancellationEnabled bool) bool {
+	// Don't accept new submissions while shutting down
+	if api.srvShutdown.Load() {
+		log.Info("rejecting block submission during shutdown")
+		api.RespondErrorCode(w, http.StatusServiceUnavailable, ErrorCodeShuttingDown, "relay is shutting down")
+		return false
+	}
+
+	// Don't accept submissions while the beacon nodes are not synced, as the head slot and proposer duties are stale
+	if api.beaconUnsynced.Load() {
+		log.Info("rejecting block submission while the beacon nodes are not synced")
+		api.RespondErrorCode(w, http.StatusServiceUnavailable, ErrorCodeBeaconNotSynced, "beacon nodes are not synced")
+		return false
+	}
+
+	// If cancellations are disabled but builder requested it, return error
+	if isCancellationEnabled && !api.ffEnableCancellations.Load() {
+		log.Info("builder submitted with cancellations enabled, but feature flag is disabled")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeCancellationsDisabled, "cancellations are disabled")
+		return false
+	}
+	return true
+}
+
+// blockSubmission is a decoded block submission, with the details of the request which are needed to process it
+type blockSubmission struct {
+	ctx                  context.Context // of the request, to stop waiting for the original of a duplicate once it's cancelled
+	requestID            string
+	payload              *common.VersionedSubmitBlockRequest
+	payloadBytes         int
+	cancellationsEnabled bool
+	preconf              *preconfArgs
+	trustedBuilder       *trustedBuilder // set for submissions on the trusted builder listener
+	headSlot             uint64
+	receivedAt           time.Time
+	decodedAt            time.Time
+	profile              common.Profile // with the durations of loading and decoding the payload
+}
+
+// processBlockSubmission checks, simulates and saves a decoded block submission. It's shared by the REST and gRPC
+// APIs, which respond with the outcome recorded by respW.
+func (api *RelayAPI) processBlockSubmission(respW *submissionResponseWriter, log *logrus.Entry, sub *blockSubmission) {
+	var w http.ResponseWriter = respW
+	var nextTime time.Time
+	payload, pf := sub.payload, sub.profile
+	headSlot, receivedAt, decodedAt := sub.headSlot, sub.receivedAt, sub.decodedAt
+	isCancellationEnabled, preconf, trustedBuilder := sub.cancellationsEnabled, sub.preconf, sub.trustedBuilder
+	prevTime := decodedAt
+
+	isLargeRequest := sub.payloadBytes > fastTrackPayloadSizeLimit
 	// getting block submission info also validates bid trace and execution submission are not empty
 	submission, err := common.GetBlockSubmissionInfo(payload)
 	if err != nil {
 		log.WithError(err).Warn("missing fields in submit block request")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 		return
 	}
+	if api.isBidArchiveEnabled() {
+		defer api.archiveBid(respW, submission, receivedAt)
+	}
+	if api.isEventPublishingEnabled() {
+		defer api.publishBidEvent(respW, submission)
+	}
+	isSigVerified := false
+	if api.builderQuarantine != nil {
+		defer func() {
+			api.recordBuilderOutcome(log, respW, submission, &isSigVerified)
+		}()
+	}
+	var eligibleAt time.Time // will be set once the bid is ready
+	if api.isBidFirehoseEnabled() {
+		defer func() {
+			api.streamBidTrace(respW, submission, receivedAt, eligibleAt, pf.Simulation)
+		}()
+	}
 	log = log.WithFields(logrus.Fields{
 		"timestampAfterDecoding": time.Now().UTC().UnixMilli(),
 		"slot":                   submission.BidTrace.Slot,
@@ -1933,15 +3013,22 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		"parentHash":             submission.BidTrace.ParentHash.String(),
 		"value":                  submission.BidTrace.Value.Dec(),
 		"numTx":                  len(submission.Transactions),
-		"payloadBytes":           len(requestPayloadBytes),
+		"payloadBytes":           sub.payloadBytes,
 		"isLargeRequest":         isLargeRequest,
 	})
 	// deneb specific logging
-	if payload.Deneb != nil {
+	if payload.Deneb != nil || payload.Electra != nil {
+		log = log.WithFields(logrus.Fields{
+			"numBlobs":      len(submission.Blobs),
+			"blobGasUsed":   submission.BlobGasUsed,
+			"excessBlobGas": submission.ExcessBlobGas,
+		})
+	}
+	if submission.ExecutionRequests != nil {
 		log = log.WithFields(logrus.Fields{
-			"numBlobs":      len(payload.Deneb.BlobsBundle.Blobs),
-			"blobGasUsed":   payload.Deneb.ExecutionPayload.BlobGasUsed,
-			"excessBlobGas": payload.Deneb.ExecutionPayload.ExcessBlobGas,
+			"numDepositRequests":       len(submission.ExecutionRequests.Deposits),
+			"numWithdrawalRequests":    len(submission.ExecutionRequests.Withdrawals),
+			"numConsolidationRequests": len(submission.ExecutionRequests.Consolidations),
 		})
 	}
 
@@ -1957,6 +3044,24 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	}
 
 	log = log.WithField("builderIsHighPrio", builderEntry.status.IsHighPrio)
+	if builderEntry.labels.Operator != "" {
+		log = log.WithFields(logrus.Fields{
+			"builderOperator": builderEntry.labels.Operator,
+			"builderRegion":   builderEntry.labels.Region,
+			"builderTier":     builderEntry.labels.Tier,
+		})
+	}
+	api.builderOperatorSubmissions.inc(builderEntry.labels.Operator)
+
+	// Submissions on the trusted builder listener may only be for the builder pubkeys of the authenticated identity
+	if trustedBuilder != nil {
+		log = log.WithField("trustedBuilder", trustedBuilder.name)
+		if !trustedBuilder.allowsBuilder(builderPubkey) {
+			log.Warn("builder pubkey not allowed for trusted builder")
+			api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeBuilderNotAllowed, ErrTrustedBuilderNotAllowed.Error())
+			return
+		}
+	}
 
 	gasLimit, ok := api.checkSubmissionFeeRecipient(w, log, submission.BidTrace)
 	if !ok {
@@ -1974,7 +3079,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	err = SanityCheckBuilderBlockSubmission(payload)
 	if err != nil {
 		log.WithError(err).Info("block submission sanity checks failed")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeSanityCheckFailed, err.Error())
 		return
 	}
 
@@ -1983,18 +3088,81 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	// Verify the signature
-	log = log.WithField("timestampBeforeSignatureCheck", time.Now().UTC().UnixMilli())
-	signature := submission.Signature
-	ok, err = ssz.VerifySignature(submission.BidTrace, api.opts.EthNetDetails.DomainBuilder, builderPubkey[:], signature[:])
-	log = log.WithField("timestampAfterSignatureCheck", time.Now().UTC().UnixMilli())
-	if err != nil {
-		log.WithError(err).Warn("failed verifying builder signature")
-		api.RespondError(w, http.StatusBadRequest, "failed verifying builder signature")
+	ok = api.checkSubmissionGas(w, log, submission, gasLimit, attrs)
+	if !ok {
 		return
-	} else if !ok {
-		log.Warn("invalid builder signature")
-		api.RespondError(w, http.StatusBadRequest, "invalid signature")
+	}
+
+	// Verify the signature, unless the builder is authenticated by a client certificate on the trusted builder listener
+	if api.ffSkipSigVerifyForMTLSBuilders && trustedBuilder != nil && trustedBuilder.viaCertificate {
+		log = log.WithField("skippedSignatureCheck", true)
+		isSigVerified = true
+	} else {
+		timeBeforeSigVerify := time.Now().UTC()
+		log = log.WithField("timestampBeforeSignatureCheck", timeBeforeSigVerify.UnixMilli())
+		ok, err = api.builderSigVerifier.Verify(submission.BidTrace, submission.Signature)
+		respW.timings.sigVerify = time.Since(timeBeforeSigVerify)
+		log = log.WithField("timestampAfterSignatureCheck", time.Now().UTC().UnixMilli())
+		if err != nil {
+			log.WithError(err).Warn("failed verifying builder signature")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "failed verifying builder signature")
+			return
+		} else if !ok {
+			log.Warn("invalid builder signature")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "invalid signature")
+			return
+		}
+		isSigVerified = true
+	}
+
+	// Resolve or cancel the pending header-only bid of the block, once the outcome of the submission is known
+	if api.ffEnableHeaderSubmissions {
+		defer api.processPendingPayload(log, respW, submission)
+	}
+
+	// Duplicates of a block which was already submitted by the builder are answered with the outcome of the first
+	// submission (waiting for it if it's still being processed), without simulating and storing the block again
+	dedupKey := submissionDedupKey{submission.BidTrace.Slot, builderPubkey, submission.BidTrace.BlockHash}
+	dedupEntry, isDuplicate := api.submissionDedup.start(dedupKey)
+	if isDuplicate {
+		api.respondDuplicateSubmission(sub.ctx, w, log, dedupEntry)
+		return
+	}
+	respW.onWriteHeader = func() { api.submissionDedup.finish(dedupKey, dedupEntry, respW) }
+	defer api.submissionDedup.finish(dedupKey, dedupEntry, respW)
+
+	// In filtered mode, reject blocks with transactions from or to a blocklisted address
+	if api.blocklist != nil {
+		if api.blocklist.IsStale() {
+			log.Warn("rejecting block submission, the blocklist is stale")
+			api.RespondErrorCode(w, http.StatusServiceUnavailable, ErrorCodeBlocklistUnavailable, "blocklist is outdated")
+			return
+		}
+		address, err := api.blocklist.CheckTransactions(submission.Transactions)
+		if err != nil {
+			log.WithError(err).Warn("failed to decode transactions for blocklist check")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, "failed to decode transactions")
+			return
+		} else if address != nil {
+			log.WithField("blocklistedAddress", address.Hex()).Info("rejecting block with blocklisted address")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeBlocklistedTransaction, fmt.Sprintf("block contains transaction with blocklisted address %s", address.Hex()))
+			return
+		}
+	}
+
+	// Reject blocks which don't include the transactions the proposer committed to
+	if !api.checkSubmissionConstraints(w, log, submission) {
+		return
+	}
+
+	// Reject blocks which pay the proposer less than the bid value
+	if api.ffVerifyProposerPayment && !api.checkSubmissionPayment(w, log, submission) {
+		return
+	}
+
+	// Reject blocks whose preconfirmation commitment doesn't match the payload
+	preconfCommitment, ok := api.checkSubmissionPreconf(w, log, submission, preconf, receivedAt)
+	if !ok {
 		return
 	}
 
@@ -2005,7 +3173,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 
 	// channel to send simulation result to the deferred function
 	simResultC := make(chan *blockSimResult, 1)
-	var eligibleAt time.Time // will be set once the bid is ready
+	var isNewTopBid bool // will be set if the bid became the top bid (and could be delivered)
 
 	bfOpts := bidFloorOpts{
 		w:                    w,
@@ -2015,7 +3183,9 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		simResultC:           simResultC,
 		submission:           submission,
 	}
+	timeBeforeFloorCheck := time.Now().UTC()
 	floorBidValue, ok := api.checkFloorBidValue(bfOpts)
+	respW.timings.floorCheck = time.Since(timeBeforeFloorCheck)
 	if !ok {
 		return
 	}
@@ -2024,25 +3194,16 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 
 	// Deferred saving of the builder submission to database (whenever this function ends)
 	defer func() {
-		savePayloadToDatabase := !api.ffDisablePayloadDBStorage
-		var simResult *blockSimResult
-		select {
-		case simResult = <-simResultC:
-		case <-time.After(10 * time.Second):
-			log.Warn("timed out waiting for simulation result")
-			simResult = &blockSimResult{false, false, nil, nil}
-		}
-
-		submissionEntry, err := api.db.SaveBuilderBlockSubmission(payload, simResult.requestErr, simResult.validationErr, receivedAt, eligibleAt, simResult.wasSimulated, savePayloadToDatabase, pf, simResult.optimisticSubmission)
-		if err != nil {
-			log.WithError(err).WithField("payload", payload).Error("saving builder block submission to database failed")
-			return
-		}
-
-		err = api.db.UpsertBlockBuilderEntryAfterSubmission(submissionEntry, simResult.validationErr != nil)
-		if err != nil {
-			log.WithError(err).Error("failed to upsert block-builder-entry")
-		}
+		api.saveSubmission(&submissionDBEntry{
+			log:        log,
+			payload:    payload,
+			receivedAt: receivedAt,
+			decodedAt:  decodedAt,
+			eligibleAt: eligibleAt,
+			profile:    pf,
+			isTopBid:   isNewTopBid,
+			isTrusted:  trustedBuilder != nil,
+		}, simResultC)
 	}()
 
 	// ---------------------------------
@@ -2073,16 +3234,20 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	// Simulate the block submission and save to db
 	fastTrackValidation := builderEntry.status.IsHighPrio && bidIsTopBid && !isLargeRequest
 	timeBeforeValidation := time.Now().UTC()
+	simBudget := api.getSimBudget(submission.BidTrace.Slot, bidIsTopBid, timeBeforeValidation)
 
 	log = log.WithFields(logrus.Fields{
 		"timestampBeforeValidation": timeBeforeValidation.UTC().UnixMilli(),
 		"fastTrackValidation":       fastTrackValidation,
+		"simBudgetMs":               simBudget.timeout.Milliseconds(),
+		"simBudgetReason":           simBudget.reason,
 	})
 
 	// Construct simulation request
 	opts := blockSimOptions{
 		isHighPrio: builderEntry.status.IsHighPrio,
 		fastTrack:  fastTrackValidation,
+		requestID:  sub.requestID,
 		log:        log,
 		builder:    builderEntry,
 		req: &common.BuilderBlockValidationRequest{
@@ -2096,25 +3261,35 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		builderEntry.collateral.Cmp(submission.BidTrace.Value.ToBig()) >= 0 &&
 		submission.BidTrace.Slot == api.optimisticSlot.Load() {
 		go api.processOptimisticBlock(opts, simResultC)
+	} else if api.isSimCached(log, submission, attrs, gasLimit) {
+		// Block was already simulated successfully with the same inputs
+		simResultC <- &blockSimResult{false, false, nil, nil}
 	} else {
-		// Simulate block (synchronously).
-		requestErr, validationErr := api.simulateBlock(context.Background(), opts) // success/error logging happens inside
+		// Simulate block (synchronously), within the timeout budget of the submission
+		respW.timings.simBudget = simBudget
+		simCtx, cancelSim := context.WithTimeout(context.Background(), simBudget.timeout)
+		requestErr, validationErr := api.simulateBlock(simCtx, opts) // success/error logging happens inside
+		cancelSim()
 		simResultC <- &blockSimResult{requestErr == nil, false, requestErr, validationErr}
+		if api.simCache != nil && requestErr == nil && validationErr == nil {
+			api.simCache.add(submission.BidTrace.BlockHash, newSimCacheInputs(submission, attrs, gasLimit))
+		}
+		respW.timings.sim = time.Since(timeBeforeValidation)
 		validationDurationMs := time.Since(timeBeforeValidation).Milliseconds()
 		log = log.WithFields(logrus.Fields{
 			"timestampAfterValidation": time.Now().UTC().UnixMilli(),
 			"validationDurationMs":     validationDurationMs,
 		})
 		if requestErr != nil { // Request error
-			if os.IsTimeout(requestErr) {
-				api.RespondError(w, http.StatusGatewayTimeout, "validation request timeout")
+			if os.IsTimeout(requestErr) || errors.Is(requestErr, context.DeadlineExceeded) {
+				api.RespondErrorCode(w, http.StatusGatewayTimeout, ErrorCodeSimTimeout, "validation request timeout")
 			} else {
-				api.RespondError(w, http.StatusBadRequest, requestErr.Error())
+				api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeSimRequestFailed, requestErr.Error())
 			}
 			return
 		} else {
 			if validationErr != nil {
-				api.RespondError(w, http.StatusBadRequest, validationErr.Error())
+				api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeSimFailed, validationErr.Error())
 				return
 			}
 		}
@@ -2140,7 +3315,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 			log.WithError(err).Error("failed getting latest payload receivedAt from redis")
 		} else if receivedAt.UnixMilli() < latestPayloadReceivedAt {
 			log.Infof("already have a newer payload: now=%d / prev=%d", receivedAt.UnixMilli(), latestPayloadReceivedAt)
-			api.RespondError(w, http.StatusBadRequest, "already using a newer payload")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeNewerPayloadExists, "already using a newer payload")
 			return
 		}
 	}
@@ -2154,7 +3329,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		floorBidValue:        floorBidValue,
 		payload:              payload,
 	}
-	updateBidResult, getPayloadResponse, ok := api.updateRedisBid(redisOpts)
+	updateBidResult, getPayloadResponse, bidTrace, ok := api.updateRedisBid(redisOpts)
 	if !ok {
 		return
 	}
@@ -2168,27 +3343,31 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		"prevTopBidValue":            updateBidResult.PrevTopBidValue,
 		"profileRedisSavePayloadUs":  updateBidResult.TimeSavePayload.Microseconds(),
 		"profileRedisUpdateTopBidUs": updateBidResult.TimeUpdateTopBid.Microseconds(),
-		"profileRedisUpdateFloorUs":  updateBidResult.TimeUpdateFloor.Microseconds(),
 	})
 
 	if updateBidResult.WasBidSaved {
 		// Bid is eligible to win the auction
 		eligibleAt = time.Now().UTC()
+		isNewTopBid = updateBidResult.IsNewTopBid
+		api.recordBlockGasLimit(submission)
 		log = log.WithField("timestampEligibleAt", eligibleAt.UnixMilli())
 
-		// Save to memcache in the background
-		if api.memcached != nil {
-			go func() {
-				err = api.memcached.SaveExecutionPayload(submission.BidTrace.Slot, submission.BidTrace.ProposerPubkey.String(), submission.BidTrace.BlockHash.String(), getPayloadResponse)
-				if err != nil {
-					log.WithError(err).Error("failed saving execution payload in memcached")
-				}
-			}()
+		// Save to the secondary cache backends (i.e. memcached) in the background
+		if len(api.datastore.CacheBackends()) > 1 {
+			go api.datastore.SaveToSecondaryBackends(log, getPayloadResponse, bidTrace)
+		}
+
+		// Record the preconfirmation commitment of the eligible bid
+		if preconfCommitment != nil {
+			if err := api.redis.SavePreconfCommitment(preconfCommitment); err != nil {
+				log.WithError(err).Error("failed to save preconfirmation commitment")
+			}
 		}
 	}
 
 	nextTime = time.Now().UTC()
 	pf.RedisUpdate = uint64(nextTime.Sub(prevTime).Microseconds())
+	respW.timings.redis = nextTime.Sub(prevTime)
 	pf.Total = uint64(nextTime.Sub(receivedAt).Microseconds())
 
 	// All done, log with profiling information
@@ -2281,11 +3460,94 @@ func (api *RelayAPI) handleInternalBuilderCollateral(w http.ResponseWriter, req
 	}
 }
 
+func (api *RelayAPI) handleInternalBuilderRelayFee(w http.ResponseWriter, req *http.Request) {
+	builderPubkey := mux.Vars(req)["pubkey"]
+	relayFeeBps, err := strconv.ParseUint(req.URL.Query().Get("bps"), 10, 64)
+	if err != nil || relayFeeBps > 10_000 {
+		api.RespondError(w, http.StatusBadRequest, "invalid bps argument, must be between 0 and 10000")
+		return
+	}
+
+	log := api.log.WithFields(logrus.Fields{
+		"pubkey":      builderPubkey,
+		"relayFeeBps": relayFeeBps,
+	})
+	log.Info("updating builder relay fee")
+	if err := api.db.SetBlockBuilderRelayFee(builderPubkey, relayFeeBps); err != nil {
+		fullErr := fmt.Errorf("unable to set relay fee in db for pubkey: %v: %w", builderPubkey, err)
+		log.Error(fullErr.Error())
+		api.RespondError(w, http.StatusInternalServerError, fullErr.Error())
+		return
+	}
+	api.RespondOK(w, NilResponse)
+}
+
+func (api *RelayAPI) handleInternalBuilderDemotions(w http.ResponseWriter, req *http.Request) {
+	builderPubkey := mux.Vars(req)["pubkey"]
+
+	limit := uint64(100)
+	if args := req.URL.Query(); args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if _limit > limit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", limit))
+			return
+		}
+		limit = _limit
+	}
+
+	entries, err := api.db.GetBuilderDemotions(builderPubkey, limit)
+	if err != nil {
+		api.log.WithError(err).Error("error getting builder demotions")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.BuilderDemotionJSON, len(entries))
+	for i, entry := range entries {
+		response[i] = database.BuilderDemotionEntryToBuilderDemotionJSON(entry)
+	}
+	api.RespondOK(w, response)
+}
+
 // -----------
 //  DATA APIS
 // -----------
 
 func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, req *http.Request) {
+	deliveredPayloads, ok := api.queryDataDeliveredPayloads(w, req)
+	if !ok {
+		return
+	}
+
+	response := make([]common.BidTraceV2JSON, len(deliveredPayloads))
+	for i, payload := range deliveredPayloads {
+		response[i] = database.DeliveredPayloadEntryToBidTraceV2JSON(payload)
+	}
+
+	api.RespondOK(w, response)
+}
+
+func (api *RelayAPI) handleDataV2ProposerPayloadDelivered(w http.ResponseWriter, req *http.Request) {
+	deliveredPayloads, ok := api.queryDataDeliveredPayloads(w, req)
+	if !ok {
+		return
+	}
+
+	response := make([]common.DeliveredPayloadV2JSON, len(deliveredPayloads))
+	for i, payload := range deliveredPayloads {
+		response[i] = database.DeliveredPayloadEntryToDeliveredPayloadV2JSON(payload)
+	}
+
+	api.RespondOK(w, response)
+}
+
+// queryDataDeliveredPayloads returns the delivered payloads for the query arguments, which are the same in all versions
+// of the data API. It returns false if the response was already sent, i.e. an error or the aggregate.
+func (api *RelayAPI) queryDataDeliveredPayloads(w http.ResponseWriter, req *http.Request) ([]*database.DeliveredPayloadEntry, bool) {
 	var err error
 	args := req.URL.Query()
 
@@ -2295,18 +3557,18 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 
 	if args.Get("slot") != "" && args.Get("cursor") != "" {
 		api.RespondError(w, http.StatusBadRequest, "cannot specify both slot and cursor")
-		return
+		return nil, false
 	} else if args.Get("slot") != "" {
 		filters.Slot, err = strconv.ParseInt(args.Get("slot"), 10, 64)
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
-			return
+			return nil, false
 		}
 	} else if args.Get("cursor") != "" {
 		filters.Cursor, err = strconv.ParseInt(args.Get("cursor"), 10, 64)
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid cursor argument")
-			return
+			return nil, false
 		}
 	}
 
@@ -2314,7 +3576,7 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 		_, err := utils.HexToHash(args.Get("block_hash"))
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid block_hash argument")
-			return
+			return nil, false
 		}
 		filters.BlockHash = args.Get("block_hash")
 	}
@@ -2323,14 +3585,14 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 		filters.BlockNumber, err = strconv.ParseInt(args.Get("block_number"), 10, 64)
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid block_number argument")
-			return
+			return nil, false
 		}
 	}
 
 	if args.Get("proposer_pubkey") != "" {
 		if err = checkBLSPublicKeyHex(args.Get("proposer_pubkey")); err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid proposer_pubkey argument")
-			return
+			return nil, false
 		}
 		filters.ProposerPubkey = args.Get("proposer_pubkey")
 	}
@@ -2338,20 +3600,29 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 	if args.Get("builder_pubkey") != "" {
 		if err = checkBLSPublicKeyHex(args.Get("builder_pubkey")); err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid builder_pubkey argument")
-			return
+			return nil, false
 		}
 		filters.BuilderPubkey = args.Get("builder_pubkey")
 	}
 
+	// Sums of the delivered payloads per builder operator, the limit is the number of payloads to sum up
+	aggregate := args.Get("aggregate")
+	if aggregate == dataAggregateBuilderOperator {
+		filters.Limit = dataAggregateMaxPayloads
+	} else if aggregate != "" {
+		api.RespondError(w, http.StatusBadRequest, "invalid aggregate argument")
+		return nil, false
+	}
+
 	if args.Get("limit") != "" {
 		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
-			return
+			return nil, false
 		}
 		if _limit > filters.Limit {
 			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", filters.Limit))
-			return
+			return nil, false
 		}
 		filters.Limit = _limit
 	}
@@ -2362,22 +3633,61 @@ func (api *RelayAPI) handleDataProposerPayloadDelivered(w http.ResponseWriter, r
 		filters.OrderByValue = -1
 	}
 
+	if aggregate != "" {
+		if filters.OrderByValue != 0 {
+			api.RespondError(w, http.StatusBadRequest, "order_by argument not supported with aggregate")
+			return nil, false
+		}
+		entries, err := api.db.GetDeliveredPayloadsByBuilderOperator(filters)
+		if err != nil {
+			api.log.WithError(err).Error("error getting delivered payloads by builder operator")
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return nil, false
+		}
+		api.RespondOK(w, entries)
+		return nil, false
+	}
+
 	deliveredPayloads, err := api.db.GetRecentDeliveredPayloads(filters)
 	if err != nil {
 		api.log.WithError(err).Error("error getting recently delivered payloads")
 		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return deliveredPayloads, true
+}
+
+func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *http.Request) {
+	blockSubmissions, ok := api.queryDataBuilderBidsReceived(w, req)
+	if !ok {
 		return
 	}
 
-	response := make([]common.BidTraceV2JSON, len(deliveredPayloads))
-	for i, payload := range deliveredPayloads {
-		response[i] = database.DeliveredPayloadEntryToBidTraceV2JSON(payload)
+	response := make([]common.BidTraceV2WithTimestampJSON, len(blockSubmissions))
+	for i, payload := range blockSubmissions {
+		response[i] = database.BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload)
 	}
 
 	api.RespondOK(w, response)
 }
 
-func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *http.Request) {
+func (api *RelayAPI) handleDataV2BuilderBidsReceived(w http.ResponseWriter, req *http.Request) {
+	blockSubmissions, ok := api.queryDataBuilderBidsReceived(w, req)
+	if !ok {
+		return
+	}
+
+	response := make([]common.BuilderBidV2JSON, len(blockSubmissions))
+	for i, payload := range blockSubmissions {
+		response[i] = database.BuilderSubmissionEntryToBuilderBidV2JSON(payload)
+	}
+
+	api.RespondOK(w, response)
+}
+
+// queryDataBuilderBidsReceived returns the block submissions for the query arguments, which are the same in all
+// versions of the data API. It returns false if an error response was sent.
+func (api *RelayAPI) queryDataBuilderBidsReceived(w http.ResponseWriter, req *http.Request) ([]*database.BuilderBlockSubmissionEntry, bool) {
 	var err error
 	args := req.URL.Query()
 
@@ -2391,14 +3701,14 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 
 	if args.Get("cursor") != "" {
 		api.RespondError(w, http.StatusBadRequest, "cursor argument not supported")
-		return
+		return nil, false
 	}
 
 	if args.Get("slot") != "" {
 		filters.Slot, err = strconv.ParseInt(args.Get("slot"), 10, 64)
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
-			return
+			return nil, false
 		}
 	}
 
@@ -2406,7 +3716,7 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 		_, err := utils.HexToHash(args.Get("block_hash"))
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid block_hash argument")
-			return
+			return nil, false
 		}
 		filters.BlockHash = args.Get("block_hash")
 	}
@@ -2415,14 +3725,14 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 		filters.BlockNumber, err = strconv.ParseInt(args.Get("block_number"), 10, 64)
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid block_number argument")
-			return
+			return nil, false
 		}
 	}
 
 	if args.Get("builder_pubkey") != "" {
 		if err = checkBLSPublicKeyHex(args.Get("builder_pubkey")); err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid builder_pubkey argument")
-			return
+			return nil, false
 		}
 		filters.BuilderPubkey = args.Get("builder_pubkey")
 	}
@@ -2430,18 +3740,18 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 	// at least one query arguments is required
 	if filters.Slot == 0 && filters.BlockHash == "" && filters.BlockNumber == 0 && filters.BuilderPubkey == "" {
 		api.RespondError(w, http.StatusBadRequest, "need to query for specific slot or block_hash or block_number or builder_pubkey")
-		return
+		return nil, false
 	}
 
 	if args.Get("limit") != "" {
 		_limit, err := strconv.ParseInt(args.Get("limit"), 10, 64)
 		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
-			return
+			return nil, false
 		}
 		if _limit > filters.Limit {
 			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", filters.Limit))
-			return
+			return nil, false
 		}
 		filters.Limit = _limit
 	}
@@ -2450,15 +3760,9 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 	if err != nil {
 		api.log.WithError(err).Error("error getting recent builder submissions")
 		api.RespondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	response := make([]common.BidTraceV2WithTimestampJSON, len(blockSubmissions))
-	for i, payload := range blockSubmissions {
-		response[i] = database.BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload)
+		return nil, false
 	}
-
-	api.RespondOK(w, response)
+	return blockSubmissions, true
 }
 
 func (api *RelayAPI) handleDataValidatorRegistration(w http.ResponseWriter, req *http.Request) {
@@ -2495,14 +3799,256 @@ func (api *RelayAPI) handleDataValidatorRegistration(w http.ResponseWriter, req
 	api.RespondOK(w, signedRegistration)
 }
 
-func (api *RelayAPI) handleLivez(w http.ResponseWriter, req *http.Request) {
-	api.RespondMsg(w, http.StatusOK, "live")
+func (api *RelayAPI) handleDataBids(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+
+	if args.Get("slot") == "" {
+		api.RespondError(w, http.StatusBadRequest, "missing slot argument")
+		return
+	}
+	slot, err := strconv.ParseUint(args.Get("slot"), 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+		return
+	}
+
+	limit := uint64(500)
+	if args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if _limit > limit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", limit))
+			return
+		}
+		limit = _limit
+	}
+
+	entries, err := api.db.GetBidArchiveEntries(slot, limit)
+	if err != nil {
+		api.log.WithError(err).Error("error getting archived bids")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.ArchivedBidJSON, len(entries))
+	for i, entry := range entries {
+		response[i] = database.BidArchiveEntryToArchivedBidJSON(entry)
+	}
+
+	api.RespondOK(w, response)
 }
 
-func (api *RelayAPI) handleReadyz(w http.ResponseWriter, req *http.Request) {
-	if api.IsReady() {
-		api.RespondMsg(w, http.StatusOK, "ready")
-	} else {
-		api.RespondMsg(w, http.StatusServiceUnavailable, "not ready")
+// handleDataProposerRequests returns the getHeader and getPayload requests of proposers for a slot, to reconstruct
+// missed slots
+func (api *RelayAPI) handleDataProposerRequests(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+
+	if args.Get("slot") == "" {
+		api.RespondError(w, http.StatusBadRequest, "missing slot argument")
+		return
+	}
+	slot, err := strconv.ParseUint(args.Get("slot"), 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+		return
+	}
+
+	entries, err := api.db.GetProposerRequests(slot)
+	if err != nil {
+		api.log.WithError(err).Error("error getting proposer requests")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []*database.ProposerRequestEntry{}
+	}
+
+	api.RespondOK(w, entries)
+}
+
+func (api *RelayAPI) handleDataPaymentVerification(w http.ResponseWriter, req *http.Request) {
+	var err error
+	args := req.URL.Query()
+
+	filters := database.GetPaymentVerificationsFilters{
+		Limit:             200,
+		DiscrepanciesOnly: args.Get("discrepancies") == "1",
+	}
+
+	if args.Get("slot") != "" {
+		filters.Slot, err = strconv.ParseUint(args.Get("slot"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+			return
+		}
+	}
+
+	if status := args.Get("status"); status != "" {
+		if status != database.PaymentStatusOK && status != database.PaymentStatusUnderpaid && status != database.PaymentStatusBlockNotIncluded {
+			api.RespondError(w, http.StatusBadRequest, "invalid status argument")
+			return
+		}
+		filters.Status = status
+	}
+
+	if args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if _limit > filters.Limit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", filters.Limit))
+			return
+		}
+		filters.Limit = _limit
 	}
+
+	entries, err := api.db.GetPaymentVerifications(filters)
+	if err != nil {
+		api.log.WithError(err).Error("error getting payment verifications")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.PaymentVerificationJSON, len(entries))
+	for i, entry := range entries {
+		response[i] = database.PaymentVerificationEntryToPaymentVerificationJSON(entry)
+	}
+
+	api.RespondOK(w, response)
+}
+
+func (api *RelayAPI) handleDataSlotSummary(w http.ResponseWriter, req *http.Request) {
+	var err error
+	args := req.URL.Query()
+
+	filters := database.GetSlotSummariesFilters{
+		Limit: 200,
+	}
+
+	if args.Get("slot") != "" && args.Get("cursor") != "" {
+		api.RespondError(w, http.StatusBadRequest, "cannot specify both slot and cursor")
+		return
+	} else if args.Get("slot") != "" {
+		filters.Slot, err = strconv.ParseUint(args.Get("slot"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+			return
+		}
+	} else if args.Get("cursor") != "" {
+		filters.Cursor, err = strconv.ParseUint(args.Get("cursor"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid cursor argument")
+			return
+		}
+	}
+
+	if args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if _limit > filters.Limit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", filters.Limit))
+			return
+		}
+		filters.Limit = _limit
+	}
+
+	entries, err := api.db.GetSlotSummaries(filters)
+	if err != nil {
+		api.log.WithError(err).Error("error getting slot summaries")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.SlotSummaryJSON, len(entries))
+	for i, entry := range entries {
+		response[i] = database.SlotSummaryEntryToSlotSummaryJSON(entry)
+	}
+
+	api.RespondOK(w, response)
+}
+
+func (api *RelayAPI) handleDataEpochSummary(w http.ResponseWriter, req *http.Request) {
+	var err error
+	args := req.URL.Query()
+
+	filters := database.GetEpochSummariesFilters{
+		Limit: 200,
+	}
+
+	if args.Get("from_epoch") != "" {
+		filters.FromEpoch, err = strconv.ParseUint(args.Get("from_epoch"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid from_epoch argument")
+			return
+		}
+	}
+
+	if args.Get("to_epoch") != "" {
+		filters.ToEpoch, err = strconv.ParseUint(args.Get("to_epoch"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid to_epoch argument")
+			return
+		}
+		if filters.ToEpoch < filters.FromEpoch {
+			api.RespondError(w, http.StatusBadRequest, "to_epoch must not be before from_epoch")
+			return
+		}
+	}
+
+	if args.Get("limit") != "" {
+		_limit, err := strconv.ParseUint(args.Get("limit"), 10, 64)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid limit argument")
+			return
+		}
+		if _limit > filters.Limit {
+			api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("maximum limit is %d", filters.Limit))
+			return
+		}
+		filters.Limit = _limit
+	}
+
+	entries, err := api.db.GetEpochSummaries(filters)
+	if err != nil {
+		api.log.WithError(err).Error("error getting epoch summaries")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]common.EpochSummaryJSON, len(entries))
+	for i, entry := range entries {
+		response[i] = database.EpochSummaryEntryToEpochSummaryJSON(entry)
+	}
+
+	api.RespondOK(w, response)
+}
+
+// countSlotRequest increments the getHeader or getPayload count of the slot summary, in the background
+func (api *RelayAPI) countSlotRequest(log *logrus.Entry, slot uint64, field string) {
+	go func() {
+		if err := api.redis.IncSlotRequestCount(slot, field); err != nil {
+			log.WithError(err).Warn("failed to count slot request")
+		}
+	}()
+}
+
+// countEpoch adds n to a counter of the epoch summary, in the background
+func (api *RelayAPI) countEpoch(log *logrus.Entry, epoch uint64, field string, n int64) {
+	go func() {
+		if err := api.redis.IncEpochCount(epoch, field, n); err != nil {
+			log.WithError(err).Warn("failed to count for the epoch summary")
+		}
+	}()
+}
+
+func (api *RelayAPI) handleLivez(w http.ResponseWriter, req *http.Request) {
+	api.RespondMsg(w, http.StatusOK, "live")
 }
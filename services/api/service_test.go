@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"math/big"
 	"net/http"
@@ -15,12 +17,17 @@ import (
 	"github.com/alicebob/miniredis/v2"
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	builderSpec "github.com/attestantio/go-builder-client/spec"
+	eth2Api "github.com/attestantio/go-eth2-client/api"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/utils"
@@ -39,7 +46,7 @@ const (
 	testParentHash      = "0xbd3291854dc822b7ec585925cda0e18f06af28fa2886e15f52d52dd4b6f94ed6"
 	testWithdrawalsRoot = "0x7f6d156912a4cb1e74ee37e492ad883f7f7ac856d987b3228b517e490aa0189e"
 	testPrevRandao      = "0x9962816e9d0a39fd4c80935338a741dc916d1545694e41eb5a505e1a3098f9e4"
-	testBuilderPubkey   = "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	testBuilderPubkey   = "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
 )
 
 var (
@@ -191,6 +198,65 @@ func TestLivez(t *testing.T) {
 	require.Equal(t, "{\"message\":\"live\"}\n", rr.Body.String())
 }
 
+func TestRequestID(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	// A request ID is generated and returned in the error response
+	rr := backend.request(http.MethodPost, pathSubmitNewBlock, nil)
+	requestID := rr.Header().Get(HeaderRequestID)
+	require.NotEmpty(t, requestID)
+	resp := new(HTTPErrorResp)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, requestID, resp.RequestID)
+	require.Equal(t, ErrorCodeDecodeFailed, resp.ErrorCode)
+
+	// The request ID of the client is used
+	rr = backend.requestBytes(http.MethodPost, pathSubmitNewBlock, nil, map[string]string{HeaderRequestID: "builder-req-1"})
+	require.Equal(t, "builder-req-1", rr.Header().Get(HeaderRequestID))
+
+	// Invalid request IDs are replaced
+	rr = backend.requestBytes(http.MethodPost, pathSubmitNewBlock, nil, map[string]string{HeaderRequestID: "no spaces allowed"})
+	require.NotEqual(t, "no spaces allowed", rr.Header().Get(HeaderRequestID))
+}
+
+func TestHealthz(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.beaconClient = beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconclient.NewMockBeaconInstance()})
+	rr := backend.request(http.MethodGet, "/healthz", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	resp := new(HealthResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, "healthy", resp.Status)
+	require.Equal(t, healthStatusOK, resp.Dependencies["redis"].Status)
+	require.Equal(t, healthStatusOK, resp.Dependencies["database"].Status)
+	require.Equal(t, healthStatusOK, resp.Dependencies["beacon"].Status)
+
+	// Readiness additionally requires the known validators
+	rr = backend.request(http.MethodGet, "/readyz", nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	backend.relay.datastore.KnownValidatorsWasUpdated.Store(true)
+	rr = backend.request(http.MethodGet, "/readyz", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestShutdownDraining(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.srvShutdown.Store(true)
+
+	// Liveness stays positive, readiness is negative
+	rr := backend.request(http.MethodGet, "/livez", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = backend.request(http.MethodGet, "/readyz", nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	// Block submissions are rejected, the proposer API is still served
+	rr = backend.request(http.MethodPost, pathSubmitNewBlock, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	rr = backend.request(http.MethodGet, pathStatus, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
 func TestRegisterValidator(t *testing.T) {
 	path := "/eth/v1/builder/validators"
 
@@ -211,12 +277,16 @@ func TestGetHeader(t *testing.T) {
 		},
 	}
 
+	// capella in epoch 0, deneb from epoch 1
+	backend.relay.forkSchedule = common.ForkVersionSchedule{CapellaEpoch: 0, DenebEpoch: 1, ElectraEpoch: -1}
+
 	// request params
 	slot := uint64(2)
+	denebSlot := common.SlotsPerEpoch + 1
 	backend.relay.headSlot.Store(slot)
 	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
-	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
-	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	proposerPubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+	builderPubkey := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
 	bidValue := uint256.NewInt(99)
 	trace := &common.BidTraceV2WithBlobFields{
 		BidTrace: builderApiV1.BidTrace{
@@ -250,9 +320,9 @@ func TestGetHeader(t *testing.T) {
 	require.Equal(t, bidValue.String(), value.String())
 
 	// Create a deneb bid
-	path = fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot+1, parentHash, proposerPubkey)
+	path = fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", denebSlot, parentHash, proposerPubkey)
 	opts = common.CreateTestBlockSubmissionOpts{
-		Slot:           slot + 1,
+		Slot:           denebSlot,
 		ParentHash:     parentHash,
 		ProposerPubkey: proposerPubkey,
 		Version:        spec.DataVersionDeneb,
@@ -275,6 +345,79 @@ func TestGetHeader(t *testing.T) {
 	// Check 3: Request returns 204 if sending a filtered user agent
 	rr = backend.requestWithUA(http.MethodGet, path, "mev-boost/v1.5.0 Go-http-client/1.1", nil)
 	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	// Check 4: Request returns 204 if the bid is not of the fork of the slot
+	capellaBidPath := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", denebSlot+1, parentHash, proposerPubkey)
+	opts = common.CreateTestBlockSubmissionOpts{
+		Slot:           denebSlot + 1,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+		Version:        spec.DataVersionCapella,
+	}
+	payload, getPayloadResp, getHeaderResp = common.CreateTestBlockSubmission(t, builderPubkey, bidValue, &opts)
+	_, err = backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	require.NoError(t, err)
+	rr = backend.request(http.MethodGet, capellaBidPath, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	// Check 5: Request returns 204 if the bid is below the min bid of the proposer (which is cached in memory)
+	err = backend.redis.SetProposerMinBid(proposerPubkey, big.NewInt(100))
+	require.NoError(t, err)
+	backend.datastore.InvalidateProposerCache(proposerPubkey)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	err = backend.redis.SetProposerMinBid(proposerPubkey, big.NewInt(99))
+	require.NoError(t, err)
+	backend.datastore.InvalidateProposerCache(proposerPubkey)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Check 6: Request returns 204 if sent before the minimum time into the slot
+	tunables := DefaultTunables()
+	tunables.GetHeaderRequestMinMs = -1000
+	require.NoError(t, backend.relay.UpdateTunables(tunables))
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestUpdateTunables(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	require.Equal(t, DefaultTunables(), *backend.relay.getTunables())
+
+	tunables := DefaultTunables()
+	tunables.GetHeaderRequestCutoffMs = 2000
+	tunables.BlockSimMaxConcurrent = 8
+	require.NoError(t, backend.relay.UpdateTunables(tunables))
+	require.Equal(t, 2000, backend.relay.getTunables().GetHeaderRequestCutoffMs)
+
+	// Invalid tunables are not applied
+	tunables.GetHeaderRequestMinMs = 2500
+	require.ErrorIs(t, backend.relay.UpdateTunables(tunables), ErrInvalidTunables)
+	require.Equal(t, 0, backend.relay.getTunables().GetHeaderRequestMinMs)
+}
+
+func TestInternalProposerMinBid(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	proposerPubkey := "0xb78e514aac8b56810a529b49d9883bf615987f6338bdb664181c505ae8b542d6c91e77daf5e1086ae7b55a9854cd39cc"
+	path := "/internal/v1/proposer/min_bid/" + proposerPubkey
+
+	rr := backend.request(http.MethodPost, path+"?value=1000000000000000000", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	minBid, err := backend.redis.GetProposerMinBid(proposerPubkey)
+	require.NoError(t, err)
+	require.Equal(t, "1000000000000000000", minBid.String())
+
+	// Setting 0 removes the min bid
+	rr = backend.request(http.MethodPost, path+"?value=0", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	minBid, err = backend.redis.GetProposerMinBid(proposerPubkey)
+	require.NoError(t, err)
+	require.Nil(t, minBid)
+
+	for _, query := range []string{"", "?value=abc", "?value=-1"} {
+		rr = backend.request(http.MethodPost, path+query, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
 }
 
 func TestBuilderApiGetValidators(t *testing.T) {
@@ -333,6 +476,168 @@ func TestDataApiGetDataProposerPayloadDelivered(t *testing.T) {
 			require.Contains(t, rr.Body.String(), "invalid block_hash argument")
 		}
 	})
+
+	t.Run("Aggregate by builder operator", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+
+		rr := backend.request(http.MethodGet, path+"?aggregate=builder_operator&limit=50400", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = backend.request(http.MethodGet, path+"?aggregate=builder_operator&limit=50401", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = backend.request(http.MethodGet, path+"?aggregate=builder_operator&order_by=value", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = backend.request(http.MethodGet, path+"?aggregate=builder_region", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid aggregate argument")
+	})
+}
+
+// dataAPIDB returns a single delivered payload and block submission
+type dataAPIDB struct {
+	database.MockDB
+	payload    *database.DeliveredPayloadEntry
+	submission *database.BuilderBlockSubmissionEntry
+}
+
+func (db dataAPIDB) GetRecentDeliveredPayloads(filters database.GetPayloadsFilters) ([]*database.DeliveredPayloadEntry, error) {
+	return []*database.DeliveredPayloadEntry{db.payload}, nil
+}
+
+func (db dataAPIDB) GetBuilderSubmissions(filters database.GetBuilderSubmissionsFilters) ([]*database.BuilderBlockSubmissionEntry, error) {
+	return []*database.BuilderBlockSubmissionEntry{db.submission}, nil
+}
+
+func TestDataApiV2(t *testing.T) {
+	signedAt := time.UnixMilli(1700000000123)
+	db := dataAPIDB{
+		payload: &database.DeliveredPayloadEntry{
+			SignedAt:    sql.NullTime{Time: signedAt, Valid: true},
+			Slot:        testSlot,
+			Value:       "1000",
+			NumBlobs:    3,
+			BlobGasUsed: 393216,
+			MsIntoSlot:  1200,
+		},
+		submission: &database.BuilderBlockSubmissionEntry{
+			ReceivedAt:           sql.NullTime{Time: signedAt, Valid: true},
+			ReceivedAtNs:         signedAt.UnixNano() + 456_789,
+			Slot:                 testSlot,
+			Value:                "1000",
+			NumBlobs:             3,
+			OptimisticSubmission: true,
+		},
+	}
+	backend := newTestBackend(t, 1)
+	backend.relay.db = db
+
+	// v1 responses are unchanged
+	rr := backend.request(http.MethodGet, pathDataProposerPayloadDelivered, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	expected, err := json.Marshal([]common.BidTraceV2JSON{database.DeliveredPayloadEntryToBidTraceV2JSON(db.payload)})
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), rr.Body.String())
+	require.NotContains(t, rr.Body.String(), "num_blobs")
+
+	rr = backend.request(http.MethodGet, pathDataV2ProposerPayloadDelivered, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var payloads []common.DeliveredPayloadV2JSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &payloads))
+	require.Len(t, payloads, 1)
+	require.Equal(t, signedAt.UnixMilli(), payloads[0].TimestampMs)
+	require.Equal(t, uint64(3), payloads[0].NumBlobs)
+	require.Equal(t, "1000", payloads[0].AdjustedValue)
+
+	// Both versions validate the query arguments the same way
+	rr = backend.request(http.MethodGet, pathDataV2ProposerPayloadDelivered+"?slot=1&cursor=1", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = backend.request(http.MethodGet, pathDataV2BuilderBidsReceived, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodGet, pathDataV2BuilderBidsReceived+"?slot=42", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var bids []common.BuilderBidV2JSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &bids))
+	require.Len(t, bids, 1)
+	require.Equal(t, signedAt.UnixMilli(), bids[0].TimestampMs)
+	require.Zero(t, bids[0].EligibleAtMs)
+	require.True(t, bids[0].Optimistic)
+	require.Equal(t, uint64(3), bids[0].NumBlobs)
+}
+
+func TestDataApiGetBids(t *testing.T) {
+	path := "/relay/v1/data/bids"
+
+	t.Run("Accept valid slot", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		rr := backend.request(http.MethodGet, path+"?slot=123", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Reject missing or invalid arguments", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		for _, query := range []string{"", "?slot=abc", "?slot=123&limit=501"} {
+			rr := backend.request(http.MethodGet, path+query, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code, query)
+		}
+	})
+}
+
+func TestDataApiGetPaymentVerification(t *testing.T) {
+	path := "/relay/v1/data/payment_verification"
+	backend := newTestBackend(t, 1)
+
+	for _, query := range []string{"", "?slot=123", "?status=underpaid", "?discrepancies=1&limit=10"} {
+		rr := backend.request(http.MethodGet, path+query, nil)
+		require.Equal(t, http.StatusOK, rr.Code, query)
+	}
+
+	for _, query := range []string{"?slot=abc", "?status=foo", "?limit=201"} {
+		rr := backend.request(http.MethodGet, path+query, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestDataApiGetSlotSummary(t *testing.T) {
+	path := "/relay/v1/data/slot_summary"
+	backend := newTestBackend(t, 1)
+
+	for _, query := range []string{"", "?slot=123", "?cursor=123", "?limit=10"} {
+		rr := backend.request(http.MethodGet, path+query, nil)
+		require.Equal(t, http.StatusOK, rr.Code, query)
+	}
+
+	for _, query := range []string{"?slot=abc", "?cursor=abc", "?slot=1&cursor=2", "?limit=201"} {
+		rr := backend.request(http.MethodGet, path+query, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestDataApiGetEpochSummary(t *testing.T) {
+	path := "/relay/v1/data/epoch_summary"
+	backend := newTestBackend(t, 1)
+
+	for _, query := range []string{"", "?from_epoch=10", "?from_epoch=10&to_epoch=20", "?to_epoch=20&limit=10"} {
+		rr := backend.request(http.MethodGet, path+query, nil)
+		require.Equal(t, http.StatusOK, rr.Code, query)
+	}
+
+	for _, query := range []string{"?from_epoch=abc", "?to_epoch=abc", "?from_epoch=20&to_epoch=10", "?limit=201"} {
+		rr := backend.request(http.MethodGet, path+query, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestBidArchiveCounter(t *testing.T) {
+	c := bidArchiveCounter{}
+	for i := 0; i < bidArchiveMaxPerSlot; i++ {
+		require.True(t, c.inc(10))
+	}
+	require.False(t, c.inc(10))
+	require.False(t, c.inc(9))
+	require.True(t, c.inc(11))
 }
 
 func TestBuilderSubmitBlockSSZ(t *testing.T) {
@@ -444,8 +749,8 @@ func TestBuilderSubmitBlock(t *testing.T) {
 
 			// Setup the test relay backend
 			backend.relay.headSlot.Store(headSlot)
-			backend.relay.capellaEpoch = 0
-			backend.relay.denebEpoch = 2
+			backend.relay.forkSchedule.CapellaEpoch = 0
+			backend.relay.forkSchedule.DenebEpoch = 2
 			backend.relay.proposerDutiesMap = make(map[uint64]*common.BuilderGetValidatorsResponseEntry)
 			backend.relay.proposerDutiesMap[headSlot+1] = &common.BuilderGetValidatorsResponseEntry{
 				Slot: headSlot,
@@ -494,6 +799,7 @@ func TestBuilderSubmitBlock(t *testing.T) {
 			rr := backend.requestBytes(http.MethodPost, path, reqJSONBytes, nil)
 			require.Contains(t, rr.Body.String(), "invalid signature")
 			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Contains(t, rr.Header().Get(HeaderServerTiming), "sigverify;dur=")
 
 			// Send SSZ encoded request
 			reqSSZBytes, err := req.MarshalSSZ()
@@ -951,6 +1257,62 @@ func TestCheckSubmissionSlotDetails(t *testing.T) {
 			},
 			expectOk: false,
 		},
+		{
+			description: "electra_slot",
+			payload: &common.VersionedSubmitBlockRequest{
+				VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{
+					Version: spec.DataVersionElectra,
+					Electra: &builderApiElectra.SubmitBlockRequest{
+						ExecutionPayload: &deneb.ExecutionPayload{
+							Timestamp: (testSlot + 64) * common.SecondsPerSlot,
+						},
+						BlobsBundle:       &builderApiDeneb.BlobsBundle{},
+						ExecutionRequests: &electra.ExecutionRequests{},
+						Message: &builderApiV1.BidTrace{
+							Slot: testSlot + 64,
+						},
+					},
+				},
+			},
+			expectOk: true,
+		},
+		{
+			description: "non_electra_slot",
+			payload: &common.VersionedSubmitBlockRequest{
+				VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{
+					Version: spec.DataVersionElectra,
+					Electra: &builderApiElectra.SubmitBlockRequest{
+						ExecutionPayload: &deneb.ExecutionPayload{
+							Timestamp: (testSlot + 32) * common.SecondsPerSlot,
+						},
+						BlobsBundle:       &builderApiDeneb.BlobsBundle{},
+						ExecutionRequests: &electra.ExecutionRequests{},
+						Message: &builderApiV1.BidTrace{
+							Slot: testSlot + 32,
+						},
+					},
+				},
+			},
+			expectOk: false,
+		},
+		{
+			description: "deneb_payload_for_electra_slot",
+			payload: &common.VersionedSubmitBlockRequest{
+				VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{
+					Version: spec.DataVersionDeneb,
+					Deneb: &builderApiDeneb.SubmitBlockRequest{
+						ExecutionPayload: &deneb.ExecutionPayload{
+							Timestamp: (testSlot + 64) * common.SecondsPerSlot,
+						},
+						BlobsBundle: &builderApiDeneb.BlobsBundle{},
+						Message: &builderApiV1.BidTrace{
+							Slot: testSlot + 64,
+						},
+					},
+				},
+			},
+			expectOk: false,
+		},
 		{
 			description: "failure_past_slot",
 			payload: &common.VersionedSubmitBlockRequest{
@@ -987,8 +1349,9 @@ func TestCheckSubmissionSlotDetails(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.description, func(t *testing.T) {
 			_, _, backend := startTestBackend(t)
-			backend.relay.capellaEpoch = 1
-			backend.relay.denebEpoch = 2
+			backend.relay.forkSchedule.CapellaEpoch = 1
+			backend.relay.forkSchedule.DenebEpoch = 2
+			backend.relay.forkSchedule.ElectraEpoch = 3
 			headSlot := testSlot - 1
 			w := httptest.NewRecorder()
 			logger := logrus.New()
@@ -1053,7 +1416,7 @@ func TestCheckBuilderEntry(t *testing.T) {
 		t.Run(tc.description, func(t *testing.T) {
 			_, _, backend := startTestBackend(t)
 			backend.relay.blockBuildersCache[tc.pk.String()] = tc.entry
-			backend.relay.ffDisableLowPrioBuilders = true
+			backend.relay.ffDisableLowPrioBuilders.Store(true)
 			w := httptest.NewRecorder()
 			logger := logrus.New()
 			log := logrus.NewEntry(logger)
@@ -1240,7 +1603,7 @@ func TestUpdateRedis(t *testing.T) {
 				floorBidValue:        floorValue,
 				payload:              tc.payload,
 			}
-			updateResp, getPayloadResp, ok := backend.relay.updateRedisBid(rOpts)
+			updateResp, getPayloadResp, _, ok := backend.relay.updateRedisBid(rOpts)
 			require.Equal(t, tc.expectOk, ok)
 			if ok {
 				require.NotNil(t, updateResp)
@@ -1270,6 +1633,7 @@ func TestCheckProposerSignature(t *testing.T) {
 		goerli, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
 		require.NoError(t, err)
 		backend.relay.opts.EthNetDetails = *goerli
+		backend.relay.forkSchedule = goerli.ForkSchedule
 		// check signature
 		pubkey, err := utils.HexToPubkey("0xa8afcb5313602f936864b30600f568e04069e596ceed9b55e2a1c872c959ddcb90589636469c15d97e7565344d9ed4ad")
 		require.NoError(t, err)
@@ -1295,6 +1659,7 @@ func TestCheckProposerSignature(t *testing.T) {
 		goerli, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
 		require.NoError(t, err)
 		backend.relay.opts.EthNetDetails = *goerli
+		backend.relay.forkSchedule = goerli.ForkSchedule
 		// check signature
 		pubkey, err := utils.HexToPubkey("0xa8afcb5313602f936864b30600f568e04069e596ceed9b55e2a1c872c959ddcb90589636469c15d97e7565344d9ed4ad")
 		require.NoError(t, err)
@@ -1313,6 +1678,7 @@ func TestCheckProposerSignature(t *testing.T) {
 		goerli, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
 		require.NoError(t, err)
 		backend.relay.opts.EthNetDetails = *goerli
+		backend.relay.forkSchedule = goerli.ForkSchedule
 		// check signature
 		t.Log(payload.Deneb.Message.Slot)
 		pubkey, err := utils.HexToPubkey("0x8322b8af5c6d97e855cc75ad19d59b381a880630cded89268c14acb058cf3c5720ebcde5fa6087dcbb64dbd826936148")
@@ -1339,6 +1705,7 @@ func TestCheckProposerSignature(t *testing.T) {
 		goerli, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
 		require.NoError(t, err)
 		backend.relay.opts.EthNetDetails = *goerli
+		backend.relay.forkSchedule = goerli.ForkSchedule
 		// check signature
 		pubkey, err := utils.HexToPubkey("0x8322b8af5c6d97e855cc75ad19d59b381a880630cded89268c14acb058cf3c5720ebcde5fa6087dcbb64dbd826936148")
 		require.NoError(t, err)
@@ -1357,3 +1724,59 @@ func gzipBytes(t *testing.T, b []byte) []byte {
 	require.NoError(t, zw.Close())
 	return buf.Bytes()
 }
+
+func TestCheckParentBeaconRoot(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	builder := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, Version: spec.DataVersionDeneb}
+	_, getPayloadResp, _ := common.CreateTestBlockSubmission(t, builder, uint256.NewInt(100), &opts)
+	parentHash := getPayloadResp.Deneb.ExecutionPayload.ParentHash.String()
+
+	parentBeaconRoot := phase0.Root{0x02}
+	block := &common.VersionedSignedBlindedBeaconBlock{
+		VersionedSignedBlindedBeaconBlock: eth2Api.VersionedSignedBlindedBeaconBlock{
+			Version: spec.DataVersionDeneb,
+			Deneb: &eth2ApiV1Deneb.SignedBlindedBeaconBlock{
+				Message: &eth2ApiV1Deneb.BlindedBeaconBlock{Slot: 2, ParentRoot: parentBeaconRoot},
+			},
+		},
+	}
+	numUnchecked := func() int64 {
+		if v, ok := getPayloadExpvar.Get("parent_beacon_root_unchecked").(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+
+	// Unknown payload attributes are skipped, but counted
+	before := numUnchecked()
+	require.NoError(t, backend.relay.checkParentBeaconRoot(common.TestLog, block, getPayloadResp))
+	require.Equal(t, before+1, numUnchecked())
+
+	backend.relay.payloadAttributes[getPayloadAttributesKey(parentHash, 2)] = payloadAttributesHelper{slot: 2, parentHash: parentHash}
+	require.NoError(t, backend.relay.checkParentBeaconRoot(common.TestLog, block, getPayloadResp))
+	require.Equal(t, before+2, numUnchecked())
+
+	// Known parent beacon block root
+	backend.relay.payloadAttributes[getPayloadAttributesKey(parentHash, 2)] = payloadAttributesHelper{slot: 2, parentHash: parentHash, parentBeaconRoot: &parentBeaconRoot}
+	require.NoError(t, backend.relay.checkParentBeaconRoot(common.TestLog, block, getPayloadResp))
+	otherRoot := phase0.Root{0x03}
+	backend.relay.payloadAttributes[getPayloadAttributesKey(parentHash, 2)] = payloadAttributesHelper{slot: 2, parentHash: parentHash, parentBeaconRoot: &otherRoot}
+	require.ErrorIs(t, backend.relay.checkParentBeaconRoot(common.TestLog, block, getPayloadResp), ErrParentBeaconRootMismatch)
+	require.Equal(t, before+2, numUnchecked())
+
+	// Electra blocks are checked the same way
+	opts.Version = spec.DataVersionElectra
+	_, electraPayloadResp, _ := common.CreateTestBlockSubmission(t, builder, uint256.NewInt(100), &opts)
+	electraBlock := &common.VersionedSignedBlindedBeaconBlock{
+		VersionedSignedBlindedBeaconBlock: eth2Api.VersionedSignedBlindedBeaconBlock{
+			Version: spec.DataVersionElectra,
+			Electra: &eth2ApiV1Electra.SignedBlindedBeaconBlock{
+				Message: &eth2ApiV1Electra.BlindedBeaconBlock{Slot: 2, ParentRoot: parentBeaconRoot},
+			},
+		},
+	}
+	require.ErrorIs(t, backend.relay.checkParentBeaconRoot(common.TestLog, electraBlock, electraPayloadResp), ErrParentBeaconRootMismatch)
+	backend.relay.payloadAttributes[getPayloadAttributesKey(parentHash, 2)] = payloadAttributesHelper{slot: 2, parentHash: parentHash, parentBeaconRoot: &parentBeaconRoot}
+	require.NoError(t, backend.relay.checkParentBeaconRoot(common.TestLog, electraBlock, electraPayloadResp))
+}
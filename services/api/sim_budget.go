@@ -0,0 +1,57 @@
+package api
+
+import (
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+var (
+	// time into the slot by which the simulations of its submissions must be done (0 for the fixed BLOCKSIM_TIMEOUT_MS)
+	simBudgetSlotDeadline = time.Duration(cli.GetEnvInt("BLOCKSIM_DEADLINE_MS", 0)) * time.Millisecond
+
+	// minimum simulation timeout, for submissions received after the deadline
+	simBudgetMinTimeout = time.Duration(cli.GetEnvInt("BLOCKSIM_TIMEOUT_MIN_MS", 500)) * time.Millisecond
+
+	// simulation timeout of late top bids, so that they fail fast and the builder can resubmit
+	simBudgetTopBidTimeout = time.Duration(cli.GetEnvInt("BLOCKSIM_TIMEOUT_TOP_BID_MS", 1500)) * time.Millisecond
+)
+
+// reasons of simulation timeout budgets, in the logs and the Server-Timing header
+const (
+	simBudgetReasonFixed        = "fixed"
+	simBudgetReasonMax          = "max"
+	simBudgetReasonSlotDeadline = "slot_deadline"
+	simBudgetReasonMin          = "min"
+	simBudgetReasonTopBid       = "top_bid"
+)
+
+// simBudget is the timeout of the simulation of a block submission, and why it was chosen
+type simBudget struct {
+	timeout time.Duration
+	reason  string
+}
+
+// getSimBudget returns the simulation timeout of a submission for the slot. Early submissions can use up to
+// BLOCKSIM_TIMEOUT_MS, later ones the time left until BLOCKSIM_DEADLINE_MS into the slot, but at least
+// BLOCKSIM_TIMEOUT_MIN_MS. Late top bids are limited to BLOCKSIM_TIMEOUT_TOP_BID_MS, as a slow simulation would delay
+// the best bid of the slot.
+func (api *RelayAPI) getSimBudget(slot uint64, isTopBid bool, now time.Time) simBudget {
+	if simBudgetSlotDeadline == 0 {
+		return simBudget{timeout: simRequestTimeout, reason: simBudgetReasonFixed}
+	}
+
+	slotStart := time.Unix(int64(api.genesisInfo.Data.GenesisTime+slot*common.SecondsPerSlot), 0)
+	remaining := slotStart.Add(simBudgetSlotDeadline).Sub(now)
+	switch {
+	case remaining >= simRequestTimeout:
+		return simBudget{timeout: simRequestTimeout, reason: simBudgetReasonMax}
+	case isTopBid && remaining > simBudgetTopBidTimeout:
+		return simBudget{timeout: simBudgetTopBidTimeout, reason: simBudgetReasonTopBid}
+	case remaining > simBudgetMinTimeout:
+		return simBudget{timeout: remaining, reason: simBudgetReasonSlotDeadline}
+	default:
+		return simBudget{timeout: simBudgetMinTimeout, reason: simBudgetReasonMin}
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSimBudget(t *testing.T) {
+	api := &RelayAPI{genesisInfo: &beaconclient.GetGenesisResponse{Data: beaconclient.GetGenesisResponseData{GenesisTime: 1606824023}}}
+	slot := uint64(100)
+	slotStart := time.Unix(int64(1606824023+slot*common.SecondsPerSlot), 0)
+
+	defer func(deadline, maxTimeout, minTimeout, topBidTimeout time.Duration) {
+		simBudgetSlotDeadline, simRequestTimeout, simBudgetMinTimeout, simBudgetTopBidTimeout = deadline, maxTimeout, minTimeout, topBidTimeout
+	}(simBudgetSlotDeadline, simRequestTimeout, simBudgetMinTimeout, simBudgetTopBidTimeout)
+	simRequestTimeout = 3 * time.Second
+	simBudgetMinTimeout = 500 * time.Millisecond
+	simBudgetTopBidTimeout = 1500 * time.Millisecond
+
+	// Disabled without a deadline
+	simBudgetSlotDeadline = 0
+	require.Equal(t, simBudget{timeout: 3 * time.Second, reason: simBudgetReasonFixed}, api.getSimBudget(slot, false, slotStart))
+
+	simBudgetSlotDeadline = 2 * time.Second
+	testCases := []struct {
+		name     string
+		at       time.Duration // relative to the slot start
+		isTopBid bool
+		expected simBudget
+	}{
+		{"early submission", -6 * time.Second, false, simBudget{3 * time.Second, simBudgetReasonMax}},
+		{"early top bid", -6 * time.Second, true, simBudget{3 * time.Second, simBudgetReasonMax}},
+		{"late submission", 0, false, simBudget{2 * time.Second, simBudgetReasonSlotDeadline}},
+		{"late top bid", 0, true, simBudget{1500 * time.Millisecond, simBudgetReasonTopBid}},
+		{"late top bid close to the deadline", 1 * time.Second, true, simBudget{1 * time.Second, simBudgetReasonSlotDeadline}},
+		{"after the deadline", 3 * time.Second, false, simBudget{500 * time.Millisecond, simBudgetReasonMin}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, api.getSimBudget(slot, tc.isTopBid, slotStart.Add(tc.at)))
+		})
+	}
+}
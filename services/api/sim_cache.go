@@ -0,0 +1,108 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+// simCacheInputs is everything a successful simulation of a block depends on besides the block itself: the payload
+// attributes it was built for, the proposer payment claimed in the bid, and the registered gas limit
+type simCacheInputs struct {
+	slot                 uint64
+	parentHash           string
+	prevRandao           string
+	withdrawalsRoot      phase0.Root
+	parentBeaconRoot     phase0.Root
+	proposerFeeRecipient bellatrix.ExecutionAddress
+	value                string
+	registeredGasLimit   uint64
+}
+
+func newSimCacheInputs(submission *common.BlockSubmissionInfo, attrs payloadAttributesHelper, registeredGasLimit uint64) simCacheInputs {
+	inputs := simCacheInputs{
+		slot:                 submission.BidTrace.Slot,
+		parentHash:           attrs.parentHash,
+		prevRandao:           attrs.payloadAttributes.PrevRandao,
+		withdrawalsRoot:      attrs.withdrawalsRoot,
+		proposerFeeRecipient: submission.BidTrace.ProposerFeeRecipient,
+		value:                submission.BidTrace.Value.Dec(),
+		registeredGasLimit:   registeredGasLimit,
+	}
+	if attrs.parentBeaconRoot != nil {
+		inputs.parentBeaconRoot = *attrs.parentBeaconRoot
+	}
+	return inputs
+}
+
+// simCache remembers the block hashes of the latest slot which were simulated successfully, so that resubmissions of
+// the same block (i.e. with a newer timestamp, or by another builder key) are not simulated again
+type simCache struct {
+	mu      sync.Mutex
+	slot    uint64
+	entries map[phase0.Hash32]simCacheInputs
+}
+
+func newSimCache() *simCache {
+	return &simCache{entries: make(map[phase0.Hash32]simCacheInputs)}
+}
+
+// get returns whether the block was simulated successfully with the same inputs. An entry with different inputs
+// (i.e. payload attributes which changed after a reorg) is dropped, and the block is simulated again.
+func (c *simCache) get(blockHash phase0.Hash32, inputs simCacheInputs) (found, invalidated bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[blockHash]
+	if !ok {
+		return false, false
+	}
+	if cached != inputs {
+		delete(c.entries, blockHash)
+		return false, true
+	}
+	return true, false
+}
+
+// add records a successful simulation. Entries of previous slots are dropped once a block of a newer slot is added.
+func (c *simCache) add(blockHash phase0.Hash32, inputs simCacheInputs) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if inputs.slot < c.slot {
+		return
+	}
+	if inputs.slot > c.slot {
+		c.slot = inputs.slot
+		clear(c.entries)
+	}
+	c.entries[blockHash] = inputs
+}
+
+// prune drops the entries if they're of a slot before the given slot, and returns how many were dropped
+func (c *simCache) prune(beforeSlot uint64) (removed int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.slot >= beforeSlot {
+		return 0
+	}
+	removed = len(c.entries)
+	clear(c.entries)
+	return removed
+}
+
+// isSimCached returns whether the simulation of the submission can be skipped, because the same block was already
+// simulated successfully for the same payload attributes, proposer payment and gas limit
+func (api *RelayAPI) isSimCached(log *logrus.Entry, submission *common.BlockSubmissionInfo, attrs payloadAttributesHelper, registeredGasLimit uint64) bool {
+	if api.simCache == nil {
+		return false
+	}
+	found, invalidated := api.simCache.get(submission.BidTrace.BlockHash, newSimCacheInputs(submission, attrs, registeredGasLimit))
+	if invalidated {
+		log.Info("cached simulation of the block doesn't match the submission, simulating again")
+	} else if found {
+		log.Info("block already simulated successfully, skipping simulation")
+	}
+	return found
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimCache(t *testing.T) {
+	c := newSimCache()
+	blockHash := phase0.Hash32{0x01}
+	inputs := simCacheInputs{slot: 10, parentHash: "0x02", prevRandao: "0x03", value: "100", registeredGasLimit: 30_000_000}
+
+	found, invalidated := c.get(blockHash, inputs)
+	require.False(t, found)
+	require.False(t, invalidated)
+
+	c.add(blockHash, inputs)
+	found, _ = c.get(blockHash, inputs)
+	require.True(t, found)
+
+	// different payload attributes invalidate the entry
+	changed := inputs
+	changed.prevRandao = "0x04"
+	found, invalidated = c.get(blockHash, changed)
+	require.False(t, found)
+	require.True(t, invalidated)
+	found, invalidated = c.get(blockHash, inputs)
+	require.False(t, found)
+	require.False(t, invalidated)
+
+	// entries of older slots are dropped when a newer slot is added, and older slots aren't added
+	c.add(blockHash, inputs)
+	newer := inputs
+	newer.slot = 11
+	c.add(phase0.Hash32{0x05}, newer)
+	found, _ = c.get(blockHash, inputs)
+	require.False(t, found)
+	c.add(blockHash, inputs)
+	found, _ = c.get(blockHash, inputs)
+	require.False(t, found)
+
+	// pruning drops the entries once their slot is over
+	require.Equal(t, 0, c.prune(11))
+	require.Equal(t, 1, c.prune(12))
+	found, _ = c.get(phase0.Hash32{0x05}, newer)
+	require.False(t, found)
+}
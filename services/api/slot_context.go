@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+// slotContext is what block submissions for the upcoming slot are validated against: the payload attributes of the
+// current head and the proposer duty. It is replaced as a whole on beacon events, so submissions can read it without
+// taking any lock.
+type slotContext struct {
+	slot         uint64
+	attrs        payloadAttributesHelper
+	proposerDuty *common.BuilderGetValidatorsResponseEntry // nil if not (yet) known
+	updatedAt    time.Time
+}
+
+// SlotContextJSON is the response of the internal slot context endpoint
+type SlotContextJSON struct {
+	Slot                  uint64 `json:"slot,string"`
+	Epoch                 uint64 `json:"epoch,string"`
+	ParentHash            string `json:"parent_hash"`
+	ParentBlockNumber     uint64 `json:"parent_block_number,string"`
+	PrevRandao            string `json:"prev_randao"`
+	Timestamp             uint64 `json:"timestamp,string"`
+	WithdrawalsRoot       string `json:"withdrawals_root"`
+	ParentBeaconBlockRoot string `json:"parent_beacon_block_root,omitempty"`
+	ProposerPubkey        string `json:"proposer_pubkey,omitempty"`
+	ProposerFeeRecipient  string `json:"proposer_fee_recipient,omitempty"`
+	RegisteredGasLimit    uint64 `json:"registered_gas_limit,omitempty,string"`
+	UpdatedAt             string `json:"updated_at"`
+}
+
+func (c *slotContext) toJSON() SlotContextJSON {
+	ret := SlotContextJSON{
+		Slot:              c.slot,
+		Epoch:             common.SlotToEpoch(c.slot),
+		ParentHash:        c.attrs.parentHash,
+		ParentBlockNumber: c.attrs.parentBlockNumber,
+		PrevRandao:        c.attrs.payloadAttributes.PrevRandao,
+		Timestamp:         c.attrs.payloadAttributes.Timestamp,
+		WithdrawalsRoot:   c.attrs.withdrawalsRoot.String(),
+		UpdatedAt:         c.updatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	if c.attrs.parentBeaconRoot != nil {
+		ret.ParentBeaconBlockRoot = c.attrs.parentBeaconRoot.String()
+	}
+	if c.proposerDuty != nil && c.proposerDuty.Entry != nil && c.proposerDuty.Entry.Message != nil {
+		ret.ProposerPubkey = c.proposerDuty.Entry.Message.Pubkey.String()
+		ret.ProposerFeeRecipient = c.proposerDuty.Entry.Message.FeeRecipient.String()
+		ret.RegisteredGasLimit = c.proposerDuty.Entry.Message.GasLimit
+	}
+	return ret
+}
+
+// updateSlotContextAttrs switches the slot context to new payload attributes, unless they are for an older slot
+func (api *RelayAPI) updateSlotContextAttrs(attrs payloadAttributesHelper) {
+	api.proposerDutiesLock.RLock()
+	duty := api.proposerDutiesMap[attrs.slot]
+	api.proposerDutiesLock.RUnlock()
+
+	next := &slotContext{slot: attrs.slot, attrs: attrs, proposerDuty: duty, updatedAt: time.Now()}
+	for {
+		prev := api.slotCtx.Load()
+		if prev != nil && prev.slot > attrs.slot {
+			return
+		}
+		if api.slotCtx.CompareAndSwap(prev, next) {
+			return
+		}
+	}
+}
+
+// updateSlotContextDuty sets the proposer duty of the slot context after the proposer duties were updated
+func (api *RelayAPI) updateSlotContextDuty(dutiesMap map[uint64]*common.BuilderGetValidatorsResponseEntry) {
+	for {
+		prev := api.slotCtx.Load()
+		if prev == nil || prev.proposerDuty == dutiesMap[prev.slot] {
+			return
+		}
+		next := *prev
+		next.proposerDuty = dutiesMap[prev.slot]
+		next.updatedAt = time.Now()
+		if api.slotCtx.CompareAndSwap(prev, &next) {
+			return
+		}
+	}
+}
+
+// getProposerDuty returns the proposer duty of the slot, from the slot context if it's the current slot
+func (api *RelayAPI) getProposerDuty(slot uint64) *common.BuilderGetValidatorsResponseEntry {
+	if ctx := api.slotCtx.Load(); ctx != nil && ctx.slot == slot && ctx.proposerDuty != nil {
+		return ctx.proposerDuty
+	}
+	api.proposerDutiesLock.RLock()
+	defer api.proposerDutiesLock.RUnlock()
+	return api.proposerDutiesMap[slot]
+}
+
+// getPayloadAttributes returns the payload attributes of the slot and parent, from the slot context if they are the
+// current ones, and otherwise from the attributes of all known heads (i.e. after a reorg)
+func (api *RelayAPI) getPayloadAttributes(parentHash string, slot uint64) (payloadAttributesHelper, bool) {
+	if ctx := api.slotCtx.Load(); ctx != nil && ctx.slot == slot && ctx.attrs.parentHash == parentHash {
+		return ctx.attrs, true
+	}
+	api.payloadAttributesLock.RLock()
+	defer api.payloadAttributesLock.RUnlock()
+	attrs, ok := api.payloadAttributes[getPayloadAttributesKey(parentHash, slot)]
+	return attrs, ok
+}
+
+func (api *RelayAPI) handleInternalSlotContext(w http.ResponseWriter, req *http.Request) {
+	ctx := api.slotCtx.Load()
+	if ctx == nil {
+		api.RespondError(w, http.StatusNotFound, "slot context not yet known")
+		return
+	}
+	api.RespondOK(w, ctx.toJSON())
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlotContext(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	api := backend.relay
+
+	duty := &common.BuilderGetValidatorsResponseEntry{
+		Slot: 11,
+		Entry: &builderApiV1.SignedValidatorRegistration{
+			Message: &builderApiV1.ValidatorRegistration{GasLimit: 30_000_000},
+		},
+	}
+	api.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{}
+
+	t.Run("not yet known", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, pathInternalSlotContext, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("payload attributes and proposer duty", func(t *testing.T) {
+		api.updateSlotContextAttrs(payloadAttributesHelper{slot: 11, parentHash: "0x01", payloadAttributes: beaconclient.PayloadAttributes{PrevRandao: "0x02"}})
+		require.Nil(t, api.getProposerDuty(11))
+
+		api.updateSlotContextDuty(map[uint64]*common.BuilderGetValidatorsResponseEntry{11: duty})
+		require.Equal(t, duty, api.getProposerDuty(11))
+
+		attrs, ok := api.getPayloadAttributes("0x01", 11)
+		require.True(t, ok)
+		require.Equal(t, "0x02", attrs.payloadAttributes.PrevRandao)
+		_, ok = api.getPayloadAttributes("0x03", 11)
+		require.False(t, ok)
+
+		rr := backend.request(http.MethodGet, pathInternalSlotContext, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := new(SlotContextJSON)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, uint64(11), resp.Slot)
+		require.Equal(t, "0x01", resp.ParentHash)
+		require.Equal(t, uint64(30_000_000), resp.RegisteredGasLimit)
+	})
+
+	t.Run("attributes of older slots are ignored", func(t *testing.T) {
+		api.updateSlotContextAttrs(payloadAttributesHelper{slot: 10, parentHash: "0x04"})
+		require.Equal(t, uint64(11), api.slotCtx.Load().slot)
+
+		api.updateSlotContextAttrs(payloadAttributesHelper{slot: 12, parentHash: "0x05"})
+		require.Equal(t, uint64(12), api.slotCtx.Load().slot)
+		require.Nil(t, api.slotCtx.Load().proposerDuty)
+	})
+}
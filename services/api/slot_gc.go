@@ -0,0 +1,49 @@
+package api
+
+import (
+	"expvar"
+
+	"github.com/sirupsen/logrus"
+)
+
+// number of entries of past slots removed from the in-memory caches, served on the diagnostics listener
+var slotGCExpvar = expvar.NewMap("slot_gc")
+
+// pruneSlotState drops the in-memory auction state (duplicate submissions, simulated blocks and payload attributes)
+// of the slots before the given slot. The caches are otherwise only pruned by the requests of a newer slot, and keep
+// the state of the last busy slot while there are no submissions.
+func (api *RelayAPI) pruneSlotState(beforeSlot uint64) {
+	removed := map[string]int{
+		"submission_dedup":   api.submissionDedup.prune(beforeSlot),
+		"payload_attributes": api.prunePayloadAttributes(beforeSlot),
+	}
+	if api.simCache != nil {
+		removed["sim_cache"] = api.simCache.prune(beforeSlot)
+	}
+
+	fields := logrus.Fields{"beforeSlot": beforeSlot}
+	for name, n := range removed {
+		slotGCExpvar.Add(name, int64(n))
+		fields[name] = n
+	}
+	api.log.WithFields(fields).Debug("pruned in-memory state of past slots")
+}
+
+// prunePayloadAttributes removes the payload attributes of the slots before the given slot, and returns how many
+// were removed
+func (api *RelayAPI) prunePayloadAttributes(beforeSlot uint64) int {
+	api.payloadAttributesLock.Lock()
+	defer api.payloadAttributesLock.Unlock()
+	return api.prunePayloadAttributesLocked(beforeSlot)
+}
+
+// prunePayloadAttributesLocked is prunePayloadAttributes for callers holding payloadAttributesLock
+func (api *RelayAPI) prunePayloadAttributesLocked(beforeSlot uint64) (removed int) {
+	for key, attr := range api.payloadAttributes {
+		if attr.slot < beforeSlot {
+			delete(api.payloadAttributes, key)
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneSlotState(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.simCache = newSimCache()
+	for _, slot := range []uint64{9, 10, 11} {
+		backend.relay.payloadAttributes[getPayloadAttributesKey("0x01", slot)] = payloadAttributesHelper{slot: slot, parentHash: "0x01"}
+		backend.relay.submissionDedup.entries[submissionDedupKey{slot: slot}] = &submissionDedupEntry{}
+	}
+	backend.relay.simCache.add(phase0.Hash32{0x02}, simCacheInputs{slot: 9})
+
+	backend.relay.pruneSlotState(10)
+	require.Len(t, backend.relay.payloadAttributes, 2)
+	require.Contains(t, backend.relay.payloadAttributes, getPayloadAttributesKey("0x01", 10))
+	require.Len(t, backend.relay.submissionDedup.entries, 2)
+	require.Empty(t, backend.relay.simCache.entries)
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrSubmissionTooLarge = errors.New("block submission too large")
+	ErrNoProposerPayment  = errors.New("last transaction is not a payment to the proposer fee recipient")
+
+	// maximum size of a block submission request body, after decompression
+	maxSubmissionBytes = int64(cli.GetEnvInt("BLOCK_SUBMISSION_MAX_BYTES", 10*1024*1024))
+)
+
+const (
+	// gasLimitBoundDivisor limits the change of the gas limit from the parent block (https://eips.ethereum.org/EIPS/eip-1559)
+	gasLimitBoundDivisor = 1024
+
+	// minGasLimit is the minimum gas limit of a block
+	minGasLimit = 5000
+
+	// blockGasLimitsCacheSize is the number of recent block hashes whose gas limit is remembered
+	blockGasLimitsCacheSize = 1_000
+)
+
+// readSubmissionBody reads the request body, failing with ErrSubmissionTooLarge if it's larger than maxBytes
+func readSubmissionBody(r io.Reader, maxBytes int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrSubmissionTooLarge, maxBytes)
+	}
+	return body, nil
+}
+
+// expectedGasLimit returns the gas limit of a block built on a parent with parentGasLimit, which moves towards the
+// gas limit registered by the proposer as far as allowed (the same formula as CalcGasLimit in geth)
+func expectedGasLimit(parentGasLimit, registeredGasLimit uint64) uint64 {
+	delta := parentGasLimit/gasLimitBoundDivisor - 1
+	if registeredGasLimit < minGasLimit {
+		registeredGasLimit = minGasLimit
+	}
+	if parentGasLimit < registeredGasLimit {
+		return min(parentGasLimit+delta, registeredGasLimit)
+	}
+	if parentGasLimit > registeredGasLimit {
+		return max(parentGasLimit-delta, registeredGasLimit)
+	}
+	return parentGasLimit
+}
+
+// checkSubmissionGas checks the gas fields of the bid trace against the execution payload and, if the gas limit of the
+// parent block is known, the gas limit against the one registered by the proposer
+func (api *RelayAPI) checkSubmissionGas(w http.ResponseWriter, log *logrus.Entry, submission *common.BlockSubmissionInfo, registeredGasLimit uint64, attrs payloadAttributesHelper) bool {
+	if submission.BidTrace.GasLimit != submission.GasLimit || submission.BidTrace.GasUsed != submission.GasUsed {
+		msg := fmt.Sprintf("bid trace gas limit %d / gas used %d do not match the payload %d / %d", submission.BidTrace.GasLimit, submission.BidTrace.GasUsed, submission.GasLimit, submission.GasUsed)
+		log.Info(msg)
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeGasLimitMismatch, msg)
+		return false
+	}
+
+	if submission.GasUsed > submission.GasLimit {
+		msg := fmt.Sprintf("gas used %d exceeds gas limit %d", submission.GasUsed, submission.GasLimit)
+		log.Info(msg)
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeGasUsedExceedsGasLimit, msg)
+		return false
+	}
+
+	parentGasLimit, ok := api.blockGasLimits.Get(attrs.parentHash)
+	if !ok || registeredGasLimit == 0 {
+		return true
+	}
+	expected := expectedGasLimit(parentGasLimit, registeredGasLimit)
+	if submission.GasLimit != expected {
+		msg := fmt.Sprintf("incorrect gas limit - got: %d, expected: %d (parent: %d, registered: %d)", submission.GasLimit, expected, parentGasLimit, registeredGasLimit)
+		log.Info(msg)
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeGasLimitMismatch, msg)
+		return false
+	}
+	return true
+}
+
+// getProposerPayment returns the value transferred to the proposer fee recipient by the last transaction of the block
+func getProposerPayment(submission *common.BlockSubmissionInfo) (*big.Int, error) {
+	if len(submission.Transactions) == 0 {
+		return nil, ErrNoProposerPayment
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(submission.Transactions[len(submission.Transactions)-1]); err != nil {
+		return nil, err
+	}
+	if to := tx.To(); to == nil || *to != ethcommon.Address(submission.BidTrace.ProposerFeeRecipient) {
+		return nil, ErrNoProposerPayment
+	}
+	return tx.Value(), nil
+}
+
+// checkSubmissionPayment rejects a block whose payment to the proposer is lower than the bid value. If the proposer
+// fee recipient is the fee recipient of the block, the proposer is paid by the balance difference, which only the
+// block simulation can verify. Otherwise the last transaction of the block must pay at least the bid value.
+func (api *RelayAPI) checkSubmissionPayment(w http.ResponseWriter, log *logrus.Entry, submission *common.BlockSubmissionInfo) bool {
+	if submission.FeeRecipient == submission.BidTrace.ProposerFeeRecipient {
+		return true
+	}
+
+	paid, err := getProposerPayment(submission)
+	if errors.Is(err, ErrNoProposerPayment) {
+		paid = big.NewInt(0)
+	} else if err != nil {
+		log.WithError(err).Warn("failed to decode the proposer payment transaction")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeDecodeFailed, "failed to decode the last transaction")
+		return false
+	}
+
+	claimed := submission.BidTrace.Value.ToBig()
+	if paid.Cmp(claimed) >= 0 {
+		return true
+	}
+
+	discrepancy := new(big.Int).Sub(claimed, paid)
+	log.WithFields(logrus.Fields{
+		"claimedValue": claimed.String(),
+		"paidValue":    paid.String(),
+		"discrepancy":  discrepancy.String(),
+	}).Warn("bid value exceeds the payment to the proposer")
+	if err := api.redis.IncStats(datastore.RedisStatsFieldInflatedBids, 1); err != nil {
+		log.WithError(err).Error("failed to increment the inflated bids count")
+	}
+
+	// Keep a durable record of the discrepancy, as the rejected submission itself isn't saved
+	entry := &database.BidPaymentDiscrepancyEntry{
+		Slot:                 submission.BidTrace.Slot,
+		BlockHash:            submission.BidTrace.BlockHash.String(),
+		BuilderPubkey:        submission.BidTrace.BuilderPubkey.String(),
+		ProposerPubkey:       submission.BidTrace.ProposerPubkey.String(),
+		ProposerFeeRecipient: submission.BidTrace.ProposerFeeRecipient.String(),
+		ClaimedValue:         claimed.String(),
+		PaidValue:            paid.String(),
+	}
+	api.backgroundDBWritesWG.Add(1)
+	go func() {
+		defer api.backgroundDBWritesWG.Done()
+		if err := api.db.InsertBidPaymentDiscrepancy(entry); err != nil {
+			log.WithError(err).Error("failed to save the payment discrepancy")
+		}
+	}()
+
+	msg := fmt.Sprintf("bid value %s exceeds the payment of %s to the proposer fee recipient %s by %s wei", claimed.String(), paid.String(), submission.BidTrace.ProposerFeeRecipient.String(), discrepancy.String())
+	api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeBidValueMismatch, msg)
+	return false
+}
+
+// recordBlockGasLimit remembers the gas limit of an eligible block, to check the gas limit of its children
+func (api *RelayAPI) recordBlockGasLimit(submission *common.BlockSubmissionInfo) {
+	api.blockGasLimits.Add(submission.BidTrace.BlockHash.String(), submission.GasLimit)
+}
+
+// hasPayloadAttributesForSlot returns true if payload attributes for the slot are known, for any parent hash
+func (api *RelayAPI) hasPayloadAttributesForSlot(slot uint64) bool {
+	if ctx := api.slotCtx.Load(); ctx != nil && ctx.slot == slot {
+		return true
+	}
+	api.payloadAttributesLock.RLock()
+	defer api.payloadAttributesLock.RUnlock()
+	for _, attrs := range api.payloadAttributes {
+		if attrs.slot == slot {
+			return true
+		}
+	}
+	return false
+}
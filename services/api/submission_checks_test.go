@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSubmissionBody(t *testing.T) {
+	body, err := readSubmissionBody(bytes.NewReader(make([]byte, 100)), 100)
+	require.NoError(t, err)
+	require.Len(t, body, 100)
+
+	_, err = readSubmissionBody(bytes.NewReader(make([]byte, 101)), 100)
+	require.ErrorIs(t, err, ErrSubmissionTooLarge)
+}
+
+func TestExpectedGasLimit(t *testing.T) {
+	// parent 30M: the gas limit may change by 30M/1024-1 = 29_295
+	require.Equal(t, uint64(30_000_000), expectedGasLimit(30_000_000, 30_000_000))
+	require.Equal(t, uint64(30_029_295), expectedGasLimit(30_000_000, 36_000_000))
+	require.Equal(t, uint64(29_970_705), expectedGasLimit(30_000_000, 25_000_000))
+	require.Equal(t, uint64(30_010_000), expectedGasLimit(30_000_000, 30_010_000))
+	require.Equal(t, uint64(29_990_000), expectedGasLimit(30_000_000, 29_990_000))
+}
+
+func TestCheckSubmissionGas(t *testing.T) {
+	parentHash := "0xbd3291854dc822b7ec585925cda0e18f06af28fa2886e15f52d52dd4b6f94ed6"
+	api := &RelayAPI{blockGasLimits: lru.NewCache[string, uint64](10)}
+	attrs := payloadAttributesHelper{parentHash: parentHash}
+
+	submission := func(bidGasLimit, gasLimit, gasUsed uint64) *common.BlockSubmissionInfo {
+		return &common.BlockSubmissionInfo{
+			BidTrace: &builderApiV1.BidTrace{GasLimit: bidGasLimit, GasUsed: gasUsed},
+			GasLimit: gasLimit,
+			GasUsed:  gasUsed,
+		}
+	}
+	check := func(s *common.BlockSubmissionInfo, registeredGasLimit uint64) (bool, ErrorCode) {
+		w := httptest.NewRecorder()
+		respW := &submissionResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		ok := api.checkSubmissionGas(respW, common.TestLog, s, registeredGasLimit, attrs)
+		return ok, respW.errCode
+	}
+
+	ok, errCode := check(submission(30_000_000, 29_000_000, 1_000), 30_000_000)
+	require.False(t, ok)
+	require.Equal(t, ErrorCodeGasLimitMismatch, errCode)
+
+	ok, errCode = check(submission(30_000_000, 30_000_000, 30_000_001), 30_000_000)
+	require.False(t, ok)
+	require.Equal(t, ErrorCodeGasUsedExceedsGasLimit, errCode)
+
+	// unknown parent gas limit
+	ok, _ = check(submission(30_000_000, 30_000_000, 1_000), 36_000_000)
+	require.True(t, ok)
+
+	api.blockGasLimits.Add(parentHash, 30_000_000)
+	ok, errCode = check(submission(30_000_000, 30_000_000, 1_000), 36_000_000)
+	require.False(t, ok)
+	require.Equal(t, ErrorCodeGasLimitMismatch, errCode)
+
+	ok, _ = check(submission(30_029_295, 30_029_295, 1_000), 36_000_000)
+	require.True(t, ok)
+}
+
+func TestCheckSubmissionPayment(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	mockDB := database.MockDB{PaymentDiscrepancies: make(map[string]*database.BidPaymentDiscrepancyEntry)}
+	backend.relay.db = mockDB
+	proposerFeeRecipient := ethcommon.HexToAddress("0xdb65fEd33dc262Fe09D9a2Ba8F80b329BA25f941")
+	builderFeeRecipient := ethcommon.HexToAddress("0x5cc0dde14e7256340cc820415a6022a7d1c93a35")
+
+	paymentTx := func(to ethcommon.Address, value int64) bellatrix.Transaction {
+		tx, err := types.NewTx(&types.LegacyTx{To: &to, Value: big.NewInt(value), Gas: 21_000}).MarshalBinary()
+		require.NoError(t, err)
+		return tx
+	}
+	numSubmissions := byte(0)
+	submission := func(feeRecipient ethcommon.Address, value uint64, txs ...bellatrix.Transaction) *common.BlockSubmissionInfo {
+		numSubmissions++
+		return &common.BlockSubmissionInfo{
+			BidTrace: &builderApiV1.BidTrace{
+				Slot:                 testSlot,
+				BlockHash:            phase0.Hash32{numSubmissions},
+				ProposerFeeRecipient: bellatrix.ExecutionAddress(proposerFeeRecipient),
+				Value:                uint256.NewInt(value),
+			},
+			FeeRecipient: bellatrix.ExecutionAddress(feeRecipient),
+			Transactions: txs,
+		}
+	}
+	check := func(s *common.BlockSubmissionInfo) (bool, ErrorCode) {
+		w := httptest.NewRecorder()
+		respW := &submissionResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		ok := backend.relay.checkSubmissionPayment(respW, common.TestLog, s)
+		backend.relay.backgroundDBWritesWG.Wait()
+		return ok, respW.errCode
+	}
+
+	t.Run("fee recipient is the proposer", func(t *testing.T) {
+		// paid by the balance difference of the proposer fee recipient, which only the simulation can verify
+		ok, _ := check(submission(proposerFeeRecipient, 100, paymentTx(builderFeeRecipient, 5)))
+		require.True(t, ok)
+		ok, _ = check(submission(proposerFeeRecipient, 100))
+		require.True(t, ok)
+	})
+
+	t.Run("paid by the last transaction", func(t *testing.T) {
+		ok, _ := check(submission(builderFeeRecipient, 100, paymentTx(builderFeeRecipient, 5), paymentTx(proposerFeeRecipient, 100)))
+		require.True(t, ok)
+		ok, _ = check(submission(builderFeeRecipient, 100, paymentTx(proposerFeeRecipient, 101)))
+		require.True(t, ok)
+	})
+
+	t.Run("inflated bid value", func(t *testing.T) {
+		s := submission(builderFeeRecipient, 100, paymentTx(proposerFeeRecipient, 99))
+		ok, errCode := check(s)
+		require.False(t, ok)
+		require.Equal(t, ErrorCodeBidValueMismatch, errCode)
+
+		entry := mockDB.PaymentDiscrepancies[s.BidTrace.BlockHash.String()]
+		require.NotNil(t, entry)
+		require.Equal(t, testSlot, entry.Slot)
+		require.Equal(t, "100", entry.ClaimedValue)
+		require.Equal(t, "99", entry.PaidValue)
+		require.Equal(t, s.BidTrace.ProposerFeeRecipient.String(), entry.ProposerFeeRecipient)
+	})
+
+	t.Run("last transaction isn't the payment", func(t *testing.T) {
+		s := submission(builderFeeRecipient, 100, paymentTx(proposerFeeRecipient, 100), paymentTx(builderFeeRecipient, 5))
+		ok, errCode := check(s)
+		require.False(t, ok)
+		require.Equal(t, ErrorCodeBidValueMismatch, errCode)
+		require.Equal(t, "0", mockDB.PaymentDiscrepancies[s.BidTrace.BlockHash.String()].PaidValue)
+
+		ok, errCode = check(submission(builderFeeRecipient, 100, bellatrix.Transaction{0x01}))
+		require.False(t, ok)
+		require.Equal(t, ErrorCodeDecodeFailed, errCode)
+	})
+
+	t.Run("block without transactions", func(t *testing.T) {
+		s := submission(builderFeeRecipient, 100)
+		ok, errCode := check(s)
+		require.False(t, ok)
+		require.Equal(t, ErrorCodeBidValueMismatch, errCode)
+		require.Equal(t, "0", mockDB.PaymentDiscrepancies[s.BidTrace.BlockHash.String()].PaidValue)
+	})
+
+	numInflated, err := backend.redis.GetStatsUint64(datastore.RedisStatsFieldInflatedBids)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), numInflated)
+	require.Len(t, mockDB.PaymentDiscrepancies, 3)
+}
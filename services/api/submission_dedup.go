@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+type submissionDedupKey struct {
+	slot          uint64
+	builderPubkey phase0.BLSPubKey
+	blockHash     phase0.Hash32
+}
+
+// submissionDedupEntry is the outcome of the first submission of a block, which duplicates are answered with. done is
+// closed once the outcome is known.
+type submissionDedupEntry struct {
+	done     chan struct{}
+	doneOnce sync.Once
+
+	statusCode int
+	errCode    ErrorCode
+	message    string
+}
+
+// submissionDedup tracks the block submissions of the latest slot by (slot, builder, block hash), so that duplicate
+// submissions of the same block (i.e. concurrent retries by a builder) are neither simulated nor stored again
+type submissionDedup struct {
+	mu      sync.Mutex
+	slot    uint64
+	entries map[submissionDedupKey]*submissionDedupEntry
+}
+
+func newSubmissionDedup() *submissionDedup {
+	return &submissionDedup{entries: make(map[submissionDedupKey]*submissionDedupEntry)}
+}
+
+// start returns the entry for the key, and whether it already existed (i.e. the submission is a duplicate). Entries of
+// previous slots are dropped once the first submission for a newer slot arrives.
+func (d *submissionDedup) start(key submissionDedupKey) (entry *submissionDedupEntry, isDuplicate bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if key.slot > d.slot {
+		d.slot = key.slot
+		for k := range d.entries {
+			if k.slot < key.slot {
+				delete(d.entries, k)
+			}
+		}
+	}
+
+	if entry, ok := d.entries[key]; ok {
+		return entry, true
+	}
+	entry = &submissionDedupEntry{done: make(chan struct{})}
+	d.entries[key] = entry
+	return entry, false
+}
+
+// prune drops the entries of the slots before the given slot, and returns how many were dropped. Duplicates of a
+// pruned submission which are still waiting for it are answered when it finishes.
+func (d *submissionDedup) prune(beforeSlot uint64) (removed int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k := range d.entries {
+		if k.slot < beforeSlot {
+			delete(d.entries, k)
+			removed++
+		}
+	}
+	return removed
+}
+
+// finish records the outcome of the submission and releases waiting duplicates. Server errors and failed simulation
+// requests are not cached, so that later retries of the block are processed again.
+func (d *submissionDedup) finish(key submissionDedupKey, entry *submissionDedupEntry, w *submissionResponseWriter) {
+	entry.doneOnce.Do(func() {
+		entry.statusCode = w.statusCode
+		entry.errCode = w.errCode
+		entry.message = w.message
+		close(entry.done)
+
+		if entry.statusCode >= http.StatusInternalServerError || entry.errCode == ErrorCodeSimRequestFailed {
+			d.mu.Lock()
+			if d.entries[key] == entry {
+				delete(d.entries, key)
+			}
+			d.mu.Unlock()
+		}
+	})
+}
+
+// respondDuplicateSubmission waits for the outcome of the first submission of the block and responds with it
+func (api *RelayAPI) respondDuplicateSubmission(ctx context.Context, w http.ResponseWriter, log *logrus.Entry, entry *submissionDedupEntry) {
+	api.submissionDedupHits.Inc()
+	log = log.WithField("isDuplicateSubmission", true)
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		log.Info("request cancelled while waiting for the original submission")
+		return
+	}
+
+	log.WithField("originalStatusCode", entry.statusCode).Info("duplicate block submission, responding with the original result")
+	if entry.errCode != "" {
+		api.RespondErrorCode(w, entry.statusCode, entry.errCode, entry.message)
+		return
+	}
+	w.WriteHeader(entry.statusCode)
+}
+
+// reportSubmissionDedupHits adds the number of duplicate submissions since the last call to the stats in Redis
+func (api *RelayAPI) reportSubmissionDedupHits(slot uint64) {
+	hits := api.submissionDedupHits.Swap(0)
+	if hits == 0 {
+		return
+	}
+	log := api.log.WithFields(logrus.Fields{
+		"slot":                slot,
+		"submissionDedupHits": hits,
+	})
+	log.Info("duplicate block submissions in previous slot")
+	if err := api.redis.IncStats(datastore.RedisStatsFieldSubmissionDedupHits, int64(hits)); err != nil {
+		log.WithError(err).Error("failed to update submission dedup stats")
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmissionDedup(t *testing.T) {
+	d := newSubmissionDedup()
+	key := submissionDedupKey{slot: 10, builderPubkey: phase0.BLSPubKey{0x01}, blockHash: phase0.Hash32{0x02}}
+
+	// First submission
+	entry, isDuplicate := d.start(key)
+	require.False(t, isDuplicate)
+
+	// Concurrent duplicate waits for the outcome
+	entry2, isDuplicate := d.start(key)
+	require.True(t, isDuplicate)
+	require.Equal(t, entry, entry2)
+	select {
+	case <-entry2.done:
+		t.Fatal("entry should not be done yet")
+	default:
+	}
+
+	w := &submissionResponseWriter{ResponseWriter: httptest.NewRecorder(), statusCode: http.StatusOK}
+	w.errCode = ErrorCodeSimFailed
+	w.message = "simulation failed"
+	w.WriteHeader(http.StatusBadRequest)
+	d.finish(key, entry, w)
+	<-entry2.done
+	require.Equal(t, http.StatusBadRequest, entry2.statusCode)
+	require.Equal(t, ErrorCodeSimFailed, entry2.errCode)
+	require.Equal(t, "simulation failed", entry2.message)
+
+	// Finishing again doesn't change the outcome
+	w.statusCode = http.StatusOK
+	d.finish(key, entry, w)
+	require.Equal(t, http.StatusBadRequest, entry.statusCode)
+
+	// Different block hash is not a duplicate
+	key2 := key
+	key2.blockHash = phase0.Hash32{0x03}
+	entry3, isDuplicate := d.start(key2)
+	require.False(t, isDuplicate)
+
+	// Server errors are not cached
+	w = &submissionResponseWriter{ResponseWriter: httptest.NewRecorder(), statusCode: http.StatusInternalServerError}
+	d.finish(key2, entry3, w)
+	_, isDuplicate = d.start(key2)
+	require.False(t, isDuplicate)
+
+	// Entries of previous slots are dropped on a new slot
+	key3 := key
+	key3.slot = 11
+	_, isDuplicate = d.start(key3)
+	require.False(t, isDuplicate)
+	require.Len(t, d.entries, 1)
+	_, isDuplicate = d.start(key)
+	require.False(t, isDuplicate)
+
+	// Pruning on a new head slot drops the entries of past slots
+	require.Equal(t, 1, d.prune(11))
+	require.Len(t, d.entries, 1)
+	require.Equal(t, 0, d.prune(11))
+}
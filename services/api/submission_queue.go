@@ -0,0 +1,182 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	submissionQueueDropNewest = "drop_newest" // drop the new submission if the queue is full
+	submissionQueueDropOldest = "drop_oldest" // drop the oldest queued submission to make room for the new one
+	submissionQueueBlock      = "block"       // wait for room in the queue, and drop the new submission after a timeout
+)
+
+var (
+	ErrInvalidSubmissionQueueDropPolicy = errors.New("invalid submission queue drop policy")
+
+	// size of the queue of block submissions to write to the database (0 to write them synchronously)
+	submissionQueueSize = cli.GetEnvInt("SUBMISSION_QUEUE_SIZE", 10_000)
+
+	// number of goroutines writing queued block submissions to the database
+	numSubmissionDBProcessors = cli.GetEnvInt("NUM_SUBMISSION_DB_PROCESSORS", 4)
+
+	// what to do if the submission queue is full
+	submissionQueueDropPolicy = common.GetEnv("SUBMISSION_QUEUE_DROP_POLICY", submissionQueueDropNewest)
+
+	// with the block policy, how long to wait for room in the submission queue
+	submissionQueueBlockTimeout = time.Duration(cli.GetEnvInt("SUBMISSION_QUEUE_BLOCK_TIMEOUT_MS", 500)) * time.Millisecond
+)
+
+// submissionDBEntry is a block submission together with its simulation result, to be written to the database
+type submissionDBEntry struct {
+	log        *logrus.Entry
+	payload    *common.VersionedSubmitBlockRequest
+	simResult  *blockSimResult
+	receivedAt time.Time
+	decodedAt  time.Time
+	eligibleAt time.Time
+	profile    common.Profile
+	isTopBid   bool // top bids are never dropped, as their payload is the getPayload fallback
+	isTrusted  bool
+}
+
+// SubmissionQueueStatus is the state of the submission queue, reported by /healthz and /readyz
+type SubmissionQueueStatus struct {
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+func checkSubmissionQueueDropPolicy(policy string) error {
+	switch policy {
+	case submissionQueueDropNewest, submissionQueueDropOldest, submissionQueueBlock:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidSubmissionQueueDropPolicy, policy)
+	}
+}
+
+func (api *RelayAPI) isSubmissionQueueEnabled() bool {
+	return api.submissionDBC != nil
+}
+
+// getSubmissionQueueStatus returns the current queue depth, or nil if the queue is disabled
+func (api *RelayAPI) getSubmissionQueueStatus() *SubmissionQueueStatus {
+	if !api.isSubmissionQueueEnabled() {
+		return nil
+	}
+	return &SubmissionQueueStatus{
+		Depth:    len(api.submissionDBC),
+		Capacity: cap(api.submissionDBC),
+		Dropped:  api.submissionQueueDropped.Load(),
+	}
+}
+
+// waitForSimResult waits for the simulation result of a block submission, which is sent once the handler or the
+// optimistic processing is done with it
+func waitForSimResult(log *logrus.Entry, simResultC <-chan *blockSimResult) *blockSimResult {
+	select {
+	case simResult := <-simResultC:
+		return simResult
+	case <-time.After(10 * time.Second):
+		log.Warn("timed out waiting for simulation result")
+		return &blockSimResult{false, false, nil, nil}
+	}
+}
+
+// saveSubmission writes the block submission to the database once its simulation result is available, through the
+// submission queue or synchronously if the queue is disabled
+func (api *RelayAPI) saveSubmission(entry *submissionDBEntry, simResultC <-chan *blockSimResult) {
+	if !api.isSubmissionQueueEnabled() {
+		entry.simResult = waitForSimResult(entry.log, simResultC)
+		api.saveSubmissionToDB(entry)
+		return
+	}
+
+	select {
+	case entry.simResult = <-simResultC:
+		api.queueSubmission(entry)
+	default:
+		// the block is still being simulated optimistically, wait for the result in the background
+		api.submissionQueuePendingWG.Add(1)
+		go func() {
+			defer api.submissionQueuePendingWG.Done()
+			entry.simResult = waitForSimResult(entry.log, simResultC)
+			api.queueSubmission(entry)
+		}()
+	}
+}
+
+// queueSubmission adds the submission to the queue, applying the drop policy if the queue is full
+func (api *RelayAPI) queueSubmission(entry *submissionDBEntry) {
+	select {
+	case api.submissionDBC <- entry:
+		return
+	default:
+	}
+
+	switch submissionQueueDropPolicy {
+	case submissionQueueDropOldest:
+		select {
+		case oldest := <-api.submissionDBC:
+			api.dropSubmission(oldest)
+		default:
+		}
+		select {
+		case api.submissionDBC <- entry:
+			return
+		default:
+		}
+	case submissionQueueBlock:
+		timer := time.NewTimer(submissionQueueBlockTimeout)
+		defer timer.Stop()
+		select {
+		case api.submissionDBC <- entry:
+			return
+		case <-timer.C:
+		}
+	}
+	api.dropSubmission(entry)
+}
+
+// dropSubmission drops a submission which doesn't fit into the queue, unless it is a top bid which is then saved
+// synchronously
+func (api *RelayAPI) dropSubmission(entry *submissionDBEntry) {
+	log := entry.log.WithField("submissionQueueDepth", len(api.submissionDBC))
+	if entry.isTopBid {
+		log.Warn("submission queue full, saving top bid synchronously")
+		api.saveSubmissionToDB(entry)
+		return
+	}
+	api.submissionQueueDropped.Inc()
+	log.Error("submission queue full, dropping block submission")
+}
+
+func (api *RelayAPI) startSubmissionDBProcessor() {
+	defer api.submissionDBProcessorsWG.Done()
+	for entry := range api.submissionDBC {
+		api.saveSubmissionToDB(entry)
+	}
+}
+
+func (api *RelayAPI) saveSubmissionToDB(entry *submissionDBEntry) {
+	// Payloads that became the top bid are always saved, as fallback for getPayload if Redis and Memcached lose them
+	savePayloadToDatabase := !api.ffDisablePayloadDBStorage || entry.isTopBid
+	simResult := entry.simResult
+
+	submissionEntry, err := api.db.SaveBuilderBlockSubmission(entry.payload, simResult.requestErr, simResult.validationErr, entry.receivedAt, entry.decodedAt, entry.eligibleAt, simResult.wasSimulated, savePayloadToDatabase, entry.profile, simResult.optimisticSubmission, entry.isTrusted)
+	if err != nil {
+		entry.log.WithError(err).WithField("payload", entry.payload).Error("saving builder block submission to database failed")
+		return
+	}
+
+	err = api.db.UpsertBlockBuilderEntryAfterSubmission(submissionEntry, simResult.validationErr != nil)
+	if err != nil {
+		entry.log.WithError(err).Error("failed to upsert block-builder-entry")
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmissionQueueDropPolicies(t *testing.T) {
+	newEntry := func(isTopBid bool) *submissionDBEntry {
+		return &submissionDBEntry{log: common.TestLog, simResult: &blockSimResult{}, isTopBid: isTopBid}
+	}
+
+	defer func(policy string) { submissionQueueDropPolicy = policy }(submissionQueueDropPolicy)
+
+	t.Run("drop newest", func(t *testing.T) {
+		submissionQueueDropPolicy = submissionQueueDropNewest
+		api := &RelayAPI{log: common.TestLog, db: database.MockDB{}, submissionDBC: make(chan *submissionDBEntry, 1)}
+		first := newEntry(false)
+		api.queueSubmission(first)
+		api.queueSubmission(newEntry(false))
+		require.Equal(t, &SubmissionQueueStatus{Depth: 1, Capacity: 1, Dropped: 1}, api.getSubmissionQueueStatus())
+		require.Equal(t, first, <-api.submissionDBC)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		submissionQueueDropPolicy = submissionQueueDropOldest
+		api := &RelayAPI{log: common.TestLog, db: database.MockDB{}, submissionDBC: make(chan *submissionDBEntry, 1)}
+		api.queueSubmission(newEntry(false))
+		second := newEntry(false)
+		api.queueSubmission(second)
+		require.Equal(t, uint64(1), api.submissionQueueDropped.Load())
+		require.Equal(t, second, <-api.submissionDBC)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		submissionQueueDropPolicy = submissionQueueBlock
+		api := &RelayAPI{log: common.TestLog, db: database.MockDB{}, submissionDBC: make(chan *submissionDBEntry, 1)}
+		api.queueSubmission(newEntry(false))
+		go func() { <-api.submissionDBC }()
+		api.queueSubmission(newEntry(false))
+		require.Equal(t, uint64(0), api.submissionQueueDropped.Load())
+	})
+
+	t.Run("top bids are not dropped", func(t *testing.T) {
+		submissionQueueDropPolicy = submissionQueueDropNewest
+		api := &RelayAPI{log: common.TestLog, db: database.MockDB{}, submissionDBC: make(chan *submissionDBEntry, 1)}
+		api.queueSubmission(newEntry(false))
+		api.queueSubmission(newEntry(true))
+		require.Equal(t, uint64(0), api.submissionQueueDropped.Load())
+	})
+
+	require.ErrorIs(t, checkSubmissionQueueDropPolicy("foo"), ErrInvalidSubmissionQueueDropPolicy)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HeaderServerTiming breaks down the processing time of a block submission (https://www.w3.org/TR/server-timing/),
+// so that builders can distinguish the network latency from the time spent in the relay
+const HeaderServerTiming = "Server-Timing"
+
+// submissionTimings records the time spent in the stages of a block submission. Stages which weren't reached are zero
+// and left out of the Server-Timing header.
+type submissionTimings struct {
+	receivedAt time.Time
+
+	decode     time.Duration // reading and decoding the request body
+	sigVerify  time.Duration
+	floorCheck time.Duration
+	sim        time.Duration
+	redis      time.Duration
+
+	simBudget simBudget // timeout of the simulation, not a stage
+}
+
+// serverTiming returns the value of the Server-Timing header, in milliseconds, i.e.
+// "decode;dur=1.20, sigverify;dur=0.80, total;dur=2.50". The simulation timeout is added with the reason it was chosen,
+// i.e. `sim_budget;dur=1500.00;desc="top_bid"`
+func (t *submissionTimings) serverTiming() string {
+	metrics := make([]string, 0, 7)
+	add := func(name string, d time.Duration) {
+		if d > 0 {
+			metrics = append(metrics, fmt.Sprintf("%s;dur=%.2f", name, float64(d.Microseconds())/1000))
+		}
+	}
+	add("decode", t.decode)
+	add("sigverify", t.sigVerify)
+	add("floor_check", t.floorCheck)
+	add("sim", t.sim)
+	add("redis", t.redis)
+	if t.simBudget.timeout > 0 {
+		metrics = append(metrics, fmt.Sprintf("sim_budget;dur=%.2f;desc=%q", float64(t.simBudget.timeout.Microseconds())/1000, t.simBudget.reason))
+	}
+	if !t.receivedAt.IsZero() {
+		add("total", time.Since(t.receivedAt))
+	}
+	return strings.Join(metrics, ", ")
+}
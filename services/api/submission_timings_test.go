@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmissionTimingsServerTiming(t *testing.T) {
+	timings := submissionTimings{}
+	require.Equal(t, "", timings.serverTiming())
+
+	timings.decode = 1200 * time.Microsecond
+	timings.sim = 25 * time.Millisecond
+	require.Equal(t, "decode;dur=1.20, sim;dur=25.00", timings.serverTiming())
+
+	timings.simBudget = simBudget{timeout: 1500 * time.Millisecond, reason: simBudgetReasonTopBid}
+	require.Equal(t, `decode;dur=1.20, sim;dur=25.00, sim_budget;dur=1500.00;desc="top_bid"`, timings.serverTiming())
+	timings.simBudget = simBudget{}
+
+	timings.receivedAt = time.Now().Add(-time.Second)
+	require.Contains(t, timings.serverTiming(), "decode;dur=1.20, sim;dur=25.00, total;dur=")
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NYTimes/gziphandler"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-utils/httplogger"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/gorilla/mux"
+)
+
+var (
+	ErrInvalidClientCA           = errors.New("no valid certificates in client CA file")
+	ErrTrustedIdentityMissingKey = errors.New("trusted builder identity needs a cert_common_name or ips")
+	ErrUnknownTrustedBuilder     = errors.New("unknown trusted builder identity")
+	ErrTrustedBuilderNotAllowed  = errors.New("builder pubkey not allowed for trusted builder identity")
+)
+
+type trustedBuilderContextKey struct{}
+
+// TrustedBuilderIdentity maps a client certificate common name and/or IP addresses (or CIDR ranges) to the builder
+// pubkeys which may be submitted by that identity over the trusted builder listener.
+type TrustedBuilderIdentity struct {
+	Name           string   `json:"name"`
+	CertCommonName string   `json:"cert_common_name"`
+	IPs            []string `json:"ips"`
+	BuilderPubkeys []string `json:"builder_pubkeys"`
+}
+
+// TrustedBuilderListenerOpts contains the options for the optional second listener for trusted builder submissions
+type TrustedBuilderListenerOpts struct {
+	ListenAddr string
+
+	// TLS server certificate. Without it, the listener serves plain HTTP and only IP allowlisting is possible.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// If set, clients can authenticate with a certificate signed by this CA
+	ClientCAFile string
+
+	Identities []TrustedBuilderIdentity
+}
+
+// LoadTrustedBuilderIdentities loads the trusted builder identities from a JSON file
+func LoadTrustedBuilderIdentities(fn string) (identities []TrustedBuilderIdentity, err error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &identities)
+	return identities, err
+}
+
+// trustedBuilder is an authenticated identity of the trusted builder listener
+type trustedBuilder struct {
+	name           string
+	viaCertificate bool
+	builderPubkeys map[phase0.BLSPubKey]bool
+}
+
+func (b *trustedBuilder) allowsBuilder(pubkey phase0.BLSPubKey) bool {
+	return b.builderPubkeys[pubkey]
+}
+
+type trustedBuilderIPNet struct {
+	ipNet   *net.IPNet
+	builder *trustedBuilder
+}
+
+// trustedBuilders authenticates requests of the trusted builder listener
+type trustedBuilders struct {
+	byCertCommonName map[string]*trustedBuilder
+	byIPNet          []trustedBuilderIPNet
+}
+
+func newTrustedBuilders(identities []TrustedBuilderIdentity) (*trustedBuilders, error) {
+	ret := &trustedBuilders{
+		byCertCommonName: make(map[string]*trustedBuilder),
+	}
+
+	for _, identity := range identities {
+		if identity.CertCommonName == "" && len(identity.IPs) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrTrustedIdentityMissingKey, identity.Name)
+		}
+
+		builderPubkeys := make(map[phase0.BLSPubKey]bool)
+		for _, pubkeyHex := range identity.BuilderPubkeys {
+			pubkey, err := common.StrToPhase0Pubkey(pubkeyHex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid builder pubkey %s for trusted builder %s: %w", pubkeyHex, identity.Name, err)
+			}
+			builderPubkeys[pubkey] = true
+		}
+
+		if identity.CertCommonName != "" {
+			ret.byCertCommonName[identity.CertCommonName] = &trustedBuilder{
+				name:           identity.Name,
+				viaCertificate: true,
+				builderPubkeys: builderPubkeys,
+			}
+		}
+
+		ipBuilder := &trustedBuilder{
+			name:           identity.Name,
+			builderPubkeys: builderPubkeys,
+		}
+		for _, ip := range identity.IPs {
+			if !strings.Contains(ip, "/") {
+				if strings.Contains(ip, ":") {
+					ip += "/128"
+				} else {
+					ip += "/32"
+				}
+			}
+			_, ipNet, err := net.ParseCIDR(ip)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ip %s for trusted builder %s: %w", ip, identity.Name, err)
+			}
+			ret.byIPNet = append(ret.byIPNet, trustedBuilderIPNet{ipNet: ipNet, builder: ipBuilder})
+		}
+	}
+
+	return ret, nil
+}
+
+// authenticate returns the trusted builder of the request, by verified client certificate or else by remote IP
+func (t *trustedBuilders) authenticate(req *http.Request) *trustedBuilder {
+	if isAuthenticatedMTLSRequest(req) {
+		if builder, ok := t.byCertCommonName[req.TLS.VerifiedChains[0][0].Subject.CommonName]; ok {
+			return builder
+		}
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+	for _, entry := range t.byIPNet {
+		if entry.ipNet.Contains(ip) {
+			return entry.builder
+		}
+	}
+	return nil
+}
+
+// getTrustedBuilder returns the trusted builder of a request on the trusted builder listener, or nil for all other requests
+func getTrustedBuilder(req *http.Request) *trustedBuilder {
+	builder, _ := req.Context().Value(trustedBuilderContextKey{}).(*trustedBuilder)
+	return builder
+}
+
+func (api *RelayAPI) trustedBuilderMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		builder := api.trustedBuilders.authenticate(req)
+		if builder == nil {
+			api.RespondError(w, http.StatusForbidden, ErrUnknownTrustedBuilder.Error())
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), trustedBuilderContextKey{}, builder)))
+	})
+}
+
+func (api *RelayAPI) getTrustedBuilderRouter() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc(pathBuilderGetValidators, api.handleBuilderGetValidators).Methods(http.MethodGet)
+	r.HandleFunc(pathSubmitNewBlock, api.handleSubmitNewBlock).Methods(http.MethodPost)
+
+	loggedRouter := httplogger.LoggingMiddlewareLogrus(api.log.WithField("listener", "trusted"), api.trustedBuilderMiddleware(r))
+	withGz := gziphandler.GzipHandler(loggedRouter)
+	return requestIDMiddleware(api.requestLimitsMiddleware(withGz))
+}
+
+// startTrustedBuilderServer starts the listener for trusted builder submissions (blocking)
+func (api *RelayAPI) startTrustedBuilderServer() error {
+	opts := api.opts.TrustedBuilderListener
+	api.trustedSrv = &http.Server{
+		Addr:    opts.ListenAddr,
+		Handler: api.getTrustedBuilderRouter(),
+
+		ReadTimeout:       time.Duration(apiReadTimeoutMs) * time.Millisecond,
+		ReadHeaderTimeout: time.Duration(apiReadHeaderTimeoutMs) * time.Millisecond,
+		WriteTimeout:      time.Duration(apiWriteTimeoutMs) * time.Millisecond,
+		IdleTimeout:       time.Duration(apiIdleTimeoutMs) * time.Millisecond,
+		MaxHeaderBytes:    apiMaxHeaderBytes,
+	}
+
+	if opts.TLSCertFile == "" {
+		api.log.Infof("trusted builder listener starting on %s (without TLS, IP allowlist only)", opts.ListenAddr)
+		return api.trustedSrv.ListenAndServe()
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if opts.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return ErrInvalidClientCA
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven // IP allowlisted clients may connect without certificate
+	}
+	api.trustedSrv.TLSConfig = tlsConfig
+
+	api.log.Infof("trusted builder listener starting on %s (TLS)", opts.ListenAddr)
+	return api.trustedSrv.ListenAndServeTLS(opts.TLSCertFile, opts.TLSKeyFile)
+}
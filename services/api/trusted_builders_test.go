@@ -0,0 +1,55 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedBuildersAuthenticate(t *testing.T) {
+	builderPubkeyHex := "0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4"
+	builderPubkey, err := common.StrToPhase0Pubkey(builderPubkeyHex)
+	require.NoError(t, err)
+
+	trusted, err := newTrustedBuilders([]TrustedBuilderIdentity{
+		{Name: "builder1", CertCommonName: "builder1.example", BuilderPubkeys: []string{builderPubkeyHex}},
+		{Name: "builder2", IPs: []string{"10.0.0.1", "192.168.1.0/24"}},
+	})
+	require.NoError(t, err)
+
+	// Unknown IP
+	req := httptest.NewRequest(http.MethodPost, pathSubmitNewBlock, nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	require.Nil(t, trusted.authenticate(req))
+
+	// Allowlisted IPs
+	req.RemoteAddr = "10.0.0.1:1234"
+	builder := trusted.authenticate(req)
+	require.NotNil(t, builder)
+	require.Equal(t, "builder2", builder.name)
+	require.False(t, builder.viaCertificate)
+	require.False(t, builder.allowsBuilder(builderPubkey))
+
+	req.RemoteAddr = "192.168.1.100:1234"
+	require.NotNil(t, trusted.authenticate(req))
+
+	// Verified client certificate
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "builder1.example"}}
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	builder = trusted.authenticate(req)
+	require.NotNil(t, builder)
+	require.Equal(t, "builder1", builder.name)
+	require.True(t, builder.viaCertificate)
+	require.True(t, builder.allowsBuilder(builderPubkey))
+
+	// Identities need a certificate common name or IPs
+	_, err = newTrustedBuilders([]TrustedBuilderIdentity{{Name: "builder3"}})
+	require.ErrorIs(t, err, ErrTrustedIdentityMissingKey)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidTunables = errors.New("invalid tunables")
+
+// Tunables are the settings of the API which are safe to change while it is running, i.e. on SIGHUP. The defaults come
+// from the environment variables of the same (upper case) name, and the --blocklist flag.
+type Tunables struct {
+	GetHeaderRequestMinMs     int `yaml:"getheader_request_min_ms"`     // 0 means no minimum
+	GetHeaderRequestCutoffMs  int `yaml:"getheader_request_cutoff_ms"`  // 0 means no cutoff
+	GetPayloadRequestCutoffMs int `yaml:"getpayload_request_cutoff_ms"` // 0 means no cutoff
+	GetPayloadResponseDelayMs int `yaml:"getpayload_response_delay_ms"`
+
+	// getHeader responses are held until this many ms into the slot (plus a random jitter), and then serve the best
+	// bid at that moment. 0 disables the delay.
+	GetHeaderResponseTargetMs int `yaml:"getheader_response_target_ms"`
+	GetHeaderResponseJitterMs int `yaml:"getheader_response_jitter_ms"`
+
+	BlockSimMaxConcurrent int `yaml:"blocksim_max_concurrent"` // 0 for no maximum
+
+	// Address blocklist (file or URL), only used in filtered relay mode
+	Blocklist string `yaml:"blocklist"`
+}
+
+// DefaultTunables returns the tunables from the environment variables, without the blocklist
+func DefaultTunables() Tunables {
+	return Tunables{
+		GetHeaderRequestMinMs:     getHeaderRequestMinMs,
+		GetHeaderRequestCutoffMs:  getHeaderRequestCutoffMs,
+		GetPayloadRequestCutoffMs: getPayloadRequestCutoffMs,
+		GetPayloadResponseDelayMs: getPayloadResponseDelayMs,
+		GetHeaderResponseTargetMs: getHeaderResponseTargetMs,
+		GetHeaderResponseJitterMs: getHeaderResponseJitterMs,
+		BlockSimMaxConcurrent:     int(maxConcurrentBlocks),
+	}
+}
+
+func (t *Tunables) Validate() error {
+	if t.GetHeaderRequestCutoffMs < 0 || t.GetPayloadRequestCutoffMs < 0 {
+		return fmt.Errorf("%w: request cutoffs must not be negative", ErrInvalidTunables)
+	}
+	if t.GetHeaderRequestMinMs > 0 && t.GetHeaderRequestCutoffMs > 0 && t.GetHeaderRequestMinMs >= t.GetHeaderRequestCutoffMs {
+		return fmt.Errorf("%w: getHeader minimum (%d ms) must be before the cutoff (%d ms)", ErrInvalidTunables, t.GetHeaderRequestMinMs, t.GetHeaderRequestCutoffMs)
+	}
+	if t.GetPayloadResponseDelayMs < 0 {
+		return fmt.Errorf("%w: getPayload response delay must not be negative", ErrInvalidTunables)
+	}
+	if t.GetHeaderResponseTargetMs < 0 || t.GetHeaderResponseJitterMs < 0 {
+		return fmt.Errorf("%w: getHeader response target and jitter must not be negative", ErrInvalidTunables)
+	}
+	if t.GetHeaderResponseTargetMs > 0 && t.GetHeaderRequestCutoffMs > 0 && t.GetHeaderResponseTargetMs+t.GetHeaderResponseJitterMs >= t.GetHeaderRequestCutoffMs {
+		return fmt.Errorf("%w: getHeader response target (%d ms plus %d ms jitter) must be before the cutoff (%d ms)", ErrInvalidTunables, t.GetHeaderResponseTargetMs, t.GetHeaderResponseJitterMs, t.GetHeaderRequestCutoffMs)
+	}
+	if t.BlockSimMaxConcurrent < 0 {
+		return fmt.Errorf("%w: maximum concurrent block simulations must not be negative", ErrInvalidTunables)
+	}
+	return nil
+}
+
+// getTunables returns the current tunables
+func (api *RelayAPI) getTunables() *Tunables {
+	return api.tunables.Load()
+}
+
+// UpdateTunables validates and applies new tunables. If the blocklist source changed, the new blocklist is loaded
+// first, and the previous tunables are kept if that fails.
+func (api *RelayAPI) UpdateTunables(t Tunables) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	previous := api.getTunables()
+	if api.blocklist != nil && t.Blocklist != previous.Blocklist {
+		if t.Blocklist == "" {
+			return fmt.Errorf("%w: %w", ErrInvalidTunables, ErrMissingBlocklist)
+		}
+		if err := api.blocklist.SetSource(t.Blocklist); err != nil {
+			return err
+		}
+	}
+
+	api.blockSimRateLimiter.SetMaxConcurrent(int64(t.BlockSimMaxConcurrent))
+	api.tunables.Store(&t)
+	api.log.WithField("tunables", fmt.Sprintf("%+v", t)).Info("tunables updated")
+	return nil
+}
@@ -16,8 +16,10 @@ var (
 )
 
 type HTTPErrorResp struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code      int       `json:"code"`
+	ErrorCode ErrorCode `json:"error_code"`
+	Message   string    `json:"message"`
+	RequestID string    `json:"request_id,omitempty"`
 }
 
 type HTTPMessageResp struct {
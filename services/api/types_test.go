@@ -5,6 +5,7 @@ import (
 
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	builderSpec "github.com/attestantio/go-builder-client/spec"
 	"github.com/attestantio/go-eth2-client/spec"
@@ -22,7 +23,7 @@ import (
 )
 
 func TestBuilderBlockRequestToSignedBuilderBid(t *testing.T) {
-	builderPk, err := utils.HexToPubkey("0xf9716c94aab536227804e859d15207aa7eaaacd839f39dcbdb5adc942842a8d2fb730f9f49fc719fdb86f1873e0ed1c2")
+	builderPk, err := utils.HexToPubkey("0x983649810ed1691509d42918af2918a7042a28537475125a7c4d85a2f567efdba757410eb6546d724506ce42bdda9f1b")
 	require.NoError(t, err)
 
 	builderSk, err := utils.HexToSignature("0x8209b5391cd69f392b1f02dbc03bab61f574bb6bb54bf87b59e2a85bdc0756f7db6a71ce1b41b727a1f46ccc77b213bf0df1426177b5b29926b39956114421eaa36ec4602969f6f6370a44de44a6bce6dae2136e5fb594cce2a476354264d1ea")
@@ -117,6 +118,26 @@ func TestBuilderBlockRequestToSignedBuilderBid(t *testing.T) {
 		},
 	}
 
+	electraPayload := *cases[1].reqPayload.Deneb.ExecutionPayload
+	cases = append(cases, struct {
+		name       string
+		reqPayload *common.VersionedSubmitBlockRequest
+	}{
+		name: "Electra",
+		reqPayload: &common.VersionedSubmitBlockRequest{
+			VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{
+				Version: spec.DataVersionElectra,
+				Electra: &builderApiElectra.SubmitBlockRequest{
+					ExecutionPayload:  &electraPayload,
+					BlobsBundle:       cases[1].reqPayload.Deneb.BlobsBundle,
+					ExecutionRequests: common.TestExecutionRequests(),
+					Message:           cases[1].reqPayload.Deneb.Message,
+					Signature:         builderSk,
+				},
+			},
+		},
+	})
+
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			sk, _, err := bls.GenerateNewKeypair()
@@ -143,6 +164,13 @@ func TestBuilderBlockRequestToSignedBuilderBid(t *testing.T) {
 
 			require.Equal(t, 0, bidValue.Cmp(respValue))
 			require.Equal(t, respHash, bidHash)
+
+			if tc.reqPayload.Version == spec.DataVersionElectra {
+				require.Equal(t, tc.reqPayload.Electra.ExecutionRequests, signedBuilderBid.Electra.Message.ExecutionRequests)
+				ok, err := ssz.VerifySignature(signedBuilderBid.Electra.Message, ssz.DomainBuilder, publicKey[:], signedBuilderBid.Electra.Signature[:])
+				require.NoError(t, err)
+				require.True(t, ok)
+			}
 		})
 	}
 }
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/sirupsen/logrus"
+)
+
+// bidOriginLocal is the origin of bids which were submitted to this relay
+const bidOriginLocal = "local"
+
+var (
+	ErrInvalidUpstreamRelay = errors.New("invalid upstream relay, must be https://0x<relay pubkey>@<host>")
+	ErrUnknownUpstreamRelay = errors.New("unknown upstream relay")
+	ErrInvalidUpstreamBid   = errors.New("invalid upstream bid")
+
+	// timeouts of the getHeader and getPayload requests to upstream relays
+	upstreamGetHeaderTimeout  = time.Duration(cli.GetEnvInt("UPSTREAM_GETHEADER_TIMEOUT_MS", 950)) * time.Millisecond
+	upstreamGetPayloadTimeout = time.Duration(cli.GetEnvInt("UPSTREAM_GETPAYLOAD_TIMEOUT_MS", 4000)) * time.Millisecond
+)
+
+// upstreamRelay is a relay whose bids are merged with the local bids in getHeader (aggregation mode)
+type upstreamRelay struct {
+	url    *url.URL // without the pubkey
+	pubkey phase0.BLSPubKey
+}
+
+// newUpstreamRelay parses an upstream relay in the format of mev-boost: https://0x<relay pubkey>@<host>
+func newUpstreamRelay(relayURL string) (*upstreamRelay, error) {
+	u, err := url.Parse(relayURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUpstreamRelay, err)
+	}
+	if u.Scheme == "" || u.Host == "" || u.User == nil {
+		return nil, ErrInvalidUpstreamRelay
+	}
+	pubkey, err := common.StrToPhase0Pubkey(u.User.Username())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUpstreamRelay, err)
+	}
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return &upstreamRelay{url: u, pubkey: pubkey}, nil
+}
+
+// String returns the URL of the upstream relay, which is also the origin of its bids
+func (r *upstreamRelay) String() string {
+	return r.url.String()
+}
+
+// upstreamBid is a verified bid of an upstream relay
+type upstreamBid struct {
+	relay *upstreamRelay
+	bid   *builderSpec.VersionedSignedBuilderBid
+	value *uint256.Int
+}
+
+// getUpstreamHeader requests the bid of an upstream relay, and verifies that it's signed by the relay and built on
+// the requested parent for the fork of the slot. Returns nil if the relay has no bid.
+func (api *RelayAPI) getUpstreamHeader(ctx context.Context, relay *upstreamRelay, slot uint64, parentHash, proposerPubkey string) (*upstreamBid, error) {
+	path := fmt.Sprintf("%s/eth/v1/builder/header/%d/%s/%s", relay.url.String(), slot, parentHash, proposerPubkey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := api.upstreamClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status code %d", ErrInvalidUpstreamBid, resp.StatusCode)
+	}
+
+	bid := new(builderSpec.VersionedSignedBuilderBid)
+	if err := json.NewDecoder(resp.Body).Decode(bid); err != nil {
+		return nil, err
+	}
+	if bid.IsEmpty() {
+		return nil, nil
+	}
+
+	if slotFork := api.forkSchedule.ForkAtSlot(slot); bid.Version != slotFork {
+		return nil, fmt.Errorf("%w: version %s, expected %s", ErrInvalidUpstreamBid, bid.Version.String(), slotFork.String())
+	}
+	bidParentHash, err := bid.ParentHash()
+	if err != nil {
+		return nil, err
+	} else if !strings.EqualFold(bidParentHash.String(), parentHash) {
+		return nil, fmt.Errorf("%w: parent hash %s", ErrInvalidUpstreamBid, bidParentHash.String())
+	}
+	bidPubkey, err := bid.Builder()
+	if err != nil {
+		return nil, err
+	} else if bidPubkey != relay.pubkey {
+		return nil, fmt.Errorf("%w: pubkey %s", ErrInvalidUpstreamBid, bidPubkey.String())
+	}
+	root, err := bid.MessageHashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := bid.Signature()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := ssz.VerifySignatureRoot(root, api.opts.EthNetDetails.DomainBuilder, relay.pubkey[:], signature[:])
+	if err != nil || !ok {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidUpstreamBid)
+	}
+
+	value, err := bid.Value()
+	if err != nil {
+		return nil, err
+	}
+	return &upstreamBid{relay: relay, bid: bid, value: value}, nil
+}
+
+// getBestUpstreamBid requests the bids of all upstream relays in parallel, and returns the highest one (nil if none of
+// the relays has a valid bid)
+func (api *RelayAPI) getBestUpstreamBid(log *logrus.Entry, slot uint64, parentHash, proposerPubkey string) *upstreamBid {
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamGetHeaderTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	bids := make([]*upstreamBid, len(api.upstreamRelays))
+	for i, relay := range api.upstreamRelays {
+		wg.Add(1)
+		go func(i int, relay *upstreamRelay) {
+			defer wg.Done()
+			bid, err := api.getUpstreamHeader(ctx, relay, slot, parentHash, proposerPubkey)
+			if err != nil {
+				log.WithError(err).WithField("upstreamRelay", relay.String()).Warn("failed to get upstream bid")
+				return
+			}
+			bids[i] = bid
+		}(i, relay)
+	}
+	wg.Wait()
+
+	var best *upstreamBid
+	for _, bid := range bids {
+		if bid != nil && (best == nil || bid.value.Cmp(best.value) > 0) {
+			best = bid
+		}
+	}
+	return best
+}
+
+// mergeUpstreamBids returns the best of the local bid and the bids of the upstream relays, together with its origin.
+// An upstream bid is signed again with the key of this relay, and recorded to proxy getPayload for it.
+func (api *RelayAPI) mergeUpstreamBids(log *logrus.Entry, slot uint64, parentHash, proposerPubkey string, localBid *builderSpec.VersionedSignedBuilderBid) (*builderSpec.VersionedSignedBuilderBid, string) {
+	best := api.getBestUpstreamBid(log, slot, parentHash, proposerPubkey)
+	if best == nil {
+		return localBid, bidOriginLocal
+	}
+	if localBid != nil && !localBid.IsEmpty() {
+		localValue, err := localBid.Value()
+		if err == nil && localValue.Cmp(best.value) >= 0 {
+			return localBid, bidOriginLocal
+		}
+	}
+
+	log = log.WithField("upstreamRelay", best.relay.String())
+	if err := api.resignUpstreamBid(best.bid); err != nil {
+		log.WithError(err).Error("failed to sign upstream bid")
+		return localBid, bidOriginLocal
+	}
+	blockHash, err := best.bid.BlockHash()
+	if err != nil {
+		log.WithError(err).Error("failed to get upstream bid block hash")
+		return localBid, bidOriginLocal
+	}
+	if err := api.redis.SetUpstreamBid(slot, blockHash.String(), best.relay.String()); err != nil {
+		log.WithError(err).Error("failed to record upstream bid")
+		return localBid, bidOriginLocal
+	}
+	return best.bid, best.relay.String()
+}
+
+// resignUpstreamBid replaces the pubkey and signature of an upstream bid with the ones of this relay, as the proposer
+// verifies bids against the pubkey of the relay it requested them from
+func (api *RelayAPI) resignUpstreamBid(bid *builderSpec.VersionedSignedBuilderBid) error {
+	switch bid.Version { //nolint:exhaustive
+	case spec.DataVersionCapella:
+		bid.Capella.Message.Pubkey = *api.publicKey
+		signature, err := ssz.SignMessage(bid.Capella.Message, api.opts.EthNetDetails.DomainBuilder, api.blsSk)
+		if err != nil {
+			return err
+		}
+		bid.Capella.Signature = signature
+	case spec.DataVersionDeneb:
+		bid.Deneb.Message.Pubkey = *api.publicKey
+		signature, err := ssz.SignMessage(bid.Deneb.Message, api.opts.EthNetDetails.DomainBuilder, api.blsSk)
+		if err != nil {
+			return err
+		}
+		bid.Deneb.Signature = signature
+	case spec.DataVersionElectra:
+		bid.Electra.Message.Pubkey = *api.publicKey
+		signature, err := ssz.SignMessage(bid.Electra.Message, api.opts.EthNetDetails.DomainBuilder, api.blsSk)
+		if err != nil {
+			return err
+		}
+		bid.Electra.Signature = signature
+	default:
+		return common.ErrInvalidVersion
+	}
+	return nil
+}
+
+// getUpstreamRelay returns the configured upstream relay with the given URL
+func (api *RelayAPI) getUpstreamRelay(relayURL string) (*upstreamRelay, error) {
+	for _, relay := range api.upstreamRelays {
+		if relay.String() == relayURL {
+			return relay, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownUpstreamRelay, relayURL)
+}
+
+// proxyUpstreamGetPayload forwards a getPayload request to the upstream relay of the bid, and the response of the
+// upstream relay back to the proposer. The upstream relay publishes the block.
+func (api *RelayAPI) proxyUpstreamGetPayload(w http.ResponseWriter, log *logrus.Entry, relayURL string, body []byte) {
+	log = log.WithField("upstreamRelay", relayURL)
+	relay, err := api.getUpstreamRelay(relayURL)
+	if err != nil {
+		log.WithError(err).Error("getPayload for a bid of an unknown upstream relay")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadNotFound, "unknown upstream relay of the bid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamGetPayloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, relay.url.String()+pathGetPayload, bytes.NewReader(body))
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := api.upstreamClient.Do(req)
+	if err != nil {
+		log.WithError(err).Error("upstream getPayload request failed")
+		api.RespondErrorCode(w, http.StatusBadGateway, ErrorCodeInternalError, "upstream relay getPayload request failed")
+		return
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.WithError(err).Error("failed to read upstream getPayload response")
+		api.RespondErrorCode(w, http.StatusBadGateway, ErrorCodeInternalError, "failed to read upstream relay getPayload response")
+		return
+	}
+
+	log.WithField("upstreamStatusCode", resp.StatusCode).Info("proxied getPayload to upstream relay")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(respBody) //nolint:errcheck
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUpstreamRelay(t *testing.T) {
+	pubkey := "0xa1885d66bef164889a2e35845c3b626545d7b0e513efe335e97c3a45e534013fa3bc38c3b7e6143695aecc4872ac52c4"
+	relay, err := newUpstreamRelay(fmt.Sprintf("https://%s@relay.example.com/", pubkey))
+	require.NoError(t, err)
+	require.Equal(t, "https://relay.example.com", relay.String())
+	require.Equal(t, pubkey, relay.pubkey.String())
+
+	_, err = newUpstreamRelay("https://relay.example.com")
+	require.ErrorIs(t, err, ErrInvalidUpstreamRelay)
+	_, err = newUpstreamRelay("https://0x01@relay.example.com")
+	require.ErrorIs(t, err, ErrInvalidUpstreamRelay)
+}
+
+func TestUpstreamRelayAggregation(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{
+			GenesisTime: uint64(time.Now().UTC().Unix()),
+		},
+	}
+	backend.relay.forkSchedule = common.ForkVersionSchedule{CapellaEpoch: 0, DenebEpoch: -1, ElectraEpoch: -1}
+	domain := backend.relay.opts.EthNetDetails.DomainBuilder
+
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+	// Bid of the upstream relay, signed with its own key
+	upstreamSk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	upstreamPubkey, err := publicKeyFromSecretKey(upstreamSk)
+	require.NoError(t, err)
+	parent, err := common.StrToPhase0Hash(parentHash)
+	require.NoError(t, err)
+	payload := &common.VersionedSubmitBlockRequest{
+		VersionedSubmitBlockRequest: builderSpec.VersionedSubmitBlockRequest{
+			Version: spec.DataVersionCapella,
+			Capella: &builderApiCapella.SubmitBlockRequest{
+				Message:          &builderApiV1.BidTrace{Slot: slot, Value: uint256.NewInt(300)},
+				ExecutionPayload: &capella.ExecutionPayload{ParentHash: parent, BlockHash: phase0.Hash32{0x01}},
+			},
+		},
+	}
+	upstreamBid, err := common.BuildGetHeaderResponse(payload, upstreamSk, &upstreamPubkey, domain)
+	require.NoError(t, err)
+
+	getPayloadBody := []byte(`{"version":"capella","data":{}}`)
+	var proxiedBody []byte
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			proxiedBody, _ = io.ReadAll(req.Body)
+			w.Write(getPayloadBody) //nolint:errcheck
+			return
+		}
+		json.NewEncoder(w).Encode(upstreamBid) //nolint:errcheck,errchkjson
+	}))
+	defer upstream.Close()
+	relay, err := newUpstreamRelay(strings.Replace(upstream.URL, "http://", "http://"+upstreamPubkey.String()+"@", 1))
+	require.NoError(t, err)
+	backend.relay.upstreamRelays = []*upstreamRelay{relay}
+	backend.relay.upstreamClient = upstream.Client()
+
+	t.Run("upstream bid is served with the relay signature", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		bid := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), bid))
+
+		value, err := bid.Value()
+		require.NoError(t, err)
+		require.Equal(t, "300", value.Dec())
+		pubkey, err := bid.Builder()
+		require.NoError(t, err)
+		require.Equal(t, *backend.relay.publicKey, pubkey)
+		ok, err := ssz.VerifySignature(bid.Capella.Message, domain, pubkey[:], bid.Capella.Signature[:])
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		relayURL, err := backend.redis.GetUpstreamBid(slot, phase0.Hash32{0x01}.String())
+		require.NoError(t, err)
+		require.Equal(t, upstream.URL, relayURL)
+	})
+
+	t.Run("getPayload is proxied", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		backend.relay.proxyUpstreamGetPayload(w, common.TestLog, upstream.URL, []byte("signed blinded block"))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, getPayloadBody, w.Body.Bytes())
+		require.Equal(t, []byte("signed blinded block"), proxiedBody)
+
+		w = httptest.NewRecorder()
+		backend.relay.proxyUpstreamGetPayload(w, common.TestLog, "https://unknown.example.com", nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("bids with an invalid signature are ignored", func(t *testing.T) {
+		upstreamBid.Capella.Signature = phase0.BLSSignature{}
+		rr := backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"net/http"
 
 	builderApi "github.com/attestantio/go-builder-client/api"
 	"github.com/attestantio/go-eth2-client/spec"
@@ -23,6 +24,10 @@ var (
 	ErrPayloadMismatch    = errors.New("beacon-block and payload version mismatch")
 	ErrHeaderHTRMismatch  = errors.New("beacon-block and payload header mismatch")
 	ErrBlobMismatch       = errors.New("beacon-block and payload blob contents mismatch")
+
+	ErrWithdrawalsRootMismatch   = errors.New("beacon-block and payload withdrawals root mismatch")
+	ErrParentBeaconRootMismatch  = errors.New("beacon-block parent root and payload parent beacon block root mismatch")
+	ErrExecutionRequestsMismatch = errors.New("beacon-block and payload execution requests mismatch")
 )
 
 func SanityCheckBuilderBlockSubmission(payload *common.VersionedSubmitBlockRequest) error {
@@ -64,6 +69,10 @@ func EqBlindedBlockContentsToBlockContents(bb *common.VersionedSignedBlindedBeac
 			return err
 		}
 
+		if err := eqWithdrawalsRoot(bb.Capella.Message.Body.ExecutionPayloadHeader.WithdrawalsRoot, payload.Capella.Withdrawals); err != nil {
+			return err
+		}
+
 		versionedPayload.Capella = payload.Capella
 		payloadHeader, err := utils.PayloadToPayloadHeader(versionedPayload)
 		if err != nil {
@@ -85,6 +94,10 @@ func EqBlindedBlockContentsToBlockContents(bb *common.VersionedSignedBlindedBeac
 			return err
 		}
 
+		if err := eqWithdrawalsRoot(block.Body.ExecutionPayloadHeader.WithdrawalsRoot, payload.Deneb.ExecutionPayload.Withdrawals); err != nil {
+			return err
+		}
+
 		versionedPayload.Deneb = payload.Deneb.ExecutionPayload
 		payloadHeader, err := utils.PayloadToPayloadHeader(versionedPayload)
 		if err != nil {
@@ -109,6 +122,41 @@ func EqBlindedBlockContentsToBlockContents(bb *common.VersionedSignedBlindedBeac
 				return errors.Wrap(ErrBlobMismatch, fmt.Sprintf("mismatched KZG commitment at index %d", i))
 			}
 		}
+	case spec.DataVersionElectra:
+		block := bb.Electra.Message
+		bbHeaderHtr, err := block.Body.ExecutionPayloadHeader.HashTreeRoot()
+		if err != nil {
+			return err
+		}
+
+		if err := eqWithdrawalsRoot(block.Body.ExecutionPayloadHeader.WithdrawalsRoot, payload.Electra.ExecutionPayload.Withdrawals); err != nil {
+			return err
+		}
+
+		versionedPayload.Electra = payload.Electra.ExecutionPayload
+		payloadHeader, err := utils.PayloadToPayloadHeader(versionedPayload)
+		if err != nil {
+			return err
+		}
+
+		payloadHeaderHtr, err := payloadHeader.Electra.HashTreeRoot()
+		if err != nil {
+			return err
+		}
+
+		if bbHeaderHtr != payloadHeaderHtr {
+			return ErrHeaderHTRMismatch
+		}
+
+		if len(block.Body.BlobKZGCommitments) != len(payload.Electra.BlobsBundle.Commitments) {
+			return errors.Wrap(ErrBlobMismatch, "mismatched number of KZG commitments")
+		}
+
+		for i, commitment := range block.Body.BlobKZGCommitments {
+			if commitment != payload.Electra.BlobsBundle.Commitments[i] {
+				return errors.Wrap(ErrBlobMismatch, fmt.Sprintf("mismatched KZG commitment at index %d", i))
+			}
+		}
 	default:
 		return ErrUnsupportedPayload
 	}
@@ -116,6 +164,57 @@ func EqBlindedBlockContentsToBlockContents(bb *common.VersionedSignedBlindedBeac
 	return nil
 }
 
+// eqWithdrawalsRoot checks the withdrawals root of a blinded block's execution payload header against the withdrawals
+// of the payload, to tell a withdrawals mismatch apart from other header mismatches
+func eqWithdrawalsRoot(headerRoot phase0.Root, withdrawals []*capella.Withdrawal) error {
+	if withdrawals == nil {
+		withdrawals = []*capella.Withdrawal{}
+	}
+	payloadRoot, err := ComputeWithdrawalsRoot(withdrawals)
+	if err != nil {
+		return err
+	}
+	if headerRoot != payloadRoot {
+		return errors.Wrap(ErrWithdrawalsRootMismatch, fmt.Sprintf("beacon block %s, payload %s", headerRoot.String(), payloadRoot.String()))
+	}
+	return nil
+}
+
+// EqBlindedBlockParentBeaconRoot checks that a Deneb or Electra blinded block is built on the parent beacon block root
+// which the payload was built and simulated with
+func EqBlindedBlockParentBeaconRoot(bb *common.VersionedSignedBlindedBeaconBlock, parentBeaconRoot phase0.Root) error {
+	var parentRoot phase0.Root
+	switch bb.Version { //nolint:exhaustive
+	case spec.DataVersionDeneb:
+		parentRoot = bb.Deneb.Message.ParentRoot
+	case spec.DataVersionElectra:
+		parentRoot = bb.Electra.Message.ParentRoot
+	default:
+		return nil
+	}
+	if parentRoot != parentBeaconRoot {
+		return errors.Wrap(ErrParentBeaconRootMismatch, fmt.Sprintf("beacon block %s, payload %s", parentRoot.String(), parentBeaconRoot.String()))
+	}
+	return nil
+}
+
+// EqBlindedBlockExecutionRequests checks that an Electra blinded block includes the execution requests which the
+// builder submitted with the payload. The execution requests are part of the block hash, so the block would be invalid
+// with any others.
+func EqBlindedBlockExecutionRequests(bb *common.VersionedSignedBlindedBeaconBlock, executionRequestsRoot phase0.Root) error {
+	if bb.Version != spec.DataVersionElectra {
+		return nil
+	}
+	root, err := bb.Electra.Message.Body.ExecutionRequests.HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	if root != executionRequestsRoot {
+		return errors.Wrap(ErrExecutionRequestsMismatch, fmt.Sprintf("beacon block %#x, payload %s", root, executionRequestsRoot.String()))
+	}
+	return nil
+}
+
 func checkBLSPublicKeyHex(pkHex string) error {
 	_, err := utils.HexToPubkey(pkHex)
 	return err
@@ -150,3 +249,8 @@ func verifyBlockSignature(block *common.VersionedSignedBlindedBeaconBlock, domai
 func getPayloadAttributesKey(parentHash string, slot uint64) string {
 	return fmt.Sprintf("%s-%d", parentHash, slot)
 }
+
+// isAuthenticatedMTLSRequest returns true if the request arrived over TLS with a verified client certificate
+func isAuthenticatedMTLSRequest(req *http.Request) bool {
+	return req.TLS != nil && len(req.TLS.VerifiedChains) > 0
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"testing"
+
+	eth2Api "github.com/attestantio/go-eth2-client/api"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqBlindedBlockContentsToBlockContents(t *testing.T) {
+	builder := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, Version: spec.DataVersionDeneb}
+	_, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builder, uint256.NewInt(100), &opts)
+
+	blindedBlock := func() *common.VersionedSignedBlindedBeaconBlock {
+		header := *getHeaderResp.Deneb.Message.Header
+		return &common.VersionedSignedBlindedBeaconBlock{
+			VersionedSignedBlindedBeaconBlock: eth2Api.VersionedSignedBlindedBeaconBlock{
+				Version: spec.DataVersionDeneb,
+				Deneb: &eth2ApiV1Deneb.SignedBlindedBeaconBlock{
+					Message: &eth2ApiV1Deneb.BlindedBeaconBlock{
+						Slot: 2,
+						Body: &eth2ApiV1Deneb.BlindedBeaconBlockBody{
+							ExecutionPayloadHeader: &header,
+							BlobKZGCommitments:     []deneb.KZGCommitment{},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	require.NoError(t, EqBlindedBlockContentsToBlockContents(blindedBlock(), getPayloadResp))
+
+	bb := blindedBlock()
+	bb.Deneb.Message.Body.ExecutionPayloadHeader.WithdrawalsRoot = phase0.Root{0x01}
+	require.ErrorIs(t, EqBlindedBlockContentsToBlockContents(bb, getPayloadResp), ErrWithdrawalsRootMismatch)
+
+	bb = blindedBlock()
+	bb.Deneb.Message.Body.ExecutionPayloadHeader.GasUsed++
+	require.ErrorIs(t, EqBlindedBlockContentsToBlockContents(bb, getPayloadResp), ErrHeaderHTRMismatch)
+
+	bb = blindedBlock()
+	bb.Deneb.Message.Body.BlobKZGCommitments = []deneb.KZGCommitment{{0x01}}
+	require.ErrorIs(t, EqBlindedBlockContentsToBlockContents(bb, getPayloadResp), ErrBlobMismatch)
+
+	// The payload withdrawals are checked against the header
+	getPayloadResp.Deneb.ExecutionPayload.Withdrawals = []*capella.Withdrawal{{Index: 1, Amount: 10}}
+	require.ErrorIs(t, EqBlindedBlockContentsToBlockContents(blindedBlock(), getPayloadResp), ErrWithdrawalsRootMismatch)
+
+	// Parent beacon block root
+	parentBeaconRoot := phase0.Root{0x02}
+	bb = blindedBlock()
+	bb.Deneb.Message.ParentRoot = parentBeaconRoot
+	require.NoError(t, EqBlindedBlockParentBeaconRoot(bb, parentBeaconRoot))
+	require.ErrorIs(t, EqBlindedBlockParentBeaconRoot(bb, phase0.Root{0x03}), ErrParentBeaconRootMismatch)
+}
+
+func TestEqBlindedBlockContentsToBlockContentsElectra(t *testing.T) {
+	builder := "0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 2, Version: spec.DataVersionElectra}
+	_, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builder, uint256.NewInt(100), &opts)
+
+	executionRequests := common.TestExecutionRequests()
+	blindedBlock := func() *common.VersionedSignedBlindedBeaconBlock {
+		header := *getHeaderResp.Electra.Message.Header
+		return &common.VersionedSignedBlindedBeaconBlock{
+			VersionedSignedBlindedBeaconBlock: eth2Api.VersionedSignedBlindedBeaconBlock{
+				Version: spec.DataVersionElectra,
+				Electra: &eth2ApiV1Electra.SignedBlindedBeaconBlock{
+					Message: &eth2ApiV1Electra.BlindedBeaconBlock{
+						Slot: 2,
+						Body: &eth2ApiV1Electra.BlindedBeaconBlockBody{
+							ExecutionPayloadHeader: &header,
+							BlobKZGCommitments:     []deneb.KZGCommitment{},
+							ExecutionRequests:      executionRequests,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	require.NoError(t, EqBlindedBlockContentsToBlockContents(blindedBlock(), getPayloadResp))
+
+	bb := blindedBlock()
+	bb.Electra.Message.Body.ExecutionPayloadHeader.GasUsed++
+	require.ErrorIs(t, EqBlindedBlockContentsToBlockContents(bb, getPayloadResp), ErrHeaderHTRMismatch)
+
+	bb = blindedBlock()
+	bb.Electra.Message.Body.BlobKZGCommitments = []deneb.KZGCommitment{{0x01}}
+	require.ErrorIs(t, EqBlindedBlockContentsToBlockContents(bb, getPayloadResp), ErrBlobMismatch)
+
+	// Parent beacon block root
+	parentBeaconRoot := phase0.Root{0x02}
+	bb = blindedBlock()
+	bb.Electra.Message.ParentRoot = parentBeaconRoot
+	require.NoError(t, EqBlindedBlockParentBeaconRoot(bb, parentBeaconRoot))
+	require.ErrorIs(t, EqBlindedBlockParentBeaconRoot(bb, phase0.Root{0x03}), ErrParentBeaconRootMismatch)
+
+	// Execution requests
+	executionRequestsRoot, err := executionRequests.HashTreeRoot()
+	require.NoError(t, err)
+	require.NoError(t, EqBlindedBlockExecutionRequests(blindedBlock(), executionRequestsRoot))
+	bb = blindedBlock()
+	bb.Electra.Message.Body.ExecutionRequests = &electra.ExecutionRequests{}
+	require.ErrorIs(t, EqBlindedBlockExecutionRequests(bb, executionRequestsRoot), ErrExecutionRequestsMismatch)
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// warmStartRedis restores the state of the current and next slot from the database into Redis, where it is missing
+// (i.e. after Redis was flushed or replaced): the proposer duties, the registration timestamps of the upcoming proposers,
+// and the top bids of the next slot. Without it, block submissions would be rejected until the housekeeper updated the
+// duties, and getHeader would return no bid until builders submitted again.
+func (api *RelayAPI) warmStartRedis(headSlot uint64) {
+	log := api.log.WithFields(logrus.Fields{
+		"method":   "warmStartRedis",
+		"headSlot": headSlot,
+	})
+
+	numDuties, duties := api.warmStartProposerDuties(log, headSlot)
+	numRegistrations := api.warmStartRegistrations(log, headSlot, duties)
+	numBids := api.warmStartTopBids(log, headSlot+1)
+
+	log.WithFields(logrus.Fields{
+		"numDutiesRestored":        numDuties,
+		"numRegistrationsRestored": numRegistrations,
+		"numBidsRestored":          numBids,
+	}).Info("warm start of redis done")
+}
+
+// warmStartProposerDuties builds the proposer duties from the beacon node and the database if they are not in Redis,
+// and returns the number of restored duties along with the duties
+func (api *RelayAPI) warmStartProposerDuties(log *logrus.Entry, headSlot uint64) (int, []common.BuilderGetValidatorsResponseEntry) {
+	duties, err := api.redis.GetProposerDuties()
+	if err != nil {
+		log.WithError(err).Error("failed to get proposer duties from redis")
+		return 0, nil
+	}
+	if len(duties) > 0 {
+		return 0, duties
+	}
+
+	duties, err = datastore.BuildProposerDuties(log, api.beaconClient, api.db, common.SlotToEpoch(headSlot))
+	if err != nil {
+		log.WithError(err).Error("failed to build proposer duties")
+		return 0, nil
+	}
+	err = api.redis.SetProposerDuties(duties)
+	if err != nil {
+		log.WithError(err).Error("failed to save proposer duties")
+		return 0, nil
+	}
+	return len(duties), duties
+}
+
+// warmStartRegistrations restores the registration timestamps of the proposers of upcoming slots, and returns the
+// number of proposers
+func (api *RelayAPI) warmStartRegistrations(log *logrus.Entry, headSlot uint64, duties []common.BuilderGetValidatorsResponseEntry) int {
+	numRegistrations := 0
+	for _, duty := range duties {
+		if duty.Slot <= headSlot || duty.Entry == nil {
+			continue
+		}
+		pubkey := common.NewPubkeyHex(duty.Entry.Message.Pubkey.String())
+		err := api.redis.SetValidatorRegistrationTimestampIfNewer(pubkey, uint64(duty.Entry.Message.Timestamp.Unix()))
+		if err != nil {
+			log.WithError(err).WithField("pubkey", pubkey).Error("failed to restore validator registration timestamp")
+			continue
+		}
+		numRegistrations++
+	}
+	return numRegistrations
+}
+
+// warmStartTopBids restores the latest eligible and valid bid of every builder for the slot from the database, for all
+// parent hashes and proposers without a top bid in Redis, and returns the number of restored bids
+func (api *RelayAPI) warmStartTopBids(log *logrus.Entry, slot uint64) int {
+	entries, err := api.db.GetBuilderSubmissionsWithPayloadBySlots(slot, slot)
+	if err != nil {
+		log.WithError(err).Error("failed to get builder submissions")
+		return 0
+	}
+
+	// The latest eligible submission of every builder, by parent hash and proposer (entries are sorted by insertion)
+	type bidKey struct {
+		parentHash     string
+		proposerPubkey string
+		builderPubkey  string
+	}
+	latest := make(map[bidKey]*database.BuilderBlockSubmissionEntry)
+	keys := make([]bidKey, 0)
+	lastDemotions := make(map[string]*database.BuilderDemotionEntry)
+	for _, entry := range entries {
+		if !api.isRestorableSubmission(log, entry, lastDemotions) {
+			continue
+		}
+		key := bidKey{entry.ParentHash, entry.ProposerPubkey, entry.BuilderPubkey}
+		if _, ok := latest[key]; !ok {
+			keys = append(keys, key)
+		}
+		latest[key] = entry
+	}
+
+	// Only restore bids where Redis has no top bid, not to overwrite newer bids
+	hasTopBid := make(map[string]bool)
+	numBids := 0
+	for _, key := range keys {
+		entry := latest[key]
+		bidLog := log.WithFields(logrus.Fields{
+			"parentHash":    entry.ParentHash,
+			"builderPubkey": entry.BuilderPubkey,
+			"blockHash":     entry.BlockHash,
+		})
+
+		topBidKey := entry.ParentHash + entry.ProposerPubkey
+		isKnown, ok := hasTopBid[topBidKey]
+		if !ok {
+			bid, err := api.redis.GetBestBid(slot, entry.ParentHash, entry.ProposerPubkey)
+			if err != nil {
+				bidLog.WithError(err).Error("failed to get best bid")
+				continue
+			}
+			isKnown = bid != nil
+			hasTopBid[topBidKey] = isKnown
+		}
+		if isKnown {
+			continue
+		}
+
+		err = api.warmStartBid(entry)
+		if err != nil {
+			bidLog.WithError(err).Error("failed to restore bid")
+			continue
+		}
+		numBids++
+	}
+	return numBids
+}
+
+// isRestorableSubmission returns whether the bid of an eligible submission can be restored: it was simulated
+// successfully, or it was submitted optimistically, its simulation didn't fail, and the builder wasn't demoted since.
+// Optimistic submissions are eligible before they are simulated, so they might belong to a failed block of a demoted
+// builder. The last demotion of every builder is cached in lastDemotions.
+func (api *RelayAPI) isRestorableSubmission(log *logrus.Entry, entry *database.BuilderBlockSubmissionEntry, lastDemotions map[string]*database.BuilderDemotionEntry) bool {
+	if !entry.EligibleAt.Valid {
+		return false
+	} else if entry.SimSuccess {
+		return true
+	} else if !entry.OptimisticSubmission || entry.WasSimulated {
+		return false
+	}
+
+	demotion, ok := lastDemotions[entry.BuilderPubkey]
+	if !ok {
+		demotions, err := api.db.GetBuilderDemotions(entry.BuilderPubkey, 1)
+		if err != nil {
+			log.WithError(err).WithField("builderPubkey", entry.BuilderPubkey).Error("failed to get builder demotions")
+			return false
+		}
+		if len(demotions) > 0 {
+			demotion = demotions[0]
+		}
+		lastDemotions[entry.BuilderPubkey] = demotion
+	}
+	return demotion == nil || demotion.InsertedAt.Before(entry.ReceivedAt.Time)
+}
+
+// warmStartBid saves a bid from the database to Redis. Restored bids are cancellable, so that they don't set the
+// floor bid.
+func (api *RelayAPI) warmStartBid(entry *database.BuilderBlockSubmissionEntry) error {
+	executionPayloadEntry, err := api.db.GetExecutionPayloadEntryByID(entry.ExecutionPayloadID.Int64)
+	if err != nil {
+		return err
+	}
+	payload, err := database.BuilderSubmissionEntryToSubmitBlockRequest(entry, executionPayloadEntry)
+	if err != nil {
+		return err
+	}
+	getHeaderResponse, getPayloadResponse, bidTrace, err := api.buildBidResponses(payload)
+	if err != nil {
+		return err
+	}
+	_, err = api.redis.SaveBidAndUpdateTopBid(context.Background(), api.redis.NewTxPipeline(), bidTrace, payload, getPayloadResponse, getHeaderResponse, entry.ReceivedAt.Time, true, nil)
+	return err
+}
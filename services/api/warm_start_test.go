@@ -0,0 +1,115 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+// warmStartProposerPubkey is the proposer of the slot of the restored bids
+var warmStartProposerPubkey = phase0.BLSPubKey(hexutil.MustDecode("0xb6e6991523edb370b092c8357460297e21b38a8eec9729558358e6a3c56433c9605ad5a97d224ca3aec02678bd81f47c"))
+
+type warmStartDB struct {
+	database.MockDB
+	submissions []*database.BuilderBlockSubmissionEntry
+	payloads    map[int64]*database.ExecutionPayloadEntry
+	demotedAt   map[string]time.Time // by builder pubkey
+}
+
+func (db warmStartDB) GetBuilderSubmissionsWithPayloadBySlots(slotFrom, slotTo uint64) ([]*database.BuilderBlockSubmissionEntry, error) {
+	return db.submissions, nil
+}
+
+func (db warmStartDB) GetExecutionPayloadEntryByID(executionPayloadID int64) (*database.ExecutionPayloadEntry, error) {
+	return db.payloads[executionPayloadID], nil
+}
+
+func (db warmStartDB) GetBuilderDemotions(builderPubkey string, limit uint64) ([]*database.BuilderDemotionEntry, error) {
+	if demotedAt, ok := db.demotedAt[builderPubkey]; ok {
+		return []*database.BuilderDemotionEntry{{InsertedAt: demotedAt, BuilderPubkey: builderPubkey}}, nil
+	}
+	return nil, nil
+}
+
+func (db *warmStartDB) addSubmission(t *testing.T, value uint64, isEligible bool) *database.BuilderBlockSubmissionEntry {
+	t.Helper()
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	builderPubkey, err := utils.BlsPublicKeyToPublicKey(pk)
+	require.NoError(t, err)
+
+	bidTrace := getTestBidTrace(builderPubkey, value, testSlot+1)
+	bidTrace.ProposerPubkey = warmStartProposerPubkey
+	payload := common.TestBuilderSubmitBlockRequest(sk, bidTrace, spec.DataVersionDeneb)
+	payloadEntry, err := database.PayloadToExecPayloadEntry(payload)
+	require.NoError(t, err)
+	id := int64(len(db.submissions) + 1)
+	db.payloads[id] = payloadEntry
+
+	message := payload.Deneb.Message
+	entry := &database.BuilderBlockSubmissionEntry{
+		ReceivedAt:           sql.NullTime{Time: time.Now(), Valid: true},
+		EligibleAt:           sql.NullTime{Time: time.Now(), Valid: isEligible},
+		WasSimulated:         true,
+		SimSuccess:           isEligible,
+		ExecutionPayloadID:   sql.NullInt64{Int64: id, Valid: true},
+		Signature:            payload.Deneb.Signature.String(),
+		Slot:                 message.Slot,
+		ParentHash:           message.ParentHash.String(),
+		BlockHash:            message.BlockHash.String(),
+		BuilderPubkey:        message.BuilderPubkey.String(),
+		ProposerPubkey:       message.ProposerPubkey.String(),
+		ProposerFeeRecipient: message.ProposerFeeRecipient.String(),
+		Value:                message.Value.Dec(),
+	}
+	db.submissions = append(db.submissions, entry)
+	return entry
+}
+
+func TestWarmStartTopBids(t *testing.T) {
+	db := &warmStartDB{payloads: make(map[int64]*database.ExecutionPayloadEntry), demotedAt: make(map[string]time.Time)}
+	db.addSubmission(t, 100, true)
+	db.addSubmission(t, 300, false)
+	db.addSubmission(t, 200, true)
+
+	// Optimistic submissions are eligible before the simulation: failed ones and those of builders demoted since are
+	// not restored
+	failed := db.addSubmission(t, 500, true)
+	failed.OptimisticSubmission, failed.SimSuccess = true, false
+	demoted := db.addSubmission(t, 400, true)
+	demoted.OptimisticSubmission, demoted.WasSimulated, demoted.SimSuccess = true, false, false
+	db.demotedAt[demoted.BuilderPubkey] = demoted.ReceivedAt.Time.Add(time.Second)
+	pending := db.addSubmission(t, 250, true)
+	pending.OptimisticSubmission, pending.WasSimulated, pending.SimSuccess = true, false, false
+	db.demotedAt[pending.BuilderPubkey] = pending.ReceivedAt.Time.Add(-time.Hour)
+
+	backend := newTestBackend(t, 1)
+	backend.relay.db = db
+	getTopBidValue := func() uint64 {
+		bid, err := backend.relay.redis.GetBestBid(testSlot+1, emptyHash, warmStartProposerPubkey.String())
+		require.NoError(t, err)
+		require.NotNil(t, bid)
+		value, err := bid.Value()
+		require.NoError(t, err)
+		return value.Uint64()
+	}
+
+	// The eligible and valid bids are restored, with the payload of the top bid
+	require.Equal(t, 3, backend.relay.warmStartTopBids(common.TestLog, testSlot+1))
+	require.Equal(t, uint64(250), getTopBidValue())
+	payload, err := backend.relay.redis.GetPayloadContents(testSlot+1, warmStartProposerPubkey.String(), pending.BlockHash)
+	require.NoError(t, err)
+	require.NotNil(t, payload)
+
+	// Bids are not restored again once there is a top bid
+	require.Equal(t, 0, backend.relay.warmStartTopBids(common.TestLog, testSlot+1))
+}
@@ -0,0 +1,97 @@
+package housekeeper
+
+import (
+	"math/big"
+	"os"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// collateral contract, whose view function returns the deposit (in wei) of a builder pubkey. If set, the collateral
+	// of all builders is updated from the contract once per epoch (requires the execution node).
+	collateralContractAddress = os.Getenv("COLLATERAL_CONTRACT_ADDRESS")
+	collateralContractMethod  = common.GetEnv("COLLATERAL_CONTRACT_METHOD", "collateralOf(bytes)")
+)
+
+// encodeBytesCall returns the calldata of a contract call with a single bytes argument
+func encodeBytesCall(method string, arg []byte) []byte {
+	data := crypto.Keccak256([]byte(method))[:4]
+	data = append(data, ethcommon.LeftPadBytes(big.NewInt(32).Bytes(), 32)...) // offset of the argument
+	data = append(data, ethcommon.LeftPadBytes(big.NewInt(int64(len(arg))).Bytes(), 32)...)
+	data = append(data, ethcommon.RightPadBytes(arg, (len(arg)+31)/32*32)...)
+	return data
+}
+
+// getCollateral returns the collateral of a builder pubkey in the collateral contract, at the finalized block
+func (c *executionClient) getCollateral(contract, method, builderPubkey string) (*big.Int, error) {
+	pubkey, err := hexutil.Decode(builderPubkey)
+	if err != nil {
+		return nil, err
+	}
+	msg := map[string]string{
+		"to":   contract,
+		"data": hexutil.Encode(encodeBytesCall(method, pubkey)),
+	}
+	var result hexutil.Bytes
+	if err := c.call(&result, "eth_call", msg, "finalized"); err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(result), nil
+}
+
+// updateBuilderCollateral sets the collateral of all builders to their deposit in the collateral contract
+func (hk *Housekeeper) updateBuilderCollateral() {
+	// Should only happen once at a time
+	if hk.isUpdatingCollateral.Swap(true) {
+		return
+	}
+	defer hk.isUpdatingCollateral.Store(false)
+
+	builders, err := hk.db.GetBlockBuilders()
+	if err != nil {
+		hk.log.WithError(err).Error("failed to get block builders for collateral update")
+		return
+	}
+
+	log := hk.log.WithField("collateralContract", collateralContractAddress)
+	log.Info("updating builder collateral from contract...")
+	timeStarted := time.Now()
+
+	numUpdated := 0
+	for _, builder := range builders {
+		collateral, err := hk.executionClient.getCollateral(collateralContractAddress, collateralContractMethod, builder.BuilderPubkey)
+		if err != nil {
+			// retried in the next epoch
+			log.WithError(err).WithField("builderPubkey", builder.BuilderPubkey).Error("failed to get builder collateral from contract")
+			return
+		}
+
+		if prevCollateral, ok := new(big.Int).SetString(builder.Collateral, 10); ok && prevCollateral.Cmp(collateral) == 0 {
+			continue
+		}
+
+		err = hk.db.SetBlockBuilderCollateral(builder.BuilderPubkey, builder.BuilderID, collateral.String())
+		if err != nil {
+			log.WithError(err).WithField("builderPubkey", builder.BuilderPubkey).Error("failed to set builder collateral")
+			return
+		}
+		log.WithFields(logrus.Fields{
+			"builderPubkey":  builder.BuilderPubkey,
+			"prevCollateral": builder.Collateral,
+			"collateral":     collateral.String(),
+		}).Info("updated builder collateral from contract")
+		numUpdated++
+	}
+
+	log.WithFields(logrus.Fields{
+		"numBuilders": len(builders),
+		"numUpdated":  numUpdated,
+		"timeNeeded":  time.Since(timeStarted).String(),
+	}).Info("updated builder collateral from contract")
+}
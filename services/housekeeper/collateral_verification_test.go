@@ -0,0 +1,66 @@
+package housekeeper
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-utils/jsonrpc"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+const testBuilderPubkey = "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+func TestEncodeBytesCall(t *testing.T) {
+	pubkey, err := hexutil.Decode(testBuilderPubkey)
+	require.NoError(t, err)
+
+	data := encodeBytesCall("collateralOf(bytes)", pubkey)
+	require.Len(t, data, 4+32+32+64)
+	require.Equal(t, big.NewInt(32), new(big.Int).SetBytes(data[4:36]))
+	require.Equal(t, big.NewInt(48), new(big.Int).SetBytes(data[36:68]))
+	require.Equal(t, pubkey, data[68:116])
+}
+
+func TestUpdateBuilderCollateral(t *testing.T) {
+	collateral := big.NewInt(2_000_000_000_000_000_000)
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(jsonrpc.JSONRPCRequest)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		require.Equal(t, "eth_call", req.Method)
+		require.Equal(t, "finalized", req.Params[1])
+
+		// only the test builder has a deposit
+		result := hexutil.Encode(ethcommon.LeftPadBytes(nil, 32))
+		msg := req.Params[0].(map[string]any)
+		if strings.HasSuffix(msg["data"].(string), testBuilderPubkey[2:]+strings.Repeat("0", 32)) {
+			result = hexutil.Encode(ethcommon.LeftPadBytes(collateral.Bytes(), 32))
+		}
+		resultBytes, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(jsonrpc.NewJSONRPCResponse(req.ID, resultBytes)))
+	}))
+	defer node.Close()
+
+	otherPubkey := "0x" + strings.Repeat("ab", 48)
+	db := database.MockDB{Builders: map[string]*database.BlockBuilderEntry{
+		testBuilderPubkey: {BuilderPubkey: testBuilderPubkey, BuilderID: "builder", Collateral: "0"},
+		otherPubkey:       {BuilderPubkey: otherPubkey, Collateral: "1000"},
+	}}
+
+	collateralContractAddress = "0x0000000000000000000000000000000000000001"
+	defer func() { collateralContractAddress = "" }()
+
+	hk := NewHousekeeper(&HousekeeperOpts{Log: common.TestLog, DB: db, ExecutionURI: node.URL})
+	hk.updateBuilderCollateral()
+	require.Equal(t, collateral.String(), db.Builders[testBuilderPubkey].Collateral)
+	require.Equal(t, "builder", db.Builders[testBuilderPubkey].BuilderID)
+	require.Equal(t, "0", db.Builders[otherPubkey].Collateral)
+}
@@ -0,0 +1,40 @@
+package housekeeper
+
+import (
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// summarizeEpoch rolls up the delivered payloads of an epoch from the database, and the validator registrations and
+// getHeader responses from Redis, and saves the summary
+func (hk *Housekeeper) summarizeEpoch(epoch uint64) (*database.EpochSummaryEntry, error) {
+	entry, err := hk.db.AggregateEpochSummary(epoch)
+	if err != nil {
+		return nil, err
+	}
+	entry.NumRegistrations, err = hk.redis.GetEpochCount(epoch, datastore.RedisEpochCountFieldRegistrations)
+	if err != nil {
+		return nil, err
+	}
+	entry.NumHeadersServed, err = hk.redis.GetEpochCount(epoch, datastore.RedisEpochCountFieldHeadersServed)
+	if err != nil {
+		return nil, err
+	}
+	return entry, hk.db.UpsertEpochSummary(entry)
+}
+
+func (hk *Housekeeper) saveEpochSummary(epoch uint64) {
+	log := hk.log.WithField("epoch", epoch)
+	entry, err := hk.summarizeEpoch(epoch)
+	if err != nil {
+		log.WithError(err).Error("failed to save epoch summary")
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"numRegistrations":     entry.NumRegistrations,
+		"numHeadersServed":     entry.NumHeadersServed,
+		"numPayloadsDelivered": entry.NumPayloadsDelivered,
+		"deliveredValue":       entry.DeliveredValue,
+	}).Info("saved epoch summary")
+}
@@ -0,0 +1,44 @@
+package housekeeper
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+// epochSummaryDB records the saved epoch summaries
+type epochSummaryDB struct {
+	database.MockDB
+	saved []*database.EpochSummaryEntry
+}
+
+func (db *epochSummaryDB) UpsertEpochSummary(entry *database.EpochSummaryEntry) error {
+	db.saved = append(db.saved, entry)
+	return nil
+}
+
+func TestSummarizeEpoch(t *testing.T) {
+	redisTestServer, err := miniredis.Run()
+	require.NoError(t, err)
+	redisCache, err := datastore.NewRedisCache("", redisTestServer.Addr(), "")
+	require.NoError(t, err)
+
+	epoch := uint64(7)
+	require.NoError(t, redisCache.IncEpochCount(epoch, datastore.RedisEpochCountFieldRegistrations, 250))
+	require.NoError(t, redisCache.IncEpochCount(epoch, datastore.RedisEpochCountFieldHeadersServed, 30))
+	require.NoError(t, redisCache.IncEpochCount(epoch+1, datastore.RedisEpochCountFieldHeadersServed, 1))
+
+	db := new(epochSummaryDB)
+	hk := NewHousekeeper(&HousekeeperOpts{Log: common.TestLog, Redis: redisCache, DB: db})
+	entry, err := hk.summarizeEpoch(epoch)
+	require.NoError(t, err)
+
+	require.Equal(t, []*database.EpochSummaryEntry{entry}, db.saved)
+	require.Equal(t, epoch, entry.Epoch)
+	require.Equal(t, uint64(250), entry.NumRegistrations)
+	require.Equal(t, uint64(30), entry.NumHeadersServed)
+}
@@ -3,20 +3,26 @@
 // - Update known validators
 // - Updating proposer duties
 // - Saving metrics
-// - Deleting old bids
+// - Deleting the Redis keys of the auctions of past slots
+// - Pruning old execution payloads from the database
+// - Verifying proposer payments of delivered payloads on the execution layer
+// - Updating the builder collateral from the collateral contract
+// - Saving the auction summary of each slot, and a rollup of each epoch
 // - ...
 package housekeeper
 
 import (
 	"errors"
+	"math"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/mev-boost-relay/beaconclient"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/flashbots/mev-boost-relay/database"
@@ -32,8 +38,14 @@ type HousekeeperOpts struct {
 	DB           database.IDatabaseService
 	BeaconClient beaconclient.IMultiBeaconClient
 
+	// Optional execution node, for verifying proposer payments
+	ExecutionURI string
+
 	PprofAPI           bool
 	PprofListenAddress string
+
+	// Version of the relay, reported in the instance heartbeat
+	Version string
 }
 
 type Housekeeper struct {
@@ -44,19 +56,44 @@ type Housekeeper struct {
 	db           database.IDatabaseService
 	beaconClient beaconclient.IMultiBeaconClient
 
+	executionClient *executionClient
+
 	pprofAPI           bool
 	pprofListenAddress string
 
 	isStarted                uberatomic.Bool
 	isUpdatingProposerDuties uberatomic.Bool
+	isPruningPayloads        uberatomic.Bool
+	isCompactingPayloads     uberatomic.Bool
+	isVerifyingPayments      uberatomic.Bool
+	isUpdatingCollateral     uberatomic.Bool
+	isSummarizingSlots       uberatomic.Bool
+	isDeletingSlotKeys       uberatomic.Bool
 	proposerDutiesSlot       uint64
 
-	headSlot uberatomic.Uint64
+	headSlot  uberatomic.Uint64
+	startedAt time.Time
+	isLeader  uberatomic.Bool // whether this instance holds the housekeeper lease
 
 	proposersAlreadySaved map[uint64]string // to avoid repeating redis writes
 }
 
-var ErrServerAlreadyStarted = errors.New("server was already started")
+var (
+	ErrServerAlreadyStarted = errors.New("server was already started")
+
+	// execution payload retention (0 days means payloads are kept forever)
+	payloadRetentionDays     = cli.GetEnvInt("PAYLOAD_RETENTION_DAYS", 0)
+	payloadPruneBatchSize    = cli.GetEnvInt("PAYLOAD_PRUNE_BATCH_SIZE", 1000)
+	payloadPruneBatchDelayMs = cli.GetEnvInt("PAYLOAD_PRUNE_BATCH_DELAY_MS", 500)
+
+	// execution payloads older than this many epochs are compacted (0 to keep all full payloads), except delivered ones.
+	// Uses the batch size and delay of pruning.
+	payloadCompactionEpochs         = cli.GetEnvInt("PAYLOAD_COMPACTION_EPOCHS", 0)
+	payloadCompactionRequireArchive = os.Getenv("PAYLOAD_COMPACTION_REQUIRE_ARCHIVE") == "1"
+
+	// the lease is renewed on every slot, and taken over by another housekeeper if not renewed for this long
+	leaseTTL = 3 * common.DurationPerSlot
+)
 
 func NewHousekeeper(opts *HousekeeperOpts) *Housekeeper {
 	server := &Housekeeper{
@@ -68,6 +105,19 @@ func NewHousekeeper(opts *HousekeeperOpts) *Housekeeper {
 		pprofAPI:              opts.PprofAPI,
 		pprofListenAddress:    opts.PprofListenAddress,
 		proposersAlreadySaved: make(map[uint64]string),
+		startedAt:             time.Now().UTC(),
+	}
+
+	if opts.ExecutionURI != "" {
+		server.executionClient = newExecutionClient(opts.ExecutionURI)
+	}
+
+	if collateralContractAddress != "" {
+		if server.executionClient == nil {
+			server.log.Warn("env: COLLATERAL_CONTRACT_ADDRESS - ignored without execution node")
+		} else {
+			server.log.WithField("method", collateralContractMethod).Infof("env: COLLATERAL_CONTRACT_ADDRESS - builder collateral is updated from contract %s", collateralContractAddress)
+		}
 	}
 
 	return server
@@ -140,9 +190,56 @@ func (hk *Housekeeper) processNewSlot(headSlot uint64) {
 		}
 	}
 
+	// With several housekeepers, only the lease holder performs the tasks below
+	hk.updateLeaseAndHeartbeat(log)
+	if !hk.isLeader.Load() {
+		log.Debug("not the housekeeper lease holder, skipping slot tasks")
+		return
+	}
+
 	// Update proposer duties
 	go hk.updateProposerDuties(headSlot)
 
+	// Prune old execution payloads once per epoch
+	if payloadRetentionDays > 0 && common.SlotPos(headSlot) == 1 {
+		go hk.pruneExecutionPayloads()
+	}
+
+	// Compact old execution payloads once per epoch
+	if payloadCompactionEpochs > 0 && common.SlotPos(headSlot) == 2 {
+		go hk.compactExecutionPayloads(headSlot)
+	}
+
+	// Verify proposer payments of finalized delivered payloads once per epoch
+	if hk.executionClient != nil && common.SlotPos(headSlot) == 3 {
+		go hk.verifyPayments()
+	}
+
+	// Update the builder collateral from the collateral contract once per epoch
+	if hk.executionClient != nil && collateralContractAddress != "" && common.SlotPos(headSlot) == 4 {
+		go hk.updateBuilderCollateral()
+	}
+
+	// Save the summary of the previous epoch once per epoch
+	if headSlot >= common.SlotsPerEpoch && common.SlotPos(headSlot) == 5 {
+		go hk.saveEpochSummary(headSlot/common.SlotsPerEpoch - 1)
+	}
+
+	// Save the auction summaries of the slots which are old enough (including missed slots)
+	if headSlot > slotSummaryDelaySlots {
+		toSlot := headSlot - slotSummaryDelaySlots
+		fromSlot := toSlot
+		if prevHeadSlot > slotSummaryDelaySlots {
+			fromSlot = prevHeadSlot - slotSummaryDelaySlots + 1
+		}
+		go hk.summarizeSlots(fromSlot, toSlot)
+	}
+
+	// Delete the Redis keys of the auctions of past slots
+	if slotGCRetainSlots > 0 && headSlot > slotGCRetainSlots {
+		go hk.deleteSlotKeys(headSlot - slotGCRetainSlots + 1)
+	}
+
 	// Set headSlot in redis (for the website)
 	err := hk.redis.SetStats(datastore.RedisStatsFieldLatestSlot, headSlot)
 	if err != nil {
@@ -156,6 +253,28 @@ func (hk *Housekeeper) processNewSlot(headSlot uint64) {
 	}).Infof("updated headSlot to %d", headSlot)
 }
 
+// updateLeaseAndHeartbeat acquires or renews the housekeeper lease, and writes the instance heartbeat
+func (hk *Housekeeper) updateLeaseAndHeartbeat(log *logrus.Entry) {
+	isLeader, err := hk.redis.AcquireLease(datastore.LeaseHousekeeper, common.InstanceID, leaseTTL)
+	if err != nil {
+		log.WithError(err).Error("failed to acquire housekeeper lease")
+	}
+	if wasLeader := hk.isLeader.Swap(isLeader); wasLeader != isLeader {
+		log.WithField("isLeader", isLeader).Info("housekeeper lease changed")
+	}
+
+	err = hk.redis.SetInstanceHeartbeat(datastore.InstanceHeartbeat{
+		InstanceID: common.InstanceID,
+		Service:    "housekeeper",
+		Version:    hk.opts.Version,
+		StartedAt:  hk.startedAt,
+		LastSeen:   time.Now().UTC(),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to write instance heartbeat")
+	}
+}
+
 func (hk *Housekeeper) updateProposerDuties(headSlot uint64) {
 	// Should only happen once at a time
 	if hk.isUpdatingProposerDuties.Swap(true) {
@@ -176,56 +295,11 @@ func (hk *Housekeeper) updateProposerDuties(headSlot uint64) {
 	})
 	log.Debug("updating proposer duties...")
 
-	// Query current epoch
-	r, err := hk.beaconClient.GetProposerDuties(epoch)
+	proposerDuties, err := datastore.BuildProposerDuties(log, hk.beaconClient, hk.db, epoch)
 	if err != nil {
-		log.WithError(err).Error("failed to get proposer duties for all beacon nodes")
+		log.WithError(err).Error("failed to build proposer duties")
 		return
 	}
-	entries := r.Data
-
-	// Query next epoch
-	r2, err := hk.beaconClient.GetProposerDuties(epoch + 1)
-	if err != nil {
-		log.WithError(err).Error("failed to get proposer duties for next epoch for all beacon nodes")
-	} else if r2 != nil {
-		entries = append(entries, r2.Data...)
-	}
-
-	// Get registrations from database
-	pubkeys := []string{}
-	for _, entry := range entries {
-		pubkeys = append(pubkeys, entry.Pubkey)
-	}
-	validatorRegistrationEntries, err := hk.db.GetValidatorRegistrationsForPubkeys(pubkeys)
-	if err != nil {
-		log.WithError(err).Error("failed to get validator registrations")
-		return
-	}
-
-	// Convert db entries to signed validator registration type
-	signedValidatorRegistrations := make(map[string]*builderApiV1.SignedValidatorRegistration)
-	for _, regEntry := range validatorRegistrationEntries {
-		signedEntry, err := regEntry.ToSignedValidatorRegistration()
-		if err != nil {
-			log.WithError(err).Error("failed to convert validator registration entry to signed validator registration")
-			continue
-		}
-		signedValidatorRegistrations[regEntry.Pubkey] = signedEntry
-	}
-
-	// Prepare proposer duties
-	proposerDuties := []common.BuilderGetValidatorsResponseEntry{}
-	for _, duty := range entries {
-		reg := signedValidatorRegistrations[duty.Pubkey]
-		if reg != nil {
-			proposerDuties = append(proposerDuties, common.BuilderGetValidatorsResponseEntry{
-				Slot:           duty.Slot,
-				ValidatorIndex: duty.ValidatorIndex,
-				Entry:          reg,
-			})
-		}
-	}
 
 	// Save duties to Redis
 	err = hk.redis.SetProposerDuties(proposerDuties)
@@ -264,3 +338,141 @@ func (hk *Housekeeper) updateValidatorRegistrationsInRedis() {
 	}
 	hk.log.Infof("updating %d validator registrations in Redis done - %f sec", len(regs), time.Since(timeStarted).Seconds())
 }
+
+// compactExecutionPayloads replaces the full execution payloads of older slots with their roots and summary fields in
+// batches, resuming after the last compacted id. Delivered payloads are kept, and with PAYLOAD_COMPACTION_REQUIRE_ARCHIVE
+// only payloads which were already exported by the archive tool are compacted.
+func (hk *Housekeeper) compactExecutionPayloads(headSlot uint64) {
+	// Should only happen once at a time
+	if hk.isCompactingPayloads.Swap(true) {
+		return
+	}
+	defer hk.isCompactingPayloads.Store(false)
+
+	keepSlots := uint64(payloadCompactionEpochs) * common.SlotsPerEpoch
+	if headSlot <= keepSlots {
+		return
+	}
+	keepFromSlot := headSlot - keepSlots
+
+	maxID := int64(math.MaxInt64)
+	if payloadCompactionRequireArchive {
+		archivedID, err := hk.db.GetBackfillProgress(database.ProgressArchiveExecutionPayloads)
+		if err != nil {
+			hk.log.WithError(err).Error("failed to get the execution payload archive progress")
+			return
+		}
+		maxID = archivedID
+	}
+
+	lastID, err := hk.db.GetBackfillProgress(database.ProgressCompactExecutionPayloads)
+	if err != nil {
+		hk.log.WithError(err).Error("failed to get the execution payload compaction progress")
+		return
+	}
+
+	log := hk.log.WithFields(logrus.Fields{
+		"keepFromSlot": keepFromSlot,
+		"maxID":        maxID,
+		"afterID":      lastID,
+		"batchSize":    payloadPruneBatchSize,
+	})
+	log.Info("compacting execution payloads...")
+	timeStarted := time.Now()
+
+	var numCompacted, numKept, numErrors int
+	for {
+		entries, err := hk.db.GetExecutionPayloadsForCompaction(lastID, uint64(payloadPruneBatchSize))
+		if err != nil {
+			log.WithError(err).Error("failed to get execution payloads to compact")
+			return
+		}
+
+		done := len(entries) < payloadPruneBatchSize
+		compacted := []*database.ExecutionPayloadEntry{}
+		for _, entry := range entries {
+			if entry.Slot >= keepFromSlot || entry.ID > maxID {
+				done = true
+				break
+			}
+			lastID = entry.ID
+			if entry.Delivered || entry.CompactedAt.Valid {
+				numKept++
+				continue
+			}
+			payload, err := database.CompactExecutionPayload(&entry.ExecutionPayloadEntry)
+			if err != nil {
+				log.WithError(err).WithField("id", entry.ID).Warn("failed to compact execution payload")
+				numErrors++
+				continue
+			}
+			compacted = append(compacted, &database.ExecutionPayloadEntry{ID: entry.ID, Payload: payload})
+		}
+
+		if len(compacted) > 0 {
+			if err := hk.db.CompactExecutionPayloads(compacted); err != nil {
+				log.WithError(err).Error("failed to compact execution payloads")
+				return
+			}
+			numCompacted += len(compacted)
+		}
+		if err := hk.db.SetBackfillProgress(database.ProgressCompactExecutionPayloads, lastID); err != nil {
+			log.WithError(err).Error("failed to store the execution payload compaction progress")
+			return
+		}
+		if done {
+			break
+		}
+		time.Sleep(time.Duration(payloadPruneBatchDelayMs) * time.Millisecond)
+	}
+
+	log.WithFields(logrus.Fields{
+		"lastID":       lastID,
+		"numCompacted": numCompacted,
+		"numKept":      numKept,
+		"numErrors":    numErrors,
+		"durationMs":   time.Since(timeStarted).Milliseconds(),
+	}).Info("compacting execution payloads done")
+}
+
+// pruneExecutionPayloads deletes execution payloads older than the retention period in batches, pausing between
+// batches to give the database time for vacuuming and to not starve other queries.
+func (hk *Housekeeper) pruneExecutionPayloads() {
+	// Should only happen once at a time
+	if hk.isPruningPayloads.Swap(true) {
+		return
+	}
+	defer hk.isPruningPayloads.Store(false)
+
+	olderThan := time.Now().UTC().Add(-time.Duration(payloadRetentionDays) * 24 * time.Hour)
+	log := hk.log.WithFields(logrus.Fields{
+		"olderThan": olderThan,
+		"batchSize": payloadPruneBatchSize,
+	})
+	log.Info("pruning execution payloads...")
+	timeStarted := time.Now()
+
+	var numPrunedTotal int64
+	for {
+		numPruned, err := hk.db.PruneExecutionPayloads(olderThan, uint64(payloadPruneBatchSize))
+		if err != nil {
+			log.WithError(err).Error("failed to prune execution payloads")
+			break
+		}
+		numPrunedTotal += numPruned
+		if numPruned > 0 {
+			if err := hk.redis.IncStats(datastore.RedisStatsFieldPayloadsPruned, numPruned); err != nil {
+				log.WithError(err).Error("failed to update pruned payloads stats")
+			}
+		}
+		if numPruned < int64(payloadPruneBatchSize) {
+			break
+		}
+		time.Sleep(time.Duration(payloadPruneBatchDelayMs) * time.Millisecond)
+	}
+
+	log.WithFields(logrus.Fields{
+		"numPruned":  numPrunedTotal,
+		"durationMs": time.Since(timeStarted).Milliseconds(),
+	}).Info("pruning execution payloads done")
+}
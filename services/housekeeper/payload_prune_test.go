@@ -0,0 +1,75 @@
+package housekeeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/go-redis/redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// pruneDB deletes up to batchSize of the remaining payloads per call
+type pruneDB struct {
+	database.MockDB
+	remaining int64
+	calls     int
+	err       error
+}
+
+func (db *pruneDB) PruneExecutionPayloads(olderThan time.Time, batchSize uint64) (numDeleted int64, err error) {
+	db.calls++
+	if db.err != nil {
+		return 0, db.err
+	}
+	numDeleted = min(db.remaining, int64(batchSize))
+	db.remaining -= numDeleted
+	return numDeleted, nil
+}
+
+func TestPruneExecutionPayloads(t *testing.T) {
+	batchSize, batchDelayMs := payloadPruneBatchSize, payloadPruneBatchDelayMs
+	payloadPruneBatchSize, payloadPruneBatchDelayMs = 10, 0
+	t.Cleanup(func() { payloadPruneBatchSize, payloadPruneBatchDelayMs = batchSize, batchDelayMs })
+
+	for _, tc := range []struct {
+		name          string
+		remaining     int64
+		err           error
+		expectedCalls int
+		expectedStats uint64
+	}{
+		{name: "nothing to prune", remaining: 0, expectedCalls: 1, expectedStats: 0},
+		{name: "partial batch", remaining: 7, expectedCalls: 1, expectedStats: 7},
+		{name: "several batches", remaining: 25, expectedCalls: 3, expectedStats: 25},
+		{name: "full batches", remaining: 20, expectedCalls: 3, expectedStats: 20},
+		{name: "error stops pruning", err: errors.New("db error"), expectedCalls: 1, expectedStats: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			redisTestServer, err := miniredis.Run()
+			require.NoError(t, err)
+			redisCache, err := datastore.NewRedisCache("", redisTestServer.Addr(), "")
+			require.NoError(t, err)
+
+			db := &pruneDB{remaining: tc.remaining, err: tc.err}
+			hk := NewHousekeeper(&HousekeeperOpts{Log: common.TestLog, Redis: redisCache, DB: db})
+			hk.pruneExecutionPayloads()
+
+			require.Equal(t, tc.expectedCalls, db.calls)
+			require.Equal(t, int64(0), db.remaining)
+			require.False(t, hk.isPruningPayloads.Load())
+
+			pruned, err := redisCache.GetStatsUint64(datastore.RedisStatsFieldPayloadsPruned)
+			if tc.expectedStats == 0 {
+				require.ErrorIs(t, err, redis.Nil)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStats, pruned)
+		})
+	}
+}
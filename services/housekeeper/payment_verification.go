@@ -0,0 +1,219 @@
+package housekeeper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/go-utils/jsonrpc"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// number of delivered payloads to verify per batch
+	paymentVerificationBatchSize = cli.GetEnvInt("PAYMENT_VERIFICATION_BATCH_SIZE", 100)
+
+	executionClientTimeout = 10 * time.Second
+)
+
+type executionBlock struct {
+	Number       hexutil.Uint64         `json:"number"`
+	Hash         string                 `json:"hash"`
+	Transactions []executionTransaction `json:"transactions"`
+}
+
+type executionTransaction struct {
+	To    *string      `json:"to"`
+	Value *hexutil.Big `json:"value"`
+}
+
+// executionClient is a minimal JSON-RPC client for the execution layer node
+type executionClient struct {
+	uri    string
+	client http.Client
+}
+
+func newExecutionClient(uri string) *executionClient {
+	return &executionClient{
+		uri:    uri,
+		client: http.Client{Timeout: executionClientTimeout},
+	}
+}
+
+// call sends a JSON-RPC request and decodes the result into reply. A null result leaves reply unchanged.
+func (c *executionClient) call(reply any, method string, params ...any) error {
+	req := jsonrpc.JSONRPCRequest{
+		ID:      1,
+		Method:  method,
+		Params:  params,
+		Version: "2.0",
+	}
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Post(c.uri, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	res := new(jsonrpc.JSONRPCResponse)
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return fmt.Errorf("%s failed: %w", method, res.Error)
+	}
+	if len(res.Result) == 0 || string(res.Result) == "null" {
+		return nil
+	}
+	return json.Unmarshal(res.Result, reply)
+}
+
+// getBlockByNumber returns the block (with transactions), or nil if it doesn't exist. blockNumber can also be a tag
+// like "finalized".
+func (c *executionClient) getBlockByNumber(blockNumber string) (block *executionBlock, err error) {
+	err = c.call(&block, "eth_getBlockByNumber", blockNumber, true)
+	return block, err
+}
+
+func (c *executionClient) getBalance(address string, blockNumber uint64) (*big.Int, error) {
+	balance := new(hexutil.Big)
+	err := c.call(balance, "eth_getBalance", address, hexutil.EncodeUint64(blockNumber))
+	return balance.ToInt(), err
+}
+
+// verifyPayments verifies the proposer payment of all delivered payloads up to the finalized block
+func (hk *Housekeeper) verifyPayments() {
+	// Should only happen once at a time
+	if hk.isVerifyingPayments.Swap(true) {
+		return
+	}
+	defer hk.isVerifyingPayments.Store(false)
+
+	finalizedBlock, err := hk.executionClient.getBlockByNumber("finalized")
+	if err != nil || finalizedBlock == nil {
+		hk.log.WithError(err).Error("failed to get finalized block from execution node")
+		return
+	}
+
+	log := hk.log.WithField("finalizedBlockNumber", uint64(finalizedBlock.Number))
+	log.Info("verifying proposer payments...")
+	timeStarted := time.Now()
+
+	numVerified, numDiscrepancies := 0, 0
+	for {
+		payloads, err := hk.db.GetDeliveredPayloadsWithoutPaymentVerification(uint64(finalizedBlock.Number), uint64(paymentVerificationBatchSize))
+		if err != nil {
+			log.WithError(err).Error("failed to get delivered payloads for payment verification")
+			break
+		}
+
+		for _, payload := range payloads {
+			entry, err := hk.verifyPayment(payload)
+			if err != nil {
+				// retried in the next run
+				log.WithError(err).WithField("slot", payload.Slot).Error("failed to verify proposer payment")
+				return
+			}
+
+			if entry.Status != database.PaymentStatusOK {
+				numDiscrepancies++
+				log.WithFields(logrus.Fields{
+					"slot":          entry.Slot,
+					"blockHash":     entry.BlockHash,
+					"builderPubkey": entry.BuilderPubkey,
+					"expectedValue": entry.ExpectedValue,
+					"receivedValue": entry.ReceivedValue,
+					"status":        entry.Status,
+				}).Warn("proposer payment discrepancy")
+			}
+
+			err = hk.db.InsertPaymentVerification(entry)
+			if err != nil {
+				log.WithError(err).WithField("slot", payload.Slot).Error("failed to save payment verification")
+				return
+			}
+			numVerified++
+		}
+
+		if len(payloads) < paymentVerificationBatchSize {
+			break
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"numVerified":      numVerified,
+		"numDiscrepancies": numDiscrepancies,
+		"durationMs":       time.Since(timeStarted).Milliseconds(),
+	}).Info("verifying proposer payments done")
+}
+
+// verifyPayment checks whether the proposer received the bid value of a delivered payload, either by the last
+// transaction of the block (payment transaction of the builder) or by the fee recipient's balance difference.
+func (hk *Housekeeper) verifyPayment(payload *database.DeliveredPayloadEntry) (*database.PaymentVerificationEntry, error) {
+	entry := &database.PaymentVerificationEntry{
+		Slot:                 payload.Slot,
+		BlockNumber:          payload.BlockNumber,
+		BlockHash:            payload.BlockHash,
+		BuilderPubkey:        payload.BuilderPubkey,
+		ProposerPubkey:       payload.ProposerPubkey,
+		ProposerFeeRecipient: payload.ProposerFeeRecipient,
+		ExpectedValue:        payload.Value,
+		ReceivedValue:        "0",
+	}
+
+	expectedValue, ok := new(big.Int).SetString(payload.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid value %s", payload.Value) //nolint:goerr113
+	}
+
+	block, err := hk.executionClient.getBlockByNumber(hexutil.EncodeUint64(payload.BlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if block == nil || !strings.EqualFold(block.Hash, payload.BlockHash) {
+		entry.Status = database.PaymentStatusBlockNotIncluded
+		return entry, nil
+	}
+
+	// Payment transaction at the end of the block
+	if len(block.Transactions) > 0 {
+		lastTx := block.Transactions[len(block.Transactions)-1]
+		if lastTx.To != nil && lastTx.Value != nil && strings.EqualFold(*lastTx.To, payload.ProposerFeeRecipient) && lastTx.Value.ToInt().Cmp(expectedValue) >= 0 {
+			entry.Method = database.PaymentMethodLastTx
+			entry.ReceivedValue = lastTx.Value.ToInt().String()
+			entry.Status = database.PaymentStatusOK
+			return entry, nil
+		}
+	}
+
+	// Balance difference of the fee recipient (i.e. if the builder used the fee recipient as coinbase)
+	balanceBefore, err := hk.executionClient.getBalance(payload.ProposerFeeRecipient, payload.BlockNumber-1)
+	if err != nil {
+		return nil, err
+	}
+	balanceAfter, err := hk.executionClient.getBalance(payload.ProposerFeeRecipient, payload.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	received := new(big.Int).Sub(balanceAfter, balanceBefore)
+
+	entry.Method = database.PaymentMethodBalanceDiff
+	entry.ReceivedValue = received.String()
+	if received.Cmp(expectedValue) >= 0 {
+		entry.Status = database.PaymentStatusOK
+	} else {
+		entry.Status = database.PaymentStatusUnderpaid
+	}
+	return entry, nil
+}
@@ -0,0 +1,103 @@
+package housekeeper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flashbots/go-utils/jsonrpc"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testFeeRecipient = "0x5cc0dde14e7256340cc820415a6022a7d1c93a35"
+	testBlockHash    = "0xa645370cc112c2e8e3cce121416c7dc849e773506d4b6fb9b752ada711355369"
+)
+
+// newTestExecutionNode returns an execution node which serves the block and the fee recipient balances
+// (balanceBefore at block 99, balanceAfter at block 100)
+func newTestExecutionNode(t *testing.T, block map[string]any, balanceBefore, balanceAfter string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(jsonrpc.JSONRPCRequest)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+
+		var result any
+		switch req.Method {
+		case "eth_getBlockByNumber":
+			result = block
+		case "eth_getBalance":
+			result = balanceAfter
+			if req.Params[1] == "0x63" {
+				result = balanceBefore
+			}
+		}
+		resultBytes, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(jsonrpc.NewJSONRPCResponse(req.ID, resultBytes)))
+	}))
+}
+
+func TestVerifyPayment(t *testing.T) {
+	payload := &database.DeliveredPayloadEntry{
+		Slot:                 42,
+		BlockNumber:          100,
+		BlockHash:            testBlockHash,
+		ProposerFeeRecipient: testFeeRecipient,
+		Value:                "1000",
+	}
+	paymentTx := map[string]any{"to": testFeeRecipient, "value": "0x3e8"} // 1000
+
+	testCases := []struct {
+		name           string
+		block          map[string]any
+		balanceAfter   string
+		expectedStatus string
+		expectedMethod string
+	}{
+		{
+			name:           "payment transaction",
+			block:          map[string]any{"number": "0x64", "hash": testBlockHash, "transactions": []any{paymentTx}},
+			balanceAfter:   "0x0",
+			expectedStatus: database.PaymentStatusOK,
+			expectedMethod: database.PaymentMethodLastTx,
+		},
+		{
+			name:           "fee recipient balance",
+			block:          map[string]any{"number": "0x64", "hash": testBlockHash, "transactions": []any{}},
+			balanceAfter:   "0x7d0", // 2000
+			expectedStatus: database.PaymentStatusOK,
+			expectedMethod: database.PaymentMethodBalanceDiff,
+		},
+		{
+			name:           "underpaid",
+			block:          map[string]any{"number": "0x64", "hash": testBlockHash, "transactions": []any{}},
+			balanceAfter:   "0x5dc", // 1500
+			expectedStatus: database.PaymentStatusUnderpaid,
+			expectedMethod: database.PaymentMethodBalanceDiff,
+		},
+		{
+			name:           "other block included",
+			block:          map[string]any{"number": "0x64", "hash": "0x01", "transactions": []any{paymentTx}},
+			balanceAfter:   "0x0",
+			expectedStatus: database.PaymentStatusBlockNotIncluded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := newTestExecutionNode(t, tc.block, "0x3e8", tc.balanceAfter)
+			defer node.Close()
+
+			hk := NewHousekeeper(&HousekeeperOpts{Log: common.TestLog, ExecutionURI: node.URL})
+			entry, err := hk.verifyPayment(payload)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStatus, entry.Status)
+			require.Equal(t, tc.expectedMethod, entry.Method)
+			require.Equal(t, "1000", entry.ExpectedValue)
+		})
+	}
+}
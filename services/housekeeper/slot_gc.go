@@ -0,0 +1,45 @@
+package housekeeper
+
+import (
+	"context"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// number of slots up to the head slot whose auction keys are kept in Redis, older ones are deleted on every new slot
+// instead of waiting for them to expire (0 to disable)
+var slotGCRetainSlots = uint64(cli.GetEnvInt("SLOT_GC_RETAIN_SLOTS", 2))
+
+// deleteSlotKeys deletes the Redis keys of the auctions (bids, floor bids, pending payloads and block publication
+// claims) of the slots before the given slot
+func (hk *Housekeeper) deleteSlotKeys(beforeSlot uint64) {
+	// Should only happen once at a time
+	if hk.isDeletingSlotKeys.Swap(true) {
+		return
+	}
+	defer hk.isDeletingSlotKeys.Store(false)
+
+	log := hk.log.WithField("beforeSlot", beforeSlot)
+	timeStarted := time.Now()
+	deleted, err := hk.redis.DeleteSlotKeysBefore(context.Background(), beforeSlot)
+	if err != nil {
+		log.WithError(err).Error("failed to delete slot keys")
+	}
+
+	total := int64(0)
+	fields := logrus.Fields{"durationMs": time.Since(timeStarted).Milliseconds()}
+	for kind, n := range deleted {
+		total += n
+		fields[kind] = n
+	}
+	if total == 0 {
+		return
+	}
+	if err := hk.redis.IncStats(datastore.RedisStatsFieldSlotGCKeysRemoved, total); err != nil {
+		log.WithError(err).Error("failed to update slot gc stats")
+	}
+	log.WithFields(fields).WithField("numKeys", total).Info("deleted keys of past slots")
+}
@@ -0,0 +1,35 @@
+package housekeeper
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteSlotKeys(t *testing.T) {
+	redisTestServer, err := miniredis.Run()
+	require.NoError(t, err)
+	redisCache, err := datastore.NewRedisCache("", redisTestServer.Addr(), "")
+	require.NoError(t, err)
+
+	blockHash := "0x01"
+	for _, slot := range []uint64{40, 41, 42} {
+		require.NoError(t, redisCache.SavePendingPayload(&common.PendingPayload{Slot: slot, BlockHash: blockHash}))
+	}
+
+	hk := NewHousekeeper(&HousekeeperOpts{Log: common.TestLog, Redis: redisCache, DB: &database.MockDB{}})
+	hk.deleteSlotKeys(42)
+
+	for slot, isKept := range map[uint64]bool{40: false, 41: false, 42: true} {
+		pending, err := redisCache.GetPendingPayload(slot, blockHash)
+		require.NoError(t, err)
+		require.Equal(t, isKept, pending != nil, "slot %d", slot)
+	}
+	removed, err := redisCache.GetStatsUint64(datastore.RedisStatsFieldSlotGCKeysRemoved)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), removed)
+}
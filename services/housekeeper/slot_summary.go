@@ -0,0 +1,57 @@
+package housekeeper
+
+import (
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// slotSummaryDelaySlots is how many slots after a slot its summary is written, as block submissions and delivered
+// payloads are saved to the database in the background
+const slotSummaryDelaySlots = 2
+
+// summarizeSlots writes the auction summaries of the slots in [fromSlot, toSlot]
+func (hk *Housekeeper) summarizeSlots(fromSlot, toSlot uint64) {
+	// Should only happen once at a time
+	if hk.isSummarizingSlots.Swap(true) {
+		return
+	}
+	defer hk.isSummarizingSlots.Store(false)
+
+	for slot := fromSlot; slot <= toSlot; slot++ {
+		log := hk.log.WithField("slot", slot)
+		timeStarted := time.Now()
+		entry, err := hk.summarizeSlot(slot)
+		if err != nil {
+			log.WithError(err).Error("failed to save slot summary")
+			continue
+		}
+		log.WithFields(logrus.Fields{
+			"numBids":       entry.NumBids,
+			"numBuilders":   entry.NumBuilders,
+			"numGetHeader":  entry.NumGetHeader,
+			"numGetPayload": entry.NumGetPayload,
+			"durationMs":    time.Since(timeStarted).Milliseconds(),
+		}).Debug("saved slot summary")
+	}
+}
+
+// summarizeSlot aggregates the bids and the delivered payload of a slot from the database, and the getHeader and
+// getPayload requests from Redis, and saves the summary
+func (hk *Housekeeper) summarizeSlot(slot uint64) (*database.SlotSummaryEntry, error) {
+	entry, err := hk.db.AggregateSlotSummary(slot)
+	if err != nil {
+		return nil, err
+	}
+	entry.NumGetHeader, err = hk.redis.GetSlotRequestCount(slot, datastore.RedisSlotRequestFieldGetHeader)
+	if err != nil {
+		return nil, err
+	}
+	entry.NumGetPayload, err = hk.redis.GetSlotRequestCount(slot, datastore.RedisSlotRequestFieldGetPayload)
+	if err != nil {
+		return nil, err
+	}
+	return entry, hk.db.UpsertSlotSummary(entry)
+}
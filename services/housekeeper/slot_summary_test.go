@@ -0,0 +1,46 @@
+package housekeeper
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+// slotSummaryDB records the saved slot summaries
+type slotSummaryDB struct {
+	database.MockDB
+	saved []*database.SlotSummaryEntry
+}
+
+func (db *slotSummaryDB) UpsertSlotSummary(entry *database.SlotSummaryEntry) error {
+	db.saved = append(db.saved, entry)
+	return nil
+}
+
+func TestSummarizeSlots(t *testing.T) {
+	redisTestServer, err := miniredis.Run()
+	require.NoError(t, err)
+	redisCache, err := datastore.NewRedisCache("", redisTestServer.Addr(), "")
+	require.NoError(t, err)
+
+	slot := uint64(42)
+	require.NoError(t, redisCache.IncSlotRequestCount(slot, datastore.RedisSlotRequestFieldGetHeader))
+	require.NoError(t, redisCache.IncSlotRequestCount(slot, datastore.RedisSlotRequestFieldGetHeader))
+	require.NoError(t, redisCache.IncSlotRequestCount(slot, datastore.RedisSlotRequestFieldGetPayload))
+
+	db := new(slotSummaryDB)
+	hk := NewHousekeeper(&HousekeeperOpts{Log: common.TestLog, Redis: redisCache, DB: db})
+	hk.summarizeSlots(slot, slot+1)
+
+	require.Len(t, db.saved, 2)
+	require.Equal(t, slot, db.saved[0].Slot)
+	require.Equal(t, uint64(2), db.saved[0].NumGetHeader)
+	require.Equal(t, uint64(1), db.saved[0].NumGetPayload)
+	require.Equal(t, slot+1, db.saved[1].Slot)
+	require.Equal(t, uint64(0), db.saved[1].NumGetHeader)
+	require.False(t, hk.isSummarizingSlots.Load())
+}
@@ -26,6 +26,7 @@ type StatusHTMLData struct { //nolint:musttag
 	ValidatorsRegistered        uint64
 	BellatrixForkVersion        string
 	CapellaForkVersion          string
+	DenebForkVersion            string
 	GenesisForkVersion          string
 	GenesisValidatorsRoot       string
 	BuilderSigningDomain        string
@@ -33,6 +34,8 @@ type StatusHTMLData struct { //nolint:musttag
 	HeadSlot                    uint64
 	NumPayloadsDelivered        uint64
 	Payloads                    []*database.DeliveredPayloadEntry
+	TopBuilders24h              []*database.TopBuilderEntry
+	TopBuilders7d               []*database.TopBuilderEntry
 
 	ValueLink      string
 	ValueOrderIcon string
@@ -67,10 +70,19 @@ func caseIt(s string) string {
 	return caser.String(s)
 }
 
+// shortHex shortens a long hex string to the first and last few characters (i.e. 0x1234..abcd)
+func shortHex(s string) string {
+	if len(s) <= 14 {
+		return s
+	}
+	return s[:8] + ".." + s[len(s)-6:]
+}
+
 var funcMap = template.FuncMap{
 	"weiToEth":  weiToEth,
 	"prettyInt": prettyInt,
 	"caseIt":    caseIt,
+	"shortHex":  shortHex,
 }
 
 //go:embed website.html
@@ -14,6 +14,7 @@ import (
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/go-utils/httplogger"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/flashbots/mev-boost-relay/database"
@@ -29,6 +30,15 @@ import (
 var (
 	ErrServerAlreadyStarted = errors.New("server was already started")
 	EnablePprof             = os.Getenv("PPROF") == "1"
+
+	numTopBuilders = uint64(10)
+
+	// maximum website requests per second per client IP and in total (0 for no limit)
+	rateLimitPerIP  = cli.GetEnvInt("WEBSITE_RATE_LIMIT_PER_IP", 0)
+	rateLimitGlobal = cli.GetEnvInt("WEBSITE_RATE_LIMIT_GLOBAL", 0)
+
+	// IPs or CIDR ranges of reverse proxies whose X-Forwarded-For header is trusted by the rate limiter
+	rateLimitTrustedProxies = common.GetEnvStrSlice("RATE_LIMIT_TRUSTED_PROXIES", nil)
 )
 
 type WebserverOpts struct {
@@ -36,7 +46,7 @@ type WebserverOpts struct {
 	RelayPubkeyHex string
 	NetworkDetails *common.EthNetworkDetails
 	Redis          *datastore.RedisCache
-	DB             *database.DatabaseService
+	DB             database.IDatabaseService
 	Log            *logrus.Entry
 
 	ShowConfigDetails bool
@@ -51,7 +61,7 @@ type Webserver struct {
 	log  *logrus.Entry
 
 	redis *datastore.RedisCache
-	db    *database.DatabaseService
+	db    database.IDatabaseService
 
 	srv        *http.Server
 	srvStarted uberatomic.Bool
@@ -65,6 +75,9 @@ type Webserver struct {
 	htmlByValueAsc  *[]byte
 
 	minifier *minify.M
+
+	// nil if rate limiting is disabled
+	rateLimiter *common.RateLimiter
 }
 
 func NewWebserver(opts *WebserverOpts) (*Webserver, error) {
@@ -99,6 +112,7 @@ func NewWebserver(opts *WebserverOpts) (*Webserver, error) {
 		ValidatorsRegistered:        0,
 		BellatrixForkVersion:        opts.NetworkDetails.BellatrixForkVersionHex,
 		CapellaForkVersion:          opts.NetworkDetails.CapellaForkVersionHex,
+		DenebForkVersion:            opts.NetworkDetails.DenebForkVersionHex,
 		GenesisForkVersion:          opts.NetworkDetails.GenesisForkVersionHex,
 		GenesisValidatorsRoot:       opts.NetworkDetails.GenesisValidatorsRootHex,
 		BuilderSigningDomain:        hexutil.Encode(opts.NetworkDetails.DomainBuilder[:]),
@@ -106,6 +120,8 @@ func NewWebserver(opts *WebserverOpts) (*Webserver, error) {
 		HeadSlot:                    0,
 		NumPayloadsDelivered:        0,
 		Payloads:                    []*database.DeliveredPayloadEntry{},
+		TopBuilders24h:              []*database.TopBuilderEntry{},
+		TopBuilders7d:               []*database.TopBuilderEntry{},
 		ValueLink:                   "",
 		ValueOrderIcon:              "",
 		ShowConfigDetails:           opts.ShowConfigDetails,
@@ -115,6 +131,17 @@ func NewWebserver(opts *WebserverOpts) (*Webserver, error) {
 		RelayURL:                    opts.RelayURL,
 	}
 
+	if rateLimitPerIP > 0 || rateLimitGlobal > 0 {
+		server.rateLimiter, err = common.NewRateLimiter(common.RateLimiterOpts{
+			PerIPRate:      float64(rateLimitPerIP),
+			GlobalRate:     float64(rateLimitGlobal),
+			TrustedProxies: rateLimitTrustedProxies,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return server, nil
 }
 
@@ -156,7 +183,12 @@ func (srv *Webserver) getRouter() http.Handler {
 		r.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux)
 	}
 
-	loggedRouter := httplogger.LoggingMiddlewareLogrus(srv.log, r)
+	var handler http.Handler = r
+	if srv.rateLimiter != nil {
+		handler = srv.rateLimiter.Wrap(r)
+	}
+
+	loggedRouter := httplogger.LoggingMiddlewareLogrus(srv.log, handler)
 	withGz := gziphandler.GzipHandler(loggedRouter)
 	return withGz
 }
@@ -182,6 +214,16 @@ func (srv *Webserver) updateHTML() {
 		srv.log.WithError(err).Error("error getting recent payloads")
 	}
 
+	topBuilders24h, err := srv.db.GetTopBuilders(time.Now().Add(-24*time.Hour), numTopBuilders)
+	if err != nil {
+		srv.log.WithError(err).Error("error getting top builders (24h)")
+	}
+
+	topBuilders7d, err := srv.db.GetTopBuilders(time.Now().Add(-7*24*time.Hour), numTopBuilders)
+	if err != nil {
+		srv.log.WithError(err).Error("error getting top builders (7d)")
+	}
+
 	_numPayloadsDelivered, err := srv.db.GetNumDeliveredPayloads()
 	if err != nil {
 		srv.log.WithError(err).Error("error getting number of delivered payloads")
@@ -206,6 +248,8 @@ func (srv *Webserver) updateHTML() {
 	srv.statusHTMLData.ValidatorsRegistered = _numRegistered
 	srv.statusHTMLData.NumPayloadsDelivered = _numPayloadsDelivered
 	srv.statusHTMLData.HeadSlot = _latestSlotInt
+	srv.statusHTMLData.TopBuilders24h = topBuilders24h
+	srv.statusHTMLData.TopBuilders7d = topBuilders7d
 
 	// Now generate the HTML
 	htmlDefault := bytes.Buffer{}